@@ -32,6 +32,7 @@ const (
 	CoinType_ZCASH        CoinType = 2
 	CoinType_LITECOIN     CoinType = 3
 	CoinType_ETHEREUM     CoinType = 4
+	CoinType_DOGECOIN     CoinType = 5
 )
 
 var CoinType_name = map[int32]string{
@@ -40,6 +41,7 @@ var CoinType_name = map[int32]string{
 	2: "ZCASH",
 	3: "LITECOIN",
 	4: "ETHEREUM",
+	5: "DOGECOIN",
 }
 var CoinType_value = map[string]int32{
 	"BITCOIN":      0,
@@ -47,13 +49,14 @@ var CoinType_value = map[string]int32{
 	"ZCASH":        2,
 	"LITECOIN":     3,
 	"ETHEREUM":     4,
+	"DOGECOIN":     5,
 }
 
 func (x CoinType) String() string {
 	return proto.EnumName(CoinType_name, int32(x))
 }
 func (CoinType) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor_api_2ff753dddd9b028a, []int{0}
+	return fileDescriptor_api_d239b4462bcb8aa3, []int{0}
 }
 
 type KeyPurpose int32
@@ -76,7 +79,85 @@ func (x KeyPurpose) String() string {
 	return proto.EnumName(KeyPurpose_name, int32(x))
 }
 func (KeyPurpose) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor_api_2ff753dddd9b028a, []int{1}
+	return fileDescriptor_api_d239b4462bcb8aa3, []int{1}
+}
+
+type ExportFormat int32
+
+const (
+	ExportFormat_CSV  ExportFormat = 0
+	ExportFormat_JSON ExportFormat = 1
+)
+
+var ExportFormat_name = map[int32]string{
+	0: "CSV",
+	1: "JSON",
+}
+var ExportFormat_value = map[string]int32{
+	"CSV":  0,
+	"JSON": 1,
+}
+
+func (x ExportFormat) String() string {
+	return proto.EnumName(ExportFormat_name, int32(x))
+}
+func (ExportFormat) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_api_d239b4462bcb8aa3, []int{2}
+}
+
+type OutputOwner int32
+
+const (
+	OutputOwner_EXTERNAL_OUTPUT OutputOwner = 0
+	OutputOwner_WALLET_OUTPUT   OutputOwner = 1
+	OutputOwner_CHANGE_OUTPUT   OutputOwner = 2
+)
+
+var OutputOwner_name = map[int32]string{
+	0: "EXTERNAL_OUTPUT",
+	1: "WALLET_OUTPUT",
+	2: "CHANGE_OUTPUT",
+}
+var OutputOwner_value = map[string]int32{
+	"EXTERNAL_OUTPUT": 0,
+	"WALLET_OUTPUT":   1,
+	"CHANGE_OUTPUT":   2,
+}
+
+func (x OutputOwner) String() string {
+	return proto.EnumName(OutputOwner_name, int32(x))
+}
+func (OutputOwner) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_api_d239b4462bcb8aa3, []int{3}
+}
+
+type Direction int32
+
+const (
+	Direction_ANY_DIRECTION Direction = 0
+	Direction_INCOMING      Direction = 1
+	Direction_OUTGOING      Direction = 2
+	Direction_SELF          Direction = 3
+)
+
+var Direction_name = map[int32]string{
+	0: "ANY_DIRECTION",
+	1: "INCOMING",
+	2: "OUTGOING",
+	3: "SELF",
+}
+var Direction_value = map[string]int32{
+	"ANY_DIRECTION": 0,
+	"INCOMING":      1,
+	"OUTGOING":      2,
+	"SELF":          3,
+}
+
+func (x Direction) String() string {
+	return proto.EnumName(Direction_name, int32(x))
+}
+func (Direction) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_api_d239b4462bcb8aa3, []int{4}
 }
 
 type FeeLevel int32
@@ -102,7 +183,7 @@ func (x FeeLevel) String() string {
 	return proto.EnumName(FeeLevel_name, int32(x))
 }
 func (FeeLevel) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor_api_2ff753dddd9b028a, []int{2}
+	return fileDescriptor_api_d239b4462bcb8aa3, []int{5}
 }
 
 type Empty struct {
@@ -115,7 +196,7 @@ func (m *Empty) Reset()         { *m = Empty{} }
 func (m *Empty) String() string { return proto.CompactTextString(m) }
 func (*Empty) ProtoMessage()    {}
 func (*Empty) Descriptor() ([]byte, []int) {
-	return fileDescriptor_api_2ff753dddd9b028a, []int{0}
+	return fileDescriptor_api_d239b4462bcb8aa3, []int{0}
 }
 func (m *Empty) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_Empty.Unmarshal(m, b)
@@ -137,6 +218,7 @@ var xxx_messageInfo_Empty proto.InternalMessageInfo
 
 type CoinSelection struct {
 	Coin                 CoinType `protobuf:"varint,1,opt,name=coin,proto3,enum=pb.CoinType" json:"coin,omitempty"`
+	CoinName             string   `protobuf:"bytes,2,opt,name=coinName,proto3" json:"coinName,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -146,7 +228,7 @@ func (m *CoinSelection) Reset()         { *m = CoinSelection{} }
 func (m *CoinSelection) String() string { return proto.CompactTextString(m) }
 func (*CoinSelection) ProtoMessage()    {}
 func (*CoinSelection) Descriptor() ([]byte, []int) {
-	return fileDescriptor_api_2ff753dddd9b028a, []int{1}
+	return fileDescriptor_api_d239b4462bcb8aa3, []int{1}
 }
 func (m *CoinSelection) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_CoinSelection.Unmarshal(m, b)
@@ -173,6 +255,13 @@ func (m *CoinSelection) GetCoin() CoinType {
 	return CoinType_BITCOIN
 }
 
+func (m *CoinSelection) GetCoinName() string {
+	if m != nil {
+		return m.CoinName
+	}
+	return ""
+}
+
 type Row struct {
 	Data                 string   `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
@@ -184,7 +273,7 @@ func (m *Row) Reset()         { *m = Row{} }
 func (m *Row) String() string { return proto.CompactTextString(m) }
 func (*Row) ProtoMessage()    {}
 func (*Row) Descriptor() ([]byte, []int) {
-	return fileDescriptor_api_2ff753dddd9b028a, []int{2}
+	return fileDescriptor_api_d239b4462bcb8aa3, []int{2}
 }
 func (m *Row) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_Row.Unmarshal(m, b)
@@ -211,9 +300,72 @@ func (m *Row) GetData() string {
 	return ""
 }
 
+type ExportRequest struct {
+	Coin                 CoinType     `protobuf:"varint,1,opt,name=coin,proto3,enum=pb.CoinType" json:"coin,omitempty"`
+	AllCoins             bool         `protobuf:"varint,2,opt,name=allCoins,proto3" json:"allCoins,omitempty"`
+	Currency             string       `protobuf:"bytes,3,opt,name=currency,proto3" json:"currency,omitempty"`
+	Format               ExportFormat `protobuf:"varint,4,opt,name=format,proto3,enum=pb.ExportFormat" json:"format,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *ExportRequest) Reset()         { *m = ExportRequest{} }
+func (m *ExportRequest) String() string { return proto.CompactTextString(m) }
+func (*ExportRequest) ProtoMessage()    {}
+func (*ExportRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_api_d239b4462bcb8aa3, []int{3}
+}
+func (m *ExportRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ExportRequest.Unmarshal(m, b)
+}
+func (m *ExportRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ExportRequest.Marshal(b, m, deterministic)
+}
+func (dst *ExportRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ExportRequest.Merge(dst, src)
+}
+func (m *ExportRequest) XXX_Size() int {
+	return xxx_messageInfo_ExportRequest.Size(m)
+}
+func (m *ExportRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ExportRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ExportRequest proto.InternalMessageInfo
+
+func (m *ExportRequest) GetCoin() CoinType {
+	if m != nil {
+		return m.Coin
+	}
+	return CoinType_BITCOIN
+}
+
+func (m *ExportRequest) GetAllCoins() bool {
+	if m != nil {
+		return m.AllCoins
+	}
+	return false
+}
+
+func (m *ExportRequest) GetCurrency() string {
+	if m != nil {
+		return m.Currency
+	}
+	return ""
+}
+
+func (m *ExportRequest) GetFormat() ExportFormat {
+	if m != nil {
+		return m.Format
+	}
+	return ExportFormat_CSV
+}
+
 type KeySelection struct {
 	Coin                 CoinType   `protobuf:"varint,1,opt,name=coin,proto3,enum=pb.CoinType" json:"coin,omitempty"`
 	Purpose              KeyPurpose `protobuf:"varint,2,opt,name=purpose,proto3,enum=pb.KeyPurpose" json:"purpose,omitempty"`
+	CoinName             string     `protobuf:"bytes,3,opt,name=coinName,proto3" json:"coinName,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
 	XXX_unrecognized     []byte     `json:"-"`
 	XXX_sizecache        int32      `json:"-"`
@@ -223,7 +375,7 @@ func (m *KeySelection) Reset()         { *m = KeySelection{} }
 func (m *KeySelection) String() string { return proto.CompactTextString(m) }
 func (*KeySelection) ProtoMessage()    {}
 func (*KeySelection) Descriptor() ([]byte, []int) {
-	return fileDescriptor_api_2ff753dddd9b028a, []int{3}
+	return fileDescriptor_api_d239b4462bcb8aa3, []int{4}
 }
 func (m *KeySelection) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_KeySelection.Unmarshal(m, b)
@@ -257,9 +409,17 @@ func (m *KeySelection) GetPurpose() KeyPurpose {
 	return KeyPurpose_INTERNAL
 }
 
+func (m *KeySelection) GetCoinName() string {
+	if m != nil {
+		return m.CoinName
+	}
+	return ""
+}
+
 type Address struct {
 	Coin                 CoinType `protobuf:"varint,1,opt,name=coin,proto3,enum=pb.CoinType" json:"coin,omitempty"`
 	Addr                 string   `protobuf:"bytes,2,opt,name=addr,proto3" json:"addr,omitempty"`
+	CoinName             string   `protobuf:"bytes,3,opt,name=coinName,proto3" json:"coinName,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -269,7 +429,7 @@ func (m *Address) Reset()         { *m = Address{} }
 func (m *Address) String() string { return proto.CompactTextString(m) }
 func (*Address) ProtoMessage()    {}
 func (*Address) Descriptor() ([]byte, []int) {
-	return fileDescriptor_api_2ff753dddd9b028a, []int{4}
+	return fileDescriptor_api_d239b4462bcb8aa3, []int{5}
 }
 func (m *Address) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_Address.Unmarshal(m, b)
@@ -303,6 +463,13 @@ func (m *Address) GetAddr() string {
 	return ""
 }
 
+func (m *Address) GetCoinName() string {
+	if m != nil {
+		return m.CoinName
+	}
+	return ""
+}
+
 type Height struct {
 	Height               uint32   `protobuf:"varint,1,opt,name=height,proto3" json:"height,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
@@ -314,7 +481,7 @@ func (m *Height) Reset()         { *m = Height{} }
 func (m *Height) String() string { return proto.CompactTextString(m) }
 func (*Height) ProtoMessage()    {}
 func (*Height) Descriptor() ([]byte, []int) {
-	return fileDescriptor_api_2ff753dddd9b028a, []int{5}
+	return fileDescriptor_api_d239b4462bcb8aa3, []int{6}
 }
 func (m *Height) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_Height.Unmarshal(m, b)
@@ -353,7 +520,7 @@ func (m *Balances) Reset()         { *m = Balances{} }
 func (m *Balances) String() string { return proto.CompactTextString(m) }
 func (*Balances) ProtoMessage()    {}
 func (*Balances) Descriptor() ([]byte, []int) {
-	return fileDescriptor_api_2ff753dddd9b028a, []int{6}
+	return fileDescriptor_api_d239b4462bcb8aa3, []int{7}
 }
 func (m *Balances) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_Balances.Unmarshal(m, b)
@@ -387,6 +554,200 @@ func (m *Balances) GetUnconfirmed() uint64 {
 	return 0
 }
 
+type PortfolioRequest struct {
+	Currency             string   `protobuf:"bytes,1,opt,name=currency,proto3" json:"currency,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PortfolioRequest) Reset()         { *m = PortfolioRequest{} }
+func (m *PortfolioRequest) String() string { return proto.CompactTextString(m) }
+func (*PortfolioRequest) ProtoMessage()    {}
+func (*PortfolioRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_api_d239b4462bcb8aa3, []int{8}
+}
+func (m *PortfolioRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PortfolioRequest.Unmarshal(m, b)
+}
+func (m *PortfolioRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PortfolioRequest.Marshal(b, m, deterministic)
+}
+func (dst *PortfolioRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PortfolioRequest.Merge(dst, src)
+}
+func (m *PortfolioRequest) XXX_Size() int {
+	return xxx_messageInfo_PortfolioRequest.Size(m)
+}
+func (m *PortfolioRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_PortfolioRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PortfolioRequest proto.InternalMessageInfo
+
+func (m *PortfolioRequest) GetCurrency() string {
+	if m != nil {
+		return m.Currency
+	}
+	return ""
+}
+
+type Holding struct {
+	CurrencyCode         string               `protobuf:"bytes,1,opt,name=currencyCode,proto3" json:"currencyCode,omitempty"`
+	Confirmed            uint64               `protobuf:"varint,2,opt,name=confirmed,proto3" json:"confirmed,omitempty"`
+	Unconfirmed          uint64               `protobuf:"varint,3,opt,name=unconfirmed,proto3" json:"unconfirmed,omitempty"`
+	Rate                 float64              `protobuf:"fixed64,4,opt,name=rate,proto3" json:"rate,omitempty"`
+	RateTime             *timestamp.Timestamp `protobuf:"bytes,5,opt,name=rateTime,proto3" json:"rateTime,omitempty"`
+	FiatConfirmed        float64              `protobuf:"fixed64,6,opt,name=fiatConfirmed,proto3" json:"fiatConfirmed,omitempty"`
+	FiatUnconfirmed      float64              `protobuf:"fixed64,7,opt,name=fiatUnconfirmed,proto3" json:"fiatUnconfirmed,omitempty"`
+	Error                string               `protobuf:"bytes,8,opt,name=error,proto3" json:"error,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *Holding) Reset()         { *m = Holding{} }
+func (m *Holding) String() string { return proto.CompactTextString(m) }
+func (*Holding) ProtoMessage()    {}
+func (*Holding) Descriptor() ([]byte, []int) {
+	return fileDescriptor_api_d239b4462bcb8aa3, []int{9}
+}
+func (m *Holding) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Holding.Unmarshal(m, b)
+}
+func (m *Holding) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Holding.Marshal(b, m, deterministic)
+}
+func (dst *Holding) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Holding.Merge(dst, src)
+}
+func (m *Holding) XXX_Size() int {
+	return xxx_messageInfo_Holding.Size(m)
+}
+func (m *Holding) XXX_DiscardUnknown() {
+	xxx_messageInfo_Holding.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Holding proto.InternalMessageInfo
+
+func (m *Holding) GetCurrencyCode() string {
+	if m != nil {
+		return m.CurrencyCode
+	}
+	return ""
+}
+
+func (m *Holding) GetConfirmed() uint64 {
+	if m != nil {
+		return m.Confirmed
+	}
+	return 0
+}
+
+func (m *Holding) GetUnconfirmed() uint64 {
+	if m != nil {
+		return m.Unconfirmed
+	}
+	return 0
+}
+
+func (m *Holding) GetRate() float64 {
+	if m != nil {
+		return m.Rate
+	}
+	return 0
+}
+
+func (m *Holding) GetRateTime() *timestamp.Timestamp {
+	if m != nil {
+		return m.RateTime
+	}
+	return nil
+}
+
+func (m *Holding) GetFiatConfirmed() float64 {
+	if m != nil {
+		return m.FiatConfirmed
+	}
+	return 0
+}
+
+func (m *Holding) GetFiatUnconfirmed() float64 {
+	if m != nil {
+		return m.FiatUnconfirmed
+	}
+	return 0
+}
+
+func (m *Holding) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+type PortfolioValue struct {
+	Currency             string     `protobuf:"bytes,1,opt,name=currency,proto3" json:"currency,omitempty"`
+	Holdings             []*Holding `protobuf:"bytes,2,rep,name=holdings,proto3" json:"holdings,omitempty"`
+	TotalConfirmed       float64    `protobuf:"fixed64,3,opt,name=totalConfirmed,proto3" json:"totalConfirmed,omitempty"`
+	TotalUnconfirmed     float64    `protobuf:"fixed64,4,opt,name=totalUnconfirmed,proto3" json:"totalUnconfirmed,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
+	XXX_unrecognized     []byte     `json:"-"`
+	XXX_sizecache        int32      `json:"-"`
+}
+
+func (m *PortfolioValue) Reset()         { *m = PortfolioValue{} }
+func (m *PortfolioValue) String() string { return proto.CompactTextString(m) }
+func (*PortfolioValue) ProtoMessage()    {}
+func (*PortfolioValue) Descriptor() ([]byte, []int) {
+	return fileDescriptor_api_d239b4462bcb8aa3, []int{10}
+}
+func (m *PortfolioValue) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PortfolioValue.Unmarshal(m, b)
+}
+func (m *PortfolioValue) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PortfolioValue.Marshal(b, m, deterministic)
+}
+func (dst *PortfolioValue) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PortfolioValue.Merge(dst, src)
+}
+func (m *PortfolioValue) XXX_Size() int {
+	return xxx_messageInfo_PortfolioValue.Size(m)
+}
+func (m *PortfolioValue) XXX_DiscardUnknown() {
+	xxx_messageInfo_PortfolioValue.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PortfolioValue proto.InternalMessageInfo
+
+func (m *PortfolioValue) GetCurrency() string {
+	if m != nil {
+		return m.Currency
+	}
+	return ""
+}
+
+func (m *PortfolioValue) GetHoldings() []*Holding {
+	if m != nil {
+		return m.Holdings
+	}
+	return nil
+}
+
+func (m *PortfolioValue) GetTotalConfirmed() float64 {
+	if m != nil {
+		return m.TotalConfirmed
+	}
+	return 0
+}
+
+func (m *PortfolioValue) GetTotalUnconfirmed() float64 {
+	if m != nil {
+		return m.TotalUnconfirmed
+	}
+	return 0
+}
+
 type Key struct {
 	Key                  string   `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
@@ -398,7 +759,7 @@ func (m *Key) Reset()         { *m = Key{} }
 func (m *Key) String() string { return proto.CompactTextString(m) }
 func (*Key) ProtoMessage()    {}
 func (*Key) Descriptor() ([]byte, []int) {
-	return fileDescriptor_api_2ff753dddd9b028a, []int{7}
+	return fileDescriptor_api_d239b4462bcb8aa3, []int{11}
 }
 func (m *Key) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_Key.Unmarshal(m, b)
@@ -436,7 +797,7 @@ func (m *Keys) Reset()         { *m = Keys{} }
 func (m *Keys) String() string { return proto.CompactTextString(m) }
 func (*Keys) ProtoMessage()    {}
 func (*Keys) Descriptor() ([]byte, []int) {
-	return fileDescriptor_api_2ff753dddd9b028a, []int{8}
+	return fileDescriptor_api_d239b4462bcb8aa3, []int{12}
 }
 func (m *Keys) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_Keys.Unmarshal(m, b)
@@ -474,7 +835,7 @@ func (m *Addresses) Reset()         { *m = Addresses{} }
 func (m *Addresses) String() string { return proto.CompactTextString(m) }
 func (*Addresses) ProtoMessage()    {}
 func (*Addresses) Descriptor() ([]byte, []int) {
-	return fileDescriptor_api_2ff753dddd9b028a, []int{9}
+	return fileDescriptor_api_d239b4462bcb8aa3, []int{13}
 }
 func (m *Addresses) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_Addresses.Unmarshal(m, b)
@@ -512,7 +873,7 @@ func (m *BoolResponse) Reset()         { *m = BoolResponse{} }
 func (m *BoolResponse) String() string { return proto.CompactTextString(m) }
 func (*BoolResponse) ProtoMessage()    {}
 func (*BoolResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_api_2ff753dddd9b028a, []int{10}
+	return fileDescriptor_api_d239b4462bcb8aa3, []int{14}
 }
 func (m *BoolResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_BoolResponse.Unmarshal(m, b)
@@ -550,7 +911,7 @@ func (m *NetParams) Reset()         { *m = NetParams{} }
 func (m *NetParams) String() string { return proto.CompactTextString(m) }
 func (*NetParams) ProtoMessage()    {}
 func (*NetParams) Descriptor() ([]byte, []int) {
-	return fileDescriptor_api_2ff753dddd9b028a, []int{11}
+	return fileDescriptor_api_d239b4462bcb8aa3, []int{15}
 }
 func (m *NetParams) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_NetParams.Unmarshal(m, b)
@@ -579,6 +940,7 @@ func (m *NetParams) GetName() string {
 
 type TransactionList struct {
 	Transactions         []*Tx    `protobuf:"bytes,1,rep,name=transactions,proto3" json:"transactions,omitempty"`
+	NextCursor           string   `protobuf:"bytes,2,opt,name=nextCursor,proto3" json:"nextCursor,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -588,7 +950,7 @@ func (m *TransactionList) Reset()         { *m = TransactionList{} }
 func (m *TransactionList) String() string { return proto.CompactTextString(m) }
 func (*TransactionList) ProtoMessage()    {}
 func (*TransactionList) Descriptor() ([]byte, []int) {
-	return fileDescriptor_api_2ff753dddd9b028a, []int{12}
+	return fileDescriptor_api_d239b4462bcb8aa3, []int{16}
 }
 func (m *TransactionList) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_TransactionList.Unmarshal(m, b)
@@ -615,6 +977,13 @@ func (m *TransactionList) GetTransactions() []*Tx {
 	return nil
 }
 
+func (m *TransactionList) GetNextCursor() string {
+	if m != nil {
+		return m.NextCursor
+	}
+	return ""
+}
+
 type Tx struct {
 	Txid                 string               `protobuf:"bytes,1,opt,name=txid,proto3" json:"txid,omitempty"`
 	Value                int64                `protobuf:"varint,2,opt,name=value,proto3" json:"value,omitempty"`
@@ -622,6 +991,21 @@ type Tx struct {
 	Timestamp            *timestamp.Timestamp `protobuf:"bytes,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
 	WatchOnly            bool                 `protobuf:"varint,5,opt,name=watchOnly,proto3" json:"watchOnly,omitempty"`
 	Raw                  []byte               `protobuf:"bytes,6,opt,name=raw,proto3" json:"raw,omitempty"`
+	Data                 [][]byte             `protobuf:"bytes,7,rep,name=data,proto3" json:"data,omitempty"`
+	ReferenceID          string               `protobuf:"bytes,8,opt,name=referenceID,proto3" json:"referenceID,omitempty"`
+	Memo                 string               `protobuf:"bytes,9,opt,name=memo,proto3" json:"memo,omitempty"`
+	Labels               []string             `protobuf:"bytes,10,rep,name=labels,proto3" json:"labels,omitempty"`
+	Counterparty         string               `protobuf:"bytes,11,opt,name=counterparty,proto3" json:"counterparty,omitempty"`
+	Inputs               []*DecodedInput      `protobuf:"bytes,12,rep,name=inputs,proto3" json:"inputs,omitempty"`
+	Outputs              []*TxOutput          `protobuf:"bytes,13,rep,name=outputs,proto3" json:"outputs,omitempty"`
+	Fee                  uint64               `protobuf:"varint,14,opt,name=fee,proto3" json:"fee,omitempty"`
+	FeeKnown             bool                 `protobuf:"varint,15,opt,name=feeKnown,proto3" json:"feeKnown,omitempty"`
+	Size                 uint64               `protobuf:"varint,16,opt,name=size,proto3" json:"size,omitempty"`
+	Vsize                uint64               `protobuf:"varint,17,opt,name=vsize,proto3" json:"vsize,omitempty"`
+	FeeRate              float64              `protobuf:"fixed64,18,opt,name=feeRate,proto3" json:"feeRate,omitempty"`
+	Confirmations        uint32               `protobuf:"varint,19,opt,name=confirmations,proto3" json:"confirmations,omitempty"`
+	Rbf                  bool                 `protobuf:"varint,20,opt,name=rbf,proto3" json:"rbf,omitempty"`
+	ExpiryHeight         uint32               `protobuf:"varint,21,opt,name=expiryHeight,proto3" json:"expiryHeight,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
 	XXX_unrecognized     []byte               `json:"-"`
 	XXX_sizecache        int32                `json:"-"`
@@ -631,7 +1015,7 @@ func (m *Tx) Reset()         { *m = Tx{} }
 func (m *Tx) String() string { return proto.CompactTextString(m) }
 func (*Tx) ProtoMessage()    {}
 func (*Tx) Descriptor() ([]byte, []int) {
-	return fileDescriptor_api_2ff753dddd9b028a, []int{13}
+	return fileDescriptor_api_d239b4462bcb8aa3, []int{17}
 }
 func (m *Tx) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_Tx.Unmarshal(m, b)
@@ -693,55 +1077,381 @@ func (m *Tx) GetRaw() []byte {
 	return nil
 }
 
-type Txid struct {
-	Coin                 CoinType `protobuf:"varint,1,opt,name=coin,proto3,enum=pb.CoinType" json:"coin,omitempty"`
-	Hash                 string   `protobuf:"bytes,2,opt,name=hash,proto3" json:"hash,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+func (m *Tx) GetData() [][]byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
 }
 
-func (m *Txid) Reset()         { *m = Txid{} }
-func (m *Txid) String() string { return proto.CompactTextString(m) }
-func (*Txid) ProtoMessage()    {}
-func (*Txid) Descriptor() ([]byte, []int) {
-	return fileDescriptor_api_2ff753dddd9b028a, []int{14}
+func (m *Tx) GetReferenceID() string {
+	if m != nil {
+		return m.ReferenceID
+	}
+	return ""
 }
-func (m *Txid) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_Txid.Unmarshal(m, b)
+
+func (m *Tx) GetMemo() string {
+	if m != nil {
+		return m.Memo
+	}
+	return ""
 }
-func (m *Txid) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_Txid.Marshal(b, m, deterministic)
+
+func (m *Tx) GetLabels() []string {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
 }
-func (dst *Txid) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_Txid.Merge(dst, src)
+
+func (m *Tx) GetCounterparty() string {
+	if m != nil {
+		return m.Counterparty
+	}
+	return ""
 }
-func (m *Txid) XXX_Size() int {
-	return xxx_messageInfo_Txid.Size(m)
+
+func (m *Tx) GetInputs() []*DecodedInput {
+	if m != nil {
+		return m.Inputs
+	}
+	return nil
 }
-func (m *Txid) XXX_DiscardUnknown() {
-	xxx_messageInfo_Txid.DiscardUnknown(m)
+
+func (m *Tx) GetOutputs() []*TxOutput {
+	if m != nil {
+		return m.Outputs
+	}
+	return nil
 }
 
-var xxx_messageInfo_Txid proto.InternalMessageInfo
+func (m *Tx) GetFee() uint64 {
+	if m != nil {
+		return m.Fee
+	}
+	return 0
+}
 
-func (m *Txid) GetCoin() CoinType {
+func (m *Tx) GetFeeKnown() bool {
 	if m != nil {
-		return m.Coin
+		return m.FeeKnown
 	}
-	return CoinType_BITCOIN
+	return false
 }
 
-func (m *Txid) GetHash() string {
+func (m *Tx) GetSize() uint64 {
 	if m != nil {
-		return m.Hash
+		return m.Size
 	}
-	return ""
+	return 0
 }
 
-type FeeLevelSelection struct {
-	Coin                 CoinType `protobuf:"varint,1,opt,name=coin,proto3,enum=pb.CoinType" json:"coin,omitempty"`
+func (m *Tx) GetVsize() uint64 {
+	if m != nil {
+		return m.Vsize
+	}
+	return 0
+}
+
+func (m *Tx) GetFeeRate() float64 {
+	if m != nil {
+		return m.FeeRate
+	}
+	return 0
+}
+
+func (m *Tx) GetConfirmations() uint32 {
+	if m != nil {
+		return m.Confirmations
+	}
+	return 0
+}
+
+func (m *Tx) GetRbf() bool {
+	if m != nil {
+		return m.Rbf
+	}
+	return false
+}
+
+func (m *Tx) GetExpiryHeight() uint32 {
+	if m != nil {
+		return m.ExpiryHeight
+	}
+	return 0
+}
+
+type TxOutput struct {
+	Index                uint32      `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	Address              string      `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"`
+	ScriptPubKey         []byte      `protobuf:"bytes,3,opt,name=scriptPubKey,proto3" json:"scriptPubKey,omitempty"`
+	Value                uint64      `protobuf:"varint,4,opt,name=value,proto3" json:"value,omitempty"`
+	Data                 []byte      `protobuf:"bytes,5,opt,name=data,proto3" json:"data,omitempty"`
+	Owner                OutputOwner `protobuf:"varint,6,opt,name=owner,proto3,enum=pb.OutputOwner" json:"owner,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *TxOutput) Reset()         { *m = TxOutput{} }
+func (m *TxOutput) String() string { return proto.CompactTextString(m) }
+func (*TxOutput) ProtoMessage()    {}
+func (*TxOutput) Descriptor() ([]byte, []int) {
+	return fileDescriptor_api_d239b4462bcb8aa3, []int{18}
+}
+func (m *TxOutput) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TxOutput.Unmarshal(m, b)
+}
+func (m *TxOutput) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TxOutput.Marshal(b, m, deterministic)
+}
+func (dst *TxOutput) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TxOutput.Merge(dst, src)
+}
+func (m *TxOutput) XXX_Size() int {
+	return xxx_messageInfo_TxOutput.Size(m)
+}
+func (m *TxOutput) XXX_DiscardUnknown() {
+	xxx_messageInfo_TxOutput.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TxOutput proto.InternalMessageInfo
+
+func (m *TxOutput) GetIndex() uint32 {
+	if m != nil {
+		return m.Index
+	}
+	return 0
+}
+
+func (m *TxOutput) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+func (m *TxOutput) GetScriptPubKey() []byte {
+	if m != nil {
+		return m.ScriptPubKey
+	}
+	return nil
+}
+
+func (m *TxOutput) GetValue() uint64 {
+	if m != nil {
+		return m.Value
+	}
+	return 0
+}
+
+func (m *TxOutput) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+func (m *TxOutput) GetOwner() OutputOwner {
+	if m != nil {
+		return m.Owner
+	}
+	return OutputOwner_EXTERNAL_OUTPUT
+}
+
+type TransactionFilter struct {
+	Coin                 CoinType             `protobuf:"varint,1,opt,name=coin,proto3,enum=pb.CoinType" json:"coin,omitempty"`
+	Label                string               `protobuf:"bytes,2,opt,name=label,proto3" json:"label,omitempty"`
+	From                 *timestamp.Timestamp `protobuf:"bytes,3,opt,name=from,proto3" json:"from,omitempty"`
+	To                   *timestamp.Timestamp `protobuf:"bytes,4,opt,name=to,proto3" json:"to,omitempty"`
+	MinHeight            int32                `protobuf:"varint,5,opt,name=minHeight,proto3" json:"minHeight,omitempty"`
+	MaxHeight            int32                `protobuf:"varint,6,opt,name=maxHeight,proto3" json:"maxHeight,omitempty"`
+	Direction            Direction            `protobuf:"varint,7,opt,name=direction,proto3,enum=pb.Direction" json:"direction,omitempty"`
+	Address              string               `protobuf:"bytes,8,opt,name=address,proto3" json:"address,omitempty"`
+	IncludeWatchOnly     bool                 `protobuf:"varint,9,opt,name=includeWatchOnly,proto3" json:"includeWatchOnly,omitempty"`
+	MinValue             uint64               `protobuf:"varint,10,opt,name=minValue,proto3" json:"minValue,omitempty"`
+	Cursor               string               `protobuf:"bytes,11,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	Limit                uint32               `protobuf:"varint,12,opt,name=limit,proto3" json:"limit,omitempty"`
+	CoinName             string               `protobuf:"bytes,13,opt,name=coinName,proto3" json:"coinName,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *TransactionFilter) Reset()         { *m = TransactionFilter{} }
+func (m *TransactionFilter) String() string { return proto.CompactTextString(m) }
+func (*TransactionFilter) ProtoMessage()    {}
+func (*TransactionFilter) Descriptor() ([]byte, []int) {
+	return fileDescriptor_api_d239b4462bcb8aa3, []int{19}
+}
+func (m *TransactionFilter) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TransactionFilter.Unmarshal(m, b)
+}
+func (m *TransactionFilter) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TransactionFilter.Marshal(b, m, deterministic)
+}
+func (dst *TransactionFilter) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TransactionFilter.Merge(dst, src)
+}
+func (m *TransactionFilter) XXX_Size() int {
+	return xxx_messageInfo_TransactionFilter.Size(m)
+}
+func (m *TransactionFilter) XXX_DiscardUnknown() {
+	xxx_messageInfo_TransactionFilter.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TransactionFilter proto.InternalMessageInfo
+
+func (m *TransactionFilter) GetCoin() CoinType {
+	if m != nil {
+		return m.Coin
+	}
+	return CoinType_BITCOIN
+}
+
+func (m *TransactionFilter) GetLabel() string {
+	if m != nil {
+		return m.Label
+	}
+	return ""
+}
+
+func (m *TransactionFilter) GetFrom() *timestamp.Timestamp {
+	if m != nil {
+		return m.From
+	}
+	return nil
+}
+
+func (m *TransactionFilter) GetTo() *timestamp.Timestamp {
+	if m != nil {
+		return m.To
+	}
+	return nil
+}
+
+func (m *TransactionFilter) GetMinHeight() int32 {
+	if m != nil {
+		return m.MinHeight
+	}
+	return 0
+}
+
+func (m *TransactionFilter) GetMaxHeight() int32 {
+	if m != nil {
+		return m.MaxHeight
+	}
+	return 0
+}
+
+func (m *TransactionFilter) GetDirection() Direction {
+	if m != nil {
+		return m.Direction
+	}
+	return Direction_ANY_DIRECTION
+}
+
+func (m *TransactionFilter) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+func (m *TransactionFilter) GetIncludeWatchOnly() bool {
+	if m != nil {
+		return m.IncludeWatchOnly
+	}
+	return false
+}
+
+func (m *TransactionFilter) GetMinValue() uint64 {
+	if m != nil {
+		return m.MinValue
+	}
+	return 0
+}
+
+func (m *TransactionFilter) GetCursor() string {
+	if m != nil {
+		return m.Cursor
+	}
+	return ""
+}
+
+func (m *TransactionFilter) GetLimit() uint32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+func (m *TransactionFilter) GetCoinName() string {
+	if m != nil {
+		return m.CoinName
+	}
+	return ""
+}
+
+type Txid struct {
+	Coin                 CoinType `protobuf:"varint,1,opt,name=coin,proto3,enum=pb.CoinType" json:"coin,omitempty"`
+	Hash                 string   `protobuf:"bytes,2,opt,name=hash,proto3" json:"hash,omitempty"`
+	CoinName             string   `protobuf:"bytes,3,opt,name=coinName,proto3" json:"coinName,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Txid) Reset()         { *m = Txid{} }
+func (m *Txid) String() string { return proto.CompactTextString(m) }
+func (*Txid) ProtoMessage()    {}
+func (*Txid) Descriptor() ([]byte, []int) {
+	return fileDescriptor_api_d239b4462bcb8aa3, []int{20}
+}
+func (m *Txid) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Txid.Unmarshal(m, b)
+}
+func (m *Txid) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Txid.Marshal(b, m, deterministic)
+}
+func (dst *Txid) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Txid.Merge(dst, src)
+}
+func (m *Txid) XXX_Size() int {
+	return xxx_messageInfo_Txid.Size(m)
+}
+func (m *Txid) XXX_DiscardUnknown() {
+	xxx_messageInfo_Txid.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Txid proto.InternalMessageInfo
+
+func (m *Txid) GetCoin() CoinType {
+	if m != nil {
+		return m.Coin
+	}
+	return CoinType_BITCOIN
+}
+
+func (m *Txid) GetHash() string {
+	if m != nil {
+		return m.Hash
+	}
+	return ""
+}
+
+func (m *Txid) GetCoinName() string {
+	if m != nil {
+		return m.CoinName
+	}
+	return ""
+}
+
+type FeeLevelSelection struct {
+	Coin                 CoinType `protobuf:"varint,1,opt,name=coin,proto3,enum=pb.CoinType" json:"coin,omitempty"`
 	FeeLevel             FeeLevel `protobuf:"varint,2,opt,name=feeLevel,proto3,enum=pb.FeeLevel" json:"feeLevel,omitempty"`
+	CoinName             string   `protobuf:"bytes,3,opt,name=coinName,proto3" json:"coinName,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -751,7 +1461,7 @@ func (m *FeeLevelSelection) Reset()         { *m = FeeLevelSelection{} }
 func (m *FeeLevelSelection) String() string { return proto.CompactTextString(m) }
 func (*FeeLevelSelection) ProtoMessage()    {}
 func (*FeeLevelSelection) Descriptor() ([]byte, []int) {
-	return fileDescriptor_api_2ff753dddd9b028a, []int{15}
+	return fileDescriptor_api_d239b4462bcb8aa3, []int{21}
 }
 func (m *FeeLevelSelection) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_FeeLevelSelection.Unmarshal(m, b)
@@ -785,6 +1495,13 @@ func (m *FeeLevelSelection) GetFeeLevel() FeeLevel {
 	return FeeLevel_ECONOMIC
 }
 
+func (m *FeeLevelSelection) GetCoinName() string {
+	if m != nil {
+		return m.CoinName
+	}
+	return ""
+}
+
 type FeePerByte struct {
 	Fee                  uint64   `protobuf:"varint,1,opt,name=fee,proto3" json:"fee,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
@@ -796,7 +1513,7 @@ func (m *FeePerByte) Reset()         { *m = FeePerByte{} }
 func (m *FeePerByte) String() string { return proto.CompactTextString(m) }
 func (*FeePerByte) ProtoMessage()    {}
 func (*FeePerByte) Descriptor() ([]byte, []int) {
-	return fileDescriptor_api_2ff753dddd9b028a, []int{16}
+	return fileDescriptor_api_d239b4462bcb8aa3, []int{22}
 }
 func (m *FeePerByte) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_FeePerByte.Unmarshal(m, b)
@@ -834,7 +1551,7 @@ func (m *Fee) Reset()         { *m = Fee{} }
 func (m *Fee) String() string { return proto.CompactTextString(m) }
 func (*Fee) ProtoMessage()    {}
 func (*Fee) Descriptor() ([]byte, []int) {
-	return fileDescriptor_api_2ff753dddd9b028a, []int{17}
+	return fileDescriptor_api_d239b4462bcb8aa3, []int{23}
 }
 func (m *Fee) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_Fee.Unmarshal(m, b)
@@ -867,6 +1584,12 @@ type SpendInfo struct {
 	Amount               uint64   `protobuf:"varint,3,opt,name=amount,proto3" json:"amount,omitempty"`
 	FeeLevel             FeeLevel `protobuf:"varint,4,opt,name=feeLevel,proto3,enum=pb.FeeLevel" json:"feeLevel,omitempty"`
 	Memo                 string   `protobuf:"bytes,5,opt,name=memo,proto3" json:"memo,omitempty"`
+	Data                 []byte   `protobuf:"bytes,6,opt,name=data,proto3" json:"data,omitempty"`
+	ReferenceID          string   `protobuf:"bytes,7,opt,name=referenceID,proto3" json:"referenceID,omitempty"`
+	Labels               []string `protobuf:"bytes,8,rep,name=labels,proto3" json:"labels,omitempty"`
+	RequestID            string   `protobuf:"bytes,9,opt,name=requestID,proto3" json:"requestID,omitempty"`
+	SpendAll             bool     `protobuf:"varint,10,opt,name=spendAll,proto3" json:"spendAll,omitempty"`
+	CoinName             string   `protobuf:"bytes,11,opt,name=coinName,proto3" json:"coinName,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -876,7 +1599,7 @@ func (m *SpendInfo) Reset()         { *m = SpendInfo{} }
 func (m *SpendInfo) String() string { return proto.CompactTextString(m) }
 func (*SpendInfo) ProtoMessage()    {}
 func (*SpendInfo) Descriptor() ([]byte, []int) {
-	return fileDescriptor_api_2ff753dddd9b028a, []int{18}
+	return fileDescriptor_api_d239b4462bcb8aa3, []int{24}
 }
 func (m *SpendInfo) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_SpendInfo.Unmarshal(m, b)
@@ -931,632 +1654,2254 @@ func (m *SpendInfo) GetMemo() string {
 	return ""
 }
 
-type Confirmations struct {
-	Confirmations        uint32   `protobuf:"varint,1,opt,name=confirmations,proto3" json:"confirmations,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+func (m *SpendInfo) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
 }
 
-func (m *Confirmations) Reset()         { *m = Confirmations{} }
-func (m *Confirmations) String() string { return proto.CompactTextString(m) }
-func (*Confirmations) ProtoMessage()    {}
-func (*Confirmations) Descriptor() ([]byte, []int) {
-	return fileDescriptor_api_2ff753dddd9b028a, []int{19}
-}
-func (m *Confirmations) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_Confirmations.Unmarshal(m, b)
-}
-func (m *Confirmations) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_Confirmations.Marshal(b, m, deterministic)
-}
-func (dst *Confirmations) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_Confirmations.Merge(dst, src)
+func (m *SpendInfo) GetReferenceID() string {
+	if m != nil {
+		return m.ReferenceID
+	}
+	return ""
 }
-func (m *Confirmations) XXX_Size() int {
-	return xxx_messageInfo_Confirmations.Size(m)
+
+func (m *SpendInfo) GetLabels() []string {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
 }
-func (m *Confirmations) XXX_DiscardUnknown() {
-	xxx_messageInfo_Confirmations.DiscardUnknown(m)
+
+func (m *SpendInfo) GetRequestID() string {
+	if m != nil {
+		return m.RequestID
+	}
+	return ""
 }
 
-var xxx_messageInfo_Confirmations proto.InternalMessageInfo
+func (m *SpendInfo) GetSpendAll() bool {
+	if m != nil {
+		return m.SpendAll
+	}
+	return false
+}
 
-func (m *Confirmations) GetConfirmations() uint32 {
+func (m *SpendInfo) GetCoinName() string {
 	if m != nil {
-		return m.Confirmations
+		return m.CoinName
 	}
-	return 0
+	return ""
 }
 
-type Utxo struct {
-	Txid                 string   `protobuf:"bytes,1,opt,name=txid,proto3" json:"txid,omitempty"`
-	Index                uint32   `protobuf:"varint,2,opt,name=index,proto3" json:"index,omitempty"`
-	Value                uint64   `protobuf:"varint,3,opt,name=value,proto3" json:"value,omitempty"`
+type FiatSpendInfo struct {
+	Coin                 CoinType `protobuf:"varint,1,opt,name=coin,proto3,enum=pb.CoinType" json:"coin,omitempty"`
+	Address              string   `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"`
+	Amount               float64  `protobuf:"fixed64,3,opt,name=amount,proto3" json:"amount,omitempty"`
+	Currency             string   `protobuf:"bytes,4,opt,name=currency,proto3" json:"currency,omitempty"`
+	FeeLevel             FeeLevel `protobuf:"varint,5,opt,name=feeLevel,proto3,enum=pb.FeeLevel" json:"feeLevel,omitempty"`
+	Memo                 string   `protobuf:"bytes,6,opt,name=memo,proto3" json:"memo,omitempty"`
+	ReferenceID          string   `protobuf:"bytes,7,opt,name=referenceID,proto3" json:"referenceID,omitempty"`
+	Labels               []string `protobuf:"bytes,8,rep,name=labels,proto3" json:"labels,omitempty"`
+	RequestID            string   `protobuf:"bytes,9,opt,name=requestID,proto3" json:"requestID,omitempty"`
+	MaxRateAge           uint32   `protobuf:"varint,10,opt,name=maxRateAge,proto3" json:"maxRateAge,omitempty"`
+	ExpectedRate         float64  `protobuf:"fixed64,11,opt,name=expectedRate,proto3" json:"expectedRate,omitempty"`
+	MaxSlippage          float64  `protobuf:"fixed64,12,opt,name=maxSlippage,proto3" json:"maxSlippage,omitempty"`
+	CoinName             string   `protobuf:"bytes,13,opt,name=coinName,proto3" json:"coinName,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *Utxo) Reset()         { *m = Utxo{} }
-func (m *Utxo) String() string { return proto.CompactTextString(m) }
-func (*Utxo) ProtoMessage()    {}
-func (*Utxo) Descriptor() ([]byte, []int) {
-	return fileDescriptor_api_2ff753dddd9b028a, []int{20}
+func (m *FiatSpendInfo) Reset()         { *m = FiatSpendInfo{} }
+func (m *FiatSpendInfo) String() string { return proto.CompactTextString(m) }
+func (*FiatSpendInfo) ProtoMessage()    {}
+func (*FiatSpendInfo) Descriptor() ([]byte, []int) {
+	return fileDescriptor_api_d239b4462bcb8aa3, []int{25}
 }
-func (m *Utxo) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_Utxo.Unmarshal(m, b)
+func (m *FiatSpendInfo) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FiatSpendInfo.Unmarshal(m, b)
 }
-func (m *Utxo) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_Utxo.Marshal(b, m, deterministic)
+func (m *FiatSpendInfo) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FiatSpendInfo.Marshal(b, m, deterministic)
 }
-func (dst *Utxo) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_Utxo.Merge(dst, src)
+func (dst *FiatSpendInfo) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FiatSpendInfo.Merge(dst, src)
 }
-func (m *Utxo) XXX_Size() int {
-	return xxx_messageInfo_Utxo.Size(m)
+func (m *FiatSpendInfo) XXX_Size() int {
+	return xxx_messageInfo_FiatSpendInfo.Size(m)
 }
-func (m *Utxo) XXX_DiscardUnknown() {
-	xxx_messageInfo_Utxo.DiscardUnknown(m)
+func (m *FiatSpendInfo) XXX_DiscardUnknown() {
+	xxx_messageInfo_FiatSpendInfo.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_Utxo proto.InternalMessageInfo
+var xxx_messageInfo_FiatSpendInfo proto.InternalMessageInfo
 
-func (m *Utxo) GetTxid() string {
+func (m *FiatSpendInfo) GetCoin() CoinType {
 	if m != nil {
-		return m.Txid
+		return m.Coin
 	}
-	return ""
+	return CoinType_BITCOIN
 }
 
-func (m *Utxo) GetIndex() uint32 {
+func (m *FiatSpendInfo) GetAddress() string {
 	if m != nil {
-		return m.Index
+		return m.Address
 	}
-	return 0
+	return ""
 }
 
-func (m *Utxo) GetValue() uint64 {
+func (m *FiatSpendInfo) GetAmount() float64 {
 	if m != nil {
-		return m.Value
+		return m.Amount
 	}
 	return 0
 }
 
-type SweepInfo struct {
-	Coin                 CoinType `protobuf:"varint,1,opt,name=coin,proto3,enum=pb.CoinType" json:"coin,omitempty"`
-	Utxos                []*Utxo  `protobuf:"bytes,2,rep,name=utxos,proto3" json:"utxos,omitempty"`
-	Address              string   `protobuf:"bytes,3,opt,name=address,proto3" json:"address,omitempty"`
-	Key                  string   `protobuf:"bytes,4,opt,name=key,proto3" json:"key,omitempty"`
-	RedeemScript         []byte   `protobuf:"bytes,5,opt,name=redeemScript,proto3" json:"redeemScript,omitempty"`
-	FeeLevel             FeeLevel `protobuf:"varint,6,opt,name=feeLevel,proto3,enum=pb.FeeLevel" json:"feeLevel,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+func (m *FiatSpendInfo) GetCurrency() string {
+	if m != nil {
+		return m.Currency
+	}
+	return ""
 }
 
-func (m *SweepInfo) Reset()         { *m = SweepInfo{} }
-func (m *SweepInfo) String() string { return proto.CompactTextString(m) }
-func (*SweepInfo) ProtoMessage()    {}
-func (*SweepInfo) Descriptor() ([]byte, []int) {
-	return fileDescriptor_api_2ff753dddd9b028a, []int{21}
-}
-func (m *SweepInfo) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_SweepInfo.Unmarshal(m, b)
-}
-func (m *SweepInfo) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_SweepInfo.Marshal(b, m, deterministic)
-}
-func (dst *SweepInfo) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_SweepInfo.Merge(dst, src)
-}
-func (m *SweepInfo) XXX_Size() int {
-	return xxx_messageInfo_SweepInfo.Size(m)
-}
-func (m *SweepInfo) XXX_DiscardUnknown() {
-	xxx_messageInfo_SweepInfo.DiscardUnknown(m)
+func (m *FiatSpendInfo) GetFeeLevel() FeeLevel {
+	if m != nil {
+		return m.FeeLevel
+	}
+	return FeeLevel_ECONOMIC
 }
 
-var xxx_messageInfo_SweepInfo proto.InternalMessageInfo
+func (m *FiatSpendInfo) GetMemo() string {
+	if m != nil {
+		return m.Memo
+	}
+	return ""
+}
 
-func (m *SweepInfo) GetCoin() CoinType {
+func (m *FiatSpendInfo) GetReferenceID() string {
 	if m != nil {
-		return m.Coin
+		return m.ReferenceID
 	}
-	return CoinType_BITCOIN
+	return ""
 }
 
-func (m *SweepInfo) GetUtxos() []*Utxo {
+func (m *FiatSpendInfo) GetLabels() []string {
 	if m != nil {
-		return m.Utxos
+		return m.Labels
 	}
 	return nil
 }
 
-func (m *SweepInfo) GetAddress() string {
+func (m *FiatSpendInfo) GetRequestID() string {
 	if m != nil {
-		return m.Address
+		return m.RequestID
 	}
 	return ""
 }
 
-func (m *SweepInfo) GetKey() string {
+func (m *FiatSpendInfo) GetMaxRateAge() uint32 {
 	if m != nil {
-		return m.Key
+		return m.MaxRateAge
 	}
-	return ""
+	return 0
 }
 
-func (m *SweepInfo) GetRedeemScript() []byte {
+func (m *FiatSpendInfo) GetExpectedRate() float64 {
 	if m != nil {
-		return m.RedeemScript
+		return m.ExpectedRate
 	}
-	return nil
+	return 0
 }
 
-func (m *SweepInfo) GetFeeLevel() FeeLevel {
+func (m *FiatSpendInfo) GetMaxSlippage() float64 {
 	if m != nil {
-		return m.FeeLevel
+		return m.MaxSlippage
 	}
-	return FeeLevel_ECONOMIC
+	return 0
 }
 
-type Input struct {
-	Txid                 string   `protobuf:"bytes,1,opt,name=txid,proto3" json:"txid,omitempty"`
-	Index                uint32   `protobuf:"varint,2,opt,name=index,proto3" json:"index,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+func (m *FiatSpendInfo) GetCoinName() string {
+	if m != nil {
+		return m.CoinName
+	}
+	return ""
 }
 
-func (m *Input) Reset()         { *m = Input{} }
-func (m *Input) String() string { return proto.CompactTextString(m) }
-func (*Input) ProtoMessage()    {}
-func (*Input) Descriptor() ([]byte, []int) {
-	return fileDescriptor_api_2ff753dddd9b028a, []int{22}
+type FiatSpendResult struct {
+	Coin                 CoinType             `protobuf:"varint,1,opt,name=coin,proto3,enum=pb.CoinType" json:"coin,omitempty"`
+	Hash                 string               `protobuf:"bytes,2,opt,name=hash,proto3" json:"hash,omitempty"`
+	Amount               uint64               `protobuf:"varint,3,opt,name=amount,proto3" json:"amount,omitempty"`
+	Currency             string               `protobuf:"bytes,4,opt,name=currency,proto3" json:"currency,omitempty"`
+	Rate                 float64              `protobuf:"fixed64,5,opt,name=rate,proto3" json:"rate,omitempty"`
+	RateTime             *timestamp.Timestamp `protobuf:"bytes,6,opt,name=rateTime,proto3" json:"rateTime,omitempty"`
+	CoinName             string               `protobuf:"bytes,7,opt,name=coinName,proto3" json:"coinName,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
 }
-func (m *Input) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_Input.Unmarshal(m, b)
+
+func (m *FiatSpendResult) Reset()         { *m = FiatSpendResult{} }
+func (m *FiatSpendResult) String() string { return proto.CompactTextString(m) }
+func (*FiatSpendResult) ProtoMessage()    {}
+func (*FiatSpendResult) Descriptor() ([]byte, []int) {
+	return fileDescriptor_api_d239b4462bcb8aa3, []int{26}
 }
-func (m *Input) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_Input.Marshal(b, m, deterministic)
+func (m *FiatSpendResult) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FiatSpendResult.Unmarshal(m, b)
 }
-func (dst *Input) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_Input.Merge(dst, src)
+func (m *FiatSpendResult) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FiatSpendResult.Marshal(b, m, deterministic)
 }
-func (m *Input) XXX_Size() int {
-	return xxx_messageInfo_Input.Size(m)
+func (dst *FiatSpendResult) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FiatSpendResult.Merge(dst, src)
 }
-func (m *Input) XXX_DiscardUnknown() {
-	xxx_messageInfo_Input.DiscardUnknown(m)
+func (m *FiatSpendResult) XXX_Size() int {
+	return xxx_messageInfo_FiatSpendResult.Size(m)
+}
+func (m *FiatSpendResult) XXX_DiscardUnknown() {
+	xxx_messageInfo_FiatSpendResult.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_Input proto.InternalMessageInfo
+var xxx_messageInfo_FiatSpendResult proto.InternalMessageInfo
 
-func (m *Input) GetTxid() string {
+func (m *FiatSpendResult) GetCoin() CoinType {
 	if m != nil {
-		return m.Txid
+		return m.Coin
+	}
+	return CoinType_BITCOIN
+}
+
+func (m *FiatSpendResult) GetHash() string {
+	if m != nil {
+		return m.Hash
 	}
 	return ""
 }
 
-func (m *Input) GetIndex() uint32 {
+func (m *FiatSpendResult) GetAmount() uint64 {
 	if m != nil {
-		return m.Index
+		return m.Amount
 	}
 	return 0
 }
 
-type Output struct {
-	ScriptPubKey         []byte   `protobuf:"bytes,1,opt,name=scriptPubKey,proto3" json:"scriptPubKey,omitempty"`
-	Value                uint64   `protobuf:"varint,2,opt,name=value,proto3" json:"value,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+func (m *FiatSpendResult) GetCurrency() string {
+	if m != nil {
+		return m.Currency
+	}
+	return ""
 }
 
-func (m *Output) Reset()         { *m = Output{} }
-func (m *Output) String() string { return proto.CompactTextString(m) }
-func (*Output) ProtoMessage()    {}
-func (*Output) Descriptor() ([]byte, []int) {
-	return fileDescriptor_api_2ff753dddd9b028a, []int{23}
-}
-func (m *Output) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_Output.Unmarshal(m, b)
-}
-func (m *Output) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_Output.Marshal(b, m, deterministic)
-}
-func (dst *Output) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_Output.Merge(dst, src)
-}
-func (m *Output) XXX_Size() int {
-	return xxx_messageInfo_Output.Size(m)
-}
-func (m *Output) XXX_DiscardUnknown() {
-	xxx_messageInfo_Output.DiscardUnknown(m)
+func (m *FiatSpendResult) GetRate() float64 {
+	if m != nil {
+		return m.Rate
+	}
+	return 0
 }
 
-var xxx_messageInfo_Output proto.InternalMessageInfo
-
-func (m *Output) GetScriptPubKey() []byte {
+func (m *FiatSpendResult) GetRateTime() *timestamp.Timestamp {
 	if m != nil {
-		return m.ScriptPubKey
+		return m.RateTime
 	}
 	return nil
 }
 
-func (m *Output) GetValue() uint64 {
+func (m *FiatSpendResult) GetCoinName() string {
 	if m != nil {
-		return m.Value
+		return m.CoinName
 	}
-	return 0
+	return ""
 }
 
-type Signature struct {
-	Index                uint32   `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
-	Signature            []byte   `protobuf:"bytes,2,opt,name=signature,proto3" json:"signature,omitempty"`
+type Payment struct {
+	Address              string   `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	Amount               uint64   `protobuf:"varint,2,opt,name=amount,proto3" json:"amount,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *Signature) Reset()         { *m = Signature{} }
-func (m *Signature) String() string { return proto.CompactTextString(m) }
-func (*Signature) ProtoMessage()    {}
-func (*Signature) Descriptor() ([]byte, []int) {
-	return fileDescriptor_api_2ff753dddd9b028a, []int{24}
+func (m *Payment) Reset()         { *m = Payment{} }
+func (m *Payment) String() string { return proto.CompactTextString(m) }
+func (*Payment) ProtoMessage()    {}
+func (*Payment) Descriptor() ([]byte, []int) {
+	return fileDescriptor_api_d239b4462bcb8aa3, []int{27}
 }
-func (m *Signature) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_Signature.Unmarshal(m, b)
+func (m *Payment) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Payment.Unmarshal(m, b)
 }
-func (m *Signature) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_Signature.Marshal(b, m, deterministic)
+func (m *Payment) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Payment.Marshal(b, m, deterministic)
 }
-func (dst *Signature) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_Signature.Merge(dst, src)
+func (dst *Payment) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Payment.Merge(dst, src)
 }
-func (m *Signature) XXX_Size() int {
-	return xxx_messageInfo_Signature.Size(m)
+func (m *Payment) XXX_Size() int {
+	return xxx_messageInfo_Payment.Size(m)
 }
-func (m *Signature) XXX_DiscardUnknown() {
-	xxx_messageInfo_Signature.DiscardUnknown(m)
+func (m *Payment) XXX_DiscardUnknown() {
+	xxx_messageInfo_Payment.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_Signature proto.InternalMessageInfo
+var xxx_messageInfo_Payment proto.InternalMessageInfo
 
-func (m *Signature) GetIndex() uint32 {
+func (m *Payment) GetAddress() string {
 	if m != nil {
-		return m.Index
+		return m.Address
 	}
-	return 0
+	return ""
 }
 
-func (m *Signature) GetSignature() []byte {
+func (m *Payment) GetAmount() uint64 {
 	if m != nil {
-		return m.Signature
+		return m.Amount
 	}
-	return nil
+	return 0
 }
 
-type CreateMultisigInfo struct {
-	Coin                 CoinType  `protobuf:"varint,1,opt,name=coin,proto3,enum=pb.CoinType" json:"coin,omitempty"`
-	Inputs               []*Input  `protobuf:"bytes,2,rep,name=inputs,proto3" json:"inputs,omitempty"`
-	Outputs              []*Output `protobuf:"bytes,3,rep,name=outputs,proto3" json:"outputs,omitempty"`
-	Key                  string    `protobuf:"bytes,4,opt,name=key,proto3" json:"key,omitempty"`
-	RedeemScript         []byte    `protobuf:"bytes,5,opt,name=redeemScript,proto3" json:"redeemScript,omitempty"`
-	FeePerByte           uint64    `protobuf:"varint,6,opt,name=feePerByte,proto3" json:"feePerByte,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
-	XXX_unrecognized     []byte    `json:"-"`
-	XXX_sizecache        int32     `json:"-"`
+type BatchSpendInfo struct {
+	Coin                 CoinType   `protobuf:"varint,1,opt,name=coin,proto3,enum=pb.CoinType" json:"coin,omitempty"`
+	Payments             []*Payment `protobuf:"bytes,2,rep,name=payments,proto3" json:"payments,omitempty"`
+	FeeLevel             FeeLevel   `protobuf:"varint,3,opt,name=feeLevel,proto3,enum=pb.FeeLevel" json:"feeLevel,omitempty"`
+	ReferenceID          string     `protobuf:"bytes,4,opt,name=referenceID,proto3" json:"referenceID,omitempty"`
+	RequestID            string     `protobuf:"bytes,5,opt,name=requestID,proto3" json:"requestID,omitempty"`
+	Memo                 string     `protobuf:"bytes,6,opt,name=memo,proto3" json:"memo,omitempty"`
+	Labels               []string   `protobuf:"bytes,7,rep,name=labels,proto3" json:"labels,omitempty"`
+	CoinName             string     `protobuf:"bytes,8,opt,name=coinName,proto3" json:"coinName,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
+	XXX_unrecognized     []byte     `json:"-"`
+	XXX_sizecache        int32      `json:"-"`
 }
 
-func (m *CreateMultisigInfo) Reset()         { *m = CreateMultisigInfo{} }
-func (m *CreateMultisigInfo) String() string { return proto.CompactTextString(m) }
-func (*CreateMultisigInfo) ProtoMessage()    {}
-func (*CreateMultisigInfo) Descriptor() ([]byte, []int) {
-	return fileDescriptor_api_2ff753dddd9b028a, []int{25}
+func (m *BatchSpendInfo) Reset()         { *m = BatchSpendInfo{} }
+func (m *BatchSpendInfo) String() string { return proto.CompactTextString(m) }
+func (*BatchSpendInfo) ProtoMessage()    {}
+func (*BatchSpendInfo) Descriptor() ([]byte, []int) {
+	return fileDescriptor_api_d239b4462bcb8aa3, []int{28}
 }
-func (m *CreateMultisigInfo) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_CreateMultisigInfo.Unmarshal(m, b)
+func (m *BatchSpendInfo) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BatchSpendInfo.Unmarshal(m, b)
 }
-func (m *CreateMultisigInfo) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_CreateMultisigInfo.Marshal(b, m, deterministic)
+func (m *BatchSpendInfo) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BatchSpendInfo.Marshal(b, m, deterministic)
 }
-func (dst *CreateMultisigInfo) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_CreateMultisigInfo.Merge(dst, src)
+func (dst *BatchSpendInfo) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BatchSpendInfo.Merge(dst, src)
 }
-func (m *CreateMultisigInfo) XXX_Size() int {
-	return xxx_messageInfo_CreateMultisigInfo.Size(m)
+func (m *BatchSpendInfo) XXX_Size() int {
+	return xxx_messageInfo_BatchSpendInfo.Size(m)
 }
-func (m *CreateMultisigInfo) XXX_DiscardUnknown() {
-	xxx_messageInfo_CreateMultisigInfo.DiscardUnknown(m)
+func (m *BatchSpendInfo) XXX_DiscardUnknown() {
+	xxx_messageInfo_BatchSpendInfo.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_CreateMultisigInfo proto.InternalMessageInfo
+var xxx_messageInfo_BatchSpendInfo proto.InternalMessageInfo
 
-func (m *CreateMultisigInfo) GetCoin() CoinType {
+func (m *BatchSpendInfo) GetCoin() CoinType {
 	if m != nil {
 		return m.Coin
 	}
 	return CoinType_BITCOIN
 }
 
-func (m *CreateMultisigInfo) GetInputs() []*Input {
+func (m *BatchSpendInfo) GetPayments() []*Payment {
 	if m != nil {
-		return m.Inputs
+		return m.Payments
 	}
 	return nil
 }
 
-func (m *CreateMultisigInfo) GetOutputs() []*Output {
+func (m *BatchSpendInfo) GetFeeLevel() FeeLevel {
 	if m != nil {
-		return m.Outputs
+		return m.FeeLevel
 	}
-	return nil
+	return FeeLevel_ECONOMIC
 }
 
-func (m *CreateMultisigInfo) GetKey() string {
+func (m *BatchSpendInfo) GetReferenceID() string {
 	if m != nil {
-		return m.Key
+		return m.ReferenceID
 	}
 	return ""
 }
 
-func (m *CreateMultisigInfo) GetRedeemScript() []byte {
+func (m *BatchSpendInfo) GetRequestID() string {
 	if m != nil {
-		return m.RedeemScript
+		return m.RequestID
 	}
-	return nil
+	return ""
 }
 
-func (m *CreateMultisigInfo) GetFeePerByte() uint64 {
+func (m *BatchSpendInfo) GetMemo() string {
 	if m != nil {
-		return m.FeePerByte
+		return m.Memo
 	}
-	return 0
+	return ""
 }
 
-type SignatureList struct {
-	Sigs                 []*Signature `protobuf:"bytes,1,rep,name=sigs,proto3" json:"sigs,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
-	XXX_unrecognized     []byte       `json:"-"`
-	XXX_sizecache        int32        `json:"-"`
+func (m *BatchSpendInfo) GetLabels() []string {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
 }
 
-func (m *SignatureList) Reset()         { *m = SignatureList{} }
-func (m *SignatureList) String() string { return proto.CompactTextString(m) }
-func (*SignatureList) ProtoMessage()    {}
+func (m *BatchSpendInfo) GetCoinName() string {
+	if m != nil {
+		return m.CoinName
+	}
+	return ""
+}
+
+type PlannedInput struct {
+	Txid                 string   `protobuf:"bytes,1,opt,name=txid,proto3" json:"txid,omitempty"`
+	Index                uint32   `protobuf:"varint,2,opt,name=index,proto3" json:"index,omitempty"`
+	Value                uint64   `protobuf:"varint,3,opt,name=value,proto3" json:"value,omitempty"`
+	Address              string   `protobuf:"bytes,4,opt,name=address,proto3" json:"address,omitempty"`
+	Sequence             uint32   `protobuf:"varint,5,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PlannedInput) Reset()         { *m = PlannedInput{} }
+func (m *PlannedInput) String() string { return proto.CompactTextString(m) }
+func (*PlannedInput) ProtoMessage()    {}
+func (*PlannedInput) Descriptor() ([]byte, []int) {
+	return fileDescriptor_api_d239b4462bcb8aa3, []int{29}
+}
+func (m *PlannedInput) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PlannedInput.Unmarshal(m, b)
+}
+func (m *PlannedInput) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PlannedInput.Marshal(b, m, deterministic)
+}
+func (dst *PlannedInput) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PlannedInput.Merge(dst, src)
+}
+func (m *PlannedInput) XXX_Size() int {
+	return xxx_messageInfo_PlannedInput.Size(m)
+}
+func (m *PlannedInput) XXX_DiscardUnknown() {
+	xxx_messageInfo_PlannedInput.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PlannedInput proto.InternalMessageInfo
+
+func (m *PlannedInput) GetTxid() string {
+	if m != nil {
+		return m.Txid
+	}
+	return ""
+}
+
+func (m *PlannedInput) GetIndex() uint32 {
+	if m != nil {
+		return m.Index
+	}
+	return 0
+}
+
+func (m *PlannedInput) GetValue() uint64 {
+	if m != nil {
+		return m.Value
+	}
+	return 0
+}
+
+func (m *PlannedInput) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+func (m *PlannedInput) GetSequence() uint32 {
+	if m != nil {
+		return m.Sequence
+	}
+	return 0
+}
+
+type PlannedOutput struct {
+	Address              string   `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	ScriptPubKey         []byte   `protobuf:"bytes,2,opt,name=scriptPubKey,proto3" json:"scriptPubKey,omitempty"`
+	Value                uint64   `protobuf:"varint,3,opt,name=value,proto3" json:"value,omitempty"`
+	Change               bool     `protobuf:"varint,4,opt,name=change,proto3" json:"change,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PlannedOutput) Reset()         { *m = PlannedOutput{} }
+func (m *PlannedOutput) String() string { return proto.CompactTextString(m) }
+func (*PlannedOutput) ProtoMessage()    {}
+func (*PlannedOutput) Descriptor() ([]byte, []int) {
+	return fileDescriptor_api_d239b4462bcb8aa3, []int{30}
+}
+func (m *PlannedOutput) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PlannedOutput.Unmarshal(m, b)
+}
+func (m *PlannedOutput) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PlannedOutput.Marshal(b, m, deterministic)
+}
+func (dst *PlannedOutput) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PlannedOutput.Merge(dst, src)
+}
+func (m *PlannedOutput) XXX_Size() int {
+	return xxx_messageInfo_PlannedOutput.Size(m)
+}
+func (m *PlannedOutput) XXX_DiscardUnknown() {
+	xxx_messageInfo_PlannedOutput.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PlannedOutput proto.InternalMessageInfo
+
+func (m *PlannedOutput) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+func (m *PlannedOutput) GetScriptPubKey() []byte {
+	if m != nil {
+		return m.ScriptPubKey
+	}
+	return nil
+}
+
+func (m *PlannedOutput) GetValue() uint64 {
+	if m != nil {
+		return m.Value
+	}
+	return 0
+}
+
+func (m *PlannedOutput) GetChange() bool {
+	if m != nil {
+		return m.Change
+	}
+	return false
+}
+
+type SpendPlan struct {
+	Coin                 CoinType         `protobuf:"varint,1,opt,name=coin,proto3,enum=pb.CoinType" json:"coin,omitempty"`
+	Inputs               []*PlannedInput  `protobuf:"bytes,2,rep,name=inputs,proto3" json:"inputs,omitempty"`
+	Outputs              []*PlannedOutput `protobuf:"bytes,3,rep,name=outputs,proto3" json:"outputs,omitempty"`
+	Fee                  uint64           `protobuf:"varint,4,opt,name=fee,proto3" json:"fee,omitempty"`
+	FeePerByte           uint64           `protobuf:"varint,5,opt,name=feePerByte,proto3" json:"feePerByte,omitempty"`
+	FeeRate              float64          `protobuf:"fixed64,6,opt,name=feeRate,proto3" json:"feeRate,omitempty"`
+	Vsize                uint64           `protobuf:"varint,7,opt,name=vsize,proto3" json:"vsize,omitempty"`
+	DustChangeDropped    bool             `protobuf:"varint,8,opt,name=dustChangeDropped,proto3" json:"dustChangeDropped,omitempty"`
+	DroppedChange        uint64           `protobuf:"varint,9,opt,name=droppedChange,proto3" json:"droppedChange,omitempty"`
+	CoinName             string           `protobuf:"bytes,10,opt,name=coinName,proto3" json:"coinName,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *SpendPlan) Reset()         { *m = SpendPlan{} }
+func (m *SpendPlan) String() string { return proto.CompactTextString(m) }
+func (*SpendPlan) ProtoMessage()    {}
+func (*SpendPlan) Descriptor() ([]byte, []int) {
+	return fileDescriptor_api_d239b4462bcb8aa3, []int{31}
+}
+func (m *SpendPlan) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SpendPlan.Unmarshal(m, b)
+}
+func (m *SpendPlan) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SpendPlan.Marshal(b, m, deterministic)
+}
+func (dst *SpendPlan) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SpendPlan.Merge(dst, src)
+}
+func (m *SpendPlan) XXX_Size() int {
+	return xxx_messageInfo_SpendPlan.Size(m)
+}
+func (m *SpendPlan) XXX_DiscardUnknown() {
+	xxx_messageInfo_SpendPlan.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SpendPlan proto.InternalMessageInfo
+
+func (m *SpendPlan) GetCoin() CoinType {
+	if m != nil {
+		return m.Coin
+	}
+	return CoinType_BITCOIN
+}
+
+func (m *SpendPlan) GetInputs() []*PlannedInput {
+	if m != nil {
+		return m.Inputs
+	}
+	return nil
+}
+
+func (m *SpendPlan) GetOutputs() []*PlannedOutput {
+	if m != nil {
+		return m.Outputs
+	}
+	return nil
+}
+
+func (m *SpendPlan) GetFee() uint64 {
+	if m != nil {
+		return m.Fee
+	}
+	return 0
+}
+
+func (m *SpendPlan) GetFeePerByte() uint64 {
+	if m != nil {
+		return m.FeePerByte
+	}
+	return 0
+}
+
+func (m *SpendPlan) GetFeeRate() float64 {
+	if m != nil {
+		return m.FeeRate
+	}
+	return 0
+}
+
+func (m *SpendPlan) GetVsize() uint64 {
+	if m != nil {
+		return m.Vsize
+	}
+	return 0
+}
+
+func (m *SpendPlan) GetDustChangeDropped() bool {
+	if m != nil {
+		return m.DustChangeDropped
+	}
+	return false
+}
+
+func (m *SpendPlan) GetDroppedChange() uint64 {
+	if m != nil {
+		return m.DroppedChange
+	}
+	return 0
+}
+
+func (m *SpendPlan) GetCoinName() string {
+	if m != nil {
+		return m.CoinName
+	}
+	return ""
+}
+
+type ExecutePlanInfo struct {
+	Plan                 *SpendPlan `protobuf:"bytes,1,opt,name=plan,proto3" json:"plan,omitempty"`
+	ReferenceID          string     `protobuf:"bytes,2,opt,name=referenceID,proto3" json:"referenceID,omitempty"`
+	RequestID            string     `protobuf:"bytes,3,opt,name=requestID,proto3" json:"requestID,omitempty"`
+	Memo                 string     `protobuf:"bytes,4,opt,name=memo,proto3" json:"memo,omitempty"`
+	Labels               []string   `protobuf:"bytes,5,rep,name=labels,proto3" json:"labels,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
+	XXX_unrecognized     []byte     `json:"-"`
+	XXX_sizecache        int32      `json:"-"`
+}
+
+func (m *ExecutePlanInfo) Reset()         { *m = ExecutePlanInfo{} }
+func (m *ExecutePlanInfo) String() string { return proto.CompactTextString(m) }
+func (*ExecutePlanInfo) ProtoMessage()    {}
+func (*ExecutePlanInfo) Descriptor() ([]byte, []int) {
+	return fileDescriptor_api_d239b4462bcb8aa3, []int{32}
+}
+func (m *ExecutePlanInfo) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ExecutePlanInfo.Unmarshal(m, b)
+}
+func (m *ExecutePlanInfo) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ExecutePlanInfo.Marshal(b, m, deterministic)
+}
+func (dst *ExecutePlanInfo) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ExecutePlanInfo.Merge(dst, src)
+}
+func (m *ExecutePlanInfo) XXX_Size() int {
+	return xxx_messageInfo_ExecutePlanInfo.Size(m)
+}
+func (m *ExecutePlanInfo) XXX_DiscardUnknown() {
+	xxx_messageInfo_ExecutePlanInfo.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ExecutePlanInfo proto.InternalMessageInfo
+
+func (m *ExecutePlanInfo) GetPlan() *SpendPlan {
+	if m != nil {
+		return m.Plan
+	}
+	return nil
+}
+
+func (m *ExecutePlanInfo) GetReferenceID() string {
+	if m != nil {
+		return m.ReferenceID
+	}
+	return ""
+}
+
+func (m *ExecutePlanInfo) GetRequestID() string {
+	if m != nil {
+		return m.RequestID
+	}
+	return ""
+}
+
+func (m *ExecutePlanInfo) GetMemo() string {
+	if m != nil {
+		return m.Memo
+	}
+	return ""
+}
+
+func (m *ExecutePlanInfo) GetLabels() []string {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
+}
+
+type SpendRequest struct {
+	Coin                 CoinType `protobuf:"varint,1,opt,name=coin,proto3,enum=pb.CoinType" json:"coin,omitempty"`
+	RequestID            string   `protobuf:"bytes,2,opt,name=requestID,proto3" json:"requestID,omitempty"`
+	CoinName             string   `protobuf:"bytes,3,opt,name=coinName,proto3" json:"coinName,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SpendRequest) Reset()         { *m = SpendRequest{} }
+func (m *SpendRequest) String() string { return proto.CompactTextString(m) }
+func (*SpendRequest) ProtoMessage()    {}
+func (*SpendRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_api_d239b4462bcb8aa3, []int{33}
+}
+func (m *SpendRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SpendRequest.Unmarshal(m, b)
+}
+func (m *SpendRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SpendRequest.Marshal(b, m, deterministic)
+}
+func (dst *SpendRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SpendRequest.Merge(dst, src)
+}
+func (m *SpendRequest) XXX_Size() int {
+	return xxx_messageInfo_SpendRequest.Size(m)
+}
+func (m *SpendRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SpendRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SpendRequest proto.InternalMessageInfo
+
+func (m *SpendRequest) GetCoin() CoinType {
+	if m != nil {
+		return m.Coin
+	}
+	return CoinType_BITCOIN
+}
+
+func (m *SpendRequest) GetRequestID() string {
+	if m != nil {
+		return m.RequestID
+	}
+	return ""
+}
+
+func (m *SpendRequest) GetCoinName() string {
+	if m != nil {
+		return m.CoinName
+	}
+	return ""
+}
+
+type RawTxInfo struct {
+	Coin                 CoinType `protobuf:"varint,1,opt,name=coin,proto3,enum=pb.CoinType" json:"coin,omitempty"`
+	Tx                   []byte   `protobuf:"bytes,2,opt,name=tx,proto3" json:"tx,omitempty"`
+	CoinName             string   `protobuf:"bytes,3,opt,name=coinName,proto3" json:"coinName,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RawTxInfo) Reset()         { *m = RawTxInfo{} }
+func (m *RawTxInfo) String() string { return proto.CompactTextString(m) }
+func (*RawTxInfo) ProtoMessage()    {}
+func (*RawTxInfo) Descriptor() ([]byte, []int) {
+	return fileDescriptor_api_d239b4462bcb8aa3, []int{34}
+}
+func (m *RawTxInfo) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RawTxInfo.Unmarshal(m, b)
+}
+func (m *RawTxInfo) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RawTxInfo.Marshal(b, m, deterministic)
+}
+func (dst *RawTxInfo) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RawTxInfo.Merge(dst, src)
+}
+func (m *RawTxInfo) XXX_Size() int {
+	return xxx_messageInfo_RawTxInfo.Size(m)
+}
+func (m *RawTxInfo) XXX_DiscardUnknown() {
+	xxx_messageInfo_RawTxInfo.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RawTxInfo proto.InternalMessageInfo
+
+func (m *RawTxInfo) GetCoin() CoinType {
+	if m != nil {
+		return m.Coin
+	}
+	return CoinType_BITCOIN
+}
+
+func (m *RawTxInfo) GetTx() []byte {
+	if m != nil {
+		return m.Tx
+	}
+	return nil
+}
+
+func (m *RawTxInfo) GetCoinName() string {
+	if m != nil {
+		return m.CoinName
+	}
+	return ""
+}
+
+type DecodedInput struct {
+	Txid                 string   `protobuf:"bytes,1,opt,name=txid,proto3" json:"txid,omitempty"`
+	Index                uint32   `protobuf:"varint,2,opt,name=index,proto3" json:"index,omitempty"`
+	Sequence             uint32   `protobuf:"varint,3,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	Address              string   `protobuf:"bytes,4,opt,name=address,proto3" json:"address,omitempty"`
+	Value                uint64   `protobuf:"varint,5,opt,name=value,proto3" json:"value,omitempty"`
+	Known                bool     `protobuf:"varint,6,opt,name=known,proto3" json:"known,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DecodedInput) Reset()         { *m = DecodedInput{} }
+func (m *DecodedInput) String() string { return proto.CompactTextString(m) }
+func (*DecodedInput) ProtoMessage()    {}
+func (*DecodedInput) Descriptor() ([]byte, []int) {
+	return fileDescriptor_api_d239b4462bcb8aa3, []int{35}
+}
+func (m *DecodedInput) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DecodedInput.Unmarshal(m, b)
+}
+func (m *DecodedInput) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DecodedInput.Marshal(b, m, deterministic)
+}
+func (dst *DecodedInput) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DecodedInput.Merge(dst, src)
+}
+func (m *DecodedInput) XXX_Size() int {
+	return xxx_messageInfo_DecodedInput.Size(m)
+}
+func (m *DecodedInput) XXX_DiscardUnknown() {
+	xxx_messageInfo_DecodedInput.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DecodedInput proto.InternalMessageInfo
+
+func (m *DecodedInput) GetTxid() string {
+	if m != nil {
+		return m.Txid
+	}
+	return ""
+}
+
+func (m *DecodedInput) GetIndex() uint32 {
+	if m != nil {
+		return m.Index
+	}
+	return 0
+}
+
+func (m *DecodedInput) GetSequence() uint32 {
+	if m != nil {
+		return m.Sequence
+	}
+	return 0
+}
+
+func (m *DecodedInput) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+func (m *DecodedInput) GetValue() uint64 {
+	if m != nil {
+		return m.Value
+	}
+	return 0
+}
+
+func (m *DecodedInput) GetKnown() bool {
+	if m != nil {
+		return m.Known
+	}
+	return false
+}
+
+type DecodedOutput struct {
+	Index                uint32   `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	Address              string   `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"`
+	ScriptPubKey         []byte   `protobuf:"bytes,3,opt,name=scriptPubKey,proto3" json:"scriptPubKey,omitempty"`
+	Value                uint64   `protobuf:"varint,4,opt,name=value,proto3" json:"value,omitempty"`
+	Data                 []byte   `protobuf:"bytes,5,opt,name=data,proto3" json:"data,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DecodedOutput) Reset()         { *m = DecodedOutput{} }
+func (m *DecodedOutput) String() string { return proto.CompactTextString(m) }
+func (*DecodedOutput) ProtoMessage()    {}
+func (*DecodedOutput) Descriptor() ([]byte, []int) {
+	return fileDescriptor_api_d239b4462bcb8aa3, []int{36}
+}
+func (m *DecodedOutput) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DecodedOutput.Unmarshal(m, b)
+}
+func (m *DecodedOutput) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DecodedOutput.Marshal(b, m, deterministic)
+}
+func (dst *DecodedOutput) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DecodedOutput.Merge(dst, src)
+}
+func (m *DecodedOutput) XXX_Size() int {
+	return xxx_messageInfo_DecodedOutput.Size(m)
+}
+func (m *DecodedOutput) XXX_DiscardUnknown() {
+	xxx_messageInfo_DecodedOutput.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DecodedOutput proto.InternalMessageInfo
+
+func (m *DecodedOutput) GetIndex() uint32 {
+	if m != nil {
+		return m.Index
+	}
+	return 0
+}
+
+func (m *DecodedOutput) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+func (m *DecodedOutput) GetScriptPubKey() []byte {
+	if m != nil {
+		return m.ScriptPubKey
+	}
+	return nil
+}
+
+func (m *DecodedOutput) GetValue() uint64 {
+	if m != nil {
+		return m.Value
+	}
+	return 0
+}
+
+func (m *DecodedOutput) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+type DecodedTx struct {
+	Coin                 CoinType         `protobuf:"varint,1,opt,name=coin,proto3,enum=pb.CoinType" json:"coin,omitempty"`
+	Txid                 string           `protobuf:"bytes,2,opt,name=txid,proto3" json:"txid,omitempty"`
+	Version              int32            `protobuf:"varint,3,opt,name=version,proto3" json:"version,omitempty"`
+	Locktime             uint32           `protobuf:"varint,4,opt,name=locktime,proto3" json:"locktime,omitempty"`
+	ExpiryHeight         uint32           `protobuf:"varint,5,opt,name=expiryHeight,proto3" json:"expiryHeight,omitempty"`
+	Size                 uint64           `protobuf:"varint,6,opt,name=size,proto3" json:"size,omitempty"`
+	Vsize                uint64           `protobuf:"varint,7,opt,name=vsize,proto3" json:"vsize,omitempty"`
+	Inputs               []*DecodedInput  `protobuf:"bytes,8,rep,name=inputs,proto3" json:"inputs,omitempty"`
+	Outputs              []*DecodedOutput `protobuf:"bytes,9,rep,name=outputs,proto3" json:"outputs,omitempty"`
+	Fee                  uint64           `protobuf:"varint,10,opt,name=fee,proto3" json:"fee,omitempty"`
+	FeeKnown             bool             `protobuf:"varint,11,opt,name=feeKnown,proto3" json:"feeKnown,omitempty"`
+	Rbf                  bool             `protobuf:"varint,12,opt,name=rbf,proto3" json:"rbf,omitempty"`
+	CoinName             string           `protobuf:"bytes,13,opt,name=coinName,proto3" json:"coinName,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *DecodedTx) Reset()         { *m = DecodedTx{} }
+func (m *DecodedTx) String() string { return proto.CompactTextString(m) }
+func (*DecodedTx) ProtoMessage()    {}
+func (*DecodedTx) Descriptor() ([]byte, []int) {
+	return fileDescriptor_api_d239b4462bcb8aa3, []int{37}
+}
+func (m *DecodedTx) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DecodedTx.Unmarshal(m, b)
+}
+func (m *DecodedTx) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DecodedTx.Marshal(b, m, deterministic)
+}
+func (dst *DecodedTx) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DecodedTx.Merge(dst, src)
+}
+func (m *DecodedTx) XXX_Size() int {
+	return xxx_messageInfo_DecodedTx.Size(m)
+}
+func (m *DecodedTx) XXX_DiscardUnknown() {
+	xxx_messageInfo_DecodedTx.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DecodedTx proto.InternalMessageInfo
+
+func (m *DecodedTx) GetCoin() CoinType {
+	if m != nil {
+		return m.Coin
+	}
+	return CoinType_BITCOIN
+}
+
+func (m *DecodedTx) GetTxid() string {
+	if m != nil {
+		return m.Txid
+	}
+	return ""
+}
+
+func (m *DecodedTx) GetVersion() int32 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+func (m *DecodedTx) GetLocktime() uint32 {
+	if m != nil {
+		return m.Locktime
+	}
+	return 0
+}
+
+func (m *DecodedTx) GetExpiryHeight() uint32 {
+	if m != nil {
+		return m.ExpiryHeight
+	}
+	return 0
+}
+
+func (m *DecodedTx) GetSize() uint64 {
+	if m != nil {
+		return m.Size
+	}
+	return 0
+}
+
+func (m *DecodedTx) GetVsize() uint64 {
+	if m != nil {
+		return m.Vsize
+	}
+	return 0
+}
+
+func (m *DecodedTx) GetInputs() []*DecodedInput {
+	if m != nil {
+		return m.Inputs
+	}
+	return nil
+}
+
+func (m *DecodedTx) GetOutputs() []*DecodedOutput {
+	if m != nil {
+		return m.Outputs
+	}
+	return nil
+}
+
+func (m *DecodedTx) GetFee() uint64 {
+	if m != nil {
+		return m.Fee
+	}
+	return 0
+}
+
+func (m *DecodedTx) GetFeeKnown() bool {
+	if m != nil {
+		return m.FeeKnown
+	}
+	return false
+}
+
+func (m *DecodedTx) GetRbf() bool {
+	if m != nil {
+		return m.Rbf
+	}
+	return false
+}
+
+func (m *DecodedTx) GetCoinName() string {
+	if m != nil {
+		return m.CoinName
+	}
+	return ""
+}
+
+type Confirmations struct {
+	Confirmations        uint32   `protobuf:"varint,1,opt,name=confirmations,proto3" json:"confirmations,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Confirmations) Reset()         { *m = Confirmations{} }
+func (m *Confirmations) String() string { return proto.CompactTextString(m) }
+func (*Confirmations) ProtoMessage()    {}
+func (*Confirmations) Descriptor() ([]byte, []int) {
+	return fileDescriptor_api_d239b4462bcb8aa3, []int{38}
+}
+func (m *Confirmations) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Confirmations.Unmarshal(m, b)
+}
+func (m *Confirmations) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Confirmations.Marshal(b, m, deterministic)
+}
+func (dst *Confirmations) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Confirmations.Merge(dst, src)
+}
+func (m *Confirmations) XXX_Size() int {
+	return xxx_messageInfo_Confirmations.Size(m)
+}
+func (m *Confirmations) XXX_DiscardUnknown() {
+	xxx_messageInfo_Confirmations.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Confirmations proto.InternalMessageInfo
+
+func (m *Confirmations) GetConfirmations() uint32 {
+	if m != nil {
+		return m.Confirmations
+	}
+	return 0
+}
+
+type Utxo struct {
+	Txid                 string   `protobuf:"bytes,1,opt,name=txid,proto3" json:"txid,omitempty"`
+	Index                uint32   `protobuf:"varint,2,opt,name=index,proto3" json:"index,omitempty"`
+	Value                uint64   `protobuf:"varint,3,opt,name=value,proto3" json:"value,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Utxo) Reset()         { *m = Utxo{} }
+func (m *Utxo) String() string { return proto.CompactTextString(m) }
+func (*Utxo) ProtoMessage()    {}
+func (*Utxo) Descriptor() ([]byte, []int) {
+	return fileDescriptor_api_d239b4462bcb8aa3, []int{39}
+}
+func (m *Utxo) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Utxo.Unmarshal(m, b)
+}
+func (m *Utxo) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Utxo.Marshal(b, m, deterministic)
+}
+func (dst *Utxo) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Utxo.Merge(dst, src)
+}
+func (m *Utxo) XXX_Size() int {
+	return xxx_messageInfo_Utxo.Size(m)
+}
+func (m *Utxo) XXX_DiscardUnknown() {
+	xxx_messageInfo_Utxo.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Utxo proto.InternalMessageInfo
+
+func (m *Utxo) GetTxid() string {
+	if m != nil {
+		return m.Txid
+	}
+	return ""
+}
+
+func (m *Utxo) GetIndex() uint32 {
+	if m != nil {
+		return m.Index
+	}
+	return 0
+}
+
+func (m *Utxo) GetValue() uint64 {
+	if m != nil {
+		return m.Value
+	}
+	return 0
+}
+
+type SweepInfo struct {
+	Coin                 CoinType `protobuf:"varint,1,opt,name=coin,proto3,enum=pb.CoinType" json:"coin,omitempty"`
+	Utxos                []*Utxo  `protobuf:"bytes,2,rep,name=utxos,proto3" json:"utxos,omitempty"`
+	Address              string   `protobuf:"bytes,3,opt,name=address,proto3" json:"address,omitempty"`
+	Key                  string   `protobuf:"bytes,4,opt,name=key,proto3" json:"key,omitempty"`
+	RedeemScript         []byte   `protobuf:"bytes,5,opt,name=redeemScript,proto3" json:"redeemScript,omitempty"`
+	FeeLevel             FeeLevel `protobuf:"varint,6,opt,name=feeLevel,proto3,enum=pb.FeeLevel" json:"feeLevel,omitempty"`
+	CoinName             string   `protobuf:"bytes,7,opt,name=coinName,proto3" json:"coinName,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SweepInfo) Reset()         { *m = SweepInfo{} }
+func (m *SweepInfo) String() string { return proto.CompactTextString(m) }
+func (*SweepInfo) ProtoMessage()    {}
+func (*SweepInfo) Descriptor() ([]byte, []int) {
+	return fileDescriptor_api_d239b4462bcb8aa3, []int{40}
+}
+func (m *SweepInfo) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SweepInfo.Unmarshal(m, b)
+}
+func (m *SweepInfo) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SweepInfo.Marshal(b, m, deterministic)
+}
+func (dst *SweepInfo) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SweepInfo.Merge(dst, src)
+}
+func (m *SweepInfo) XXX_Size() int {
+	return xxx_messageInfo_SweepInfo.Size(m)
+}
+func (m *SweepInfo) XXX_DiscardUnknown() {
+	xxx_messageInfo_SweepInfo.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SweepInfo proto.InternalMessageInfo
+
+func (m *SweepInfo) GetCoin() CoinType {
+	if m != nil {
+		return m.Coin
+	}
+	return CoinType_BITCOIN
+}
+
+func (m *SweepInfo) GetUtxos() []*Utxo {
+	if m != nil {
+		return m.Utxos
+	}
+	return nil
+}
+
+func (m *SweepInfo) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+func (m *SweepInfo) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *SweepInfo) GetRedeemScript() []byte {
+	if m != nil {
+		return m.RedeemScript
+	}
+	return nil
+}
+
+func (m *SweepInfo) GetFeeLevel() FeeLevel {
+	if m != nil {
+		return m.FeeLevel
+	}
+	return FeeLevel_ECONOMIC
+}
+
+func (m *SweepInfo) GetCoinName() string {
+	if m != nil {
+		return m.CoinName
+	}
+	return ""
+}
+
+type Input struct {
+	Txid                 string   `protobuf:"bytes,1,opt,name=txid,proto3" json:"txid,omitempty"`
+	Index                uint32   `protobuf:"varint,2,opt,name=index,proto3" json:"index,omitempty"`
+	Value                uint64   `protobuf:"varint,3,opt,name=value,proto3" json:"value,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Input) Reset()         { *m = Input{} }
+func (m *Input) String() string { return proto.CompactTextString(m) }
+func (*Input) ProtoMessage()    {}
+func (*Input) Descriptor() ([]byte, []int) {
+	return fileDescriptor_api_d239b4462bcb8aa3, []int{41}
+}
+func (m *Input) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Input.Unmarshal(m, b)
+}
+func (m *Input) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Input.Marshal(b, m, deterministic)
+}
+func (dst *Input) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Input.Merge(dst, src)
+}
+func (m *Input) XXX_Size() int {
+	return xxx_messageInfo_Input.Size(m)
+}
+func (m *Input) XXX_DiscardUnknown() {
+	xxx_messageInfo_Input.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Input proto.InternalMessageInfo
+
+func (m *Input) GetTxid() string {
+	if m != nil {
+		return m.Txid
+	}
+	return ""
+}
+
+func (m *Input) GetIndex() uint32 {
+	if m != nil {
+		return m.Index
+	}
+	return 0
+}
+
+func (m *Input) GetValue() uint64 {
+	if m != nil {
+		return m.Value
+	}
+	return 0
+}
+
+type Output struct {
+	ScriptPubKey         []byte   `protobuf:"bytes,1,opt,name=scriptPubKey,proto3" json:"scriptPubKey,omitempty"`
+	Value                uint64   `protobuf:"varint,2,opt,name=value,proto3" json:"value,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Output) Reset()         { *m = Output{} }
+func (m *Output) String() string { return proto.CompactTextString(m) }
+func (*Output) ProtoMessage()    {}
+func (*Output) Descriptor() ([]byte, []int) {
+	return fileDescriptor_api_d239b4462bcb8aa3, []int{42}
+}
+func (m *Output) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Output.Unmarshal(m, b)
+}
+func (m *Output) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Output.Marshal(b, m, deterministic)
+}
+func (dst *Output) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Output.Merge(dst, src)
+}
+func (m *Output) XXX_Size() int {
+	return xxx_messageInfo_Output.Size(m)
+}
+func (m *Output) XXX_DiscardUnknown() {
+	xxx_messageInfo_Output.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Output proto.InternalMessageInfo
+
+func (m *Output) GetScriptPubKey() []byte {
+	if m != nil {
+		return m.ScriptPubKey
+	}
+	return nil
+}
+
+func (m *Output) GetValue() uint64 {
+	if m != nil {
+		return m.Value
+	}
+	return 0
+}
+
+type Signature struct {
+	Index                uint32   `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	Signature            []byte   `protobuf:"bytes,2,opt,name=signature,proto3" json:"signature,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Signature) Reset()         { *m = Signature{} }
+func (m *Signature) String() string { return proto.CompactTextString(m) }
+func (*Signature) ProtoMessage()    {}
+func (*Signature) Descriptor() ([]byte, []int) {
+	return fileDescriptor_api_d239b4462bcb8aa3, []int{43}
+}
+func (m *Signature) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Signature.Unmarshal(m, b)
+}
+func (m *Signature) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Signature.Marshal(b, m, deterministic)
+}
+func (dst *Signature) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Signature.Merge(dst, src)
+}
+func (m *Signature) XXX_Size() int {
+	return xxx_messageInfo_Signature.Size(m)
+}
+func (m *Signature) XXX_DiscardUnknown() {
+	xxx_messageInfo_Signature.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Signature proto.InternalMessageInfo
+
+func (m *Signature) GetIndex() uint32 {
+	if m != nil {
+		return m.Index
+	}
+	return 0
+}
+
+func (m *Signature) GetSignature() []byte {
+	if m != nil {
+		return m.Signature
+	}
+	return nil
+}
+
+type CreateMultisigInfo struct {
+	Coin                 CoinType  `protobuf:"varint,1,opt,name=coin,proto3,enum=pb.CoinType" json:"coin,omitempty"`
+	Inputs               []*Input  `protobuf:"bytes,2,rep,name=inputs,proto3" json:"inputs,omitempty"`
+	Outputs              []*Output `protobuf:"bytes,3,rep,name=outputs,proto3" json:"outputs,omitempty"`
+	Key                  string    `protobuf:"bytes,4,opt,name=key,proto3" json:"key,omitempty"`
+	RedeemScript         []byte    `protobuf:"bytes,5,opt,name=redeemScript,proto3" json:"redeemScript,omitempty"`
+	FeePerByte           uint64    `protobuf:"varint,6,opt,name=feePerByte,proto3" json:"feePerByte,omitempty"`
+	CoinName             string    `protobuf:"bytes,7,opt,name=coinName,proto3" json:"coinName,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *CreateMultisigInfo) Reset()         { *m = CreateMultisigInfo{} }
+func (m *CreateMultisigInfo) String() string { return proto.CompactTextString(m) }
+func (*CreateMultisigInfo) ProtoMessage()    {}
+func (*CreateMultisigInfo) Descriptor() ([]byte, []int) {
+	return fileDescriptor_api_d239b4462bcb8aa3, []int{44}
+}
+func (m *CreateMultisigInfo) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CreateMultisigInfo.Unmarshal(m, b)
+}
+func (m *CreateMultisigInfo) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CreateMultisigInfo.Marshal(b, m, deterministic)
+}
+func (dst *CreateMultisigInfo) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CreateMultisigInfo.Merge(dst, src)
+}
+func (m *CreateMultisigInfo) XXX_Size() int {
+	return xxx_messageInfo_CreateMultisigInfo.Size(m)
+}
+func (m *CreateMultisigInfo) XXX_DiscardUnknown() {
+	xxx_messageInfo_CreateMultisigInfo.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CreateMultisigInfo proto.InternalMessageInfo
+
+func (m *CreateMultisigInfo) GetCoin() CoinType {
+	if m != nil {
+		return m.Coin
+	}
+	return CoinType_BITCOIN
+}
+
+func (m *CreateMultisigInfo) GetInputs() []*Input {
+	if m != nil {
+		return m.Inputs
+	}
+	return nil
+}
+
+func (m *CreateMultisigInfo) GetOutputs() []*Output {
+	if m != nil {
+		return m.Outputs
+	}
+	return nil
+}
+
+func (m *CreateMultisigInfo) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *CreateMultisigInfo) GetRedeemScript() []byte {
+	if m != nil {
+		return m.RedeemScript
+	}
+	return nil
+}
+
+func (m *CreateMultisigInfo) GetFeePerByte() uint64 {
+	if m != nil {
+		return m.FeePerByte
+	}
+	return 0
+}
+
+func (m *CreateMultisigInfo) GetCoinName() string {
+	if m != nil {
+		return m.CoinName
+	}
+	return ""
+}
+
+type SignatureList struct {
+	Sigs                 []*Signature `protobuf:"bytes,1,rep,name=sigs,proto3" json:"sigs,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *SignatureList) Reset()         { *m = SignatureList{} }
+func (m *SignatureList) String() string { return proto.CompactTextString(m) }
+func (*SignatureList) ProtoMessage()    {}
 func (*SignatureList) Descriptor() ([]byte, []int) {
-	return fileDescriptor_api_2ff753dddd9b028a, []int{26}
+	return fileDescriptor_api_d239b4462bcb8aa3, []int{45}
+}
+func (m *SignatureList) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SignatureList.Unmarshal(m, b)
+}
+func (m *SignatureList) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SignatureList.Marshal(b, m, deterministic)
+}
+func (dst *SignatureList) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SignatureList.Merge(dst, src)
+}
+func (m *SignatureList) XXX_Size() int {
+	return xxx_messageInfo_SignatureList.Size(m)
+}
+func (m *SignatureList) XXX_DiscardUnknown() {
+	xxx_messageInfo_SignatureList.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SignatureList proto.InternalMessageInfo
+
+func (m *SignatureList) GetSigs() []*Signature {
+	if m != nil {
+		return m.Sigs
+	}
+	return nil
+}
+
+type CosignerSignatures struct {
+	PubKey               []byte       `protobuf:"bytes,1,opt,name=pubKey,proto3" json:"pubKey,omitempty"`
+	Sigs                 []*Signature `protobuf:"bytes,2,rep,name=sigs,proto3" json:"sigs,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *CosignerSignatures) Reset()         { *m = CosignerSignatures{} }
+func (m *CosignerSignatures) String() string { return proto.CompactTextString(m) }
+func (*CosignerSignatures) ProtoMessage()    {}
+func (*CosignerSignatures) Descriptor() ([]byte, []int) {
+	return fileDescriptor_api_d239b4462bcb8aa3, []int{46}
+}
+func (m *CosignerSignatures) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CosignerSignatures.Unmarshal(m, b)
+}
+func (m *CosignerSignatures) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CosignerSignatures.Marshal(b, m, deterministic)
+}
+func (dst *CosignerSignatures) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CosignerSignatures.Merge(dst, src)
+}
+func (m *CosignerSignatures) XXX_Size() int {
+	return xxx_messageInfo_CosignerSignatures.Size(m)
+}
+func (m *CosignerSignatures) XXX_DiscardUnknown() {
+	xxx_messageInfo_CosignerSignatures.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CosignerSignatures proto.InternalMessageInfo
+
+func (m *CosignerSignatures) GetPubKey() []byte {
+	if m != nil {
+		return m.PubKey
+	}
+	return nil
+}
+
+func (m *CosignerSignatures) GetSigs() []*Signature {
+	if m != nil {
+		return m.Sigs
+	}
+	return nil
+}
+
+type MultisignInfo struct {
+	Coin                 CoinType              `protobuf:"varint,1,opt,name=coin,proto3,enum=pb.CoinType" json:"coin,omitempty"`
+	Inputs               []*Input              `protobuf:"bytes,2,rep,name=inputs,proto3" json:"inputs,omitempty"`
+	Outputs              []*Output             `protobuf:"bytes,3,rep,name=outputs,proto3" json:"outputs,omitempty"`
+	Sig1                 []*Signature          `protobuf:"bytes,4,rep,name=sig1,proto3" json:"sig1,omitempty"`
+	Sig2                 []*Signature          `protobuf:"bytes,5,rep,name=sig2,proto3" json:"sig2,omitempty"`
+	RedeemScript         []byte                `protobuf:"bytes,6,opt,name=redeemScript,proto3" json:"redeemScript,omitempty"`
+	FeePerByte           uint64                `protobuf:"varint,7,opt,name=feePerByte,proto3" json:"feePerByte,omitempty"`
+	Broadcast            bool                  `protobuf:"varint,8,opt,name=broadcast,proto3" json:"broadcast,omitempty"`
+	Cosigners            []*CosignerSignatures `protobuf:"bytes,9,rep,name=cosigners,proto3" json:"cosigners,omitempty"`
+	CoinName             string                `protobuf:"bytes,10,opt,name=coinName,proto3" json:"coinName,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *MultisignInfo) Reset()         { *m = MultisignInfo{} }
+func (m *MultisignInfo) String() string { return proto.CompactTextString(m) }
+func (*MultisignInfo) ProtoMessage()    {}
+func (*MultisignInfo) Descriptor() ([]byte, []int) {
+	return fileDescriptor_api_d239b4462bcb8aa3, []int{47}
+}
+func (m *MultisignInfo) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_MultisignInfo.Unmarshal(m, b)
+}
+func (m *MultisignInfo) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_MultisignInfo.Marshal(b, m, deterministic)
+}
+func (dst *MultisignInfo) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MultisignInfo.Merge(dst, src)
+}
+func (m *MultisignInfo) XXX_Size() int {
+	return xxx_messageInfo_MultisignInfo.Size(m)
+}
+func (m *MultisignInfo) XXX_DiscardUnknown() {
+	xxx_messageInfo_MultisignInfo.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MultisignInfo proto.InternalMessageInfo
+
+func (m *MultisignInfo) GetCoin() CoinType {
+	if m != nil {
+		return m.Coin
+	}
+	return CoinType_BITCOIN
+}
+
+func (m *MultisignInfo) GetInputs() []*Input {
+	if m != nil {
+		return m.Inputs
+	}
+	return nil
+}
+
+func (m *MultisignInfo) GetOutputs() []*Output {
+	if m != nil {
+		return m.Outputs
+	}
+	return nil
+}
+
+func (m *MultisignInfo) GetSig1() []*Signature {
+	if m != nil {
+		return m.Sig1
+	}
+	return nil
 }
-func (m *SignatureList) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_SignatureList.Unmarshal(m, b)
+
+func (m *MultisignInfo) GetSig2() []*Signature {
+	if m != nil {
+		return m.Sig2
+	}
+	return nil
+}
+
+func (m *MultisignInfo) GetRedeemScript() []byte {
+	if m != nil {
+		return m.RedeemScript
+	}
+	return nil
+}
+
+func (m *MultisignInfo) GetFeePerByte() uint64 {
+	if m != nil {
+		return m.FeePerByte
+	}
+	return 0
+}
+
+func (m *MultisignInfo) GetBroadcast() bool {
+	if m != nil {
+		return m.Broadcast
+	}
+	return false
+}
+
+func (m *MultisignInfo) GetCosigners() []*CosignerSignatures {
+	if m != nil {
+		return m.Cosigners
+	}
+	return nil
+}
+
+func (m *MultisignInfo) GetCoinName() string {
+	if m != nil {
+		return m.CoinName
+	}
+	return ""
+}
+
+type MergeMultisigInfo struct {
+	Coin                 CoinType `protobuf:"varint,1,opt,name=coin,proto3,enum=pb.CoinType" json:"coin,omitempty"`
+	Txs                  [][]byte `protobuf:"bytes,2,rep,name=txs,proto3" json:"txs,omitempty"`
+	RedeemScript         []byte   `protobuf:"bytes,3,opt,name=redeemScript,proto3" json:"redeemScript,omitempty"`
+	Broadcast            bool     `protobuf:"varint,4,opt,name=broadcast,proto3" json:"broadcast,omitempty"`
+	CoinName             string   `protobuf:"bytes,5,opt,name=coinName,proto3" json:"coinName,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *MergeMultisigInfo) Reset()         { *m = MergeMultisigInfo{} }
+func (m *MergeMultisigInfo) String() string { return proto.CompactTextString(m) }
+func (*MergeMultisigInfo) ProtoMessage()    {}
+func (*MergeMultisigInfo) Descriptor() ([]byte, []int) {
+	return fileDescriptor_api_d239b4462bcb8aa3, []int{48}
+}
+func (m *MergeMultisigInfo) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_MergeMultisigInfo.Unmarshal(m, b)
+}
+func (m *MergeMultisigInfo) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_MergeMultisigInfo.Marshal(b, m, deterministic)
+}
+func (dst *MergeMultisigInfo) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MergeMultisigInfo.Merge(dst, src)
+}
+func (m *MergeMultisigInfo) XXX_Size() int {
+	return xxx_messageInfo_MergeMultisigInfo.Size(m)
+}
+func (m *MergeMultisigInfo) XXX_DiscardUnknown() {
+	xxx_messageInfo_MergeMultisigInfo.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MergeMultisigInfo proto.InternalMessageInfo
+
+func (m *MergeMultisigInfo) GetCoin() CoinType {
+	if m != nil {
+		return m.Coin
+	}
+	return CoinType_BITCOIN
+}
+
+func (m *MergeMultisigInfo) GetTxs() [][]byte {
+	if m != nil {
+		return m.Txs
+	}
+	return nil
+}
+
+func (m *MergeMultisigInfo) GetRedeemScript() []byte {
+	if m != nil {
+		return m.RedeemScript
+	}
+	return nil
+}
+
+func (m *MergeMultisigInfo) GetBroadcast() bool {
+	if m != nil {
+		return m.Broadcast
+	}
+	return false
+}
+
+func (m *MergeMultisigInfo) GetCoinName() string {
+	if m != nil {
+		return m.CoinName
+	}
+	return ""
+}
+
+type RawTx struct {
+	Tx                   []byte   `protobuf:"bytes,1,opt,name=tx,proto3" json:"tx,omitempty"`
+	Complete             bool     `protobuf:"varint,2,opt,name=complete,proto3" json:"complete,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RawTx) Reset()         { *m = RawTx{} }
+func (m *RawTx) String() string { return proto.CompactTextString(m) }
+func (*RawTx) ProtoMessage()    {}
+func (*RawTx) Descriptor() ([]byte, []int) {
+	return fileDescriptor_api_d239b4462bcb8aa3, []int{49}
+}
+func (m *RawTx) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RawTx.Unmarshal(m, b)
+}
+func (m *RawTx) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RawTx.Marshal(b, m, deterministic)
+}
+func (dst *RawTx) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RawTx.Merge(dst, src)
+}
+func (m *RawTx) XXX_Size() int {
+	return xxx_messageInfo_RawTx.Size(m)
+}
+func (m *RawTx) XXX_DiscardUnknown() {
+	xxx_messageInfo_RawTx.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RawTx proto.InternalMessageInfo
+
+func (m *RawTx) GetTx() []byte {
+	if m != nil {
+		return m.Tx
+	}
+	return nil
+}
+
+func (m *RawTx) GetComplete() bool {
+	if m != nil {
+		return m.Complete
+	}
+	return false
+}
+
+type EstimateFeeData struct {
+	Coin                 CoinType  `protobuf:"varint,1,opt,name=coin,proto3,enum=pb.CoinType" json:"coin,omitempty"`
+	Inputs               []*Input  `protobuf:"bytes,2,rep,name=inputs,proto3" json:"inputs,omitempty"`
+	Outputs              []*Output `protobuf:"bytes,3,rep,name=outputs,proto3" json:"outputs,omitempty"`
+	FeePerByte           uint64    `protobuf:"varint,4,opt,name=feePerByte,proto3" json:"feePerByte,omitempty"`
+	CoinName             string    `protobuf:"bytes,5,opt,name=coinName,proto3" json:"coinName,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *EstimateFeeData) Reset()         { *m = EstimateFeeData{} }
+func (m *EstimateFeeData) String() string { return proto.CompactTextString(m) }
+func (*EstimateFeeData) ProtoMessage()    {}
+func (*EstimateFeeData) Descriptor() ([]byte, []int) {
+	return fileDescriptor_api_d239b4462bcb8aa3, []int{50}
+}
+func (m *EstimateFeeData) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_EstimateFeeData.Unmarshal(m, b)
+}
+func (m *EstimateFeeData) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_EstimateFeeData.Marshal(b, m, deterministic)
+}
+func (dst *EstimateFeeData) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_EstimateFeeData.Merge(dst, src)
+}
+func (m *EstimateFeeData) XXX_Size() int {
+	return xxx_messageInfo_EstimateFeeData.Size(m)
+}
+func (m *EstimateFeeData) XXX_DiscardUnknown() {
+	xxx_messageInfo_EstimateFeeData.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_EstimateFeeData proto.InternalMessageInfo
+
+func (m *EstimateFeeData) GetCoin() CoinType {
+	if m != nil {
+		return m.Coin
+	}
+	return CoinType_BITCOIN
+}
+
+func (m *EstimateFeeData) GetInputs() []*Input {
+	if m != nil {
+		return m.Inputs
+	}
+	return nil
+}
+
+func (m *EstimateFeeData) GetOutputs() []*Output {
+	if m != nil {
+		return m.Outputs
+	}
+	return nil
+}
+
+func (m *EstimateFeeData) GetFeePerByte() uint64 {
+	if m != nil {
+		return m.FeePerByte
+	}
+	return 0
+}
+
+func (m *EstimateFeeData) GetCoinName() string {
+	if m != nil {
+		return m.CoinName
+	}
+	return ""
+}
+
+type Backend struct {
+	Url                  string   `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	Current              bool     `protobuf:"varint,2,opt,name=current,proto3" json:"current,omitempty"`
+	Healthy              bool     `protobuf:"varint,3,opt,name=healthy,proto3" json:"healthy,omitempty"`
+	LatencyMs            uint64   `protobuf:"varint,4,opt,name=latencyMs,proto3" json:"latencyMs,omitempty"`
+	ErrorRate            float64  `protobuf:"fixed64,5,opt,name=errorRate,proto3" json:"errorRate,omitempty"`
+	BlockHeight          int32    `protobuf:"varint,6,opt,name=blockHeight,proto3" json:"blockHeight,omitempty"`
+	BlocksBehind         int32    `protobuf:"varint,7,opt,name=blocksBehind,proto3" json:"blocksBehind,omitempty"`
+	Requests             uint64   `protobuf:"varint,8,opt,name=requests,proto3" json:"requests,omitempty"`
+	Score                float64  `protobuf:"fixed64,9,opt,name=score,proto3" json:"score,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Backend) Reset()         { *m = Backend{} }
+func (m *Backend) String() string { return proto.CompactTextString(m) }
+func (*Backend) ProtoMessage()    {}
+func (*Backend) Descriptor() ([]byte, []int) {
+	return fileDescriptor_api_d239b4462bcb8aa3, []int{51}
+}
+func (m *Backend) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Backend.Unmarshal(m, b)
+}
+func (m *Backend) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Backend.Marshal(b, m, deterministic)
+}
+func (dst *Backend) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Backend.Merge(dst, src)
+}
+func (m *Backend) XXX_Size() int {
+	return xxx_messageInfo_Backend.Size(m)
+}
+func (m *Backend) XXX_DiscardUnknown() {
+	xxx_messageInfo_Backend.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Backend proto.InternalMessageInfo
+
+func (m *Backend) GetUrl() string {
+	if m != nil {
+		return m.Url
+	}
+	return ""
+}
+
+func (m *Backend) GetCurrent() bool {
+	if m != nil {
+		return m.Current
+	}
+	return false
+}
+
+func (m *Backend) GetHealthy() bool {
+	if m != nil {
+		return m.Healthy
+	}
+	return false
+}
+
+func (m *Backend) GetLatencyMs() uint64 {
+	if m != nil {
+		return m.LatencyMs
+	}
+	return 0
+}
+
+func (m *Backend) GetErrorRate() float64 {
+	if m != nil {
+		return m.ErrorRate
+	}
+	return 0
+}
+
+func (m *Backend) GetBlockHeight() int32 {
+	if m != nil {
+		return m.BlockHeight
+	}
+	return 0
+}
+
+func (m *Backend) GetBlocksBehind() int32 {
+	if m != nil {
+		return m.BlocksBehind
+	}
+	return 0
+}
+
+func (m *Backend) GetRequests() uint64 {
+	if m != nil {
+		return m.Requests
+	}
+	return 0
+}
+
+func (m *Backend) GetScore() float64 {
+	if m != nil {
+		return m.Score
+	}
+	return 0
+}
+
+type BackendList struct {
+	Backends             []*Backend `protobuf:"bytes,1,rep,name=backends,proto3" json:"backends,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
+	XXX_unrecognized     []byte     `json:"-"`
+	XXX_sizecache        int32      `json:"-"`
+}
+
+func (m *BackendList) Reset()         { *m = BackendList{} }
+func (m *BackendList) String() string { return proto.CompactTextString(m) }
+func (*BackendList) ProtoMessage()    {}
+func (*BackendList) Descriptor() ([]byte, []int) {
+	return fileDescriptor_api_d239b4462bcb8aa3, []int{52}
+}
+func (m *BackendList) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BackendList.Unmarshal(m, b)
 }
-func (m *SignatureList) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_SignatureList.Marshal(b, m, deterministic)
+func (m *BackendList) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BackendList.Marshal(b, m, deterministic)
 }
-func (dst *SignatureList) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_SignatureList.Merge(dst, src)
+func (dst *BackendList) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BackendList.Merge(dst, src)
 }
-func (m *SignatureList) XXX_Size() int {
-	return xxx_messageInfo_SignatureList.Size(m)
+func (m *BackendList) XXX_Size() int {
+	return xxx_messageInfo_BackendList.Size(m)
 }
-func (m *SignatureList) XXX_DiscardUnknown() {
-	xxx_messageInfo_SignatureList.DiscardUnknown(m)
+func (m *BackendList) XXX_DiscardUnknown() {
+	xxx_messageInfo_BackendList.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_SignatureList proto.InternalMessageInfo
+var xxx_messageInfo_BackendList proto.InternalMessageInfo
 
-func (m *SignatureList) GetSigs() []*Signature {
+func (m *BackendList) GetBackends() []*Backend {
 	if m != nil {
-		return m.Sigs
+		return m.Backends
 	}
 	return nil
 }
 
-type MultisignInfo struct {
-	Coin                 CoinType     `protobuf:"varint,1,opt,name=coin,proto3,enum=pb.CoinType" json:"coin,omitempty"`
-	Inputs               []*Input     `protobuf:"bytes,2,rep,name=inputs,proto3" json:"inputs,omitempty"`
-	Outputs              []*Output    `protobuf:"bytes,3,rep,name=outputs,proto3" json:"outputs,omitempty"`
-	Sig1                 []*Signature `protobuf:"bytes,4,rep,name=sig1,proto3" json:"sig1,omitempty"`
-	Sig2                 []*Signature `protobuf:"bytes,5,rep,name=sig2,proto3" json:"sig2,omitempty"`
-	RedeemScript         []byte       `protobuf:"bytes,6,opt,name=redeemScript,proto3" json:"redeemScript,omitempty"`
-	FeePerByte           uint64       `protobuf:"varint,7,opt,name=feePerByte,proto3" json:"feePerByte,omitempty"`
-	Broadcast            bool         `protobuf:"varint,8,opt,name=broadcast,proto3" json:"broadcast,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
-	XXX_unrecognized     []byte       `json:"-"`
-	XXX_sizecache        int32        `json:"-"`
+type TxMetadata struct {
+	Coin                 CoinType `protobuf:"varint,1,opt,name=coin,proto3,enum=pb.CoinType" json:"coin,omitempty"`
+	Txid                 string   `protobuf:"bytes,2,opt,name=txid,proto3" json:"txid,omitempty"`
+	ReferenceID          string   `protobuf:"bytes,3,opt,name=referenceID,proto3" json:"referenceID,omitempty"`
+	Memo                 string   `protobuf:"bytes,4,opt,name=memo,proto3" json:"memo,omitempty"`
+	Labels               []string `protobuf:"bytes,5,rep,name=labels,proto3" json:"labels,omitempty"`
+	Counterparty         string   `protobuf:"bytes,6,opt,name=counterparty,proto3" json:"counterparty,omitempty"`
+	CoinName             string   `protobuf:"bytes,7,opt,name=coinName,proto3" json:"coinName,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *MultisignInfo) Reset()         { *m = MultisignInfo{} }
-func (m *MultisignInfo) String() string { return proto.CompactTextString(m) }
-func (*MultisignInfo) ProtoMessage()    {}
-func (*MultisignInfo) Descriptor() ([]byte, []int) {
-	return fileDescriptor_api_2ff753dddd9b028a, []int{27}
+func (m *TxMetadata) Reset()         { *m = TxMetadata{} }
+func (m *TxMetadata) String() string { return proto.CompactTextString(m) }
+func (*TxMetadata) ProtoMessage()    {}
+func (*TxMetadata) Descriptor() ([]byte, []int) {
+	return fileDescriptor_api_d239b4462bcb8aa3, []int{53}
 }
-func (m *MultisignInfo) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_MultisignInfo.Unmarshal(m, b)
+func (m *TxMetadata) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TxMetadata.Unmarshal(m, b)
 }
-func (m *MultisignInfo) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_MultisignInfo.Marshal(b, m, deterministic)
+func (m *TxMetadata) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TxMetadata.Marshal(b, m, deterministic)
 }
-func (dst *MultisignInfo) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_MultisignInfo.Merge(dst, src)
+func (dst *TxMetadata) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TxMetadata.Merge(dst, src)
 }
-func (m *MultisignInfo) XXX_Size() int {
-	return xxx_messageInfo_MultisignInfo.Size(m)
+func (m *TxMetadata) XXX_Size() int {
+	return xxx_messageInfo_TxMetadata.Size(m)
 }
-func (m *MultisignInfo) XXX_DiscardUnknown() {
-	xxx_messageInfo_MultisignInfo.DiscardUnknown(m)
+func (m *TxMetadata) XXX_DiscardUnknown() {
+	xxx_messageInfo_TxMetadata.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_MultisignInfo proto.InternalMessageInfo
+var xxx_messageInfo_TxMetadata proto.InternalMessageInfo
 
-func (m *MultisignInfo) GetCoin() CoinType {
+func (m *TxMetadata) GetCoin() CoinType {
 	if m != nil {
 		return m.Coin
 	}
 	return CoinType_BITCOIN
 }
 
-func (m *MultisignInfo) GetInputs() []*Input {
-	if m != nil {
-		return m.Inputs
-	}
-	return nil
-}
-
-func (m *MultisignInfo) GetOutputs() []*Output {
+func (m *TxMetadata) GetTxid() string {
 	if m != nil {
-		return m.Outputs
+		return m.Txid
 	}
-	return nil
+	return ""
 }
 
-func (m *MultisignInfo) GetSig1() []*Signature {
+func (m *TxMetadata) GetReferenceID() string {
 	if m != nil {
-		return m.Sig1
+		return m.ReferenceID
 	}
-	return nil
+	return ""
 }
 
-func (m *MultisignInfo) GetSig2() []*Signature {
+func (m *TxMetadata) GetMemo() string {
 	if m != nil {
-		return m.Sig2
+		return m.Memo
 	}
-	return nil
+	return ""
 }
 
-func (m *MultisignInfo) GetRedeemScript() []byte {
+func (m *TxMetadata) GetLabels() []string {
 	if m != nil {
-		return m.RedeemScript
+		return m.Labels
 	}
 	return nil
 }
 
-func (m *MultisignInfo) GetFeePerByte() uint64 {
+func (m *TxMetadata) GetCounterparty() string {
 	if m != nil {
-		return m.FeePerByte
+		return m.Counterparty
 	}
-	return 0
+	return ""
 }
 
-func (m *MultisignInfo) GetBroadcast() bool {
+func (m *TxMetadata) GetCoinName() string {
 	if m != nil {
-		return m.Broadcast
+		return m.CoinName
 	}
-	return false
+	return ""
 }
 
-type RawTx struct {
-	Tx                   []byte   `protobuf:"bytes,1,opt,name=tx,proto3" json:"tx,omitempty"`
+type Reference struct {
+	Coin                 CoinType `protobuf:"varint,1,opt,name=coin,proto3,enum=pb.CoinType" json:"coin,omitempty"`
+	ReferenceID          string   `protobuf:"bytes,2,opt,name=referenceID,proto3" json:"referenceID,omitempty"`
+	CoinName             string   `protobuf:"bytes,3,opt,name=coinName,proto3" json:"coinName,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *RawTx) Reset()         { *m = RawTx{} }
-func (m *RawTx) String() string { return proto.CompactTextString(m) }
-func (*RawTx) ProtoMessage()    {}
-func (*RawTx) Descriptor() ([]byte, []int) {
-	return fileDescriptor_api_2ff753dddd9b028a, []int{28}
+func (m *Reference) Reset()         { *m = Reference{} }
+func (m *Reference) String() string { return proto.CompactTextString(m) }
+func (*Reference) ProtoMessage()    {}
+func (*Reference) Descriptor() ([]byte, []int) {
+	return fileDescriptor_api_d239b4462bcb8aa3, []int{54}
 }
-func (m *RawTx) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_RawTx.Unmarshal(m, b)
+func (m *Reference) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Reference.Unmarshal(m, b)
 }
-func (m *RawTx) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_RawTx.Marshal(b, m, deterministic)
+func (m *Reference) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Reference.Marshal(b, m, deterministic)
 }
-func (dst *RawTx) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_RawTx.Merge(dst, src)
+func (dst *Reference) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Reference.Merge(dst, src)
 }
-func (m *RawTx) XXX_Size() int {
-	return xxx_messageInfo_RawTx.Size(m)
+func (m *Reference) XXX_Size() int {
+	return xxx_messageInfo_Reference.Size(m)
 }
-func (m *RawTx) XXX_DiscardUnknown() {
-	xxx_messageInfo_RawTx.DiscardUnknown(m)
+func (m *Reference) XXX_DiscardUnknown() {
+	xxx_messageInfo_Reference.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_RawTx proto.InternalMessageInfo
+var xxx_messageInfo_Reference proto.InternalMessageInfo
 
-func (m *RawTx) GetTx() []byte {
+func (m *Reference) GetCoin() CoinType {
 	if m != nil {
-		return m.Tx
+		return m.Coin
 	}
-	return nil
+	return CoinType_BITCOIN
 }
 
-type EstimateFeeData struct {
-	Coin                 CoinType  `protobuf:"varint,1,opt,name=coin,proto3,enum=pb.CoinType" json:"coin,omitempty"`
-	Inputs               []*Input  `protobuf:"bytes,2,rep,name=inputs,proto3" json:"inputs,omitempty"`
-	Outputs              []*Output `protobuf:"bytes,3,rep,name=outputs,proto3" json:"outputs,omitempty"`
-	FeePerByte           uint64    `protobuf:"varint,4,opt,name=feePerByte,proto3" json:"feePerByte,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
-	XXX_unrecognized     []byte    `json:"-"`
-	XXX_sizecache        int32     `json:"-"`
+func (m *Reference) GetReferenceID() string {
+	if m != nil {
+		return m.ReferenceID
+	}
+	return ""
 }
 
-func (m *EstimateFeeData) Reset()         { *m = EstimateFeeData{} }
-func (m *EstimateFeeData) String() string { return proto.CompactTextString(m) }
-func (*EstimateFeeData) ProtoMessage()    {}
-func (*EstimateFeeData) Descriptor() ([]byte, []int) {
-	return fileDescriptor_api_2ff753dddd9b028a, []int{29}
+func (m *Reference) GetCoinName() string {
+	if m != nil {
+		return m.CoinName
+	}
+	return ""
 }
-func (m *EstimateFeeData) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_EstimateFeeData.Unmarshal(m, b)
+
+type AddressLabel struct {
+	Coin                 CoinType `protobuf:"varint,1,opt,name=coin,proto3,enum=pb.CoinType" json:"coin,omitempty"`
+	Address              string   `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"`
+	Label                string   `protobuf:"bytes,3,opt,name=label,proto3" json:"label,omitempty"`
+	CoinName             string   `protobuf:"bytes,4,opt,name=coinName,proto3" json:"coinName,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
-func (m *EstimateFeeData) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_EstimateFeeData.Marshal(b, m, deterministic)
+
+func (m *AddressLabel) Reset()         { *m = AddressLabel{} }
+func (m *AddressLabel) String() string { return proto.CompactTextString(m) }
+func (*AddressLabel) ProtoMessage()    {}
+func (*AddressLabel) Descriptor() ([]byte, []int) {
+	return fileDescriptor_api_d239b4462bcb8aa3, []int{55}
 }
-func (dst *EstimateFeeData) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_EstimateFeeData.Merge(dst, src)
+func (m *AddressLabel) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AddressLabel.Unmarshal(m, b)
 }
-func (m *EstimateFeeData) XXX_Size() int {
-	return xxx_messageInfo_EstimateFeeData.Size(m)
+func (m *AddressLabel) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AddressLabel.Marshal(b, m, deterministic)
 }
-func (m *EstimateFeeData) XXX_DiscardUnknown() {
-	xxx_messageInfo_EstimateFeeData.DiscardUnknown(m)
+func (dst *AddressLabel) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AddressLabel.Merge(dst, src)
+}
+func (m *AddressLabel) XXX_Size() int {
+	return xxx_messageInfo_AddressLabel.Size(m)
+}
+func (m *AddressLabel) XXX_DiscardUnknown() {
+	xxx_messageInfo_AddressLabel.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_EstimateFeeData proto.InternalMessageInfo
+var xxx_messageInfo_AddressLabel proto.InternalMessageInfo
 
-func (m *EstimateFeeData) GetCoin() CoinType {
+func (m *AddressLabel) GetCoin() CoinType {
 	if m != nil {
 		return m.Coin
 	}
 	return CoinType_BITCOIN
 }
 
-func (m *EstimateFeeData) GetInputs() []*Input {
+func (m *AddressLabel) GetAddress() string {
 	if m != nil {
-		return m.Inputs
+		return m.Address
 	}
-	return nil
+	return ""
 }
 
-func (m *EstimateFeeData) GetOutputs() []*Output {
+func (m *AddressLabel) GetLabel() string {
 	if m != nil {
-		return m.Outputs
+		return m.Label
 	}
-	return nil
+	return ""
 }
 
-func (m *EstimateFeeData) GetFeePerByte() uint64 {
+func (m *AddressLabel) GetCoinName() string {
 	if m != nil {
-		return m.FeePerByte
+		return m.CoinName
 	}
-	return 0
+	return ""
 }
 
 func init() {
 	proto.RegisterType((*Empty)(nil), "pb.Empty")
 	proto.RegisterType((*CoinSelection)(nil), "pb.CoinSelection")
 	proto.RegisterType((*Row)(nil), "pb.Row")
+	proto.RegisterType((*ExportRequest)(nil), "pb.ExportRequest")
 	proto.RegisterType((*KeySelection)(nil), "pb.KeySelection")
 	proto.RegisterType((*Address)(nil), "pb.Address")
 	proto.RegisterType((*Height)(nil), "pb.Height")
 	proto.RegisterType((*Balances)(nil), "pb.Balances")
+	proto.RegisterType((*PortfolioRequest)(nil), "pb.PortfolioRequest")
+	proto.RegisterType((*Holding)(nil), "pb.Holding")
+	proto.RegisterType((*PortfolioValue)(nil), "pb.PortfolioValue")
 	proto.RegisterType((*Key)(nil), "pb.Key")
 	proto.RegisterType((*Keys)(nil), "pb.Keys")
 	proto.RegisterType((*Addresses)(nil), "pb.Addresses")
@@ -1564,11 +3909,26 @@ func init() {
 	proto.RegisterType((*NetParams)(nil), "pb.NetParams")
 	proto.RegisterType((*TransactionList)(nil), "pb.TransactionList")
 	proto.RegisterType((*Tx)(nil), "pb.Tx")
+	proto.RegisterType((*TxOutput)(nil), "pb.TxOutput")
+	proto.RegisterType((*TransactionFilter)(nil), "pb.TransactionFilter")
 	proto.RegisterType((*Txid)(nil), "pb.Txid")
 	proto.RegisterType((*FeeLevelSelection)(nil), "pb.FeeLevelSelection")
 	proto.RegisterType((*FeePerByte)(nil), "pb.FeePerByte")
 	proto.RegisterType((*Fee)(nil), "pb.Fee")
 	proto.RegisterType((*SpendInfo)(nil), "pb.SpendInfo")
+	proto.RegisterType((*FiatSpendInfo)(nil), "pb.FiatSpendInfo")
+	proto.RegisterType((*FiatSpendResult)(nil), "pb.FiatSpendResult")
+	proto.RegisterType((*Payment)(nil), "pb.Payment")
+	proto.RegisterType((*BatchSpendInfo)(nil), "pb.BatchSpendInfo")
+	proto.RegisterType((*PlannedInput)(nil), "pb.PlannedInput")
+	proto.RegisterType((*PlannedOutput)(nil), "pb.PlannedOutput")
+	proto.RegisterType((*SpendPlan)(nil), "pb.SpendPlan")
+	proto.RegisterType((*ExecutePlanInfo)(nil), "pb.ExecutePlanInfo")
+	proto.RegisterType((*SpendRequest)(nil), "pb.SpendRequest")
+	proto.RegisterType((*RawTxInfo)(nil), "pb.RawTxInfo")
+	proto.RegisterType((*DecodedInput)(nil), "pb.DecodedInput")
+	proto.RegisterType((*DecodedOutput)(nil), "pb.DecodedOutput")
+	proto.RegisterType((*DecodedTx)(nil), "pb.DecodedTx")
 	proto.RegisterType((*Confirmations)(nil), "pb.Confirmations")
 	proto.RegisterType((*Utxo)(nil), "pb.Utxo")
 	proto.RegisterType((*SweepInfo)(nil), "pb.SweepInfo")
@@ -1577,11 +3937,21 @@ func init() {
 	proto.RegisterType((*Signature)(nil), "pb.Signature")
 	proto.RegisterType((*CreateMultisigInfo)(nil), "pb.CreateMultisigInfo")
 	proto.RegisterType((*SignatureList)(nil), "pb.SignatureList")
+	proto.RegisterType((*CosignerSignatures)(nil), "pb.CosignerSignatures")
 	proto.RegisterType((*MultisignInfo)(nil), "pb.MultisignInfo")
+	proto.RegisterType((*MergeMultisigInfo)(nil), "pb.MergeMultisigInfo")
 	proto.RegisterType((*RawTx)(nil), "pb.RawTx")
 	proto.RegisterType((*EstimateFeeData)(nil), "pb.EstimateFeeData")
+	proto.RegisterType((*Backend)(nil), "pb.Backend")
+	proto.RegisterType((*BackendList)(nil), "pb.BackendList")
+	proto.RegisterType((*TxMetadata)(nil), "pb.TxMetadata")
+	proto.RegisterType((*Reference)(nil), "pb.Reference")
+	proto.RegisterType((*AddressLabel)(nil), "pb.AddressLabel")
 	proto.RegisterEnum("pb.CoinType", CoinType_name, CoinType_value)
 	proto.RegisterEnum("pb.KeyPurpose", KeyPurpose_name, KeyPurpose_value)
+	proto.RegisterEnum("pb.ExportFormat", ExportFormat_name, ExportFormat_value)
+	proto.RegisterEnum("pb.OutputOwner", OutputOwner_name, OutputOwner_value)
+	proto.RegisterEnum("pb.Direction", Direction_name, Direction_value)
 	proto.RegisterEnum("pb.FeeLevel", FeeLevel_name, FeeLevel_value)
 }
 
@@ -1602,26 +3972,41 @@ type APIClient interface {
 	NewAddress(ctx context.Context, in *KeySelection, opts ...grpc.CallOption) (*Address, error)
 	ChainTip(ctx context.Context, in *CoinSelection, opts ...grpc.CallOption) (*Height, error)
 	Balance(ctx context.Context, in *CoinSelection, opts ...grpc.CallOption) (*Balances, error)
+	Portfolio(ctx context.Context, in *PortfolioRequest, opts ...grpc.CallOption) (*PortfolioValue, error)
 	MasterPrivateKey(ctx context.Context, in *CoinSelection, opts ...grpc.CallOption) (*Key, error)
 	MasterPublicKey(ctx context.Context, in *CoinSelection, opts ...grpc.CallOption) (*Key, error)
 	HasKey(ctx context.Context, in *Address, opts ...grpc.CallOption) (*BoolResponse, error)
 	Params(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*NetParams, error)
-	Transactions(ctx context.Context, in *CoinSelection, opts ...grpc.CallOption) (*TransactionList, error)
+	Transactions(ctx context.Context, in *TransactionFilter, opts ...grpc.CallOption) (*TransactionList, error)
 	GetTransaction(ctx context.Context, in *Txid, opts ...grpc.CallOption) (*Tx, error)
 	GetFeePerByte(ctx context.Context, in *FeeLevelSelection, opts ...grpc.CallOption) (*FeePerByte, error)
 	Spend(ctx context.Context, in *SpendInfo, opts ...grpc.CallOption) (*Txid, error)
+	SpendBatch(ctx context.Context, in *BatchSpendInfo, opts ...grpc.CallOption) (*Txid, error)
+	SpendFiat(ctx context.Context, in *FiatSpendInfo, opts ...grpc.CallOption) (*FiatSpendResult, error)
+	PlanSpend(ctx context.Context, in *SpendInfo, opts ...grpc.CallOption) (*SpendPlan, error)
+	ExecuteSpendPlan(ctx context.Context, in *ExecutePlanInfo, opts ...grpc.CallOption) (*Txid, error)
+	AbandonSpendRequest(ctx context.Context, in *SpendRequest, opts ...grpc.CallOption) (*Empty, error)
+	BroadcastRawTx(ctx context.Context, in *RawTxInfo, opts ...grpc.CallOption) (*Txid, error)
+	DecodeRawTx(ctx context.Context, in *RawTxInfo, opts ...grpc.CallOption) (*DecodedTx, error)
 	BumpFee(ctx context.Context, in *Txid, opts ...grpc.CallOption) (*Txid, error)
 	AddWatchedScript(ctx context.Context, in *Address, opts ...grpc.CallOption) (*Empty, error)
 	GetConfirmations(ctx context.Context, in *Txid, opts ...grpc.CallOption) (*Confirmations, error)
 	SweepAddress(ctx context.Context, in *SweepInfo, opts ...grpc.CallOption) (*Txid, error)
 	CreateMultisigSignature(ctx context.Context, in *CreateMultisigInfo, opts ...grpc.CallOption) (*SignatureList, error)
 	Multisign(ctx context.Context, in *MultisignInfo, opts ...grpc.CallOption) (*RawTx, error)
+	MergeMultisig(ctx context.Context, in *MergeMultisigInfo, opts ...grpc.CallOption) (*RawTx, error)
 	EstimateFee(ctx context.Context, in *EstimateFeeData, opts ...grpc.CallOption) (*Fee, error)
 	GetKey(ctx context.Context, in *Address, opts ...grpc.CallOption) (*Key, error)
 	ListKeys(ctx context.Context, in *CoinSelection, opts ...grpc.CallOption) (*Keys, error)
 	ListAddresses(ctx context.Context, in *CoinSelection, opts ...grpc.CallOption) (*Addresses, error)
 	WalletNotify(ctx context.Context, in *CoinSelection, opts ...grpc.CallOption) (API_WalletNotifyClient, error)
 	DumpTables(ctx context.Context, in *CoinSelection, opts ...grpc.CallOption) (API_DumpTablesClient, error)
+	ExportTransactions(ctx context.Context, in *ExportRequest, opts ...grpc.CallOption) (API_ExportTransactionsClient, error)
+	BackendStatus(ctx context.Context, in *CoinSelection, opts ...grpc.CallOption) (*BackendList, error)
+	GetTransactionMetadata(ctx context.Context, in *Txid, opts ...grpc.CallOption) (*TxMetadata, error)
+	SetTransactionMetadata(ctx context.Context, in *TxMetadata, opts ...grpc.CallOption) (*Empty, error)
+	GetTransactionByReference(ctx context.Context, in *Reference, opts ...grpc.CallOption) (*Tx, error)
+	SetAddressLabel(ctx context.Context, in *AddressLabel, opts ...grpc.CallOption) (*Empty, error)
 }
 
 type aPIClient struct {
@@ -1677,6 +4062,15 @@ func (c *aPIClient) Balance(ctx context.Context, in *CoinSelection, opts ...grpc
 	return out, nil
 }
 
+func (c *aPIClient) Portfolio(ctx context.Context, in *PortfolioRequest, opts ...grpc.CallOption) (*PortfolioValue, error) {
+	out := new(PortfolioValue)
+	err := c.cc.Invoke(ctx, "/pb.API/Portfolio", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *aPIClient) MasterPrivateKey(ctx context.Context, in *CoinSelection, opts ...grpc.CallOption) (*Key, error) {
 	out := new(Key)
 	err := c.cc.Invoke(ctx, "/pb.API/MasterPrivateKey", in, out, opts...)
@@ -1713,7 +4107,7 @@ func (c *aPIClient) Params(ctx context.Context, in *Empty, opts ...grpc.CallOpti
 	return out, nil
 }
 
-func (c *aPIClient) Transactions(ctx context.Context, in *CoinSelection, opts ...grpc.CallOption) (*TransactionList, error) {
+func (c *aPIClient) Transactions(ctx context.Context, in *TransactionFilter, opts ...grpc.CallOption) (*TransactionList, error) {
 	out := new(TransactionList)
 	err := c.cc.Invoke(ctx, "/pb.API/Transactions", in, out, opts...)
 	if err != nil {
@@ -1749,6 +4143,69 @@ func (c *aPIClient) Spend(ctx context.Context, in *SpendInfo, opts ...grpc.CallO
 	return out, nil
 }
 
+func (c *aPIClient) SpendBatch(ctx context.Context, in *BatchSpendInfo, opts ...grpc.CallOption) (*Txid, error) {
+	out := new(Txid)
+	err := c.cc.Invoke(ctx, "/pb.API/SpendBatch", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aPIClient) SpendFiat(ctx context.Context, in *FiatSpendInfo, opts ...grpc.CallOption) (*FiatSpendResult, error) {
+	out := new(FiatSpendResult)
+	err := c.cc.Invoke(ctx, "/pb.API/SpendFiat", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aPIClient) PlanSpend(ctx context.Context, in *SpendInfo, opts ...grpc.CallOption) (*SpendPlan, error) {
+	out := new(SpendPlan)
+	err := c.cc.Invoke(ctx, "/pb.API/PlanSpend", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aPIClient) ExecuteSpendPlan(ctx context.Context, in *ExecutePlanInfo, opts ...grpc.CallOption) (*Txid, error) {
+	out := new(Txid)
+	err := c.cc.Invoke(ctx, "/pb.API/ExecuteSpendPlan", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aPIClient) AbandonSpendRequest(ctx context.Context, in *SpendRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/pb.API/AbandonSpendRequest", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aPIClient) BroadcastRawTx(ctx context.Context, in *RawTxInfo, opts ...grpc.CallOption) (*Txid, error) {
+	out := new(Txid)
+	err := c.cc.Invoke(ctx, "/pb.API/BroadcastRawTx", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aPIClient) DecodeRawTx(ctx context.Context, in *RawTxInfo, opts ...grpc.CallOption) (*DecodedTx, error) {
+	out := new(DecodedTx)
+	err := c.cc.Invoke(ctx, "/pb.API/DecodeRawTx", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *aPIClient) BumpFee(ctx context.Context, in *Txid, opts ...grpc.CallOption) (*Txid, error) {
 	out := new(Txid)
 	err := c.cc.Invoke(ctx, "/pb.API/BumpFee", in, out, opts...)
@@ -1803,6 +4260,15 @@ func (c *aPIClient) Multisign(ctx context.Context, in *MultisignInfo, opts ...gr
 	return out, nil
 }
 
+func (c *aPIClient) MergeMultisig(ctx context.Context, in *MergeMultisigInfo, opts ...grpc.CallOption) (*RawTx, error) {
+	out := new(RawTx)
+	err := c.cc.Invoke(ctx, "/pb.API/MergeMultisig", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *aPIClient) EstimateFee(ctx context.Context, in *EstimateFeeData, opts ...grpc.CallOption) (*Fee, error) {
 	out := new(Fee)
 	err := c.cc.Invoke(ctx, "/pb.API/EstimateFee", in, out, opts...)
@@ -1883,24 +4349,101 @@ func (c *aPIClient) DumpTables(ctx context.Context, in *CoinSelection, opts ...g
 	if err := x.ClientStream.CloseSend(); err != nil {
 		return nil, err
 	}
-	return x, nil
+	return x, nil
+}
+
+type API_DumpTablesClient interface {
+	Recv() (*Row, error)
+	grpc.ClientStream
+}
+
+type aPIDumpTablesClient struct {
+	grpc.ClientStream
+}
+
+func (x *aPIDumpTablesClient) Recv() (*Row, error) {
+	m := new(Row)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *aPIClient) ExportTransactions(ctx context.Context, in *ExportRequest, opts ...grpc.CallOption) (API_ExportTransactionsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_API_serviceDesc.Streams[2], "/pb.API/ExportTransactions", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &aPIExportTransactionsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type API_ExportTransactionsClient interface {
+	Recv() (*Row, error)
+	grpc.ClientStream
+}
+
+type aPIExportTransactionsClient struct {
+	grpc.ClientStream
+}
+
+func (x *aPIExportTransactionsClient) Recv() (*Row, error) {
+	m := new(Row)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *aPIClient) BackendStatus(ctx context.Context, in *CoinSelection, opts ...grpc.CallOption) (*BackendList, error) {
+	out := new(BackendList)
+	err := c.cc.Invoke(ctx, "/pb.API/BackendStatus", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aPIClient) GetTransactionMetadata(ctx context.Context, in *Txid, opts ...grpc.CallOption) (*TxMetadata, error) {
+	out := new(TxMetadata)
+	err := c.cc.Invoke(ctx, "/pb.API/GetTransactionMetadata", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-type API_DumpTablesClient interface {
-	Recv() (*Row, error)
-	grpc.ClientStream
+func (c *aPIClient) SetTransactionMetadata(ctx context.Context, in *TxMetadata, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/pb.API/SetTransactionMetadata", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-type aPIDumpTablesClient struct {
-	grpc.ClientStream
+func (c *aPIClient) GetTransactionByReference(ctx context.Context, in *Reference, opts ...grpc.CallOption) (*Tx, error) {
+	out := new(Tx)
+	err := c.cc.Invoke(ctx, "/pb.API/GetTransactionByReference", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-func (x *aPIDumpTablesClient) Recv() (*Row, error) {
-	m := new(Row)
-	if err := x.ClientStream.RecvMsg(m); err != nil {
+func (c *aPIClient) SetAddressLabel(ctx context.Context, in *AddressLabel, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/pb.API/SetAddressLabel", in, out, opts...)
+	if err != nil {
 		return nil, err
 	}
-	return m, nil
+	return out, nil
 }
 
 // APIServer is the server API for API service.
@@ -1910,26 +4453,41 @@ type APIServer interface {
 	NewAddress(context.Context, *KeySelection) (*Address, error)
 	ChainTip(context.Context, *CoinSelection) (*Height, error)
 	Balance(context.Context, *CoinSelection) (*Balances, error)
+	Portfolio(context.Context, *PortfolioRequest) (*PortfolioValue, error)
 	MasterPrivateKey(context.Context, *CoinSelection) (*Key, error)
 	MasterPublicKey(context.Context, *CoinSelection) (*Key, error)
 	HasKey(context.Context, *Address) (*BoolResponse, error)
 	Params(context.Context, *Empty) (*NetParams, error)
-	Transactions(context.Context, *CoinSelection) (*TransactionList, error)
+	Transactions(context.Context, *TransactionFilter) (*TransactionList, error)
 	GetTransaction(context.Context, *Txid) (*Tx, error)
 	GetFeePerByte(context.Context, *FeeLevelSelection) (*FeePerByte, error)
 	Spend(context.Context, *SpendInfo) (*Txid, error)
+	SpendBatch(context.Context, *BatchSpendInfo) (*Txid, error)
+	SpendFiat(context.Context, *FiatSpendInfo) (*FiatSpendResult, error)
+	PlanSpend(context.Context, *SpendInfo) (*SpendPlan, error)
+	ExecuteSpendPlan(context.Context, *ExecutePlanInfo) (*Txid, error)
+	AbandonSpendRequest(context.Context, *SpendRequest) (*Empty, error)
+	BroadcastRawTx(context.Context, *RawTxInfo) (*Txid, error)
+	DecodeRawTx(context.Context, *RawTxInfo) (*DecodedTx, error)
 	BumpFee(context.Context, *Txid) (*Txid, error)
 	AddWatchedScript(context.Context, *Address) (*Empty, error)
 	GetConfirmations(context.Context, *Txid) (*Confirmations, error)
 	SweepAddress(context.Context, *SweepInfo) (*Txid, error)
 	CreateMultisigSignature(context.Context, *CreateMultisigInfo) (*SignatureList, error)
 	Multisign(context.Context, *MultisignInfo) (*RawTx, error)
+	MergeMultisig(context.Context, *MergeMultisigInfo) (*RawTx, error)
 	EstimateFee(context.Context, *EstimateFeeData) (*Fee, error)
 	GetKey(context.Context, *Address) (*Key, error)
 	ListKeys(context.Context, *CoinSelection) (*Keys, error)
 	ListAddresses(context.Context, *CoinSelection) (*Addresses, error)
 	WalletNotify(*CoinSelection, API_WalletNotifyServer) error
 	DumpTables(*CoinSelection, API_DumpTablesServer) error
+	ExportTransactions(*ExportRequest, API_ExportTransactionsServer) error
+	BackendStatus(context.Context, *CoinSelection) (*BackendList, error)
+	GetTransactionMetadata(context.Context, *Txid) (*TxMetadata, error)
+	SetTransactionMetadata(context.Context, *TxMetadata) (*Empty, error)
+	GetTransactionByReference(context.Context, *Reference) (*Tx, error)
+	SetAddressLabel(context.Context, *AddressLabel) (*Empty, error)
 }
 
 func RegisterAPIServer(s *grpc.Server, srv APIServer) {
@@ -2026,6 +4584,24 @@ func _API_Balance_Handler(srv interface{}, ctx context.Context, dec func(interfa
 	return interceptor(ctx, in, info, handler)
 }
 
+func _API_Portfolio_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PortfolioRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).Portfolio(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.API/Portfolio",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).Portfolio(ctx, req.(*PortfolioRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _API_MasterPrivateKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(CoinSelection)
 	if err := dec(in); err != nil {
@@ -2099,7 +4675,7 @@ func _API_Params_Handler(srv interface{}, ctx context.Context, dec func(interfac
 }
 
 func _API_Transactions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(CoinSelection)
+	in := new(TransactionFilter)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
@@ -2111,7 +4687,7 @@ func _API_Transactions_Handler(srv interface{}, ctx context.Context, dec func(in
 		FullMethod: "/pb.API/Transactions",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(APIServer).Transactions(ctx, req.(*CoinSelection))
+		return srv.(APIServer).Transactions(ctx, req.(*TransactionFilter))
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -2170,6 +4746,132 @@ func _API_Spend_Handler(srv interface{}, ctx context.Context, dec func(interface
 	return interceptor(ctx, in, info, handler)
 }
 
+func _API_SpendBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchSpendInfo)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).SpendBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.API/SpendBatch",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).SpendBatch(ctx, req.(*BatchSpendInfo))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_SpendFiat_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FiatSpendInfo)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).SpendFiat(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.API/SpendFiat",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).SpendFiat(ctx, req.(*FiatSpendInfo))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_PlanSpend_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SpendInfo)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).PlanSpend(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.API/PlanSpend",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).PlanSpend(ctx, req.(*SpendInfo))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_ExecuteSpendPlan_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExecutePlanInfo)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).ExecuteSpendPlan(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.API/ExecuteSpendPlan",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).ExecuteSpendPlan(ctx, req.(*ExecutePlanInfo))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_AbandonSpendRequest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SpendRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).AbandonSpendRequest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.API/AbandonSpendRequest",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).AbandonSpendRequest(ctx, req.(*SpendRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_BroadcastRawTx_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RawTxInfo)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).BroadcastRawTx(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.API/BroadcastRawTx",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).BroadcastRawTx(ctx, req.(*RawTxInfo))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_DecodeRawTx_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RawTxInfo)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).DecodeRawTx(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.API/DecodeRawTx",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).DecodeRawTx(ctx, req.(*RawTxInfo))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _API_BumpFee_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(Txid)
 	if err := dec(in); err != nil {
@@ -2278,6 +4980,24 @@ func _API_Multisign_Handler(srv interface{}, ctx context.Context, dec func(inter
 	return interceptor(ctx, in, info, handler)
 }
 
+func _API_MergeMultisig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MergeMultisigInfo)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).MergeMultisig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.API/MergeMultisig",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).MergeMultisig(ctx, req.(*MergeMultisigInfo))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _API_EstimateFee_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(EstimateFeeData)
 	if err := dec(in); err != nil {
@@ -2392,6 +5112,117 @@ func (x *aPIDumpTablesServer) Send(m *Row) error {
 	return x.ServerStream.SendMsg(m)
 }
 
+func _API_ExportTransactions_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(APIServer).ExportTransactions(m, &aPIExportTransactionsServer{stream})
+}
+
+type API_ExportTransactionsServer interface {
+	Send(*Row) error
+	grpc.ServerStream
+}
+
+type aPIExportTransactionsServer struct {
+	grpc.ServerStream
+}
+
+func (x *aPIExportTransactionsServer) Send(m *Row) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _API_BackendStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CoinSelection)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).BackendStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.API/BackendStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).BackendStatus(ctx, req.(*CoinSelection))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_GetTransactionMetadata_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Txid)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).GetTransactionMetadata(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.API/GetTransactionMetadata",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).GetTransactionMetadata(ctx, req.(*Txid))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_SetTransactionMetadata_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TxMetadata)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).SetTransactionMetadata(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.API/SetTransactionMetadata",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).SetTransactionMetadata(ctx, req.(*TxMetadata))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_GetTransactionByReference_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Reference)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).GetTransactionByReference(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.API/GetTransactionByReference",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).GetTransactionByReference(ctx, req.(*Reference))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_SetAddressLabel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddressLabel)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).SetAddressLabel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.API/SetAddressLabel",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).SetAddressLabel(ctx, req.(*AddressLabel))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _API_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "pb.API",
 	HandlerType: (*APIServer)(nil),
@@ -2416,6 +5247,10 @@ var _API_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Balance",
 			Handler:    _API_Balance_Handler,
 		},
+		{
+			MethodName: "Portfolio",
+			Handler:    _API_Portfolio_Handler,
+		},
 		{
 			MethodName: "MasterPrivateKey",
 			Handler:    _API_MasterPrivateKey_Handler,
@@ -2448,6 +5283,34 @@ var _API_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Spend",
 			Handler:    _API_Spend_Handler,
 		},
+		{
+			MethodName: "SpendBatch",
+			Handler:    _API_SpendBatch_Handler,
+		},
+		{
+			MethodName: "SpendFiat",
+			Handler:    _API_SpendFiat_Handler,
+		},
+		{
+			MethodName: "PlanSpend",
+			Handler:    _API_PlanSpend_Handler,
+		},
+		{
+			MethodName: "ExecuteSpendPlan",
+			Handler:    _API_ExecuteSpendPlan_Handler,
+		},
+		{
+			MethodName: "AbandonSpendRequest",
+			Handler:    _API_AbandonSpendRequest_Handler,
+		},
+		{
+			MethodName: "BroadcastRawTx",
+			Handler:    _API_BroadcastRawTx_Handler,
+		},
+		{
+			MethodName: "DecodeRawTx",
+			Handler:    _API_DecodeRawTx_Handler,
+		},
 		{
 			MethodName: "BumpFee",
 			Handler:    _API_BumpFee_Handler,
@@ -2472,6 +5335,10 @@ var _API_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Multisign",
 			Handler:    _API_Multisign_Handler,
 		},
+		{
+			MethodName: "MergeMultisig",
+			Handler:    _API_MergeMultisig_Handler,
+		},
 		{
 			MethodName: "EstimateFee",
 			Handler:    _API_EstimateFee_Handler,
@@ -2488,6 +5355,26 @@ var _API_serviceDesc = grpc.ServiceDesc{
 			MethodName: "ListAddresses",
 			Handler:    _API_ListAddresses_Handler,
 		},
+		{
+			MethodName: "BackendStatus",
+			Handler:    _API_BackendStatus_Handler,
+		},
+		{
+			MethodName: "GetTransactionMetadata",
+			Handler:    _API_GetTransactionMetadata_Handler,
+		},
+		{
+			MethodName: "SetTransactionMetadata",
+			Handler:    _API_SetTransactionMetadata_Handler,
+		},
+		{
+			MethodName: "GetTransactionByReference",
+			Handler:    _API_GetTransactionByReference_Handler,
+		},
+		{
+			MethodName: "SetAddressLabel",
+			Handler:    _API_SetAddressLabel_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -2500,103 +5387,224 @@ var _API_serviceDesc = grpc.ServiceDesc{
 			Handler:       _API_DumpTables_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "ExportTransactions",
+			Handler:       _API_ExportTransactions_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "api.proto",
 }
 
-func init() { proto.RegisterFile("api.proto", fileDescriptor_api_2ff753dddd9b028a) }
-
-var fileDescriptor_api_2ff753dddd9b028a = []byte{
-	// 1449 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xbc, 0x57, 0xcd, 0x72, 0xdb, 0x46,
-	0x12, 0xe6, 0x0f, 0xf8, 0x83, 0x16, 0x28, 0xd3, 0xb3, 0xbb, 0x36, 0x57, 0xeb, 0x92, 0xe9, 0x59,
-	0x1f, 0x64, 0xad, 0x57, 0xb6, 0xe8, 0x4a, 0xca, 0x87, 0xa4, 0x5c, 0x12, 0xad, 0x1f, 0x46, 0x12,
-	0xc5, 0x1a, 0xd1, 0xe5, 0xc4, 0x17, 0xd7, 0x90, 0x68, 0x49, 0x28, 0x93, 0x00, 0x0a, 0x18, 0x58,
-	0xe4, 0x3d, 0x8f, 0x91, 0x5c, 0xf2, 0x04, 0x79, 0x8b, 0x3c, 0x41, 0xde, 0x27, 0x35, 0x83, 0x01,
-	0x01, 0xc8, 0xb4, 0x2d, 0xe7, 0xe0, 0x5b, 0x4f, 0xf7, 0x87, 0x99, 0xee, 0xaf, 0x7b, 0x7a, 0x1a,
-	0x60, 0x72, 0xdf, 0xd9, 0xf2, 0x03, 0x4f, 0x78, 0xa4, 0xe4, 0x8f, 0xd6, 0xee, 0x5f, 0x78, 0xde,
-	0xc5, 0x04, 0x9f, 0x28, 0xcd, 0x28, 0x3a, 0x7f, 0x22, 0x9c, 0x29, 0x86, 0x82, 0x4f, 0xfd, 0x18,
-	0x44, 0x6b, 0x50, 0xd9, 0x9b, 0xfa, 0x62, 0x4e, 0xb7, 0xa1, 0xd1, 0xf5, 0x1c, 0xf7, 0x0c, 0x27,
-	0x38, 0x16, 0x8e, 0xe7, 0x92, 0x36, 0x18, 0x63, 0xcf, 0x71, 0x5b, 0xc5, 0x76, 0x71, 0x63, 0xb5,
-	0x63, 0x6d, 0xf9, 0xa3, 0x2d, 0x09, 0x18, 0xce, 0x7d, 0x64, 0xca, 0x42, 0xff, 0x0d, 0x65, 0xe6,
-	0x5d, 0x11, 0x02, 0x86, 0xcd, 0x05, 0x57, 0x40, 0x93, 0x29, 0x99, 0xbe, 0x01, 0xeb, 0x08, 0xe7,
-	0x5f, 0xb0, 0x19, 0xd9, 0x80, 0x9a, 0x1f, 0x05, 0xbe, 0x17, 0x62, 0xab, 0xa4, 0x40, 0xab, 0x12,
-	0x74, 0x84, 0xf3, 0x41, 0xac, 0x65, 0x89, 0x99, 0xbe, 0x80, 0xda, 0x8e, 0x6d, 0x07, 0x18, 0x86,
-	0x37, 0xd8, 0x96, 0x80, 0xc1, 0x6d, 0x3b, 0x50, 0x7b, 0x9a, 0x4c, 0xc9, 0xb4, 0x0d, 0xd5, 0x43,
-	0x74, 0x2e, 0x2e, 0x05, 0xb9, 0x03, 0xd5, 0x4b, 0x25, 0xa9, 0x1d, 0x1a, 0x4c, 0xaf, 0xe8, 0x0f,
-	0x50, 0xdf, 0xe5, 0x13, 0xee, 0x8e, 0x31, 0x24, 0xf7, 0xc0, 0x1c, 0x7b, 0xee, 0xb9, 0x13, 0x4c,
-	0xd1, 0x56, 0x30, 0x83, 0xa5, 0x0a, 0xd2, 0x86, 0x95, 0xc8, 0x4d, 0xed, 0x25, 0x65, 0xcf, 0xaa,
-	0xe8, 0x5d, 0x28, 0x1f, 0xe1, 0x9c, 0x34, 0xa1, 0xfc, 0x0e, 0xe7, 0x9a, 0x24, 0x29, 0xd2, 0xff,
-	0x82, 0x71, 0x84, 0xf3, 0x90, 0xfc, 0x07, 0x8c, 0x77, 0x38, 0x0f, 0x5b, 0xc5, 0x76, 0x79, 0x63,
-	0xa5, 0x53, 0xd3, 0x61, 0x33, 0xa5, 0xa4, 0xdf, 0x82, 0xa9, 0x83, 0xc5, 0x90, 0x3c, 0x02, 0x93,
-	0x27, 0x0b, 0x0d, 0x5f, 0x91, 0x70, 0x8d, 0x60, 0xa9, 0x95, 0x52, 0xb0, 0x76, 0x3d, 0x6f, 0xc2,
-	0x30, 0xf4, 0x3d, 0x37, 0x44, 0xc9, 0xc3, 0xc8, 0xf3, 0x26, 0xea, 0xfc, 0x3a, 0x53, 0x32, 0xbd,
-	0x0f, 0x66, 0x1f, 0xc5, 0x80, 0x07, 0x7c, 0x1a, 0x4a, 0x80, 0xcb, 0xa7, 0x98, 0x64, 0x51, 0xca,
-	0xf4, 0x7b, 0xb8, 0x35, 0x0c, 0xb8, 0x1b, 0x72, 0x95, 0xc4, 0x63, 0x27, 0x14, 0x64, 0x13, 0x2c,
-	0x91, 0xaa, 0x12, 0x2f, 0xaa, 0xd2, 0x8b, 0xe1, 0x8c, 0xe5, 0x6c, 0xf4, 0xf7, 0x22, 0x94, 0x86,
-	0x33, 0xb9, 0xb3, 0x98, 0x39, 0x76, 0xb2, 0xb3, 0x94, 0xc9, 0x3f, 0xa1, 0xf2, 0x9e, 0x4f, 0xa2,
-	0x38, 0xd7, 0x65, 0x16, 0x2f, 0x32, 0xe9, 0x28, 0xb7, 0x8b, 0x1b, 0x95, 0x24, 0x1d, 0xe4, 0x39,
-	0x98, 0x8b, 0xba, 0x6d, 0x19, 0xed, 0xe2, 0xc6, 0x4a, 0x67, 0x6d, 0x2b, 0xae, 0xec, 0xad, 0xa4,
-	0xb2, 0xb7, 0x86, 0x09, 0x82, 0xa5, 0x60, 0x99, 0xbc, 0x2b, 0x2e, 0xc6, 0x97, 0xa7, 0xee, 0x64,
-	0xde, 0xaa, 0xa8, 0xd8, 0x53, 0x85, 0xcc, 0x49, 0xc0, 0xaf, 0x5a, 0xd5, 0x76, 0x71, 0xc3, 0x62,
-	0x52, 0xa4, 0xdf, 0x81, 0x31, 0x94, 0xfe, 0xdd, 0xa8, 0xb0, 0x2e, 0x79, 0x78, 0x99, 0x14, 0x96,
-	0x94, 0xe9, 0x5b, 0xb8, 0xbd, 0x8f, 0x78, 0x8c, 0xef, 0x71, 0xf2, 0x65, 0xa5, 0x5f, 0x3f, 0xd7,
-	0x9f, 0xe9, 0xda, 0x57, 0xa8, 0x64, 0x2b, 0xb6, 0xb0, 0xd2, 0x75, 0x80, 0x7d, 0xc4, 0x01, 0x06,
-	0xbb, 0x73, 0x81, 0xd2, 0xfd, 0x73, 0x44, 0x5d, 0x93, 0x52, 0x94, 0xb5, 0xb6, 0x8f, 0xcb, 0x0c,
-	0xbf, 0x16, 0xc1, 0x3c, 0xf3, 0xd1, 0xb5, 0x7b, 0xee, 0xb9, 0x77, 0x03, 0x97, 0x5a, 0x50, 0xd3,
-	0xb5, 0xa4, 0x03, 0x4c, 0x96, 0x32, 0x47, 0x7c, 0xea, 0x45, 0x6e, 0x9c, 0x23, 0x83, 0xe9, 0x55,
-	0x2e, 0x08, 0xe3, 0x53, 0x41, 0x48, 0xe6, 0xa6, 0x38, 0xf5, 0x54, 0x3a, 0x4c, 0xa6, 0x64, 0xfa,
-	0x8d, 0xec, 0x3e, 0xea, 0xc6, 0x70, 0x55, 0x3b, 0xe4, 0x21, 0x34, 0xc6, 0x59, 0x85, 0xbe, 0xa0,
-	0x79, 0x25, 0xdd, 0x07, 0xe3, 0x95, 0x98, 0x79, 0x1f, 0x2b, 0x31, 0xc7, 0xb5, 0x71, 0xa6, 0x02,
-	0x68, 0xb0, 0x78, 0x91, 0x16, 0x5e, 0xec, 0x7d, 0xbc, 0xa0, 0x7f, 0x48, 0x7a, 0xae, 0x10, 0xfd,
-	0x1b, 0xd2, 0xb3, 0x0e, 0x95, 0x48, 0xcc, 0x3c, 0x49, 0x8e, 0x2c, 0xff, 0xba, 0x84, 0x48, 0x47,
-	0x58, 0xac, 0xce, 0xd2, 0x57, 0xce, 0xd3, 0xa7, 0xdb, 0x80, 0xb1, 0x68, 0x03, 0x84, 0x82, 0x15,
-	0xa0, 0x8d, 0x38, 0x3d, 0x1b, 0x07, 0x8e, 0x2f, 0x14, 0x2d, 0x16, 0xcb, 0xe9, 0x72, 0xe4, 0x56,
-	0x3f, 0x59, 0x21, 0xdb, 0x50, 0xe9, 0xb9, 0x7e, 0x24, 0x6e, 0x4e, 0x09, 0xdd, 0x85, 0xea, 0x69,
-	0x24, 0xe4, 0x37, 0x14, 0xac, 0x50, 0x1d, 0x38, 0x88, 0x46, 0x47, 0xba, 0x59, 0x59, 0x2c, 0xa7,
-	0xcb, 0xdf, 0xdc, 0x05, 0x81, 0x2f, 0xc0, 0x3c, 0x73, 0x2e, 0x5c, 0x2e, 0xa2, 0x00, 0xd3, 0x63,
-	0x8a, 0x59, 0xe6, 0xef, 0x81, 0x19, 0x26, 0x10, 0xf5, 0xb1, 0xc5, 0x52, 0x05, 0xfd, 0xb3, 0x08,
-	0xa4, 0x1b, 0x20, 0x17, 0x78, 0x12, 0x4d, 0x84, 0x13, 0x3a, 0x17, 0x37, 0x4c, 0xc5, 0x03, 0xa8,
-	0x3a, 0x32, 0xe0, 0x24, 0x17, 0xa6, 0xc4, 0x28, 0x0a, 0x98, 0x36, 0x90, 0x87, 0x50, 0xf3, 0x54,
-	0x80, 0x32, 0x1b, 0x12, 0x03, 0x12, 0x13, 0xc7, 0xcc, 0x12, 0xd3, 0xdf, 0xcc, 0xcc, 0x3a, 0xc0,
-	0xf9, 0xe2, 0x46, 0xaa, 0xdc, 0x18, 0x2c, 0xa3, 0xa1, 0x1d, 0x68, 0x2c, 0x88, 0x51, 0x0d, 0xf4,
-	0x01, 0x18, 0xa1, 0x73, 0x91, 0x34, 0xce, 0x86, 0xf4, 0x64, 0x01, 0x60, 0xca, 0x44, 0x7f, 0x2b,
-	0x41, 0x23, 0x61, 0xc1, 0xfd, 0xda, 0x34, 0xc4, 0xfe, 0x6d, 0xb7, 0x8c, 0x8f, 0xf9, 0xb7, 0xad,
-	0x21, 0x9d, 0x56, 0xe5, 0x63, 0x90, 0xce, 0x07, 0xd4, 0x55, 0x3f, 0x4b, 0x5d, 0xed, 0x3a, 0x75,
-	0xb2, 0x60, 0x46, 0x81, 0xc7, 0xed, 0x31, 0x0f, 0x45, 0xab, 0x1e, 0xf7, 0xee, 0x85, 0x82, 0xde,
-	0x85, 0x0a, 0xe3, 0x57, 0xc3, 0x19, 0x59, 0x85, 0x92, 0x98, 0xe9, 0x52, 0x2d, 0x89, 0x19, 0xfd,
-	0xa5, 0x08, 0xb7, 0xf6, 0x42, 0xe1, 0x4c, 0xb9, 0xc0, 0x7d, 0xc4, 0x97, 0x5c, 0xf0, 0xaf, 0xc9,
-	0x5f, 0x3e, 0x2a, 0xe3, 0x7a, 0x54, 0x9b, 0x03, 0xa8, 0x27, 0x47, 0x93, 0x15, 0xa8, 0xed, 0xf6,
-	0x86, 0xdd, 0xd3, 0x5e, 0xbf, 0x59, 0x20, 0x4d, 0xb0, 0xf4, 0xe2, 0x6d, 0x77, 0xe7, 0xec, 0xb0,
-	0x59, 0x24, 0x26, 0x54, 0xde, 0x28, 0xb1, 0x44, 0x2c, 0xa8, 0x1f, 0xf7, 0x86, 0x7b, 0x0a, 0x5a,
-	0x96, 0xab, 0xbd, 0xe1, 0xe1, 0x1e, 0xdb, 0x7b, 0x75, 0xd2, 0x34, 0x36, 0x37, 0x00, 0xd2, 0x31,
-	0x49, 0xda, 0x7a, 0xfd, 0xe1, 0x1e, 0xeb, 0xef, 0x1c, 0x37, 0x0b, 0x0a, 0xf9, 0xa3, 0x5e, 0x15,
-	0x37, 0x3b, 0x50, 0x4f, 0x5a, 0x86, 0xb2, 0x74, 0x4f, 0xfb, 0xa7, 0x27, 0xbd, 0x6e, 0xb3, 0x40,
-	0x00, 0xaa, 0xfd, 0x53, 0x76, 0x22, 0x51, 0xd2, 0x32, 0x60, 0xbd, 0x53, 0xd6, 0x1b, 0xfe, 0xd4,
-	0x2c, 0x75, 0x7e, 0x36, 0xa1, 0xbc, 0x33, 0xe8, 0x91, 0x75, 0x30, 0xce, 0x84, 0xe7, 0x13, 0x45,
-	0x8c, 0x1a, 0x19, 0xd7, 0x52, 0x91, 0x16, 0xc8, 0x36, 0xac, 0x76, 0xa3, 0x20, 0x40, 0x57, 0x24,
-	0xc3, 0x59, 0x53, 0x4f, 0x32, 0x8b, 0xa7, 0x70, 0x2d, 0x3b, 0xac, 0xd0, 0x02, 0xf9, 0x3f, 0x40,
-	0x1f, 0xaf, 0x6e, 0x0c, 0xff, 0x1f, 0xd4, 0xbb, 0x97, 0xdc, 0x71, 0x87, 0x8e, 0x4f, 0x6e, 0x27,
-	0x29, 0x4c, 0xd1, 0x2a, 0x1b, 0xf1, 0x5c, 0x47, 0x0b, 0xe4, 0x31, 0xd4, 0xf4, 0x04, 0xb7, 0x0c,
-	0xab, 0x2a, 0x20, 0x99, 0xf0, 0x68, 0x81, 0x3c, 0x85, 0xe6, 0x09, 0x0f, 0x05, 0x06, 0x83, 0xc0,
-	0x79, 0xcf, 0x05, 0xca, 0x46, 0xb7, 0xe4, 0xb3, 0x64, 0x36, 0xa3, 0x05, 0xf2, 0x04, 0x6e, 0xe9,
-	0x2f, 0xa2, 0xd1, 0xc4, 0x19, 0x7f, 0xfe, 0x83, 0x47, 0x50, 0x3d, 0xe4, 0xa1, 0xc4, 0x65, 0xc3,
-	0x5a, 0x53, 0x51, 0x67, 0x27, 0x35, 0x5a, 0x20, 0x0f, 0xa1, 0xaa, 0x87, 0xb2, 0x0c, 0xd9, 0xea,
-	0x9a, 0x2d, 0xc6, 0x35, 0x5a, 0x20, 0xcf, 0xc1, 0xca, 0x0c, 0x67, 0xe1, 0xb2, 0xe3, 0xff, 0xa1,
-	0xc6, 0xb2, 0xfc, 0x04, 0xa7, 0xf6, 0x5f, 0x3d, 0x40, 0x91, 0xd1, 0x93, 0x7a, 0x3c, 0xbf, 0x39,
-	0xf6, 0x9a, 0x9e, 0xe4, 0xd4, 0xfe, 0x8d, 0x03, 0x14, 0x99, 0x71, 0xe3, 0x5f, 0xd9, 0x27, 0x27,
-	0x3d, 0x64, 0x55, 0xab, 0x93, 0x8e, 0x57, 0x20, 0x14, 0x2a, 0x6a, 0xd6, 0x20, 0x71, 0x6b, 0x48,
-	0xc6, 0x8e, 0xb5, 0xc5, 0x29, 0xb4, 0x40, 0xee, 0x43, 0x6d, 0x37, 0x9a, 0xfa, 0x72, 0x5a, 0x49,
-	0x0f, 0xcf, 0x02, 0x1e, 0x43, 0x73, 0xc7, 0xb6, 0x5f, 0xcb, 0x59, 0x0d, 0x6d, 0xdd, 0x31, 0x72,
-	0xcc, 0x5d, 0xab, 0xbe, 0xe6, 0x01, 0x8a, 0xfc, 0x08, 0x91, 0xee, 0xab, 0xa9, 0xc9, 0x4e, 0x0e,
-	0x32, 0x21, 0x96, 0x7a, 0xf2, 0x93, 0xfa, 0x8b, 0x9d, 0x4d, 0x86, 0x80, 0x9c, 0x2f, 0xfb, 0x70,
-	0x37, 0xff, 0x36, 0xa5, 0x6f, 0xdd, 0x1d, 0xb5, 0xf5, 0x07, 0x0f, 0x57, 0x7c, 0x64, 0xae, 0xf3,
-	0xab, 0x0a, 0x36, 0x17, 0x7d, 0x3d, 0xce, 0x57, 0xae, 0xcd, 0xc7, 0x21, 0xa9, 0xae, 0xa6, 0x6e,
-	0xc7, 0x4a, 0xa6, 0x8d, 0x11, 0x95, 0xcb, 0x6b, 0x7d, 0x2d, 0xae, 0xaf, 0x7d, 0x94, 0xa4, 0xb7,
-	0xa1, 0x7a, 0x80, 0xe2, 0x83, 0xfa, 0xca, 0x55, 0x60, 0x5d, 0xfa, 0xa1, 0xfe, 0x39, 0x96, 0x14,
-	0x4b, 0x5d, 0x23, 0x25, 0x37, 0xcf, 0xa0, 0x21, 0xa1, 0xe9, 0x9f, 0xc7, 0x12, 0x7c, 0x23, 0x73,
-	0x0c, 0xc6, 0xd7, 0xd9, 0x7a, 0xcd, 0x27, 0x13, 0x14, 0x7d, 0x4f, 0x38, 0xe7, 0x4b, 0xef, 0xc3,
-	0xa2, 0xba, 0x9e, 0x16, 0xc9, 0x63, 0x80, 0x97, 0xd1, 0xd4, 0x1f, 0xf2, 0xd1, 0x64, 0xf9, 0x01,
-	0xca, 0x75, 0xe6, 0x5d, 0x49, 0xf4, 0xa8, 0xaa, 0xe6, 0xfc, 0x67, 0x7f, 0x05, 0x00, 0x00, 0xff,
-	0xff, 0x87, 0x69, 0x86, 0xca, 0xe0, 0x0e, 0x00, 0x00,
+func init() { proto.RegisterFile("api.proto", fileDescriptor_api_d239b4462bcb8aa3) }
+
+var fileDescriptor_api_d239b4462bcb8aa3 = []byte{
+	// 3308 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xcc, 0x5a, 0x4b, 0x73, 0xe3, 0xc6,
+	0xf1, 0x27, 0x48, 0xf0, 0xd5, 0x22, 0x29, 0x6a, 0x76, 0xbd, 0xa6, 0xf5, 0xdf, 0x5a, 0x6b, 0xf1,
+	0x77, 0x6c, 0x59, 0x6b, 0xcb, 0xb6, 0xbc, 0x76, 0x5c, 0x95, 0x54, 0xa5, 0x24, 0xea, 0xb1, 0xf4,
+	0x4a, 0xa4, 0x6a, 0xc4, 0xf5, 0x23, 0xa9, 0xc4, 0x05, 0x91, 0x43, 0x09, 0x59, 0x10, 0x40, 0x80,
+	0xe1, 0x8a, 0x8a, 0x73, 0x4b, 0xae, 0x3e, 0xa4, 0x72, 0x48, 0x55, 0x2e, 0xc9, 0x25, 0x87, 0x9c,
+	0x72, 0xcd, 0x21, 0x97, 0x7c, 0x83, 0x9c, 0xfc, 0x05, 0xf2, 0x09, 0x52, 0x95, 0x43, 0x8e, 0xa9,
+	0x79, 0x00, 0x98, 0xe1, 0x4b, 0x5c, 0x3b, 0x76, 0xe5, 0x44, 0x74, 0x4f, 0x03, 0xd3, 0xd3, 0xaf,
+	0xf9, 0xf5, 0x0c, 0xa1, 0x6c, 0x07, 0xce, 0x76, 0x10, 0xfa, 0xd4, 0x47, 0xd9, 0xe0, 0x7c, 0xfd,
+	0xe5, 0x0b, 0xdf, 0xbf, 0x70, 0xc9, 0x5b, 0x9c, 0x73, 0x3e, 0x1a, 0xbc, 0x45, 0x9d, 0x21, 0x89,
+	0xa8, 0x3d, 0x0c, 0x84, 0x90, 0x55, 0x84, 0xfc, 0xc1, 0x30, 0xa0, 0xd7, 0xd6, 0x09, 0x54, 0x9b,
+	0xbe, 0xe3, 0x9d, 0x11, 0x97, 0xf4, 0xa8, 0xe3, 0x7b, 0x68, 0x03, 0xcc, 0x9e, 0xef, 0x78, 0x0d,
+	0x63, 0xc3, 0xd8, 0xac, 0xed, 0x54, 0xb6, 0x83, 0xf3, 0x6d, 0x26, 0xd0, 0xbd, 0x0e, 0x08, 0xe6,
+	0x23, 0x68, 0x1d, 0x4a, 0xec, 0xb7, 0x6d, 0x0f, 0x49, 0x23, 0xbb, 0x61, 0x6c, 0x96, 0x71, 0x42,
+	0x5b, 0x2f, 0x41, 0x0e, 0xfb, 0x57, 0x08, 0x81, 0xd9, 0xb7, 0xa9, 0xcd, 0x3f, 0x52, 0xc6, 0xfc,
+	0xd9, 0xfa, 0x8d, 0x01, 0xd5, 0x83, 0x71, 0xe0, 0x87, 0x14, 0x93, 0x9f, 0x8d, 0x48, 0x44, 0x97,
+	0x9b, 0xca, 0x76, 0x5d, 0xc6, 0x8c, 0xf8, 0x54, 0x25, 0x9c, 0xd0, 0x5c, 0x8d, 0x51, 0x18, 0x12,
+	0xaf, 0x77, 0xdd, 0xc8, 0x49, 0x35, 0x24, 0x8d, 0x36, 0xa1, 0x30, 0xf0, 0xc3, 0xa1, 0x4d, 0x1b,
+	0x26, 0xff, 0x76, 0x9d, 0x7d, 0x5b, 0x4c, 0x7e, 0xc8, 0xf9, 0x58, 0x8e, 0x5b, 0xcf, 0xa0, 0xf2,
+	0x98, 0x5c, 0x3f, 0xcf, 0xf2, 0x37, 0xa1, 0x18, 0x8c, 0xc2, 0xc0, 0x8f, 0xc4, 0xea, 0x6b, 0x3b,
+	0x35, 0x26, 0xf4, 0x98, 0x5c, 0x9f, 0x0a, 0x2e, 0x8e, 0x87, 0x35, 0x43, 0xe5, 0x26, 0x0c, 0xf5,
+	0x23, 0x28, 0xee, 0xf6, 0xfb, 0x21, 0x89, 0xa2, 0x25, 0xa6, 0x44, 0x60, 0xda, 0xfd, 0x7e, 0x28,
+	0xad, 0xcd, 0x9f, 0x17, 0x7e, 0x7c, 0x03, 0x0a, 0x8f, 0x88, 0x73, 0x71, 0x49, 0xd1, 0x1d, 0x28,
+	0x5c, 0xf2, 0x27, 0xfe, 0xf5, 0x2a, 0x96, 0x94, 0xf5, 0x21, 0x94, 0xf6, 0x6c, 0xd7, 0xf6, 0x7a,
+	0x24, 0x42, 0x77, 0xa1, 0xdc, 0xf3, 0xbd, 0x81, 0x13, 0x0e, 0x49, 0x9f, 0x8b, 0x99, 0x38, 0x65,
+	0xa0, 0x0d, 0x58, 0x19, 0x79, 0xe9, 0x78, 0x96, 0x8f, 0xab, 0x2c, 0x6b, 0x1b, 0xea, 0xa7, 0x7e,
+	0x48, 0x07, 0xbe, 0xeb, 0xf8, 0xb1, 0x6b, 0x55, 0xe7, 0x18, 0xba, 0x73, 0xac, 0xdf, 0x67, 0xa1,
+	0xf8, 0xc8, 0x77, 0xfb, 0x8e, 0x77, 0x81, 0x2c, 0xa8, 0xc4, 0xfc, 0xa6, 0xdf, 0x27, 0x52, 0x56,
+	0xe3, 0xe9, 0xfa, 0x65, 0x6f, 0xd0, 0x2f, 0x37, 0xa5, 0x1f, 0xb3, 0x5e, 0x68, 0x53, 0xc2, 0x43,
+	0xc1, 0xc0, 0xfc, 0x19, 0xbd, 0x0f, 0x25, 0xf6, 0xdb, 0x75, 0x86, 0xa4, 0x91, 0xdf, 0x30, 0x36,
+	0x57, 0x76, 0xd6, 0xb7, 0x45, 0xce, 0x6c, 0xc7, 0x39, 0xb3, 0xdd, 0x8d, 0x73, 0x06, 0x27, 0xb2,
+	0xe8, 0x15, 0xa8, 0x0e, 0x1c, 0x9b, 0x36, 0x93, 0xf9, 0x0a, 0xfc, 0xa3, 0x3a, 0x13, 0x6d, 0xc2,
+	0x2a, 0x63, 0x3c, 0x51, 0xf4, 0x2a, 0x72, 0xb9, 0x49, 0x36, 0xba, 0x0d, 0x79, 0x12, 0x86, 0x7e,
+	0xd8, 0x28, 0xf1, 0x85, 0x0b, 0xc2, 0xfa, 0x93, 0x01, 0xb5, 0xc4, 0xa4, 0x1f, 0xd9, 0xee, 0x88,
+	0x2c, 0x32, 0x28, 0x7a, 0x0d, 0x4a, 0x97, 0xc2, 0x9e, 0x2c, 0x4b, 0x72, 0x9b, 0x2b, 0x3b, 0x2b,
+	0x2c, 0x88, 0xa4, 0x8d, 0x71, 0x32, 0x88, 0x5e, 0x85, 0x1a, 0xf5, 0xa9, 0xed, 0x36, 0x35, 0x73,
+	0x19, 0x78, 0x82, 0x8b, 0xb6, 0xa0, 0xce, 0x39, 0xea, 0x02, 0x84, 0xf5, 0xa6, 0xf8, 0xd6, 0x8b,
+	0x90, 0x7b, 0x4c, 0xae, 0x51, 0x1d, 0x72, 0x4f, 0x49, 0xac, 0x1a, 0x7b, 0xb4, 0xfe, 0x1f, 0xcc,
+	0xc7, 0xe4, 0x3a, 0x42, 0xff, 0x07, 0xe6, 0x53, 0x72, 0x1d, 0x35, 0x0c, 0xae, 0x59, 0x51, 0x26,
+	0x0b, 0xe6, 0x4c, 0xeb, 0x7d, 0x28, 0xcb, 0x34, 0x20, 0x11, 0x7a, 0x1d, 0xca, 0x76, 0x4c, 0x34,
+	0x8c, 0x74, 0x21, 0x52, 0x02, 0xa7, 0xa3, 0x96, 0x05, 0x95, 0x3d, 0xdf, 0x77, 0x31, 0x89, 0x02,
+	0xdf, 0x8b, 0x08, 0xf3, 0xf1, 0xb9, 0xef, 0xbb, 0x7c, 0xfe, 0x12, 0xe6, 0xcf, 0xd6, 0xcb, 0x50,
+	0x6e, 0x13, 0x7a, 0x6a, 0x87, 0xf6, 0x30, 0x62, 0x02, 0x1e, 0x4b, 0x15, 0x59, 0x91, 0xd8, 0xb3,
+	0xf5, 0x63, 0x58, 0xed, 0x86, 0xb6, 0x17, 0xd9, 0x3c, 0xf5, 0x8f, 0x9d, 0x88, 0xa2, 0x2d, 0xa8,
+	0xd0, 0x94, 0x15, 0x6b, 0x51, 0x60, 0x5a, 0x74, 0xc7, 0x58, 0x1b, 0x43, 0xf7, 0x00, 0x3c, 0x32,
+	0xa6, 0xcd, 0x51, 0x18, 0xf9, 0x71, 0x6e, 0x2a, 0x1c, 0xeb, 0x4b, 0x13, 0xb2, 0xdd, 0x31, 0x9b,
+	0x99, 0x8e, 0x9d, 0x7e, 0x3c, 0x33, 0x7b, 0x66, 0x6e, 0x7f, 0xc6, 0xdc, 0xca, 0xdf, 0xca, 0x61,
+	0x41, 0x28, 0xc9, 0xca, 0xdc, 0x92, 0x8f, 0x93, 0x15, 0x7d, 0x00, 0xe5, 0xa4, 0x7e, 0x37, 0xcc,
+	0x1b, 0xa3, 0x35, 0x15, 0x66, 0xa9, 0x73, 0x65, 0xd3, 0xde, 0x65, 0xc7, 0x73, 0xaf, 0x79, 0x9c,
+	0x97, 0x70, 0xca, 0x60, 0x3e, 0x0b, 0xed, 0x2b, 0x1e, 0xc2, 0x15, 0xcc, 0x1e, 0x93, 0xba, 0x5d,
+	0xdc, 0xc8, 0x6d, 0x56, 0x44, 0xdd, 0x66, 0x09, 0x16, 0x92, 0x01, 0x61, 0xa1, 0x46, 0x5a, 0xfb,
+	0x32, 0x50, 0x55, 0x16, 0x7b, 0x6b, 0x48, 0x86, 0x7e, 0xa3, 0x2c, 0x56, 0xc8, 0x9e, 0xd9, 0x5a,
+	0x5c, 0xfb, 0x9c, 0xb8, 0x51, 0x03, 0x36, 0x72, 0x9b, 0x65, 0x2c, 0x29, 0x9e, 0xf0, 0xfe, 0xc8,
+	0xa3, 0x24, 0x0c, 0xec, 0x90, 0x5e, 0x37, 0x56, 0x64, 0xc2, 0x2b, 0x3c, 0x56, 0xbd, 0x1d, 0x2f,
+	0x18, 0xd1, 0xa8, 0x51, 0xe1, 0xe6, 0xe7, 0xd5, 0x7b, 0x9f, 0xf4, 0xfc, 0x3e, 0xe9, 0xb7, 0xd8,
+	0x00, 0x96, 0xe3, 0xe8, 0x55, 0x28, 0xfa, 0x23, 0xca, 0x45, 0xab, 0x5c, 0xb4, 0x22, 0x3c, 0xd5,
+	0xe1, 0x4c, 0x1c, 0x0f, 0xb2, 0x95, 0x0e, 0x08, 0x69, 0xd4, 0x78, 0x71, 0x60, 0x8f, 0x2c, 0x9f,
+	0x06, 0x84, 0x3c, 0xf6, 0xfc, 0x2b, 0xaf, 0xb1, 0x2a, 0x76, 0x96, 0x98, 0x66, 0xeb, 0x89, 0x9c,
+	0x9f, 0x93, 0x46, 0x9d, 0x8b, 0xf3, 0x67, 0xee, 0x31, 0xce, 0x5c, 0xe3, 0x4c, 0x41, 0xa0, 0x06,
+	0x14, 0x07, 0x84, 0x60, 0x56, 0x5d, 0x10, 0xcf, 0x8f, 0x98, 0x64, 0x85, 0x42, 0xe6, 0x88, 0x2d,
+	0x22, 0xe9, 0x16, 0xaf, 0xbf, 0x3a, 0x93, 0x7b, 0xe0, 0x7c, 0xd0, 0xb8, 0xcd, 0x15, 0x60, 0x8f,
+	0xcc, 0x3e, 0x64, 0x1c, 0x38, 0xe1, 0xb5, 0x28, 0xe0, 0x8d, 0x17, 0xf8, 0x6b, 0x1a, 0xcf, 0xfa,
+	0xb3, 0x01, 0xa5, 0x78, 0x8d, 0x4c, 0x31, 0xc7, 0xeb, 0x93, 0xb1, 0x2c, 0xf0, 0x82, 0x60, 0x8a,
+	0xc9, 0x64, 0x91, 0x81, 0x19, 0x93, 0x6c, 0x82, 0xa8, 0x17, 0x3a, 0x01, 0x3d, 0x1d, 0x9d, 0x3f,
+	0x26, 0x62, 0xeb, 0xac, 0x60, 0x8d, 0x97, 0x86, 0xa7, 0x29, 0x17, 0xcb, 0x88, 0x24, 0x38, 0xf2,
+	0xfc, 0x0d, 0xfe, 0x8c, 0xbe, 0x03, 0x79, 0xff, 0xca, 0x23, 0x21, 0x0f, 0xa2, 0xda, 0xce, 0x2a,
+	0x33, 0xbf, 0x50, 0xac, 0xc3, 0xd8, 0x58, 0x8c, 0x5a, 0x7f, 0xcd, 0xc1, 0x9a, 0x92, 0x6a, 0x87,
+	0x8e, 0x4b, 0x49, 0xb8, 0xc4, 0xc6, 0x77, 0x1b, 0xf2, 0x3c, 0x6e, 0xe4, 0x22, 0x04, 0x81, 0xb6,
+	0xc1, 0x1c, 0x84, 0xfe, 0xb0, 0x91, 0xbb, 0x31, 0x15, 0xb8, 0x1c, 0xda, 0x82, 0x2c, 0xf5, 0x97,
+	0x48, 0x9c, 0x2c, 0xf5, 0x59, 0xc6, 0x0c, 0x1d, 0x4f, 0x1a, 0x3f, 0xcf, 0xd3, 0x30, 0x65, 0xf0,
+	0x51, 0x7b, 0x2c, 0x47, 0x0b, 0x72, 0x34, 0x66, 0xa0, 0x07, 0x50, 0xee, 0x3b, 0xa1, 0x00, 0x12,
+	0xbc, 0xe0, 0xd7, 0x76, 0xaa, 0x3c, 0x74, 0x63, 0x26, 0x4e, 0xc7, 0x55, 0x0f, 0x95, 0x74, 0x0f,
+	0x6d, 0x41, 0xdd, 0xf1, 0x7a, 0xee, 0xa8, 0x4f, 0x3e, 0x4e, 0x72, 0xb7, 0xcc, 0x23, 0x64, 0x8a,
+	0xcf, 0xc2, 0x78, 0xe8, 0x78, 0x7c, 0x8b, 0x68, 0x00, 0x77, 0x56, 0x42, 0xb3, 0x14, 0xec, 0x89,
+	0xda, 0x24, 0x92, 0x4c, 0x52, 0xdc, 0xa8, 0xce, 0xd0, 0xa1, 0x8d, 0x8a, 0x88, 0x18, 0x4e, 0x68,
+	0x78, 0xa2, 0x3a, 0x81, 0x27, 0x3e, 0x01, 0xb3, 0xcb, 0xca, 0xd6, 0x52, 0x48, 0xe5, 0xd2, 0x8e,
+	0x2e, 0x63, 0xa4, 0xc2, 0x9e, 0x17, 0x22, 0x95, 0xcf, 0x61, 0xed, 0x90, 0x90, 0x63, 0xf2, 0x8c,
+	0xb8, 0xcf, 0x87, 0xc1, 0x4a, 0x03, 0xf9, 0x5a, 0x23, 0x9b, 0x4a, 0xc5, 0x9f, 0xc2, 0xc9, 0xe8,
+	0xc2, 0xc9, 0xef, 0x01, 0x1c, 0x12, 0x72, 0x4a, 0xc2, 0xbd, 0x6b, 0x4a, 0xe2, 0x1a, 0x61, 0x24,
+	0x35, 0x82, 0x6d, 0x6d, 0x87, 0x64, 0xd6, 0xc0, 0xdf, 0xb2, 0x50, 0x3e, 0x0b, 0x88, 0xd7, 0x6f,
+	0x79, 0x03, 0x7f, 0x09, 0x75, 0xe7, 0x67, 0xe3, 0x1d, 0x28, 0xd8, 0x43, 0x56, 0xfb, 0x24, 0x70,
+	0x91, 0x94, 0xb6, 0x40, 0x73, 0xe1, 0x02, 0xe3, 0xe2, 0x9b, 0x57, 0x8a, 0x6f, 0x9c, 0xa9, 0x85,
+	0x0d, 0x63, 0x5e, 0x19, 0x2f, 0x4e, 0x97, 0xf1, 0xb4, 0x64, 0x97, 0xb4, 0x92, 0x7d, 0x17, 0xca,
+	0xa1, 0x80, 0x75, 0xad, 0x7d, 0x59, 0xe3, 0x53, 0x06, 0x33, 0x70, 0xc4, 0x4c, 0xb1, 0xeb, 0xba,
+	0x3c, 0x02, 0x4b, 0x38, 0xa1, 0x35, 0xe3, 0xaf, 0x4c, 0x18, 0xff, 0xb7, 0x39, 0xa8, 0x1e, 0x3a,
+	0x36, 0xfd, 0x26, 0xec, 0x68, 0x24, 0x76, 0x54, 0x61, 0x93, 0x39, 0xd5, 0x24, 0xa4, 0x36, 0xce,
+	0x2f, 0x65, 0xe3, 0x82, 0x62, 0xe3, 0x6f, 0xca, 0x9e, 0xf7, 0x00, 0x86, 0xf6, 0x98, 0xed, 0x21,
+	0xbb, 0x17, 0x22, 0xa7, 0xab, 0x58, 0xe1, 0xc8, 0x0d, 0x82, 0xf4, 0x28, 0xe9, 0x33, 0x16, 0xb7,
+	0xab, 0x81, 0x35, 0x1e, 0xd3, 0x6d, 0x68, 0x8f, 0xcf, 0x5c, 0x27, 0x08, 0xec, 0x0b, 0xc2, 0xf3,
+	0xdc, 0xc0, 0x2a, 0x6b, 0x61, 0xb6, 0xff, 0xc3, 0x80, 0xd5, 0xc4, 0x33, 0x98, 0x44, 0x23, 0x97,
+	0x7e, 0xc5, 0xcc, 0x9f, 0x17, 0xdd, 0x8b, 0xbc, 0x12, 0xa3, 0xf5, 0xfc, 0x1c, 0xb4, 0x5e, 0x78,
+	0x0e, 0xb4, 0xae, 0xae, 0xb2, 0x38, 0xb1, 0xca, 0xef, 0x41, 0xf1, 0xd4, 0xbe, 0x1e, 0x12, 0x8f,
+	0xaa, 0x61, 0x65, 0xcc, 0x0b, 0xab, 0xac, 0xba, 0x00, 0xeb, 0x8b, 0x2c, 0xd4, 0xf6, 0x58, 0x11,
+	0x7e, 0x9e, 0xe8, 0x7d, 0x0d, 0x4a, 0x81, 0x98, 0x51, 0x83, 0xe9, 0x52, 0x0b, 0x9c, 0x0c, 0x6a,
+	0x81, 0x99, 0x5b, 0x18, 0x98, 0x13, 0x41, 0x68, 0x4e, 0x07, 0xa1, 0x16, 0x6c, 0xf9, 0xc9, 0x60,
+	0x9b, 0x15, 0xd8, 0x69, 0xd8, 0x16, 0xb5, 0xb0, 0x55, 0x8d, 0x59, 0x9a, 0x30, 0xe6, 0xaf, 0x0c,
+	0xa8, 0x9c, 0xba, 0xb6, 0xe7, 0x49, 0x80, 0x36, 0x0f, 0xf4, 0x0a, 0xa4, 0x92, 0x55, 0x91, 0x4a,
+	0x82, 0x35, 0x72, 0x2a, 0xd6, 0x50, 0x5c, 0x62, 0xea, 0x2e, 0x61, 0xf5, 0x86, 0xe9, 0xef, 0xf5,
+	0x44, 0x8c, 0x54, 0x71, 0x42, 0x5b, 0x9f, 0x43, 0x55, 0x6a, 0x21, 0xc1, 0xd1, 0x7c, 0xcf, 0x4e,
+	0xc2, 0xa0, 0xec, 0x22, 0x18, 0xa4, 0xa9, 0xc6, 0xb6, 0xd5, 0x4b, 0xdb, 0xbb, 0x10, 0xe8, 0xa8,
+	0x84, 0x25, 0x65, 0x7d, 0x19, 0x6f, 0x0a, 0x4c, 0x85, 0xa5, 0xf6, 0xb0, 0x18, 0xe5, 0x66, 0x53,
+	0x94, 0xab, 0x1a, 0x31, 0x41, 0xb9, 0x0f, 0x52, 0x94, 0x9b, 0xe3, 0xa2, 0x6b, 0x8a, 0xe8, 0x1c,
+	0xa8, 0x6b, 0xa6, 0x50, 0xf7, 0x1e, 0xc0, 0x20, 0xd9, 0xe6, 0xb8, 0xcd, 0x4c, 0xac, 0x70, 0x54,
+	0x10, 0x5b, 0xd0, 0x41, 0x6c, 0x02, 0x7a, 0x8b, 0x2a, 0xe8, 0x7d, 0x03, 0xd6, 0xfa, 0xa3, 0x88,
+	0x36, 0xf9, 0xb2, 0xf7, 0x43, 0x3f, 0x08, 0x48, 0x9f, 0x47, 0x44, 0x09, 0x4f, 0x0f, 0x30, 0x20,
+	0xdc, 0x17, 0x8f, 0x82, 0xcf, 0x2b, 0x9e, 0x89, 0x75, 0xa6, 0x16, 0x5c, 0x30, 0x11, 0x5c, 0x7f,
+	0x30, 0x60, 0xf5, 0x60, 0x4c, 0x7a, 0x23, 0x4a, 0xd8, 0x9a, 0x79, 0xb6, 0xdd, 0x07, 0x33, 0x70,
+	0x6d, 0x61, 0xde, 0x15, 0x81, 0xb2, 0x12, 0xdb, 0x63, 0x3e, 0x34, 0x99, 0x1b, 0xd9, 0x1b, 0x72,
+	0x23, 0x37, 0x2f, 0x37, 0xcc, 0x99, 0xb9, 0x91, 0x57, 0x73, 0xc3, 0xfa, 0x29, 0x54, 0x64, 0xb5,
+	0x5c, 0xf6, 0x64, 0x4b, 0x9b, 0x3b, 0x3b, 0x63, 0x53, 0x9d, 0x8b, 0x5a, 0x3e, 0x85, 0x32, 0xb6,
+	0xaf, 0xba, 0xe3, 0x25, 0xab, 0x4e, 0x0d, 0xb2, 0x74, 0x2c, 0xc3, 0x3b, 0x4b, 0xc7, 0x0b, 0x3f,
+	0xfd, 0x3b, 0x03, 0x2a, 0x6a, 0x9f, 0xf5, 0x1c, 0x69, 0xac, 0xa6, 0x65, 0x4e, 0x4f, 0xcb, 0x05,
+	0xc9, 0x9c, 0x64, 0x58, 0x5e, 0xcd, 0xb0, 0xdb, 0x90, 0x7f, 0xca, 0x1b, 0xb3, 0x02, 0x0f, 0x2a,
+	0x41, 0x58, 0x5f, 0x18, 0x50, 0x95, 0xca, 0xfd, 0x2f, 0xb4, 0x3e, 0xd6, 0xbf, 0xb3, 0x50, 0x96,
+	0xfa, 0x74, 0xc7, 0xcb, 0x6d, 0x90, 0xdc, 0x96, 0x59, 0xc5, 0x96, 0x0d, 0x28, 0x3e, 0x23, 0x61,
+	0xc4, 0xfa, 0x05, 0xd1, 0xf2, 0xc7, 0x24, 0xb3, 0xa7, 0xeb, 0xf7, 0x9e, 0xb2, 0x56, 0x9e, 0xab,
+	0x52, 0xc5, 0x09, 0x3d, 0xd5, 0x23, 0xe6, 0xa7, 0x7b, 0xc4, 0xa4, 0x87, 0x2d, 0xcc, 0xea, 0x61,
+	0xb5, 0x74, 0x4e, 0xeb, 0x50, 0xe9, 0x86, 0x6e, 0x5b, 0xa9, 0x43, 0xe5, 0xb4, 0x0e, 0x69, 0x3e,
+	0x99, 0xaa, 0x43, 0x30, 0xbb, 0xe5, 0x5e, 0x99, 0x68, 0xb9, 0x65, 0x23, 0x5c, 0x49, 0x1b, 0xe1,
+	0x45, 0x08, 0xe5, 0x3d, 0x76, 0x68, 0xad, 0xf6, 0xd1, 0x53, 0xdd, 0xb6, 0x31, 0xa3, 0xdb, 0xb6,
+	0x0e, 0xc1, 0x7c, 0x42, 0xc7, 0xfe, 0xd7, 0xdd, 0x9c, 0x18, 0x40, 0x2a, 0x9f, 0x5d, 0x11, 0x12,
+	0x2c, 0x99, 0x82, 0xf7, 0x20, 0x3f, 0xa2, 0x63, 0x3f, 0x2e, 0xf4, 0x25, 0x26, 0xc2, 0x14, 0xc1,
+	0x82, 0xad, 0x46, 0x6c, 0x4e, 0x8f, 0x58, 0x79, 0xaa, 0x66, 0x26, 0xa7, 0x6a, 0xcc, 0xf7, 0x21,
+	0xe9, 0x13, 0x32, 0x3c, 0xe3, 0x51, 0x2b, 0x23, 0x52, 0xe3, 0x69, 0xf8, 0xa1, 0xb0, 0x74, 0x77,
+	0x34, 0x09, 0x90, 0x8e, 0x20, 0xff, 0x5f, 0xd9, 0xcb, 0xad, 0x3d, 0x28, 0xc8, 0x84, 0x9d, 0x4c,
+	0x40, 0x63, 0x51, 0x02, 0x66, 0xd5, 0x6f, 0xfc, 0x00, 0xca, 0x67, 0xce, 0x85, 0x67, 0xd3, 0x51,
+	0x48, 0xe6, 0xe4, 0xfd, 0x5d, 0x28, 0x47, 0xb1, 0x88, 0xac, 0x77, 0x29, 0xc3, 0xfa, 0xa7, 0x01,
+	0xa8, 0x19, 0x12, 0x9b, 0x92, 0x93, 0x91, 0x4b, 0x9d, 0xc8, 0xb9, 0x58, 0xd2, 0x79, 0xf7, 0x27,
+	0xb6, 0xe9, 0x32, 0x93, 0xd1, 0xf3, 0xe2, 0x95, 0xc9, 0xfd, 0x19, 0xd2, 0x63, 0x10, 0x2d, 0x21,
+	0xbe, 0x82, 0x2f, 0xf5, 0xcd, 0xbb, 0x30, 0xb5, 0x79, 0x2f, 0xf2, 0xe0, 0x0e, 0x54, 0x13, 0xa3,
+	0xf1, 0xd3, 0xcd, 0xfb, 0xac, 0x28, 0x5c, 0xc4, 0xa7, 0x9a, 0x62, 0xd7, 0x8c, 0x05, 0x30, 0x1f,
+	0xb2, 0x3a, 0x80, 0x9a, 0x3e, 0x33, 0x1b, 0x09, 0x93, 0x21, 0x8e, 0x83, 0x03, 0xd5, 0x65, 0x92,
+	0x4a, 0x3e, 0x98, 0x9d, 0xff, 0xc1, 0x7f, 0x65, 0xa1, 0x1a, 0x9b, 0xdc, 0xfb, 0xb6, 0x6d, 0x2e,
+	0xf4, 0x7b, 0xa7, 0x61, 0xce, 0xd3, 0xef, 0x1d, 0x29, 0xb2, 0xd3, 0xc8, 0xcf, 0x13, 0xd9, 0x99,
+	0xf2, 0x53, 0xe1, 0x46, 0x3f, 0x15, 0xa7, 0xfc, 0x74, 0x17, 0xca, 0xe7, 0xa1, 0x6f, 0xf7, 0x7b,
+	0x76, 0x44, 0x25, 0x58, 0x4a, 0x19, 0xe8, 0x21, 0xbb, 0xe2, 0x10, 0x56, 0x8f, 0x6b, 0xeb, 0x1d,
+	0x61, 0x97, 0x49, 0x57, 0xe0, 0x54, 0x70, 0x21, 0x68, 0xfa, 0xa3, 0x01, 0x6b, 0x27, 0x24, 0xbc,
+	0x78, 0xde, 0x70, 0xaf, 0x43, 0x8e, 0x8e, 0x85, 0xdd, 0x2b, 0x98, 0x3d, 0x4e, 0xad, 0x3e, 0x37,
+	0x63, 0xf5, 0xda, 0xea, 0xcc, 0xc9, 0xd5, 0xa9, 0x7a, 0xe6, 0x27, 0xf4, 0x7c, 0x17, 0xf2, 0x1c,
+	0xcd, 0x48, 0x9c, 0x62, 0xe8, 0x38, 0x65, 0x18, 0xb8, 0x84, 0x92, 0xf8, 0xea, 0x2f, 0xa6, 0xad,
+	0xbf, 0x30, 0x44, 0x18, 0x51, 0x67, 0x68, 0x53, 0x72, 0x48, 0xc8, 0xbe, 0x38, 0xdf, 0xf8, 0xd6,
+	0xa2, 0x4a, 0xf7, 0xb5, 0xb9, 0x30, 0x27, 0x27, 0xd7, 0xfb, 0xcb, 0x2c, 0x14, 0xf7, 0xec, 0xde,
+	0x53, 0xe2, 0xf5, 0x99, 0xad, 0x47, 0xa1, 0x1b, 0xdf, 0x99, 0x8c, 0x42, 0x97, 0xed, 0x04, 0xa2,
+	0x11, 0xa6, 0x72, 0xcd, 0x31, 0xc9, 0x46, 0x2e, 0x89, 0xed, 0xd2, 0x4b, 0x01, 0x5b, 0x4a, 0x38,
+	0x26, 0x99, 0xed, 0x5d, 0x9b, 0xb2, 0xde, 0xf9, 0x24, 0x92, 0xca, 0xa4, 0x0c, 0x36, 0xca, 0xef,
+	0x94, 0x70, 0xda, 0x53, 0xa7, 0x0c, 0x86, 0x91, 0xcf, 0x19, 0xac, 0xd0, 0x4e, 0x34, 0x55, 0x16,
+	0xf3, 0x3e, 0x27, 0xa3, 0x3d, 0x72, 0xe9, 0x78, 0xe2, 0x1e, 0x2b, 0x8f, 0x35, 0x1e, 0x5b, 0xaf,
+	0x84, 0xae, 0xe2, 0x2c, 0xd3, 0xc4, 0x09, 0xcd, 0x6a, 0x75, 0xd4, 0xf3, 0x43, 0x01, 0xfb, 0x0d,
+	0x2c, 0x08, 0xeb, 0x7d, 0x58, 0x91, 0x46, 0xe0, 0x75, 0xe9, 0x35, 0x28, 0x9d, 0x0b, 0x52, 0xbb,
+	0xf7, 0x91, 0x22, 0x38, 0x19, 0xb4, 0xfe, 0x6e, 0x00, 0x74, 0xc7, 0x27, 0x84, 0xda, 0xfd, 0xe5,
+	0x7c, 0x3e, 0x0b, 0x74, 0x4d, 0x34, 0x06, 0xb9, 0xf9, 0x17, 0x1a, 0x4b, 0x40, 0xff, 0xa9, 0x0b,
+	0x8d, 0xc2, 0x8c, 0x0b, 0x8d, 0x45, 0x45, 0xfa, 0x29, 0x94, 0x71, 0x3c, 0xf5, 0x12, 0x0b, 0xba,
+	0xb9, 0xab, 0x59, 0x04, 0xf0, 0x7f, 0x01, 0x15, 0x79, 0x99, 0x76, 0xcc, 0xb4, 0xff, 0x5a, 0x47,
+	0x6e, 0xc9, 0xd9, 0x7c, 0x4e, 0x3d, 0x9b, 0x57, 0x67, 0x37, 0xf5, 0xd9, 0xb7, 0x7e, 0x02, 0xa5,
+	0xf8, 0xeb, 0x68, 0x05, 0x8a, 0x7b, 0xad, 0x6e, 0xb3, 0xd3, 0x6a, 0xd7, 0x33, 0xa8, 0x0e, 0x15,
+	0x49, 0x7c, 0xd6, 0xdc, 0x3d, 0x7b, 0x54, 0x37, 0x50, 0x19, 0xf2, 0x3f, 0xe4, 0x8f, 0x59, 0x54,
+	0x81, 0xd2, 0x71, 0xab, 0x7b, 0xc0, 0x45, 0x73, 0x8c, 0x3a, 0xe8, 0x3e, 0x3a, 0xc0, 0x07, 0x4f,
+	0x4e, 0xea, 0x26, 0xa3, 0xf6, 0x3b, 0x47, 0x62, 0x2c, 0xbf, 0xb5, 0x09, 0x90, 0x5e, 0xc3, 0xb3,
+	0xb1, 0x56, 0xbb, 0x7b, 0x80, 0xdb, 0xbb, 0xc7, 0xf5, 0x0c, 0x7f, 0xef, 0x13, 0x49, 0x19, 0x5b,
+	0xf7, 0xa1, 0xa2, 0xfe, 0x1b, 0x00, 0x15, 0x21, 0xd7, 0x3c, 0xfb, 0xa8, 0x9e, 0x41, 0x25, 0x30,
+	0x3f, 0x3c, 0xeb, 0xb4, 0xeb, 0xc6, 0xd6, 0x23, 0x58, 0x51, 0x2e, 0x32, 0xd0, 0x2d, 0x58, 0x8d,
+	0xdf, 0xff, 0xac, 0xf3, 0xa4, 0x7b, 0xfa, 0xa4, 0x5b, 0xcf, 0xa0, 0x35, 0xa8, 0x7e, 0xbc, 0x7b,
+	0x7c, 0x7c, 0xd0, 0x8d, 0x59, 0x06, 0x63, 0x35, 0x1f, 0xed, 0xb6, 0x8f, 0x0e, 0x62, 0x56, 0x76,
+	0x6b, 0x1f, 0xca, 0xc9, 0x0d, 0x00, 0x1b, 0xdf, 0x6d, 0x7f, 0xfa, 0xd9, 0x7e, 0x0b, 0x1f, 0x34,
+	0xbb, 0xad, 0x4e, 0x5b, 0xa8, 0xd6, 0x6a, 0x37, 0x3b, 0x27, 0xad, 0xf6, 0x51, 0xdd, 0x60, 0x54,
+	0xe7, 0x49, 0xf7, 0xa8, 0xc3, 0xa8, 0x2c, 0xd3, 0xe7, 0xec, 0xe0, 0xf8, 0xb0, 0x9e, 0xdb, 0xda,
+	0x81, 0x52, 0x0c, 0xe0, 0xf8, 0x62, 0x9a, 0x9d, 0x76, 0xe7, 0xa4, 0xd5, 0xac, 0x67, 0x10, 0x40,
+	0xa1, 0xdd, 0xc1, 0x27, 0x6c, 0x61, 0x6c, 0xe4, 0x14, 0xb7, 0x3a, 0xb8, 0xd5, 0xfd, 0xb4, 0x9e,
+	0xdd, 0xf9, 0xf5, 0x2a, 0xe4, 0x76, 0x4f, 0x5b, 0xe8, 0x1e, 0x98, 0x67, 0xd4, 0x0f, 0x10, 0xaf,
+	0x78, 0xfc, 0x7f, 0x1f, 0xeb, 0xe9, 0xa3, 0x95, 0x41, 0xef, 0x40, 0xad, 0x29, 0xea, 0x4c, 0xfc,
+	0x9f, 0x84, 0xba, 0xbc, 0xa6, 0x4d, 0x0e, 0xe5, 0xd7, 0xd5, 0x9b, 0x58, 0x2b, 0x83, 0xde, 0x04,
+	0x68, 0x93, 0xab, 0xa5, 0xc5, 0x1f, 0x40, 0xa9, 0x79, 0x69, 0x3b, 0x5e, 0xd7, 0x09, 0xd0, 0x5a,
+	0x1c, 0x66, 0xa9, 0x34, 0x2f, 0xb3, 0xf2, 0x76, 0x2b, 0x83, 0xde, 0x80, 0xa2, 0xfc, 0x73, 0xc2,
+	0x2c, 0xd9, 0x8a, 0x28, 0x0d, 0x7c, 0x9c, 0x7d, 0xfa, 0xbb, 0x50, 0x4e, 0xee, 0xca, 0xd1, 0x6d,
+	0x36, 0x38, 0xf9, 0x6f, 0x84, 0x75, 0xa4, 0x71, 0xf9, 0xed, 0x88, 0x95, 0x41, 0x6f, 0x43, 0xfd,
+	0xc4, 0x8e, 0x28, 0x09, 0x4f, 0x43, 0xe7, 0x99, 0x4d, 0x09, 0x03, 0x34, 0x33, 0xe6, 0x8b, 0x6f,
+	0xac, 0xad, 0x0c, 0x7a, 0x0b, 0x56, 0xe5, 0x1b, 0xa3, 0x73, 0xd7, 0xe9, 0xdd, 0xfc, 0xc2, 0xeb,
+	0x50, 0x78, 0x64, 0x47, 0x4c, 0x4e, 0xb5, 0xc7, 0x3a, 0x37, 0x97, 0x7a, 0x7f, 0x6d, 0x65, 0xd0,
+	0x2b, 0x50, 0x90, 0x57, 0xd5, 0x8a, 0x97, 0x38, 0x1c, 0x49, 0x2e, 0xb1, 0xad, 0x0c, 0xfa, 0x3e,
+	0x54, 0xba, 0xea, 0x1d, 0xf4, 0x0b, 0x4c, 0x60, 0xea, 0x66, 0x6d, 0xfd, 0xd6, 0x04, 0x9b, 0x55,
+	0x59, 0x3e, 0x47, 0xed, 0x88, 0x50, 0x85, 0x8f, 0x78, 0x2f, 0xc2, 0xee, 0x76, 0xd6, 0xe5, 0x1d,
+	0xb7, 0x95, 0x41, 0x1f, 0x40, 0xf5, 0x88, 0x50, 0xe5, 0x66, 0xe4, 0x05, 0xb5, 0x7b, 0x48, 0xd7,
+	0x59, 0x93, 0x6c, 0x29, 0x66, 0x65, 0x90, 0x05, 0x79, 0x7e, 0x0c, 0x82, 0xd2, 0x03, 0x19, 0x06,
+	0x3b, 0xd6, 0x93, 0x59, 0xb8, 0x73, 0x81, 0x0f, 0xf0, 0xe3, 0x53, 0x84, 0x84, 0x33, 0xd5, 0x93,
+	0x54, 0x4d, 0xfa, 0x3d, 0x79, 0xa6, 0xc6, 0xce, 0xa3, 0x85, 0xad, 0xb5, 0x3b, 0x83, 0xf5, 0x5b,
+	0x1a, 0x4b, 0x1c, 0x56, 0xf3, 0x70, 0x2b, 0xb3, 0x93, 0xa0, 0x99, 0xca, 0xe8, 0x87, 0x45, 0x56,
+	0x06, 0xbd, 0x0b, 0x75, 0x79, 0xbc, 0x94, 0x70, 0xd1, 0x2d, 0xf1, 0x87, 0x21, 0xed, 0xd0, 0x49,
+	0x53, 0xec, 0x21, 0xdc, 0xda, 0x3d, 0xb7, 0xbd, 0xbe, 0xef, 0x69, 0x07, 0x3f, 0xf5, 0xe4, 0xe3,
+	0x71, 0xec, 0x69, 0x89, 0xf6, 0x00, 0x6a, 0x7b, 0x31, 0x2c, 0x12, 0xb0, 0x87, 0x6b, 0x93, 0x9c,
+	0xe7, 0x68, 0x53, 0xbc, 0x09, 0x2b, 0xa2, 0xb7, 0x9e, 0x29, 0x59, 0x55, 0x7a, 0x6f, 0xee, 0xb6,
+	0x97, 0xa1, 0xb8, 0x37, 0x1a, 0x06, 0xec, 0xc6, 0x2a, 0xf5, 0xaa, 0x6e, 0xf9, 0xfa, 0x6e, 0xbf,
+	0xcf, 0xef, 0x0e, 0x49, 0x5f, 0x02, 0x37, 0x2d, 0x2c, 0x27, 0x6a, 0x42, 0xfd, 0x88, 0x50, 0xbd,
+	0xcd, 0x4e, 0xbf, 0x2b, 0xc3, 0x5e, 0x19, 0xe4, 0xd1, 0x5e, 0xe1, 0x6d, 0x71, 0x5c, 0x15, 0x84,
+	0xa5, 0xe3, 0x46, 0x59, 0xd3, 0xe5, 0x10, 0x5e, 0xd4, 0xbb, 0xb1, 0xb4, 0xbb, 0x13, 0xc0, 0x77,
+	0xaa, 0x55, 0x13, 0x53, 0x6a, 0xfd, 0x8c, 0x70, 0x74, 0x2c, 0xe4, 0x89, 0xf8, 0xd0, 0x7a, 0x8d,
+	0xf5, 0x72, 0x62, 0x34, 0xee, 0xe8, 0xaa, 0x06, 0x89, 0x45, 0x60, 0x4f, 0xa1, 0x64, 0xfd, 0xa5,
+	0x37, 0x61, 0x45, 0x81, 0x9a, 0x32, 0x30, 0x74, 0xec, 0x29, 0x32, 0xfe, 0x90, 0xb0, 0x14, 0xd8,
+	0x80, 0xc2, 0x11, 0xa1, 0x53, 0x19, 0xaf, 0xd5, 0x84, 0x12, 0x53, 0x9e, 0xff, 0x37, 0x66, 0x46,
+	0xf5, 0x28, 0x49, 0xc9, 0x48, 0x28, 0xcc, 0x44, 0xd3, 0x7f, 0xc8, 0xcc, 0x90, 0xaf, 0x2a, 0xd3,
+	0x10, 0x51, 0x99, 0x2b, 0x1f, 0xdb, 0xae, 0x4b, 0x68, 0xdb, 0xa7, 0xce, 0x60, 0x66, 0x85, 0x4a,
+	0x72, 0xfd, 0x6d, 0x83, 0xe5, 0xe3, 0xfe, 0x68, 0x18, 0x74, 0xed, 0x73, 0x77, 0xf6, 0x04, 0x5c,
+	0x75, 0xec, 0x5f, 0x71, 0xe9, 0x87, 0x80, 0xc4, 0xc6, 0xa9, 0x55, 0xa1, 0xb5, 0xf4, 0xef, 0x75,
+	0x71, 0xd8, 0x6b, 0x6f, 0xbd, 0x07, 0x55, 0x89, 0xe5, 0xce, 0xa8, 0x4d, 0x47, 0x33, 0xa7, 0x59,
+	0x55, 0x10, 0x9f, 0x74, 0xee, 0x43, 0xb8, 0xa3, 0x97, 0xab, 0x04, 0xf8, 0xa5, 0x81, 0x58, 0x13,
+	0x4f, 0xf1, 0x08, 0x2f, 0x19, 0x77, 0xce, 0x66, 0xbf, 0x35, 0x21, 0xab, 0xc7, 0xfb, 0x43, 0x78,
+	0x49, 0x9f, 0x6c, 0xef, 0x3a, 0xc5, 0x65, 0x22, 0xf7, 0x62, 0x52, 0xa9, 0x95, 0x6f, 0xc3, 0xea,
+	0x19, 0xa1, 0x1a, 0xa6, 0xaa, 0x2b, 0x0e, 0xe1, 0x1c, 0x6d, 0x9e, 0xf3, 0x02, 0xbf, 0xb1, 0x7a,
+	0xf7, 0x3f, 0x03, 0x00, 0xc6, 0xbf, 0x68, 0xaf, 0xb2, 0x29, 0x00, 0x00,
 }
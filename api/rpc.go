@@ -1,17 +1,32 @@
 package api
 
 import (
+	"bytes"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"math"
 	"net"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/muecoin/multiwallet"
 	"github.com/muecoin/multiwallet/api/pb"
-	"github.com/muecoin/multiwallet/bitcoin"
-	"github.com/muecoin/multiwallet/bitcoincash"
-	"github.com/muecoin/multiwallet/litecoin"
-	"github.com/muecoin/multiwallet/zcash"
+	"github.com/muecoin/multiwallet/client"
+	"github.com/muecoin/multiwallet/multisig"
+	"github.com/muecoin/multiwallet/rates"
+	"github.com/muecoin/multiwallet/registry"
+	"github.com/muecoin/multiwallet/service"
+	"github.com/muecoin/multiwallet/util"
 	"github.com/OpenBazaar/wallet-interface"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
 	"github.com/btcsuite/btcutil"
+	"github.com/golang/protobuf/ptypes"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
@@ -37,19 +52,37 @@ func ServeAPI(w multiwallet.MultiWallet) error {
 	return nil
 }
 
-func coinType(coinType pb.CoinType) wallet.CoinType {
-	switch coinType {
-	case pb.CoinType_BITCOIN:
-		return wallet.Bitcoin
-	case pb.CoinType_BITCOIN_CASH:
-		return wallet.BitcoinCash
-	case pb.CoinType_ZCASH:
-		return wallet.Zcash
-	case pb.CoinType_LITECOIN:
-		return wallet.Litecoin
-	default:
-		return wallet.Bitcoin
+// coinSelection is a message selecting a coin, by name or by the CoinType
+// enum value
+type coinSelection interface {
+	GetCoin() pb.CoinType
+	GetCoinName() string
+}
+
+// selectCoin returns the registered coin selected by in. A coin name, which
+// is the registry name or the currency code of the coin, takes precedence
+// over the enum value, which only lists the coins known to the API.
+func selectCoin(in coinSelection) (*registry.Coin, error) {
+	name := in.GetCoinName()
+	if name == "" {
+		name = pb.CoinType_name[int32(in.GetCoin())]
+	}
+	if c, ok := registry.ByName(name); ok {
+		return c, nil
+	}
+	if c, ok := registry.ByCurrencyCode(name); ok {
+		return c, nil
+	}
+	return nil, fmt.Errorf("unknown coin %q", name)
+}
+
+// coinWallet returns the wallet of the coin selected by in
+func (s *server) coinWallet(in coinSelection) (wallet.Wallet, error) {
+	c, err := selectCoin(in)
+	if err != nil {
+		return nil, err
 	}
+	return s.w.WalletForCurrencyCode(c.CurrencyCode)
 }
 
 func (s *server) Stop(ctx context.Context, in *pb.Empty) (*pb.Empty, error) {
@@ -66,13 +99,12 @@ func (s *server) CurrentAddress(ctx context.Context, in *pb.KeySelection) (*pb.A
 	} else {
 		return nil, errors.New("Unknown key purpose")
 	}
-	ct := coinType(in.Coin)
-	wal, err := s.w.WalletForCurrencyCode(ct.CurrencyCode())
+	wal, err := s.coinWallet(in)
 	if err != nil {
 		return nil, err
 	}
 	addr := wal.CurrentAddress(purpose)
-	return &pb.Address{Coin: in.Coin, Addr: addr.String()}, nil
+	return &pb.Address{Coin: in.Coin, CoinName: in.CoinName, Addr: addr.String()}, nil
 }
 
 func (s *server) NewAddress(ctx context.Context, in *pb.KeySelection) (*pb.Address, error) {
@@ -84,18 +116,16 @@ func (s *server) NewAddress(ctx context.Context, in *pb.KeySelection) (*pb.Addre
 	} else {
 		return nil, errors.New("Unknown key purpose")
 	}
-	ct := coinType(in.Coin)
-	wal, err := s.w.WalletForCurrencyCode(ct.CurrencyCode())
+	wal, err := s.coinWallet(in)
 	if err != nil {
 		return nil, err
 	}
 	addr := wal.NewAddress(purpose)
-	return &pb.Address{Coin: in.Coin, Addr: addr.String()}, nil
+	return &pb.Address{Coin: in.Coin, CoinName: in.CoinName, Addr: addr.String()}, nil
 }
 
 func (s *server) ChainTip(ctx context.Context, in *pb.CoinSelection) (*pb.Height, error) {
-	ct := coinType(in.Coin)
-	wal, err := s.w.WalletForCurrencyCode(ct.CurrencyCode())
+	wal, err := s.coinWallet(in)
 	if err != nil {
 		return nil, err
 	}
@@ -104,8 +134,7 @@ func (s *server) ChainTip(ctx context.Context, in *pb.CoinSelection) (*pb.Height
 }
 
 func (s *server) Balance(ctx context.Context, in *pb.CoinSelection) (*pb.Balances, error) {
-	ct := coinType(in.Coin)
-	wal, err := s.w.WalletForCurrencyCode(ct.CurrencyCode())
+	wal, err := s.coinWallet(in)
 	if err != nil {
 		return nil, err
 	}
@@ -113,6 +142,36 @@ func (s *server) Balance(ctx context.Context, in *pb.CoinSelection) (*pb.Balance
 	return &pb.Balances{Confirmed: uint64(c), Unconfirmed: uint64(u)}, nil
 }
 
+func (s *server) Portfolio(ctx context.Context, in *pb.PortfolioRequest) (*pb.PortfolioValue, error) {
+	p := s.w.Portfolio(in.Currency)
+	resp := &pb.PortfolioValue{
+		Currency:         p.Currency,
+		TotalConfirmed:   p.TotalConfirmed,
+		TotalUnconfirmed: p.TotalUnconfirmed,
+	}
+	for _, h := range p.Holdings {
+		holding := &pb.Holding{
+			CurrencyCode: h.CurrencyCode,
+			Confirmed:    uint64(h.Confirmed),
+			Unconfirmed:  uint64(h.Unconfirmed),
+		}
+		if h.Err != nil {
+			holding.Error = h.Err.Error()
+		} else {
+			rateTime, err := ptypes.TimestampProto(h.Rate.Time)
+			if err != nil {
+				return nil, err
+			}
+			holding.Rate = h.Rate.Value
+			holding.RateTime = rateTime
+			holding.FiatConfirmed = h.FiatConfirmed
+			holding.FiatUnconfirmed = h.FiatUnconfirmed
+		}
+		resp.Holdings = append(resp.Holdings, holding)
+	}
+	return resp, nil
+}
+
 func (s *server) MasterPrivateKey(ctx context.Context, in *pb.CoinSelection) (*pb.Key, error) {
 	// Stub
 	return &pb.Key{Key: ""}, nil
@@ -133,15 +192,198 @@ func (s *server) HasKey(ctx context.Context, in *pb.Address) (*pb.BoolResponse,
 	return &pb.BoolResponse{Bool: false}, nil
 }
 
-func (s *server) Transactions(ctx context.Context, in *pb.CoinSelection) (*pb.TransactionList, error) {
-	// Stub
+type txMetadataStore interface {
+	TransactionMetadata(txid chainhash.Hash) (service.TxMetadata, bool)
+	SetTransactionMetadata(md service.TxMetadata) error
+	SetAddressLabel(addr btcutil.Address, label string) error
+	TransactionByReference(referenceID string) (wallet.Txn, error)
+	TransactionsWithLabel(label string) ([]wallet.Txn, error)
+}
+
+type txHistory interface {
+	TransactionPage(filter service.TxFilter) ([]wallet.Txn, string, error)
+}
+
+func (s *server) Transactions(ctx context.Context, in *pb.TransactionFilter) (*pb.TransactionList, error) {
+	wal, err := s.coinWallet(in)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		txns   []wallet.Txn
+		cursor string
+	)
+	if history, ok := wal.(txHistory); ok {
+		filter, err := txFilter(in)
+		if err != nil {
+			return nil, err
+		}
+		txns, cursor, err = history.TransactionPage(filter)
+		if err != nil {
+			return nil, err
+		}
+	} else if in.Label != "" {
+		store, ok := wal.(txMetadataStore)
+		if !ok {
+			return nil, errors.New("wallet does not record transaction labels")
+		}
+		txns, err = store.TransactionsWithLabel(in.Label)
+	} else {
+		txns, err = wal.Transactions()
+	}
+	if err != nil {
+		return nil, err
+	}
 	var list []*pb.Tx
-	return &pb.TransactionList{Transactions: list}, nil
+	for _, txn := range txns {
+		respTx, err := txToProto(wal, txn)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, respTx)
+	}
+	return &pb.TransactionList{Transactions: list, NextCursor: cursor}, nil
+}
+
+func txFilter(in *pb.TransactionFilter) (service.TxFilter, error) {
+	filter := service.TxFilter{
+		MinHeight:        in.MinHeight,
+		MaxHeight:        in.MaxHeight,
+		Address:          in.Address,
+		Label:            in.Label,
+		IncludeWatchOnly: in.IncludeWatchOnly,
+		MinValue:         int64(in.MinValue),
+		Cursor:           in.Cursor,
+		Limit:            int(in.Limit),
+	}
+	switch in.Direction {
+	case pb.Direction_INCOMING:
+		filter.Direction = service.Incoming
+	case pb.Direction_OUTGOING:
+		filter.Direction = service.Outgoing
+	case pb.Direction_SELF:
+		filter.Direction = service.Self
+	}
+	var err error
+	if in.From != nil {
+		if filter.From, err = ptypes.Timestamp(in.From); err != nil {
+			return filter, err
+		}
+	}
+	if in.To != nil {
+		if filter.To, err = ptypes.Timestamp(in.To); err != nil {
+			return filter, err
+		}
+	}
+	return filter, nil
+}
+
+type nullDataProvider interface {
+	TransactionData(txid chainhash.Hash) ([][]byte, error)
+}
+
+type txDetailer interface {
+	TransactionDetails(txid chainhash.Hash) (*util.TxDetails, error)
 }
 
 func (s *server) GetTransaction(ctx context.Context, in *pb.Txid) (*pb.Tx, error) {
-	// Stub
-	respTx := &pb.Tx{}
+	wal, err := s.coinWallet(in)
+	if err != nil {
+		return nil, err
+	}
+	txid, err := chainhash.NewHashFromStr(in.Hash)
+	if err != nil {
+		return nil, err
+	}
+	txn, err := wal.GetTransaction(*txid)
+	if err != nil {
+		return nil, err
+	}
+	respTx, err := txToProto(wal, txn)
+	if err != nil {
+		return nil, err
+	}
+	if detailer, ok := wal.(txDetailer); ok {
+		details, err := detailer.TransactionDetails(*txid)
+		if err != nil {
+			return nil, err
+		}
+		addTxDetails(respTx, details)
+	}
+	return respTx, nil
+}
+
+// addTxDetails fills in the decoded inputs and outputs, the fee and the size
+// of a transaction.
+func addTxDetails(respTx *pb.Tx, details *util.TxDetails) {
+	respTx.Inputs = decodedInputsToProto(details.Inputs)
+	for i, out := range details.Outputs {
+		output := &pb.TxOutput{
+			Index:        uint32(i),
+			ScriptPubKey: out.Script,
+			Value:        uint64(out.Value),
+			Data:         out.Data,
+			Owner:        outputOwner(details.Owners[i]),
+		}
+		if out.Address != nil {
+			output.Address = out.Address.String()
+		}
+		respTx.Outputs = append(respTx.Outputs, output)
+	}
+	respTx.Fee = uint64(details.Fee)
+	respTx.FeeKnown = details.FeeKnown
+	respTx.Size = uint64(details.Size)
+	respTx.Vsize = uint64(details.VSize)
+	respTx.FeeRate = details.FeeRate()
+	respTx.Confirmations = details.Confirmations
+	respTx.Rbf = details.RBF
+	respTx.ExpiryHeight = details.ExpiryHeight
+}
+
+func outputOwner(owner util.OutputOwner) pb.OutputOwner {
+	switch owner {
+	case util.OwnerWallet:
+		return pb.OutputOwner_WALLET_OUTPUT
+	case util.OwnerChange:
+		return pb.OutputOwner_CHANGE_OUTPUT
+	default:
+		return pb.OutputOwner_EXTERNAL_OUTPUT
+	}
+}
+
+// txToProto converts a wallet transaction adding the data outputs and
+// metadata the wallet recorded for it.
+func txToProto(wal wallet.Wallet, txn wallet.Txn) (*pb.Tx, error) {
+	txid, err := chainhash.NewHashFromStr(txn.Txid)
+	if err != nil {
+		return nil, err
+	}
+	ts, err := ptypes.TimestampProto(txn.Timestamp)
+	if err != nil {
+		return nil, err
+	}
+	respTx := &pb.Tx{
+		Txid:      txn.Txid,
+		Value:     txn.Value,
+		Height:    txn.Height,
+		Timestamp: ts,
+		WatchOnly: txn.WatchOnly,
+		Raw:       txn.Bytes,
+	}
+	if provider, ok := wal.(nullDataProvider); ok {
+		respTx.Data, err = provider.TransactionData(*txid)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if store, ok := wal.(txMetadataStore); ok {
+		if md, ok := store.TransactionMetadata(*txid); ok {
+			respTx.ReferenceID = md.ReferenceID
+			respTx.Memo = md.Memo
+			respTx.Labels = md.Labels
+			respTx.Counterparty = md.Counterparty
+		}
+	}
 	return respTx, nil
 }
 
@@ -150,12 +392,29 @@ func (s *server) GetFeePerByte(ctx context.Context, in *pb.FeeLevelSelection) (*
 	return &pb.FeePerByte{Fee: 0}, nil
 }
 
+type requestSpender interface {
+	SpendWithRequestID(requestID string, amount int64, addr btcutil.Address, feeLevel wallet.FeeLevel, referenceID string, data []byte, spendAll bool) (*chainhash.Hash, error)
+	SpendBatch(requestID string, payments []wallet.TransactionOutput, feeLevel wallet.FeeLevel, referenceID string) (*chainhash.Hash, error)
+}
+
+func feeLevel(level pb.FeeLevel) wallet.FeeLevel {
+	switch level {
+	case pb.FeeLevel_PRIORITY:
+		return wallet.PRIOIRTY
+	case pb.FeeLevel_NORMAL:
+		return wallet.NORMAL
+	case pb.FeeLevel_ECONOMIC:
+		return wallet.ECONOMIC
+	default:
+		return wallet.NORMAL
+	}
+}
+
 func (s *server) Spend(ctx context.Context, in *pb.SpendInfo) (*pb.Txid, error) {
 	var addr btcutil.Address
 	var err error
 
-	ct := coinType(in.Coin)
-	wal, err := s.w.WalletForCurrencyCode(ct.CurrencyCode())
+	wal, err := s.coinWallet(in)
 	if err != nil {
 		return nil, err
 	}
@@ -164,27 +423,366 @@ func (s *server) Spend(ctx context.Context, in *pb.SpendInfo) (*pb.Txid, error)
 		return nil, err
 	}
 
-	var feeLevel wallet.FeeLevel
-	switch in.FeeLevel {
-	case pb.FeeLevel_PRIORITY:
-		feeLevel = wallet.PRIOIRTY
-	case pb.FeeLevel_NORMAL:
-		feeLevel = wallet.NORMAL
-	case pb.FeeLevel_ECONOMIC:
-		feeLevel = wallet.ECONOMIC
-	default:
-		feeLevel = wallet.NORMAL
+	var txid *chainhash.Hash
+	if spender, ok := wal.(requestSpender); ok {
+		txid, err = spender.SpendWithRequestID(in.RequestID, int64(in.Amount), addr, feeLevel(in.FeeLevel), in.ReferenceID, in.Data, in.SpendAll)
+	} else if len(in.Data) > 0 || in.RequestID != "" {
+		return nil, errors.New("wallet does not support data outputs or request IDs")
+	} else {
+		txid, err = wal.Spend(int64(in.Amount), addr, feeLevel(in.FeeLevel), in.ReferenceID, in.SpendAll)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := saveSpendMetadata(wal, txid, in.Memo, in.Labels); err != nil {
+		return nil, err
+	}
+	return &pb.Txid{Coin: in.Coin, CoinName: in.CoinName, Hash: txid.String()}, nil
+}
+
+type fiatConverter interface {
+	ConvertFiatOnce(requestID string, fiat rates.FiatAmount) (rates.Conversion, error)
+}
+
+// SpendFiat converts the fiat amount at the current rate of the wallet and
+// spends it like Spend, returning the rate used. A retry with the same request
+// ID spends and returns the amount and rate of the first attempt.
+func (s *server) SpendFiat(ctx context.Context, in *pb.FiatSpendInfo) (*pb.FiatSpendResult, error) {
+	wal, err := s.coinWallet(in)
+	if err != nil {
+		return nil, err
+	}
+	fiat := rates.FiatAmount{
+		Currency:     in.Currency,
+		Amount:       in.Amount,
+		MaxAge:       time.Duration(in.MaxRateAge) * time.Second,
+		ExpectedRate: in.ExpectedRate,
+		MaxSlippage:  in.MaxSlippage,
+	}
+	var conv rates.Conversion
+	if converter, ok := wal.(fiatConverter); ok {
+		conv, err = converter.ConvertFiatOnce(in.RequestID, fiat)
+	} else if in.RequestID != "" {
+		return nil, errors.New("wallet does not support request IDs")
+	} else {
+		conv, err = rates.Convert(wal.ExchangeRates(), fiat)
+	}
+	if err != nil {
+		return nil, err
+	}
+	txid, err := s.Spend(ctx, &pb.SpendInfo{
+		Coin:        in.Coin,
+		CoinName:    in.CoinName,
+		Address:     in.Address,
+		Amount:      uint64(conv.Amount),
+		FeeLevel:    in.FeeLevel,
+		Memo:        in.Memo,
+		ReferenceID: in.ReferenceID,
+		Labels:      in.Labels,
+		RequestID:   in.RequestID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	rateTime, err := ptypes.TimestampProto(conv.Rate.Time)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.FiatSpendResult{
+		Coin:     in.Coin,
+		CoinName: in.CoinName,
+		Hash:     txid.Hash,
+		Amount:   uint64(conv.Amount),
+		Currency: conv.Currency,
+		Rate:     conv.Rate.Value,
+		RateTime: rateTime,
+	}, nil
+}
+
+func (s *server) SpendBatch(ctx context.Context, in *pb.BatchSpendInfo) (*pb.Txid, error) {
+	wal, err := s.coinWallet(in)
+	if err != nil {
+		return nil, err
+	}
+	spender, ok := wal.(requestSpender)
+	if !ok {
+		return nil, errors.New("wallet does not support batch spends")
+	}
+	payments := make([]wallet.TransactionOutput, 0, len(in.Payments))
+	for _, payment := range in.Payments {
+		addr, err := wal.DecodeAddress(payment.Address)
+		if err != nil {
+			return nil, err
+		}
+		payments = append(payments, wallet.TransactionOutput{Address: addr, Value: int64(payment.Amount)})
+	}
+	txid, err := spender.SpendBatch(in.RequestID, payments, feeLevel(in.FeeLevel), in.ReferenceID)
+	if err != nil {
+		return nil, err
+	}
+	if err := saveSpendMetadata(wal, txid, in.Memo, in.Labels); err != nil {
+		return nil, err
+	}
+	return &pb.Txid{Coin: in.Coin, CoinName: in.CoinName, Hash: txid.String()}, nil
+}
+
+type spendPlanner interface {
+	PlanSpend(amount int64, addr btcutil.Address, feeLevel wallet.FeeLevel, data []byte, spendAll bool) (*util.SpendPlan, error)
+	ExecuteSpendPlan(requestID string, plan *util.SpendPlan, referenceID string) (*chainhash.Hash, error)
+}
+
+func (s *server) spendPlanner(in coinSelection) (wallet.Wallet, spendPlanner, error) {
+	wal, err := s.coinWallet(in)
+	if err != nil {
+		return nil, nil, err
+	}
+	planner, ok := wal.(spendPlanner)
+	if !ok {
+		return nil, nil, errors.New("wallet does not support spend plans")
+	}
+	return wal, planner, nil
+}
+
+func (s *server) PlanSpend(ctx context.Context, in *pb.SpendInfo) (*pb.SpendPlan, error) {
+	wal, planner, err := s.spendPlanner(in)
+	if err != nil {
+		return nil, err
+	}
+	addr, err := wal.DecodeAddress(in.Address)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := planner.PlanSpend(int64(in.Amount), addr, feeLevel(in.FeeLevel), in.Data, in.SpendAll)
+	if err != nil {
+		return nil, err
+	}
+	return planToProto(in, plan), nil
+}
+
+func (s *server) ExecuteSpendPlan(ctx context.Context, in *pb.ExecutePlanInfo) (*pb.Txid, error) {
+	if in.Plan == nil {
+		return nil, errors.New("no spend plan to execute")
+	}
+	wal, planner, err := s.spendPlanner(in.Plan)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := planFromProto(wal, in.Plan)
+	if err != nil {
+		return nil, err
+	}
+	txid, err := planner.ExecuteSpendPlan(in.RequestID, plan, in.ReferenceID)
+	if err != nil {
+		return nil, err
+	}
+	if err := saveSpendMetadata(wal, txid, in.Memo, in.Labels); err != nil {
+		return nil, err
+	}
+	return &pb.Txid{Coin: in.Plan.Coin, CoinName: in.Plan.CoinName, Hash: txid.String()}, nil
+}
+
+type requestAbandoner interface {
+	AbandonSpendRequest(requestID string) error
+}
+
+func (s *server) AbandonSpendRequest(ctx context.Context, in *pb.SpendRequest) (*pb.Empty, error) {
+	wal, err := s.coinWallet(in)
+	if err != nil {
+		return nil, err
+	}
+	abandoner, ok := wal.(requestAbandoner)
+	if !ok {
+		return nil, errors.New("wallet does not spend with request IDs")
+	}
+	if err := abandoner.AbandonSpendRequest(in.RequestID); err != nil {
+		return nil, err
+	}
+	return &pb.Empty{}, nil
+}
+
+func planToProto(coin coinSelection, plan *util.SpendPlan) *pb.SpendPlan {
+	resp := &pb.SpendPlan{
+		Coin:              coin.GetCoin(),
+		CoinName:          coin.GetCoinName(),
+		Fee:               uint64(plan.Fee),
+		FeePerByte:        plan.FeePerByte,
+		FeeRate:           plan.FeeRate(),
+		Vsize:             uint64(plan.VSize),
+		DustChangeDropped: plan.DustChangeDropped(),
+		DroppedChange:     uint64(plan.DroppedChange),
+	}
+	for _, in := range plan.Inputs {
+		input := &pb.PlannedInput{
+			Txid:     in.OutPoint.Hash.String(),
+			Index:    in.OutPoint.Index,
+			Value:    uint64(in.Value),
+			Sequence: in.Sequence,
+		}
+		if in.Address != nil {
+			input.Address = in.Address.String()
+		}
+		resp.Inputs = append(resp.Inputs, input)
+	}
+	for _, out := range plan.Outputs {
+		output := &pb.PlannedOutput{
+			ScriptPubKey: out.Script,
+			Value:        uint64(out.Value),
+			Change:       out.Change,
+		}
+		if out.Address != nil {
+			output.Address = out.Address.String()
+		}
+		resp.Outputs = append(resp.Outputs, output)
+	}
+	return resp
+}
+
+// planFromProto returns the plan sent back by a client. The wallet checks the
+// inputs and the fee before signing it.
+func planFromProto(wal wallet.Wallet, in *pb.SpendPlan) (*util.SpendPlan, error) {
+	plan := &util.SpendPlan{
+		Fee:           int64(in.Fee),
+		FeePerByte:    in.FeePerByte,
+		VSize:         int(in.Vsize),
+		DroppedChange: int64(in.DroppedChange),
+	}
+	for _, input := range in.Inputs {
+		hash, err := chainhash.NewHashFromStr(input.Txid)
+		if err != nil {
+			return nil, err
+		}
+		plan.Inputs = append(plan.Inputs, util.PlannedInput{
+			OutPoint: *wire.NewOutPoint(hash, input.Index),
+			Value:    int64(input.Value),
+			Sequence: input.Sequence,
+		})
+	}
+	for _, output := range in.Outputs {
+		out := util.PlannedOutput{
+			Script: output.ScriptPubKey,
+			Value:  int64(output.Value),
+			Change: output.Change,
+		}
+		if output.Address != "" {
+			addr, err := wal.DecodeAddress(output.Address)
+			if err != nil {
+				return nil, err
+			}
+			out.Address = addr
+		}
+		plan.Outputs = append(plan.Outputs, out)
+	}
+	return plan, nil
+}
+
+type rawTxHandler interface {
+	BroadcastRawTx(raw []byte) (*chainhash.Hash, error)
+	DecodeRawTx(raw []byte) (*util.DecodedTx, error)
+}
+
+func (s *server) rawTxHandler(in coinSelection) (rawTxHandler, error) {
+	wal, err := s.coinWallet(in)
+	if err != nil {
+		return nil, err
+	}
+	handler, ok := wal.(rawTxHandler)
+	if !ok {
+		return nil, errors.New("wallet does not support raw transactions")
+	}
+	return handler, nil
+}
+
+func (s *server) BroadcastRawTx(ctx context.Context, in *pb.RawTxInfo) (*pb.Txid, error) {
+	handler, err := s.rawTxHandler(in)
+	if err != nil {
+		return nil, err
+	}
+	txid, err := handler.BroadcastRawTx(in.Tx)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.Txid{Coin: in.Coin, CoinName: in.CoinName, Hash: txid.String()}, nil
+}
+
+func (s *server) DecodeRawTx(ctx context.Context, in *pb.RawTxInfo) (*pb.DecodedTx, error) {
+	handler, err := s.rawTxHandler(in)
+	if err != nil {
+		return nil, err
 	}
-	txid, err := wal.Spend(int64(in.Amount), addr, feeLevel, "", false)
+	decoded, err := handler.DecodeRawTx(in.Tx)
 	if err != nil {
 		return nil, err
 	}
-	return &pb.Txid{Coin: in.Coin, Hash: txid.String()}, nil
+	return decodedToProto(in, decoded), nil
+}
+
+func decodedToProto(coin coinSelection, decoded *util.DecodedTx) *pb.DecodedTx {
+	resp := &pb.DecodedTx{
+		Coin:         coin.GetCoin(),
+		CoinName:     coin.GetCoinName(),
+		Txid:         decoded.Txid.String(),
+		Version:      decoded.Version,
+		Locktime:     decoded.LockTime,
+		ExpiryHeight: decoded.ExpiryHeight,
+		Size:         uint64(decoded.Size),
+		Vsize:        uint64(decoded.VSize),
+		Fee:          uint64(decoded.Fee),
+		FeeKnown:     decoded.FeeKnown,
+		Rbf:          decoded.RBF,
+	}
+	resp.Inputs = decodedInputsToProto(decoded.Inputs)
+	for i, out := range decoded.Outputs {
+		output := &pb.DecodedOutput{
+			Index:        uint32(i),
+			ScriptPubKey: out.Script,
+			Value:        uint64(out.Value),
+			Data:         out.Data,
+		}
+		if out.Address != nil {
+			output.Address = out.Address.String()
+		}
+		resp.Outputs = append(resp.Outputs, output)
+	}
+	return resp
+}
+
+func decodedInputsToProto(inputs []util.DecodedInput) []*pb.DecodedInput {
+	var resp []*pb.DecodedInput
+	for _, in := range inputs {
+		input := &pb.DecodedInput{
+			Txid:     in.OutPoint.Hash.String(),
+			Index:    in.OutPoint.Index,
+			Sequence: in.Sequence,
+			Value:    uint64(in.Value),
+			Known:    in.Known,
+		}
+		if in.Address != nil {
+			input.Address = in.Address.String()
+		}
+		resp = append(resp, input)
+	}
+	return resp
+}
+
+// saveSpendMetadata saves the memo and labels given with a spend
+func saveSpendMetadata(wal wallet.Wallet, txid *chainhash.Hash, memo string, labels []string) error {
+	store, ok := wal.(txMetadataStore)
+	if !ok || (memo == "" && len(labels) == 0) {
+		return nil
+	}
+	md, _ := store.TransactionMetadata(*txid)
+	md.Txid = txid.String()
+	md.Memo = memo
+	md.Labels = append(md.Labels, labels...)
+	if err := store.SetTransactionMetadata(md); err != nil {
+		return fmt.Errorf("spent in %s but failed to save the memo and labels: %s", txid, err)
+	}
+	return nil
 }
 
 func (s *server) BumpFee(ctx context.Context, in *pb.Txid) (*pb.Txid, error) {
 	// Stub
-	return &pb.Txid{Coin: in.Coin, Hash: ""}, nil
+	return &pb.Txid{Coin: in.Coin, CoinName: in.CoinName, Hash: ""}, nil
 }
 
 func (s *server) AddWatchedScript(ctx context.Context, in *pb.Address) (*pb.Empty, error) {
@@ -198,7 +796,7 @@ func (s *server) GetConfirmations(ctx context.Context, in *pb.Txid) (*pb.Confirm
 
 func (s *server) SweepAddress(ctx context.Context, in *pb.SweepInfo) (*pb.Txid, error) {
 	// Stub
-	return &pb.Txid{Coin: in.Coin, Hash: ""}, nil
+	return &pb.Txid{Coin: in.Coin, CoinName: in.CoinName, Hash: ""}, nil
 }
 
 func (s *server) CreateMultisigSignature(ctx context.Context, in *pb.CreateMultisigInfo) (*pb.SignatureList, error) {
@@ -206,9 +804,83 @@ func (s *server) CreateMultisigSignature(ctx context.Context, in *pb.CreateMulti
 	return &pb.SignatureList{Sigs: retSigs}, nil
 }
 
+// multisigner is implemented by wallets which sign m of n multisigs with the
+// signatures of any number of cosigners.
+type multisigner interface {
+	MultisignWithSignatures(ins []wallet.TransactionInput, outs []wallet.TransactionOutput, sigs multisig.Signatures, redeemScript []byte, feePerByte uint64, broadcast bool) ([]byte, bool, error)
+	MergeMultisig(redeemScript []byte, txs [][]byte, broadcast bool) ([]byte, bool, error)
+}
+
 func (s *server) Multisign(ctx context.Context, in *pb.MultisignInfo) (*pb.RawTx, error) {
-	// Stub
-	return &pb.RawTx{Tx: []byte{}}, nil
+	wal, err := s.coinWallet(in)
+	if err != nil {
+		return nil, err
+	}
+	var ins []wallet.TransactionInput
+	for _, input := range in.Inputs {
+		h, err := hex.DecodeString(input.Txid)
+		if err != nil {
+			return nil, err
+		}
+		ins = append(ins, wallet.TransactionInput{
+			OutpointHash:  h,
+			OutpointIndex: input.Index,
+			Value:         int64(input.Value),
+		})
+	}
+	var outs []wallet.TransactionOutput
+	for _, output := range in.Outputs {
+		addr, err := wal.ScriptToAddress(output.ScriptPubKey)
+		if err != nil {
+			return nil, err
+		}
+		outs = append(outs, wallet.TransactionOutput{Address: addr, Value: int64(output.Value)})
+	}
+
+	if len(in.Cosigners) == 0 {
+		tx, err := wal.Multisign(ins, outs, signatures(in.Sig1), signatures(in.Sig2), in.RedeemScript, in.FeePerByte, in.Broadcast)
+		if err != nil {
+			return nil, err
+		}
+		return &pb.RawTx{Tx: tx, Complete: true}, nil
+	}
+	signer, ok := wal.(multisigner)
+	if !ok {
+		return nil, errors.New("wallet does not support m of n multisigs")
+	}
+	sigs := make(multisig.Signatures)
+	for _, cosigner := range in.Cosigners {
+		sigs.Add(cosigner.PubKey, signatures(cosigner.Sigs))
+	}
+	tx, complete, err := signer.MultisignWithSignatures(ins, outs, sigs, in.RedeemScript, in.FeePerByte, in.Broadcast)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.RawTx{Tx: tx, Complete: complete}, nil
+}
+
+func (s *server) MergeMultisig(ctx context.Context, in *pb.MergeMultisigInfo) (*pb.RawTx, error) {
+	wal, err := s.coinWallet(in)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := wal.(multisigner)
+	if !ok {
+		return nil, errors.New("wallet does not support m of n multisigs")
+	}
+	tx, complete, err := signer.MergeMultisig(in.RedeemScript, in.Txs, in.Broadcast)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.RawTx{Tx: tx, Complete: complete}, nil
+}
+
+func signatures(sigs []*pb.Signature) []wallet.Signature {
+	var ret []wallet.Signature
+	for _, sig := range sigs {
+		ret = append(ret, wallet.Signature{InputIndex: sig.Index, Signature: sig.Signature})
+	}
+	return ret
 }
 
 func (s *server) EstimateFee(ctx context.Context, in *pb.EstimateFeeData) (*pb.Fee, error) {
@@ -252,30 +924,248 @@ func (h *HeaderWriter) Write(p []byte) (n int, err error) {
 
 func (s *server) DumpTables(in *pb.CoinSelection, stream pb.API_DumpTablesServer) error {
 	writer := HeaderWriter{stream}
-	ct := coinType(in.Coin)
-	wal, err := s.w.WalletForCurrencyCode(ct.CurrencyCode())
+	wal, err := s.coinWallet(in)
 	if err != nil {
 		return err
 	}
-	bitcoinWallet, ok := wal.(*bitcoin.BitcoinWallet)
-	if ok {
-		bitcoinWallet.DumpTables(&writer)
-		return nil
+	if dumper, ok := wal.(tableDumper); ok {
+		dumper.DumpTables(&writer)
 	}
-	bitcoincashWallet, ok := wal.(*bitcoincash.BitcoinCashWallet)
-	if ok {
-		bitcoincashWallet.DumpTables(&writer)
-		return nil
+	return nil
+}
+
+type tableDumper interface {
+	DumpTables(wr io.Writer)
+}
+
+type transactionExporter interface {
+	ExportTransactions(currency string) ([]service.ExportRow, error)
+}
+
+var exportHeader = []string{"date", "coin", "txid", "direction", "amount", "fee", "balance", "label", "fiatValue", "currency"}
+
+// exportJSON is a row of a JSON export. Amounts are in coins and are null if
+// unknown.
+type exportJSON struct {
+	Date      string       `json:"date"`
+	Coin      string       `json:"coin"`
+	Txid      string       `json:"txid"`
+	Direction string       `json:"direction"`
+	Amount    json.Number  `json:"amount"`
+	Fee       *json.Number `json:"fee"`
+	Balance   json.Number  `json:"balance"`
+	Label     string       `json:"label"`
+	FiatValue *float64     `json:"fiatValue"`
+	Currency  string       `json:"currency"`
+}
+
+func (s *server) ExportTransactions(in *pb.ExportRequest, stream pb.API_ExportTransactionsServer) error {
+	var (
+		rows []service.ExportRow
+		err  error
+	)
+	if in.AllCoins {
+		rows, err = s.w.ExportTransactions(in.Currency)
+	} else {
+		wal, werr := s.coinWallet(in)
+		if werr != nil {
+			return werr
+		}
+		exporter, ok := wal.(transactionExporter)
+		if !ok {
+			return errors.New("wallet does not export transactions")
+		}
+		rows, err = exporter.ExportTransactions(in.Currency)
 	}
-	litecoinWallet, ok := wal.(*litecoin.LitecoinWallet)
-	if ok {
-		litecoinWallet.DumpTables(&writer)
-		return nil
+	if err != nil {
+		return err
 	}
-	zcashWallet, ok := wal.(*zcash.ZCashWallet)
-	if ok {
-		zcashWallet.DumpTables(&writer)
+
+	if in.Format == pb.ExportFormat_JSON {
+		for _, row := range rows {
+			b, err := json.Marshal(exportRowJSON(row))
+			if err != nil {
+				return err
+			}
+			if err := stream.Send(&pb.Row{Data: string(b)}); err != nil {
+				return err
+			}
+		}
 		return nil
 	}
+	if err := stream.Send(&pb.Row{Data: csvRecord(exportHeader)}); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		record := []string{
+			row.Time.UTC().Format(time.RFC3339),
+			row.Coin,
+			row.Txid,
+			row.Direction.String(),
+			formatCoins(row.Amount, row.CoinType),
+			"",
+			formatCoins(row.Balance, row.CoinType),
+			row.Label,
+			"",
+			row.Currency,
+		}
+		if row.FeeKnown {
+			record[5] = formatCoins(row.Fee, row.CoinType)
+		}
+		if row.FiatKnown {
+			record[8] = strconv.FormatFloat(row.FiatValue, 'f', 2, 64)
+		}
+		if err := stream.Send(&pb.Row{Data: csvRecord(record)}); err != nil {
+			return err
+		}
+	}
 	return nil
 }
+
+func exportRowJSON(row service.ExportRow) exportJSON {
+	r := exportJSON{
+		Date:      row.Time.UTC().Format(time.RFC3339),
+		Coin:      row.Coin,
+		Txid:      row.Txid,
+		Direction: row.Direction.String(),
+		Amount:    json.Number(formatCoins(row.Amount, row.CoinType)),
+		Balance:   json.Number(formatCoins(row.Balance, row.CoinType)),
+		Label:     row.Label,
+		Currency:  row.Currency,
+	}
+	if row.FeeKnown {
+		fee := json.Number(formatCoins(row.Fee, row.CoinType))
+		r.Fee = &fee
+	}
+	if row.FiatKnown {
+		value := math.Round(row.FiatValue*100) / 100
+		r.FiatValue = &value
+	}
+	return r
+}
+
+// formatCoins formats an amount in satoshis as a decimal number of coins
+func formatCoins(amount int64, ct util.ExtCoinType) string {
+	return strconv.FormatFloat(float64(amount)/util.SatoshisPerCoin(ct.ToCoinType()), 'f', -1, 64)
+}
+
+// csvRecord returns record as a CSV line without the line break
+func csvRecord(record []string) string {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write(record)
+	w.Flush()
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+type backendStatusProvider interface {
+	BackendStatus() []client.BackendStatus
+}
+
+func (s *server) BackendStatus(ctx context.Context, in *pb.CoinSelection) (*pb.BackendList, error) {
+	wal, err := s.coinWallet(in)
+	if err != nil {
+		return nil, err
+	}
+	provider, ok := wal.(backendStatusProvider)
+	if !ok {
+		return nil, errors.New("wallet does not report backend status")
+	}
+	var list []*pb.Backend
+	for _, status := range provider.BackendStatus() {
+		list = append(list, &pb.Backend{
+			Url:          string(status.Target),
+			Current:      status.Current,
+			Healthy:      status.Healthy,
+			LatencyMs:    uint64(status.Latency / time.Millisecond),
+			ErrorRate:    status.ErrorRate,
+			BlockHeight:  int32(status.BlockHeight),
+			BlocksBehind: int32(status.BlocksBehind),
+			Requests:     status.Requests,
+			Score:        status.Score,
+		})
+	}
+	return &pb.BackendList{Backends: list}, nil
+}
+
+func (s *server) metadataStore(in coinSelection) (txMetadataStore, wallet.Wallet, error) {
+	wal, err := s.coinWallet(in)
+	if err != nil {
+		return nil, nil, err
+	}
+	store, ok := wal.(txMetadataStore)
+	if !ok {
+		return nil, nil, errors.New("wallet does not record transaction metadata")
+	}
+	return store, wal, nil
+}
+
+func (s *server) GetTransactionMetadata(ctx context.Context, in *pb.Txid) (*pb.TxMetadata, error) {
+	store, _, err := s.metadataStore(in)
+	if err != nil {
+		return nil, err
+	}
+	txid, err := chainhash.NewHashFromStr(in.Hash)
+	if err != nil {
+		return nil, err
+	}
+	md, _ := store.TransactionMetadata(*txid)
+	return &pb.TxMetadata{
+		Coin:         in.Coin,
+		CoinName:     in.CoinName,
+		Txid:         txid.String(),
+		ReferenceID:  md.ReferenceID,
+		Memo:         md.Memo,
+		Labels:       md.Labels,
+		Counterparty: md.Counterparty,
+	}, nil
+}
+
+func (s *server) SetTransactionMetadata(ctx context.Context, in *pb.TxMetadata) (*pb.Empty, error) {
+	store, _, err := s.metadataStore(in)
+	if err != nil {
+		return nil, err
+	}
+	txid, err := chainhash.NewHashFromStr(in.Txid)
+	if err != nil {
+		return nil, err
+	}
+	err = store.SetTransactionMetadata(service.TxMetadata{
+		Txid:         txid.String(),
+		ReferenceID:  in.ReferenceID,
+		Memo:         in.Memo,
+		Labels:       in.Labels,
+		Counterparty: in.Counterparty,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &pb.Empty{}, nil
+}
+
+func (s *server) GetTransactionByReference(ctx context.Context, in *pb.Reference) (*pb.Tx, error) {
+	store, wal, err := s.metadataStore(in)
+	if err != nil {
+		return nil, err
+	}
+	txn, err := store.TransactionByReference(in.ReferenceID)
+	if err != nil {
+		return nil, err
+	}
+	return txToProto(wal, txn)
+}
+
+func (s *server) SetAddressLabel(ctx context.Context, in *pb.AddressLabel) (*pb.Empty, error) {
+	store, wal, err := s.metadataStore(in)
+	if err != nil {
+		return nil, err
+	}
+	addr, err := wal.DecodeAddress(in.Address)
+	if err != nil {
+		return nil, err
+	}
+	if err := store.SetAddressLabel(addr, in.Label); err != nil {
+		return nil, err
+	}
+	return &pb.Empty{}, nil
+}
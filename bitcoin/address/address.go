@@ -0,0 +1,234 @@
+package address
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcutil/bech32"
+)
+
+// UnsupportedWitnessVerError describes an error where a bech32m address being
+// decoded has a witness version other than taproot's.
+type UnsupportedWitnessVerError byte
+
+func (e UnsupportedWitnessVerError) Error() string {
+	return fmt.Sprintf("unsupported witness version: %d", byte(e))
+}
+
+var (
+	// ErrChecksumMismatch describes an error where decoding failed due
+	// to a bad bech32m checksum.
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+
+	// ErrUnknownAddressType describes an error where an output script
+	// does not pay to any known address type.
+	ErrUnknownAddressType = errors.New("unknown address type")
+)
+
+const (
+	// TaprootWitnessVersion is the segwit version of pay-to-taproot outputs
+	TaprootWitnessVersion = 1
+
+	// bech32mConst is the checksum constant of BIP350. The btcutil bech32
+	// package only verifies the original bech32 checksum used by version 0
+	// addresses.
+	bech32mConst = 0x2bc830a3
+
+	charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+)
+
+var gen = [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+
+func polymod(values []byte) uint32 {
+	chk := uint32(1)
+	for _, v := range values {
+		b := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (b>>uint(i))&1 == 1 {
+				chk ^= gen[i]
+			}
+		}
+	}
+	return chk
+}
+
+func hrpExpand(hrp string) []byte {
+	v := make([]byte, 0, len(hrp)*2+1)
+	for i := 0; i < len(hrp); i++ {
+		v = append(v, hrp[i]>>5)
+	}
+	v = append(v, 0)
+	for i := 0; i < len(hrp); i++ {
+		v = append(v, hrp[i]&31)
+	}
+	return v
+}
+
+// encodeBech32m encodes the 5 bit groups in data with a bech32m checksum
+func encodeBech32m(hrp string, data []byte) string {
+	values := append(hrpExpand(hrp), data...)
+	mod := polymod(append(values, 0, 0, 0, 0, 0, 0)) ^ bech32mConst
+
+	encoded := make([]byte, 0, len(hrp)+1+len(data)+6)
+	encoded = append(append(encoded, hrp...), '1')
+	for _, d := range data {
+		encoded = append(encoded, charset[d])
+	}
+	for i := 0; i < 6; i++ {
+		encoded = append(encoded, charset[(mod>>uint(5*(5-i)))&31])
+	}
+	return string(encoded)
+}
+
+// decodeBech32m returns the human-readable part and the 5 bit groups of a
+// bech32m string with the checksum removed.
+func decodeBech32m(s string) (string, []byte, error) {
+	if len(s) < 8 || len(s) > 90 {
+		return "", nil, fmt.Errorf("invalid bech32m string length %d", len(s))
+	}
+	lower := strings.ToLower(s)
+	if lower != s && strings.ToUpper(s) != s {
+		return "", nil, errors.New("mixed case bech32m string")
+	}
+	s = lower
+
+	one := strings.LastIndexByte(s, '1')
+	if one < 1 || one+7 > len(s) {
+		return "", nil, errors.New("invalid bech32m separator position")
+	}
+	hrp := s[:one]
+	data := make([]byte, 0, len(s)-one-1)
+	for i := one + 1; i < len(s); i++ {
+		d := strings.IndexByte(charset, s[i])
+		if d < 0 {
+			return "", nil, fmt.Errorf("invalid bech32m character %q", s[i])
+		}
+		data = append(data, byte(d))
+	}
+	if polymod(append(hrpExpand(hrp), data...)) != bech32mConst {
+		return "", nil, ErrChecksumMismatch
+	}
+	return hrp, data[:len(data)-6], nil
+}
+
+// AddressTaproot is a pay-to-taproot (P2TR) address, a segwit version 1
+// output committing to a 32 byte x-only public key.
+type AddressTaproot struct {
+	hrp            string
+	witnessProgram [32]byte
+}
+
+// NewAddressTaproot returns a new AddressTaproot paying to the x-only output key
+func NewAddressTaproot(witnessProg []byte, net *chaincfg.Params) (*AddressTaproot, error) {
+	return newAddressTaproot(net.Bech32HRPSegwit, witnessProg)
+}
+
+func newAddressTaproot(hrp string, witnessProg []byte) (*AddressTaproot, error) {
+	if len(witnessProg) != 32 {
+		return nil, errors.New("witness program must be 32 bytes for p2tr")
+	}
+	addr := &AddressTaproot{hrp: strings.ToLower(hrp)}
+	copy(addr.witnessProgram[:], witnessProg)
+	return addr, nil
+}
+
+// EncodeAddress returns the bech32m string encoding of the address
+func (a *AddressTaproot) EncodeAddress() string {
+	converted, err := bech32.ConvertBits(a.witnessProgram[:], 8, 5, true)
+	if err != nil {
+		return ""
+	}
+	return encodeBech32m(a.hrp, append([]byte{TaprootWitnessVersion}, converted...))
+}
+
+// ScriptAddress returns the witness program, the x-only output key
+func (a *AddressTaproot) ScriptAddress() []byte {
+	return a.witnessProgram[:]
+}
+
+// IsForNet returns whether or not the address is associated with the passed
+// bitcoin network.
+func (a *AddressTaproot) IsForNet(net *chaincfg.Params) bool {
+	return a.hrp == net.Bech32HRPSegwit
+}
+
+// String returns the bech32m string encoding of the address
+func (a *AddressTaproot) String() string {
+	return a.EncodeAddress()
+}
+
+// Hrp returns the human-readable part of the address
+func (a *AddressTaproot) Hrp() string {
+	return a.hrp
+}
+
+// WitnessVersion returns the witness version of the address
+func (a *AddressTaproot) WitnessVersion() byte {
+	return TaprootWitnessVersion
+}
+
+// WitnessProgram returns the witness program of the address
+func (a *AddressTaproot) WitnessProgram() []byte {
+	return a.witnessProgram[:]
+}
+
+// DecodeAddress decodes a bitcoin address of any type btcutil supports as
+// well as bech32m encoded taproot addresses.
+func DecodeAddress(addr string, params *chaincfg.Params) (btcutil.Address, error) {
+	oneIndex := strings.LastIndexByte(addr, '1')
+	if oneIndex > 1 && chaincfg.IsBech32SegwitPrefix(strings.ToLower(addr[:oneIndex+1])) {
+		if hrp, data, err := decodeBech32m(addr); err == nil {
+			if len(data) < 1 {
+				return nil, errors.New("no witness version")
+			}
+			if data[0] != TaprootWitnessVersion {
+				return nil, UnsupportedWitnessVerError(data[0])
+			}
+			prog, err := bech32.ConvertBits(data[1:], 5, 8, false)
+			if err != nil {
+				return nil, err
+			}
+			return newAddressTaproot(hrp, prog)
+		}
+	}
+	return btcutil.DecodeAddress(addr, params)
+}
+
+// IsPayToTaproot returns whether the script is a pay-to-taproot output script
+func IsPayToTaproot(script []byte) bool {
+	return len(script) == 1+1+32 && script[0] == txscript.OP_1 && script[1] == txscript.OP_DATA_32
+}
+
+// PayToAddrScript returns the output script paying to addr
+func PayToAddrScript(addr btcutil.Address) ([]byte, error) {
+	if addr, ok := addr.(*AddressTaproot); ok {
+		if addr == nil {
+			return nil, errors.New("unable to generate payment script for nil address")
+		}
+		return txscript.NewScriptBuilder().
+			AddOp(txscript.OP_1).
+			AddData(addr.ScriptAddress()).
+			Script()
+	}
+	return txscript.PayToAddrScript(addr)
+}
+
+// ExtractPkScriptAddrs returns the address paid to by an output script
+func ExtractPkScriptAddrs(pkScript []byte, chainParams *chaincfg.Params) (btcutil.Address, error) {
+	if IsPayToTaproot(pkScript) {
+		return NewAddressTaproot(pkScript[2:], chainParams)
+	}
+	_, addrs, _, err := txscript.ExtractPkScriptAddrs(pkScript, chainParams)
+	if err != nil {
+		return nil, err
+	}
+	if len(addrs) == 0 {
+		return nil, ErrUnknownAddressType
+	}
+	return addrs[0], nil
+}
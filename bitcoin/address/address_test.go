@@ -0,0 +1,139 @@
+package address
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcutil"
+)
+
+func TestDecodeTaprootAddress(t *testing.T) {
+	tests := []struct {
+		addr    string
+		params  *chaincfg.Params
+		program string
+	}{
+		{
+			"bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqzk5jj0",
+			&chaincfg.MainNetParams,
+			"79be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798",
+		},
+		{
+			"BC1P0XLXVLHEMJA6C4DQV22UAPCTQUPFHLXM9H8Z3K2E72Q4K9HCZ7VQZK5JJ0",
+			&chaincfg.MainNetParams,
+			"79be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798",
+		},
+		{
+			"bc1p5cyxnuxmeuwuvkwfem96lqzszd02n6xdcjrs20cac6yqjjwudpxqkedrcr",
+			&chaincfg.MainNetParams,
+			"a60869f0dbcf1dc659c9cecbaf8050135ea9e8cdc487053f1dc6880949dc684c",
+		},
+		{
+			"tb1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vq47zagq",
+			&chaincfg.TestNet3Params,
+			"79be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798",
+		},
+	}
+	for _, test := range tests {
+		addr, err := DecodeAddress(test.addr, test.params)
+		if err != nil {
+			t.Errorf("%s: %s", test.addr, err)
+			continue
+		}
+		tr, ok := addr.(*AddressTaproot)
+		if !ok {
+			t.Errorf("%s: decoded as %T", test.addr, addr)
+			continue
+		}
+		program, _ := hex.DecodeString(test.program)
+		if !bytes.Equal(tr.WitnessProgram(), program) {
+			t.Errorf("%s: expected program %x but had %x", test.addr, program, tr.WitnessProgram())
+		}
+		if !tr.IsForNet(test.params) {
+			t.Errorf("%s: address is not for network %s", test.addr, test.params.Name)
+		}
+		encoded, err := NewAddressTaproot(program, test.params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if encoded.String() != tr.String() {
+			t.Errorf("%s: re-encoded as %s", test.addr, encoded.String())
+		}
+	}
+}
+
+func TestDecodeAddressInvalid(t *testing.T) {
+	for _, addr := range []string{
+		// Version 1 program with a bech32 checksum
+		"bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqh2y7hd",
+		// Mixed case
+		"bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqZk5jj0",
+		// Bad checksum
+		"bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqzk5jj1",
+	} {
+		if _, err := DecodeAddress(addr, &chaincfg.MainNetParams); err == nil {
+			t.Errorf("Decoded invalid address %s", addr)
+		}
+	}
+}
+
+func TestDecodeAddressLegacy(t *testing.T) {
+	for _, addr := range []string{
+		"17rxURoF96VhmkcEGCj5LNQkmN9HVhWb7F",
+		"bc1qxtq7ha2l5qg70atpwp3fus84fx3w0v2w4r2my7gt89ll3w0vnlgspu349h",
+	} {
+		decoded, err := DecodeAddress(addr, &chaincfg.MainNetParams)
+		if err != nil {
+			t.Errorf("%s: %s", addr, err)
+			continue
+		}
+		if decoded.String() != addr {
+			t.Errorf("Expected %s but had %s", addr, decoded.String())
+		}
+	}
+}
+
+func TestPayToAddrScript(t *testing.T) {
+	program, _ := hex.DecodeString("a60869f0dbcf1dc659c9cecbaf8050135ea9e8cdc487053f1dc6880949dc684c")
+	addr, err := NewAddressTaproot(program, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	script, err := PayToAddrScript(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, _ := hex.DecodeString("5120a60869f0dbcf1dc659c9cecbaf8050135ea9e8cdc487053f1dc6880949dc684c")
+	if !bytes.Equal(script, expected) {
+		t.Errorf("Expected script %x but had %x", expected, script)
+	}
+	if !IsPayToTaproot(script) {
+		t.Error("Script not recognized as pay-to-taproot")
+	}
+
+	extracted, err := ExtractPkScriptAddrs(script, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if extracted.String() != "bc1p5cyxnuxmeuwuvkwfem96lqzszd02n6xdcjrs20cac6yqjjwudpxqkedrcr" {
+		t.Errorf("Extracted incorrect address %s", extracted.String())
+	}
+
+	legacy, err := btcutil.DecodeAddress("17rxURoF96VhmkcEGCj5LNQkmN9HVhWb7F", &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	script, err = PayToAddrScript(legacy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	extracted, err = ExtractPkScriptAddrs(script, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if extracted.String() != legacy.String() {
+		t.Errorf("Extracted incorrect address %s", extracted.String())
+	}
+}
@@ -24,18 +24,52 @@ import (
 	"github.com/btcsuite/btcwallet/wallet/txauthor"
 	"github.com/btcsuite/btcwallet/wallet/txrules"
 
+	btcaddr "github.com/muecoin/multiwallet/bitcoin/address"
+	"github.com/muecoin/multiwallet/htlc"
+	"github.com/muecoin/multiwallet/multisig"
 	"github.com/muecoin/multiwallet/util"
 )
 
 func (w *BitcoinWallet) buildTx(amount int64, addr btc.Address, feeLevel wi.FeeLevel, optionalOutput *wire.TxOut) (*wire.MsgTx, error) {
-	// Check for dust
-	script, _ := txscript.PayToAddrScript(addr)
-	if txrules.IsDustAmount(btc.Amount(amount), len(script), txrules.DefaultRelayFeePerKb) {
-		return nil, wi.ErrorDustAmount
+	return w.buildBatchTx([]wi.TransactionOutput{{Address: addr, Value: amount}}, feeLevel, optionalOutput)
+}
+
+// buildBatchTx builds a transaction paying every payment and optionalOutput
+// if set.
+func (w *BitcoinWallet) buildBatchTx(payments []wi.TransactionOutput, feeLevel wi.FeeLevel, optionalOutput *wire.TxOut) (*wire.MsgTx, error) {
+	plan, err := w.planBatchTx(payments, feeLevel, optionalOutput)
+	if err != nil {
+		return nil, err
 	}
+	return w.signPlan(plan)
+}
 
-	var additionalPrevScripts map[wire.OutPoint][]byte
-	var additionalKeysByAddress map[string]*btc.WIF
+func (w *BitcoinWallet) planBatchTx(payments []wi.TransactionOutput, feeLevel wi.FeeLevel, optionalOutput *wire.TxOut) (*util.SpendPlan, error) {
+	if len(payments) == 0 {
+		return nil, errors.New("no payments to send")
+	}
+	var outputs []*wire.TxOut
+	for _, payment := range payments {
+		// Check for dust
+		script, err := w.AddressToScript(payment.Address)
+		if err != nil {
+			return nil, err
+		}
+		if txrules.IsDustAmount(btc.Amount(payment.Value), len(script), txrules.DefaultRelayFeePerKb) {
+			return nil, wi.ErrorDustAmount
+		}
+		outputs = append(outputs, wire.NewTxOut(payment.Value, script))
+	}
+	if optionalOutput != nil {
+		outputs = append(outputs, optionalOutput)
+	}
+	return w.planTxWithOutputs(outputs, feeLevel)
+}
+
+// planTxWithOutputs selects the coins paying outputs at feeLevel and adds a
+// change output unless the change is dust.
+func (w *BitcoinWallet) planTxWithOutputs(outputs []*wire.TxOut, feeLevel wi.FeeLevel) (*util.SpendPlan, error) {
+	var prevOuts map[wire.OutPoint]*wire.TxOut
 
 	// Create input source
 	height, _ := w.ws.ChainTip()
@@ -55,26 +89,14 @@ func (w *BitcoinWallet) buildTx(amount int64, addr btc.Address, feeLevel wi.FeeL
 		if err != nil {
 			return total, inputs, inputValues, scripts, wi.ErrorInsuffientFunds
 		}
-		additionalPrevScripts = make(map[wire.OutPoint][]byte)
-		additionalKeysByAddress = make(map[string]*btc.WIF)
+		prevOuts = make(map[wire.OutPoint]*wire.TxOut)
 		for _, c := range coins.Coins() {
 			total += c.Value()
 			outpoint := wire.NewOutPoint(c.Hash(), c.Index())
 			in := wire.NewTxIn(outpoint, []byte{}, [][]byte{})
 			in.Sequence = 0 // Opt-in RBF so we can bump fees
 			inputs = append(inputs, in)
-			additionalPrevScripts[*outpoint] = c.PkScript()
-			key := coinMap[c]
-			addr, err := key.Address(w.params)
-			if err != nil {
-				continue
-			}
-			privKey, err := key.ECPrivKey()
-			if err != nil {
-				continue
-			}
-			wif, _ := btc.NewWIF(privKey, w.params, true)
-			additionalKeysByAddress[addr.EncodeAddress()] = wif
+			prevOuts[*outpoint] = wire.NewTxOut(int64(c.Value()), c.PkScript())
 		}
 		return total, inputs, inputValues, scripts, nil
 	}
@@ -82,24 +104,19 @@ func (w *BitcoinWallet) buildTx(amount int64, addr btc.Address, feeLevel wi.FeeL
 	// Get the fee per kilobyte
 	feePerKB := int64(w.GetFeePerByte(feeLevel)) * 1000
 
-	// outputs
-	out := wire.NewTxOut(amount, script)
-
 	// Create change source
+	var changeScript []byte
 	changeSource := func() ([]byte, error) {
 		addr := w.CurrentAddress(wi.INTERNAL)
-		script, err := txscript.PayToAddrScript(addr)
+		script, err := w.AddressToScript(addr)
 		if err != nil {
 			return []byte{}, err
 		}
+		changeScript = script
 		return script, nil
 	}
 
-	outputs := []*wire.TxOut{out}
-	if optionalOutput != nil {
-		outputs = append(outputs, optionalOutput)
-	}
-	authoredTx, err := newUnsignedTransaction(outputs, btc.Amount(feePerKB), inputSource, changeSource)
+	authoredTx, err := newUnsignedTransaction(outputs, btc.Amount(feePerKB), w.inputType(), inputSource, changeSource)
 	if err != nil {
 		return nil, err
 	}
@@ -107,30 +124,28 @@ func (w *BitcoinWallet) buildTx(amount int64, addr btc.Address, feeLevel wi.FeeL
 	// BIP 69 sorting
 	txsort.InPlaceSort(authoredTx.Tx)
 
-	// Sign tx
-	getKey := txscript.KeyClosure(func(addr btc.Address) (*btcec.PrivateKey, bool, error) {
-		addrStr := addr.EncodeAddress()
-		wif := additionalKeysByAddress[addrStr]
-		return wif.PrivKey, wif.CompressPubKey, nil
-	})
-	getScript := txscript.ScriptClosure(func(
-		addr btc.Address) ([]byte, error) {
-		return []byte{}, nil
-	})
-	for i, txIn := range authoredTx.Tx.TxIn {
-		prevOutScript := additionalPrevScripts[txIn.PreviousOutPoint]
-		script, err := txscript.SignTxOutput(w.params,
-			authoredTx.Tx, i, prevOutScript, txscript.SigHashAll, getKey,
-			getScript, txIn.SignatureScript)
-		if err != nil {
-			return nil, errors.New("Failed to sign transaction")
-		}
-		txIn.SignatureScript = script
+	plan, err := util.NewSpendPlan(authoredTx.Tx, prevOuts, changeScript, w.ScriptToAddress)
+	if err != nil {
+		return nil, err
+	}
+	plan.FeePerByte = w.GetFeePerByte(feeLevel)
+	plan.VSize = EstimateSerializeSize(len(plan.Inputs), authoredTx.Tx.TxOut, false, w.inputType())
+	if authoredTx.ChangeIndex < 0 {
+		requiredFee := txrules.FeeForSerializeSize(btc.Amount(feePerKB), EstimateSerializeSize(len(plan.Inputs), outputs, true, w.inputType()))
+		plan.DroppedChange = plan.Fee - int64(requiredFee)
 	}
-	return authoredTx.Tx, nil
+	return plan, nil
 }
 
-func (w *BitcoinWallet) buildSpendAllTx(addr btc.Address, feeLevel wi.FeeLevel) (*wire.MsgTx, error) {
+func (w *BitcoinWallet) buildSpendAllTx(addr btc.Address, feeLevel wi.FeeLevel, optionalOutput *wire.TxOut) (*wire.MsgTx, error) {
+	plan, err := w.planSpendAllTx(addr, feeLevel, optionalOutput)
+	if err != nil {
+		return nil, err
+	}
+	return w.signPlan(plan)
+}
+
+func (w *BitcoinWallet) planSpendAllTx(addr btc.Address, feeLevel wi.FeeLevel, optionalOutput *wire.TxOut) (*util.SpendPlan, error) {
 	tx := wire.NewMsgTx(1)
 
 	height, _ := w.ws.ChainTip()
@@ -140,17 +155,26 @@ func (w *BitcoinWallet) buildSpendAllTx(addr btc.Address, feeLevel wi.FeeLevel)
 	}
 	coinMap := util.GatherCoins(height, utxos, w.ScriptToAddress, w.km.GetKeyForScript)
 
-	totalIn, _, additionalPrevScripts, additionalKeysByAddress := util.LoadAllInputs(tx, coinMap, w.params)
+	totalIn, inVals, additionalPrevScripts, _ := util.LoadAllInputs(tx, coinMap, w.params)
+	prevOuts := make(map[wire.OutPoint]*wire.TxOut)
+	for op, script := range additionalPrevScripts {
+		prevOuts[op] = wire.NewTxOut(inVals[op], script)
+	}
 
 	// outputs
-	script, err := txscript.PayToAddrScript(addr)
+	script, err := w.AddressToScript(addr)
 	if err != nil {
 		return nil, err
 	}
 
+	outputs := []*wire.TxOut{wire.NewTxOut(0, script)}
+	if optionalOutput != nil {
+		outputs = append(outputs, optionalOutput)
+	}
+
 	// Get the fee
 	feePerByte := int64(w.GetFeePerByte(feeLevel))
-	estimatedSize := EstimateSerializeSize(1, []*wire.TxOut{wire.NewTxOut(0, script)}, false, P2PKH)
+	estimatedSize := EstimateSerializeSize(1, outputs, false, w.inputType())
 	fee := int64(estimatedSize) * feePerByte
 
 	// Check for dust output
@@ -161,11 +185,42 @@ func (w *BitcoinWallet) buildSpendAllTx(addr btc.Address, feeLevel wi.FeeLevel)
 	// Build the output
 	out := wire.NewTxOut(totalIn-fee, script)
 	tx.TxOut = append(tx.TxOut, out)
+	if optionalOutput != nil {
+		tx.TxOut = append(tx.TxOut, optionalOutput)
+	}
 
 	// BIP 69 sorting
 	txsort.InPlaceSort(tx)
 
+	plan, err := util.NewSpendPlan(tx, prevOuts, nil, w.ScriptToAddress)
+	if err != nil {
+		return nil, err
+	}
+	plan.FeePerByte = uint64(feePerByte)
+	plan.VSize = EstimateSerializeSize(len(tx.TxIn), tx.TxOut, false, w.inputType())
+	return plan, nil
+}
+
+// signPlan signs the transaction of plan. It fails if the wallet can no
+// longer spend the inputs of the plan.
+func (w *BitcoinWallet) signPlan(plan *util.SpendPlan) (*wire.MsgTx, error) {
+	height, _ := w.ws.ChainTip()
+	utxos, err := w.db.Utxos().GetAll()
+	if err != nil {
+		return nil, err
+	}
+	coinMap, err := plan.Coins(util.GatherCoins(height, utxos, w.ScriptToAddress, w.km.GetKeyForScript))
+	if err != nil {
+		return nil, err
+	}
+	_, inVals, additionalPrevScripts, additionalKeysByAddress := util.LoadAllInputs(wire.NewMsgTx(1), coinMap, w.params)
+	prevOuts := make(map[wire.OutPoint]*wire.TxOut)
+	for op, script := range additionalPrevScripts {
+		prevOuts[op] = wire.NewTxOut(inVals[op], script)
+	}
+
 	// Sign
+	tx := plan.Tx()
 	getKey := txscript.KeyClosure(func(addr btc.Address) (*btcec.PrivateKey, bool, error) {
 		addrStr := addr.EncodeAddress()
 		wif, ok := additionalKeysByAddress[addrStr]
@@ -174,31 +229,60 @@ func (w *BitcoinWallet) buildSpendAllTx(addr btc.Address, feeLevel wi.FeeLevel)
 		}
 		return wif.PrivKey, wif.CompressPubKey, nil
 	})
+	if err := w.signInputs(tx, prevOuts, getKey); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// signInputs signs every input of tx spending a P2PKH or P2TR output of the
+// wallet. Taproot inputs are signed through the key path with the BIP86
+// tweaked key, which requires the previous output of every input.
+func (w *BitcoinWallet) signInputs(tx *wire.MsgTx, prevOuts map[wire.OutPoint]*wire.TxOut, getKey txscript.KeyDB) error {
 	getScript := txscript.ScriptClosure(func(
 		addr btc.Address) ([]byte, error) {
 		return []byte{}, nil
 	})
 	for i, txIn := range tx.TxIn {
-		prevOutScript := additionalPrevScripts[txIn.PreviousOutPoint]
+		prevOut, ok := prevOuts[txIn.PreviousOutPoint]
+		if !ok {
+			return errors.New("failed to sign transaction: missing previous output")
+		}
+		if btcaddr.IsPayToTaproot(prevOut.PkScript) {
+			key, err := w.km.GetKeyForScript(prevOut.PkScript[2:])
+			if err != nil {
+				return err
+			}
+			privKey, err := key.ECPrivKey()
+			if err != nil {
+				return err
+			}
+			witness, err := taprootKeySpendWitness(tx, i, prevOuts, privKey)
+			if err != nil {
+				return fmt.Errorf("failed to sign transaction: %s", err)
+			}
+			txIn.Witness = witness
+			continue
+		}
 		script, err := txscript.SignTxOutput(w.params,
-			tx, i, prevOutScript, txscript.SigHashAll, getKey,
+			tx, i, prevOut.PkScript, txscript.SigHashAll, getKey,
 			getScript, txIn.SignatureScript)
 		if err != nil {
-			return nil, errors.New("failed to sign transaction")
+			return errors.New("failed to sign transaction")
 		}
 		txIn.SignatureScript = script
 	}
-	return tx, nil
+	return nil
 }
 
-func newUnsignedTransaction(outputs []*wire.TxOut, feePerKb btc.Amount, fetchInputs txauthor.InputSource, fetchChange txauthor.ChangeSource) (*txauthor.AuthoredTx, error) {
+func newUnsignedTransaction(outputs []*wire.TxOut, feePerKb btc.Amount, inputType InputType, fetchInputs txauthor.InputSource, fetchChange txauthor.ChangeSource) (*txauthor.AuthoredTx, error) {
 
 	var targetAmount btc.Amount
 	for _, txOut := range outputs {
 		targetAmount += btc.Amount(txOut.Value)
 	}
 
-	estimatedSize := EstimateSerializeSize(1, outputs, true, P2PKH)
+	estimatedSize := EstimateSerializeSize(1, outputs, true, inputType)
 	targetFee := txrules.FeeForSerializeSize(feePerKb, estimatedSize)
 
 	for {
@@ -210,7 +294,7 @@ func newUnsignedTransaction(outputs []*wire.TxOut, feePerKb btc.Amount, fetchInp
 			return nil, errors.New("insufficient funds available to construct transaction")
 		}
 
-		maxSignedSize := EstimateSerializeSize(len(inputs), outputs, true, P2PKH)
+		maxSignedSize := EstimateSerializeSize(len(inputs), outputs, true, inputType)
 		maxRequiredFee := txrules.FeeForSerializeSize(feePerKb, maxSignedSize)
 		remainingAmount := inputAmount - targetAmount
 		if remainingAmount < maxRequiredFee {
@@ -226,15 +310,16 @@ func newUnsignedTransaction(outputs []*wire.TxOut, feePerKb btc.Amount, fetchInp
 		}
 		changeIndex := -1
 		changeAmount := inputAmount - targetAmount - maxRequiredFee
+		changeSize := ChangeOutputSize(inputType)
 		if changeAmount != 0 && !txrules.IsDustAmount(changeAmount,
-			P2PKHOutputSize, txrules.DefaultRelayFeePerKb) {
+			changeSize, txrules.DefaultRelayFeePerKb) {
 			changeScript, err := fetchChange()
 			if err != nil {
 				return nil, err
 			}
-			if len(changeScript) > P2PKHPkScriptSize {
+			if wire.NewTxOut(0, changeScript).SerializeSize() > changeSize {
 				return nil, errors.New("fee estimation requires change " +
-					"scripts no larger than P2PKH output scripts")
+					"scripts no larger than the estimated change output")
 			}
 			change := wire.NewTxOut(int64(changeAmount), changeScript)
 			l := len(outputs)
@@ -301,32 +386,39 @@ func (w *BitcoinWallet) sweepAddress(ins []wi.TransactionInput, address *btc.Add
 	} else {
 		internalAddr = w.CurrentAddress(wi.INTERNAL)
 	}
-	script, err := txscript.PayToAddrScript(internalAddr)
+	script, err := w.AddressToScript(internalAddr)
 	if err != nil {
 		return nil, err
 	}
 
 	var val int64
 	var inputs []*wire.TxIn
-	additionalPrevScripts := make(map[wire.OutPoint][]byte)
+	prevOuts := make(map[wire.OutPoint]*wire.TxOut)
+	allTaproot := len(ins) > 0
 	for _, in := range ins {
 		val += in.Value
 		ch, err := chainhash.NewHashFromStr(hex.EncodeToString(in.OutpointHash))
 		if err != nil {
 			return nil, err
 		}
-		script, err := txscript.PayToAddrScript(in.LinkedAddress)
+		script, err := w.AddressToScript(in.LinkedAddress)
 		if err != nil {
 			return nil, err
 		}
 		outpoint := wire.NewOutPoint(ch, in.OutpointIndex)
 		input := wire.NewTxIn(outpoint, []byte{}, [][]byte{})
 		inputs = append(inputs, input)
-		additionalPrevScripts[*outpoint] = script
+		prevOuts[*outpoint] = wire.NewTxOut(in.Value, script)
+		if !btcaddr.IsPayToTaproot(script) {
+			allTaproot = false
+		}
 	}
 	out := wire.NewTxOut(val, script)
 
 	txType := P2PKH
+	if allTaproot {
+		txType = P2TR
+	}
 	if redeemScript != nil {
 		txType = P2SH_1of2_Multisig
 		_, err := spvwallet.LockTimeFromRedeemScript(*redeemScript)
@@ -404,7 +496,15 @@ func (w *BitcoinWallet) sweepAddress(ins []wi.TransactionInput, address *btc.Add
 	hashes := txscript.NewTxSigHashes(tx)
 	for i, txIn := range tx.TxIn {
 		if redeemScript == nil {
-			prevOutScript := additionalPrevScripts[txIn.PreviousOutPoint]
+			prevOutScript := prevOuts[txIn.PreviousOutPoint].PkScript
+			if btcaddr.IsPayToTaproot(prevOutScript) {
+				witness, err := taprootKeySpendWitness(tx, i, prevOuts, privKey)
+				if err != nil {
+					return nil, err
+				}
+				txIn.Witness = witness
+				continue
+			}
 			script, err := txscript.SignTxOutput(w.params,
 				tx, i, prevOutScript, txscript.SigHashAll, getKey,
 				getScript, txIn.SignatureScript)
@@ -413,7 +513,7 @@ func (w *BitcoinWallet) sweepAddress(ins []wi.TransactionInput, address *btc.Add
 			}
 			txIn.SignatureScript = script
 		} else {
-			sig, err := txscript.RawTxInWitnessSignature(tx, hashes, i, ins[i].Value, *redeemScript, txscript.SigHashAll, privKey)
+			sig, err := txscript.RawTxInWitnessSignature(tx, hashes, i, prevOuts[txIn.PreviousOutPoint].Value, *redeemScript, txscript.SigHashAll, privKey)
 			if err != nil {
 				return nil, err
 			}
@@ -436,34 +536,33 @@ func (w *BitcoinWallet) sweepAddress(ins []wi.TransactionInput, address *btc.Add
 	return &txid, nil
 }
 
-func (w *BitcoinWallet) createMultisigSignature(ins []wi.TransactionInput, outs []wi.TransactionOutput, key *hd.ExtendedKey, redeemScript []byte, feePerByte uint64) ([]wi.Signature, error) {
-	var sigs []wi.Signature
+// newMultisigTx returns the unsigned transaction the cosigners of the
+// redeem script sign, with the fee subtracted from the outputs, and the
+// values of the outputs it spends.
+func (w *BitcoinWallet) newMultisigTx(ins []wi.TransactionInput, outs []wi.TransactionOutput, rs *multisig.RedeemScript, feePerByte uint64) (*wire.MsgTx, map[wire.OutPoint]int64, error) {
 	tx := wire.NewMsgTx(1)
+	inVals := make(map[wire.OutPoint]int64)
 	for _, in := range ins {
 		ch, err := chainhash.NewHashFromStr(hex.EncodeToString(in.OutpointHash))
 		if err != nil {
-			return sigs, err
+			return nil, nil, err
 		}
 		outpoint := wire.NewOutPoint(ch, in.OutpointIndex)
 		input := wire.NewTxIn(outpoint, []byte{}, [][]byte{})
 		tx.TxIn = append(tx.TxIn, input)
+		inVals[*outpoint] = in.Value
 	}
 	for _, out := range outs {
-		scriptPubKey, err := txscript.PayToAddrScript(out.Address)
+		scriptPubKey, err := w.AddressToScript(out.Address)
 		if err != nil {
-			return sigs, err
+			return nil, nil, err
 		}
 		output := wire.NewTxOut(out.Value, scriptPubKey)
 		tx.TxOut = append(tx.TxOut, output)
 	}
 
 	// Subtract fee
-	txType := P2SH_2of3_Multisig
-	_, err := spvwallet.LockTimeFromRedeemScript(redeemScript)
-	if err == nil {
-		txType = P2SH_Multisig_Timelock_2Sigs
-	}
-	estimatedSize := EstimateSerializeSize(len(ins), tx.TxOut, false, txType)
+	estimatedSize := EstimateMultisigSerializeSize(len(ins), tx.TxOut, rs)
 	fee := estimatedSize * int(feePerByte)
 	if len(tx.TxOut) > 0 {
 		feePerOutput := fee / len(tx.TxOut)
@@ -474,6 +573,19 @@ func (w *BitcoinWallet) createMultisigSignature(ins []wi.TransactionInput, outs
 
 	// BIP 69 sorting
 	txsort.InPlaceSort(tx)
+	return tx, inVals, nil
+}
+
+func (w *BitcoinWallet) createMultisigSignature(ins []wi.TransactionInput, outs []wi.TransactionOutput, key *hd.ExtendedKey, redeemScript []byte, feePerByte uint64) ([]wi.Signature, error) {
+	var sigs []wi.Signature
+	rs, err := multisig.ParseRedeemScript(redeemScript)
+	if err != nil {
+		return sigs, err
+	}
+	tx, inVals, err := w.newMultisigTx(ins, outs, rs, feePerByte)
+	if err != nil {
+		return sigs, err
+	}
 
 	signingKey, err := key.ECPrivKey()
 	if err != nil {
@@ -481,8 +593,8 @@ func (w *BitcoinWallet) createMultisigSignature(ins []wi.TransactionInput, outs
 	}
 
 	hashes := txscript.NewTxSigHashes(tx)
-	for i := range tx.TxIn {
-		sig, err := txscript.RawTxInWitnessSignature(tx, hashes, i, ins[i].Value, redeemScript, txscript.SigHashAll, signingKey)
+	for i, txIn := range tx.TxIn {
+		sig, err := txscript.RawTxInWitnessSignature(tx, hashes, i, inVals[txIn.PreviousOutPoint], redeemScript, txscript.SigHashAll, signingKey)
 		if err != nil {
 			continue
 		}
@@ -493,47 +605,13 @@ func (w *BitcoinWallet) createMultisigSignature(ins []wi.TransactionInput, outs
 }
 
 func (w *BitcoinWallet) multisign(ins []wi.TransactionInput, outs []wi.TransactionOutput, sigs1 []wi.Signature, sigs2 []wi.Signature, redeemScript []byte, feePerByte uint64, broadcast bool) ([]byte, error) {
-	tx := wire.NewMsgTx(1)
-	for _, in := range ins {
-		ch, err := chainhash.NewHashFromStr(hex.EncodeToString(in.OutpointHash))
-		if err != nil {
-			return nil, err
-		}
-		outpoint := wire.NewOutPoint(ch, in.OutpointIndex)
-		input := wire.NewTxIn(outpoint, []byte{}, [][]byte{})
-		tx.TxIn = append(tx.TxIn, input)
-	}
-	for _, out := range outs {
-		scriptPubKey, err := txscript.PayToAddrScript(out.Address)
-		if err != nil {
-			return nil, err
-		}
-		output := wire.NewTxOut(out.Value, scriptPubKey)
-		tx.TxOut = append(tx.TxOut, output)
-	}
-
-	// Subtract fee
-	txType := P2SH_2of3_Multisig
-	_, err := spvwallet.LockTimeFromRedeemScript(redeemScript)
-	if err == nil {
-		txType = P2SH_Multisig_Timelock_2Sigs
-	}
-	estimatedSize := EstimateSerializeSize(len(ins), tx.TxOut, false, txType)
-	fee := estimatedSize * int(feePerByte)
-	if len(tx.TxOut) > 0 {
-		feePerOutput := fee / len(tx.TxOut)
-		for _, output := range tx.TxOut {
-			output.Value -= int64(feePerOutput)
-		}
+	rs, err := multisig.ParseRedeemScript(redeemScript)
+	if err != nil {
+		return nil, err
 	}
-
-	// BIP 69 sorting
-	txsort.InPlaceSort(tx)
-
-	// Check if time locked
-	var timeLocked bool
-	if redeemScript[0] == txscript.OP_IF {
-		timeLocked = true
+	tx, _, err := w.newMultisigTx(ins, outs, rs, feePerByte)
+	if err != nil {
+		return nil, err
 	}
 
 	for i, input := range tx.TxIn {
@@ -554,7 +632,7 @@ func (w *BitcoinWallet) multisign(ins []wi.TransactionInput, outs []wi.Transacti
 
 		witness := wire.TxWitness{[]byte{}, sig1, sig2}
 
-		if timeLocked {
+		if rs.Timelocked {
 			witness = append(witness, []byte{0x01})
 		}
 		witness = append(witness, redeemScript)
@@ -571,6 +649,281 @@ func (w *BitcoinWallet) multisign(ins []wi.TransactionInput, outs []wi.Transacti
 	return buf.Bytes(), nil
 }
 
+// multisignWithSignatures signs the transaction with the signatures of any
+// number of cosigners of an m of n multisig. Until the threshold is met the
+// inputs hold partial witnesses and complete is false.
+func (w *BitcoinWallet) multisignWithSignatures(ins []wi.TransactionInput, outs []wi.TransactionOutput, sigs multisig.Signatures, redeemScript []byte, feePerByte uint64, broadcast bool) ([]byte, bool, error) {
+	rs, err := multisig.ParseRedeemScript(redeemScript)
+	if err != nil {
+		return nil, false, err
+	}
+	tx, _, err := w.newMultisigTx(ins, outs, rs, feePerByte)
+	if err != nil {
+		return nil, false, err
+	}
+
+	complete := true
+	for i, input := range tx.TxIn {
+		slots, err := rs.Slots(sigs, i)
+		if err != nil {
+			return nil, false, err
+		}
+		stack, ok := rs.Stack(slots)
+		input.Witness = stack
+		complete = complete && ok
+	}
+	return w.finishMultisig(tx, complete, broadcast)
+}
+
+// mergeMultisig combines the signatures of partially signed transactions
+// spending the outputs of the redeem script.
+func (w *BitcoinWallet) mergeMultisig(redeemScript []byte, txs [][]byte, broadcast bool) ([]byte, bool, error) {
+	rs, err := multisig.ParseRedeemScript(redeemScript)
+	if err != nil {
+		return nil, false, err
+	}
+	var merged *wire.MsgTx
+	var slots [][][]byte
+	var finals []wire.TxWitness
+	for _, raw := range txs {
+		tx := wire.NewMsgTx(1)
+		if err := tx.Deserialize(bytes.NewReader(raw)); err != nil {
+			return nil, false, err
+		}
+		if merged == nil {
+			merged = tx
+			slots = make([][][]byte, len(tx.TxIn))
+			finals = make([]wire.TxWitness, len(tx.TxIn))
+		} else if multisig.UnsignedTxHash(tx) != multisig.UnsignedTxHash(merged) {
+			return nil, false, errors.New("partially signed transactions differ")
+		}
+		for i, in := range tx.TxIn {
+			s, complete, err := rs.ParseStack(in.Witness)
+			if err != nil {
+				return nil, false, fmt.Errorf("input %d: %s", i, err)
+			}
+			if complete {
+				finals[i] = in.Witness
+			} else if slots[i] == nil {
+				slots[i] = s
+			} else if slots[i], err = multisig.MergeSlots(slots[i], s); err != nil {
+				return nil, false, err
+			}
+		}
+	}
+	if merged == nil {
+		return nil, false, errors.New("no transactions to merge")
+	}
+
+	complete := true
+	for i, input := range merged.TxIn {
+		if finals[i] != nil {
+			input.Witness = finals[i]
+			continue
+		}
+		stack, ok := rs.Stack(slots[i])
+		input.Witness = stack
+		complete = complete && ok
+	}
+	return w.finishMultisig(merged, complete, broadcast)
+}
+
+// finishMultisig serializes a multisig transaction, broadcasting it if
+// requested once every input is fully signed.
+func (w *BitcoinWallet) finishMultisig(tx *wire.MsgTx, complete, broadcast bool) ([]byte, bool, error) {
+	if broadcast && complete {
+		if err := w.Broadcast(tx); err != nil {
+			return nil, false, err
+		}
+	}
+	var buf bytes.Buffer
+	tx.BtcEncode(&buf, wire.ProtocolVersion, wire.WitnessEncoding)
+	return buf.Bytes(), complete, nil
+}
+
+// releaseAfterTimeout spends the outputs of an escrow through the timeout
+// branch of its redeem script, signed by the timeout key, once its relative
+// lock time has passed.
+func (w *BitcoinWallet) releaseAfterTimeout(ins []wi.TransactionInput, address btc.Address, timeoutKey *hd.ExtendedKey, redeemScript []byte, feeLevel wi.FeeLevel) (*chainhash.Hash, error) {
+	rs, err := multisig.ParseRedeemScript(redeemScript)
+	if err != nil {
+		return nil, err
+	}
+	blocks, err := rs.TimeoutBlocks()
+	if err != nil {
+		return nil, err
+	}
+	privKey, err := timeoutKey.ECPrivKey()
+	if err != nil {
+		return nil, fmt.Errorf("retrieving private key: %s", err.Error())
+	}
+	if !bytes.Equal(privKey.PubKey().SerializeCompressed(), rs.TimeoutKey) {
+		return nil, errors.New("key is not the timeout key of the redeem script")
+	}
+	if address == nil {
+		address = w.CurrentAddress(wi.INTERNAL)
+	}
+	script, err := w.AddressToScript(address)
+	if err != nil {
+		return nil, err
+	}
+
+	// The relative lock time is only enforced on version 2 transactions
+	tx := wire.NewMsgTx(2)
+	var val int64
+	var outpoints []wire.OutPoint
+	inVals := make(map[wire.OutPoint]int64)
+	for _, in := range ins {
+		ch, err := chainhash.NewHashFromStr(hex.EncodeToString(in.OutpointHash))
+		if err != nil {
+			return nil, err
+		}
+		outpoint := wire.NewOutPoint(ch, in.OutpointIndex)
+		input := wire.NewTxIn(outpoint, []byte{}, [][]byte{})
+		input.Sequence = rs.Timeout
+		tx.TxIn = append(tx.TxIn, input)
+		outpoints = append(outpoints, *outpoint)
+		inVals[*outpoint] = in.Value
+		val += in.Value
+	}
+	if err := w.ws.CheckEscrowTimeout(outpoints, blocks); err != nil {
+		return nil, err
+	}
+
+	out := wire.NewTxOut(val, script)
+	tx.TxOut = append(tx.TxOut, out)
+	fee := int64(EstimateTimeoutSerializeSize(len(ins), tx.TxOut, rs)) * int64(w.GetFeePerByte(feeLevel))
+	out.Value = val - fee
+	if w.IsDust(out.Value) {
+		return nil, wi.ErrorDustAmount
+	}
+
+	// BIP 69 sorting
+	txsort.InPlaceSort(tx)
+
+	hashes := txscript.NewTxSigHashes(tx)
+	for i, txIn := range tx.TxIn {
+		sig, err := txscript.RawTxInWitnessSignature(tx, hashes, i, inVals[txIn.PreviousOutPoint], redeemScript, txscript.SigHashAll, privKey)
+		if err != nil {
+			return nil, err
+		}
+		txIn.Witness = rs.TimeoutStack(sig)
+	}
+
+	// broadcast
+	if err := w.Broadcast(tx); err != nil {
+		return nil, err
+	}
+	txid := tx.TxHash()
+	return &txid, nil
+}
+
+// generateHTLCScript returns the P2WSH address and script of a hash time
+// locked contract.
+func (w *BitcoinWallet) generateHTLCScript(secretHash, recipientKey, refundKey []byte, lockTime uint32) (btc.Address, []byte, error) {
+	contract, err := htlc.NewContract(secretHash, recipientKey, refundKey, lockTime)
+	if err != nil {
+		return nil, nil, err
+	}
+	witnessProgram := sha256.Sum256(contract.Script)
+	addr, err := btc.NewAddressWitnessScriptHash(witnessProgram[:], w.params)
+	if err != nil {
+		return nil, nil, err
+	}
+	return addr, contract.Script, nil
+}
+
+// spendHTLC spends the outputs of a hash time locked contract to address. The
+// contract is redeemed by the recipient key if a secret is given and refunded
+// by the refund key otherwise.
+func (w *BitcoinWallet) spendHTLC(ins []wi.TransactionInput, address btc.Address, key *hd.ExtendedKey, script []byte, secret []byte, feeLevel wi.FeeLevel) (*chainhash.Hash, error) {
+	contract, err := htlc.ParseContract(script)
+	if err != nil {
+		return nil, err
+	}
+	privKey, err := key.ECPrivKey()
+	if err != nil {
+		return nil, fmt.Errorf("retrieving private key: %s", err.Error())
+	}
+	pubKey := privKey.PubKey().SerializeCompressed()
+	redeem := secret != nil
+	if redeem {
+		if !contract.Matches(secret) {
+			return nil, htlc.ErrSecretMismatch
+		}
+		if !bytes.Equal(pubKey, contract.RecipientKey) {
+			return nil, errors.New("key is not the recipient key of the contract")
+		}
+	} else {
+		if !bytes.Equal(pubKey, contract.RefundKey) {
+			return nil, errors.New("key is not the refund key of the contract")
+		}
+		if tip, _ := w.ChainTip(); !contract.Refundable(tip) {
+			return nil, htlc.ErrLockTimeNotReached
+		}
+	}
+	if address == nil {
+		address = w.CurrentAddress(wi.INTERNAL)
+	}
+	outScript, err := w.AddressToScript(address)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := wire.NewMsgTx(wire.TxVersion)
+	var val int64
+	inVals := make(map[wire.OutPoint]int64)
+	for _, in := range ins {
+		ch, err := chainhash.NewHashFromStr(hex.EncodeToString(in.OutpointHash))
+		if err != nil {
+			return nil, err
+		}
+		outpoint := wire.NewOutPoint(ch, in.OutpointIndex)
+		input := wire.NewTxIn(outpoint, []byte{}, [][]byte{})
+		if !redeem {
+			// The lock time is only enforced on inputs which are not final
+			input.Sequence = wire.MaxTxInSequenceNum - 1
+		}
+		tx.TxIn = append(tx.TxIn, input)
+		inVals[*outpoint] = in.Value
+		val += in.Value
+	}
+	if !redeem {
+		tx.LockTime = contract.LockTime
+	}
+
+	out := wire.NewTxOut(val, outScript)
+	tx.TxOut = append(tx.TxOut, out)
+	fee := int64(EstimateHTLCSerializeSize(len(ins), tx.TxOut, redeem)) * int64(w.GetFeePerByte(feeLevel))
+	out.Value = val - fee
+	if w.IsDust(out.Value) {
+		return nil, wi.ErrorDustAmount
+	}
+
+	// BIP 69 sorting
+	txsort.InPlaceSort(tx)
+
+	hashes := txscript.NewTxSigHashes(tx)
+	for i, txIn := range tx.TxIn {
+		sig, err := txscript.RawTxInWitnessSignature(tx, hashes, i, inVals[txIn.PreviousOutPoint], script, txscript.SigHashAll, privKey)
+		if err != nil {
+			return nil, err
+		}
+		if redeem {
+			txIn.Witness = contract.RedeemStack(sig, secret)
+		} else {
+			txIn.Witness = contract.RefundStack(sig)
+		}
+	}
+
+	// broadcast
+	if err := w.Broadcast(tx); err != nil {
+		return nil, err
+	}
+	txid := tx.TxHash()
+	return &txid, nil
+}
+
 func (w *BitcoinWallet) generateMultisigScript(keys []hd.ExtendedKey, threshold int, timeout time.Duration, timeoutKey *hd.ExtendedKey) (addr btc.Address, redeemScript []byte, err error) {
 	if uint32(timeout.Hours()) > 0 && timeoutKey == nil {
 		return nil, nil, errors.New("Timeout key must be non nil when using an escrow timeout")
@@ -7,10 +7,13 @@ import (
 	"testing"
 	"time"
 
+	btcaddr "github.com/muecoin/multiwallet/bitcoin/address"
 	"github.com/muecoin/multiwallet/cache"
 	"github.com/muecoin/multiwallet/datastore"
+	"github.com/muecoin/multiwallet/htlc"
 	"github.com/muecoin/multiwallet/keys"
 	"github.com/muecoin/multiwallet/model/mock"
+	"github.com/muecoin/multiwallet/multisig"
 	"github.com/muecoin/multiwallet/service"
 	"github.com/OpenBazaar/spvwallet"
 	"github.com/OpenBazaar/wallet-interface"
@@ -29,6 +32,10 @@ type FeeResponse struct {
 }
 
 func newMockWallet() (*BitcoinWallet, error) {
+	return newMockWalletWithAddressType(LegacyAddress)
+}
+
+func newMockWalletWithAddressType(addrType AddressType) (*BitcoinWallet, error) {
 	mockDb := datastore.NewMockMultiwalletDatastore()
 
 	db, err := mockDb.GetDatastoreForWallet(wallet.Bitcoin)
@@ -46,7 +53,12 @@ func newMockWallet() (*BitcoinWallet, error) {
 	if err != nil {
 		return nil, err
 	}
-	km, err := keys.NewKeyManager(db.Keys(), params, master, wallet.Bitcoin, keyToAddress)
+	var km *keys.KeyManager
+	if addrType == TaprootAddress {
+		km, err = keys.NewKeyManagerWithDerivation(db.Keys(), params, master, wallet.Bitcoin, taprootKeyToAddress, keys.Bip86Derivation)
+	} else {
+		km, err = keys.NewKeyManager(db.Keys(), params, master, wallet.Bitcoin, keyToAddress)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -54,10 +66,11 @@ func newMockWallet() (*BitcoinWallet, error) {
 	fp := spvwallet.NewFeeProvider(2000, 300, 200, 100, "", nil)
 
 	bw := &BitcoinWallet{
-		params: params,
-		km:     km,
-		db:     db,
-		fp:     fp,
+		params:   params,
+		km:       km,
+		db:       db,
+		fp:       fp,
+		addrType: addrType,
 	}
 	cli := mock.NewMockApiClient(bw.AddressToScript)
 	ws, err := service.NewWalletService(db, km, cli, params, wallet.Bitcoin, cache.NewMockCacher())
@@ -120,6 +133,28 @@ func TestBitcoinWallet_buildTx(t *testing.T) {
 		t.Error("Built tx does not contain a valid change output")
 	}
 
+	// Data output
+	dataOut, err := util.NullDataOutput([]byte("order 1a3w"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err = w.buildTx(1500000, addr, wallet.NORMAL, dataOut)
+	if err != nil {
+		t.Error(err)
+	}
+	if !containsOutput(tx, addr) {
+		t.Error("Built tx does not contain the requested output")
+	}
+	hasData := false
+	for _, out := range tx.TxOut {
+		if bytes.Equal(out.PkScript, dataOut.PkScript) && out.Value == 0 {
+			hasData = true
+		}
+	}
+	if !hasData {
+		t.Error("Built tx does not contain the data output")
+	}
+
 	// Insuffient funds
 	_, err = w.buildTx(1000000000, addr, wallet.NORMAL, nil)
 	if err != wallet.ErrorInsuffientFunds {
@@ -133,6 +168,122 @@ func TestBitcoinWallet_buildTx(t *testing.T) {
 	}
 }
 
+func TestBitcoinWallet_buildBatchTx(t *testing.T) {
+	w, err := newMockWallet()
+	if err != nil {
+		t.Error(err)
+	}
+	w.ws.Start()
+	time.Sleep(time.Second / 2)
+
+	waitForTxnSync(t, w.db.Txns())
+	addr1, err := w.DecodeAddress("1AhsMpyyyVyPZ9KDUgwsX3zTDJWWSsRo4f")
+	if err != nil {
+		t.Error(err)
+	}
+	addr2, err := w.DecodeAddress("1DxGWC22a46VPEjq8YKoeVXSLzB7BA8sJS")
+	if err != nil {
+		t.Error(err)
+	}
+
+	payments := []wallet.TransactionOutput{{Address: addr1, Value: 1000000}, {Address: addr2, Value: 500000}}
+	tx, err := w.buildBatchTx(payments, wallet.NORMAL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !containsOutput(tx, addr1) || !containsOutput(tx, addr2) {
+		t.Error("Built tx does not contain every payment")
+	}
+	if !validInputs(tx, w.db) {
+		t.Error("Built tx does not contain valid inputs")
+	}
+	if !validChangeAddress(tx, w.db, w.params) {
+		t.Error("Built tx does not contain a valid change output")
+	}
+
+	// Every payment is checked for dust
+	payments = append(payments, wallet.TransactionOutput{Address: addr2, Value: 1})
+	if _, err := w.buildBatchTx(payments, wallet.NORMAL, nil); err != wallet.ErrorDustAmount {
+		t.Error("Failed to throw dust error")
+	}
+	if _, err := w.buildBatchTx(nil, wallet.NORMAL, nil); err == nil {
+		t.Error("Built a tx without payments")
+	}
+}
+
+func TestBitcoinWallet_PlanSpend(t *testing.T) {
+	w, err := newMockWallet()
+	if err != nil {
+		t.Error(err)
+	}
+	w.ws.Start()
+	time.Sleep(time.Second / 2)
+
+	waitForTxnSync(t, w.db.Txns())
+	addr, err := w.DecodeAddress("1AhsMpyyyVyPZ9KDUgwsX3zTDJWWSsRo4f")
+	if err != nil {
+		t.Error(err)
+	}
+
+	plan, err := w.PlanSpend(1000000, addr, wallet.NORMAL, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var in, out int64
+	for _, input := range plan.Inputs {
+		in += input.Value
+	}
+	var change int
+	for _, output := range plan.Outputs {
+		out += output.Value
+		if output.Change {
+			change++
+			if !w.HasKey(output.Address) {
+				t.Error("Change output does not pay the wallet")
+			}
+		}
+	}
+	if change != 1 {
+		t.Errorf("Expected one change output but had %d", change)
+	}
+	if plan.Fee != in-out || plan.Fee <= 0 {
+		t.Errorf("Fee %d does not match the inputs and outputs", plan.Fee)
+	}
+	if plan.VSize <= 0 || plan.FeeRate() < float64(plan.FeePerByte) {
+		t.Errorf("Fee rate %f is below %d per byte", plan.FeeRate(), plan.FeePerByte)
+	}
+	if plan.DustChangeDropped() {
+		t.Error("Dropped change that is not dust")
+	}
+
+	// The signed transaction is exactly the plan
+	tx, err := w.signPlan(plan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tx.TxIn) != len(plan.Inputs) || len(tx.TxOut) != len(plan.Outputs) {
+		t.Fatal("Signed tx differs from the plan")
+	}
+	for i, input := range plan.Inputs {
+		if tx.TxIn[i].PreviousOutPoint != input.OutPoint || len(tx.TxIn[i].SignatureScript) == 0 {
+			t.Errorf("Input %d differs from the plan or is not signed", i)
+		}
+	}
+	for i, output := range plan.Outputs {
+		if tx.TxOut[i].Value != output.Value || !bytes.Equal(tx.TxOut[i].PkScript, output.Script) {
+			t.Errorf("Output %d differs from the plan", i)
+		}
+	}
+
+	// A plan spending coins the wallet no longer has is not signed
+	stale := *plan
+	stale.Inputs = append([]util.PlannedInput{{OutPoint: wire.OutPoint{Index: 7}, Value: 1000}}, plan.Inputs...)
+	stale.Fee += 1000
+	if _, err := w.signPlan(&stale); err != util.ErrStalePlan {
+		t.Errorf("Expected ErrStalePlan but had %v", err)
+	}
+}
+
 func TestBitcoinWallet_buildSpendAllTx(t *testing.T) {
 	w, err := newMockWallet()
 	if err != nil {
@@ -148,7 +299,7 @@ func TestBitcoinWallet_buildSpendAllTx(t *testing.T) {
 	}
 
 	// Test build spendAll tx
-	tx, err := w.buildSpendAllTx(addr, wallet.NORMAL)
+	tx, err := w.buildSpendAllTx(addr, wallet.NORMAL, nil)
 	if err != nil {
 		t.Error(err)
 	}
@@ -194,6 +345,98 @@ func TestBitcoinWallet_buildSpendAllTx(t *testing.T) {
 	}
 }
 
+func TestBitcoinWallet_buildTxTaproot(t *testing.T) {
+	w, err := newMockWalletWithAddressType(TaprootAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.ws.Start()
+	time.Sleep(time.Second / 2)
+
+	// Fund two of the wallet's taproot addresses
+	for i := 0; i < 2; i++ {
+		key, err := w.km.GenerateChildKey(wallet.EXTERNAL, uint32(i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		addr, err := w.km.KeyToAddress(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		script, err := w.AddressToScript(addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !btcaddr.IsPayToTaproot(script) {
+			t.Fatalf("Wallet address %s is not a taproot address", addr)
+		}
+		hash := chainhash.DoubleHashH([]byte{byte(i)})
+		err = w.db.Utxos().Put(wallet.Utxo{
+			Op:           *wire.NewOutPoint(&hash, uint32(i)),
+			AtHeight:     1289590,
+			Value:        1000000,
+			ScriptPubkey: script,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	addr, err := w.DecodeAddress("1AhsMpyyyVyPZ9KDUgwsX3zTDJWWSsRo4f")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err := w.buildTx(1500000, addr, wallet.NORMAL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tx.TxIn) != 2 {
+		t.Fatalf("Expected 2 inputs but had %d", len(tx.TxIn))
+	}
+	if !containsOutput(tx, addr) {
+		t.Error("Built tx does not contain the requested output")
+	}
+
+	var hasChange bool
+	for _, out := range tx.TxOut {
+		if !btcaddr.IsPayToTaproot(out.PkScript) {
+			continue
+		}
+		if _, err := w.db.Keys().GetPathForKey(out.PkScript[2:]); err == nil {
+			hasChange = true
+		}
+	}
+	if !hasChange {
+		t.Error("Built tx does not contain a taproot change output")
+	}
+
+	// Verify the key path signature of each input
+	utxos, err := w.db.Utxos().GetAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	prevOuts := make(map[wire.OutPoint]*wire.TxOut)
+	for _, u := range utxos {
+		prevOuts[u.Op] = wire.NewTxOut(u.Value, u.ScriptPubkey)
+	}
+	for i, in := range tx.TxIn {
+		prevOut, ok := prevOuts[in.PreviousOutPoint]
+		if !ok {
+			t.Fatalf("Input %d does not spend a wallet utxo", i)
+		}
+		if len(in.SignatureScript) != 0 || len(in.Witness) != 1 || len(in.Witness[0]) != SchnorrSignatureSize {
+			t.Fatalf("Input %d does not have a taproot key path witness", i)
+		}
+		hash, err := calcTaprootSignatureHash(tx, i, prevOuts, sigHashDefault)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !schnorrVerify(prevOut.PkScript[2:], hash, in.Witness[0]) {
+			t.Errorf("Input %d has an invalid signature", i)
+		}
+	}
+}
+
 func containsOutput(tx *wire.MsgTx, addr btcutil.Address) bool {
 	for _, o := range tx.TxOut {
 		script, _ := txscript.PayToAddrScript(addr)
@@ -356,7 +599,7 @@ func TestBitcoinWallet_newUnsignedTransaction(t *testing.T) {
 	}
 
 	// Regular transaction
-	authoredTx, err := newUnsignedTransaction(outputs, btcutil.Amount(1000), inputSource, changeSource)
+	authoredTx, err := newUnsignedTransaction(outputs, btcutil.Amount(1000), P2PKH, inputSource, changeSource)
 	if err != nil {
 		t.Error(err)
 	}
@@ -369,7 +612,7 @@ func TestBitcoinWallet_newUnsignedTransaction(t *testing.T) {
 
 	// Insufficient funds
 	outputs[0].Value = 1000000000
-	_, err = newUnsignedTransaction(outputs, btcutil.Amount(1000), inputSource, changeSource)
+	_, err = newUnsignedTransaction(outputs, btcutil.Amount(1000), P2PKH, inputSource, changeSource)
 	if err == nil {
 		t.Error("Failed to return insuffient funds error")
 	}
@@ -492,6 +735,300 @@ func TestBitcoinWallet_Multisign(t *testing.T) {
 	}
 }
 
+func TestBitcoinWallet_MultisignWithSignatures(t *testing.T) {
+	w, err := newMockWallet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var keys []hdkeychain.ExtendedKey
+	for i := 0; i < 5; i++ {
+		key, err := w.km.GetFreshKey(wallet.INTERNAL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, *key)
+	}
+	addr, redeemScript, err := w.generateMultisigScript(keys, 3, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ins, outs, _, err := buildTxData(w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ins[0].Value = 40000
+	ins[1].Value = 60000
+
+	// Three of the five cosigners sign, out of key order
+	var partials [][]byte
+	sigs := make(multisig.Signatures)
+	for _, i := range []int{4, 0, 2} {
+		keySigs, err := w.CreateMultisigSignature(ins, outs, &keys[i], redeemScript, 50)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pubKey, err := keys[i].ECPubKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		cosigner := make(multisig.Signatures)
+		cosigner.Add(pubKey.SerializeCompressed(), keySigs)
+		sigs.Merge(cosigner)
+
+		partial, complete, err := w.MultisignWithSignatures(ins, outs, cosigner, redeemScript, 50, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if complete {
+			t.Error("Transaction with a single signature reported as complete")
+		}
+		partials = append(partials, partial)
+	}
+
+	txBytes, complete, err := w.MergeMultisig(redeemScript, partials[:2], false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if complete {
+		t.Error("Transaction with two signatures reported as complete")
+	}
+	txBytes, complete, err = w.MergeMultisig(redeemScript, [][]byte{txBytes, partials[2]}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !complete {
+		t.Error("Transaction with three signatures reported as partial")
+	}
+	direct, complete, err := w.MultisignWithSignatures(ins, outs, sigs, redeemScript, 50, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !complete || !bytes.Equal(direct, txBytes) {
+		t.Error("Merged transaction differs from the one signed with every signature")
+	}
+
+	tx := wire.NewMsgTx(1)
+	if err := tx.Deserialize(bytes.NewReader(txBytes)); err != nil {
+		t.Fatal(err)
+	}
+	pkScript, err := w.AddressToScript(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inVals := map[wire.OutPoint]int64{}
+	for _, in := range ins {
+		ch, _ := chainhash.NewHashFromStr(hex.EncodeToString(in.OutpointHash))
+		inVals[*wire.NewOutPoint(ch, in.OutpointIndex)] = in.Value
+	}
+	hashes := txscript.NewTxSigHashes(tx)
+	for i, in := range tx.TxIn {
+		if len(in.Witness) != 5 {
+			t.Errorf("Input %d: expected 3 signatures in the witness but had %d elements", i, len(in.Witness))
+		}
+		vm, err := txscript.NewEngine(pkScript, tx, i, txscript.StandardVerifyFlags, nil, hashes, inVals[in.PreviousOutPoint])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := vm.Execute(); err != nil {
+			t.Errorf("Input %d: %s", i, err)
+		}
+	}
+
+	// Signatures of a key outside the redeem script are rejected
+	other, err := w.km.GetFreshKey(wallet.INTERNAL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherPub, err := other.ECPubKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	unknown := make(multisig.Signatures)
+	unknown.Add(otherPub.SerializeCompressed(), []wallet.Signature{{InputIndex: 0, Signature: []byte{0x30}}})
+	if _, _, err := w.MultisignWithSignatures(ins, outs, unknown, redeemScript, 50, false); err != multisig.ErrUnknownCosigner {
+		t.Errorf("Expected ErrUnknownCosigner but had %v", err)
+	}
+}
+
+func TestBitcoinWallet_ReleaseAfterTimeout(t *testing.T) {
+	w, err := newMockWallet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.ws.Start()
+	waitForTxnSync(t, w.db.Txns())
+	time.Sleep(time.Second / 2)
+
+	var keys []hdkeychain.ExtendedKey
+	for i := 0; i < 4; i++ {
+		key, err := w.km.GetFreshKey(wallet.INTERNAL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, *key)
+	}
+	timeoutKey := &keys[3]
+	addr, redeemScript, err := w.generateMultisigScript(keys[:3], 2, time.Hour, timeoutKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkScript, err := w.AddressToScript(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The one hour timeout is six blocks
+	tip, _ := w.ChainTip()
+	op := wire.NewOutPoint(&chainhash.Hash{0x0e}, 1)
+	utxo := wallet.Utxo{Op: *op, Value: 100000, ScriptPubkey: pkScript, AtHeight: int32(tip) - 4, WatchOnly: true}
+	if err := w.db.Utxos().Put(utxo); err != nil {
+		t.Fatal(err)
+	}
+	h, _ := hex.DecodeString(op.Hash.String())
+	ins := []wallet.TransactionInput{{OutpointHash: h, OutpointIndex: op.Index, Value: utxo.Value, LinkedAddress: addr}}
+
+	if _, err := w.ReleaseAfterTimeout(ins, nil, timeoutKey, redeemScript, wallet.NORMAL); err != multisig.ErrTimeoutNotMatured {
+		t.Errorf("Expected ErrTimeoutNotMatured but had %v", err)
+	}
+	utxo.AtHeight--
+	if err := w.db.Utxos().Put(utxo); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.ReleaseAfterTimeout(ins, nil, &keys[0], redeemScript, wallet.NORMAL); err == nil {
+		t.Error("Released the escrow with a key other than the timeout key")
+	}
+	txid, err := w.ReleaseAfterTimeout(ins, nil, timeoutKey, redeemScript, wallet.NORMAL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	txn, err := w.db.Txns().Get(*txid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx := wire.NewMsgTx(1)
+	if err := tx.Deserialize(bytes.NewReader(txn.Bytes)); err != nil {
+		t.Fatal(err)
+	}
+	if tx.Version != 2 || tx.TxIn[0].Sequence != 6 {
+		t.Errorf("Expected a version 2 transaction with sequence 6 but had version %d and sequence %d", tx.Version, tx.TxIn[0].Sequence)
+	}
+	vm, err := txscript.NewEngine(pkScript, tx, 0, txscript.StandardVerifyFlags, nil, txscript.NewTxSigHashes(tx), utxo.Value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := vm.Execute(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestBitcoinWallet_HTLC(t *testing.T) {
+	w, err := newMockWallet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.ws.Start()
+	waitForTxnSync(t, w.db.Txns())
+	time.Sleep(time.Second / 2)
+
+	recipientKey, err := w.km.GetFreshKey(wallet.INTERNAL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	refundKey, err := w.km.GetFreshKey(wallet.INTERNAL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recipientPub, err := recipientKey.ECPubKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	refundPub, err := refundKey.ECPubKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret, secretHash, err := htlc.NewSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spend := func(lockTime uint32, txid func(ins []wallet.TransactionInput, contract []byte) (*chainhash.Hash, error)) (*wire.MsgTx, []byte, error) {
+		addr, contract, err := w.GenerateHTLCScript(secretHash, recipientPub.SerializeCompressed(), refundPub.SerializeCompressed(), lockTime)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := addr.(*btcutil.AddressWitnessScriptHash); !ok {
+			t.Fatalf("Expected a P2WSH contract address but had %T", addr)
+		}
+		pkScript, err := w.AddressToScript(addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		h, _ := hex.DecodeString(chainhash.Hash{0x0f}.String())
+		ins := []wallet.TransactionInput{{OutpointHash: h, OutpointIndex: lockTime, Value: 100000, LinkedAddress: addr}}
+		hash, err := txid(ins, contract)
+		if err != nil {
+			return nil, nil, err
+		}
+		txn, err := w.db.Txns().Get(*hash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tx := wire.NewMsgTx(1)
+		if err := tx.Deserialize(bytes.NewReader(txn.Bytes)); err != nil {
+			t.Fatal(err)
+		}
+		return tx, pkScript, nil
+	}
+	verify := func(tx *wire.MsgTx, pkScript []byte) {
+		vm, err := txscript.NewEngine(pkScript, tx, 0, txscript.StandardVerifyFlags, nil, txscript.NewTxSigHashes(tx), 100000)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := vm.Execute(); err != nil {
+			t.Error(err)
+		}
+	}
+
+	tip, _ := w.ChainTip()
+	tx, pkScript, err := spend(tip+10, func(ins []wallet.TransactionInput, contract []byte) (*chainhash.Hash, error) {
+		if _, err := w.RedeemHTLC(ins, nil, recipientKey, contract, secretHash, wallet.NORMAL); err != htlc.ErrSecretMismatch {
+			t.Errorf("Expected ErrSecretMismatch but had %v", err)
+		}
+		if _, err := w.RedeemHTLC(ins, nil, refundKey, contract, secret, wallet.NORMAL); err == nil {
+			t.Error("Redeemed the contract with the refund key")
+		}
+		return w.RedeemHTLC(ins, nil, recipientKey, contract, secret, wallet.NORMAL)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	verify(tx, pkScript)
+	contract, err := htlc.ParseContract(tx.TxIn[0].Witness[3])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if revealed, ok := contract.ExtractSecret(tx.TxIn[0].Witness); !ok || !bytes.Equal(revealed, secret) {
+		t.Error("Failed to extract the secret from the redeem witness")
+	}
+
+	if _, _, err := spend(tip+1, func(ins []wallet.TransactionInput, contract []byte) (*chainhash.Hash, error) {
+		return w.RefundHTLC(ins, nil, refundKey, contract, wallet.NORMAL)
+	}); err != htlc.ErrLockTimeNotReached {
+		t.Errorf("Expected ErrLockTimeNotReached but had %v", err)
+	}
+	tx, pkScript, err = spend(tip, func(ins []wallet.TransactionInput, contract []byte) (*chainhash.Hash, error) {
+		return w.RefundHTLC(ins, nil, refundKey, contract, wallet.NORMAL)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx.LockTime != tip || tx.TxIn[0].Sequence == wire.MaxTxInSequenceNum {
+		t.Errorf("Expected a refund locked at %d but had lock time %d and sequence %d", tip, tx.LockTime, tx.TxIn[0].Sequence)
+	}
+	verify(tx, pkScript)
+}
+
 func TestBitcoinWallet_bumpFee(t *testing.T) {
 	w, err := newMockWallet()
 	if err != nil {
@@ -630,3 +1167,37 @@ func TestBitcoinWallet_estimateSpendFee(t *testing.T) {
 		t.Error("Returned incorrect fee")
 	}
 }
+
+func TestBitcoinWallet_TransactionDetails(t *testing.T) {
+	w, err := newMockWallet()
+	if err != nil {
+		t.Error(err)
+	}
+	w.ws.Start()
+	time.Sleep(time.Second / 2)
+
+	waitForTxnSync(t, w.db.Txns())
+	txid, err := chainhash.NewHashFromStr("ff2b865c3b73439912eebf4cce9a15b12c7d7bcdd14ae1110a90541426c4e7c5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	details, err := w.TransactionDetails(*txid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if details.Txid != *txid {
+		t.Error("Returned incorrect txid")
+	}
+	if len(details.Inputs) != 1 || !details.Inputs[0].Known || details.Inputs[0].Value != 2717080 {
+		t.Fatalf("Failed to resolve the spent output %+v", details.Inputs)
+	}
+	if !details.FeeKnown || details.Fee != 100000 {
+		t.Errorf("Expected a fee of 100000 but had %d", details.Fee)
+	}
+	if details.FeeRate() != float64(details.Fee)/float64(details.VSize) {
+		t.Error("Returned incorrect fee rate")
+	}
+	if len(details.Owners) != 2 || details.Owners[0] != util.OwnerExternal {
+		t.Errorf("Expected the payment to be external but had %v", details.Owners)
+	}
+}
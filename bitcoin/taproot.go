@@ -0,0 +1,273 @@
+package bitcoin
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// sigHashDefault is the BIP341 sighash type committing to the whole
+// transaction like SigHashAll. Signatures using it omit the sighash byte.
+const sigHashDefault txscript.SigHashType = 0x00
+
+// SchnorrSignatureSize is the size of a BIP340 signature
+const SchnorrSignatureSize = 64
+
+// taggedHash returns the BIP340 tagged hash of the concatenated msgs
+func taggedHash(tag string, msgs ...[]byte) []byte {
+	tagHash := sha256.Sum256([]byte(tag))
+	h := sha256.New()
+	h.Write(tagHash[:])
+	h.Write(tagHash[:])
+	for _, m := range msgs {
+		h.Write(m)
+	}
+	return h.Sum(nil)
+}
+
+// xOnly returns the 32 byte x coordinate of a point
+func xOnly(x *big.Int) []byte {
+	var b [32]byte
+	xb := x.Bytes()
+	copy(b[32-len(xb):], xb)
+	return b[:]
+}
+
+// taprootTweak returns the BIP86 tweak of an internal key, which commits the
+// output to the key alone with no script tree.
+func taprootTweak(internalKey *btcec.PublicKey) (*big.Int, error) {
+	t := new(big.Int).SetBytes(taggedHash("TapTweak", xOnly(internalKey.X)))
+	if t.Cmp(btcec.S256().N) >= 0 {
+		return nil, errors.New("taproot tweak exceeds the curve order")
+	}
+	return t, nil
+}
+
+// taprootOutputKey returns the x-only output key of a BIP86 output spendable
+// by the internal key.
+func taprootOutputKey(internalKey *btcec.PublicKey) ([]byte, error) {
+	curve := btcec.S256()
+	t, err := taprootTweak(internalKey)
+	if err != nil {
+		return nil, err
+	}
+	// The internal key is used with an even y coordinate
+	py := new(big.Int).Set(internalKey.Y)
+	if py.Bit(0) == 1 {
+		py.Sub(curve.P, py)
+	}
+	tx, ty := curve.ScalarBaseMult(t.Bytes())
+	qx, qy := curve.Add(internalKey.X, py, tx, ty)
+	if qx.Sign() == 0 && qy.Sign() == 0 {
+		return nil, errors.New("invalid taproot output key")
+	}
+	return xOnly(qx), nil
+}
+
+// tweakTaprootPrivKey returns the private key of the BIP86 output key of key
+func tweakTaprootPrivKey(key *btcec.PrivateKey) (*btcec.PrivateKey, error) {
+	curve := btcec.S256()
+	t, err := taprootTweak(key.PubKey())
+	if err != nil {
+		return nil, err
+	}
+	d := new(big.Int).Set(key.D)
+	if key.PubKey().Y.Bit(0) == 1 {
+		d.Sub(curve.N, d)
+	}
+	d.Add(d, t)
+	d.Mod(d, curve.N)
+	if d.Sign() == 0 {
+		return nil, errors.New("invalid taproot private key")
+	}
+	tweaked, _ := btcec.PrivKeyFromBytes(curve, xOnly(d))
+	return tweaked, nil
+}
+
+// schnorrSign returns the BIP340 signature of the 32 byte hash using the
+// auxiliary randomness aux.
+func schnorrSign(key *btcec.PrivateKey, hash, aux []byte) ([]byte, error) {
+	if len(hash) != 32 || len(aux) != 32 {
+		return nil, errors.New("hash and auxiliary randomness must be 32 bytes")
+	}
+	curve := btcec.S256()
+	d := new(big.Int).Set(key.D)
+	pub := key.PubKey()
+	if pub.Y.Bit(0) == 1 {
+		d.Sub(curve.N, d)
+	}
+	px := xOnly(pub.X)
+
+	masked := new(big.Int).SetBytes(taggedHash("BIP0340/aux", aux))
+	masked.Xor(masked, d)
+	k := new(big.Int).SetBytes(taggedHash("BIP0340/nonce", xOnly(masked), px, hash))
+	k.Mod(k, curve.N)
+	if k.Sign() == 0 {
+		return nil, errors.New("invalid schnorr nonce")
+	}
+	rx, ry := curve.ScalarBaseMult(k.Bytes())
+	if ry.Bit(0) == 1 {
+		k.Sub(curve.N, k)
+	}
+
+	var sig [SchnorrSignatureSize]byte
+	copy(sig[:32], xOnly(rx))
+	e := new(big.Int).SetBytes(taggedHash("BIP0340/challenge", sig[:32], px, hash))
+	e.Mod(e, curve.N)
+	s := e.Mul(e, d)
+	s.Add(s, k)
+	s.Mod(s, curve.N)
+	copy(sig[32:], xOnly(s))
+	return sig[:], nil
+}
+
+// schnorrVerify reports whether sig is a valid BIP340 signature of the 32
+// byte hash by the x-only public key.
+func schnorrVerify(pubKey []byte, hash []byte, sig []byte) bool {
+	if len(pubKey) != 32 || len(hash) != 32 || len(sig) != SchnorrSignatureSize {
+		return false
+	}
+	curve := btcec.S256()
+	px := new(big.Int).SetBytes(pubKey)
+	r := new(big.Int).SetBytes(sig[:32])
+	s := new(big.Int).SetBytes(sig[32:])
+	if px.Cmp(curve.P) >= 0 || r.Cmp(curve.P) >= 0 || s.Cmp(curve.N) >= 0 {
+		return false
+	}
+
+	// Lift x to the point with an even y coordinate
+	y2 := new(big.Int).Exp(px, big.NewInt(3), curve.P)
+	y2.Add(y2, curve.B)
+	y2.Mod(y2, curve.P)
+	py := new(big.Int).Exp(y2, curve.QPlus1Div4(), curve.P)
+	if new(big.Int).Exp(py, big.NewInt(2), curve.P).Cmp(y2) != 0 {
+		return false
+	}
+	if py.Bit(0) == 1 {
+		py.Sub(curve.P, py)
+	}
+
+	e := new(big.Int).SetBytes(taggedHash("BIP0340/challenge", sig[:32], pubKey, hash))
+	e.Mod(e, curve.N)
+
+	// R = sG - eP
+	sx, sy := curve.ScalarBaseMult(s.Bytes())
+	ex, ey := curve.ScalarMult(px, py, e.Bytes())
+	ey.Sub(curve.P, ey)
+	rx, ry := curve.Add(sx, sy, ex, ey)
+	if rx.Sign() == 0 && ry.Sign() == 0 {
+		return false
+	}
+	return ry.Bit(0) == 0 && rx.Cmp(r) == 0
+}
+
+// calcTaprootSignatureHash returns the BIP341 signature hash of a key path
+// spend of input idx. Unlike earlier sighash versions it commits to the
+// amount and script of every output being spent.
+func calcTaprootSignatureHash(tx *wire.MsgTx, idx int, prevOuts map[wire.OutPoint]*wire.TxOut, hashType txscript.SigHashType) ([]byte, error) {
+	if idx < 0 || idx >= len(tx.TxIn) {
+		return nil, fmt.Errorf("input index %d out of range", idx)
+	}
+	switch hashType {
+	case sigHashDefault, txscript.SigHashAll, txscript.SigHashNone, txscript.SigHashSingle,
+		txscript.SigHashAll | txscript.SigHashAnyOneCanPay,
+		txscript.SigHashNone | txscript.SigHashAnyOneCanPay,
+		txscript.SigHashSingle | txscript.SigHashAnyOneCanPay:
+	default:
+		return nil, fmt.Errorf("invalid taproot sighash type %d", hashType)
+	}
+	anyoneCanPay := hashType&txscript.SigHashAnyOneCanPay != 0
+	outputType := hashType & 0x03
+	if outputType == txscript.SigHashSingle && idx >= len(tx.TxOut) {
+		return nil, errors.New("no output matching the input for SigHashSingle")
+	}
+
+	var msg bytes.Buffer
+	msg.WriteByte(0x00) // Epoch
+	msg.WriteByte(byte(hashType))
+	binary.Write(&msg, binary.LittleEndian, tx.Version)
+	binary.Write(&msg, binary.LittleEndian, tx.LockTime)
+
+	if !anyoneCanPay {
+		var outpoints, amounts, scripts, sequences bytes.Buffer
+		for _, in := range tx.TxIn {
+			prevOut, ok := prevOuts[in.PreviousOutPoint]
+			if !ok {
+				return nil, fmt.Errorf("missing previous output %s", in.PreviousOutPoint)
+			}
+			writeOutPoint(&outpoints, in.PreviousOutPoint)
+			binary.Write(&amounts, binary.LittleEndian, prevOut.Value)
+			wire.WriteVarBytes(&scripts, 0, prevOut.PkScript)
+			binary.Write(&sequences, binary.LittleEndian, in.Sequence)
+		}
+		for _, b := range []*bytes.Buffer{&outpoints, &amounts, &scripts, &sequences} {
+			h := sha256.Sum256(b.Bytes())
+			msg.Write(h[:])
+		}
+	}
+	if outputType != txscript.SigHashNone && outputType != txscript.SigHashSingle {
+		var outputs bytes.Buffer
+		for _, out := range tx.TxOut {
+			wire.WriteTxOut(&outputs, 0, 0, out)
+		}
+		h := sha256.Sum256(outputs.Bytes())
+		msg.Write(h[:])
+	}
+
+	msg.WriteByte(0x00) // Key path spend without an annex
+	if anyoneCanPay {
+		in := tx.TxIn[idx]
+		prevOut, ok := prevOuts[in.PreviousOutPoint]
+		if !ok {
+			return nil, fmt.Errorf("missing previous output %s", in.PreviousOutPoint)
+		}
+		writeOutPoint(&msg, in.PreviousOutPoint)
+		binary.Write(&msg, binary.LittleEndian, prevOut.Value)
+		wire.WriteVarBytes(&msg, 0, prevOut.PkScript)
+		binary.Write(&msg, binary.LittleEndian, in.Sequence)
+	} else {
+		binary.Write(&msg, binary.LittleEndian, uint32(idx))
+	}
+	if outputType == txscript.SigHashSingle {
+		var output bytes.Buffer
+		wire.WriteTxOut(&output, 0, 0, tx.TxOut[idx])
+		h := sha256.Sum256(output.Bytes())
+		msg.Write(h[:])
+	}
+	return taggedHash("TapSighash", msg.Bytes()), nil
+}
+
+func writeOutPoint(buf *bytes.Buffer, op wire.OutPoint) {
+	buf.Write(op.Hash[:])
+	binary.Write(buf, binary.LittleEndian, op.Index)
+}
+
+// taprootKeySpendWitness returns the witness spending input idx, a BIP86
+// output of key, through the key path.
+func taprootKeySpendWitness(tx *wire.MsgTx, idx int, prevOuts map[wire.OutPoint]*wire.TxOut, key *btcec.PrivateKey) (wire.TxWitness, error) {
+	tweaked, err := tweakTaprootPrivKey(key)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := calcTaprootSignatureHash(tx, idx, prevOuts, sigHashDefault)
+	if err != nil {
+		return nil, err
+	}
+	aux := make([]byte, 32)
+	if _, err := rand.Read(aux); err != nil {
+		return nil, err
+	}
+	sig, err := schnorrSign(tweaked, hash, aux)
+	if err != nil {
+		return nil, err
+	}
+	return wire.TxWitness{sig}, nil
+}
@@ -0,0 +1,237 @@
+package bitcoin
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/muecoin/multiwallet/keys"
+	"github.com/OpenBazaar/wallet-interface"
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil/hdkeychain"
+	"github.com/tyler-smith/go-bip39"
+)
+
+func TestSchnorrSign(t *testing.T) {
+	// Test vectors of BIP340
+	tests := []struct {
+		key    string
+		pubKey string
+		aux    string
+		msg    string
+		sig    string
+	}{
+		{
+			"0000000000000000000000000000000000000000000000000000000000000003",
+			"F9308A019258C31049344F85F89D5229B531C845836F99B08601F113BCE036F9",
+			"0000000000000000000000000000000000000000000000000000000000000000",
+			"0000000000000000000000000000000000000000000000000000000000000000",
+			"E907831F80848D1069A5371B402410364BDF1C5F8307B0084C55F1CE2DCA821525F66A4A85EA8B71E482A74F382D2CE5EBEEE8FDB2172F477DF4900D310536C0",
+		},
+		{
+			"B7E151628AED2A6ABF7158809CF4F3C762E7160F38B4DA56A784D9045190CFEF",
+			"DFF1D77F2A671C5F36183726DB2341BE58FEAE1DA2DECED843240F7B502BA659",
+			"0000000000000000000000000000000000000000000000000000000000000001",
+			"243F6A8885A308D313198A2E03707344A4093822299F31D0082EFA98EC4E6C89",
+			"6896BD60EEAE296DB48A229FF71DFE071BDE413E6D43F917DC8DCF8C78DE33418906D11AC976ABCCB20B091292BFF4EA897EFCB639EA871CFA95F6DE339E4B0A",
+		},
+	}
+	for i, test := range tests {
+		keyBytes, _ := hex.DecodeString(test.key)
+		pubKey, _ := hex.DecodeString(test.pubKey)
+		aux, _ := hex.DecodeString(test.aux)
+		msg, _ := hex.DecodeString(test.msg)
+		expected, _ := hex.DecodeString(test.sig)
+
+		key, _ := btcec.PrivKeyFromBytes(btcec.S256(), keyBytes)
+		sig, err := schnorrSign(key, msg, aux)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(sig, expected) {
+			t.Errorf("Test %d: expected signature %x but had %x", i, expected, sig)
+		}
+		if !schnorrVerify(pubKey, msg, sig) {
+			t.Errorf("Test %d: failed to verify signature", i)
+		}
+		sig[63] ^= 0x01
+		if schnorrVerify(pubKey, msg, sig) {
+			t.Errorf("Test %d: verified a modified signature", i)
+		}
+	}
+}
+
+func TestTaprootOutputKey(t *testing.T) {
+	keyBytes, _ := hex.DecodeString("B7E151628AED2A6ABF7158809CF4F3C762E7160F38B4DA56A784D9045190CFEF")
+	key, pubKey := btcec.PrivKeyFromBytes(btcec.S256(), keyBytes)
+	outputKey, err := taprootOutputKey(pubKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, _ := hex.DecodeString("7ad4375032c38eba4fc60deca75fa30a3a6bdf2fb38f7e617288e2d3776117cb")
+	if !bytes.Equal(outputKey, expected) {
+		t.Errorf("Expected output key %x but had %x", expected, outputKey)
+	}
+	tweaked, err := tweakTaprootPrivKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(tweaked.PubKey().SerializeCompressed()[1:], outputKey) {
+		t.Error("Tweaked private key does not match the output key")
+	}
+}
+
+func TestTaprootKeyToAddress(t *testing.T) {
+	// Test vectors of BIP86
+	seed := bip39.NewSeed("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about", "")
+	master, err := hdkeychain.NewMaster(seed, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	internal, external, err := keys.Bip86Derivation(master, wallet.Bitcoin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		chain *hdkeychain.ExtendedKey
+		index uint32
+		addr  string
+	}{
+		{external, 0, "bc1p5cyxnuxmeuwuvkwfem96lqzszd02n6xdcjrs20cac6yqjjwudpxqkedrcr"},
+		{external, 1, "bc1p4qhjn9zdvkux4e44uhx8tc55attvtyu358kutcqkudyccelu0was9fqzwh"},
+		{internal, 0, "bc1p3qkhfews2uk44qtvauqyr2ttdsw7svhkl9nkm9s9c3x4ax5h60wqwruhk7"},
+	}
+	for _, test := range tests {
+		key, err := test.chain.Child(test.index)
+		if err != nil {
+			t.Fatal(err)
+		}
+		addr, err := taprootKeyToAddress(key, &chaincfg.MainNetParams)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if addr.String() != test.addr {
+			t.Errorf("Expected address %s but had %s", test.addr, addr.String())
+		}
+	}
+}
+
+func TestCalcTaprootSignatureHash(t *testing.T) {
+	// keyPathSpending test vectors of BIP341
+	rawTx, _ := hex.DecodeString("02000000097de20cbff686da83a54981d2b9bab3586f4ca7e48f57f5b55963115f3b334e9c010000000000000000d7b7cab57b1393ace2d064f4d4a2cb8af6def61273e127517d44759b6dafdd990000000000fffffffff8e1f583384333689228c5d28eac13366be082dc57441760d957275419a418420000000000fffffffff0689180aa63b30cb162a73c6d2a38b7eeda2a83ece74310fda0843ad604853b0100000000feffffffaa5202bdf6d8ccd2ee0f0202afbbb7461d9264a25e5bfd3c5a52ee1239e0ba6c0000000000feffffff956149bdc66faa968eb2be2d2faa29718acbfe3941215893a2a3446d32acd050000000000000000000e664b9773b88c09c32cb70a2a3e4da0ced63b7ba3b22f848531bbb1d5d5f4c94010000000000000000e9aa6b8e6c9de67619e6a3924ae25696bb7b694bb677a632a74ef7eadfd4eabf0000000000ffffffffa778eb6a263dc090464cd125c466b5a99667720b1c110468831d058aa1b82af10100000000ffffffff0200ca9a3b000000001976a91406afd46bcdfd22ef94ac122aa11f241244a37ecc88ac807840cb0000000020ac9a87f5594be208f8532db38cff670c450ed2fea8fcdefcc9a663f78bab962b0065cd1d")
+	tx := wire.NewMsgTx(2)
+	if err := tx.Deserialize(bytes.NewReader(rawTx)); err != nil {
+		t.Fatal(err)
+	}
+	utxosSpent := []struct {
+		script string
+		amount int64
+	}{
+		{"512053a1f6e454df1aa2776a2814a721372d6258050de330b3c6d10ee8f4e0dda343", 420000000},
+		{"5120147c9c57132f6e7ecddba9800bb0c4449251c92a1e60371ee77557b6620f3ea3", 462000000},
+		{"76a914751e76e8199196d454941c45d1b3a323f1433bd688ac", 294000000},
+		{"5120e4d810fd50586274face62b8a807eb9719cef49c04177cc6b76a9a4251d5450e", 504000000},
+		{"512091b64d5324723a985170e4dc5a0f84c041804f2cd12660fa5dec09fc21783605", 630000000},
+		{"00147dd65592d0ab2fe0d0257d571abf032cd9db93dc", 378000000},
+		{"512075169f4001aa68f15bbed28b218df1d0a62cbbcf1188c6665110c293c907b831", 672000000},
+		{"5120712447206d7a5238acc7ff53fbe94a3b64539ad291c7cdbc490b7577e4b17df5", 546000000},
+		{"512077e30a5522dd9f894c3f8b8bd4c4b2cf82ca7da8a3ea6a239655c39c050ab220", 588000000},
+	}
+	prevOuts := make(map[wire.OutPoint]*wire.TxOut)
+	for i, utxo := range utxosSpent {
+		script, _ := hex.DecodeString(utxo.script)
+		prevOuts[tx.TxIn[i].PreviousOutPoint] = wire.NewTxOut(utxo.amount, script)
+	}
+
+	tests := []struct {
+		idx      int
+		hashType txscript.SigHashType
+		expected string
+	}{
+		{0, txscript.SigHashSingle, "2514a6272f85cfa0f45eb907fcb0d121b808ed37c6ea160a5a9046ed5526d555"},
+		{1, txscript.SigHashSingle | txscript.SigHashAnyOneCanPay, "325a644af47e8a5a2591cda0ab0723978537318f10e6a63d4eed783b96a71a4d"},
+		{3, txscript.SigHashAll, "bf013ea93474aa67815b1b6cc441d23b64fa310911d991e713cd34c7f5d46669"},
+		{4, sigHashDefault, "4f900a0bae3f1446fd48490c2958b5a023228f01661cda3496a11da502a7f7ef"},
+		{6, txscript.SigHashNone, "15f25c298eb5cdc7eb1d638dd2d45c97c4c59dcaec6679cfc16ad84f30876b85"},
+		{7, txscript.SigHashNone | txscript.SigHashAnyOneCanPay, "cd292de50313804dabe4685e83f923d2969577191a3e1d2882220dca88cbeb10"},
+		{8, txscript.SigHashAll | txscript.SigHashAnyOneCanPay, "cccb739eca6c13a8a89e6e5cd317ffe55669bbda23f2fd37b0f18755e008edd2"},
+	}
+	for _, test := range tests {
+		hash, err := calcTaprootSignatureHash(tx, test.idx, prevOuts, test.hashType)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hex.EncodeToString(hash) != test.expected {
+			t.Errorf("Input %d: expected sighash %s but had %x", test.idx, test.expected, hash)
+		}
+	}
+
+	// Input 0 commits to no script tree, as BIP86 outputs do. The vectors sign
+	// with zero auxiliary randomness.
+	keyBytes, _ := hex.DecodeString("6b973d88838f27366ed61c9ad6367663045cb456e28335c109e30717ae0c6baa")
+	key, _ := btcec.PrivKeyFromBytes(btcec.S256(), keyBytes)
+	tweaked, err := tweakTaprootPrivKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hex.EncodeToString(tweaked.Serialize()) != "2405b971772ad26915c8dcdf10f238753a9b837e5f8e6a86fd7c0cce5b7296d9" {
+		t.Errorf("Incorrect tweaked private key %x", tweaked.Serialize())
+	}
+	hash, _ := hex.DecodeString(tests[0].expected)
+	sig, err := schnorrSign(tweaked, hash, make([]byte, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, _ := hex.DecodeString("ed7c1647cb97379e76892be0cacff57ec4a7102aa24296ca39af7541246d8ff14d38958d4cc1e2e478e4d4a764bbfd835b16d4e314b72937b29833060b87276c")
+	if !bytes.Equal(sig, expected) {
+		t.Errorf("Expected signature %x but had %x", expected, sig)
+	}
+
+	if _, err := calcTaprootSignatureHash(tx, len(tx.TxIn), prevOuts, sigHashDefault); err == nil {
+		t.Error("Computed the sighash of a missing input")
+	}
+	if _, err := calcTaprootSignatureHash(tx, 2, prevOuts, txscript.SigHashSingle); err == nil {
+		t.Error("Computed the SigHashSingle sighash of an input without matching output")
+	}
+	if _, err := calcTaprootSignatureHash(tx, 0, prevOuts, 0x04); err == nil {
+		t.Error("Computed the sighash of an invalid hash type")
+	}
+	delete(prevOuts, tx.TxIn[5].PreviousOutPoint)
+	if _, err := calcTaprootSignatureHash(tx, 0, prevOuts, sigHashDefault); err == nil {
+		t.Error("Computed the sighash without every previous output")
+	}
+}
+
+func TestAddressTypeOption(t *testing.T) {
+	tests := []struct {
+		value    interface{}
+		expected AddressType
+		valid    bool
+	}{
+		{nil, LegacyAddress, true},
+		{"p2pkh", LegacyAddress, true},
+		{"p2tr", TaprootAddress, true},
+		{"Taproot", TaprootAddress, true},
+		{"p2wpkh", LegacyAddress, false},
+		{1, LegacyAddress, false},
+	}
+	for _, test := range tests {
+		options := map[string]interface{}{}
+		if test.value != nil {
+			options[OptionAddressType] = test.value
+		}
+		addrType, err := addressTypeOption(options)
+		if test.valid && err != nil {
+			t.Errorf("%v: %s", test.value, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("%v: expected an error", test.value)
+		}
+		if addrType != test.expected {
+			t.Errorf("%v: expected %s but had %s", test.value, test.expected, addrType)
+		}
+	}
+}
@@ -8,6 +8,9 @@ package bitcoin
 
 import (
 	"github.com/btcsuite/btcd/wire"
+
+	"github.com/muecoin/multiwallet/htlc"
+	"github.com/muecoin/multiwallet/multisig"
 )
 
 // Worst case script and input/output size estimates.
@@ -132,6 +135,22 @@ const (
 	//   - OP_CHECKSIG
 	P2PKHPkScriptSize = 1 + 1 + 1 + 20 + 1 + 1
 
+	// P2TRPkScriptSize is the size of a transaction output script that
+	// pays to a taproot output key.  It is calculated as:
+	//
+	//   - OP_1
+	//   - OP_DATA_32
+	//   - 32 bytes x-only output key
+	P2TRPkScriptSize = 1 + 1 + 32
+
+	// RedeemP2TRWitnessSize is the size of the witness of a taproot key path
+	// spend with the default sighash type.  It is calculated as:
+	//
+	//   - 1 byte witness item count
+	//   - 1 byte signature length
+	//   - 64 bytes Schnorr signature
+	RedeemP2TRWitnessSize = 1 + 1 + 64
+
 	// RedeemP2PKHInputSize is the worst case (largest) serialize size of a
 	// transaction input redeeming a compressed P2PKH output.  It is
 	// calculated as:
@@ -143,6 +162,17 @@ const (
 	//   - 4 bytes sequence
 	RedeemP2PKHInputSize = 32 + 4 + 1 + RedeemP2PKHSigScriptSize + 4
 
+	// RedeemP2TRInputSize is the worst case (largest) serialize size of a
+	// transaction input redeeming a taproot output through the key path.  It is
+	// calculated as:
+	//
+	//   - 32 bytes previous tx
+	//   - 4 bytes output index
+	//   - 1 byte empty script len
+	//   - 4 bytes sequence
+	//   - witness discounted witness, rounded up
+	RedeemP2TRInputSize = 32 + 4 + 1 + 4 + (RedeemP2TRWitnessSize+3)/4
+
 	// RedeemP2SH2of3MultisigInputSize is the worst case (largest) serialize size of a
 	// transaction input redeeming a compressed P2SH 2 of 3 multisig output.  It is
 	// calculated as:
@@ -194,6 +224,14 @@ const (
 	//   - 1 byte compact int encoding value 25
 	//   - 25 bytes P2PKH output script
 	P2PKHOutputSize = 8 + 1 + P2PKHPkScriptSize
+
+	// P2TROutputSize is the serialize size of a transaction output with a
+	// P2TR output script.  It is calculated as:
+	//
+	//   - 8 bytes output value
+	//   - 1 byte compact int encoding value 34
+	//   - 34 bytes P2TR output script
+	P2TROutputSize = 8 + 1 + P2TRPkScriptSize
 )
 
 type InputType int
@@ -204,17 +242,20 @@ const (
 	P2SH_2of3_Multisig
 	P2SH_Multisig_Timelock_1Sig
 	P2SH_Multisig_Timelock_2Sigs
+	P2TR
 )
 
 // EstimateSerializeSize returns a worst case serialize size estimate for a
-// signed transaction that spends inputCount number of compressed P2PKH outputs
+// signed transaction that spends inputCount number of outputs of inputType
 // and contains each transaction output from txOuts.  The estimated size is
-// incremented for an additional P2PKH change output if addChangeOutput is true.
+// incremented for an additional change output if addChangeOutput is true. The
+// change pays to a P2TR output when spending P2TR outputs and to P2PKH
+// otherwise.
 func EstimateSerializeSize(inputCount int, txOuts []*wire.TxOut, addChangeOutput bool, inputType InputType) int {
 	changeSize := 0
 	outputCount := len(txOuts)
 	if addChangeOutput {
-		changeSize = P2PKHOutputSize
+		changeSize = ChangeOutputSize(inputType)
 		outputCount++
 	}
 
@@ -230,6 +271,8 @@ func EstimateSerializeSize(inputCount int, txOuts []*wire.TxOut, addChangeOutput
 		redeemScriptSize = RedeemP2SHMultisigTimelock1InputSize
 	case P2SH_Multisig_Timelock_2Sigs:
 		redeemScriptSize = RedeemP2SHMultisigTimelock2InputSize
+	case P2TR:
+		redeemScriptSize = RedeemP2TRInputSize
 	}
 
 	// 10 additional bytes are for version, locktime, and segwit flags
@@ -240,6 +283,82 @@ func EstimateSerializeSize(inputCount int, txOuts []*wire.TxOut, addChangeOutput
 		changeSize
 }
 
+// RedeemMultisigInputSize returns the worst case serialize size of a
+// transaction input redeeming an m of n P2WSH multisig output through the
+// multisig branch.
+func RedeemMultisigInputSize(m, n int, timelocked bool) int {
+	return 32 + 4 + 1 + 4 + (multisig.WitnessSize(m, n, timelocked)+3)/4
+}
+
+// EstimateMultisigSerializeSize returns a worst case serialize size estimate
+// for a transaction spending inputCount outputs of the multisig redeem script
+// to txOuts. Multisigs of up to 2 of 3 keys use the 2 of 3 estimate they have
+// always been signed with, so cosigners running older versions subtract the
+// same fee.
+func EstimateMultisigSerializeSize(inputCount int, txOuts []*wire.TxOut, rs *multisig.RedeemScript) int {
+	if rs.Threshold <= 2 && len(rs.PubKeys) <= 3 {
+		inputType := P2SH_2of3_Multisig
+		if rs.Timelocked {
+			inputType = P2SH_Multisig_Timelock_2Sigs
+		}
+		return EstimateSerializeSize(inputCount, txOuts, false, inputType)
+	}
+
+	// 10 additional bytes are for version, locktime, and segwit flags
+	return 10 + wire.VarIntSerializeSize(uint64(inputCount)) +
+		wire.VarIntSerializeSize(uint64(len(txOuts))) +
+		inputCount*RedeemMultisigInputSize(rs.Threshold, len(rs.PubKeys), rs.Timelocked) +
+		SumOutputSerializeSizes(txOuts)
+}
+
+// RedeemTimeoutInputSize returns the worst case serialize size of a
+// transaction input spending the timeout branch of an m of n P2WSH escrow.
+func RedeemTimeoutInputSize(m, n int) int {
+	return 32 + 4 + 1 + 4 + (multisig.TimeoutWitnessSize(m, n)+3)/4
+}
+
+// EstimateTimeoutSerializeSize returns a worst case serialize size estimate
+// for a transaction spending inputCount outputs of the escrow redeem script
+// through its timeout branch to txOuts.
+func EstimateTimeoutSerializeSize(inputCount int, txOuts []*wire.TxOut, rs *multisig.RedeemScript) int {
+	// 10 additional bytes are for version, locktime, and segwit flags
+	return 10 + wire.VarIntSerializeSize(uint64(inputCount)) +
+		wire.VarIntSerializeSize(uint64(len(txOuts))) +
+		inputCount*RedeemTimeoutInputSize(rs.Threshold, len(rs.PubKeys)) +
+		SumOutputSerializeSizes(txOuts)
+}
+
+// RedeemHTLCInputSize returns the worst case serialize size of a transaction
+// input spending a P2WSH hash time locked contract, either redeeming it with
+// the secret or refunding it.
+func RedeemHTLCInputSize(redeem bool) int {
+	witnessSize := htlc.RefundWitnessSize()
+	if redeem {
+		witnessSize = htlc.RedeemWitnessSize()
+	}
+	return 32 + 4 + 1 + 4 + (witnessSize+3)/4
+}
+
+// EstimateHTLCSerializeSize returns a worst case serialize size estimate for
+// a transaction spending inputCount outputs of a hash time locked contract to
+// txOuts.
+func EstimateHTLCSerializeSize(inputCount int, txOuts []*wire.TxOut, redeem bool) int {
+	// 10 additional bytes are for version, locktime, and segwit flags
+	return 10 + wire.VarIntSerializeSize(uint64(inputCount)) +
+		wire.VarIntSerializeSize(uint64(len(txOuts))) +
+		inputCount*RedeemHTLCInputSize(redeem) +
+		SumOutputSerializeSizes(txOuts)
+}
+
+// ChangeOutputSize returns the serialize size of the change output of a
+// transaction spending outputs of inputType.
+func ChangeOutputSize(inputType InputType) int {
+	if inputType == P2TR {
+		return P2TROutputSize
+	}
+	return P2PKHOutputSize
+}
+
 // SumOutputSerializeSizes sums up the serialized size of the supplied outputs.
 func SumOutputSerializeSizes(outputs []*wire.TxOut) (serializeSize int) {
 	for _, txOut := range outputs {
@@ -11,6 +11,8 @@ import (
 	"encoding/hex"
 	"github.com/btcsuite/btcd/wire"
 	"testing"
+
+	"github.com/muecoin/multiwallet/multisig"
 )
 
 const (
@@ -67,6 +69,69 @@ func TestEstimateSerializeSize(t *testing.T) {
 	}
 }
 
+func TestEstimateSerializeSizeP2TR(t *testing.T) {
+	tests := []struct {
+		InputCount           int
+		OutputScriptLengths  []int
+		AddChangeOutput      bool
+		ExpectedSizeEstimate int
+	}{
+		0: {1, []int{}, false, 70},
+		1: {1, []int{}, true, 113},
+		2: {1, []int{p2pkhScriptSize}, false, 104},
+		3: {2, []int{P2TRPkScriptSize}, true, 214},
+	}
+	for i, test := range tests {
+		outputs := make([]*wire.TxOut, 0, len(test.OutputScriptLengths))
+		for _, l := range test.OutputScriptLengths {
+			outputs = append(outputs, &wire.TxOut{PkScript: make([]byte, l)})
+		}
+		actualEstimate := EstimateSerializeSize(test.InputCount, outputs, test.AddChangeOutput, P2TR)
+		if actualEstimate != test.ExpectedSizeEstimate {
+			t.Errorf("Test %d: Got %v: Expected %v", i, actualEstimate, test.ExpectedSizeEstimate)
+		}
+	}
+}
+
+func TestEstimateMultisigSerializeSize(t *testing.T) {
+	tests := []struct {
+		InputCount           int
+		OutputScriptLengths  []int
+		Threshold            int
+		Keys                 int
+		Timelocked           bool
+		ExpectedSizeEstimate int
+	}{
+		0: {2, []int{p2pkhScriptSize}, 2, 3, false, EstimateSerializeSize(2, []*wire.TxOut{{PkScript: make([]byte, p2pkhScriptSize)}}, false, P2SH_2of3_Multisig)},
+		1: {1, []int{}, 2, 3, true, EstimateSerializeSize(1, nil, false, P2SH_Multisig_Timelock_2Sigs)},
+		2: {1, []int{}, 1, 2, false, EstimateSerializeSize(1, nil, false, P2SH_2of3_Multisig)},
+		3: {2, []int{p2pkhScriptSize}, 3, 5, false, 328},
+		4: {1, []int{}, 3, 5, true, 164},
+	}
+	for i, test := range tests {
+		outputs := make([]*wire.TxOut, 0, len(test.OutputScriptLengths))
+		for _, l := range test.OutputScriptLengths {
+			outputs = append(outputs, &wire.TxOut{PkScript: make([]byte, l)})
+		}
+		rs := &multisig.RedeemScript{Threshold: test.Threshold, PubKeys: make([][]byte, test.Keys), Timelocked: test.Timelocked}
+		actualEstimate := EstimateMultisigSerializeSize(test.InputCount, outputs, rs)
+		if actualEstimate != test.ExpectedSizeEstimate {
+			t.Errorf("Test %d: Got %v: Expected %v", i, actualEstimate, test.ExpectedSizeEstimate)
+		}
+	}
+}
+
+func TestEstimateTimeoutSerializeSize(t *testing.T) {
+	rs := &multisig.RedeemScript{Threshold: 2, PubKeys: make([][]byte, 3), Timelocked: true}
+	if size := EstimateTimeoutSerializeSize(1, nil, rs); size != 110 {
+		t.Errorf("Expected 110 but had %d", size)
+	}
+	outs := []*wire.TxOut{{PkScript: make([]byte, p2pkhScriptSize)}}
+	if size := EstimateTimeoutSerializeSize(2, outs, rs); size != 10+1+1+2*98+P2PKHOutputSize {
+		t.Errorf("Incorrect size %d spending two outputs", size)
+	}
+}
+
 func TestSumOutputSerializeSizes(t *testing.T) {
 	testTx := "0100000001066b78efa7d66d271cae6d6eb799e1d10953fb1a4a760226cc93186d52b55613010000006a47304402204e6c32cc214c496546c3277191ca734494fe49fed0af1d800db92fed2021e61802206a14d063b67f2f1c8fc18f9e9a5963fe33e18c549e56e3045e88b4fc6219be11012103f72d0a11727219bff66b8838c3c5e1c74a5257a325b0c84247bd10bdb9069e88ffffffff0200c2eb0b000000001976a914426e80ad778792e3e19c20977fb93ec0591e1a3988ac35b7cb59000000001976a914e5b6dc0b297acdd99d1a89937474df77db5743c788ac00000000"
 	txBytes, err := hex.DecodeString(testTx)
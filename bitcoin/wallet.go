@@ -6,21 +6,25 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"strings"
 	"time"
 
+	btcaddr "github.com/muecoin/multiwallet/bitcoin/address"
 	"github.com/muecoin/multiwallet/cache"
 	"github.com/muecoin/multiwallet/client"
 	"github.com/muecoin/multiwallet/config"
+	"github.com/muecoin/multiwallet/htlc"
 	"github.com/muecoin/multiwallet/keys"
 	"github.com/muecoin/multiwallet/model"
+	"github.com/muecoin/multiwallet/multisig"
+	"github.com/muecoin/multiwallet/rates"
+	"github.com/muecoin/multiwallet/registry"
 	"github.com/muecoin/multiwallet/service"
 	"github.com/muecoin/multiwallet/util"
 	"github.com/OpenBazaar/spvwallet"
-	"github.com/OpenBazaar/spvwallet/exchangerates"
 	wi "github.com/OpenBazaar/wallet-interface"
 	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
-	"github.com/btcsuite/btcd/txscript"
 	"github.com/btcsuite/btcd/wire"
 	btc "github.com/btcsuite/btcutil"
 	hd "github.com/btcsuite/btcutil/hdkeychain"
@@ -37,13 +41,46 @@ type BitcoinWallet struct {
 	ws     *service.WalletService
 	fp     *spvwallet.FeeProvider
 
+	addrType AddressType
+
 	mPrivKey *hd.ExtendedKey
 	mPubKey  *hd.ExtendedKey
 
 	exchangeRates wi.ExchangeRates
 }
 
+func init() {
+	registry.Register(registry.Coin{
+		Name:                "Bitcoin",
+		CoinType:            util.ExtendCoinType(wi.Bitcoin),
+		TestnetCoinType:     util.ExtendCoinType(wi.TestnetBitcoin),
+		CurrencyCode:        "BTC",
+		TestnetCurrencyCode: "TBTC",
+		NewWallet:           newWallet,
+		PriceProviders: []string{
+			"bitpay+https://bitpay.com/api/rates",
+			"blockchain+https://blockchain.info/ticker",
+			"coingecko+https://api.coingecko.com/api/v3/coins/bitcoin?tickers=false&community_data=false&developer_data=false&sparkline=false",
+		},
+		BlockInterval:   10 * time.Minute,
+		DecodeAddress:   btcaddr.DecodeAddress,
+		ScriptToAddress: btcaddr.ExtractPkScriptAddrs,
+		AddressToScript: btcaddr.PayToAddrScript,
+	})
+}
+
+func newWallet(cfg config.CoinConfig, mnemonic string, params *chaincfg.Params, proxy proxy.Dialer, cache cache.Cacher, disableExchangeRates bool) (wi.Wallet, error) {
+	w, err := NewBitcoinWallet(cfg, mnemonic, params, proxy, cache, disableExchangeRates)
+	if err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
 func NewBitcoinWallet(cfg config.CoinConfig, mnemonic string, params *chaincfg.Params, proxy proxy.Dialer, cache cache.Cacher, disableExchangeRates bool) (*BitcoinWallet, error) {
+	if err := util.RequireSizeFeeModel(cfg.FeeModel); err != nil {
+		return nil, err
+	}
 	seed := bip39.NewSeed(mnemonic, "")
 
 	mPrivKey, err := hd.NewMaster(seed, params)
@@ -54,16 +91,28 @@ func NewBitcoinWallet(cfg config.CoinConfig, mnemonic string, params *chaincfg.P
 	if err != nil {
 		return nil, err
 	}
-	km, err := keys.NewKeyManager(cfg.DB.Keys(), params, mPrivKey, util.ExtendCoinType(wi.Bitcoin), keyToAddress)
+	addrType, err := addressTypeOption(cfg.Options)
+	if err != nil {
+		return nil, err
+	}
+	var km *keys.KeyManager
+	if addrType == TaprootAddress {
+		km, err = keys.NewKeyManagerWithDerivation(cfg.DB.Keys(), params, mPrivKey, util.ExtendCoinType(wi.Bitcoin), taprootKeyToAddress, keys.Bip86Derivation)
+	} else {
+		km, err = keys.NewKeyManager(cfg.DB.Keys(), params, mPrivKey, util.ExtendCoinType(wi.Bitcoin), keyToAddress)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	c, err := client.NewClientPool(cfg.ClientAPIs, proxy)
+	c, err := client.NewClientPoolWithOptions(cfg.ClientAPIs, cfg.Options, proxy)
+	if err != nil {
+		return nil, err
+	}
+	er, err := rates.NewPriceFetcher(cfg.CoinType, cfg.PriceAPIs, proxy)
 	if err != nil {
 		return nil, err
 	}
-	er := exchangerates.NewBitcoinPriceFetcher(proxy)
 	if !disableExchangeRates {
 		go er.Run()
 	}
@@ -72,16 +121,79 @@ func NewBitcoinWallet(cfg config.CoinConfig, mnemonic string, params *chaincfg.P
 	if err != nil {
 		return nil, err
 	}
+	if !disableExchangeRates {
+		wm.SetExchangeRates(er)
+	}
 
 	fp := spvwallet.NewFeeProvider(cfg.MaxFee, cfg.HighFee, cfg.MediumFee, cfg.LowFee, cfg.FeeAPI, proxy)
 
-	return &BitcoinWallet{cfg.DB, km, params, c, wm, fp, mPrivKey, mPubKey, er}, nil
+	return &BitcoinWallet{cfg.DB, km, params, c, wm, fp, addrType, mPrivKey, mPubKey, er}, nil
+}
+
+// AddressType is the type of the addresses the wallet receives coins and
+// change on.
+type AddressType int
+
+const (
+	LegacyAddress AddressType = iota
+	TaprootAddress
+)
+
+func (a AddressType) String() string {
+	switch a {
+	case TaprootAddress:
+		return "p2tr"
+	default:
+		return "p2pkh"
+	}
+}
+
+// OptionAddressType is the CoinConfig option selecting the wallet's address
+// type, either "p2pkh" (the default) or "p2tr". Taproot keys are derived at
+// m/86' per BIP86 so the option can't change once the wallet holds coins.
+const OptionAddressType = "AddressType"
+
+func addressTypeOption(options map[string]interface{}) (AddressType, error) {
+	switch v := options[OptionAddressType].(type) {
+	case nil:
+		return LegacyAddress, nil
+	case string:
+		switch strings.ToLower(v) {
+		case "", LegacyAddress.String():
+			return LegacyAddress, nil
+		case TaprootAddress.String(), "taproot":
+			return TaprootAddress, nil
+		}
+	}
+	return LegacyAddress, fmt.Errorf("invalid %s: %v", OptionAddressType, options[OptionAddressType])
 }
 
 func keyToAddress(key *hd.ExtendedKey, params *chaincfg.Params) (btc.Address, error) {
 	return key.Address(params)
 }
 
+// taprootKeyToAddress returns the BIP86 address of key, a taproot output
+// without a script tree.
+func taprootKeyToAddress(key *hd.ExtendedKey, params *chaincfg.Params) (btc.Address, error) {
+	pubKey, err := key.ECPubKey()
+	if err != nil {
+		return nil, err
+	}
+	outputKey, err := taprootOutputKey(pubKey)
+	if err != nil {
+		return nil, err
+	}
+	return btcaddr.NewAddressTaproot(outputKey, params)
+}
+
+// inputType is the type of the outputs the wallet spends and sends change to
+func (w *BitcoinWallet) inputType() InputType {
+	if w.addrType == TaprootAddress {
+		return P2TR
+	}
+	return P2PKH
+}
+
 func (w *BitcoinWallet) Start() {
 	w.client.Start()
 	w.ws.Start()
@@ -132,35 +244,28 @@ func (w *BitcoinWallet) ChildKey(keyBytes []byte, chaincode []byte, isPrivateKey
 
 func (w *BitcoinWallet) CurrentAddress(purpose wi.KeyPurpose) btc.Address {
 	key, _ := w.km.GetCurrentKey(purpose)
-	addr, _ := key.Address(w.params)
+	addr, _ := w.km.KeyToAddress(key)
 	return btc.Address(addr)
 }
 
 func (w *BitcoinWallet) NewAddress(purpose wi.KeyPurpose) btc.Address {
 	i, _ := w.db.Keys().GetUnused(purpose)
 	key, _ := w.km.GenerateChildKey(purpose, uint32(i[1]))
-	addr, _ := key.Address(w.params)
+	addr, _ := w.km.KeyToAddress(key)
 	w.db.Keys().MarkKeyAsUsed(addr.ScriptAddress())
 	return btc.Address(addr)
 }
 
 func (w *BitcoinWallet) DecodeAddress(addr string) (btc.Address, error) {
-	return btc.DecodeAddress(addr, w.params)
+	return btcaddr.DecodeAddress(addr, w.params)
 }
 
 func (w *BitcoinWallet) ScriptToAddress(script []byte) (btc.Address, error) {
-	_, addrs, _, err := txscript.ExtractPkScriptAddrs(script, w.params)
-	if err != nil {
-		return nil, err
-	}
-	if len(addrs) == 0 {
-		return nil, errors.New("unknown script")
-	}
-	return addrs[0], nil
+	return btcaddr.ExtractPkScriptAddrs(script, w.params)
 }
 
 func (w *BitcoinWallet) AddressToScript(addr btc.Address) ([]byte, error) {
-	return txscript.PayToAddrScript(addr)
+	return btcaddr.PayToAddrScript(addr)
 }
 
 func (w *BitcoinWallet) HasKey(addr btc.Address) bool {
@@ -216,6 +321,50 @@ func (w *BitcoinWallet) GetTransaction(txid chainhash.Hash) (wi.Txn, error) {
 	return txn, err
 }
 
+// TransactionData returns the payloads of the OP_RETURN outputs of a wallet
+// transaction.
+func (w *BitcoinWallet) TransactionData(txid chainhash.Hash) ([][]byte, error) {
+	return w.ws.NullData(txid)
+}
+
+// TransactionMetadata returns the reference ID, memo, labels and counterparty
+// recorded for a wallet transaction.
+func (w *BitcoinWallet) TransactionMetadata(txid chainhash.Hash) (service.TxMetadata, bool) {
+	return w.ws.TxMetadata(txid.String())
+}
+
+// SetTransactionMetadata replaces the metadata recorded for a transaction
+func (w *BitcoinWallet) SetTransactionMetadata(md service.TxMetadata) error {
+	return w.ws.SetTxMetadata(md)
+}
+
+// SetAddressLabel labels incoming payments to addr with label
+func (w *BitcoinWallet) SetAddressLabel(addr btc.Address, label string) error {
+	return w.ws.SetAddressLabel(addr, label)
+}
+
+// TransactionByReference returns the transaction spent with referenceID
+func (w *BitcoinWallet) TransactionByReference(referenceID string) (wi.Txn, error) {
+	return w.ws.TransactionByReference(referenceID)
+}
+
+// TransactionsWithLabel returns the transactions labeled with label
+func (w *BitcoinWallet) TransactionsWithLabel(label string) ([]wi.Txn, error) {
+	return w.ws.TransactionsWithLabel(label)
+}
+
+// TransactionPage returns a page of the transactions selected by filter, most
+// recent first, and the cursor of the next page.
+func (w *BitcoinWallet) TransactionPage(filter service.TxFilter) ([]wi.Txn, string, error) {
+	return w.ws.TransactionPage(filter)
+}
+
+// ExportTransactions returns the transactions of the wallet from the oldest
+// with their fee, the running balance and their value in currency.
+func (w *BitcoinWallet) ExportTransactions(currency string) ([]service.ExportRow, error) {
+	return w.ws.ExportTransactions(currency)
+}
+
 func (w *BitcoinWallet) ChainTip() (uint32, chainhash.Hash) {
 	return w.ws.ChainTip()
 }
@@ -225,27 +374,124 @@ func (w *BitcoinWallet) GetFeePerByte(feeLevel wi.FeeLevel) uint64 {
 }
 
 func (w *BitcoinWallet) Spend(amount int64, addr btc.Address, feeLevel wi.FeeLevel, referenceID string, spendAll bool) (*chainhash.Hash, error) {
-	var (
-		tx  *wire.MsgTx
-		err error
-	)
-	if spendAll {
-		tx, err = w.buildSpendAllTx(addr, feeLevel)
+	return w.SpendWithData(amount, addr, feeLevel, referenceID, nil, spendAll)
+}
+
+// SpendWithData spends like Spend and attaches data of up to 80 bytes to the
+// transaction in an OP_RETURN output.
+func (w *BitcoinWallet) SpendWithData(amount int64, addr btc.Address, feeLevel wi.FeeLevel, referenceID string, data []byte, spendAll bool) (*chainhash.Hash, error) {
+	tx, err := w.buildSpendTx(amount, addr, feeLevel, data, spendAll)
+	if err != nil {
+		return nil, err
+	}
+	if err := w.Broadcast(tx); err != nil {
+		return nil, err
+	}
+	ch := tx.TxHash()
+	w.ws.RecordSpend(ch.String(), referenceID, addr)
+	return &ch, nil
+}
+
+// buildSpendTx builds and signs the transaction of SpendWithData
+func (w *BitcoinWallet) buildSpendTx(amount int64, addr btc.Address, feeLevel wi.FeeLevel, data []byte, spendAll bool) (*wire.MsgTx, error) {
+	var dataOutput *wire.TxOut
+	if len(data) > 0 {
+		var err error
+		dataOutput, err = util.NullDataOutput(data)
 		if err != nil {
 			return nil, err
 		}
-	} else {
-		tx, err = w.buildTx(amount, addr, feeLevel, nil)
+	}
+	if spendAll {
+		return w.buildSpendAllTx(addr, feeLevel, dataOutput)
+	}
+	return w.buildTx(amount, addr, feeLevel, dataOutput)
+}
+
+// SpendWithRequestID spends like SpendWithData once per request ID. Retrying
+// a request ID returns the txid of its transaction, broadcasting the same
+// transaction again if its broadcast failed.
+func (w *BitcoinWallet) SpendWithRequestID(requestID string, amount int64, addr btc.Address, feeLevel wi.FeeLevel, referenceID string, data []byte, spendAll bool) (*chainhash.Hash, error) {
+	return w.spendOnce(requestID, referenceID, []btc.Address{addr}, func() (*wire.MsgTx, error) {
+		return w.buildSpendTx(amount, addr, feeLevel, data, spendAll)
+	})
+}
+
+// SpendBatch pays every payment in a single transaction. With a request ID
+// the payments are sent once like SpendWithRequestID.
+func (w *BitcoinWallet) SpendBatch(requestID string, payments []wi.TransactionOutput, feeLevel wi.FeeLevel, referenceID string) (*chainhash.Hash, error) {
+	recipients := make([]btc.Address, 0, len(payments))
+	for _, payment := range payments {
+		recipients = append(recipients, payment.Address)
+	}
+	return w.spendOnce(requestID, referenceID, recipients, func() (*wire.MsgTx, error) {
+		return w.buildBatchTx(payments, feeLevel, nil)
+	})
+}
+
+// AbandonSpendRequest gives up the transaction of a request ID whose
+// broadcast failed, so that it is never broadcast and its inputs are left to
+// other spends.
+func (w *BitcoinWallet) AbandonSpendRequest(requestID string) error {
+	return w.ws.AbandonSpendRequest(requestID)
+}
+
+// ConvertFiatOnce converts a fiat amount at the current exchange rate once per
+// request ID, returning the first conversion again for later calls with the
+// same request ID.
+func (w *BitcoinWallet) ConvertFiatOnce(requestID string, fiat rates.FiatAmount) (rates.Conversion, error) {
+	return w.ws.ConvertOnce(requestID, func() (rates.Conversion, error) {
+		return rates.Convert(w.ExchangeRates(), fiat)
+	})
+}
+
+// PlanSpend returns the transaction SpendWithData would build without signing
+// or broadcasting it.
+func (w *BitcoinWallet) PlanSpend(amount int64, addr btc.Address, feeLevel wi.FeeLevel, data []byte, spendAll bool) (*util.SpendPlan, error) {
+	var dataOutput *wire.TxOut
+	if len(data) > 0 {
+		var err error
+		dataOutput, err = util.NullDataOutput(data)
 		if err != nil {
 			return nil, err
 		}
 	}
-
-	if err := w.Broadcast(tx); err != nil {
-		return nil, err
+	if spendAll {
+		return w.planSpendAllTx(addr, feeLevel, dataOutput)
 	}
-	ch := tx.TxHash()
-	return &ch, nil
+	return w.planBatchTx([]wi.TransactionOutput{{Address: addr, Value: amount}}, feeLevel, dataOutput)
+}
+
+// ExecuteSpendPlan signs and broadcasts the transaction of a plan returned by
+// PlanSpend as is. With a request ID the plan is executed once like
+// SpendWithRequestID.
+func (w *BitcoinWallet) ExecuteSpendPlan(requestID string, plan *util.SpendPlan, referenceID string) (*chainhash.Hash, error) {
+	return w.spendOnce(requestID, referenceID, plan.Recipients(), func() (*wire.MsgTx, error) {
+		return w.signPlan(plan)
+	})
+}
+
+// spendOnce signs the transaction returned by build and broadcasts it once per
+// request ID like service.WalletService.SpendOnce
+func (w *BitcoinWallet) spendOnce(requestID, referenceID string, recipients []btc.Address, build func() (*wire.MsgTx, error)) (*chainhash.Hash, error) {
+	return w.ws.SpendOnce(requestID, func() (*service.SignedSpend, error) {
+		tx, err := build()
+		if err != nil {
+			return nil, err
+		}
+		var buf bytes.Buffer
+		if err := tx.BtcEncode(&buf, wire.ProtocolVersion, wire.WitnessEncoding); err != nil {
+			return nil, err
+		}
+		return service.NewSignedSpend(buf.Bytes(), tx.TxHash(), tx), nil
+	}, func(raw []byte) (*chainhash.Hash, error) {
+		txid, err := w.BroadcastRawTx(raw)
+		if err != nil {
+			return nil, err
+		}
+		w.ws.RecordSpend(txid.String(), referenceID, recipients...)
+		return txid, nil
+	})
 }
 
 func (w *BitcoinWallet) BumpFee(txid chainhash.Hash) (*chainhash.Hash, error) {
@@ -255,11 +501,11 @@ func (w *BitcoinWallet) BumpFee(txid chainhash.Hash) (*chainhash.Hash, error) {
 func (w *BitcoinWallet) EstimateFee(ins []wi.TransactionInput, outs []wi.TransactionOutput, feePerByte uint64) uint64 {
 	tx := new(wire.MsgTx)
 	for _, out := range outs {
-		scriptPubKey, _ := txscript.PayToAddrScript(out.Address)
+		scriptPubKey, _ := w.AddressToScript(out.Address)
 		output := wire.NewTxOut(out.Value, scriptPubKey)
 		tx.TxOut = append(tx.TxOut, output)
 	}
-	estimatedSize := EstimateSerializeSize(len(ins), tx.TxOut, false, P2PKH)
+	estimatedSize := EstimateSerializeSize(len(ins), tx.TxOut, false, w.inputType())
 	fee := estimatedSize * int(feePerByte)
 	return uint64(fee)
 }
@@ -280,6 +526,36 @@ func (w *BitcoinWallet) Multisign(ins []wi.TransactionInput, outs []wi.Transacti
 	return w.multisign(ins, outs, sigs1, sigs2, redeemScript, feePerByte, broadcast)
 }
 
+func (w *BitcoinWallet) MultisignWithSignatures(ins []wi.TransactionInput, outs []wi.TransactionOutput, sigs multisig.Signatures, redeemScript []byte, feePerByte uint64, broadcast bool) ([]byte, bool, error) {
+	return w.multisignWithSignatures(ins, outs, sigs, redeemScript, feePerByte, broadcast)
+}
+
+func (w *BitcoinWallet) MergeMultisig(redeemScript []byte, txs [][]byte, broadcast bool) ([]byte, bool, error) {
+	return w.mergeMultisig(redeemScript, txs, broadcast)
+}
+
+func (w *BitcoinWallet) ReleaseAfterTimeout(ins []wi.TransactionInput, address btc.Address, timeoutKey *hd.ExtendedKey, redeemScript []byte, feeLevel wi.FeeLevel) (*chainhash.Hash, error) {
+	return w.releaseAfterTimeout(ins, address, timeoutKey, redeemScript, feeLevel)
+}
+
+// GenerateHTLCScript returns the address and script of a hash time locked
+// contract paying recipientKey for the preimage of secretHash, or refundKey
+// once the chain reached the lockTime height.
+func (w *BitcoinWallet) GenerateHTLCScript(secretHash, recipientKey, refundKey []byte, lockTime uint32) (btc.Address, []byte, error) {
+	return w.generateHTLCScript(secretHash, recipientKey, refundKey, lockTime)
+}
+
+func (w *BitcoinWallet) RedeemHTLC(ins []wi.TransactionInput, address btc.Address, key *hd.ExtendedKey, contract []byte, secret []byte, feeLevel wi.FeeLevel) (*chainhash.Hash, error) {
+	if secret == nil {
+		return nil, htlc.ErrSecretMismatch
+	}
+	return w.spendHTLC(ins, address, key, contract, secret, feeLevel)
+}
+
+func (w *BitcoinWallet) RefundHTLC(ins []wi.TransactionInput, address btc.Address, key *hd.ExtendedKey, contract []byte, feeLevel wi.FeeLevel) (*chainhash.Hash, error) {
+	return w.spendHTLC(ins, address, key, contract, nil, feeLevel)
+}
+
 func (w *BitcoinWallet) GenerateMultisigScript(keys []hd.ExtendedKey, threshold int, timeout time.Duration, timeoutKey *hd.ExtendedKey) (addr btc.Address, redeemScript []byte, err error) {
 	return w.generateMultisigScript(keys, threshold, timeout, timeoutKey)
 }
@@ -301,6 +577,33 @@ func (w *BitcoinWallet) AddTransactionListener(callback func(wi.TransactionCallb
 	w.ws.AddTransactionListener(callback)
 }
 
+// WatchEscrowTimeout watches the escrow address and notifies the escrow
+// timeout listeners once its outputs can be released after the timeout.
+func (w *BitcoinWallet) WatchEscrowTimeout(addr btc.Address, redeemScript []byte) error {
+	if err := w.AddWatchedAddress(addr); err != nil {
+		return err
+	}
+	script, err := w.AddressToScript(addr)
+	if err != nil {
+		return err
+	}
+	return w.ws.WatchEscrowTimeout(script, redeemScript)
+}
+
+func (w *BitcoinWallet) AddEscrowTimeoutListener(callback func(service.EscrowTimeout)) {
+	w.ws.AddEscrowTimeoutListener(callback)
+}
+
+// WatchHTLC watches the contract address and calls callback for each input
+// spending its outputs, revealing the secret of a redeem.
+func (w *BitcoinWallet) WatchHTLC(addr btc.Address, callback func(service.ScriptSpend)) error {
+	if err := w.AddWatchedAddress(addr); err != nil {
+		return err
+	}
+	w.ws.WatchSpends(addr, callback)
+	return nil
+}
+
 func (w *BitcoinWallet) ReSyncBlockchain(fromTime time.Time) {
 	go w.ws.UpdateState()
 }
@@ -320,12 +623,23 @@ func (w *BitcoinWallet) GetConfirmations(txid chainhash.Hash) (uint32, uint32, e
 func (w *BitcoinWallet) Close() {
 	w.ws.Stop()
 	w.client.Close()
+	if f, ok := w.exchangeRates.(*rates.Fetcher); ok {
+		f.Stop()
+	}
 }
 
 func (w *BitcoinWallet) ExchangeRates() wi.ExchangeRates {
 	return w.exchangeRates
 }
 
+// BackendStatus returns the health scoreboard of the API servers used by the wallet
+func (w *BitcoinWallet) BackendStatus() []client.BackendStatus {
+	if pool, ok := w.client.(*client.ClientPool); ok {
+		return pool.BackendStatus()
+	}
+	return nil
+}
+
 func (w *BitcoinWallet) DumpTables(wr io.Writer) {
 	fmt.Fprintln(wr, "Transactions-----")
 	txns, _ := w.db.Txns().GetAll(true)
@@ -343,30 +657,35 @@ func (w *BitcoinWallet) DumpTables(wr io.Writer) {
 func (w *BitcoinWallet) Broadcast(tx *wire.MsgTx) error {
 	var buf bytes.Buffer
 	tx.BtcEncode(&buf, wire.ProtocolVersion, wire.WitnessEncoding)
+	cTxn, err := w.modelTransaction(tx, tx.TxHash().String(), buf.Bytes())
+	if err != nil {
+		return err
+	}
+	_, err = w.client.Broadcast(buf.Bytes())
+	if err != nil {
+		return err
+	}
+	w.ws.ProcessIncomingTransaction(cTxn)
+	return nil
+}
+
+// modelTransaction converts tx into the form the wallet service ingests.
+// Inputs spending outputs the wallet doesn't hold are left without an address,
+// as are outputs whose script doesn't pay to one.
+func (w *BitcoinWallet) modelTransaction(tx *wire.MsgTx, txid string, raw []byte) (model.Transaction, error) {
 	cTxn := model.Transaction{
-		Txid:          tx.TxHash().String(),
+		Txid:          txid,
 		Locktime:      int(tx.LockTime),
 		Version:       int(tx.Version),
 		Confirmations: 0,
 		Time:          time.Now().Unix(),
-		RawBytes:      buf.Bytes(),
+		RawBytes:      raw,
 	}
 	utxos, err := w.db.Utxos().GetAll()
 	if err != nil {
-		return err
+		return cTxn, err
 	}
 	for n, in := range tx.TxIn {
-		var u wi.Utxo
-		for _, ut := range utxos {
-			if util.OutPointsEqual(ut.Op, in.PreviousOutPoint) {
-				u = ut
-				break
-			}
-		}
-		addr, err := w.ScriptToAddress(u.ScriptPubkey)
-		if err != nil {
-			return err
-		}
 		input := model.Input{
 			Txid: in.PreviousOutPoint.Hash.String(),
 			Vout: int(in.PreviousOutPoint.Index),
@@ -375,33 +694,133 @@ func (w *BitcoinWallet) Broadcast(tx *wire.MsgTx) error {
 			},
 			Sequence: uint32(in.Sequence),
 			N:        n,
-			Addr:     addr.String(),
-			Satoshis: u.Value,
-			Value:    float64(u.Value) / util.SatoshisPerCoin(wi.Bitcoin),
+		}
+		for _, u := range utxos {
+			if !util.OutPointsEqual(u.Op, in.PreviousOutPoint) {
+				continue
+			}
+			if addr, err := w.ScriptToAddress(u.ScriptPubkey); err == nil {
+				input.Addr = addr.String()
+			}
+			input.Satoshis = u.Value
+			input.Value = float64(u.Value) / util.SatoshisPerCoin(wi.Bitcoin)
+			break
 		}
 		cTxn.Inputs = append(cTxn.Inputs, input)
 	}
 	for n, out := range tx.TxOut {
-		addr, err := w.ScriptToAddress(out.PkScript)
-		if err != nil {
-			return err
-		}
 		output := model.Output{
 			N: n,
 			ScriptPubKey: model.OutScript{
 				Script: model.Script{
 					Hex: hex.EncodeToString(out.PkScript),
 				},
-				Addresses: []string{addr.String()},
 			},
-			Value: float64(float64(out.Value) / util.SatoshisPerCoin(wi.Bitcoin)),
+			Value: float64(out.Value) / util.SatoshisPerCoin(wi.Bitcoin),
+		}
+		if addr, err := w.ScriptToAddress(out.PkScript); err == nil {
+			output.ScriptPubKey.Addresses = []string{addr.String()}
+		} else if _, ok := util.ExtractNullData(out.PkScript); ok {
+			output.ScriptPubKey.Type = "nulldata"
 		}
 		cTxn.Outputs = append(cTxn.Outputs, output)
 	}
-	_, err = w.client.Broadcast(buf.Bytes())
+	return cTxn, nil
+}
+
+// DecodeRawTx decodes a serialized transaction. Its fee is only computed when
+// the wallet knows every output it spends.
+func (w *BitcoinWallet) DecodeRawTx(raw []byte) (*util.DecodedTx, error) {
+	return w.decodeTx(raw, false)
+}
+
+// TransactionDetails decodes a wallet transaction and tells which of its
+// outputs pay the wallet. Outputs it spends the wallet doesn't store are
+// fetched from the backend.
+func (w *BitcoinWallet) TransactionDetails(txid chainhash.Hash) (*util.TxDetails, error) {
+	txn, err := w.db.Txns().Get(txid)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	w.ws.ProcessIncomingTransaction(cTxn)
-	return nil
+	decoded, err := w.decodeTx(txn.Bytes, true)
+	if err != nil {
+		return nil, err
+	}
+	confirmations, _, err := w.GetConfirmations(txid)
+	if err != nil {
+		return nil, err
+	}
+	return util.NewTxDetails(decoded, confirmations, w.outputOwner), nil
+}
+
+func (w *BitcoinWallet) decodeTx(raw []byte, fetchPrevOuts bool) (*util.DecodedTx, error) {
+	tx, vsize, err := parseRawTx(raw)
+	if err != nil {
+		return nil, err
+	}
+	utxos, err := w.db.Utxos().GetAll()
+	if err != nil {
+		return nil, err
+	}
+	txns, err := w.db.Txns().GetAll(true)
+	if err != nil {
+		return nil, err
+	}
+	parse := func(b []byte) (*wire.MsgTx, error) {
+		prev, _, err := parseRawTx(b)
+		return prev, err
+	}
+	prevOuts := util.KnownPrevOuts(tx, utxos, txns, parse)
+	if fetchPrevOuts {
+		util.FetchPrevOuts(tx, prevOuts, w.client.GetRawTransaction, parse)
+	}
+	decoded := util.DecodeTx(tx, tx.TxHash(), len(raw), vsize, prevOuts, w.ScriptToAddress)
+	return decoded, nil
+}
+
+// outputOwner tells whether addr is a receiving or a change address of the
+// wallet.
+func (w *BitcoinWallet) outputOwner(addr btc.Address) util.OutputOwner {
+	path, err := w.db.Keys().GetPathForKey(addr.ScriptAddress())
+	if err != nil {
+		return util.OwnerExternal
+	}
+	if path.Purpose == wi.INTERNAL {
+		return util.OwnerChange
+	}
+	return util.OwnerWallet
+}
+
+// BroadcastRawTx broadcasts a serialized transaction built outside the wallet.
+// It's ingested like the wallet's own transactions if it touches our addresses.
+func (w *BitcoinWallet) BroadcastRawTx(raw []byte) (*chainhash.Hash, error) {
+	tx, _, err := parseRawTx(raw)
+	if err != nil {
+		return nil, err
+	}
+	txid := tx.TxHash()
+	cTxn, err := w.modelTransaction(tx, txid.String(), raw)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.client.Broadcast(raw); err != nil {
+		return nil, err
+	}
+	if w.ws.IsRelevant(cTxn) {
+		w.ws.ProcessIncomingTransaction(cTxn)
+	}
+	return &txid, nil
+}
+
+// parseRawTx deserializes a raw transaction and returns it with its virtual
+// size.
+func parseRawTx(raw []byte) (*wire.MsgTx, int, error) {
+	tx := wire.NewMsgTx(wire.TxVersion)
+	if err := tx.Deserialize(bytes.NewReader(raw)); err != nil {
+		return nil, 0, err
+	}
+	if tx.SerializeSize() != len(raw) {
+		return nil, 0, errors.New("transaction has trailing bytes")
+	}
+	return tx, (3*tx.SerializeSizeStripped() + len(raw) + 3) / 4, nil
 }
@@ -0,0 +1,153 @@
+package bitcoincash
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+
+	"github.com/btcsuite/btcd/btcec"
+)
+
+// SignatureScheme is the signature algorithm used to sign transaction inputs
+type SignatureScheme int
+
+const (
+	ECDSA SignatureScheme = iota
+	Schnorr
+)
+
+func (s SignatureScheme) String() string {
+	switch s {
+	case Schnorr:
+		return "schnorr"
+	default:
+		return "ecdsa"
+	}
+}
+
+// SchnorrSignatureSize is the size of a Schnorr signature without the sighash
+// type byte. Bitcoin Cash script treats any 64 byte signature as Schnorr.
+const SchnorrSignatureSize = 64
+
+// schnorrAlgo16 is mixed into the RFC6979 nonce so Schnorr and ECDSA
+// signatures with the same key and message never share a nonce.
+var schnorrAlgo16 = []byte("Schnorr+SHA256  ")
+
+// schnorrSign signs the 32 byte hash with the Bitcoin Cash Schnorr scheme. The
+// nonce is derived as libsecp256k1 does so signatures match Bitcoin ABC's.
+func schnorrSign(key *btcec.PrivateKey, hash []byte) ([]byte, error) {
+	if len(hash) != 32 {
+		return nil, errors.New("hash must be 32 bytes")
+	}
+	curve := btcec.S256()
+	d := key.D.Bytes()
+	var priv [32]byte
+	copy(priv[32-len(d):], d)
+
+	drbg := newNonceRFC6979(priv[:], hash, schnorrAlgo16)
+	var k *big.Int
+	for {
+		k = new(big.Int).SetBytes(drbg.generate())
+		if k.Sign() != 0 && k.Cmp(curve.N) < 0 {
+			break
+		}
+	}
+	rx, ry := curve.ScalarBaseMult(k.Bytes())
+	if !isQuadraticResidue(ry) {
+		k.Sub(curve.N, k)
+	}
+
+	var sig [SchnorrSignatureSize]byte
+	rb := rx.Bytes()
+	copy(sig[32-len(rb):32], rb)
+
+	e := schnorrChallenge(sig[:32], key.PubKey(), hash)
+	s := new(big.Int).Mul(e, key.D)
+	s.Add(s, k)
+	s.Mod(s, curve.N)
+	sb := s.Bytes()
+	copy(sig[64-len(sb):], sb)
+	return sig[:], nil
+}
+
+// schnorrVerify reports whether sig is a valid Bitcoin Cash Schnorr signature
+// of the 32 byte hash by pub.
+func schnorrVerify(pub *btcec.PublicKey, hash []byte, sig []byte) bool {
+	if len(sig) != SchnorrSignatureSize || len(hash) != 32 {
+		return false
+	}
+	curve := btcec.S256()
+	r := new(big.Int).SetBytes(sig[:32])
+	s := new(big.Int).SetBytes(sig[32:])
+	if r.Cmp(curve.P) >= 0 || s.Cmp(curve.N) >= 0 {
+		return false
+	}
+	e := schnorrChallenge(sig[:32], pub, hash)
+
+	// R = sG - eP
+	sx, sy := curve.ScalarBaseMult(s.Bytes())
+	ex, ey := curve.ScalarMult(pub.X, pub.Y, e.Bytes())
+	ey.Sub(curve.P, ey)
+	rx, ry := curve.Add(sx, sy, ex, ey)
+	if rx.Sign() == 0 && ry.Sign() == 0 {
+		return false
+	}
+	return isQuadraticResidue(ry) && rx.Cmp(r) == 0
+}
+
+// schnorrChallenge returns e = H(R.x || compressed(P) || m) mod n
+func schnorrChallenge(rx []byte, pub *btcec.PublicKey, hash []byte) *big.Int {
+	h := sha256.New()
+	h.Write(rx)
+	h.Write(pub.SerializeCompressed())
+	h.Write(hash)
+	e := new(big.Int).SetBytes(h.Sum(nil))
+	return e.Mod(e, btcec.S256().N)
+}
+
+func isQuadraticResidue(y *big.Int) bool {
+	p := btcec.S256().P
+	exp := new(big.Int).Rsh(new(big.Int).Sub(p, big.NewInt(1)), 1)
+	return new(big.Int).Exp(y, exp, p).Cmp(big.NewInt(1)) == 0
+}
+
+// nonceRFC6979 is the HMAC-SHA256 DRBG of RFC6979 section 3.2 seeded the way
+// libsecp256k1 seeds it: key, message and an algorithm tag.
+type nonceRFC6979 struct {
+	k, v  []byte
+	retry bool
+}
+
+func newNonceRFC6979(key, msg, algo16 []byte) *nonceRFC6979 {
+	seed := make([]byte, 0, len(key)+len(msg)+len(algo16))
+	seed = append(append(append(seed, key...), msg...), algo16...)
+
+	n := &nonceRFC6979{k: make([]byte, 32), v: make([]byte, 32)}
+	for i := range n.v {
+		n.v[i] = 0x01
+	}
+	n.k = n.hmac(n.v, []byte{0x00}, seed)
+	n.v = n.hmac(n.v)
+	n.k = n.hmac(n.v, []byte{0x01}, seed)
+	n.v = n.hmac(n.v)
+	return n
+}
+
+func (n *nonceRFC6979) generate() []byte {
+	if n.retry {
+		n.k = n.hmac(n.v, []byte{0x00})
+		n.v = n.hmac(n.v)
+	}
+	n.v = n.hmac(n.v)
+	n.retry = true
+	return n.v
+}
+
+func (n *nonceRFC6979) hmac(data ...[]byte) []byte {
+	mac := hmac.New(sha256.New, n.k)
+	for _, d := range data {
+		mac.Write(d)
+	}
+	return mac.Sum(nil)
+}
@@ -0,0 +1,218 @@
+package bitcoincash
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/OpenBazaar/wallet-interface"
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+func TestSchnorrVerify(t *testing.T) {
+	// Test vectors of the Bitcoin Cash Schnorr specification
+	tests := []struct {
+		pubKey string
+		msg    string
+		sig    string
+	}{
+		{
+			"0279BE667EF9DCBBAC55A06295CE870B07029BFCDB2DCE28D959F2815B16F81798",
+			"0000000000000000000000000000000000000000000000000000000000000000",
+			"787A848E71043D280C50470E8E1532B2DD5D20EE912A45DBDD2BD1DFBF187EF67031A98831859DC34DFFEEDDA86831842CCD0079E1F92AF177F7F22CC1DCED05",
+		},
+		{
+			"02DFF1D77F2A671C5F36183726DB2341BE58FEAE1DA2DECED843240F7B502BA659",
+			"243F6A8885A308D313198A2E03707344A4093822299F31D0082EFA98EC4E6C89",
+			"2A298DACAE57395A15D0795DDBFD1DCB564DA82B0F269BC70A74F8220429BA1D1E51A22CCEC35599B8F266912281F8365FFC2D035A230434A1A64DC59F7013FD",
+		},
+	}
+	for i, test := range tests {
+		pkBytes, _ := hex.DecodeString(test.pubKey)
+		msg, _ := hex.DecodeString(test.msg)
+		sig, _ := hex.DecodeString(test.sig)
+		pubKey, err := btcec.ParsePubKey(pkBytes, btcec.S256())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !schnorrVerify(pubKey, msg, sig) {
+			t.Errorf("Test %d: failed to verify signature", i)
+		}
+		sig[63] ^= 0x01
+		if schnorrVerify(pubKey, msg, sig) {
+			t.Errorf("Test %d: verified a modified signature", i)
+		}
+		msg[0] ^= 0x01
+		sig[63] ^= 0x01
+		if schnorrVerify(pubKey, msg, sig) {
+			t.Errorf("Test %d: verified a signature of another message", i)
+		}
+	}
+}
+
+func TestSchnorrSign(t *testing.T) {
+	keyBytes, _ := hex.DecodeString("B7E151628AED2A6ABF7158809CF4F3C762E7160F38B4DA56A784D9045190CFEF")
+	msg, _ := hex.DecodeString("243F6A8885A308D313198A2E03707344A4093822299F31D0082EFA98EC4E6C89")
+	key, pubKey := btcec.PrivKeyFromBytes(btcec.S256(), keyBytes)
+
+	sig, err := schnorrSign(key, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, _ := hex.DecodeString("e87cec707424360691ebf78b40be9bf5fbcfcf4cda8a9e49fb1a550fe00dfb6037170b5c71423897409718c46f7cd8a9f4a398fa5d367a539a60e62aaa2fc11a")
+	if !bytes.Equal(sig, expected) {
+		t.Errorf("Expected signature %x but had %x", expected, sig)
+	}
+	if !schnorrVerify(pubKey, msg, sig) {
+		t.Error("Failed to verify signature")
+	}
+
+	// Signing is deterministic
+	sig2, err := schnorrSign(key, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(sig, sig2) {
+		t.Error("Signing the same message twice returned different signatures")
+	}
+
+	if _, err := schnorrSign(key, msg[:31]); err == nil {
+		t.Error("Signed a hash of the wrong length")
+	}
+}
+
+func TestCalcSignatureHash(t *testing.T) {
+	tx := wire.NewMsgTx(1)
+	for _, in := range []struct {
+		hash     string
+		index    uint32
+		sequence uint32
+	}{
+		{"1a20f4299b4fa1f209428dace31ebf4f23f13abd8ed669cebede118343a6ae05", 1, 0xffffffff},
+		{"458d88b4ae9eb4a347f2e7f5592f1da3b9ddf7d40f307f6e5d7bc107a9b3e90e", 0, 0xfffffffe},
+	} {
+		ch, err := chainhash.NewHashFromStr(in.hash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		txIn := wire.NewTxIn(wire.NewOutPoint(ch, in.index), nil, nil)
+		txIn.Sequence = in.sequence
+		tx.TxIn = append(tx.TxIn, txIn)
+	}
+	p2pkh, _ := hex.DecodeString("76a914111111111111111111111111111111111111111188ac")
+	p2sh, _ := hex.DecodeString("a914222222222222222222222222222222222222222287")
+	tx.TxOut = []*wire.TxOut{wire.NewTxOut(20000, p2pkh), wire.NewTxOut(15000, p2sh)}
+
+	prevScript, _ := hex.DecodeString("76a91443b0267dec963f3453fc92392e0ec753dad4bb8488ac")
+	sigHash, err := calcSignatureHash(prevScript, txscript.SigHashAll, tx, 1, 50000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, _ := hex.DecodeString("c15242b75e8cfb9b7f5195c2c00806cf658e42671b45c55a4369f04c8b940cb8")
+	if !bytes.Equal(sigHash, expected) {
+		t.Errorf("Expected sighash %x but had %x", expected, sigHash)
+	}
+
+	keyBytes, _ := hex.DecodeString("B7E151628AED2A6ABF7158809CF4F3C762E7160F38B4DA56A784D9045190CFEF")
+	key, _ := btcec.PrivKeyFromBytes(btcec.S256(), keyBytes)
+	sig, err := rawTxInSignature(tx, 1, prevScript, txscript.SigHashAll, key, 50000, Schnorr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, _ = hex.DecodeString("80f574785a3fee962090420e9a61d540e9568c32f7191a7ad785e8a14d9e4e53a88770d008b3809d94b2162647cf13143fb7c03e00e5a3cd10798cc9dbf9105241")
+	if !bytes.Equal(sig, expected) {
+		t.Errorf("Expected signature %x but had %x", expected, sig)
+	}
+
+	if _, err := calcSignatureHash(prevScript, txscript.SigHashAll, tx, 2, 50000); err == nil {
+		t.Error("Computed the sighash of a missing input")
+	}
+}
+
+func TestSignatureSchemeOption(t *testing.T) {
+	tests := []struct {
+		value    interface{}
+		expected SignatureScheme
+		valid    bool
+	}{
+		{nil, ECDSA, true},
+		{"ecdsa", ECDSA, true},
+		{"schnorr", Schnorr, true},
+		{"Schnorr", Schnorr, true},
+		{"rsa", ECDSA, false},
+		{true, ECDSA, false},
+	}
+	for _, test := range tests {
+		options := map[string]interface{}{}
+		if test.value != nil {
+			options[OptionSignatureScheme] = test.value
+		}
+		scheme, err := signatureSchemeOption(options)
+		if test.valid && err != nil {
+			t.Errorf("%v: %s", test.value, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("%v: expected an error", test.value)
+		}
+		if scheme != test.expected {
+			t.Errorf("%v: expected %s but had %s", test.value, test.expected, scheme)
+		}
+	}
+}
+
+// verifySchnorrP2PKHInputs checks that every input of tx spends a wallet utxo
+// with a valid Schnorr signature.
+func verifySchnorrP2PKHInputs(t *testing.T, tx *wire.MsgTx, db wallet.Datastore) {
+	utxos, err := db.Utxos().GetAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, in := range tx.TxIn {
+		var prevOut *wallet.Utxo
+		for _, u := range utxos {
+			if u.Op == in.PreviousOutPoint {
+				prevOut = &u
+				break
+			}
+		}
+		if prevOut == nil {
+			t.Fatalf("Input %d does not spend a wallet utxo", i)
+		}
+		pushes, err := txscript.PushedData(in.SignatureScript)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(pushes) != 2 || len(pushes[0]) != SchnorrSignatureSize+1 {
+			t.Fatalf("Input %d is not signed with a Schnorr signature", i)
+		}
+		if pushes[0][SchnorrSignatureSize] != byte(txscript.SigHashAll|sigHashForkID) {
+			t.Errorf("Input %d has an incorrect sighash type", i)
+		}
+		pubKey, err := btcec.ParsePubKey(pushes[1], btcec.S256())
+		if err != nil {
+			t.Fatal(err)
+		}
+		hash, err := calcSignatureHash(prevOut.ScriptPubkey, txscript.SigHashAll, tx, i, prevOut.Value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !schnorrVerify(pubKey, hash, pushes[0][:SchnorrSignatureSize]) {
+			t.Errorf("Input %d has an invalid signature", i)
+		}
+	}
+}
+
+// verifySchnorrMultisigSignature checks that sig is a valid Schnorr signature
+// by pubKey of input idx of tx, which spends a multisig output.
+func verifySchnorrMultisigSignature(t *testing.T, tx *wire.MsgTx, idx int, redeemScript []byte, amt int64, pubKey *btcec.PublicKey, sig []byte) {
+	hash, err := calcSignatureHash(redeemScript, txscript.SigHashAll, tx, idx, amt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sig) != SchnorrSignatureSize+1 || !schnorrVerify(pubKey, hash, sig[:SchnorrSignatureSize]) {
+		t.Errorf("Input %d has an invalid signature", idx)
+	}
+}
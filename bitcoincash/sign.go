@@ -2,6 +2,7 @@ package bitcoincash
 
 import (
 	"bytes"
+	"encoding/binary"
 	"encoding/hex"
 	"errors"
 	"fmt"
@@ -25,19 +26,51 @@ import (
 	"github.com/btcsuite/btcwallet/wallet/txrules"
 	"github.com/cpacia/bchutil"
 
+	"github.com/muecoin/multiwallet/htlc"
+	"github.com/muecoin/multiwallet/multisig"
 	"github.com/muecoin/multiwallet/util"
 )
 
 func (w *BitcoinCashWallet) buildTx(amount int64, addr btc.Address, feeLevel wi.FeeLevel, optionalOutput *wire.TxOut) (*wire.MsgTx, error) {
-	// Check for dust
-	script, _ := bchutil.PayToAddrScript(addr)
-	if txrules.IsDustAmount(btc.Amount(amount), len(script), txrules.DefaultRelayFeePerKb) {
-		return nil, wi.ErrorDustAmount
+	return w.buildBatchTx([]wi.TransactionOutput{{Address: addr, Value: amount}}, feeLevel, optionalOutput)
+}
+
+// buildBatchTx builds a transaction paying every payment and optionalOutput
+// if set.
+func (w *BitcoinCashWallet) buildBatchTx(payments []wi.TransactionOutput, feeLevel wi.FeeLevel, optionalOutput *wire.TxOut) (*wire.MsgTx, error) {
+	plan, err := w.planBatchTx(payments, feeLevel, optionalOutput)
+	if err != nil {
+		return nil, err
 	}
+	return w.signPlan(plan)
+}
 
-	var additionalPrevScripts map[wire.OutPoint][]byte
-	var additionalKeysByAddress map[string]*btc.WIF
-	var inVals map[wire.OutPoint]int64
+func (w *BitcoinCashWallet) planBatchTx(payments []wi.TransactionOutput, feeLevel wi.FeeLevel, optionalOutput *wire.TxOut) (*util.SpendPlan, error) {
+	if len(payments) == 0 {
+		return nil, errors.New("no payments to send")
+	}
+	var outputs []*wire.TxOut
+	for _, payment := range payments {
+		// Check for dust
+		script, err := bchutil.PayToAddrScript(payment.Address)
+		if err != nil {
+			return nil, err
+		}
+		if txrules.IsDustAmount(btc.Amount(payment.Value), len(script), txrules.DefaultRelayFeePerKb) {
+			return nil, wi.ErrorDustAmount
+		}
+		outputs = append(outputs, wire.NewTxOut(payment.Value, script))
+	}
+	if optionalOutput != nil {
+		outputs = append(outputs, optionalOutput)
+	}
+	return w.planTxWithOutputs(outputs, feeLevel)
+}
+
+// planTxWithOutputs selects the coins paying outputs at feeLevel and adds a
+// change output unless the change is dust.
+func (w *BitcoinCashWallet) planTxWithOutputs(outputs []*wire.TxOut, feeLevel wi.FeeLevel) (*util.SpendPlan, error) {
+	var prevOuts map[wire.OutPoint]*wire.TxOut
 
 	// Create input source
 	height, _ := w.ws.ChainTip()
@@ -57,30 +90,14 @@ func (w *BitcoinCashWallet) buildTx(amount int64, addr btc.Address, feeLevel wi.
 		if err != nil {
 			return total, inputs, inputValues, scripts, wi.ErrorInsuffientFunds
 		}
-		additionalPrevScripts = make(map[wire.OutPoint][]byte)
-		additionalKeysByAddress = make(map[string]*btc.WIF)
-		inVals = make(map[wire.OutPoint]int64)
+		prevOuts = make(map[wire.OutPoint]*wire.TxOut)
 		for _, c := range coins.Coins() {
 			total += c.Value()
 			outpoint := wire.NewOutPoint(c.Hash(), c.Index())
 			in := wire.NewTxIn(outpoint, []byte{}, [][]byte{})
 			in.Sequence = 0 // Opt-in RBF so we can bump fees
 			inputs = append(inputs, in)
-			additionalPrevScripts[*outpoint] = c.PkScript()
-			key := coinMap[c]
-			addr, err := key.Address(w.params)
-			if err != nil {
-				continue
-			}
-			privKey, err := key.ECPrivKey()
-			if err != nil {
-				continue
-			}
-			wif, _ := btc.NewWIF(privKey, w.params, true)
-			additionalKeysByAddress[addr.EncodeAddress()] = wif
-			val := c.Value()
-			sat := val.ToUnit(btc.AmountSatoshi)
-			inVals[*outpoint] = int64(sat)
+			prevOuts[*outpoint] = wire.NewTxOut(int64(c.Value()), c.PkScript())
 		}
 		return total, inputs, inputValues, scripts, nil
 	}
@@ -88,24 +105,19 @@ func (w *BitcoinCashWallet) buildTx(amount int64, addr btc.Address, feeLevel wi.
 	// Get the fee per kilobyte
 	feePerKB := int64(w.GetFeePerByte(feeLevel)) * 1000
 
-	// outputs
-	out := wire.NewTxOut(amount, script)
-
 	// Create change source
+	var changeScript []byte
 	changeSource := func() ([]byte, error) {
 		addr := w.CurrentAddress(wi.INTERNAL)
 		script, err := bchutil.PayToAddrScript(addr)
 		if err != nil {
 			return []byte{}, err
 		}
+		changeScript = script
 		return script, nil
 	}
 
-	outputs := []*wire.TxOut{out}
-	if optionalOutput != nil {
-		outputs = append(outputs, optionalOutput)
-	}
-	authoredTx, err := newUnsignedTransaction(outputs, btc.Amount(feePerKB), inputSource, changeSource)
+	authoredTx, err := newUnsignedTransaction(outputs, btc.Amount(feePerKB), w.sigScheme, inputSource, changeSource)
 	if err != nil {
 		return nil, err
 	}
@@ -113,30 +125,28 @@ func (w *BitcoinCashWallet) buildTx(amount int64, addr btc.Address, feeLevel wi.
 	// BIP 69 sorting
 	txsort.InPlaceSort(authoredTx.Tx)
 
-	// Sign tx
-	getKey := txscript.KeyClosure(func(addr btc.Address) (*btcec.PrivateKey, bool, error) {
-		addrStr := addr.EncodeAddress()
-		wif := additionalKeysByAddress[addrStr]
-		return wif.PrivKey, wif.CompressPubKey, nil
-	})
-	getScript := txscript.ScriptClosure(func(
-		addr btc.Address) ([]byte, error) {
-		return []byte{}, nil
-	})
-	for i, txIn := range authoredTx.Tx.TxIn {
-		prevOutScript := additionalPrevScripts[txIn.PreviousOutPoint]
-		script, err := bchutil.SignTxOutput(w.params,
-			authoredTx.Tx, i, prevOutScript, txscript.SigHashAll, getKey,
-			getScript, txIn.SignatureScript, inVals[txIn.PreviousOutPoint])
-		if err != nil {
-			return nil, errors.New("Failed to sign transaction")
-		}
-		txIn.SignatureScript = script
+	plan, err := util.NewSpendPlan(authoredTx.Tx, prevOuts, changeScript, w.ScriptToAddress)
+	if err != nil {
+		return nil, err
+	}
+	plan.FeePerByte = w.GetFeePerByte(feeLevel)
+	plan.VSize = w.estimateSerializeSize(len(plan.Inputs), authoredTx.Tx.TxOut, false, P2PKH)
+	if authoredTx.ChangeIndex < 0 {
+		requiredFee := txrules.FeeForSerializeSize(btc.Amount(feePerKB), w.estimateSerializeSize(len(plan.Inputs), outputs, true, P2PKH))
+		plan.DroppedChange = plan.Fee - int64(requiredFee)
+	}
+	return plan, nil
+}
+
+func (w *BitcoinCashWallet) buildSpendAllTx(addr btc.Address, feeLevel wi.FeeLevel, optionalOutput *wire.TxOut) (*wire.MsgTx, error) {
+	plan, err := w.planSpendAllTx(addr, feeLevel, optionalOutput)
+	if err != nil {
+		return nil, err
 	}
-	return authoredTx.Tx, nil
+	return w.signPlan(plan)
 }
 
-func (w *BitcoinCashWallet) buildSpendAllTx(addr btc.Address, feeLevel wi.FeeLevel) (*wire.MsgTx, error) {
+func (w *BitcoinCashWallet) planSpendAllTx(addr btc.Address, feeLevel wi.FeeLevel, optionalOutput *wire.TxOut) (*util.SpendPlan, error) {
 	tx := wire.NewMsgTx(1)
 
 	height, _ := w.ws.ChainTip()
@@ -146,7 +156,11 @@ func (w *BitcoinCashWallet) buildSpendAllTx(addr btc.Address, feeLevel wi.FeeLev
 	}
 	coinMap := util.GatherCoins(height, utxos, w.ScriptToAddress, w.km.GetKeyForScript)
 
-	totalIn, inVals, additionalPrevScripts, additionalKeysByAddress := util.LoadAllInputs(tx, coinMap, w.params)
+	totalIn, inVals, additionalPrevScripts, _ := util.LoadAllInputs(tx, coinMap, w.params)
+	prevOuts := make(map[wire.OutPoint]*wire.TxOut)
+	for op, script := range additionalPrevScripts {
+		prevOuts[op] = wire.NewTxOut(inVals[op], script)
+	}
 
 	// outputs
 	script, err := bchutil.PayToAddrScript(addr)
@@ -154,9 +168,14 @@ func (w *BitcoinCashWallet) buildSpendAllTx(addr btc.Address, feeLevel wi.FeeLev
 		return nil, err
 	}
 
+	outputs := []*wire.TxOut{wire.NewTxOut(0, script)}
+	if optionalOutput != nil {
+		outputs = append(outputs, optionalOutput)
+	}
+
 	// Get the fee
 	feePerByte := int64(w.GetFeePerByte(feeLevel))
-	estimatedSize := EstimateSerializeSize(1, []*wire.TxOut{wire.NewTxOut(0, script)}, false, P2PKH)
+	estimatedSize := w.estimateSerializeSize(1, outputs, false, P2PKH)
 	fee := int64(estimatedSize) * feePerByte
 
 	// Check for dust output
@@ -167,11 +186,38 @@ func (w *BitcoinCashWallet) buildSpendAllTx(addr btc.Address, feeLevel wi.FeeLev
 	// Build the output
 	out := wire.NewTxOut(totalIn-fee, script)
 	tx.TxOut = append(tx.TxOut, out)
+	if optionalOutput != nil {
+		tx.TxOut = append(tx.TxOut, optionalOutput)
+	}
 
 	// BIP 69 sorting
 	txsort.InPlaceSort(tx)
 
+	plan, err := util.NewSpendPlan(tx, prevOuts, nil, w.ScriptToAddress)
+	if err != nil {
+		return nil, err
+	}
+	plan.FeePerByte = uint64(feePerByte)
+	plan.VSize = w.estimateSerializeSize(len(tx.TxIn), tx.TxOut, false, P2PKH)
+	return plan, nil
+}
+
+// signPlan signs the transaction of plan. It fails if the wallet can no
+// longer spend the inputs of the plan.
+func (w *BitcoinCashWallet) signPlan(plan *util.SpendPlan) (*wire.MsgTx, error) {
+	height, _ := w.ws.ChainTip()
+	utxos, err := w.db.Utxos().GetAll()
+	if err != nil {
+		return nil, err
+	}
+	coinMap, err := plan.Coins(util.GatherCoins(height, utxos, w.ScriptToAddress, w.km.GetKeyForScript))
+	if err != nil {
+		return nil, err
+	}
+	_, inVals, additionalPrevScripts, additionalKeysByAddress := util.LoadAllInputs(wire.NewMsgTx(1), coinMap, w.params)
+
 	// Sign
+	tx := plan.Tx()
 	getKey := txscript.KeyClosure(func(addr btc.Address) (*btcec.PrivateKey, bool, error) {
 		addrStr := addr.EncodeAddress()
 		wif, ok := additionalKeysByAddress[addrStr]
@@ -186,9 +232,8 @@ func (w *BitcoinCashWallet) buildSpendAllTx(addr btc.Address, feeLevel wi.FeeLev
 	})
 	for i, txIn := range tx.TxIn {
 		prevOutScript := additionalPrevScripts[txIn.PreviousOutPoint]
-		script, err := bchutil.SignTxOutput(w.params,
-			tx, i, prevOutScript, txscript.SigHashAll, getKey,
-			getScript, txIn.SignatureScript, inVals[txIn.PreviousOutPoint])
+		script, err := w.signTxOutput(tx, i, prevOutScript, getKey,
+			getScript, inVals[txIn.PreviousOutPoint])
 		if err != nil {
 			return nil, errors.New("failed to sign transaction")
 		}
@@ -197,14 +242,14 @@ func (w *BitcoinCashWallet) buildSpendAllTx(addr btc.Address, feeLevel wi.FeeLev
 	return tx, nil
 }
 
-func newUnsignedTransaction(outputs []*wire.TxOut, feePerKb btc.Amount, fetchInputs txauthor.InputSource, fetchChange txauthor.ChangeSource) (*txauthor.AuthoredTx, error) {
+func newUnsignedTransaction(outputs []*wire.TxOut, feePerKb btc.Amount, scheme SignatureScheme, fetchInputs txauthor.InputSource, fetchChange txauthor.ChangeSource) (*txauthor.AuthoredTx, error) {
 
 	var targetAmount btc.Amount
 	for _, txOut := range outputs {
 		targetAmount += btc.Amount(txOut.Value)
 	}
 
-	estimatedSize := EstimateSerializeSize(1, outputs, true, P2PKH)
+	estimatedSize := EstimateSerializeSizeWithScheme(1, outputs, true, P2PKH, scheme)
 	targetFee := txrules.FeeForSerializeSize(feePerKb, estimatedSize)
 
 	for {
@@ -216,7 +261,7 @@ func newUnsignedTransaction(outputs []*wire.TxOut, feePerKb btc.Amount, fetchInp
 			return nil, errors.New("insufficient funds available to construct transaction")
 		}
 
-		maxSignedSize := EstimateSerializeSize(len(inputs), outputs, true, P2PKH)
+		maxSignedSize := EstimateSerializeSizeWithScheme(len(inputs), outputs, true, P2PKH, scheme)
 		maxRequiredFee := txrules.FeeForSerializeSize(feePerKb, maxSignedSize)
 		remainingAmount := inputAmount - targetAmount
 		if remainingAmount < maxRequiredFee {
@@ -315,6 +360,7 @@ func (w *BitcoinCashWallet) sweepAddress(ins []wi.TransactionInput, address *btc
 	var val int64
 	var inputs []*wire.TxIn
 	additionalPrevScripts := make(map[wire.OutPoint][]byte)
+	inVals := make(map[wire.OutPoint]int64)
 	for _, in := range ins {
 		val += in.Value
 		ch, err := chainhash.NewHashFromStr(hex.EncodeToString(in.OutpointHash))
@@ -329,6 +375,7 @@ func (w *BitcoinCashWallet) sweepAddress(ins []wi.TransactionInput, address *btc
 		input := wire.NewTxIn(outpoint, []byte{}, [][]byte{})
 		inputs = append(inputs, input)
 		additionalPrevScripts[*outpoint] = script
+		inVals[*outpoint] = in.Value
 	}
 	out := wire.NewTxOut(val, script)
 
@@ -340,7 +387,7 @@ func (w *BitcoinCashWallet) sweepAddress(ins []wi.TransactionInput, address *btc
 			txType = P2SH_Multisig_Timelock_1Sig
 		}
 	}
-	estimatedSize := EstimateSerializeSize(len(ins), []*wire.TxOut{out}, false, txType)
+	estimatedSize := w.estimateSerializeSize(len(ins), []*wire.TxOut{out}, false, txType)
 
 	// Calculate the fee
 	feePerByte := int(w.GetFeePerByte(feeLevel))
@@ -408,21 +455,40 @@ func (w *BitcoinCashWallet) sweepAddress(ins []wi.TransactionInput, address *btc
 	}
 
 	for i, txIn := range tx.TxIn {
-		if !timeLocked {
+		amt := inVals[txIn.PreviousOutPoint]
+		if !timeLocked && (redeemScript == nil || w.sigScheme != Schnorr) {
 			prevOutScript := additionalPrevScripts[txIn.PreviousOutPoint]
-			script, err := bchutil.SignTxOutput(w.params,
-				tx, i, prevOutScript, txscript.SigHashAll, getKey,
-				getScript, txIn.SignatureScript, ins[i].Value)
+			script, err := w.signTxOutput(tx, i, prevOutScript, getKey,
+				getScript, amt)
 			if err != nil {
 				return nil, errors.New("Failed to sign transaction")
 			}
 			txIn.SignatureScript = script
+		} else if !timeLocked {
+			// A Schnorr multisig spend flags the signing key in the dummy element
+			sig, err := rawTxInSignature(tx, i, *redeemScript, txscript.SigHashAll, privKey, amt, Schnorr)
+			if err != nil {
+				return nil, err
+			}
+			checkBits, sigs, err := schnorrMultisigSigs(tx, i, *redeemScript, amt, [][]byte{sig})
+			if err != nil {
+				return nil, err
+			}
+			scriptSig, err := txscript.NewScriptBuilder().
+				AddData(checkBits).
+				AddData(sigs[0]).
+				AddData(*redeemScript).
+				Script()
+			if err != nil {
+				return nil, err
+			}
+			txIn.SignatureScript = scriptSig
 		} else {
 			priv, err := key.ECPrivKey()
 			if err != nil {
 				return nil, err
 			}
-			script, err := bchutil.RawTxInSignature(tx, i, *redeemScript, txscript.SigHashAll, priv, ins[i].Value)
+			script, err := rawTxInSignature(tx, i, *redeemScript, txscript.SigHashAll, priv, amt, w.sigScheme)
 			if err != nil {
 				return nil, err
 			}
@@ -443,34 +509,33 @@ func (w *BitcoinCashWallet) sweepAddress(ins []wi.TransactionInput, address *btc
 	return &txid, nil
 }
 
-func (w *BitcoinCashWallet) createMultisigSignature(ins []wi.TransactionInput, outs []wi.TransactionOutput, key *hd.ExtendedKey, redeemScript []byte, feePerByte uint64) ([]wi.Signature, error) {
-	var sigs []wi.Signature
+// newMultisigTx returns the unsigned transaction the cosigners of the
+// redeem script sign, with the fee of inputs signed with scheme subtracted
+// from the outputs, and the values of the outputs it spends.
+func (w *BitcoinCashWallet) newMultisigTx(ins []wi.TransactionInput, outs []wi.TransactionOutput, rs *multisig.RedeemScript, feePerByte uint64, scheme SignatureScheme) (*wire.MsgTx, map[wire.OutPoint]int64, error) {
 	tx := wire.NewMsgTx(1)
+	inVals := make(map[wire.OutPoint]int64)
 	for _, in := range ins {
 		ch, err := chainhash.NewHashFromStr(hex.EncodeToString(in.OutpointHash))
 		if err != nil {
-			return sigs, err
+			return nil, nil, err
 		}
 		outpoint := wire.NewOutPoint(ch, in.OutpointIndex)
 		input := wire.NewTxIn(outpoint, []byte{}, [][]byte{})
 		tx.TxIn = append(tx.TxIn, input)
+		inVals[*outpoint] = in.Value
 	}
 	for _, out := range outs {
 		scriptPubkey, err := bchutil.PayToAddrScript(out.Address)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		output := wire.NewTxOut(out.Value, scriptPubkey)
 		tx.TxOut = append(tx.TxOut, output)
 	}
 
 	// Subtract fee
-	txType := P2SH_2of3_Multisig
-	_, err := spvwallet.LockTimeFromRedeemScript(redeemScript)
-	if err == nil {
-		txType = P2SH_Multisig_Timelock_2Sigs
-	}
-	estimatedSize := EstimateSerializeSize(len(ins), tx.TxOut, false, txType)
+	estimatedSize := EstimateMultisigSerializeSizeWithScheme(len(ins), tx.TxOut, rs, scheme)
 	fee := estimatedSize * int(feePerByte)
 	if len(tx.TxOut) > 0 {
 		feePerOutput := fee / len(tx.TxOut)
@@ -481,14 +546,27 @@ func (w *BitcoinCashWallet) createMultisigSignature(ins []wi.TransactionInput, o
 
 	// BIP 69 sorting
 	txsort.InPlaceSort(tx)
+	return tx, inVals, nil
+}
+
+func (w *BitcoinCashWallet) createMultisigSignature(ins []wi.TransactionInput, outs []wi.TransactionOutput, key *hd.ExtendedKey, redeemScript []byte, feePerByte uint64) ([]wi.Signature, error) {
+	var sigs []wi.Signature
+	rs, err := multisig.ParseRedeemScript(redeemScript)
+	if err != nil {
+		return sigs, err
+	}
+	tx, inVals, err := w.newMultisigTx(ins, outs, rs, feePerByte, w.sigScheme)
+	if err != nil {
+		return sigs, err
+	}
 
 	signingKey, err := key.ECPrivKey()
 	if err != nil {
 		return sigs, err
 	}
 
-	for i := range tx.TxIn {
-		sig, err := bchutil.RawTxInSignature(tx, i, redeemScript, txscript.SigHashAll, signingKey, ins[i].Value)
+	for i, txIn := range tx.TxIn {
+		sig, err := rawTxInSignature(tx, i, redeemScript, txscript.SigHashAll, signingKey, inVals[txIn.PreviousOutPoint], w.sigScheme)
 		if err != nil {
 			continue
 		}
@@ -499,47 +577,21 @@ func (w *BitcoinCashWallet) createMultisigSignature(ins []wi.TransactionInput, o
 }
 
 func (w *BitcoinCashWallet) multisign(ins []wi.TransactionInput, outs []wi.TransactionOutput, sigs1 []wi.Signature, sigs2 []wi.Signature, redeemScript []byte, feePerByte uint64, broadcast bool) ([]byte, error) {
-	tx := wire.NewMsgTx(1)
-	for _, in := range ins {
-		ch, err := chainhash.NewHashFromStr(hex.EncodeToString(in.OutpointHash))
-		if err != nil {
-			return nil, err
-		}
-		outpoint := wire.NewOutPoint(ch, in.OutpointIndex)
-		input := wire.NewTxIn(outpoint, []byte{}, [][]byte{})
-		tx.TxIn = append(tx.TxIn, input)
-	}
-	for _, out := range outs {
-		scriptPubkey, err := bchutil.PayToAddrScript(out.Address)
-		if err != nil {
-			return nil, err
-		}
-		output := wire.NewTxOut(out.Value, scriptPubkey)
-		tx.TxOut = append(tx.TxOut, output)
+	rs, err := multisig.ParseRedeemScript(redeemScript)
+	if err != nil {
+		return nil, err
 	}
 
-	// Subtract fee
-	txType := P2SH_2of3_Multisig
-	_, err := spvwallet.LockTimeFromRedeemScript(redeemScript)
-	if err == nil {
-		txType = P2SH_Multisig_Timelock_2Sigs
+	// The cosigners must have signed with the same scheme. Schnorr signatures
+	// are only valid in a multisig which flags the signing keys.
+	scheme := ECDSA
+	if isSchnorrSignatures(sigs1) && isSchnorrSignatures(sigs2) {
+		scheme = Schnorr
 	}
-	estimatedSize := EstimateSerializeSize(len(ins), tx.TxOut, false, txType)
-	fee := estimatedSize * int(feePerByte)
-	if len(tx.TxOut) > 0 {
-		feePerOutput := fee / len(tx.TxOut)
-		for _, output := range tx.TxOut {
-			output.Value -= int64(feePerOutput)
-		}
-	}
-
-	// BIP 69 sorting
-	txsort.InPlaceSort(tx)
 
-	// Check if time locked
-	var timeLocked bool
-	if redeemScript[0] == txscript.OP_IF {
-		timeLocked = true
+	tx, inVals, err := w.newMultisigTx(ins, outs, rs, feePerByte, scheme)
+	if err != nil {
+		return nil, err
 	}
 
 	for i, input := range tx.TxIn {
@@ -556,11 +608,22 @@ func (w *BitcoinCashWallet) multisign(ins []wi.TransactionInput, outs []wi.Trans
 			}
 		}
 		builder := txscript.NewScriptBuilder()
-		builder.AddOp(txscript.OP_0)
-		builder.AddData(sig1)
-		builder.AddData(sig2)
+		if scheme == Schnorr {
+			checkBits, sigs, err := schnorrMultisigSigs(tx, i, redeemScript, inVals[input.PreviousOutPoint], [][]byte{sig1, sig2})
+			if err != nil {
+				return nil, err
+			}
+			builder.AddData(checkBits)
+			for _, sig := range sigs {
+				builder.AddData(sig)
+			}
+		} else {
+			builder.AddOp(txscript.OP_0)
+			builder.AddData(sig1)
+			builder.AddData(sig2)
+		}
 
-		if timeLocked {
+		if rs.Timelocked {
 			builder.AddOp(txscript.OP_1)
 		}
 
@@ -582,6 +645,336 @@ func (w *BitcoinCashWallet) multisign(ins []wi.TransactionInput, outs []wi.Trans
 	return buf.Bytes(), nil
 }
 
+// multisignWithSignatures signs the transaction with the signatures of any
+// number of cosigners of an m of n multisig. Until the threshold is met the
+// inputs hold partial signature scripts and complete is false.
+func (w *BitcoinCashWallet) multisignWithSignatures(ins []wi.TransactionInput, outs []wi.TransactionOutput, sigs multisig.Signatures, redeemScript []byte, feePerByte uint64, broadcast bool) ([]byte, bool, error) {
+	rs, err := multisig.ParseRedeemScript(redeemScript)
+	if err != nil {
+		return nil, false, err
+	}
+	var all []wi.Signature
+	for _, keySigs := range sigs {
+		all = append(all, keySigs...)
+	}
+	scheme := ECDSA
+	if isSchnorrSignatures(all) {
+		scheme = Schnorr
+	}
+	tx, _, err := w.newMultisigTx(ins, outs, rs, feePerByte, scheme)
+	if err != nil {
+		return nil, false, err
+	}
+
+	complete := true
+	for i, input := range tx.TxIn {
+		slots, err := rs.Slots(sigs, i)
+		if err != nil {
+			return nil, false, err
+		}
+		scriptSig, ok, err := multisigSigScript(rs, slots)
+		if err != nil {
+			return nil, false, err
+		}
+		input.SignatureScript = scriptSig
+		complete = complete && ok
+	}
+	return w.finishMultisig(tx, complete, broadcast)
+}
+
+// mergeMultisig combines the signatures of partially signed transactions
+// spending the outputs of the redeem script.
+func (w *BitcoinCashWallet) mergeMultisig(redeemScript []byte, txs [][]byte, broadcast bool) ([]byte, bool, error) {
+	rs, err := multisig.ParseRedeemScript(redeemScript)
+	if err != nil {
+		return nil, false, err
+	}
+	var merged *wire.MsgTx
+	var slots [][][]byte
+	var finals [][]byte
+	for _, raw := range txs {
+		tx := wire.NewMsgTx(1)
+		if err := tx.BtcDecode(bytes.NewReader(raw), wire.ProtocolVersion, wire.BaseEncoding); err != nil {
+			return nil, false, err
+		}
+		if merged == nil {
+			merged = tx
+			slots = make([][][]byte, len(tx.TxIn))
+			finals = make([][]byte, len(tx.TxIn))
+		} else if multisig.UnsignedTxHash(tx) != multisig.UnsignedTxHash(merged) {
+			return nil, false, errors.New("partially signed transactions differ")
+		}
+		for i, in := range tx.TxIn {
+			stack, err := multisig.ScriptStack(in.SignatureScript)
+			if err != nil {
+				return nil, false, fmt.Errorf("input %d: %s", i, err)
+			}
+			s, complete, err := rs.ParseStack(stack)
+			if err != nil {
+				return nil, false, fmt.Errorf("input %d: %s", i, err)
+			}
+			if complete {
+				finals[i] = in.SignatureScript
+			} else if slots[i] == nil {
+				slots[i] = s
+			} else if slots[i], err = multisig.MergeSlots(slots[i], s); err != nil {
+				return nil, false, err
+			}
+		}
+	}
+	if merged == nil {
+		return nil, false, errors.New("no transactions to merge")
+	}
+
+	complete := true
+	for i, input := range merged.TxIn {
+		if finals[i] != nil {
+			input.SignatureScript = finals[i]
+			continue
+		}
+		scriptSig, ok, err := multisigSigScript(rs, slots[i])
+		if err != nil {
+			return nil, false, err
+		}
+		input.SignatureScript = scriptSig
+		complete = complete && ok
+	}
+	return w.finishMultisig(merged, complete, broadcast)
+}
+
+// multisigSigScript returns the signature script of an input of the multisig
+// with the signatures in slots. Once complete, Schnorr signatures are flagged
+// in the dummy element by a bitfield of the keys which signed.
+func multisigSigScript(rs *multisig.RedeemScript, slots [][]byte) ([]byte, bool, error) {
+	stack, complete := rs.Stack(slots)
+	if complete {
+		sigs, positions, _ := rs.Select(slots)
+		var schnorrSigs int
+		for _, sig := range sigs {
+			if len(sig) == SchnorrSignatureSize+1 {
+				schnorrSigs++
+			}
+		}
+		switch schnorrSigs {
+		case 0:
+		case len(sigs):
+			checkBits := make([]byte, (len(rs.PubKeys)+7)/8)
+			for _, i := range positions {
+				checkBits[i/8] |= 1 << uint(i%8)
+			}
+			stack[0] = checkBits
+		default:
+			return nil, false, errors.New("cosigners signed with different signature schemes")
+		}
+	}
+	scriptSig, err := multisig.SignatureScript(stack)
+	if err != nil {
+		return nil, false, err
+	}
+	return scriptSig, complete, nil
+}
+
+// finishMultisig serializes a multisig transaction, broadcasting it if
+// requested once every input is fully signed.
+func (w *BitcoinCashWallet) finishMultisig(tx *wire.MsgTx, complete, broadcast bool) ([]byte, bool, error) {
+	if broadcast && complete {
+		if err := w.Broadcast(tx); err != nil {
+			return nil, false, err
+		}
+	}
+	var buf bytes.Buffer
+	tx.BtcEncode(&buf, wire.ProtocolVersion, wire.BaseEncoding)
+	return buf.Bytes(), complete, nil
+}
+
+// releaseAfterTimeout spends the outputs of an escrow through the timeout
+// branch of its redeem script, signed by the timeout key, once its relative
+// lock time has passed.
+func (w *BitcoinCashWallet) releaseAfterTimeout(ins []wi.TransactionInput, address btc.Address, timeoutKey *hd.ExtendedKey, redeemScript []byte, feeLevel wi.FeeLevel) (*chainhash.Hash, error) {
+	rs, err := multisig.ParseRedeemScript(redeemScript)
+	if err != nil {
+		return nil, err
+	}
+	blocks, err := rs.TimeoutBlocks()
+	if err != nil {
+		return nil, err
+	}
+	privKey, err := timeoutKey.ECPrivKey()
+	if err != nil {
+		return nil, fmt.Errorf("retrieving private key: %s", err.Error())
+	}
+	if !bytes.Equal(privKey.PubKey().SerializeCompressed(), rs.TimeoutKey) {
+		return nil, errors.New("key is not the timeout key of the redeem script")
+	}
+	if address == nil {
+		address = w.CurrentAddress(wi.INTERNAL)
+	}
+	script, err := w.AddressToScript(address)
+	if err != nil {
+		return nil, err
+	}
+
+	// The relative lock time is only enforced on version 2 transactions
+	tx := wire.NewMsgTx(2)
+	var val int64
+	var outpoints []wire.OutPoint
+	inVals := make(map[wire.OutPoint]int64)
+	for _, in := range ins {
+		ch, err := chainhash.NewHashFromStr(hex.EncodeToString(in.OutpointHash))
+		if err != nil {
+			return nil, err
+		}
+		outpoint := wire.NewOutPoint(ch, in.OutpointIndex)
+		input := wire.NewTxIn(outpoint, []byte{}, [][]byte{})
+		input.Sequence = rs.Timeout
+		tx.TxIn = append(tx.TxIn, input)
+		outpoints = append(outpoints, *outpoint)
+		inVals[*outpoint] = in.Value
+		val += in.Value
+	}
+	if err := w.ws.CheckEscrowTimeout(outpoints, blocks); err != nil {
+		return nil, err
+	}
+
+	out := wire.NewTxOut(val, script)
+	tx.TxOut = append(tx.TxOut, out)
+	fee := int64(EstimateTimeoutSerializeSize(len(ins), tx.TxOut, rs, w.sigScheme)) * int64(w.GetFeePerByte(feeLevel))
+	out.Value = val - fee
+	if w.IsDust(out.Value) {
+		return nil, wi.ErrorDustAmount
+	}
+
+	// BIP 69 sorting
+	txsort.InPlaceSort(tx)
+
+	for i, txIn := range tx.TxIn {
+		sig, err := rawTxInSignature(tx, i, redeemScript, txscript.SigHashAll, privKey, inVals[txIn.PreviousOutPoint], w.sigScheme)
+		if err != nil {
+			return nil, err
+		}
+		scriptSig, err := multisig.SignatureScript(rs.TimeoutStack(sig))
+		if err != nil {
+			return nil, err
+		}
+		txIn.SignatureScript = scriptSig
+	}
+
+	// broadcast
+	if err := w.Broadcast(tx); err != nil {
+		return nil, err
+	}
+	txid := tx.TxHash()
+	return &txid, nil
+}
+
+// generateHTLCScript returns the P2SH address and script of a hash time locked
+// contract.
+func (w *BitcoinCashWallet) generateHTLCScript(secretHash, recipientKey, refundKey []byte, lockTime uint32) (btc.Address, []byte, error) {
+	contract, err := htlc.NewContract(secretHash, recipientKey, refundKey, lockTime)
+	if err != nil {
+		return nil, nil, err
+	}
+	addr, err := bchutil.NewCashAddressScriptHash(contract.Script, w.params)
+	if err != nil {
+		return nil, nil, err
+	}
+	return addr, contract.Script, nil
+}
+
+// spendHTLC spends the outputs of a hash time locked contract to address. The
+// contract is redeemed by the recipient key if a secret is given and refunded
+// by the refund key otherwise.
+func (w *BitcoinCashWallet) spendHTLC(ins []wi.TransactionInput, address btc.Address, key *hd.ExtendedKey, script []byte, secret []byte, feeLevel wi.FeeLevel) (*chainhash.Hash, error) {
+	contract, err := htlc.ParseContract(script)
+	if err != nil {
+		return nil, err
+	}
+	privKey, err := key.ECPrivKey()
+	if err != nil {
+		return nil, fmt.Errorf("retrieving private key: %s", err.Error())
+	}
+	pubKey := privKey.PubKey().SerializeCompressed()
+	redeem := secret != nil
+	if redeem {
+		if !contract.Matches(secret) {
+			return nil, htlc.ErrSecretMismatch
+		}
+		if !bytes.Equal(pubKey, contract.RecipientKey) {
+			return nil, errors.New("key is not the recipient key of the contract")
+		}
+	} else {
+		if !bytes.Equal(pubKey, contract.RefundKey) {
+			return nil, errors.New("key is not the refund key of the contract")
+		}
+		if tip, _ := w.ChainTip(); !contract.Refundable(tip) {
+			return nil, htlc.ErrLockTimeNotReached
+		}
+	}
+	if address == nil {
+		address = w.CurrentAddress(wi.INTERNAL)
+	}
+	outScript, err := w.AddressToScript(address)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := wire.NewMsgTx(wire.TxVersion)
+	var val int64
+	inVals := make(map[wire.OutPoint]int64)
+	for _, in := range ins {
+		ch, err := chainhash.NewHashFromStr(hex.EncodeToString(in.OutpointHash))
+		if err != nil {
+			return nil, err
+		}
+		outpoint := wire.NewOutPoint(ch, in.OutpointIndex)
+		input := wire.NewTxIn(outpoint, []byte{}, [][]byte{})
+		if !redeem {
+			// The lock time is only enforced on inputs which are not final
+			input.Sequence = wire.MaxTxInSequenceNum - 1
+		}
+		tx.TxIn = append(tx.TxIn, input)
+		inVals[*outpoint] = in.Value
+		val += in.Value
+	}
+	if !redeem {
+		tx.LockTime = contract.LockTime
+	}
+
+	out := wire.NewTxOut(val, outScript)
+	tx.TxOut = append(tx.TxOut, out)
+	fee := int64(EstimateHTLCSerializeSize(len(ins), tx.TxOut, redeem, w.sigScheme)) * int64(w.GetFeePerByte(feeLevel))
+	out.Value = val - fee
+	if w.IsDust(out.Value) {
+		return nil, wi.ErrorDustAmount
+	}
+
+	// BIP 69 sorting
+	txsort.InPlaceSort(tx)
+
+	for i, txIn := range tx.TxIn {
+		sig, err := rawTxInSignature(tx, i, script, txscript.SigHashAll, privKey, inVals[txIn.PreviousOutPoint], w.sigScheme)
+		if err != nil {
+			return nil, err
+		}
+		stack := contract.RefundStack(sig)
+		if redeem {
+			stack = contract.RedeemStack(sig, secret)
+		}
+		scriptSig, err := multisig.SignatureScript(stack)
+		if err != nil {
+			return nil, err
+		}
+		txIn.SignatureScript = scriptSig
+	}
+
+	// broadcast
+	if err := w.Broadcast(tx); err != nil {
+		return nil, err
+	}
+	txid := tx.TxHash()
+	return &txid, nil
+}
+
 func (w *BitcoinCashWallet) generateMultisigScript(keys []hd.ExtendedKey, threshold int, timeout time.Duration, timeoutKey *hd.ExtendedKey) (addr btc.Address, redeemScript []byte, err error) {
 	if uint32(timeout.Hours()) > 0 && timeoutKey == nil {
 		return nil, nil, errors.New("Timeout key must be non nil when using an escrow timeout")
@@ -676,3 +1069,216 @@ func (w *BitcoinCashWallet) estimateSpendFee(amount int64, feeLevel wi.FeeLevel)
 	}
 	return uint64(inval - outval), err
 }
+
+// sigHashForkID is the sighash flag Bitcoin Cash requires on every signature.
+// It selects the BIP143 signature hash which commits to the input amount.
+const sigHashForkID txscript.SigHashType = 0x40
+
+const sigHashMask = 0x1f
+
+// calcSignatureHash computes the BIP143 signature hash, with SIGHASH_FORKID
+// set, of the input idx spending amt with subScript as its script code.
+func calcSignatureHash(subScript []byte, hashType txscript.SigHashType, tx *wire.MsgTx, idx int, amt int64) ([]byte, error) {
+	if idx > len(tx.TxIn)-1 {
+		return nil, fmt.Errorf("idx %d but %d txins", idx, len(tx.TxIn))
+	}
+	var (
+		zeroHash     chainhash.Hash
+		anyoneCanPay = hashType&txscript.SigHashAnyOneCanPay != 0
+		baseType     = hashType & sigHashMask
+
+		hashPrevOuts, hashSequence, hashOutputs = zeroHash[:], zeroHash[:], zeroHash[:]
+	)
+	if !anyoneCanPay {
+		var b bytes.Buffer
+		for _, in := range tx.TxIn {
+			b.Write(in.PreviousOutPoint.Hash[:])
+			var index [4]byte
+			binary.LittleEndian.PutUint32(index[:], in.PreviousOutPoint.Index)
+			b.Write(index[:])
+		}
+		hashPrevOuts = chainhash.DoubleHashB(b.Bytes())
+	}
+	if !anyoneCanPay && baseType != txscript.SigHashSingle && baseType != txscript.SigHashNone {
+		var b bytes.Buffer
+		for _, in := range tx.TxIn {
+			var sequence [4]byte
+			binary.LittleEndian.PutUint32(sequence[:], in.Sequence)
+			b.Write(sequence[:])
+		}
+		hashSequence = chainhash.DoubleHashB(b.Bytes())
+	}
+	if baseType != txscript.SigHashSingle && baseType != txscript.SigHashNone {
+		var b bytes.Buffer
+		for _, out := range tx.TxOut {
+			wire.WriteTxOut(&b, 0, 0, out)
+		}
+		hashOutputs = chainhash.DoubleHashB(b.Bytes())
+	} else if baseType == txscript.SigHashSingle && idx < len(tx.TxOut) {
+		var b bytes.Buffer
+		wire.WriteTxOut(&b, 0, 0, tx.TxOut[idx])
+		hashOutputs = chainhash.DoubleHashB(b.Bytes())
+	}
+
+	var sigHash bytes.Buffer
+	var buf [8]byte
+	binary.LittleEndian.PutUint32(buf[:4], uint32(tx.Version))
+	sigHash.Write(buf[:4])
+	sigHash.Write(hashPrevOuts)
+	sigHash.Write(hashSequence)
+
+	txIn := tx.TxIn[idx]
+	sigHash.Write(txIn.PreviousOutPoint.Hash[:])
+	binary.LittleEndian.PutUint32(buf[:4], txIn.PreviousOutPoint.Index)
+	sigHash.Write(buf[:4])
+	if err := wire.WriteVarBytes(&sigHash, 0, subScript); err != nil {
+		return nil, err
+	}
+	binary.LittleEndian.PutUint64(buf[:], uint64(amt))
+	sigHash.Write(buf[:])
+	binary.LittleEndian.PutUint32(buf[:4], txIn.Sequence)
+	sigHash.Write(buf[:4])
+
+	sigHash.Write(hashOutputs)
+	binary.LittleEndian.PutUint32(buf[:4], tx.LockTime)
+	sigHash.Write(buf[:4])
+	binary.LittleEndian.PutUint32(buf[:4], uint32(hashType|sigHashForkID))
+	sigHash.Write(buf[:4])
+
+	return chainhash.DoubleHashB(sigHash.Bytes()), nil
+}
+
+// rawTxInSignature returns the signature of the input idx with the sighash
+// type appended, signed with the given scheme.
+func rawTxInSignature(tx *wire.MsgTx, idx int, subScript []byte, hashType txscript.SigHashType,
+	key *btcec.PrivateKey, amt int64, scheme SignatureScheme) ([]byte, error) {
+
+	if scheme != Schnorr {
+		return bchutil.RawTxInSignature(tx, idx, subScript, hashType, key, amt)
+	}
+	hash, err := calcSignatureHash(subScript, hashType, tx, idx, amt)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := schnorrSign(key, hash)
+	if err != nil {
+		return nil, err
+	}
+	return append(sig, byte(hashType|sigHashForkID)), nil
+}
+
+// signTxOutput returns the signature script spending the output prevOutScript
+// with the wallet's signature scheme. Only P2PKH outputs can be signed with
+// Schnorr signatures here; multisig outputs are signed by the callers.
+func (w *BitcoinCashWallet) signTxOutput(tx *wire.MsgTx, idx int, prevOutScript []byte,
+	getKey txscript.KeyDB, getScript txscript.ScriptDB, amt int64) ([]byte, error) {
+
+	if w.sigScheme != Schnorr {
+		return bchutil.SignTxOutput(w.params, tx, idx, prevOutScript, txscript.SigHashAll,
+			getKey, getScript, tx.TxIn[idx].SignatureScript, amt)
+	}
+	class, addrs, _, err := txscript.ExtractPkScriptAddrs(prevOutScript, w.params)
+	if err != nil {
+		return nil, err
+	}
+	if class != txscript.PubKeyHashTy || len(addrs) != 1 {
+		return nil, fmt.Errorf("cannot sign %s output with schnorr", class)
+	}
+	key, compressed, err := getKey.GetKey(addrs[0])
+	if err != nil {
+		return nil, err
+	}
+	sig, err := rawTxInSignature(tx, idx, prevOutScript, txscript.SigHashAll, key, amt, Schnorr)
+	if err != nil {
+		return nil, err
+	}
+	pk := key.PubKey().SerializeUncompressed()
+	if compressed {
+		pk = key.PubKey().SerializeCompressed()
+	}
+	return txscript.NewScriptBuilder().AddData(sig).AddData(pk).Script()
+}
+
+// isSchnorrSignatures reports whether every signature is a Schnorr signature
+func isSchnorrSignatures(sigs []wi.Signature) bool {
+	for _, sig := range sigs {
+		if len(sig.Signature) != SchnorrSignatureSize+1 {
+			return false
+		}
+	}
+	return len(sigs) > 0
+}
+
+// multisigPubKeys returns the keys of the multisig in a redeem script in script
+// order. The timeout key of a timelocked escrow is not part of the multisig.
+func multisigPubKeys(redeemScript []byte) ([][]byte, error) {
+	pushes, err := txscript.PushedData(redeemScript)
+	if err != nil {
+		return nil, err
+	}
+	var keys [][]byte
+	for _, push := range pushes {
+		if len(push) == btcec.PubKeyBytesLenCompressed {
+			keys = append(keys, push)
+		}
+	}
+	if len(keys) > 0 && redeemScript[0] == txscript.OP_IF {
+		keys = keys[:len(keys)-1]
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("redeem script has no multisig keys")
+	}
+	return keys, nil
+}
+
+// schnorrMultisigSigs matches Schnorr signatures of the input idx to the keys of
+// the redeem script. It returns the dummy element of OP_CHECKMULTISIG, which
+// in Schnorr mode is a bitfield of the keys which signed, and the signatures
+// in key order.
+func schnorrMultisigSigs(tx *wire.MsgTx, idx int, redeemScript []byte, amt int64, sigs [][]byte) ([]byte, [][]byte, error) {
+	keys, err := multisigPubKeys(redeemScript)
+	if err != nil {
+		return nil, nil, err
+	}
+	hash, err := calcSignatureHash(redeemScript, txscript.SigHashAll, tx, idx, amt)
+	if err != nil {
+		return nil, nil, err
+	}
+	matched := make([][]byte, len(keys))
+	for _, sig := range sigs {
+		found := false
+		for i, key := range keys {
+			if matched[i] != nil || len(sig) != SchnorrSignatureSize+1 {
+				continue
+			}
+			pub, err := btcec.ParsePubKey(key, btcec.S256())
+			if err != nil {
+				continue
+			}
+			if schnorrVerify(pub, hash, sig[:SchnorrSignatureSize]) {
+				matched[i] = sig
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, nil, fmt.Errorf("signature of input %d does not match a key of the redeem script", idx)
+		}
+	}
+
+	checkBits := make([]byte, (len(keys)+7)/8)
+	var ordered [][]byte
+	for i, sig := range matched {
+		if sig != nil {
+			checkBits[i/8] |= 1 << uint(i%8)
+			ordered = append(ordered, sig)
+		}
+	}
+	return checkBits, ordered, nil
+}
+
+// estimateSerializeSize is EstimateSerializeSize for inputs signed with the
+// wallet's signature scheme.
+func (w *BitcoinCashWallet) estimateSerializeSize(inputCount int, txOuts []*wire.TxOut, addChangeOutput bool, inputType InputType) int {
+	return EstimateSerializeSizeWithScheme(inputCount, txOuts, addChangeOutput, inputType, w.sigScheme)
+}
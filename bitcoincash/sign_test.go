@@ -11,8 +11,10 @@ import (
 
 	"github.com/muecoin/multiwallet/cache"
 	"github.com/muecoin/multiwallet/datastore"
+	"github.com/muecoin/multiwallet/htlc"
 	"github.com/muecoin/multiwallet/keys"
 	"github.com/muecoin/multiwallet/model/mock"
+	"github.com/muecoin/multiwallet/multisig"
 	"github.com/muecoin/multiwallet/service"
 	"github.com/OpenBazaar/wallet-interface"
 	"github.com/btcsuite/btcd/chaincfg"
@@ -122,6 +124,29 @@ func TestBitcoinCashWallet_buildTx(t *testing.T) {
 		t.Error("Built tx does not contain a valid change output")
 	}
 
+	// Data output
+	dataScript, err := txscript.NullDataScript([]byte("order 1a3w"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dataOut := wire.NewTxOut(0, dataScript)
+	tx, err = w.buildTx(1500000, addr, wallet.NORMAL, dataOut)
+	if err != nil {
+		t.Error(err)
+	}
+	if !containsOutput(tx, addr) {
+		t.Error("Built tx does not contain the requested output")
+	}
+	hasData := false
+	for _, out := range tx.TxOut {
+		if bytes.Equal(out.PkScript, dataOut.PkScript) && out.Value == 0 {
+			hasData = true
+		}
+	}
+	if !hasData {
+		t.Error("Built tx does not contain the data output")
+	}
+
 	// Insuffient funds
 	_, err = w.buildTx(1000000000, addr, wallet.NORMAL, nil)
 	if err != wallet.ErrorInsuffientFunds {
@@ -135,6 +160,35 @@ func TestBitcoinCashWallet_buildTx(t *testing.T) {
 	}
 }
 
+func TestBitcoinCashWallet_buildTxSchnorr(t *testing.T) {
+	w, err := newMockWallet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.sigScheme = Schnorr
+	w.ws.Start()
+	time.Sleep(time.Second / 2)
+
+	addr, err := w.DecodeAddress("qpf464w2g36kyklq9shvyjk9lvuf6ph7jv3k8qpq0m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err := w.buildTx(1500000, addr, wallet.NORMAL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !containsOutput(tx, addr) {
+		t.Error("Built tx does not contain the requested output")
+	}
+	verifySchnorrP2PKHInputs(t, tx, w.db)
+
+	tx, err = w.buildSpendAllTx(addr, wallet.NORMAL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifySchnorrP2PKHInputs(t, tx, w.db)
+}
+
 func TestBitcoinCashWallet_buildSpendAllTx(t *testing.T) {
 	w, err := newMockWallet()
 	if err != nil {
@@ -150,7 +204,7 @@ func TestBitcoinCashWallet_buildSpendAllTx(t *testing.T) {
 	}
 
 	// Test build spendAll tx
-	tx, err := w.buildSpendAllTx(addr, wallet.NORMAL)
+	tx, err := w.buildSpendAllTx(addr, wallet.NORMAL, nil)
 	if err != nil {
 		t.Error(err)
 	}
@@ -395,7 +449,7 @@ func TestBitcoinCashWallet_newUnsignedTransaction(t *testing.T) {
 	}
 
 	// Regular transaction
-	authoredTx, err := newUnsignedTransaction(outputs, btcutil.Amount(1000), inputSource, changeSource)
+	authoredTx, err := newUnsignedTransaction(outputs, btcutil.Amount(1000), ECDSA, inputSource, changeSource)
 	if err != nil {
 		t.Error(err)
 	}
@@ -408,7 +462,7 @@ func TestBitcoinCashWallet_newUnsignedTransaction(t *testing.T) {
 
 	// Insufficient funds
 	outputs[0].Value = 1000000000
-	_, err = newUnsignedTransaction(outputs, btcutil.Amount(1000), inputSource, changeSource)
+	_, err = newUnsignedTransaction(outputs, btcutil.Amount(1000), ECDSA, inputSource, changeSource)
 	if err == nil {
 		t.Error("Failed to return insuffient funds error")
 	}
@@ -531,6 +585,356 @@ func TestBitcoinCashWallet_Multisign(t *testing.T) {
 	}
 }
 
+func TestBitcoinCashWallet_MultisignSchnorr(t *testing.T) {
+	w, err := newMockWallet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.sigScheme = Schnorr
+
+	var keys []hdkeychain.ExtendedKey
+	for i := 0; i < 3; i++ {
+		key, err := w.km.GetFreshKey(wallet.INTERNAL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, *key)
+	}
+	_, redeemScript, err := w.generateMultisigScript(keys, 2, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ins, outs, _, err := buildTxData(w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ins[0].Value = 30000
+	ins[1].Value = 40000
+
+	// The first and the last key sign, in the wrong order
+	sigs1, err := w.CreateMultisigSignature(ins, outs, &keys[2], redeemScript, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sigs2, err := w.CreateMultisigSignature(ins, outs, &keys[0], redeemScript, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, sig := range append(sigs1, sigs2...) {
+		if len(sig.Signature) != SchnorrSignatureSize+1 {
+			t.Fatal("Returned a non Schnorr signature")
+		}
+	}
+	txBytes, err := w.Multisign(ins, outs, sigs1, sigs2, redeemScript, 5, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tx := wire.NewMsgTx(0)
+	if err := tx.BtcDecode(bytes.NewReader(txBytes), wire.ProtocolVersion, wire.BaseEncoding); err != nil {
+		t.Fatal(err)
+	}
+	if len(tx.TxIn) != 2 {
+		t.Fatal("Transactions has incorrect number of inputs")
+	}
+	for i, in := range tx.TxIn {
+		// The dummy element flags the first and last key
+		if in.SignatureScript[0] != 0x55 {
+			t.Errorf("Input %d has incorrect checkbits %x", i, in.SignatureScript[0])
+		}
+		pushes, err := txscript.PushedData(in.SignatureScript)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(pushes) != 3 || !bytes.Equal(pushes[2], redeemScript) {
+			t.Fatalf("Input %d has an incorrect script", i)
+		}
+		var keySig, lastKeySig []byte
+		for _, sig := range sigs2 {
+			if int(sig.InputIndex) == i {
+				keySig = sig.Signature
+			}
+		}
+		for _, sig := range sigs1 {
+			if int(sig.InputIndex) == i {
+				lastKeySig = sig.Signature
+			}
+		}
+		if !bytes.Equal(pushes[0], keySig) || !bytes.Equal(pushes[1], lastKeySig) {
+			t.Errorf("Input %d signatures are not in key order", i)
+		}
+	}
+
+	// Signatures by keys outside of the redeem script are rejected
+	key, err := w.km.GetFreshKey(wallet.INTERNAL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sigs3, err := w.CreateMultisigSignature(ins, outs, key, redeemScript, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Multisign(ins, outs, sigs1, sigs3, redeemScript, 5, false); err == nil {
+		t.Error("Multisigned with a signature of an unknown key")
+	}
+}
+
+func TestBitcoinCashWallet_MultisignWithSignatures(t *testing.T) {
+	w, err := newMockWallet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.sigScheme = Schnorr
+
+	var keys []hdkeychain.ExtendedKey
+	for i := 0; i < 5; i++ {
+		key, err := w.km.GetFreshKey(wallet.INTERNAL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, *key)
+	}
+	_, redeemScript, err := w.generateMultisigScript(keys, 3, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ins, outs, _, err := buildTxData(w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ins[0].Value = 300000
+	ins[1].Value = 400000
+
+	// Three of the five cosigners sign, out of key order
+	var partials [][]byte
+	for _, i := range []int{4, 0, 2} {
+		keySigs, err := w.CreateMultisigSignature(ins, outs, &keys[i], redeemScript, 5)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pubKey, err := keys[i].ECPubKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		cosigner := make(multisig.Signatures)
+		cosigner.Add(pubKey.SerializeCompressed(), keySigs)
+		partial, complete, err := w.MultisignWithSignatures(ins, outs, cosigner, redeemScript, 5, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if complete {
+			t.Error("Transaction with a single signature reported as complete")
+		}
+		partials = append(partials, partial)
+	}
+	txBytes, complete, err := w.MergeMultisig(redeemScript, partials, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !complete {
+		t.Fatal("Transaction with three signatures reported as partial")
+	}
+
+	tx := wire.NewMsgTx(0)
+	if err := tx.BtcDecode(bytes.NewReader(txBytes), wire.ProtocolVersion, wire.BaseEncoding); err != nil {
+		t.Fatal(err)
+	}
+	inVals := map[wire.OutPoint]int64{}
+	for _, in := range ins {
+		ch, _ := chainhash.NewHashFromStr(hex.EncodeToString(in.OutpointHash))
+		inVals[*wire.NewOutPoint(ch, in.OutpointIndex)] = in.Value
+	}
+	for i, in := range tx.TxIn {
+		stack, err := multisig.ScriptStack(in.SignatureScript)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(stack) != 5 || !bytes.Equal(stack[4], redeemScript) {
+			t.Fatalf("Input %d has an incorrect script", i)
+		}
+		// The dummy element flags the first, third and fifth key
+		if !bytes.Equal(stack[0], []byte{0x15}) {
+			t.Errorf("Input %d has incorrect checkbits %x", i, stack[0])
+		}
+		for j, k := range []int{0, 2, 4} {
+			pubKey, err := keys[k].ECPubKey()
+			if err != nil {
+				t.Fatal(err)
+			}
+			verifySchnorrMultisigSignature(t, tx, i, redeemScript, inVals[in.PreviousOutPoint], pubKey, stack[1+j])
+		}
+	}
+}
+
+func TestBitcoinCashWallet_ReleaseAfterTimeout(t *testing.T) {
+	w, err := newMockWallet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.ws.Start()
+	waitForTxnSync(t, w.db.Txns())
+	time.Sleep(time.Second / 2)
+
+	var keys []hdkeychain.ExtendedKey
+	for i := 0; i < 4; i++ {
+		key, err := w.km.GetFreshKey(wallet.INTERNAL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, *key)
+	}
+	timeoutKey := &keys[3]
+	addr, redeemScript, err := w.generateMultisigScript(keys[:3], 2, time.Hour, timeoutKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkScript, err := w.AddressToScript(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tip, _ := w.ChainTip()
+	op := wire.NewOutPoint(&chainhash.Hash{0x0e}, 1)
+	utxo := wallet.Utxo{Op: *op, Value: 100000, ScriptPubkey: pkScript, AtHeight: int32(tip) - 4, WatchOnly: true}
+	if err := w.db.Utxos().Put(utxo); err != nil {
+		t.Fatal(err)
+	}
+	h, _ := hex.DecodeString(op.Hash.String())
+	ins := []wallet.TransactionInput{{OutpointHash: h, OutpointIndex: op.Index, Value: utxo.Value, LinkedAddress: addr}}
+
+	if _, err := w.ReleaseAfterTimeout(ins, nil, timeoutKey, redeemScript, wallet.NORMAL); err != multisig.ErrTimeoutNotMatured {
+		t.Errorf("Expected ErrTimeoutNotMatured but had %v", err)
+	}
+	utxo.AtHeight--
+	if err := w.db.Utxos().Put(utxo); err != nil {
+		t.Fatal(err)
+	}
+	txid, err := w.ReleaseAfterTimeout(ins, nil, timeoutKey, redeemScript, wallet.NORMAL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txn, err := w.db.Txns().Get(*txid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx := wire.NewMsgTx(1)
+	if err := tx.BtcDecode(bytes.NewReader(txn.Bytes), wire.ProtocolVersion, wire.BaseEncoding); err != nil {
+		t.Fatal(err)
+	}
+
+	bchTx := bchwire.NewMsgTx(tx.Version)
+	for _, in := range tx.TxIn {
+		hash := bchhash.Hash(in.PreviousOutPoint.Hash)
+		newIn := bchwire.NewTxIn(bchwire.NewOutPoint(&hash, in.PreviousOutPoint.Index), in.SignatureScript)
+		newIn.Sequence = in.Sequence
+		bchTx.AddTxIn(newIn)
+	}
+	for _, out := range tx.TxOut {
+		bchTx.AddTxOut(bchwire.NewTxOut(out.Value, out.PkScript))
+	}
+	vm, err := txscript.NewEngine(pkScript, bchTx, 0, txscript.StandardVerifyFlags, nil, nil, utxo.Value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := vm.Execute(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestBitcoinCashWallet_HTLC(t *testing.T) {
+	w, err := newMockWallet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.ws.Start()
+	waitForTxnSync(t, w.db.Txns())
+	time.Sleep(time.Second / 2)
+
+	recipientKey, err := w.km.GetFreshKey(wallet.INTERNAL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	refundKey, err := w.km.GetFreshKey(wallet.INTERNAL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recipientPub, err := recipientKey.ECPubKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	refundPub, err := refundKey.ECPubKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret, secretHash, err := htlc.NewSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	verify := func(lockTime uint32, spend func(ins []wallet.TransactionInput, contract []byte) (*chainhash.Hash, error)) {
+		addr, contract, err := w.GenerateHTLCScript(secretHash, recipientPub.SerializeCompressed(), refundPub.SerializeCompressed(), lockTime)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pkScript, err := w.AddressToScript(addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		h, _ := hex.DecodeString(chainhash.Hash{0x0f}.String())
+		ins := []wallet.TransactionInput{{OutpointHash: h, OutpointIndex: lockTime, Value: 100000, LinkedAddress: addr}}
+		txid, err := spend(ins, contract)
+		if err != nil {
+			t.Fatal(err)
+		}
+		txn, err := w.db.Txns().Get(*txid)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tx := wire.NewMsgTx(1)
+		if err := tx.BtcDecode(bytes.NewReader(txn.Bytes), wire.ProtocolVersion, wire.BaseEncoding); err != nil {
+			t.Fatal(err)
+		}
+
+		bchTx := bchwire.NewMsgTx(tx.Version)
+		bchTx.LockTime = tx.LockTime
+		for _, in := range tx.TxIn {
+			hash := bchhash.Hash(in.PreviousOutPoint.Hash)
+			newIn := bchwire.NewTxIn(bchwire.NewOutPoint(&hash, in.PreviousOutPoint.Index), in.SignatureScript)
+			newIn.Sequence = in.Sequence
+			bchTx.AddTxIn(newIn)
+		}
+		for _, out := range tx.TxOut {
+			bchTx.AddTxOut(bchwire.NewTxOut(out.Value, out.PkScript))
+		}
+		vm, err := txscript.NewEngine(pkScript, bchTx, 0, txscript.StandardVerifyFlags, nil, nil, 100000)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := vm.Execute(); err != nil {
+			t.Error(err)
+		}
+	}
+
+	tip, _ := w.ChainTip()
+	verify(tip+10, func(ins []wallet.TransactionInput, contract []byte) (*chainhash.Hash, error) {
+		return w.RedeemHTLC(ins, nil, recipientKey, contract, secret, wallet.NORMAL)
+	})
+	verify(tip, func(ins []wallet.TransactionInput, contract []byte) (*chainhash.Hash, error) {
+		return w.RefundHTLC(ins, nil, refundKey, contract, wallet.NORMAL)
+	})
+
+	if _, _, err := w.GenerateHTLCScript(secretHash, recipientPub.SerializeCompressed(), refundPub.SerializeCompressed(), 0); err == nil {
+		t.Error("Generated a contract without a lock time")
+	}
+	_, contract, err := w.GenerateHTLCScript(secretHash, recipientPub.SerializeCompressed(), refundPub.SerializeCompressed(), tip+1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.RefundHTLC(nil, nil, refundKey, contract, wallet.NORMAL); err != htlc.ErrLockTimeNotReached {
+		t.Errorf("Expected ErrLockTimeNotReached but had %v", err)
+	}
+}
+
 func TestBitcoinCashWallet_bumpFee(t *testing.T) {
 	w, err := newMockWallet()
 	w.ws.Start()
@@ -8,6 +8,9 @@ package bitcoincash
 
 import (
 	"github.com/btcsuite/btcd/wire"
+
+	"github.com/muecoin/multiwallet/htlc"
+	"github.com/muecoin/multiwallet/multisig"
 )
 
 // Worst case script and input/output size estimates.
@@ -196,6 +199,126 @@ const (
 	P2PKHOutputSize = 8 + 1 + P2PKHPkScriptSize
 )
 
+// Script and input sizes of inputs signed with Schnorr signatures. A Schnorr
+// signature is always 64 bytes plus the sighash byte. In a Schnorr multisig
+// the dummy element is a bitfield of the signing keys which for up to 16 keys
+// is pushed with a single opcode.
+const (
+	// RedeemP2PKHSchnorrSigScriptSize is the serialize size of a transaction
+	// input script that redeems a compressed P2PKH output. It is calculated as:
+	//
+	//   - OP_DATA_65
+	//   - 64 bytes Schnorr signature + 1 byte sighash
+	//   - OP_DATA_33
+	//   - 33 bytes serialized compressed pubkey
+	RedeemP2PKHSchnorrSigScriptSize = 1 + 65 + 1 + 33
+
+	// RedeemP2SH2of3MultisigSchnorrSigScriptSize is the serialize size of a
+	// transaction input script that redeems a 2 of 3 P2SH multisig output with
+	// compressed keys. It is calculated as:
+	//
+	//   - OP_N bitfield of the signing keys
+	//   - OP_DATA_65
+	//   - 65 bytes Schnorr signature
+	//   - OP_DATA_65
+	//   - 65 bytes Schnorr signature
+	//   - OP_PUSHDATA1
+	//   - 1 byte script length
+	//   - OP_2
+	//   - 3 times OP_DATA_33 and 33 bytes serialized compressed pubkey
+	//   - OP_3
+	//   - OP_CHECKMULTISIG
+	RedeemP2SH2of3MultisigSchnorrSigScriptSize = 1 + 1 + 65 + 1 + 65 + 1 + 1 + 1 + 3*(1+33) + 1 + 1
+
+	// RedeemP2SH1of2MultisigSchnorrSigScriptSize is the serialize size of a
+	// transaction input script that redeems a 1 of 2 P2SH multisig output with
+	// compressed keys. It is calculated as:
+	//
+	//   - OP_N bitfield of the signing key
+	//   - OP_DATA_65
+	//   - 65 bytes Schnorr signature
+	//   - OP_DATA_71
+	//   - OP_1
+	//   - 2 times OP_DATA_33 and 33 bytes serialized compressed pubkey
+	//   - OP_2
+	//   - OP_CHECKMULTISIG
+	RedeemP2SH1of2MultisigSchnorrSigScriptSize = 1 + 1 + 65 + 1 + 1 + 2*(1+33) + 1 + 1
+
+	// RedeemP2SHMultisigTimelock1SchnorrSigScriptSize is the serialize size of
+	// a transaction input script that redeems a compressed P2SH timelocked
+	// multisig using the timeout. It is calculated as:
+	//
+	//   - OP_DATA_65
+	//   - 65 bytes Schnorr signature
+	//   - OP_0
+	//   - OP_PUSHDATA1
+	//   - 1 byte script length
+	//   - OP_IF
+	//   - OP_2
+	//   - 3 times OP_DATA_33 and 33 bytes serialized compressed pubkey
+	//   - OP_3
+	//   - OP_CHECKMULTISIG
+	//   - OP_ELSE
+	//   - OP_DATA_2
+	//   - 2 byte block height
+	//   - OP_CHECKSEQUENCEVERIFY
+	//   - OP_DROP
+	//   - OP_DATA_33
+	//   - 33 bytes serialized compressed pubkey
+	//   - OP_CHECKSIG
+	//   - OP_ENDIF
+	RedeemP2SHMultisigTimelock1SchnorrSigScriptSize = 1 + 65 + 1 + 1 + 1 + timelockRedeemScriptSize
+
+	// RedeemP2SHMultisigTimelock2SchnorrSigScriptSize is the serialize size of
+	// a transaction input script that redeems a compressed P2SH timelocked
+	// multisig without using the timeout. It is calculated as:
+	//
+	//   - OP_N bitfield of the signing keys
+	//   - OP_DATA_65
+	//   - 65 bytes Schnorr signature
+	//   - OP_DATA_65
+	//   - 65 bytes Schnorr signature
+	//   - OP_1
+	//   - OP_PUSHDATA1
+	//   - 1 byte script length
+	//   - the redeem script as above
+	RedeemP2SHMultisigTimelock2SchnorrSigScriptSize = 1 + 1 + 65 + 1 + 65 + 1 + 1 + 1 + timelockRedeemScriptSize
+
+	timelockRedeemScriptSize = 1 + 1 + 3*(1+33) + 1 + 1 + 1 + 1 + 2 + 1 + 1 + 1 + 33 + 1 + 1
+
+	// RedeemP2PKHSchnorrInputSize is the serialize size of a transaction input
+	// redeeming a compressed P2PKH output with a Schnorr signature. It is
+	// calculated as:
+	//
+	//   - 32 bytes previous tx
+	//   - 4 bytes output index
+	//   - 1 byte script len
+	//   - signature script
+	//   - 4 bytes sequence
+	RedeemP2PKHSchnorrInputSize = 32 + 4 + 1 + RedeemP2PKHSchnorrSigScriptSize + 4
+
+	// RedeemP2SH2of3MultisigSchnorrInputSize is the serialize size of a
+	// transaction input redeeming a compressed P2SH 2 of 3 multisig output with
+	// Schnorr signatures.
+	RedeemP2SH2of3MultisigSchnorrInputSize = 32 + 4 + 1 + RedeemP2SH2of3MultisigSchnorrSigScriptSize + 4
+
+	// RedeemP2SH1of2MultisigSchnorrInputSize is the serialize size of a
+	// transaction input redeeming a compressed P2SH 1 of 2 multisig output with
+	// a Schnorr signature.
+	RedeemP2SH1of2MultisigSchnorrInputSize = 32 + 4 + 1 + RedeemP2SH1of2MultisigSchnorrSigScriptSize + 4
+
+	// RedeemP2SHMultisigTimelock1SchnorrInputSize is the serialize size of a
+	// transaction input redeeming a compressed P2SH timelocked multisig output
+	// using the timeout with a Schnorr signature.
+	RedeemP2SHMultisigTimelock1SchnorrInputSize = 32 + 4 + 1 + RedeemP2SHMultisigTimelock1SchnorrSigScriptSize + 4
+
+	// RedeemP2SHMultisigTimelock2SchnorrInputSize is the serialize size of a
+	// transaction input redeeming a compressed P2SH timelocked multisig output
+	// without using the timeout with Schnorr signatures. The script is longer
+	// than 252 bytes so its length takes 3 bytes.
+	RedeemP2SHMultisigTimelock2SchnorrInputSize = 32 + 4 + 3 + RedeemP2SHMultisigTimelock2SchnorrSigScriptSize + 4
+)
+
 type InputType int
 
 const (
@@ -211,6 +334,12 @@ const (
 // and contains each transaction output from txOuts.  The estimated size is
 // incremented for an additional P2PKH change output if addChangeOutput is true.
 func EstimateSerializeSize(inputCount int, txOuts []*wire.TxOut, addChangeOutput bool, inputType InputType) int {
+	return EstimateSerializeSizeWithScheme(inputCount, txOuts, addChangeOutput, inputType, ECDSA)
+}
+
+// EstimateSerializeSizeWithScheme is EstimateSerializeSize for inputs signed
+// with the given signature scheme.
+func EstimateSerializeSizeWithScheme(inputCount int, txOuts []*wire.TxOut, addChangeOutput bool, inputType InputType, scheme SignatureScheme) int {
 	changeSize := 0
 	outputCount := len(txOuts)
 	if addChangeOutput {
@@ -219,17 +348,32 @@ func EstimateSerializeSize(inputCount int, txOuts []*wire.TxOut, addChangeOutput
 	}
 
 	var redeemScriptSize int
-	switch inputType {
-	case P2PKH:
-		redeemScriptSize = RedeemP2PKHInputSize
-	case P2SH_1of2_Multisig:
-		redeemScriptSize = RedeemP2SH1of2MultisigInputSize
-	case P2SH_2of3_Multisig:
-		redeemScriptSize = RedeemP2SH2of3MultisigInputSize
-	case P2SH_Multisig_Timelock_1Sig:
-		redeemScriptSize = RedeemP2SHMultisigTimelock1InputSize
-	case P2SH_Multisig_Timelock_2Sigs:
-		redeemScriptSize = RedeemP2SHMultisigTimelock2InputSize
+	if scheme == Schnorr {
+		switch inputType {
+		case P2PKH:
+			redeemScriptSize = RedeemP2PKHSchnorrInputSize
+		case P2SH_1of2_Multisig:
+			redeemScriptSize = RedeemP2SH1of2MultisigSchnorrInputSize
+		case P2SH_2of3_Multisig:
+			redeemScriptSize = RedeemP2SH2of3MultisigSchnorrInputSize
+		case P2SH_Multisig_Timelock_1Sig:
+			redeemScriptSize = RedeemP2SHMultisigTimelock1SchnorrInputSize
+		case P2SH_Multisig_Timelock_2Sigs:
+			redeemScriptSize = RedeemP2SHMultisigTimelock2SchnorrInputSize
+		}
+	} else {
+		switch inputType {
+		case P2PKH:
+			redeemScriptSize = RedeemP2PKHInputSize
+		case P2SH_1of2_Multisig:
+			redeemScriptSize = RedeemP2SH1of2MultisigInputSize
+		case P2SH_2of3_Multisig:
+			redeemScriptSize = RedeemP2SH2of3MultisigInputSize
+		case P2SH_Multisig_Timelock_1Sig:
+			redeemScriptSize = RedeemP2SHMultisigTimelock1InputSize
+		case P2SH_Multisig_Timelock_2Sigs:
+			redeemScriptSize = RedeemP2SHMultisigTimelock2InputSize
+		}
 	}
 
 	// 10 additional bytes are for version, locktime, and segwit flags
@@ -240,6 +384,92 @@ func EstimateSerializeSize(inputCount int, txOuts []*wire.TxOut, addChangeOutput
 		changeSize
 }
 
+// RedeemMultisigInputSize returns the worst case serialize size of a
+// transaction input redeeming an m of n P2SH multisig output through the
+// multisig branch with signatures of the given scheme.
+func RedeemMultisigInputSize(m, n int, timelocked bool, scheme SignatureScheme) int {
+	sigScriptSize := multisig.SigScriptSize(m, n, timelocked, multisig.ECDSASignatureSize)
+	if scheme == Schnorr {
+		// The dummy element becomes a bitfield of the signing keys
+		sigScriptSize = multisig.SigScriptSize(m, n, timelocked, SchnorrSignatureSize+1) + (n+7)/8
+	}
+	return 32 + 4 + wire.VarIntSerializeSize(uint64(sigScriptSize)) + sigScriptSize + 4
+}
+
+// EstimateMultisigSerializeSize returns a worst case serialize size estimate
+// for a transaction spending inputCount outputs of the multisig redeem script
+// to txOuts. Multisigs of up to 2 of 3 keys use the 2 of 3 estimate they have
+// always been signed with, so cosigners running older versions subtract the
+// same fee.
+func EstimateMultisigSerializeSize(inputCount int, txOuts []*wire.TxOut, rs *multisig.RedeemScript) int {
+	return EstimateMultisigSerializeSizeWithScheme(inputCount, txOuts, rs, ECDSA)
+}
+
+// EstimateMultisigSerializeSizeWithScheme is EstimateMultisigSerializeSize
+// for inputs signed with the given signature scheme.
+func EstimateMultisigSerializeSizeWithScheme(inputCount int, txOuts []*wire.TxOut, rs *multisig.RedeemScript, scheme SignatureScheme) int {
+	if rs.Threshold <= 2 && len(rs.PubKeys) <= 3 {
+		inputType := P2SH_2of3_Multisig
+		if rs.Timelocked {
+			inputType = P2SH_Multisig_Timelock_2Sigs
+		}
+		return EstimateSerializeSizeWithScheme(inputCount, txOuts, false, inputType, scheme)
+	}
+
+	// 10 additional bytes are for version, locktime, and segwit flags
+	return 10 + wire.VarIntSerializeSize(uint64(inputCount)) +
+		wire.VarIntSerializeSize(uint64(len(txOuts))) +
+		inputCount*RedeemMultisigInputSize(rs.Threshold, len(rs.PubKeys), rs.Timelocked, scheme) +
+		SumOutputSerializeSizes(txOuts)
+}
+
+// RedeemTimeoutInputSize returns the worst case serialize size of a
+// transaction input spending the timeout branch of an m of n P2SH escrow
+// with a signature of the given scheme.
+func RedeemTimeoutInputSize(m, n int, scheme SignatureScheme) int {
+	sigSize := multisig.ECDSASignatureSize
+	if scheme == Schnorr {
+		sigSize = SchnorrSignatureSize + 1
+	}
+	sigScriptSize := multisig.TimeoutSigScriptSize(m, n, sigSize)
+	return 32 + 4 + wire.VarIntSerializeSize(uint64(sigScriptSize)) + sigScriptSize + 4
+}
+
+// EstimateTimeoutSerializeSize returns a worst case serialize size estimate
+// for a transaction spending inputCount outputs of the escrow redeem script
+// through its timeout branch to txOuts.
+func EstimateTimeoutSerializeSize(inputCount int, txOuts []*wire.TxOut, rs *multisig.RedeemScript, scheme SignatureScheme) int {
+	return 10 + wire.VarIntSerializeSize(uint64(inputCount)) +
+		wire.VarIntSerializeSize(uint64(len(txOuts))) +
+		inputCount*RedeemTimeoutInputSize(rs.Threshold, len(rs.PubKeys), scheme) +
+		SumOutputSerializeSizes(txOuts)
+}
+
+// RedeemHTLCInputSize returns the worst case serialize size of a transaction
+// input spending a P2SH hash time locked contract with a signature of the
+// given scheme, either redeeming it with the secret or refunding it.
+func RedeemHTLCInputSize(redeem bool, scheme SignatureScheme) int {
+	sigSize := multisig.ECDSASignatureSize
+	if scheme == Schnorr {
+		sigSize = SchnorrSignatureSize + 1
+	}
+	sigScriptSize := htlc.RefundSigScriptSize(sigSize)
+	if redeem {
+		sigScriptSize = htlc.RedeemSigScriptSize(sigSize)
+	}
+	return 32 + 4 + wire.VarIntSerializeSize(uint64(sigScriptSize)) + sigScriptSize + 4
+}
+
+// EstimateHTLCSerializeSize returns a worst case serialize size estimate for
+// a transaction spending inputCount outputs of a hash time locked contract to
+// txOuts.
+func EstimateHTLCSerializeSize(inputCount int, txOuts []*wire.TxOut, redeem bool, scheme SignatureScheme) int {
+	return 10 + wire.VarIntSerializeSize(uint64(inputCount)) +
+		wire.VarIntSerializeSize(uint64(len(txOuts))) +
+		inputCount*RedeemHTLCInputSize(redeem, scheme) +
+		SumOutputSerializeSizes(txOuts)
+}
+
 // SumOutputSerializeSizes sums up the serialized size of the supplied outputs.
 func SumOutputSerializeSizes(outputs []*wire.TxOut) (serializeSize int) {
 	for _, txOut := range outputs {
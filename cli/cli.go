@@ -1,13 +1,21 @@
 package cli
 
 import (
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/muecoin/multiwallet/api"
 	"github.com/muecoin/multiwallet/api/pb"
+	"github.com/muecoin/multiwallet/registry"
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/timestamp"
 	"github.com/jessevdk/go-flags"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
@@ -51,6 +59,18 @@ func SetupCli(parser *flags.Parser) {
 		"print out the database tables",
 		"Prints each row in the database tables",
 		&dumpTables)
+	parser.AddCommand("export",
+		"export the transaction history",
+		"Prints the transactions of a coin, or of every coin if none is given, "+
+			"from the oldest with their fee, the running balance and their fiat value at the time\n\n"+
+			"Args:\n"+
+			"1. coinType      (string, optional)\n"+
+			"Options:\n"+
+			"--format         csv or json\n"+
+			"--currency       the fiat currency of the values, USD by default\n\n"+
+			"Examples:\n"+
+			"> multiwallet export bitcoin --format json\n",
+		&export)
 	parser.AddCommand("spend",
 		"send bitcoins",
 		"Send bitcoins to the given address\n\n"+
@@ -59,37 +79,201 @@ func SetupCli(parser *flags.Parser) {
 			"2. address       (string) The recipient's bitcoin address\n"+
 			"3. amount        (integer) The amount to send in satoshi"+
 			"4. feelevel      (string default=normal) The fee level: economic, normal, priority\n\n"+
-			"5. memo          (string) The orderID\n"+
+			"5. referenceID   (string) The orderID the spend pays for\n"+
+			"6. data          (hex string) Up to 80 bytes to attach to the transaction in an OP_RETURN output\n"+
+			"Options:\n"+
+			"--memo           (string) A memo to save with the transaction\n"+
+			"--label          (string) A label to save with the transaction, may be repeated\n"+
+			"--request-id     (string) Spend only once for this ID, repeating it returns the first txid\n\n"+
 			"Examples:\n"+
 			"> multiwallet spend bitcoin 1DxGWC22a46VPEjq8YKoeVXSLzB7BA8sJS 1000000\n"+
 			"82bfd45f3564e0b5166ab9ca072200a237f78499576e9658b20b0ccd10ff325c 1a3w"+
 			"> multiwallet spend bitcoin 1DxGWC22a46VPEjq8YKoeVXSLzB7BA8sJS 3000000000 priority\n"+
-			"82bfd45f3564e0b5166ab9ca072200a237f78499576e9658b20b0ccd10ff325c 4wq2",
+			"82bfd45f3564e0b5166ab9ca072200a237f78499576e9658b20b0ccd10ff325c 4wq2\n"+
+			"> multiwallet spend bitcoin 1DxGWC22a46VPEjq8YKoeVXSLzB7BA8sJS 1000000 normal 1a3w 6f7264657220316133770a\n"+
+			"3c4b1a8f2d9e7c6b5a4f3e2d1c0b9a8f7e6d5c4b3a2f1e0d9c8b7a6f5e4d3c2b",
 		&spend)
+	parser.AddCommand("transactions",
+		"list the wallet's transactions",
+		"Returns the transactions of the specified coin with their reference ID, memo and labels, "+
+			"most recent first. When a limit is given the cursor of the next page is printed last.\n\n"+
+			"Args:\n"+
+			"1. coinType      (string)\n"+
+			"2. label         (string) Only return the transactions with this label\n\n"+
+			"Options:\n"+
+			"--from           (RFC 3339 time) Only return transactions since this time\n"+
+			"--to             (RFC 3339 time) Only return transactions until this time\n"+
+			"--min-height     (integer) Only return transactions confirmed at or above this height\n"+
+			"--max-height     (integer) Only return transactions confirmed at or below this height\n"+
+			"--direction      (string) Only return incoming, outgoing or self transactions\n"+
+			"--address        (string) Only return transactions spending from or paying this address\n"+
+			"--watch-only     Include transactions of watched scripts\n"+
+			"--min-value      (integer) Only return transactions moving at least this many satoshi\n"+
+			"--limit          (integer) The number of transactions per page\n"+
+			"--cursor         (string) The cursor of the page to return\n\n"+
+			"Examples:\n"+
+			"> multiwallet transactions bitcoin payouts\n"+
+			"> multiwallet transactions bitcoin --direction outgoing --from 2026-01-01T00:00:00Z --limit 50\n",
+		&transactions)
+	parser.AddCommand("txbyreference",
+		"get the transaction of a reference ID",
+		"Returns the most recent transaction spent with the given reference ID\n\n"+
+			"Args:\n"+
+			"1. coinType      (string)\n"+
+			"2. referenceID   (string) The orderID the spend paid for\n\n"+
+			"Examples:\n"+
+			"> multiwallet txbyreference bitcoin 1a3w\n",
+		&txByReference)
+	parser.AddCommand("tx",
+		"get the details of a transaction",
+		"Returns a transaction of the wallet with its inputs, its outputs flagged as "+
+			"wallet, change or external, its fee and size, confirmations and RBF signaling\n\n"+
+			"Args:\n"+
+			"1. coinType      (string)\n"+
+			"2. txid          (string)\n\n"+
+			"Examples:\n"+
+			"> multiwallet tx bitcoin 82bfd45f3564e0b5166ab9ca072200a237f78499576e9658b20b0ccd10ff325c\n",
+		&txDetails)
+	parser.AddCommand("abandonspend",
+		"abandon a spend request",
+		"Gives up the transaction of a spend request ID whose broadcast failed. The "+
+			"transaction is never broadcast and the request ID can no longer be spent with\n\n"+
+			"Args:\n"+
+			"1. coinType      (string)\n"+
+			"2. requestID     (string) The request ID of the spend\n\n"+
+			"Examples:\n"+
+			"> multiwallet abandonspend bitcoin payout-1\n",
+		&abandonSpend)
+	parser.AddCommand("setaddresslabel",
+		"label an address",
+		"Labels the incoming payments to an address of the wallet\n\n"+
+			"Args:\n"+
+			"1. coinType      (string)\n"+
+			"2. address       (string) The address to label\n"+
+			"3. label         (string) The label, an empty label removes it\n\n"+
+			"Examples:\n"+
+			"> multiwallet setaddresslabel bitcoin 1DxGWC22a46VPEjq8YKoeVXSLzB7BA8sJS donations\n",
+		&setAddressLabel)
+	parser.AddCommand("spendfiat",
+		"send an amount in a fiat currency",
+		"Send the value of a fiat amount at the current exchange rate to the given address "+
+			"and print the txid, the amount sent and the rate used\n\n"+
+			"Args:\n"+
+			"1. coinType      (string)\n"+
+			"2. address       (string) The recipient's address\n"+
+			"3. amount        (decimal) The amount to send in the fiat currency\n"+
+			"4. currency      (string) The fiat currency code, for example USD\n\n"+
+			"Options:\n"+
+			"--feelevel       (string default=normal) The fee level: economic, normal, priority\n"+
+			"--max-rate-age   (integer) The maximum age of the rate in seconds\n"+
+			"--expected-rate  (decimal) The rate the amount was agreed at\n"+
+			"--max-slippage   (decimal) The largest fraction the rate may differ from the expected rate\n"+
+			"--memo           (string) A memo to save with the transaction\n"+
+			"--label          (string) A label to save with the transaction, may be repeated\n"+
+			"--request-id     (string) Spend only once for this ID, repeating it returns the first txid\n\n"+
+			"Examples:\n"+
+			"> multiwallet spendfiat bitcoin 1DxGWC22a46VPEjq8YKoeVXSLzB7BA8sJS 25.50 USD --max-rate-age 300\n"+
+			"82bfd45f3564e0b5166ab9ca072200a237f78499576e9658b20b0ccd10ff325c 51000 satoshi at 50000 USD",
+		&spendFiat)
+	parser.AddCommand("spendbatch",
+		"send to several addresses in one transaction",
+		"Send each amount to its address in a single transaction\n\n"+
+			"Args:\n"+
+			"1. coinType      (string)\n"+
+			"2. address       (string) The first recipient's address\n"+
+			"3. amount        (integer) The amount to send to the first recipient in satoshi\n"+
+			"...              Further address and amount pairs\n\n"+
+			"Options:\n"+
+			"--feelevel       (string default=normal) The fee level: economic, normal, priority\n"+
+			"--reference      (string) The orderID the spend pays for\n"+
+			"--request-id     (string) Spend only once for this ID, repeating it returns the first txid\n"+
+			"--memo           (string) A memo to save with the transaction\n"+
+			"--label          (string) A label to save with the transaction, may be repeated\n\n"+
+			"Examples:\n"+
+			"> multiwallet spendbatch bitcoin 1DxGWC22a46VPEjq8YKoeVXSLzB7BA8sJS 1000000 18zAxgfKx4NuTUGUEuB8p7FKgCYPM15DfS 250000 --request-id payout-42\n"+
+			"82bfd45f3564e0b5166ab9ca072200a237f78499576e9658b20b0ccd10ff325c\n",
+		&spendBatch)
+	parser.AddCommand("planspend",
+		"preview a spend",
+		"Returns the transaction a spend would make without signing or broadcasting it. "+
+			"The plan lists the inputs, the outputs including change, the fee and the size. "+
+			"Save it to a file to send exactly this transaction with executeplan.\n\n"+
+			"Args:\n"+
+			"1. coinType      (string)\n"+
+			"2. address       (string) The recipient's address\n"+
+			"3. amount        (integer) The amount to send in satoshi\n\n"+
+			"Options:\n"+
+			"--feelevel       (string default=normal) The fee level: economic, normal, priority\n"+
+			"--data           (hex string) Up to 80 bytes to attach to the transaction in an OP_RETURN output\n"+
+			"--spend-all      Send the whole balance, the amount is ignored\n\n"+
+			"Examples:\n"+
+			"> multiwallet planspend bitcoin 1DxGWC22a46VPEjq8YKoeVXSLzB7BA8sJS 1000000 > plan.json\n",
+		&planSpend)
+	parser.AddCommand("executeplan",
+		"send a previewed spend",
+		"Signs and broadcasts the transaction of a plan returned by planspend as is. "+
+			"It fails if the coins of the plan were spent since.\n\n"+
+			"Args:\n"+
+			"1. file          (string) The file holding the plan\n\n"+
+			"Options:\n"+
+			"--reference      (string) The orderID the spend pays for\n"+
+			"--request-id     (string) Spend only once for this ID, repeating it returns the first txid\n"+
+			"--memo           (string) A memo to save with the transaction\n"+
+			"--label          (string) A label to save with the transaction, may be repeated\n\n"+
+			"Examples:\n"+
+			"> multiwallet executeplan plan.json\n"+
+			"82bfd45f3564e0b5166ab9ca072200a237f78499576e9658b20b0ccd10ff325c\n",
+		&executePlan)
+	parser.AddCommand("broadcastrawtx",
+		"broadcast a raw transaction",
+		"Broadcasts a signed transaction built outside the wallet. "+
+			"The wallet saves it if it spends or pays our addresses.\n\n"+
+			"Args:\n"+
+			"1. coinType      (string)\n"+
+			"2. tx            (hex string) The serialized transaction\n\n"+
+			"Examples:\n"+
+			"> multiwallet broadcastrawtx bitcoin 0100000001...\n"+
+			"82bfd45f3564e0b5166ab9ca072200a237f78499576e9658b20b0ccd10ff325c\n",
+		&broadcastRawTx)
+	parser.AddCommand("decoderawtx",
+		"decode a raw transaction",
+		"Returns the inputs, outputs, size and RBF signaling of a serialized transaction. "+
+			"The fee and the addresses of the inputs are only returned for outputs the wallet knows.\n\n"+
+			"Args:\n"+
+			"1. coinType      (string)\n"+
+			"2. tx            (hex string) The serialized transaction\n\n"+
+			"Examples:\n"+
+			"> multiwallet decoderawtx zcash 050000800a27a726...\n",
+		&decodeRawTx)
 	parser.AddCommand("balance",
 		"get the wallet's balances",
 		"Returns the confirmed and unconfirmed balances for the specified coin",
 		&balance)
+	parser.AddCommand("portfolio",
+		"get the value of every coin",
+		"Returns the confirmed and unconfirmed balances of every coin and their value in a fiat currency, USD by default\n\n"+
+			"Args:\n"+
+			"1. currency      (string) The fiat currency code",
+		&portfolio)
+	parser.AddCommand("backendstatus",
+		"get the health of the wallet's servers",
+		"Returns the latency, error rate and block height of each API server used by the specified coin, best scoring first",
+		&backendStatus)
 }
 
-func coinType(args []string) pb.CoinType {
+// coinName returns the registry name of the coin named by the first argument,
+// which may also be its currency code, or Bitcoin if it names no coin.
+func coinName(args []string) string {
 	if len(args) == 0 {
-		return pb.CoinType_BITCOIN
-	}
-	switch strings.ToLower(args[0]) {
-	case "bitcoin":
-		return pb.CoinType_BITCOIN
-	case "bitcoincash":
-		return pb.CoinType_BITCOIN_CASH
-	case "zcash":
-		return pb.CoinType_ZCASH
-	case "litecoin":
-		return pb.CoinType_LITECOIN
-	case "ethereum":
-		return pb.CoinType_ETHEREUM
-	default:
-		return pb.CoinType_BITCOIN
+		return "Bitcoin"
 	}
+	if coin, ok := registry.ByName(args[0]); ok {
+		return coin.Name
+	}
+	if coin, ok := registry.ByCurrencyCode(args[0]); ok {
+		return coin.Name
+	}
+	return "Bitcoin"
 }
 
 func newGRPCClient() (pb.APIClient, *grpc.ClientConn, error) {
@@ -129,7 +313,7 @@ func (x *CurrentAddress) Execute(args []string) error {
 	var purpose pb.KeyPurpose
 	userSelection := ""
 
-	t := coinType(args)
+	t := coinName(args)
 	if len(args) == 1 {
 		userSelection = args[0]
 	} else if len(args) == 2 {
@@ -144,7 +328,7 @@ func (x *CurrentAddress) Execute(args []string) error {
 		purpose = pb.KeyPurpose_EXTERNAL
 	}
 
-	resp, err := client.CurrentAddress(context.Background(), &pb.KeySelection{Coin: t, Purpose: purpose})
+	resp, err := client.CurrentAddress(context.Background(), &pb.KeySelection{CoinName: t, Purpose: purpose})
 	if err != nil {
 		return err
 	}
@@ -165,7 +349,7 @@ func (x *NewAddress) Execute(args []string) error {
 	if len(args) == 0 {
 		return errors.New("Must select coin type")
 	}
-	t := coinType(args)
+	t := coinName(args)
 	var purpose pb.KeyPurpose
 	userSelection := ""
 	if len(args) == 1 {
@@ -181,7 +365,7 @@ func (x *NewAddress) Execute(args []string) error {
 	default:
 		purpose = pb.KeyPurpose_EXTERNAL
 	}
-	resp, err := client.NewAddress(context.Background(), &pb.KeySelection{Coin: t, Purpose: purpose})
+	resp, err := client.NewAddress(context.Background(), &pb.KeySelection{CoinName: t, Purpose: purpose})
 	if err != nil {
 		return err
 	}
@@ -202,8 +386,8 @@ func (x *ChainTip) Execute(args []string) error {
 	if len(args) == 0 {
 		return errors.New("Must select coin type")
 	}
-	t := coinType(args)
-	resp, err := client.ChainTip(context.Background(), &pb.CoinSelection{Coin: t})
+	t := coinName(args)
+	resp, err := client.ChainTip(context.Background(), &pb.CoinSelection{CoinName: t})
 	if err != nil {
 		return err
 	}
@@ -224,8 +408,8 @@ func (x *DumpTables) Execute(args []string) error {
 	if len(args) == 0 {
 		return errors.New("Must select coin type")
 	}
-	t := coinType(args)
-	resp, err := client.DumpTables(context.Background(), &pb.CoinSelection{Coin: t})
+	t := coinName(args)
+	resp, err := client.DumpTables(context.Background(), &pb.CoinSelection{CoinName: t})
 	if err != nil {
 		return err
 	}
@@ -239,7 +423,53 @@ func (x *DumpTables) Execute(args []string) error {
 	return nil
 }
 
-type Spend struct{}
+type Export struct {
+	Format   string `long:"format" default:"csv" description:"the export format, csv or json"`
+	Currency string `long:"currency" default:"USD" description:"the fiat currency of the values"`
+}
+
+var export Export
+
+func (x *Export) Execute(args []string) error {
+	client, conn, err := newGRPCClient()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	req := &pb.ExportRequest{
+		AllCoins: len(args) == 0,
+		CoinName: coinName(args),
+		Currency: x.Currency,
+	}
+	switch strings.ToLower(x.Format) {
+	case "csv":
+		req.Format = pb.ExportFormat_CSV
+	case "json":
+		req.Format = pb.ExportFormat_JSON
+	default:
+		return errors.New("Format must be csv or json")
+	}
+	resp, err := client.ExportTransactions(context.Background(), req)
+	if err != nil {
+		return err
+	}
+	for {
+		row, err := resp.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Println(row.Data)
+	}
+}
+
+type Spend struct {
+	Memo      string   `long:"memo" description:"a memo to save with the transaction"`
+	Labels    []string `long:"label" description:"a label to save with the transaction, may be repeated"`
+	RequestID string   `long:"request-id" description:"spend only once for this ID, repeating it returns the first txid"`
+}
 
 var spend Spend
 
@@ -249,6 +479,7 @@ func (x *Spend) Execute(args []string) error {
 		feeLevel      pb.FeeLevel
 		referenceID   string
 		userSelection string
+		data          []byte
 
 		client, conn, err = newGRPCClient()
 	)
@@ -260,46 +491,309 @@ func (x *Spend) Execute(args []string) error {
 	if len(args) == 0 {
 		return errors.New("Must select coin type")
 	}
-	if len(args) > 4 {
-		address = args[1]
+	if len(args) < 3 {
+		return errors.New("Address and amount are required")
+	}
+	address = args[1]
+	if len(args) > 3 {
 		userSelection = args[3]
+	}
+	if len(args) > 4 {
 		referenceID = args[4]
 	}
+	if len(args) > 5 {
+		data, err = hex.DecodeString(args[5])
+		if err != nil {
+			return err
+		}
+	}
+
+	feeLevel = parseFeeLevel(userSelection)
+
+	amt, err := strconv.Atoi(args[2])
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Spend(context.Background(), &pb.SpendInfo{
+		CoinName:    coinName(args),
+		Address:     address,
+		Amount:      uint64(amt),
+		FeeLevel:    feeLevel,
+		Memo:        x.Memo,
+		Data:        data,
+		ReferenceID: referenceID,
+		Labels:      x.Labels,
+		RequestID:   x.RequestID,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(resp.Hash)
+	return nil
+}
+
+type SpendFiat struct {
+	FeeLevel     string   `long:"feelevel" description:"the fee level: economic, normal, priority"`
+	MaxRateAge   uint32   `long:"max-rate-age" description:"the maximum age of the rate in seconds"`
+	ExpectedRate float64  `long:"expected-rate" description:"the rate the amount was agreed at"`
+	MaxSlippage  float64  `long:"max-slippage" description:"the largest fraction the rate may differ from the expected rate"`
+	Memo         string   `long:"memo" description:"a memo to save with the transaction"`
+	Labels       []string `long:"label" description:"a label to save with the transaction, may be repeated"`
+	RequestID    string   `long:"request-id" description:"spend only once for this ID, repeating it returns the first txid"`
+}
+
+var spendFiat SpendFiat
+
+func (x *SpendFiat) Execute(args []string) error {
+	client, conn, err := newGRPCClient()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if len(args) == 0 {
+		return errors.New("Must select coin type")
+	}
 	if len(args) < 4 {
-		return errors.New("Address and amount are required")
+		return errors.New("Address, amount and currency are required")
+	}
+	amount, err := strconv.ParseFloat(args[2], 64)
+	if err != nil {
+		return err
 	}
+	resp, err := client.SpendFiat(context.Background(), &pb.FiatSpendInfo{
+		CoinName:     coinName(args),
+		Address:      args[1],
+		Amount:       amount,
+		Currency:     args[3],
+		FeeLevel:     parseFeeLevel(x.FeeLevel),
+		Memo:         x.Memo,
+		Labels:       x.Labels,
+		RequestID:    x.RequestID,
+		MaxRateAge:   x.MaxRateAge,
+		ExpectedRate: x.ExpectedRate,
+		MaxSlippage:  x.MaxSlippage,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s %d satoshi at %v %s\n", resp.Hash, resp.Amount, resp.Rate, resp.Currency)
+	return nil
+}
 
-	switch strings.ToLower(userSelection) {
+func parseFeeLevel(level string) pb.FeeLevel {
+	switch strings.ToLower(level) {
 	case "economic":
-		feeLevel = pb.FeeLevel_ECONOMIC
-	case "normal":
-		feeLevel = pb.FeeLevel_NORMAL
+		return pb.FeeLevel_ECONOMIC
 	case "priority":
-		feeLevel = pb.FeeLevel_PRIORITY
+		return pb.FeeLevel_PRIORITY
 	default:
-		feeLevel = pb.FeeLevel_NORMAL
+		return pb.FeeLevel_NORMAL
 	}
+}
 
-	amt, err := strconv.Atoi(args[2])
+type SpendBatch struct {
+	FeeLevel    string   `long:"feelevel" description:"the fee level: economic, normal, priority"`
+	ReferenceID string   `long:"reference" description:"the orderID the spend pays for"`
+	RequestID   string   `long:"request-id" description:"spend only once for this ID, repeating it returns the first txid"`
+	Memo        string   `long:"memo" description:"a memo to save with the transaction"`
+	Labels      []string `long:"label" description:"a label to save with the transaction, may be repeated"`
+}
+
+var spendBatch SpendBatch
+
+func (x *SpendBatch) Execute(args []string) error {
+	client, conn, err := newGRPCClient()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if len(args) == 0 {
+		return errors.New("Must select coin type")
+	}
+	if len(args) < 3 || len(args)%2 != 1 {
+		return errors.New("An address and amount are required for each payment")
+	}
+	var payments []*pb.Payment
+	for i := 1; i < len(args); i += 2 {
+		amt, err := strconv.ParseUint(args[i+1], 10, 64)
+		if err != nil {
+			return err
+		}
+		payments = append(payments, &pb.Payment{Address: args[i], Amount: amt})
+	}
+	resp, err := client.SpendBatch(context.Background(), &pb.BatchSpendInfo{
+		CoinName:    coinName(args),
+		Payments:    payments,
+		FeeLevel:    parseFeeLevel(x.FeeLevel),
+		ReferenceID: x.ReferenceID,
+		RequestID:   x.RequestID,
+		Memo:        x.Memo,
+		Labels:      x.Labels,
+	})
 	if err != nil {
 		return err
 	}
+	fmt.Println(resp.Hash)
+	return nil
+}
 
-	resp, err := client.Spend(context.Background(), &pb.SpendInfo{
-		Coin:     coinType(args),
-		Address:  address,
-		Amount:   uint64(amt),
-		FeeLevel: feeLevel,
-		Memo:     referenceID,
+type PlanSpend struct {
+	FeeLevel string `long:"feelevel" description:"the fee level: economic, normal, priority"`
+	Data     string `long:"data" description:"hex data to attach in an OP_RETURN output"`
+	SpendAll bool   `long:"spend-all" description:"send the whole balance"`
+}
+
+var planSpend PlanSpend
+
+func (x *PlanSpend) Execute(args []string) error {
+	client, conn, err := newGRPCClient()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if len(args) == 0 {
+		return errors.New("Must select coin type")
+	}
+	if len(args) < 3 {
+		return errors.New("Address and amount are required")
+	}
+	amt, err := strconv.ParseUint(args[2], 10, 64)
+	if err != nil {
+		return err
+	}
+	data, err := hex.DecodeString(x.Data)
+	if err != nil {
+		return err
+	}
+	resp, err := client.PlanSpend(context.Background(), &pb.SpendInfo{
+		CoinName: coinName(args),
+		Address:  args[1],
+		Amount:   amt,
+		FeeLevel: parseFeeLevel(x.FeeLevel),
+		Data:     data,
+		SpendAll: x.SpendAll,
 	})
 	if err != nil {
 		return err
 	}
+	m := jsonpb.Marshaler{Indent: "    ", EmitDefaults: true}
+	out, err := m.MarshalToString(resp)
+	if err != nil {
+		return err
+	}
+	fmt.Println(out)
+	return nil
+}
 
+type ExecutePlan struct {
+	ReferenceID string   `long:"reference" description:"the orderID the spend pays for"`
+	RequestID   string   `long:"request-id" description:"spend only once for this ID, repeating it returns the first txid"`
+	Memo        string   `long:"memo" description:"a memo to save with the transaction"`
+	Labels      []string `long:"label" description:"a label to save with the transaction, may be repeated"`
+}
+
+var executePlan ExecutePlan
+
+func (x *ExecutePlan) Execute(args []string) error {
+	client, conn, err := newGRPCClient()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if len(args) == 0 {
+		return errors.New("Must give the file holding the plan")
+	}
+	b, err := ioutil.ReadFile(args[0])
+	if err != nil {
+		return err
+	}
+	plan := new(pb.SpendPlan)
+	if err := jsonpb.UnmarshalString(string(b), plan); err != nil {
+		return err
+	}
+	resp, err := client.ExecuteSpendPlan(context.Background(), &pb.ExecutePlanInfo{
+		Plan:        plan,
+		ReferenceID: x.ReferenceID,
+		RequestID:   x.RequestID,
+		Memo:        x.Memo,
+		Labels:      x.Labels,
+	})
+	if err != nil {
+		return err
+	}
 	fmt.Println(resp.Hash)
 	return nil
 }
 
+type BroadcastRawTx struct{}
+
+var broadcastRawTx BroadcastRawTx
+
+func (x *BroadcastRawTx) Execute(args []string) error {
+	client, conn, err := newGRPCClient()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if len(args) == 0 {
+		return errors.New("Must select coin type")
+	}
+	if len(args) < 2 {
+		return errors.New("Transaction is required")
+	}
+	tx, err := hex.DecodeString(args[1])
+	if err != nil {
+		return err
+	}
+	resp, err := client.BroadcastRawTx(context.Background(), &pb.RawTxInfo{
+		CoinName: coinName(args),
+		Tx:       tx,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Println(resp.Hash)
+	return nil
+}
+
+type DecodeRawTx struct{}
+
+var decodeRawTx DecodeRawTx
+
+func (x *DecodeRawTx) Execute(args []string) error {
+	client, conn, err := newGRPCClient()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if len(args) == 0 {
+		return errors.New("Must select coin type")
+	}
+	if len(args) < 2 {
+		return errors.New("Transaction is required")
+	}
+	tx, err := hex.DecodeString(args[1])
+	if err != nil {
+		return err
+	}
+	resp, err := client.DecodeRawTx(context.Background(), &pb.RawTxInfo{
+		CoinName: coinName(args),
+		Tx:       tx,
+	})
+	if err != nil {
+		return err
+	}
+	m := jsonpb.Marshaler{Indent: "    ", EmitDefaults: true}
+	out, err := m.MarshalToString(resp)
+	if err != nil {
+		return err
+	}
+	fmt.Println(out)
+	return nil
+}
+
 type Balance struct{}
 
 var balance Balance
@@ -313,11 +807,258 @@ func (x *Balance) Execute(args []string) error {
 	if len(args) == 0 {
 		return errors.New("Must select coin type")
 	}
-	t := coinType(args)
-	resp, err := client.Balance(context.Background(), &pb.CoinSelection{Coin: t})
+	t := coinName(args)
+	resp, err := client.Balance(context.Background(), &pb.CoinSelection{CoinName: t})
 	if err != nil {
 		return err
 	}
 	fmt.Printf("Confirmed: %d, Unconfirmed: %d\n", resp.Confirmed, resp.Unconfirmed)
 	return nil
 }
+
+type Portfolio struct{}
+
+var portfolio Portfolio
+
+func (x *Portfolio) Execute(args []string) error {
+	client, conn, err := newGRPCClient()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	currency := "USD"
+	if len(args) > 0 {
+		currency = args[0]
+	}
+	resp, err := client.Portfolio(context.Background(), &pb.PortfolioRequest{Currency: currency})
+	if err != nil {
+		return err
+	}
+	for _, h := range resp.Holdings {
+		if h.Error != "" {
+			fmt.Printf("%s: Confirmed: %d, Unconfirmed: %d, not valued: %s\n", h.CurrencyCode, h.Confirmed, h.Unconfirmed, h.Error)
+			continue
+		}
+		fmt.Printf("%s: Confirmed: %d (%.2f %s), Unconfirmed: %d (%.2f %s) at %v %s\n", h.CurrencyCode,
+			h.Confirmed, h.FiatConfirmed, resp.Currency, h.Unconfirmed, h.FiatUnconfirmed, resp.Currency, h.Rate, resp.Currency)
+	}
+	fmt.Printf("Total: Confirmed: %.2f %s, Unconfirmed: %.2f %s\n", resp.TotalConfirmed, resp.Currency, resp.TotalUnconfirmed, resp.Currency)
+	return nil
+}
+
+type BackendStatus struct{}
+
+var backendStatus BackendStatus
+
+func (x *BackendStatus) Execute(args []string) error {
+	client, conn, err := newGRPCClient()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if len(args) == 0 {
+		return errors.New("Must select coin type")
+	}
+	t := coinName(args)
+	resp, err := client.BackendStatus(context.Background(), &pb.CoinSelection{CoinName: t})
+	if err != nil {
+		return err
+	}
+	for _, b := range resp.Backends {
+		fmt.Printf("%s Current: %t, Healthy: %t, Latency: %dms, ErrorRate: %.2f, Height: %d, Behind: %d, Requests: %d\n",
+			b.Url, b.Current, b.Healthy, b.LatencyMs, b.ErrorRate, b.BlockHeight, b.BlocksBehind, b.Requests)
+	}
+	return nil
+}
+
+func printTx(tx *pb.Tx) {
+	fmt.Printf("%s Value: %d, Height: %d, ReferenceID: %s, Memo: %s, Labels: %s\n",
+		tx.Txid, tx.Value, tx.Height, tx.ReferenceID, tx.Memo, strings.Join(tx.Labels, ","))
+}
+
+type Transactions struct {
+	From      string `long:"from" description:"only return transactions since this RFC 3339 time"`
+	To        string `long:"to" description:"only return transactions until this RFC 3339 time"`
+	MinHeight int32  `long:"min-height" description:"only return transactions confirmed at or above this height"`
+	MaxHeight int32  `long:"max-height" description:"only return transactions confirmed at or below this height"`
+	Direction string `long:"direction" description:"only return incoming, outgoing or self transactions"`
+	Address   string `long:"address" description:"only return transactions spending from or paying this address"`
+	WatchOnly bool   `long:"watch-only" description:"include transactions of watched scripts"`
+	MinValue  uint64 `long:"min-value" description:"only return transactions moving at least this many satoshi"`
+	Limit     uint32 `long:"limit" description:"the number of transactions per page"`
+	Cursor    string `long:"cursor" description:"the cursor of the page to return"`
+}
+
+var transactions Transactions
+
+func (x *Transactions) Execute(args []string) error {
+	client, conn, err := newGRPCClient()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if len(args) == 0 {
+		return errors.New("Must select coin type")
+	}
+	filter := &pb.TransactionFilter{
+		CoinName:         coinName(args),
+		MinHeight:        x.MinHeight,
+		MaxHeight:        x.MaxHeight,
+		Address:          x.Address,
+		IncludeWatchOnly: x.WatchOnly,
+		MinValue:         x.MinValue,
+		Limit:            x.Limit,
+		Cursor:           x.Cursor,
+	}
+	if len(args) > 1 {
+		filter.Label = args[1]
+	}
+	switch strings.ToLower(x.Direction) {
+	case "":
+	case "incoming":
+		filter.Direction = pb.Direction_INCOMING
+	case "outgoing":
+		filter.Direction = pb.Direction_OUTGOING
+	case "self":
+		filter.Direction = pb.Direction_SELF
+	default:
+		return errors.New("Direction must be incoming, outgoing or self")
+	}
+	if filter.From, err = parseTimestamp(x.From); err != nil {
+		return err
+	}
+	if filter.To, err = parseTimestamp(x.To); err != nil {
+		return err
+	}
+	resp, err := client.Transactions(context.Background(), filter)
+	if err != nil {
+		return err
+	}
+	for _, tx := range resp.Transactions {
+		printTx(tx)
+	}
+	if resp.NextCursor != "" {
+		fmt.Printf("Next cursor: %s\n", resp.NextCursor)
+	}
+	return nil
+}
+
+// parseTimestamp parses an RFC 3339 time, returning nil for an empty string
+func parseTimestamp(s string) (*timestamp.Timestamp, error) {
+	if s == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return nil, err
+	}
+	return ptypes.TimestampProto(t)
+}
+
+type TxByReference struct{}
+
+var txByReference TxByReference
+
+func (x *TxByReference) Execute(args []string) error {
+	client, conn, err := newGRPCClient()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if len(args) < 2 {
+		return errors.New("Coin type and reference ID are required")
+	}
+	resp, err := client.GetTransactionByReference(context.Background(), &pb.Reference{CoinName: coinName(args), ReferenceID: args[1]})
+	if err != nil {
+		return err
+	}
+	printTx(resp)
+	return nil
+}
+
+type TxDetails struct{}
+
+var txDetails TxDetails
+
+func (x *TxDetails) Execute(args []string) error {
+	client, conn, err := newGRPCClient()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if len(args) < 2 {
+		return errors.New("Coin type and txid are required")
+	}
+	tx, err := client.GetTransaction(context.Background(), &pb.Txid{CoinName: coinName(args), Hash: args[1]})
+	if err != nil {
+		return err
+	}
+	printTx(tx)
+	fmt.Printf("Confirmations: %d, RBF: %t\n", tx.Confirmations, tx.Rbf)
+	if tx.ExpiryHeight > 0 {
+		fmt.Printf("Expiry height: %d\n", tx.ExpiryHeight)
+	}
+	if tx.FeeKnown {
+		fmt.Printf("Size: %d, VSize: %d, Fee: %d (%.2f per vbyte)\n", tx.Size, tx.Vsize, tx.Fee, tx.FeeRate)
+	} else {
+		fmt.Printf("Size: %d, VSize: %d, Fee: unknown\n", tx.Size, tx.Vsize)
+	}
+	fmt.Println("Inputs:")
+	for _, in := range tx.Inputs {
+		if in.Known {
+			fmt.Printf("  %s:%d %s %d\n", in.Txid, in.Index, in.Address, in.Value)
+		} else {
+			fmt.Printf("  %s:%d unknown\n", in.Txid, in.Index)
+		}
+	}
+	fmt.Println("Outputs:")
+	for _, out := range tx.Outputs {
+		switch {
+		case out.Address != "":
+			fmt.Printf("  %d %s %d %s\n", out.Index, out.Address, out.Value, strings.ToLower(strings.TrimSuffix(out.Owner.String(), "_OUTPUT")))
+		case out.Data != nil:
+			fmt.Printf("  %d data %x\n", out.Index, out.Data)
+		default:
+			fmt.Printf("  %d %x %d\n", out.Index, out.ScriptPubKey, out.Value)
+		}
+	}
+	return nil
+}
+
+type AbandonSpend struct{}
+
+var abandonSpend AbandonSpend
+
+func (x *AbandonSpend) Execute(args []string) error {
+	client, conn, err := newGRPCClient()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if len(args) < 2 {
+		return errors.New("Coin type and request ID are required")
+	}
+	_, err = client.AbandonSpendRequest(context.Background(), &pb.SpendRequest{CoinName: coinName(args), RequestID: args[1]})
+	return err
+}
+
+type SetAddressLabel struct{}
+
+var setAddressLabel SetAddressLabel
+
+func (x *SetAddressLabel) Execute(args []string) error {
+	client, conn, err := newGRPCClient()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if len(args) < 2 {
+		return errors.New("Coin type and address are required")
+	}
+	label := ""
+	if len(args) > 2 {
+		label = args[2]
+	}
+	_, err = client.SetAddressLabel(context.Background(), &pb.AddressLabel{CoinName: coinName(args), Address: args[1], Label: label})
+	return err
+}
@@ -0,0 +1,24 @@
+package cli
+
+import "testing"
+
+func TestCoinName(t *testing.T) {
+	tests := []struct {
+		args []string
+		name string
+	}{
+		{nil, "Bitcoin"},
+		{[]string{"dogecoin"}, "Dogecoin"},
+		{[]string{"Dogecoin", "internal"}, "Dogecoin"},
+		{[]string{"doge"}, "Dogecoin"},
+		{[]string{"tdoge"}, "Dogecoin"},
+		{[]string{"bitcoin-cash"}, "Bitcoin Cash"},
+		{[]string{"MUE"}, "MonetaryUnit"},
+		{[]string{"unknown"}, "Bitcoin"},
+	}
+	for _, test := range tests {
+		if name := coinName(test.args); name != test.name {
+			t.Errorf("%v: expected %s, got %s", test.args, test.name, name)
+		}
+	}
+}
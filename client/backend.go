@@ -0,0 +1,101 @@
+package client
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/muecoin/multiwallet/client/bitcoind"
+	"github.com/muecoin/multiwallet/client/blockbook"
+	"github.com/muecoin/multiwallet/client/electrum"
+	"github.com/muecoin/multiwallet/client/insight"
+	"github.com/muecoin/multiwallet/model"
+	"github.com/btcsuite/btcutil"
+	"golang.org/x/net/proxy"
+)
+
+// BackendType identifies the server API spoken by an endpoint in
+// CoinConfig.ClientAPIs.
+type BackendType string
+
+const (
+	BackendBlockbook BackendType = "blockbook"
+	BackendInsight   BackendType = "insight"
+	BackendElectrum  BackendType = "electrum"
+	BackendBitcoind  BackendType = "bitcoind"
+)
+
+// backendClient is the set of methods the ClientPool needs from every backend
+// it rotates between.
+type backendClient interface {
+	Start(closeChan chan<- error) error
+	Close()
+	String() string
+	EndpointURL() *url.URL
+	BlockChannel() chan model.Block
+	TxChannel() chan model.Transaction
+
+	GetInfo() (*model.Info, error)
+	GetTransaction(txid string) (*model.Transaction, error)
+	GetRawTransaction(txid string) ([]byte, error)
+	GetTransactions(addrs []btcutil.Address) ([]model.Transaction, error)
+	GetUtxos(addrs []btcutil.Address) ([]model.Utxo, error)
+	ListenAddress(addr btcutil.Address)
+	Broadcast(tx []byte) (string, error)
+	GetBestBlock() (*model.Block, error)
+	EstimateFee(nBlocks int) (int, error)
+}
+
+// ParseEndpoint splits a ClientAPIs entry into its backend type and the URL
+// of the server. The backend is declared with a scheme prefix such as
+// "insight+https://example.com/api", "electrum+ssl://example.com:50002" or
+// "bitcoind+http://127.0.0.1:8332".
+// Endpoints without a prefix are treated as Blockbook servers.
+func ParseEndpoint(endpoint string) (BackendType, string, error) {
+	var i = strings.Index(endpoint, "+")
+	if i < 0 || i > strings.Index(endpoint, "://") {
+		return BackendBlockbook, endpoint, nil
+	}
+	var backend = BackendType(strings.ToLower(endpoint[:i]))
+	switch backend {
+	case BackendBlockbook, BackendInsight, BackendElectrum, BackendBitcoind:
+		return backend, endpoint[i+1:], nil
+	}
+	return "", "", fmt.Errorf("unsupported backend type: %s", backend)
+}
+
+func newBackendClient(endpoint string, options map[string]interface{}, proxyDialer proxy.Dialer) (backendClient, error) {
+	backend, apiUrl, err := ParseEndpoint(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	switch backend {
+	case BackendInsight:
+		return insight.NewInsightClient(apiUrl, proxyDialer)
+	case BackendElectrum:
+		return electrum.NewElectrumClient(apiUrl, proxyDialer)
+	case BackendBitcoind:
+		// The RPC client dials the node directly, so with a proxy only a
+		// node on this machine is used, whose connections don't leave it
+		if proxyDialer != nil && !isLoopback(apiUrl) {
+			return nil, fmt.Errorf("bitcoind endpoint %s cannot be reached through the proxy, only a node on localhost may be used with one", apiUrl)
+		}
+		return bitcoind.NewBitcoindClient(apiUrl, options)
+	default:
+		return blockbook.NewBlockBookClient(apiUrl, proxyDialer)
+	}
+}
+
+// isLoopback returns whether the host of apiUrl is localhost or a loopback IP
+func isLoopback(apiUrl string) bool {
+	u, err := url.Parse(apiUrl)
+	if err != nil {
+		return false
+	}
+	if strings.EqualFold(u.Hostname(), "localhost") {
+		return true
+	}
+	ip := net.ParseIP(u.Hostname())
+	return ip != nil && ip.IsLoopback()
+}
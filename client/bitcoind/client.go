@@ -0,0 +1,884 @@
+package bitcoind
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	clientErr "github.com/muecoin/multiwallet/client/errors"
+	"github.com/muecoin/multiwallet/model"
+	"github.com/muecoin/multiwallet/registry"
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/rpcclient"
+	"github.com/btcsuite/btcutil"
+	"github.com/op/go-logging"
+)
+
+var Log = logging.MustGetLogger("bitcoind")
+
+const (
+	// importLabel is the wallet label given to every imported address
+	importLabel = "multiwallet"
+
+	// listPageSize is the number of wallet transactions requested at a time
+	listPageSize = 1000
+
+	defaultPollInterval = 10 * time.Second
+)
+
+// Option keys read from CoinConfig.Options
+const (
+	OptionRPCUser      = "RPCUser"
+	OptionRPCPassword  = "RPCPassword"
+	OptionPollInterval = "RPCPollInterval"
+	OptionRescan       = "RPCRescanOnImport"
+	OptionScanTxOutSet = "RPCScanTxOutSet"
+)
+
+// BitcoindClient uses the JSON-RPC interface of a Bitcoin Core compatible node
+// as a wallet backend. Addresses are imported into the node's wallet as
+// watch-only and tracked with listunspent and listtransactions. Alternatively
+// unspent outputs can be found with scantxoutset which does not need the
+// addresses to be imported, but does not see the mempool. New blocks and
+// transactions are detected by polling the node.
+//
+// Nodes which lack the newer RPCs, such as zcashd and the Bitcoin Cash nodes,
+// are served with the older ones instead: estimatefee for estimatesmartfee,
+// listunspent without the include_unsafe argument, and watch-only imports
+// rescanning the chain for scantxoutset. The client switches to them the first
+// time the node rejects a newer call.
+type BitcoindClient struct {
+	apiUrl          *url.URL
+	connCfg         *rpcclient.ConnConfig
+	blockNotifyChan chan model.Block
+	txNotifyChan    chan model.Transaction
+
+	rpcClient *rpcclient.Client
+	rpcLock   sync.Mutex
+
+	closeChan chan<- error
+	done      chan struct{}
+	started   bool
+	lock      sync.Mutex
+
+	watched   map[string]btcutil.Address
+	seenTxs   map[string]string
+	bestHash  string
+	tipHeight int
+	rescanned map[string]bool
+	stateLock sync.Mutex
+
+	// Newer RPCs the node turned out to lack
+	noSmartFee     bool
+	noUnsafeArg    bool
+	noScanTxOutSet bool
+
+	// PollInterval is how often the node is checked for new blocks and transactions
+	PollInterval time.Duration
+	// Rescan makes importaddress rescan the chain for past transactions
+	Rescan bool
+	// ScanTxOutSet finds unspent outputs with scantxoutset instead of listunspent
+	ScanTxOutSet bool
+	// AddrToScript builds the output scripts scantxoutset looks for. It
+	// defaults to the address codecs of the registered coins.
+	AddrToScript func(addr btcutil.Address) ([]byte, error)
+}
+
+// NewBitcoindClient returns a client for the node at apiUrl, for example
+// http://127.0.0.1:8332 or http://127.0.0.1:8332/wallet/watchonly to select a
+// wallet. The RPC credentials are read from options and fall back to the user
+// info of the URL.
+func NewBitcoindClient(apiUrl string, options map[string]interface{}) (*BitcoindClient, error) {
+	u, err := url.Parse(apiUrl)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateScheme(u); err != nil {
+		return nil, err
+	}
+
+	var user, pass string
+	if u.User != nil {
+		user = u.User.Username()
+		pass, _ = u.User.Password()
+	}
+	if v, ok := options[OptionRPCUser].(string); ok {
+		user = v
+	}
+	if v, ok := options[OptionRPCPassword].(string); ok {
+		pass = v
+	}
+	pollInterval, err := durationOption(options, OptionPollInterval, defaultPollInterval)
+	if err != nil {
+		return nil, err
+	}
+	rescan, _ := options[OptionRescan].(bool)
+	scanTxOutSet, _ := options[OptionScanTxOutSet].(bool)
+
+	host := u.Host
+	if u.Path != "" && u.Path != "/" {
+		host += u.Path
+	}
+	bc := &BitcoindClient{
+		apiUrl: &url.URL{Scheme: u.Scheme, Host: u.Host, Path: u.Path},
+		connCfg: &rpcclient.ConnConfig{
+			Host:         host,
+			User:         user,
+			Pass:         pass,
+			HTTPPostMode: true,
+			DisableTLS:   u.Scheme == "http",
+		},
+		blockNotifyChan: make(chan model.Block),
+		txNotifyChan:    make(chan model.Transaction),
+		done:            make(chan struct{}),
+		watched:         make(map[string]btcutil.Address),
+		seenTxs:         make(map[string]string),
+		rescanned:       make(map[string]bool),
+		PollInterval:    pollInterval,
+		Rescan:          rescan,
+		ScanTxOutSet:    scanTxOutSet,
+		AddrToScript:    registry.AddressToScript,
+	}
+	return bc, nil
+}
+
+func validateScheme(target *url.URL) error {
+	switch target.Scheme {
+	case "https", "http":
+		return nil
+	}
+	return fmt.Errorf("unsupported scheme: %s", target.Scheme)
+}
+
+// durationOption reads a duration given either as a number of seconds or as a
+// string understood by time.ParseDuration
+func durationOption(options map[string]interface{}, key string, def time.Duration) (time.Duration, error) {
+	switch v := options[key].(type) {
+	case nil:
+		return def, nil
+	case int:
+		return time.Duration(v) * time.Second, nil
+	case float64:
+		return time.Duration(v * float64(time.Second)), nil
+	case string:
+		if secs, err := strconv.Atoi(v); err == nil {
+			return time.Duration(secs) * time.Second, nil
+		}
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return 0, fmt.Errorf("invalid %s: %s", key, err.Error())
+		}
+		return d, nil
+	}
+	return 0, fmt.Errorf("invalid %s: %v", key, options[key])
+}
+
+func (i *BitcoindClient) String() string {
+	return i.apiUrl.Host
+}
+
+// EndpointURL returns the URL of the node without credentials
+func (i *BitcoindClient) EndpointURL() *url.URL {
+	var u = *i.apiUrl
+	return &u
+}
+
+func (i *BitcoindClient) BlockChannel() chan model.Block {
+	return i.blockNotifyChan
+}
+
+func (i *BitcoindClient) TxChannel() chan model.Transaction {
+	return i.txNotifyChan
+}
+
+func (i *BitcoindClient) BlockNotify() <-chan model.Block {
+	return i.blockNotifyChan
+}
+
+func (i *BitcoindClient) TransactionNotify() <-chan model.Transaction {
+	return i.txNotifyChan
+}
+
+// Start checks the node is reachable, records the current tip and wallet
+// transactions and begins polling for changes. The closeChan receives a
+// single value when the client is closed (nil) or the node stops responding
+// (non-nil).
+func (i *BitcoindClient) Start(closeChan chan<- error) error {
+	best, err := i.GetBestBlock()
+	if err != nil {
+		return err
+	}
+	i.stateLock.Lock()
+	i.bestHash = best.Hash
+	i.stateLock.Unlock()
+	if _, err := i.walletChanges(false); err != nil {
+		return err
+	}
+
+	i.lock.Lock()
+	i.closeChan = closeChan
+	i.done = make(chan struct{})
+	i.started = true
+	done := i.done
+	i.lock.Unlock()
+
+	go i.poll(done)
+	Log.Infof("connected to node %s", i.String())
+	return nil
+}
+
+func (i *BitcoindClient) Close() {
+	Log.Infof("closing client (%s)...", i.String())
+	i.lock.Lock()
+	closeChan := i.closeChan
+	if i.started {
+		close(i.done)
+	}
+	i.closeChan = nil
+	i.started = false
+	i.lock.Unlock()
+
+	i.rpcLock.Lock()
+	if i.rpcClient != nil {
+		i.rpcClient.Shutdown()
+		i.rpcClient = nil
+	}
+	i.rpcLock.Unlock()
+
+	if closeChan != nil {
+		closeChan <- nil
+	}
+}
+
+func (i *BitcoindClient) sendAndDiscardCloseChan(err error) {
+	i.lock.Lock()
+	closeChan := i.closeChan
+	i.closeChan = nil
+	i.lock.Unlock()
+	if closeChan != nil {
+		closeChan <- err
+	}
+}
+
+func (i *BitcoindClient) client() (*rpcclient.Client, error) {
+	i.rpcLock.Lock()
+	defer i.rpcLock.Unlock()
+	if i.rpcClient != nil {
+		return i.rpcClient, nil
+	}
+	c, err := rpcclient.New(i.connCfg, nil)
+	if err != nil {
+		return nil, err
+	}
+	i.rpcClient = c
+	return c, nil
+}
+
+// call makes a request and classifies failures for the ClientPool. Errors
+// reported by the node are returned as is while failing to reach it is fatal
+// so the pool rotates.
+func (i *BitcoindClient) call(method string, result interface{}, params ...interface{}) error {
+	c, err := i.client()
+	if err != nil {
+		return clientErr.MakeRetryable(clientErr.MakeFatal(err))
+	}
+	var rawParams []json.RawMessage
+	for _, p := range params {
+		b, err := json.Marshal(p)
+		if err != nil {
+			return err
+		}
+		rawParams = append(rawParams, b)
+	}
+	resp, err := c.RawRequest(method, rawParams)
+	if err != nil {
+		if _, ok := err.(*btcjson.RPCError); ok {
+			return err
+		}
+		return clientErr.MakeRetryable(clientErr.MakeFatal(fmt.Errorf("%s (%s): %s", method, i.String(), err.Error())))
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.Unmarshal(resp, result); err != nil {
+		return fmt.Errorf("decoding %s response: %s", method, err.Error())
+	}
+	return nil
+}
+
+// isMethodNotFound returns whether err is the node rejecting an RPC it does
+// not implement
+func isMethodNotFound(err error) bool {
+	rerr, ok := err.(*btcjson.RPCError)
+	return ok && rerr.Code == btcjson.ErrRPCMethodNotFound.Code
+}
+
+// isRPCError returns whether err was reported by the node, as opposed to
+// failing to reach it
+func isRPCError(err error) bool {
+	_, ok := err.(*btcjson.RPCError)
+	return ok
+}
+
+// lacks returns the flag recording that the node lacks an RPC
+func (i *BitcoindClient) lacks(flag *bool) bool {
+	i.stateLock.Lock()
+	defer i.stateLock.Unlock()
+	return *flag
+}
+
+func (i *BitcoindClient) setLacks(flag *bool, method string) {
+	i.stateLock.Lock()
+	defer i.stateLock.Unlock()
+	if !*flag {
+		Log.Infof("%s does not support %s, using the older RPCs instead", i.String(), method)
+	}
+	*flag = true
+}
+
+// poll checks for a new tip and for new or newly confirmed wallet
+// transactions until done is closed.
+func (i *BitcoindClient) poll(done chan struct{}) {
+	var t = time.NewTicker(i.PollInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			if err := i.pollOnce(done); err != nil {
+				if clientErr.IsFatal(err) {
+					Log.Warningf("node %s unavailable: %s", i.String(), err.Error())
+					i.sendAndDiscardCloseChan(err)
+					return
+				}
+				Log.Errorf("polling node %s: %s", i.String(), err.Error())
+			}
+		case <-done:
+			return
+		}
+	}
+}
+
+func (i *BitcoindClient) pollOnce(done chan struct{}) error {
+	var bestHash string
+	if err := i.call("getbestblockhash", &bestHash); err != nil {
+		return err
+	}
+	i.stateLock.Lock()
+	changed := bestHash != i.bestHash
+	i.bestHash = bestHash
+	i.stateLock.Unlock()
+	if changed {
+		block, err := i.getBlockHeader(bestHash)
+		if err != nil {
+			return err
+		}
+		select {
+		case i.blockNotifyChan <- *block:
+		case <-done:
+			return nil
+		}
+	}
+
+	txids, err := i.walletChanges(true)
+	if err != nil {
+		return err
+	}
+	for _, txid := range txids {
+		tx, err := i.GetTransaction(txid)
+		if err != nil {
+			Log.Errorf("downloading tx %s: %s", txid, err.Error())
+			continue
+		}
+		select {
+		case i.txNotifyChan <- *tx:
+		case <-done:
+			return nil
+		}
+	}
+	return nil
+}
+
+type listTransactionsEntry struct {
+	Address       string `json:"address"`
+	Category      string `json:"category"`
+	Txid          string `json:"txid"`
+	Confirmations int    `json:"confirmations"`
+	BlockHash     string `json:"blockhash"`
+}
+
+// walletChanges records the recent wallet transactions and, if report is set,
+// returns those which were not seen before or whose block changed since the
+// last call
+func (i *BitcoindClient) walletChanges(report bool) ([]string, error) {
+	var entries []listTransactionsEntry
+	if err := i.call("listtransactions", &entries, "*", 100, 0, true); err != nil {
+		return nil, err
+	}
+	var changed []string
+	i.stateLock.Lock()
+	defer i.stateLock.Unlock()
+	for _, e := range entries {
+		if blockHash, ok := i.seenTxs[e.Txid]; ok && blockHash == e.BlockHash {
+			continue
+		}
+		if report {
+			changed = append(changed, e.Txid)
+		}
+		i.seenTxs[e.Txid] = e.BlockHash
+	}
+	return dedupe(changed), nil
+}
+
+func dedupe(txids []string) []string {
+	var (
+		seen = make(map[string]bool)
+		ret  []string
+	)
+	for _, txid := range txids {
+		if !seen[txid] {
+			seen[txid] = true
+			ret = append(ret, txid)
+		}
+	}
+	return ret
+}
+
+type blockHeader struct {
+	Hash              string  `json:"hash"`
+	Height            int     `json:"height"`
+	Version           int     `json:"version"`
+	MerkleRoot        string  `json:"merkleroot"`
+	Time              int64   `json:"time"`
+	Nonce             uint32  `json:"nonce"`
+	Bits              string  `json:"bits"`
+	Difficulty        float64 `json:"difficulty"`
+	Confirmations     int     `json:"confirmations"`
+	PreviousBlockhash string  `json:"previousblockhash"`
+	NextBlockhash     string  `json:"nextblockhash"`
+}
+
+func (i *BitcoindClient) getBlockHeader(hash string) (*model.Block, error) {
+	var h blockHeader
+	if err := i.call("getblockheader", &h, hash, true); err != nil {
+		return nil, err
+	}
+	i.stateLock.Lock()
+	if h.Height > i.tipHeight {
+		i.tipHeight = h.Height
+	}
+	i.stateLock.Unlock()
+	return &model.Block{
+		Hash:              h.Hash,
+		Height:            h.Height,
+		Version:           h.Version,
+		MerkleRoot:        h.MerkleRoot,
+		Time:              h.Time,
+		Nonce:             strconv.FormatUint(uint64(h.Nonce), 10),
+		Bits:              h.Bits,
+		Difficulty:        h.Difficulty,
+		Confirmations:     h.Confirmations,
+		PreviousBlockhash: h.PreviousBlockhash,
+		NextBlockhash:     h.NextBlockhash,
+		IsMainChain:       true,
+	}, nil
+}
+
+// GetBestBlock returns the header of the node's best block
+func (i *BitcoindClient) GetBestBlock() (*model.Block, error) {
+	var bestHash string
+	if err := i.call("getbestblockhash", &bestHash); err != nil {
+		return nil, err
+	}
+	return i.getBlockHeader(bestHash)
+}
+
+func (i *BitcoindClient) bestHeight() (int, error) {
+	i.stateLock.Lock()
+	tip := i.tipHeight
+	i.stateLock.Unlock()
+	if tip > 0 {
+		return tip, nil
+	}
+	best, err := i.GetBestBlock()
+	if err != nil {
+		return 0, err
+	}
+	return best.Height, nil
+}
+
+// GetInfo reports the tip height, network and relay fee of the node
+func (i *BitcoindClient) GetInfo() (*model.Info, error) {
+	var chainInfo struct {
+		Chain      string  `json:"chain"`
+		Blocks     int     `json:"blocks"`
+		Difficulty float64 `json:"difficulty"`
+	}
+	if err := i.call("getblockchaininfo", &chainInfo); err != nil {
+		return nil, err
+	}
+	var netInfo struct {
+		Version         int     `json:"version"`
+		ProtocolVersion int     `json:"protocolversion"`
+		TimeOffset      int     `json:"timeoffset"`
+		Connections     int     `json:"connections"`
+		RelayFee        float64 `json:"relayfee"`
+		Warnings        string  `json:"warnings"`
+	}
+	if err := i.call("getnetworkinfo", &netInfo); err != nil {
+		return nil, err
+	}
+	return &model.Info{
+		Version:         netInfo.Version,
+		ProtocolVersion: netInfo.ProtocolVersion,
+		Blocks:          chainInfo.Blocks,
+		TimeOffset:      netInfo.TimeOffset,
+		Connections:     netInfo.Connections,
+		DifficultyIface: chainInfo.Difficulty,
+		Difficulty:      chainInfo.Difficulty,
+		Testnet:         chainInfo.Chain != "main",
+		RelayFeeIface:   netInfo.RelayFee,
+		RelayFee:        netInfo.RelayFee,
+		Errors:          netInfo.Warnings,
+		Network:         chainInfo.Chain,
+	}, nil
+}
+
+// getVerboseTx looks the transaction up in the node's transaction index and
+// falls back to the wallet for nodes running without -txindex
+func (i *BitcoindClient) getVerboseTx(txid string) (*model.VerboseTransaction, error) {
+	tx := new(model.VerboseTransaction)
+	err := i.call("getrawtransaction", tx, txid, true)
+	if err == nil {
+		return tx, nil
+	}
+	if clientErr.IsFatal(err) {
+		return nil, err
+	}
+
+	var walletTx struct {
+		Hex           string `json:"hex"`
+		Confirmations int    `json:"confirmations"`
+		BlockHash     string `json:"blockhash"`
+		BlockTime     int64  `json:"blocktime"`
+		Time          int64  `json:"time"`
+	}
+	if err := i.call("gettransaction", &walletTx, txid, true); err != nil {
+		return nil, err
+	}
+	if err := i.call("decoderawtransaction", tx, walletTx.Hex); err != nil {
+		return nil, err
+	}
+	tx.Hex = walletTx.Hex
+	tx.Confirmations = walletTx.Confirmations
+	tx.BlockHash = walletTx.BlockHash
+	tx.BlockTime = walletTx.BlockTime
+	tx.Time = walletTx.Time
+	return tx, nil
+}
+
+// GetTransaction returns the transaction with its inputs resolved against
+// their previous outputs where the node knows them
+func (i *BitcoindClient) GetTransaction(txid string) (*model.Transaction, error) {
+	vtx, err := i.getVerboseTx(txid)
+	if err != nil {
+		return nil, err
+	}
+	tip, err := i.bestHeight()
+	if err != nil {
+		return nil, err
+	}
+	return vtx.ToTransaction(tip, func(prevTxid string) (*model.VerboseTransaction, error) {
+		prev, err := i.getVerboseTx(prevTxid)
+		if err != nil {
+			if clientErr.IsFatal(err) {
+				return nil, err
+			}
+			// previous outputs of third parties are unknown without -txindex
+			return nil, nil
+		}
+		return prev, nil
+	})
+}
+
+func (i *BitcoindClient) GetRawTransaction(txid string) ([]byte, error) {
+	vtx, err := i.getVerboseTx(txid)
+	if err != nil {
+		return nil, err
+	}
+	return hex.DecodeString(vtx.Hex)
+}
+
+// GetTransactions returns the wallet transactions paying to or spending from
+// the addresses
+func (i *BitcoindClient) GetTransactions(addrs []btcutil.Address) ([]model.Transaction, error) {
+	var wanted = make(map[string]bool)
+	for _, addr := range addrs {
+		wanted[addr.String()] = true
+		wanted[addr.EncodeAddress()] = true
+	}
+
+	var txids []string
+	for skip := 0; ; skip += listPageSize {
+		var entries []listTransactionsEntry
+		if err := i.call("listtransactions", &entries, "*", listPageSize, skip, true); err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			txids = append(txids, e.Txid)
+		}
+		if len(entries) < listPageSize {
+			break
+		}
+	}
+
+	var txs []model.Transaction
+	for _, txid := range dedupe(txids) {
+		tx, err := i.GetTransaction(txid)
+		if err != nil {
+			return nil, err
+		}
+		if involvesAddress(tx, wanted) {
+			txs = append(txs, *tx)
+		}
+	}
+	return txs, nil
+}
+
+func involvesAddress(tx *model.Transaction, addrs map[string]bool) bool {
+	for _, in := range tx.Inputs {
+		if addrs[in.Addr] {
+			return true
+		}
+	}
+	for _, out := range tx.Outputs {
+		for _, a := range out.ScriptPubKey.Addresses {
+			if addrs[a] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// GetUtxos returns the unspent outputs of the addresses
+func (i *BitcoindClient) GetUtxos(addrs []btcutil.Address) ([]model.Utxo, error) {
+	if len(addrs) == 0 {
+		return nil, nil
+	}
+	if i.ScanTxOutSet {
+		if !i.lacks(&i.noScanTxOutSet) {
+			utxos, err := i.scanUtxos(addrs)
+			if !isMethodNotFound(err) {
+				return utxos, err
+			}
+			i.setLacks(&i.noScanTxOutSet, "scantxoutset")
+		}
+		// listunspent only sees the past outputs of addresses imported
+		// with a rescan
+		if err := i.importRescan(addrs); err != nil {
+			return nil, err
+		}
+	}
+	var addrStrings []string
+	for _, addr := range addrs {
+		addrStrings = append(addrStrings, addr.String())
+	}
+	var unspents []struct {
+		Txid          string  `json:"txid"`
+		Vout          int     `json:"vout"`
+		Address       string  `json:"address"`
+		ScriptPubKey  string  `json:"scriptPubKey"`
+		Amount        float64 `json:"amount"`
+		Confirmations int     `json:"confirmations"`
+	}
+	if err := i.listUnspent(&unspents, addrStrings); err != nil {
+		return nil, err
+	}
+	var utxos []model.Utxo
+	for _, u := range unspents {
+		utxos = append(utxos, model.Utxo{
+			Address:       strings.TrimPrefix(strings.TrimPrefix(u.Address, "bchtest:"), "bitcoincash:"),
+			Txid:          u.Txid,
+			Vout:          u.Vout,
+			ScriptPubKey:  u.ScriptPubKey,
+			AmountIface:   u.Amount,
+			Amount:        u.Amount,
+			Satoshis:      int64(u.Amount*1e8 + 0.5),
+			Confirmations: u.Confirmations,
+		})
+	}
+	return utxos, nil
+}
+
+// listUnspent lists the unspent outputs of the addresses including unsafe
+// ones, omitting include_unsafe for nodes which don't take it and list them
+// all anyway
+func (i *BitcoindClient) listUnspent(result interface{}, addrs []string) error {
+	if !i.lacks(&i.noUnsafeArg) {
+		err := i.call("listunspent", result, 0, 9999999, addrs, true)
+		if !isRPCError(err) {
+			return err
+		}
+		if err := i.call("listunspent", result, 0, 9999999, addrs); err != nil {
+			return err
+		}
+		i.setLacks(&i.noUnsafeArg, "listunspent include_unsafe")
+		return nil
+	}
+	return i.call("listunspent", result, 0, 9999999, addrs)
+}
+
+// scanUtxos finds the unspent outputs of the addresses in the node's UTXO set
+func (i *BitcoindClient) scanUtxos(addrs []btcutil.Address) ([]model.Utxo, error) {
+	var (
+		descriptors []string
+		scripts     = make(map[string]btcutil.Address)
+	)
+	for _, addr := range addrs {
+		script, err := i.AddrToScript(addr)
+		if err != nil {
+			return nil, err
+		}
+		scripts[hex.EncodeToString(script)] = addr
+		descriptors = append(descriptors, "raw("+hex.EncodeToString(script)+")")
+	}
+	var result struct {
+		Success  bool `json:"success"`
+		Height   int  `json:"height"`
+		Unspents []struct {
+			Txid         string  `json:"txid"`
+			Vout         int     `json:"vout"`
+			ScriptPubKey string  `json:"scriptPubKey"`
+			Amount       float64 `json:"amount"`
+			Height       int     `json:"height"`
+		} `json:"unspents"`
+	}
+	if err := i.call("scantxoutset", &result, "start", descriptors); err != nil {
+		return nil, err
+	}
+	if !result.Success {
+		return nil, errors.New("scantxoutset did not complete")
+	}
+	var utxos []model.Utxo
+	for _, u := range result.Unspents {
+		addr, ok := scripts[u.ScriptPubKey]
+		if !ok {
+			continue
+		}
+		utxos = append(utxos, model.Utxo{
+			Address:       addr.String(),
+			Txid:          u.Txid,
+			Vout:          u.Vout,
+			ScriptPubKey:  u.ScriptPubKey,
+			AmountIface:   u.Amount,
+			Amount:        u.Amount,
+			Satoshis:      int64(u.Amount*1e8 + 0.5),
+			Confirmations: result.Height - u.Height + 1,
+		})
+	}
+	return utxos, nil
+}
+
+// ListenAddress imports the address into the node's wallet as watch-only so
+// its transactions are picked up by polling
+func (i *BitcoindClient) ListenAddress(addr btcutil.Address) {
+	i.stateLock.Lock()
+	_, ok := i.watched[addr.String()]
+	i.watched[addr.String()] = addr
+	i.stateLock.Unlock()
+	if ok {
+		return
+	}
+	if i.Rescan || i.ScanTxOutSet && i.lacks(&i.noScanTxOutSet) {
+		if err := i.importRescan([]btcutil.Address{addr}); err != nil {
+			Log.Errorf("importing address %s: %s", addr.String(), err.Error())
+		}
+		return
+	}
+	if err := i.call("importaddress", nil, addr.String(), importLabel, false); err != nil {
+		Log.Errorf("importing address %s: %s", addr.String(), err.Error())
+	}
+}
+
+// importRescan imports the addresses which were not imported with a rescan
+// yet and rescans the chain once for all of them
+func (i *BitcoindClient) importRescan(addrs []btcutil.Address) error {
+	var imports []string
+	i.stateLock.Lock()
+	for _, addr := range addrs {
+		if !i.rescanned[addr.String()] {
+			imports = append(imports, addr.String())
+		}
+	}
+	i.stateLock.Unlock()
+	for n, addr := range imports {
+		// Only the last import rescans, which covers every address of the
+		// node's wallet
+		if err := i.call("importaddress", nil, addr, importLabel, n == len(imports)-1); err != nil {
+			return err
+		}
+	}
+	i.stateLock.Lock()
+	for _, addr := range imports {
+		i.rescanned[addr] = true
+	}
+	i.stateLock.Unlock()
+	return nil
+}
+
+func (i *BitcoindClient) Broadcast(tx []byte) (string, error) {
+	var txid string
+	if err := i.call("sendrawtransaction", &txid, hex.EncodeToString(tx)); err != nil {
+		return "", clientErr.Wrapf(err, "error broadcasting tx")
+	}
+	return txid, nil
+}
+
+// EstimateFee returns the fee per kilobyte in satoshis needed to confirm within
+// nBlocks according to estimatesmartfee, or estimatefee on nodes without it
+func (i *BitcoindClient) EstimateFee(nBlocks int) (int, error) {
+	if i.lacks(&i.noSmartFee) {
+		return i.estimateFeeLegacy(nBlocks)
+	}
+	var estimate struct {
+		FeeRate *float64 `json:"feerate"`
+		Errors  []string `json:"errors"`
+	}
+	if err := i.call("estimatesmartfee", &estimate, nBlocks); isMethodNotFound(err) {
+		i.setLacks(&i.noSmartFee, "estimatesmartfee")
+		return i.estimateFeeLegacy(nBlocks)
+	} else if err != nil {
+		return 0, err
+	}
+	if estimate.FeeRate == nil {
+		if len(estimate.Errors) > 0 {
+			return 0, fmt.Errorf("no fee estimate: %s", strings.Join(estimate.Errors, ", "))
+		}
+		return 0, errors.New("no fee estimate")
+	}
+	return int(*estimate.FeeRate * 1e8), nil
+}
+
+// estimateFeeLegacy estimates the fee with estimatefee, which takes the number
+// of blocks on zcashd and no argument on the Bitcoin Cash nodes, and returns a
+// negative fee rate without an estimate
+func (i *BitcoindClient) estimateFeeLegacy(nBlocks int) (int, error) {
+	var feeRate float64
+	err := i.call("estimatefee", &feeRate, nBlocks)
+	if isRPCError(err) && !isMethodNotFound(err) {
+		err = i.call("estimatefee", &feeRate)
+	}
+	if err != nil {
+		return 0, err
+	}
+	if feeRate <= 0 {
+		return 0, errors.New("no fee estimate")
+	}
+	return int(feeRate * 1e8), nil
+}
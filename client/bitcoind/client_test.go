@@ -0,0 +1,510 @@
+package bitcoind_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/muecoin/multiwallet/client/bitcoind"
+	clientErr "github.com/muecoin/multiwallet/client/errors"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcutil"
+)
+
+const testAddress = "1C74Gbij8Q5h61W58aSKGvXK4rk82T2A3y"
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type rpcHandler func(params []json.RawMessage) (interface{}, *rpcError)
+
+// fakeNode is a minimal bitcoind JSON-RPC server
+type fakeNode struct {
+	server   *httptest.Server
+	handlers map[string]rpcHandler
+	calls    map[string][][]json.RawMessage
+	user     string
+	pass     string
+	lock     sync.Mutex
+}
+
+func newFakeNode(user, pass string) *fakeNode {
+	n := &fakeNode{
+		handlers: make(map[string]rpcHandler),
+		calls:    make(map[string][][]json.RawMessage),
+		user:     user,
+		pass:     pass,
+	}
+	n.setTip("00000000000000000002a7c4c1e48d76c5a37902165a270156b7a8d72728a054", 600000)
+	n.handle("listtransactions", func(params []json.RawMessage) (interface{}, *rpcError) {
+		return []interface{}{}, nil
+	})
+	n.server = httptest.NewServer(http.HandlerFunc(n.serveHTTP))
+	return n
+}
+
+func (n *fakeNode) URL() string {
+	return n.server.URL
+}
+
+func (n *fakeNode) handle(method string, h rpcHandler) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	n.handlers[method] = h
+}
+
+func (n *fakeNode) setTip(hash string, height int) {
+	n.handle("getbestblockhash", func(params []json.RawMessage) (interface{}, *rpcError) {
+		return hash, nil
+	})
+	n.handle("getblockheader", func(params []json.RawMessage) (interface{}, *rpcError) {
+		return map[string]interface{}{
+			"hash":              hash,
+			"height":            height,
+			"version":           536870912,
+			"time":              1573814400,
+			"nonce":             1234,
+			"bits":              "1715a35c",
+			"confirmations":     1,
+			"previousblockhash": "00000000000000000009d2ac5bdce7a3d8b0f8c5e0ea3e3c6b8b9dd0c66b1e34",
+		}, nil
+	})
+}
+
+func (n *fakeNode) callsTo(method string) [][]json.RawMessage {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	return n.calls[method]
+}
+
+func (n *fakeNode) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	user, pass, ok := r.BasicAuth()
+	if !ok || user != n.user || pass != n.pass {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	var req struct {
+		Method string            `json:"method"`
+		Params []json.RawMessage `json:"params"`
+		ID     json.RawMessage   `json:"id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	n.lock.Lock()
+	n.calls[req.Method] = append(n.calls[req.Method], req.Params)
+	h, ok := n.handlers[req.Method]
+	n.lock.Unlock()
+
+	var resp = map[string]interface{}{"id": req.ID, "result": nil, "error": nil}
+	if !ok {
+		resp["error"] = &rpcError{Code: -32601, Message: "Method not found"}
+	} else if result, rerr := h(req.Params); rerr != nil {
+		resp["error"] = rerr
+	} else {
+		resp["result"] = result
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+func mustNewClient(t *testing.T, n *fakeNode, options map[string]interface{}) *bitcoind.BitcoindClient {
+	if options == nil {
+		options = make(map[string]interface{})
+	}
+	if _, ok := options[bitcoind.OptionRPCUser]; !ok {
+		options[bitcoind.OptionRPCUser] = n.user
+		options[bitcoind.OptionRPCPassword] = n.pass
+	}
+	c, err := bitcoind.NewBitcoindClient(n.URL(), options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func mustDecodeAddress(t *testing.T, addr string) btcutil.Address {
+	a, err := btcutil.DecodeAddress(addr, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func TestBitcoindClient_Credentials(t *testing.T) {
+	n := newFakeNode("alice", "s3cret")
+	defer n.server.Close()
+
+	c, err := bitcoind.NewBitcoindClient(n.URL(), map[string]interface{}{
+		bitcoind.OptionRPCUser:     "alice",
+		bitcoind.OptionRPCPassword: "wrong",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetBestBlock(); err == nil {
+		t.Error("expected error with the wrong password")
+	}
+	c.Close()
+
+	c = mustNewClient(t, n, nil)
+	defer c.Close()
+	block, err := c.GetBestBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if block.Height != 600000 {
+		t.Errorf("expected height 600000, got %d", block.Height)
+	}
+	if block.Hash != "00000000000000000002a7c4c1e48d76c5a37902165a270156b7a8d72728a054" {
+		t.Errorf("unexpected block hash %s", block.Hash)
+	}
+}
+
+func TestBitcoindClient_CredentialsFromURL(t *testing.T) {
+	n := newFakeNode("bob", "hunter2")
+	defer n.server.Close()
+
+	c, err := bitcoind.NewBitcoindClient("http://bob:hunter2@"+n.server.Listener.Addr().String(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if _, err := c.GetBestBlock(); err != nil {
+		t.Fatal(err)
+	}
+	if c.EndpointURL().User != nil {
+		t.Error("endpoint URL should not contain credentials")
+	}
+}
+
+func TestBitcoindClient_GetUtxos(t *testing.T) {
+	n := newFakeNode("user", "pass")
+	defer n.server.Close()
+	n.handle("listunspent", func(params []json.RawMessage) (interface{}, *rpcError) {
+		return []map[string]interface{}{{
+			"txid":          "1be612e4f2b79af279e0b307337924072b819b3aca09fcb20370dd9492b83428",
+			"vout":          1,
+			"address":       testAddress,
+			"scriptPubKey":  "76a91479ce9bb0d9edf53a20cfb63dc449f00e2d63a97488ac",
+			"amount":        0.0123,
+			"confirmations": 6,
+		}}, nil
+	})
+	c := mustNewClient(t, n, nil)
+	defer c.Close()
+
+	utxos, err := c.GetUtxos([]btcutil.Address{mustDecodeAddress(t, testAddress)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(utxos) != 1 {
+		t.Fatalf("expected 1 utxo, got %d", len(utxos))
+	}
+	if utxos[0].Satoshis != 1230000 || utxos[0].Confirmations != 6 || utxos[0].Vout != 1 {
+		t.Errorf("unexpected utxo %+v", utxos[0])
+	}
+	calls := n.callsTo("listunspent")
+	if len(calls) != 1 || len(calls[0]) < 3 || string(calls[0][2]) != `["`+testAddress+`"]` {
+		t.Errorf("unexpected listunspent params %s", calls)
+	}
+}
+
+func TestBitcoindClient_ScanTxOutSet(t *testing.T) {
+	n := newFakeNode("user", "pass")
+	defer n.server.Close()
+	n.handle("scantxoutset", func(params []json.RawMessage) (interface{}, *rpcError) {
+		return map[string]interface{}{
+			"success": true,
+			"height":  600000,
+			"unspents": []map[string]interface{}{{
+				"txid":         "1be612e4f2b79af279e0b307337924072b819b3aca09fcb20370dd9492b83428",
+				"vout":         0,
+				"scriptPubKey": "76a91479ce9bb0d9edf53a20cfb63dc449f00e2d63a97488ac",
+				"amount":       0.5,
+				"height":       599991,
+			}},
+		}, nil
+	})
+	c := mustNewClient(t, n, map[string]interface{}{bitcoind.OptionScanTxOutSet: true})
+	c.AddrToScript = txscript.PayToAddrScript
+	defer c.Close()
+
+	utxos, err := c.GetUtxos([]btcutil.Address{mustDecodeAddress(t, testAddress)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(utxos) != 1 {
+		t.Fatalf("expected 1 utxo, got %d", len(utxos))
+	}
+	if utxos[0].Address != testAddress || utxos[0].Satoshis != 50000000 || utxos[0].Confirmations != 10 {
+		t.Errorf("unexpected utxo %+v", utxos[0])
+	}
+	if len(n.callsTo("listunspent")) != 0 {
+		t.Error("listunspent should not be used with scantxoutset")
+	}
+}
+
+func TestBitcoindClient_GetTransactionWithoutTxIndex(t *testing.T) {
+	n := newFakeNode("user", "pass")
+	defer n.server.Close()
+	n.handle("getrawtransaction", func(params []json.RawMessage) (interface{}, *rpcError) {
+		return nil, &rpcError{Code: -5, Message: "No such mempool or blockchain transaction"}
+	})
+	n.handle("gettransaction", func(params []json.RawMessage) (interface{}, *rpcError) {
+		var txid string
+		json.Unmarshal(params[0], &txid)
+		if txid != "1be612e4f2b79af279e0b307337924072b819b3aca09fcb20370dd9492b83428" {
+			return nil, &rpcError{Code: -5, Message: "Invalid or non-wallet transaction id"}
+		}
+		return map[string]interface{}{
+			"hex":           "0100",
+			"confirmations": 3,
+			"blockhash":     "00000000000000000002a7c4c1e48d76c5a37902165a270156b7a8d72728a054",
+			"blocktime":     1573814400,
+			"time":          1573814000,
+		}, nil
+	})
+	n.handle("decoderawtransaction", func(params []json.RawMessage) (interface{}, *rpcError) {
+		return map[string]interface{}{
+			"txid":     "1be612e4f2b79af279e0b307337924072b819b3aca09fcb20370dd9492b83428",
+			"version":  1,
+			"locktime": 0,
+			"vin": []map[string]interface{}{{
+				"txid":      "a8d0c0184dde994a09ec054286f1ce581bebf46446a512166eae7628734ea0a5",
+				"vout":      0,
+				"sequence":  4294967295,
+				"scriptSig": map[string]interface{}{"hex": "00"},
+			}},
+			"vout": []map[string]interface{}{{
+				"value": 0.0123,
+				"n":     0,
+				"scriptPubKey": map[string]interface{}{
+					"hex":     "76a91479ce9bb0d9edf53a20cfb63dc449f00e2d63a97488ac",
+					"type":    "pubkeyhash",
+					"address": testAddress,
+				},
+			}},
+		}, nil
+	})
+	c := mustNewClient(t, n, nil)
+	defer c.Close()
+
+	tx, err := c.GetTransaction("1be612e4f2b79af279e0b307337924072b819b3aca09fcb20370dd9492b83428")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx.Confirmations != 3 || tx.BlockHeight != 599998 {
+		t.Errorf("expected 3 confirmations at height 599998, got %d at %d", tx.Confirmations, tx.BlockHeight)
+	}
+	if len(tx.Inputs) != 1 || tx.Inputs[0].Addr != "" {
+		t.Errorf("unknown previous output should leave the input unresolved, got %+v", tx.Inputs)
+	}
+	if len(tx.Outputs) != 1 || len(tx.Outputs[0].ScriptPubKey.Addresses) != 1 || tx.Outputs[0].ScriptPubKey.Addresses[0] != testAddress {
+		t.Errorf("unexpected outputs %+v", tx.Outputs)
+	}
+}
+
+func TestBitcoindClient_BroadcastErrors(t *testing.T) {
+	n := newFakeNode("user", "pass")
+	n.handle("sendrawtransaction", func(params []json.RawMessage) (interface{}, *rpcError) {
+		return nil, &rpcError{Code: -26, Message: "min relay fee not met"}
+	})
+	c := mustNewClient(t, n, nil)
+	defer c.Close()
+
+	_, err := c.Broadcast([]byte{0x01})
+	if err == nil {
+		t.Fatal("expected the rejected transaction to fail")
+	}
+	if clientErr.IsFatal(err) || clientErr.IsRetryable(err) {
+		t.Errorf("expected a rejection by the node to be neither fatal nor retryable: %s", err.Error())
+	}
+
+	n.server.Close()
+	_, err = c.Broadcast([]byte{0x01})
+	if err == nil {
+		t.Fatal("expected broadcasting to an unreachable node to fail")
+	}
+	if !clientErr.IsFatal(err) || !clientErr.IsRetryable(err) {
+		t.Errorf("expected an unreachable node to be fatal and retryable: %s", err.Error())
+	}
+}
+
+func TestBitcoindClient_ListenAddressImports(t *testing.T) {
+	n := newFakeNode("user", "pass")
+	defer n.server.Close()
+	n.handle("importaddress", func(params []json.RawMessage) (interface{}, *rpcError) {
+		return nil, nil
+	})
+	c := mustNewClient(t, n, map[string]interface{}{bitcoind.OptionRescan: true})
+	defer c.Close()
+
+	addr := mustDecodeAddress(t, testAddress)
+	c.ListenAddress(addr)
+	c.ListenAddress(addr)
+	calls := n.callsTo("importaddress")
+	if len(calls) != 1 {
+		t.Fatalf("expected the address to be imported once, got %d calls", len(calls))
+	}
+	if string(calls[0][0]) != `"`+testAddress+`"` || string(calls[0][2]) != "true" {
+		t.Errorf("unexpected importaddress params %s", calls[0])
+	}
+}
+
+func TestBitcoindClient_PollsForBlocks(t *testing.T) {
+	n := newFakeNode("user", "pass")
+	defer n.server.Close()
+	c := mustNewClient(t, n, map[string]interface{}{bitcoind.OptionPollInterval: "10ms"})
+	closeChan := make(chan error, 1)
+	if err := c.Start(closeChan); err != nil {
+		t.Fatal(err)
+	}
+
+	n.setTip("0000000000000000000b1e4b6c3fa5c0c8c0d8b7e1c2c3b4a5f6e7d8c9b0a1f2", 600001)
+	select {
+	case block := <-c.BlockChannel():
+		if block.Height != 600001 {
+			t.Errorf("expected block 600001, got %d", block.Height)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for block")
+	}
+
+	c.Close()
+	select {
+	case err := <-closeChan:
+		if err != nil {
+			t.Errorf("expected nil on close, got %s", err.Error())
+		}
+	case <-time.After(time.Second):
+		t.Error("close was not reported")
+	}
+}
+
+func TestBitcoindClient_EstimateFee(t *testing.T) {
+	n := newFakeNode("user", "pass")
+	defer n.server.Close()
+	n.handle("estimatesmartfee", func(params []json.RawMessage) (interface{}, *rpcError) {
+		if string(params[0]) == "1" {
+			return map[string]interface{}{"errors": []string{"Insufficient data or no feerate found"}, "blocks": 2}, nil
+		}
+		return map[string]interface{}{"feerate": 0.00012, "blocks": 6}, nil
+	})
+	c := mustNewClient(t, n, nil)
+	defer c.Close()
+
+	fee, err := c.EstimateFee(6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fee != 12000 {
+		t.Errorf("expected 12000 sat/kB, got %d", fee)
+	}
+	if _, err := c.EstimateFee(1); err == nil {
+		t.Error("expected error when the node has no estimate")
+	}
+}
+
+// newZcashdNode returns a fake node answering like zcashd, which has neither
+// estimatesmartfee nor scantxoutset and takes three listunspent arguments
+func newZcashdNode() *fakeNode {
+	n := newFakeNode("user", "pass")
+	n.handle("listunspent", func(params []json.RawMessage) (interface{}, *rpcError) {
+		if len(params) > 3 {
+			return nil, &rpcError{Code: -1, Message: "listunspent ( minconf maxconf [\"address\",...] )"}
+		}
+		return []map[string]interface{}{{
+			"txid":          "1be612e4f2b79af279e0b307337924072b819b3aca09fcb20370dd9492b83428",
+			"vout":          0,
+			"address":       testAddress,
+			"scriptPubKey":  "76a91479ce9bb0d9edf53a20cfb63dc449f00e2d63a97488ac",
+			"amount":        0.5,
+			"confirmations": 10,
+		}}, nil
+	})
+	n.handle("importaddress", func(params []json.RawMessage) (interface{}, *rpcError) {
+		return nil, nil
+	})
+	n.handle("estimatefee", func(params []json.RawMessage) (interface{}, *rpcError) {
+		if len(params) != 1 || string(params[0]) != "6" {
+			return -1, nil
+		}
+		return 0.0001, nil
+	})
+	return n
+}
+
+func TestBitcoindClient_ZcashdFallbacks(t *testing.T) {
+	n := newZcashdNode()
+	defer n.server.Close()
+	c := mustNewClient(t, n, map[string]interface{}{bitcoind.OptionScanTxOutSet: true})
+	c.AddrToScript = txscript.PayToAddrScript
+	defer c.Close()
+	addr := mustDecodeAddress(t, testAddress)
+
+	c.ListenAddress(addr)
+	for k := 0; k < 2; k++ {
+		utxos, err := c.GetUtxos([]btcutil.Address{addr})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(utxos) != 1 || utxos[0].Satoshis != 50000000 || utxos[0].Confirmations != 10 {
+			t.Fatalf("unexpected utxos %+v", utxos)
+		}
+	}
+	if calls := n.callsTo("scantxoutset"); len(calls) != 1 {
+		t.Errorf("expected scantxoutset to be tried once, got %d calls", len(calls))
+	}
+	if calls := n.callsTo("listunspent"); len(calls) != 3 || len(calls[1]) != 3 || len(calls[2]) != 3 {
+		t.Errorf("expected listunspent to drop include_unsafe once rejected, got %s", calls)
+	}
+	imports := n.callsTo("importaddress")
+	if len(imports) != 2 || string(imports[0][2]) != "false" || string(imports[1][2]) != "true" {
+		t.Errorf("expected the address to be imported again with a rescan once, got %s", imports)
+	}
+
+	for k := 0; k < 2; k++ {
+		fee, err := c.EstimateFee(6)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fee != 10000 {
+			t.Errorf("expected 10000 sat/kB, got %d", fee)
+		}
+	}
+	if len(n.callsTo("estimatesmartfee")) != 1 {
+		t.Error("expected estimatesmartfee to be tried once")
+	}
+	if _, err := c.EstimateFee(1); err == nil {
+		t.Error("expected error when the node has no estimate")
+	}
+}
+
+func TestBitcoindClient_EstimateFeeWithoutBlocks(t *testing.T) {
+	n := newFakeNode("user", "pass")
+	defer n.server.Close()
+	// The Bitcoin Cash nodes take no argument to estimatefee
+	n.handle("estimatefee", func(params []json.RawMessage) (interface{}, *rpcError) {
+		if len(params) > 0 {
+			return nil, &rpcError{Code: -1, Message: "estimatefee"}
+		}
+		return 0.00001, nil
+	})
+	c := mustNewClient(t, n, nil)
+	defer c.Close()
+
+	fee, err := c.EstimateFee(6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fee != 1000 {
+		t.Errorf("expected 1000 sat/kB, got %d", fee)
+	}
+}
@@ -0,0 +1,620 @@
+package electrum
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"sync"
+	"time"
+
+	clientErr "github.com/muecoin/multiwallet/client/errors"
+	"github.com/muecoin/multiwallet/model"
+	"github.com/muecoin/multiwallet/registry"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcutil"
+	"github.com/op/go-logging"
+	"golang.org/x/net/proxy"
+)
+
+var Log = logging.MustGetLogger("electrum")
+
+const (
+	clientName      = "multiwallet"
+	protocolVersion = "1.4"
+)
+
+// ElectrumClient talks to an Electrum server over JSON-RPC on a TCP or TLS
+// socket. Addresses are tracked by their scripthash so the client works for
+// any coin whose output scripts can be built by AddrToScript, which defaults
+// to the address codecs of the registered coins.
+//
+// Transaction lookups use the verbose form of blockchain.transaction.get, so
+// the server must be backed by a node with a transaction index.
+type ElectrumClient struct {
+	apiUrl          *url.URL
+	blockNotifyChan chan model.Block
+	closeChan       chan<- error
+	proxyDialer     proxy.Dialer
+	txNotifyChan    chan model.Transaction
+
+	conn     *rpcConn
+	connLock sync.Mutex
+	started  bool
+	done     chan struct{}
+
+	subscriptions map[string]btcutil.Address
+	seenTxs       map[string]int
+	tipHeight     int
+	stateLock     sync.Mutex
+
+	// AddrToScript builds the output script watched for an address
+	AddrToScript func(btcutil.Address) ([]byte, error)
+	// TLSConfig is used for ssl:// and tls:// endpoints
+	TLSConfig *tls.Config
+	// RequestTimeout bounds every request to the server
+	RequestTimeout time.Duration
+}
+
+// NewElectrumClient returns a client for the server at apiUrl which must use
+// the tcp, ssl or tls scheme, for example ssl://electrum.example.com:50002.
+func NewElectrumClient(apiUrl string, proxyDialer proxy.Dialer) (*ElectrumClient, error) {
+	u, err := url.Parse(apiUrl)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateScheme(u); err != nil {
+		return nil, err
+	}
+	if u.Port() == "" {
+		return nil, fmt.Errorf("missing port in electrum endpoint: %s", apiUrl)
+	}
+	ec := &ElectrumClient{
+		apiUrl:          u,
+		blockNotifyChan: make(chan model.Block),
+		proxyDialer:     proxyDialer,
+		txNotifyChan:    make(chan model.Transaction),
+		done:            make(chan struct{}),
+		subscriptions:   make(map[string]btcutil.Address),
+		seenTxs:         make(map[string]int),
+		AddrToScript:    registry.AddressToScript,
+		TLSConfig:       &tls.Config{ServerName: u.Hostname()},
+		RequestTimeout:  time.Second * 30,
+	}
+	return ec, nil
+}
+
+func validateScheme(target *url.URL) error {
+	switch target.Scheme {
+	case "tcp", "ssl", "tls":
+		return nil
+	}
+	return fmt.Errorf("unsupported scheme: %s", target.Scheme)
+}
+
+func (i *ElectrumClient) String() string {
+	return i.apiUrl.Host
+}
+
+func (i *ElectrumClient) EndpointURL() *url.URL {
+	var u = *i.apiUrl
+	return &u
+}
+
+func (i *ElectrumClient) BlockChannel() chan model.Block {
+	return i.blockNotifyChan
+}
+
+func (i *ElectrumClient) TxChannel() chan model.Transaction {
+	return i.txNotifyChan
+}
+
+func (i *ElectrumClient) BlockNotify() <-chan model.Block {
+	return i.blockNotifyChan
+}
+
+func (i *ElectrumClient) TransactionNotify() <-chan model.Transaction {
+	return i.txNotifyChan
+}
+
+// Start subscribes to new headers and resubscribes any addresses which were
+// being listened on. The closeChan receives a single value when the client is
+// closed (nil) or the connection to the server is lost (non-nil).
+func (i *ElectrumClient) Start(closeChan chan<- error) error {
+	if _, err := i.GetBestBlock(); err != nil {
+		return err
+	}
+
+	i.connLock.Lock()
+	i.closeChan = closeChan
+	i.started = true
+	i.done = make(chan struct{})
+	i.connLock.Unlock()
+
+	i.stateLock.Lock()
+	var addrs []btcutil.Address
+	for _, addr := range i.subscriptions {
+		addrs = append(addrs, addr)
+	}
+	i.stateLock.Unlock()
+	for _, addr := range addrs {
+		if err := i.subscribe(addr); err != nil {
+			return err
+		}
+	}
+	Log.Infof("connected to electrum server %s", i.String())
+	return nil
+}
+
+func (i *ElectrumClient) Close() {
+	Log.Infof("closing client (%s)...", i.String())
+	i.connLock.Lock()
+	conn := i.conn
+	closeChan := i.closeChan
+	if i.started {
+		close(i.done)
+	}
+	i.conn = nil
+	i.closeChan = nil
+	i.started = false
+	i.connLock.Unlock()
+
+	if conn != nil {
+		conn.close(nil)
+	}
+	if closeChan != nil {
+		closeChan <- nil
+	}
+}
+
+// connection returns the active connection, dialing the server and
+// negotiating the protocol version if needed
+func (i *ElectrumClient) connection() (*rpcConn, error) {
+	i.connLock.Lock()
+	defer i.connLock.Unlock()
+	if i.conn != nil {
+		return i.conn, nil
+	}
+	netConn, err := i.dial()
+	if err != nil {
+		return nil, err
+	}
+	conn := newRPCConn(netConn, i.handleNotification, i.handleClose)
+	// the version must be negotiated once per connection before other requests
+	if err := conn.call("server.version", i.RequestTimeout, nil, clientName, protocolVersion); err != nil {
+		netConn.Close()
+		return nil, err
+	}
+	i.conn = conn
+	return i.conn, nil
+}
+
+func (i *ElectrumClient) dial() (net.Conn, error) {
+	dial := net.Dial
+	if i.proxyDialer != nil {
+		dial = i.proxyDialer.Dial
+	}
+	conn, err := dial("tcp", i.apiUrl.Host)
+	if err != nil {
+		return nil, err
+	}
+	if i.apiUrl.Scheme == "tcp" {
+		return conn, nil
+	}
+	tlsConn := tls.Client(conn, i.TLSConfig)
+	tlsConn.SetDeadline(time.Now().Add(i.RequestTimeout))
+	if err := tlsConn.Handshake(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	tlsConn.SetDeadline(time.Time{})
+	return tlsConn, nil
+}
+
+// handleClose is called once the connection is gone. Losing the connection of
+// a started client is reported on the closeChan so the pool can rotate.
+func (i *ElectrumClient) handleClose(c *rpcConn, err error) {
+	i.connLock.Lock()
+	if i.conn != c {
+		i.connLock.Unlock()
+		return
+	}
+	i.conn = nil
+	closeChan := i.closeChan
+	i.closeChan = nil
+	i.connLock.Unlock()
+
+	if closeChan != nil {
+		Log.Warningf("connection to electrum server %s lost: %s", i.String(), err.Error())
+		closeChan <- fmt.Errorf("electrum connection lost (%s): %s", i.String(), err.Error())
+	}
+}
+
+// call makes a request and classifies failures for the ClientPool. Failing to
+// reach the server is fatal so the pool rotates, timeouts are retryable and
+// errors reported by the server are returned as is.
+func (i *ElectrumClient) call(method string, result interface{}, params ...interface{}) error {
+	conn, err := i.connection()
+	if err != nil {
+		return clientErr.MakeRetryable(clientErr.MakeFatal(fmt.Errorf("connecting to %s: %s", i.String(), err.Error())))
+	}
+	err = conn.call(method, i.RequestTimeout, result, params...)
+	if err == nil {
+		return nil
+	}
+	if _, ok := err.(*RPCError); ok {
+		return err
+	}
+	if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+		return clientErr.MakeRetryable(err)
+	}
+	return clientErr.MakeRetryable(clientErr.MakeFatal(err))
+}
+
+func (i *ElectrumClient) handleNotification(method string, params json.RawMessage) {
+	switch method {
+	case "blockchain.headers.subscribe":
+		var headers []headerResponse
+		if err := json.Unmarshal(params, &headers); err != nil || len(headers) == 0 {
+			Log.Errorf("decoding header notification: %s", string(params))
+			return
+		}
+		block, err := i.blockFromHeader(headers[0])
+		if err != nil {
+			Log.Errorf("decoding header notification: %s", err.Error())
+			return
+		}
+		go i.notifyBlock(*block)
+	case "blockchain.scripthash.subscribe":
+		var args []interface{}
+		if err := json.Unmarshal(params, &args); err != nil || len(args) == 0 {
+			Log.Errorf("decoding scripthash notification: %s", string(params))
+			return
+		}
+		scriptHash, ok := args[0].(string)
+		if !ok {
+			Log.Errorf("decoding scripthash notification: %s", string(params))
+			return
+		}
+		go i.handleScriptHashChange(scriptHash)
+	}
+}
+
+func (i *ElectrumClient) notifyBlock(block model.Block) {
+	i.connLock.Lock()
+	started, done := i.started, i.done
+	i.connLock.Unlock()
+	if !started {
+		return
+	}
+	select {
+	case i.blockNotifyChan <- block:
+	case <-done:
+	}
+}
+
+func (i *ElectrumClient) notifyTx(tx model.Transaction) {
+	i.connLock.Lock()
+	started, done := i.started, i.done
+	i.connLock.Unlock()
+	if !started {
+		return
+	}
+	select {
+	case i.txNotifyChan <- tx:
+	case <-done:
+	}
+}
+
+// handleScriptHashChange fetches the history of a scripthash whose status
+// changed and emits every transaction which is new or whose height changed.
+func (i *ElectrumClient) handleScriptHashChange(scriptHash string) {
+	history, err := i.getHistory(scriptHash)
+	if err != nil {
+		Log.Errorf("fetching history after scripthash notification: %s", err.Error())
+		return
+	}
+	for _, h := range history {
+		i.stateLock.Lock()
+		height, seen := i.seenTxs[h.TxHash]
+		i.seenTxs[h.TxHash] = h.Height
+		i.stateLock.Unlock()
+		if seen && height == h.Height {
+			continue
+		}
+		tx, err := i.GetTransaction(h.TxHash)
+		if err != nil {
+			Log.Errorf("downloading tx after scripthash notification: %s", err.Error())
+			continue
+		}
+		i.notifyTx(*tx)
+	}
+}
+
+// ScriptHash returns the Electrum scripthash of an output script: the
+// byte-reversed SHA256 of the script, hex encoded.
+func ScriptHash(script []byte) string {
+	h := sha256.Sum256(script)
+	for l, r := 0, len(h)-1; l < r; l, r = l+1, r-1 {
+		h[l], h[r] = h[r], h[l]
+	}
+	return hex.EncodeToString(h[:])
+}
+
+func (i *ElectrumClient) scriptHash(addr btcutil.Address) (string, []byte, error) {
+	script, err := i.AddrToScript(addr)
+	if err != nil {
+		return "", nil, err
+	}
+	return ScriptHash(script), script, nil
+}
+
+// ListenAddress subscribes to status changes of the address. If the client
+// has not been started the subscription is made on Start.
+func (i *ElectrumClient) ListenAddress(addr btcutil.Address) {
+	scriptHash, _, err := i.scriptHash(addr)
+	if err != nil {
+		Log.Errorf("listening on %s: %s", addr.String(), err.Error())
+		return
+	}
+	i.stateLock.Lock()
+	i.subscriptions[scriptHash] = addr
+	i.stateLock.Unlock()
+
+	i.connLock.Lock()
+	started := i.started
+	i.connLock.Unlock()
+	if !started {
+		return
+	}
+	if err := i.subscribe(addr); err != nil {
+		Log.Errorf("subscribing to %s: %s", addr.String(), err.Error())
+	}
+}
+
+// subscribe registers the address with the server and records its current
+// history so that only later changes are emitted.
+func (i *ElectrumClient) subscribe(addr btcutil.Address) error {
+	scriptHash, _, err := i.scriptHash(addr)
+	if err != nil {
+		return err
+	}
+	var status *string
+	if err := i.call("blockchain.scripthash.subscribe", &status, scriptHash); err != nil {
+		return err
+	}
+	if status == nil {
+		return nil
+	}
+	history, err := i.getHistory(scriptHash)
+	if err != nil {
+		return err
+	}
+	i.stateLock.Lock()
+	for _, h := range history {
+		i.seenTxs[h.TxHash] = h.Height
+	}
+	i.stateLock.Unlock()
+	return nil
+}
+
+type historyItem struct {
+	TxHash string `json:"tx_hash"`
+	Height int    `json:"height"`
+}
+
+func (i *ElectrumClient) getHistory(scriptHash string) ([]historyItem, error) {
+	var history []historyItem
+	if err := i.call("blockchain.scripthash.get_history", &history, scriptHash); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// GetInfo reports the tip height and relay fee of the server
+func (i *ElectrumClient) GetInfo() (*model.Info, error) {
+	best, err := i.GetBestBlock()
+	if err != nil {
+		return nil, err
+	}
+	var relayFee float64
+	if err := i.call("blockchain.relayfee", &relayFee); err != nil {
+		return nil, err
+	}
+	return &model.Info{
+		Blocks:        best.Height,
+		RelayFeeIface: relayFee,
+		RelayFee:      relayFee,
+	}, nil
+}
+
+type headerResponse struct {
+	Height int    `json:"height"`
+	Hex    string `json:"hex"`
+}
+
+func (i *ElectrumClient) blockFromHeader(h headerResponse) (*model.Block, error) {
+	raw, err := hex.DecodeString(h.Hex)
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) < 80 {
+		return nil, errors.New("header too short")
+	}
+	var prev chainhash.Hash
+	copy(prev[:], raw[4:36])
+	// Zcash headers carry the final sapling root ahead of the timestamp
+	timeOffset := 68
+	if len(raw) > 80 {
+		timeOffset = 100
+	}
+	i.stateLock.Lock()
+	if h.Height > i.tipHeight {
+		i.tipHeight = h.Height
+	}
+	i.stateLock.Unlock()
+	return &model.Block{
+		Hash:              chainhash.DoubleHashH(raw).String(),
+		Height:            h.Height,
+		Version:           int(binary.LittleEndian.Uint32(raw[0:4])),
+		Time:              int64(binary.LittleEndian.Uint32(raw[timeOffset : timeOffset+4])),
+		Size:              len(raw),
+		PreviousBlockhash: prev.String(),
+		IsMainChain:       true,
+	}, nil
+}
+
+// GetBestBlock returns the tip announced by blockchain.headers.subscribe
+func (i *ElectrumClient) GetBestBlock() (*model.Block, error) {
+	var header headerResponse
+	if err := i.call("blockchain.headers.subscribe", &header); err != nil {
+		return nil, err
+	}
+	return i.blockFromHeader(header)
+}
+
+// bestHeight returns the last known tip height, asking the server if no
+// header has been seen yet
+func (i *ElectrumClient) bestHeight() (int, error) {
+	i.stateLock.Lock()
+	tip := i.tipHeight
+	i.stateLock.Unlock()
+	if tip > 0 {
+		return tip, nil
+	}
+	best, err := i.GetBestBlock()
+	if err != nil {
+		return 0, err
+	}
+	return best.Height, nil
+}
+
+func (i *ElectrumClient) GetRawTransaction(txid string) ([]byte, error) {
+	var txHex string
+	if err := i.call("blockchain.transaction.get", &txHex, txid); err != nil {
+		return nil, err
+	}
+	return hex.DecodeString(txHex)
+}
+
+func (i *ElectrumClient) getVerboseTx(txid string) (*model.VerboseTransaction, error) {
+	tx := new(model.VerboseTransaction)
+	if err := i.call("blockchain.transaction.get", tx, txid, true); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// GetTransaction returns the transaction with its inputs resolved against
+// their previous outputs so the input addresses and values are populated.
+func (i *ElectrumClient) GetTransaction(txid string) (*model.Transaction, error) {
+	vtx, err := i.getVerboseTx(txid)
+	if err != nil {
+		return nil, err
+	}
+	tip, err := i.bestHeight()
+	if err != nil {
+		return nil, err
+	}
+	return vtx.ToTransaction(tip, i.getVerboseTx)
+}
+
+// GetTransactions returns every transaction in the history of the addresses
+func (i *ElectrumClient) GetTransactions(addrs []btcutil.Address) ([]model.Transaction, error) {
+	var (
+		txs  []model.Transaction
+		seen = make(map[string]bool)
+	)
+	for _, addr := range addrs {
+		scriptHash, _, err := i.scriptHash(addr)
+		if err != nil {
+			return nil, err
+		}
+		history, err := i.getHistory(scriptHash)
+		if err != nil {
+			return nil, err
+		}
+		for _, h := range history {
+			if seen[h.TxHash] {
+				continue
+			}
+			seen[h.TxHash] = true
+			tx, err := i.GetTransaction(h.TxHash)
+			if err != nil {
+				return nil, err
+			}
+			txs = append(txs, *tx)
+		}
+	}
+	return txs, nil
+}
+
+// GetUtxos returns the unspent outputs of the addresses
+func (i *ElectrumClient) GetUtxos(addrs []btcutil.Address) ([]model.Utxo, error) {
+	type unspent struct {
+		TxHash string `json:"tx_hash"`
+		TxPos  int    `json:"tx_pos"`
+		Height int    `json:"height"`
+		Value  int64  `json:"value"`
+	}
+	var ret []model.Utxo
+	tip, err := i.bestHeight()
+	if err != nil {
+		return nil, err
+	}
+	for _, addr := range addrs {
+		scriptHash, script, err := i.scriptHash(addr)
+		if err != nil {
+			return nil, err
+		}
+		var unspents []unspent
+		if err := i.call("blockchain.scripthash.listunspent", &unspents, scriptHash); err != nil {
+			return nil, err
+		}
+		for _, u := range unspents {
+			var confirmations int
+			if u.Height > 0 && tip >= u.Height {
+				confirmations = tip - u.Height + 1
+			}
+			amount := float64(u.Value) / 1e8
+			ret = append(ret, model.Utxo{
+				Address:       addr.String(),
+				Txid:          u.TxHash,
+				Vout:          u.TxPos,
+				ScriptPubKey:  hex.EncodeToString(script),
+				AmountIface:   amount,
+				Amount:        amount,
+				Satoshis:      u.Value,
+				Confirmations: confirmations,
+			})
+		}
+	}
+	return ret, nil
+}
+
+func (i *ElectrumClient) Broadcast(tx []byte) (string, error) {
+	var txid string
+	if err := i.call("blockchain.transaction.broadcast", &txid, hex.EncodeToString(tx)); err != nil {
+		return "", clientErr.Wrapf(err, "error broadcasting tx")
+	}
+	return txid, nil
+}
+
+// EstimateFee returns the fee per kilobyte in satoshis needed to confirm within
+// nBlocks. Servers answer -1 when they have no estimate, which is returned as
+// an error.
+func (i *ElectrumClient) EstimateFee(nBlocks int) (int, error) {
+	var fee float64
+	if err := i.call("blockchain.estimatefee", &fee, nBlocks); err != nil {
+		return 0, err
+	}
+	if fee < 0 {
+		return 0, errors.New("server has no fee estimate")
+	}
+	return int(fee * 1e8), nil
+}
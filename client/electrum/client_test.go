@@ -0,0 +1,666 @@
+package electrum_test
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/muecoin/multiwallet/client"
+	"github.com/muecoin/multiwallet/client/electrum"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+)
+
+type rpcHandler func(params []json.RawMessage) (interface{}, error)
+
+// fakeServer is a minimal in-process Electrum server speaking newline
+// delimited JSON-RPC.
+type fakeServer struct {
+	listener net.Listener
+	handlers map[string]rpcHandler
+	conns    []*fakeConn
+	calls    map[string]int
+	lock     sync.Mutex
+}
+
+type fakeConn struct {
+	conn net.Conn
+	lock sync.Mutex
+}
+
+func (c *fakeConn) send(v interface{}) {
+	b, _ := json.Marshal(v)
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.conn.Write(append(b, '\n'))
+}
+
+func newFakeServer(t *testing.T, listener net.Listener) *fakeServer {
+	s := &fakeServer{
+		listener: listener,
+		handlers: make(map[string]rpcHandler),
+		calls:    make(map[string]int),
+	}
+	s.handle("server.version", func(params []json.RawMessage) (interface{}, error) {
+		return []string{"FakeElectrum 1.0", "1.4"}, nil
+	})
+	s.handle("blockchain.headers.subscribe", func(params []json.RawMessage) (interface{}, error) {
+		return map[string]interface{}{"height": testTipHeight, "hex": hex.EncodeToString(testHeader(1))}, nil
+	})
+	go s.serve()
+	return s
+}
+
+func mustNewFakeServer(t *testing.T) *fakeServer {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return newFakeServer(t, l)
+}
+
+func (s *fakeServer) URL(scheme string) string {
+	return scheme + "://" + s.listener.Addr().String()
+}
+
+func (s *fakeServer) Close() {
+	s.listener.Close()
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for _, c := range s.conns {
+		c.conn.Close()
+	}
+}
+
+func (s *fakeServer) handle(method string, h rpcHandler) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.handlers[method] = h
+}
+
+func (s *fakeServer) callCount(method string) int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.calls[method]
+}
+
+func (s *fakeServer) notify(method string, params ...interface{}) {
+	s.lock.Lock()
+	conns := append([]*fakeConn{}, s.conns...)
+	s.lock.Unlock()
+	for _, c := range conns {
+		c.send(map[string]interface{}{"jsonrpc": "2.0", "method": method, "params": params})
+	}
+}
+
+func (s *fakeServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		fc := &fakeConn{conn: conn}
+		s.lock.Lock()
+		s.conns = append(s.conns, fc)
+		s.lock.Unlock()
+		go s.serveConn(fc)
+	}
+}
+
+func (s *fakeServer) serveConn(c *fakeConn) {
+	reader := bufio.NewReader(c.conn)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			return
+		}
+		var req struct {
+			ID     uint64            `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		if err := json.Unmarshal(line, &req); err != nil {
+			return
+		}
+		s.lock.Lock()
+		s.calls[req.Method]++
+		h, ok := s.handlers[req.Method]
+		s.lock.Unlock()
+		resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+		if !ok {
+			resp["error"] = map[string]interface{}{"code": -32601, "message": "unknown method " + req.Method}
+		} else if result, err := h(req.Params); err != nil {
+			resp["error"] = map[string]interface{}{"code": 1, "message": err.Error()}
+		} else {
+			resp["result"] = result
+		}
+		c.send(resp)
+	}
+}
+
+const testTipHeight = 600000
+
+func testHeader(nonce uint32) []byte {
+	var buf bytes.Buffer
+	hdr := wire.BlockHeader{
+		Version:   0x20000000,
+		PrevBlock: chainhash.DoubleHashH([]byte("previous")),
+		Timestamp: time.Unix(1571000000, 0),
+		Bits:      0x17148edf,
+		Nonce:     nonce,
+	}
+	hdr.Serialize(&buf)
+	return buf.Bytes()
+}
+
+func mustDecodeAddress(addr string) btcutil.Address {
+	a, err := btcutil.DecodeAddress(addr, &chaincfg.MainNetParams)
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
+
+func mustScriptHash(addr btcutil.Address) string {
+	script, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		panic(err)
+	}
+	return electrum.ScriptHash(script)
+}
+
+func mustNewClient(t *testing.T, endpoint string) *electrum.ElectrumClient {
+	c, err := electrum.NewElectrumClient(endpoint, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.AddrToScript = txscript.PayToAddrScript
+	c.RequestTimeout = 5 * time.Second
+	return c
+}
+
+var (
+	fundingAddr = "1C74Gbij8Q5h61W58aSKGvXK4rk82T2A3y"
+	payeeAddr   = "1QGdNEDjWnghrjfTBCTDAPZZ3ffoKvGc9B"
+)
+
+// testTxs returns a funding transaction and a transaction spending it along
+// with the verbose responses a server would return for them.
+func testTxs() (*wire.MsgTx, *wire.MsgTx, map[string]interface{}) {
+	fundingScript, _ := txscript.PayToAddrScript(mustDecodeAddress(fundingAddr))
+	payeeScript, _ := txscript.PayToAddrScript(mustDecodeAddress(payeeAddr))
+
+	funding := wire.NewMsgTx(1)
+	funding.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{}, 0), []byte{0x51}, nil))
+	funding.AddTxOut(wire.NewTxOut(5000000, fundingScript))
+
+	spend := wire.NewMsgTx(1)
+	fundingHash := funding.TxHash()
+	spend.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&fundingHash, 0), []byte{0x00}, nil))
+	spend.AddTxOut(wire.NewTxOut(4990000, payeeScript))
+
+	verbose := func(tx *wire.MsgTx, confirmations int, addr string) map[string]interface{} {
+		var buf bytes.Buffer
+		tx.Serialize(&buf)
+		var vin []map[string]interface{}
+		for _, in := range tx.TxIn {
+			if in.PreviousOutPoint.Hash == (chainhash.Hash{}) {
+				vin = append(vin, map[string]interface{}{
+					"coinbase": hex.EncodeToString(in.SignatureScript),
+					"sequence": in.Sequence,
+				})
+				continue
+			}
+			vin = append(vin, map[string]interface{}{
+				"txid":      in.PreviousOutPoint.Hash.String(),
+				"vout":      in.PreviousOutPoint.Index,
+				"sequence":  in.Sequence,
+				"scriptSig": map[string]string{"hex": hex.EncodeToString(in.SignatureScript)},
+			})
+		}
+		return map[string]interface{}{
+			"txid":          tx.TxHash().String(),
+			"version":       tx.Version,
+			"locktime":      tx.LockTime,
+			"hex":           hex.EncodeToString(buf.Bytes()),
+			"confirmations": confirmations,
+			"time":          1571000000,
+			"blocktime":     1571000000,
+			"vin":           vin,
+			"vout": []map[string]interface{}{{
+				"value": float64(tx.TxOut[0].Value) / 1e8,
+				"n":     0,
+				"scriptPubKey": map[string]interface{}{
+					"hex":  hex.EncodeToString(tx.TxOut[0].PkScript),
+					"type": "pubkeyhash",
+					// newer nodes report a single address
+					"address": addr,
+				},
+			}},
+		}
+	}
+	return funding, spend, map[string]interface{}{
+		funding.TxHash().String(): verbose(funding, 10, fundingAddr),
+		spend.TxHash().String():   verbose(spend, 0, payeeAddr),
+	}
+}
+
+func handleTransactions(s *fakeServer, txs map[string]interface{}, raw map[string]*wire.MsgTx) {
+	s.handle("blockchain.transaction.get", func(params []json.RawMessage) (interface{}, error) {
+		var txid string
+		json.Unmarshal(params[0], &txid)
+		if len(params) > 1 {
+			tx, ok := txs[txid]
+			if !ok {
+				return nil, errors.New("no such transaction")
+			}
+			return tx, nil
+		}
+		var buf bytes.Buffer
+		raw[txid].Serialize(&buf)
+		return hex.EncodeToString(buf.Bytes()), nil
+	})
+}
+
+func TestNewElectrumClientValidatesEndpoint(t *testing.T) {
+	for _, endpoint := range []string{"http://localhost:50001", "tcp://localhost"} {
+		if _, err := electrum.NewElectrumClient(endpoint, nil); err == nil {
+			t.Errorf("expected error for endpoint %s", endpoint)
+		}
+	}
+	for _, endpoint := range []string{"tcp://localhost:50001", "ssl://localhost:50002", "tls://localhost:50002"} {
+		if _, err := electrum.NewElectrumClient(endpoint, nil); err != nil {
+			t.Errorf("unexpected error for endpoint %s: %s", endpoint, err.Error())
+		}
+	}
+}
+
+func TestScriptHash(t *testing.T) {
+	// Example from the Electrum protocol documentation
+	script, _ := hex.DecodeString("76a91462e907b15cbf27d5425399ebf6f0fb50ebb88f1888ac")
+	expected := "8b01df4e368ea28f8dc0423bcf7a4923e3a12d307c875e47a0cfbf90b5c39161"
+	if sh := electrum.ScriptHash(script); sh != expected {
+		t.Errorf("expected scripthash %s, got %s", expected, sh)
+	}
+}
+
+func TestElectrumClient_GetBestBlock(t *testing.T) {
+	s := mustNewFakeServer(t)
+	defer s.Close()
+	c := mustNewClient(t, s.URL("tcp"))
+	defer c.Close()
+
+	block, err := c.GetBestBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var hdr wire.BlockHeader
+	hdr.Deserialize(bytes.NewReader(testHeader(1)))
+	if block.Hash != hdr.BlockHash().String() {
+		t.Errorf("expected hash %s, got %s", hdr.BlockHash().String(), block.Hash)
+	}
+	if block.PreviousBlockhash != hdr.PrevBlock.String() {
+		t.Errorf("expected previous hash %s, got %s", hdr.PrevBlock.String(), block.PreviousBlockhash)
+	}
+	if block.Height != testTipHeight {
+		t.Errorf("expected height %d, got %d", testTipHeight, block.Height)
+	}
+	if block.Time != hdr.Timestamp.Unix() {
+		t.Errorf("expected time %d, got %d", hdr.Timestamp.Unix(), block.Time)
+	}
+	if n := s.callCount("server.version"); n != 1 {
+		t.Errorf("expected version to be negotiated once, got %d", n)
+	}
+}
+
+func TestElectrumClient_GetUtxos(t *testing.T) {
+	s := mustNewFakeServer(t)
+	defer s.Close()
+	c := mustNewClient(t, s.URL("tcp"))
+	defer c.Close()
+
+	var (
+		addr       = mustDecodeAddress(fundingAddr)
+		scriptHash = mustScriptHash(addr)
+		txid       = "1be612e4f2b79af279e0b307337924072b819b3aca09fcb20370dd9492b83428"
+	)
+	s.handle("blockchain.scripthash.listunspent", func(params []json.RawMessage) (interface{}, error) {
+		var sh string
+		json.Unmarshal(params[0], &sh)
+		if sh != scriptHash {
+			return nil, errors.New("unexpected scripthash")
+		}
+		return []map[string]interface{}{
+			{"tx_hash": txid, "tx_pos": 1, "height": testTipHeight - 2, "value": 4294455},
+			{"tx_hash": txid, "tx_pos": 2, "height": 0, "value": 1000},
+		}, nil
+	})
+
+	utxos, err := c.GetUtxos([]btcutil.Address{addr})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(utxos) != 2 {
+		t.Fatalf("expected 2 utxos, got %d", len(utxos))
+	}
+	if utxos[0].Txid != txid || utxos[0].Vout != 1 || utxos[0].Satoshis != 4294455 {
+		t.Errorf("returned incorrect utxo: %+v", utxos[0])
+	}
+	if utxos[0].Confirmations != 3 {
+		t.Errorf("expected 3 confirmations, got %d", utxos[0].Confirmations)
+	}
+	if utxos[1].Confirmations != 0 {
+		t.Errorf("expected unconfirmed utxo, got %d confirmations", utxos[1].Confirmations)
+	}
+	if utxos[0].Address != fundingAddr {
+		t.Errorf("expected address %s, got %s", fundingAddr, utxos[0].Address)
+	}
+	if utxos[0].ScriptPubKey != "76a91479ce9bb0d9edf53a20cfb63dc449f00e2d63a97488ac" {
+		t.Errorf("returned incorrect script: %s", utxos[0].ScriptPubKey)
+	}
+}
+
+func TestElectrumClient_GetTransaction(t *testing.T) {
+	s := mustNewFakeServer(t)
+	defer s.Close()
+	c := mustNewClient(t, s.URL("tcp"))
+	defer c.Close()
+
+	funding, spend, verbose := testTxs()
+	handleTransactions(s, verbose, map[string]*wire.MsgTx{
+		funding.TxHash().String(): funding,
+		spend.TxHash().String():   spend,
+	})
+
+	tx, err := c.GetTransaction(spend.TxHash().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx.Txid != spend.TxHash().String() {
+		t.Errorf("expected txid %s, got %s", spend.TxHash().String(), tx.Txid)
+	}
+	if len(tx.Inputs) != 1 || tx.Inputs[0].Addr != fundingAddr || tx.Inputs[0].Satoshis != 5000000 {
+		t.Errorf("input was not resolved against its previous output: %+v", tx.Inputs)
+	}
+	if len(tx.Outputs) != 1 || tx.Outputs[0].Value != 0.0499 || tx.Outputs[0].ScriptPubKey.Addresses[0] != payeeAddr {
+		t.Errorf("returned incorrect output: %+v", tx.Outputs)
+	}
+	if tx.Confirmations != 0 || tx.BlockHeight != 0 {
+		t.Errorf("expected unconfirmed transaction")
+	}
+
+	confirmed, err := c.GetTransaction(funding.TxHash().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if confirmed.BlockHeight != testTipHeight-9 {
+		t.Errorf("expected height %d, got %d", testTipHeight-9, confirmed.BlockHeight)
+	}
+
+	raw, err := c.GetRawTransaction(spend.TxHash().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	spend.Serialize(&buf)
+	if !bytes.Equal(raw, buf.Bytes()) {
+		t.Error("returned incorrect raw transaction")
+	}
+}
+
+func TestElectrumClient_GetTransactions(t *testing.T) {
+	s := mustNewFakeServer(t)
+	defer s.Close()
+	c := mustNewClient(t, s.URL("tcp"))
+	defer c.Close()
+
+	funding, spend, verbose := testTxs()
+	handleTransactions(s, verbose, nil)
+	s.handle("blockchain.scripthash.get_history", func(params []json.RawMessage) (interface{}, error) {
+		return []map[string]interface{}{
+			{"tx_hash": funding.TxHash().String(), "height": testTipHeight - 9},
+			{"tx_hash": spend.TxHash().String(), "height": 0},
+		}, nil
+	})
+
+	txs, err := c.GetTransactions([]btcutil.Address{mustDecodeAddress(fundingAddr), mustDecodeAddress(payeeAddr)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txs) != 2 {
+		t.Errorf("expected 2 unique transactions, got %d", len(txs))
+	}
+}
+
+func TestElectrumClient_BroadcastAndEstimateFee(t *testing.T) {
+	s := mustNewFakeServer(t)
+	defer s.Close()
+	c := mustNewClient(t, s.URL("tcp"))
+	defer c.Close()
+
+	_, spend, _ := testTxs()
+	var buf bytes.Buffer
+	spend.Serialize(&buf)
+	s.handle("blockchain.transaction.broadcast", func(params []json.RawMessage) (interface{}, error) {
+		var txHex string
+		json.Unmarshal(params[0], &txHex)
+		if txHex != hex.EncodeToString(buf.Bytes()) {
+			return nil, errors.New("bad transaction")
+		}
+		return spend.TxHash().String(), nil
+	})
+	s.handle("blockchain.estimatefee", func(params []json.RawMessage) (interface{}, error) {
+		var n int
+		json.Unmarshal(params[0], &n)
+		if n > 25 {
+			return -1, nil
+		}
+		return 0.0002, nil
+	})
+
+	txid, err := c.Broadcast(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if txid != spend.TxHash().String() {
+		t.Errorf("expected txid %s, got %s", spend.TxHash().String(), txid)
+	}
+	if _, err := c.Broadcast([]byte{0x00}); err == nil {
+		t.Error("expected rejected broadcast to return an error")
+	}
+
+	fee, err := c.EstimateFee(6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fee != 20000 {
+		t.Errorf("expected fee of 20000, got %d", fee)
+	}
+	if _, err := c.EstimateFee(100); err == nil {
+		t.Error("expected missing estimate to return an error")
+	}
+}
+
+func TestElectrumClient_Notifications(t *testing.T) {
+	s := mustNewFakeServer(t)
+	defer s.Close()
+	c := mustNewClient(t, s.URL("tcp"))
+
+	var (
+		funding, spend, verbose = testTxs()
+		addr                    = mustDecodeAddress(fundingAddr)
+		scriptHash              = mustScriptHash(addr)
+		historyLock             sync.Mutex
+		history                 = []map[string]interface{}{
+			{"tx_hash": funding.TxHash().String(), "height": testTipHeight - 9},
+		}
+	)
+	handleTransactions(s, verbose, nil)
+	s.handle("blockchain.scripthash.subscribe", func(params []json.RawMessage) (interface{}, error) {
+		return "status1", nil
+	})
+	s.handle("blockchain.scripthash.get_history", func(params []json.RawMessage) (interface{}, error) {
+		historyLock.Lock()
+		defer historyLock.Unlock()
+		return history, nil
+	})
+
+	// queued until started
+	c.ListenAddress(addr)
+	closeChan := make(chan error, 1)
+	if err := c.Start(closeChan); err != nil {
+		t.Fatal(err)
+	}
+	if n := s.callCount("blockchain.scripthash.subscribe"); n != 1 {
+		t.Fatalf("expected address to be subscribed on start, got %d subscriptions", n)
+	}
+
+	historyLock.Lock()
+	history = append(history, map[string]interface{}{"tx_hash": spend.TxHash().String(), "height": 0})
+	historyLock.Unlock()
+	s.notify("blockchain.scripthash.subscribe", scriptHash, "status2")
+
+	select {
+	case tx := <-c.TxChannel():
+		if tx.Txid != spend.TxHash().String() {
+			t.Errorf("expected notification for %s, got %s", spend.TxHash().String(), tx.Txid)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for transaction notification")
+	}
+
+	s.notify("blockchain.headers.subscribe", map[string]interface{}{"height": testTipHeight + 1, "hex": hex.EncodeToString(testHeader(2))})
+	select {
+	case b := <-c.BlockChannel():
+		if b.Height != testTipHeight+1 {
+			t.Errorf("expected block at height %d, got %d", testTipHeight+1, b.Height)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for block notification")
+	}
+
+	// losing the server is reported on the close channel
+	s.Close()
+	select {
+	case err := <-closeChan:
+		if err == nil {
+			t.Error("expected error after losing connection")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for connection loss")
+	}
+}
+
+type countingDialer struct {
+	dials int
+}
+
+func (d *countingDialer) Dial(network, addr string) (net.Conn, error) {
+	d.dials++
+	return net.Dial(network, addr)
+}
+
+func TestElectrumClient_UsesProxyDialer(t *testing.T) {
+	s := mustNewFakeServer(t)
+	defer s.Close()
+
+	dialer := new(countingDialer)
+	c, err := electrum.NewElectrumClient(s.URL("tcp"), dialer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if _, err := c.GetBestBlock(); err != nil {
+		t.Fatal(err)
+	}
+	if dialer.dials != 1 {
+		t.Errorf("expected connection through proxy dialer, got %d dials", dialer.dials)
+	}
+}
+
+func TestElectrumClient_TLS(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IsCA:         true,
+
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := newFakeServer(t, l)
+	defer s.Close()
+
+	c := mustNewClient(t, s.URL("ssl"))
+	defer c.Close()
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+	c.TLSConfig = &tls.Config{RootCAs: roots, ServerName: "127.0.0.1"}
+
+	block, err := c.GetBestBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if block.Height != testTipHeight {
+		t.Errorf("expected height %d, got %d", testTipHeight, block.Height)
+	}
+}
+
+func TestClientPoolWithElectrumServer(t *testing.T) {
+	s := mustNewFakeServer(t)
+	defer s.Close()
+
+	p, err := client.NewClientPool([]string{"electrum+" + s.URL("tcp")}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+
+	block, err := p.GetBestBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if block.Height != testTipHeight {
+		t.Errorf("expected height %d, got %d", testTipHeight, block.Height)
+	}
+}
@@ -0,0 +1,200 @@
+package electrum
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+var errConnClosed = errors.New("electrum connection closed")
+
+type rpcRequest struct {
+	JSONRPC string        `json:"jsonrpc"`
+	ID      uint64        `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+// rpcMessage is either a response to one of our requests (ID set) or a
+// subscription notification (Method set).
+type rpcMessage struct {
+	ID     *uint64         `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  json.RawMessage `json:"error"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+}
+
+// RPCError is an error returned by the Electrum server in response to a request
+type RPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("electrum error %d: %s", e.Code, e.Message)
+}
+
+func parseRPCError(raw json.RawMessage) error {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+	var rpcErr RPCError
+	if err := json.Unmarshal(raw, &rpcErr); err == nil {
+		return &rpcErr
+	}
+	// Older servers respond with a bare string
+	var msg string
+	if err := json.Unmarshal(raw, &msg); err == nil {
+		return &RPCError{Message: msg}
+	}
+	return &RPCError{Message: string(raw)}
+}
+
+// rpcConn multiplexes newline delimited JSON-RPC requests over a single
+// connection and dispatches server notifications to notify.
+type rpcConn struct {
+	conn    net.Conn
+	nextID  uint64
+	pending map[uint64]chan rpcMessage
+	lock    sync.Mutex
+	writeMu sync.Mutex
+	done    chan struct{}
+	err     error
+
+	notify  func(method string, params json.RawMessage)
+	onClose func(c *rpcConn, err error)
+}
+
+func newRPCConn(conn net.Conn, notify func(string, json.RawMessage), onClose func(*rpcConn, error)) *rpcConn {
+	c := &rpcConn{
+		conn:    conn,
+		pending: make(map[uint64]chan rpcMessage),
+		done:    make(chan struct{}),
+		notify:  notify,
+		onClose: onClose,
+	}
+	go c.readLoop()
+	return c
+}
+
+func (c *rpcConn) call(method string, timeout time.Duration, result interface{}, params ...interface{}) error {
+	if params == nil {
+		params = []interface{}{}
+	}
+	respChan := make(chan rpcMessage, 1)
+
+	c.lock.Lock()
+	if c.err != nil {
+		c.lock.Unlock()
+		return c.err
+	}
+	c.nextID++
+	id := c.nextID
+	c.pending[id] = respChan
+	c.lock.Unlock()
+
+	defer func() {
+		c.lock.Lock()
+		delete(c.pending, id)
+		c.lock.Unlock()
+	}()
+
+	req, err := json.Marshal(rpcRequest{JSONRPC: "2.0", ID: id, Method: method, Params: params})
+	if err != nil {
+		return err
+	}
+	c.writeMu.Lock()
+	c.conn.SetWriteDeadline(time.Now().Add(timeout))
+	_, err = c.conn.Write(append(req, '\n'))
+	c.writeMu.Unlock()
+	if err != nil {
+		c.close(err)
+		return err
+	}
+
+	var t = time.NewTimer(timeout)
+	defer t.Stop()
+	select {
+	case resp := <-respChan:
+		if err := parseRPCError(resp.Error); err != nil {
+			return err
+		}
+		if result == nil {
+			return nil
+		}
+		if err := json.Unmarshal(resp.Result, result); err != nil {
+			return fmt.Errorf("decoding %s response: %s", method, err.Error())
+		}
+		return nil
+	case <-c.done:
+		return c.closeErr()
+	case <-t.C:
+		return &timeoutError{method}
+	}
+}
+
+func (c *rpcConn) readLoop() {
+	reader := bufio.NewReader(c.conn)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			c.close(err)
+			return
+		}
+		var msg rpcMessage
+		if err := json.Unmarshal(line, &msg); err != nil {
+			Log.Warningf("discarding malformed message: %s", err.Error())
+			continue
+		}
+		if msg.ID == nil {
+			if msg.Method != "" && c.notify != nil {
+				c.notify(msg.Method, msg.Params)
+			}
+			continue
+		}
+		c.lock.Lock()
+		respChan, ok := c.pending[*msg.ID]
+		c.lock.Unlock()
+		if ok {
+			respChan <- msg
+		}
+	}
+}
+
+// close shuts the connection down with err. The first caller wins and
+// onClose is called exactly once.
+func (c *rpcConn) close(err error) {
+	c.lock.Lock()
+	if c.err != nil {
+		c.lock.Unlock()
+		return
+	}
+	if err == nil {
+		err = errConnClosed
+	}
+	c.err = err
+	close(c.done)
+	c.lock.Unlock()
+
+	c.conn.Close()
+	if c.onClose != nil {
+		c.onClose(c, err)
+	}
+}
+
+func (c *rpcConn) closeErr() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.err
+}
+
+type timeoutError struct{ method string }
+
+func (e *timeoutError) Error() string   { return fmt.Sprintf("timed out waiting for %s", e.method) }
+func (e *timeoutError) Timeout() bool   { return true }
+func (e *timeoutError) Temporary() bool { return true }
@@ -1,5 +1,7 @@
 package errors
 
+import "fmt"
+
 type wrappedError interface {
 	error
 	internalError() error
@@ -28,3 +30,16 @@ func IsRetryable(err error) bool {
 	}
 	return ok || iOK
 }
+
+// Wrapf prefixes the message of err with a formatted context, keeping whether
+// err is fatal and retryable
+func Wrapf(err error, format string, args ...interface{}) error {
+	var wrapped error = fmt.Errorf("%s: %s", fmt.Sprintf(format, args...), err.Error())
+	if IsFatal(err) {
+		wrapped = MakeFatal(wrapped)
+	}
+	if IsRetryable(err) {
+		wrapped = MakeRetryable(wrapped)
+	}
+	return wrapped
+}
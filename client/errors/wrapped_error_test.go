@@ -31,3 +31,20 @@ func TestWrappedErrorsAreComposable(t *testing.T) {
 		t.Errorf("expected retryable(fatal(err)) to be fatal but was not")
 	}
 }
+
+func TestWrapfKeepsClassification(t *testing.T) {
+	var baseErr = errors.New("base")
+
+	err := clientErr.Wrapf(clientErr.MakeRetryable(clientErr.MakeFatal(baseErr)), "broadcasting %s", "tx")
+	if err.Error() != "broadcasting tx: base" {
+		t.Errorf("unexpected message %q", err.Error())
+	}
+	if !clientErr.IsFatal(err) || !clientErr.IsRetryable(err) {
+		t.Errorf("expected wrapped fatal retryable error to stay fatal and retryable")
+	}
+
+	err = clientErr.Wrapf(baseErr, "broadcasting tx")
+	if clientErr.IsFatal(err) || clientErr.IsRetryable(err) {
+		t.Errorf("expected wrapped plain error to be neither fatal nor retryable")
+	}
+}
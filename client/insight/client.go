@@ -16,6 +16,7 @@ import (
 
 	gosocketio "github.com/OpenBazaar/golang-socketio"
 	"github.com/OpenBazaar/golang-socketio/protocol"
+	clientErr "github.com/muecoin/multiwallet/client/errors"
 	"github.com/muecoin/multiwallet/client/transport"
 	"github.com/muecoin/multiwallet/model"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
@@ -29,6 +30,8 @@ var Log = logging.MustGetLogger("client")
 type InsightClient struct {
 	apiUrl          url.URL
 	blockNotifyChan chan model.Block
+	closeChan       chan<- error
+	closeLock       sync.Mutex
 	txNotifyChan    chan model.Transaction
 	proxyDialer     proxy.Dialer
 
@@ -70,6 +73,15 @@ func NewInsightClient(apiUrl string, proxyDialer proxy.Dialer) (*InsightClient,
 	return ic, nil
 }
 
+func (i *InsightClient) String() string {
+	return i.apiUrl.Host
+}
+
+func (i *InsightClient) EndpointURL() *url.URL {
+	var u = i.apiUrl
+	return &u
+}
+
 func (i *InsightClient) BlockChannel() chan model.Block {
 	return i.blockNotifyChan
 }
@@ -78,13 +90,44 @@ func (i *InsightClient) TxChannel() chan model.Transaction {
 	return i.txNotifyChan
 }
 
-func (i *InsightClient) Start() error {
-	return i.setupListeners(i.apiUrl, i.proxyDialer)
+// Start connects the websocket listeners. The closeChan receives a single value
+// when the client is closed (nil) or its websocket is lost (non-nil).
+func (i *InsightClient) Start(closeChan chan<- error) error {
+	if err := i.setupListeners(i.apiUrl, i.proxyDialer); err != nil {
+		return err
+	}
+	i.closeLock.Lock()
+	i.closeChan = closeChan
+	i.closeLock.Unlock()
+	return nil
 }
 
 func (i *InsightClient) Close() {
-	if i.SocketClient != nil {
-		i.SocketClient.Close()
+	i.closeLock.Lock()
+	closeChan := i.closeChan
+	i.closeChan = nil
+	i.closeLock.Unlock()
+	// ListenAddress emits on the socket under listenLock, so the socket is
+	// detached under the same lock before it is closed
+	i.listenLock.Lock()
+	socketClient := i.SocketClient
+	i.SocketClient = nil
+	i.listenLock.Unlock()
+	if socketClient != nil {
+		socketClient.Close()
+	}
+	if closeChan != nil {
+		closeChan <- nil
+	}
+}
+
+func (i *InsightClient) sendAndDiscardCloseChan(err error) {
+	i.closeLock.Lock()
+	closeChan := i.closeChan
+	i.closeChan = nil
+	i.closeLock.Unlock()
+	if closeChan != nil {
+		closeChan <- err
 	}
 }
 
@@ -121,6 +164,10 @@ func (i *InsightClient) doRequest(endpoint, method string, body []byte, query ur
 			return nil, err
 		}
 	}
+	if resp.StatusCode >= 500 {
+		err := fmt.Errorf("insight server internal error (%s %s): %s", method, requestUrl.String(), resp.Status)
+		return nil, clientErr.MakeRetryable(clientErr.MakeFatal(err))
+	}
 	if resp.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("status not ok: %s", resp.Status)
 	}
@@ -350,6 +397,10 @@ func (i *InsightClient) setupListeners(u url.URL, proxyDialer proxy.Dialer) erro
 		}
 	}
 
+	i.SocketClient.On(gosocketio.OnDisconnection, func(h *gosocketio.Channel, arg interface{}) {
+		Log.Warningf("websocket disconnected (%s)", i.String())
+		i.sendAndDiscardCloseChan(fmt.Errorf("websocket disconnected (%s)", i.String()))
+	})
 	i.SocketClient.On("bitcoind/hashblock", func(h *gosocketio.Channel, arg interface{}) {
 		best, err := i.GetBestBlock()
 		if err != nil {
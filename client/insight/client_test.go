@@ -536,7 +536,7 @@ func TestInsightClient_setupListeners(t *testing.T) {
 		},
 	)
 
-	go c.Start()
+	go c.Start(nil)
 	time.Sleep(time.Second)
 
 	go func() {
@@ -610,6 +610,33 @@ func TestInsightClient_ListenAddress(t *testing.T) {
 	}
 }
 
+func TestInsightClient_CloseWhileListening(t *testing.T) {
+	var (
+		endpoint   = "http://localhost:8334"
+		c          = MustNewInsightClient(endpoint)
+		mockSocket = mock.NewMockWebsocketClient()
+	)
+	addr, err := btcutil.DecodeAddress("17rxURoF96VhmkcEGCj5LNQkmN9HVhWb7F", &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.SocketClient = mockSocket
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 1000; i++ {
+			c.ListenAddress(addr)
+		}
+		close(done)
+	}()
+	c.Close()
+	<-done
+
+	if c.SocketClient != nil {
+		t.Error("expected the socket to be detached")
+	}
+}
+
 func TestInsightClient_EstimateFee(t *testing.T) {
 	var (
 		endpoint   = "http://localhost:8334"
@@ -4,13 +4,17 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
 	"net/http"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/muecoin/multiwallet/client/blockbook"
 	clientErr "github.com/muecoin/multiwallet/client/errors"
+	"github.com/muecoin/multiwallet/client/insight"
 	"github.com/muecoin/multiwallet/model"
+	"github.com/muecoin/multiwallet/util"
 	"github.com/btcsuite/btcutil"
 	"github.com/op/go-logging"
 	"golang.org/x/net/proxy"
@@ -18,11 +22,16 @@ import (
 
 var Log = logging.MustGetLogger("pool")
 
+// backendProbeInterval is how often the inactive servers are queried for their
+// tip and latency so the rotation manager can rank them
+var backendProbeInterval = 1 * time.Minute
+
 // ClientPool is an implementation of the APIClient interface which will handle
 // server failure, rotate servers, and retry API requests.
 type ClientPool struct {
 	blockChan        chan model.Block
 	cancelListenChan context.CancelFunc
+	cancelProbe      context.CancelFunc
 	listenAddrs      []btcutil.Address
 	listenAddrsLock  sync.Mutex
 	poolManager      *rotationManager
@@ -51,38 +60,80 @@ func (m *maxTryEnum) isFinal() bool {
 
 // NewClientPool instantiates a new ClientPool object with the given server APIs
 func NewClientPool(endpoints []string, proxyDialer proxy.Dialer) (*ClientPool, error) {
+	return NewClientPoolWithOptions(endpoints, nil, proxyDialer)
+}
+
+// OptionMaxBlockLag is the CoinConfig option setting how many blocks a server
+// may trail the best known tip before the pool rotates away from it
+const OptionMaxBlockLag = "MaxBlockLag"
+
+// NewClientPoolWithOptions instantiates a new ClientPool passing the coin's
+// config options to the backends which need them, such as the RPC credentials
+// of a bitcoind endpoint. The pool itself reads OptionMaxBlockLag.
+func NewClientPoolWithOptions(endpoints []string, options map[string]interface{}, proxyDialer proxy.Dialer) (*ClientPool, error) {
 	if len(endpoints) == 0 {
 		return nil, errors.New("no client endpoints provided")
 	}
+	maxBlockLag, err := maxBlockLagOption(options)
+	if err != nil {
+		return nil, err
+	}
 
-	var (
-		pool = &ClientPool{
-			blockChan:    make(chan model.Block),
-			poolManager:  &rotationManager{},
-			listenAddrs:  make([]btcutil.Address, 0),
-			txChan:       make(chan model.Transaction),
-			unblockStart: make(chan struct{}, 1),
-		}
-		manager, err = newRotationManager(endpoints, proxyDialer)
-	)
+	var pool = &ClientPool{
+		blockChan:    make(chan model.Block),
+		poolManager:  &rotationManager{},
+		listenAddrs:  make([]btcutil.Address, 0),
+		txChan:       make(chan model.Transaction),
+		unblockStart: make(chan struct{}, 1),
+	}
+	manager, err := newRotationManager(endpoints, options, proxyDialer)
 	if err != nil {
 		return nil, err
 	}
+	manager.maxBlockLag = maxBlockLag
 	pool.poolManager = manager
 	return pool, nil
 }
 
+func maxBlockLagOption(options map[string]interface{}) (int, error) {
+	lag, err := util.IntOption(options, OptionMaxBlockLag, defaultMaxBlockLag)
+	if err != nil {
+		return 0, err
+	}
+	if lag < 0 || lag > math.MaxInt32 {
+		return 0, fmt.Errorf("invalid %s %d: must not be negative", OptionMaxBlockLag, lag)
+	}
+	return int(lag), nil
+}
+
 // Start will attempt to connect to the first available server. If it fails to
 // connect it will rotate through the servers to try to find one that works.
 func (p *ClientPool) Start() error {
+	var ctx context.Context
+	ctx, p.cancelProbe = context.WithCancel(context.Background())
 	go p.run()
+	go p.probeBackends(ctx)
 	return nil
 }
 
+// Clients returns the Blockbook clients in the pool
 func (p *ClientPool) Clients() []*blockbook.BlockBookClient {
 	var clients []*blockbook.BlockBookClient
 	for _, c := range p.poolManager.clientCache {
-		clients = append(clients, c)
+		if bc, ok := c.(*blockbook.BlockBookClient); ok {
+			clients = append(clients, bc)
+		}
+	}
+	return clients
+}
+
+// InsightClients returns the Insight clients in the pool
+func (p *ClientPool) InsightClients() []*insight.InsightClient {
+	var clients []*insight.InsightClient
+	for _, c := range p.poolManager.clientCache {
+		if ic, ok := c.(*insight.InsightClient); ok {
+			clients = append(clients, ic)
+		}
 	}
 	return clients
 }
@@ -103,7 +154,7 @@ func (p *ClientPool) runLoop() error {
 	var closeChan = make(chan error, 0)
 	defer close(closeChan)
 	if err := p.poolManager.StartCurrent(closeChan); err != nil {
-		Log.Errorf("error starting %s: %s", p.poolManager.currentTarget, err.Error())
+		Log.Errorf("error starting %s: %s", p.poolManager.CurrentTarget(), err.Error())
 		p.poolManager.FailCurrent()
 		p.poolManager.CloseCurrent()
 		return err
@@ -122,6 +173,9 @@ func (p *ClientPool) runLoop() error {
 
 // Close proxies the same request to the active client
 func (p *ClientPool) Close() {
+	if p.cancelProbe != nil {
+		p.cancelProbe()
+	}
 	p.stopWebsocketListening()
 	p.unblockStart <- struct{}{}
 	p.poolManager.CloseCurrent()
@@ -132,6 +186,18 @@ func (p *ClientPool) PoolManager() *rotationManager {
 	return p.poolManager
 }
 
+// BackendStatus returns the rolling latency, error rate and block height of every
+// server in the pool, ordered from the best to the worst scoring.
+func (p *ClientPool) BackendStatus() []BackendStatus {
+	return p.poolManager.Scoreboard()
+}
+
+// SetMaxBlockLag sets how many blocks a server may trail the best known tip
+// before the pool rotates away from it.
+func (p *ClientPool) SetMaxBlockLag(blocks int) {
+	p.poolManager.SetMaxBlockLag(blocks)
+}
+
 // FailAndCloseCurrentClient cleans up the active client's connections, and
 // signals to the rotation manager that it is unhealthy. The internal runLoop
 // will detect the client's closing and attempt to start the next available.
@@ -151,15 +217,16 @@ func (p *ClientPool) stopWebsocketListening() {
 // listenChans proxies the block and tx chans from the client to the ClientPool's channels
 func (p *ClientPool) listenChans(ctx context.Context) {
 	var (
-		client    = p.poolManager.AcquireCurrent()
-		blockChan = client.BlockChannel()
-		txChan    = client.TxChannel()
+		client, target = p.poolManager.acquireCurrentTarget()
+		blockChan      = client.BlockChannel()
+		txChan         = client.TxChannel()
 	)
 	defer p.poolManager.ReleaseCurrent()
 	go func() {
 		for {
 			select {
 			case block := <-blockChan:
+				p.poolManager.UpdateBlockHeight(target, block.Height)
 				p.blockChan <- block
 			case tx := <-txChan:
 				p.txChan <- tx
@@ -177,11 +244,24 @@ func (p *ClientPool) listenChans(ctx context.Context) {
 // can be composed like client/errors.MakeFatal(client/errors.MakeRetryable(err)).
 // This approach should allow individual requests to define how the resulting error
 // should be handled upstream of the request.
-func (p *ClientPool) executeRequest(queryFunc func(c *blockbook.BlockBookClient) error) error {
+func (p *ClientPool) executeRequest(queryFunc func(c backendClient) error) error {
+	return p.executeTargetRequest(func(c backendClient, _ RotationTarget) error {
+		return queryFunc(c)
+	})
+}
+
+// executeTargetRequest is executeRequest for queries which also need the target of the
+// client they are made to.
+func (p *ClientPool) executeTargetRequest(queryFunc func(c backendClient, target RotationTarget) error) error {
 	var err error
 	for e := p.newMaximumTryEnumerator(); e.next(); {
-		var client = p.poolManager.AcquireCurrentWhenReady()
-		if err = queryFunc(client); err != nil {
+		var (
+			client, target = p.poolManager.acquireCurrentTargetWhenReady()
+			start          = time.Now()
+		)
+		err = queryFunc(client, target)
+		p.poolManager.RecordResult(target, time.Since(start), err)
+		if err != nil {
 			p.poolManager.ReleaseCurrent()
 			if clientErr.IsFatal(err) || e.isFinal() {
 				Log.Warningf("rotating server due to fatal or exhausted attempts")
@@ -204,6 +284,43 @@ func (p *ClientPool) executeRequest(queryFunc func(c *blockbook.BlockBookClient)
 	return fmt.Errorf("request failed: %s", err.Error())
 }
 
+// probeBackends periodically measures the latency and tip of the servers which
+// are not currently active. If the active server falls too far behind the best
+// known tip it is failed so the pool rotates to a server that is keeping up.
+func (p *ClientPool) probeBackends(ctx context.Context) {
+	var t = time.NewTicker(backendProbeInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			p.probeOnce()
+			if p.poolManager.CurrentIsLagging() {
+				Log.Warningf("rotating server due to lagging block height")
+				p.FailAndCloseCurrentClient()
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (p *ClientPool) probeOnce() {
+	var current = p.poolManager.CurrentTarget()
+	for target, c := range p.poolManager.clientCache {
+		if target == current {
+			continue
+		}
+		var start = time.Now()
+		block, err := c.GetBestBlock()
+		p.poolManager.RecordResult(target, time.Since(start), err)
+		if err != nil {
+			Log.Debugf("(%s) probe failed: %s", target, err.Error())
+			continue
+		}
+		p.poolManager.UpdateBlockHeight(target, block.Height)
+	}
+}
+
 // BlockNofity proxies the active client's block channel
 func (p *ClientPool) BlockNotify() <-chan model.Block {
 	return p.blockChan
@@ -213,7 +330,7 @@ func (p *ClientPool) BlockNotify() <-chan model.Block {
 func (p *ClientPool) Broadcast(tx []byte) (string, error) {
 	var (
 		txid      string
-		queryFunc = func(c *blockbook.BlockBookClient) error {
+		queryFunc = func(c backendClient) error {
 			Log.Debugf("(%s) broadcasting transaction", c.EndpointURL().String())
 			r, err := c.Broadcast(tx)
 			if err != nil {
@@ -232,7 +349,7 @@ func (p *ClientPool) Broadcast(tx []byte) (string, error) {
 func (p *ClientPool) EstimateFee(nBlocks int) (int, error) {
 	var (
 		fee       int
-		queryFunc = func(c *blockbook.BlockBookClient) error {
+		queryFunc = func(c backendClient) error {
 			Log.Debugf("(%s) requesting fee estimate", c.EndpointURL().String())
 			r, err := c.EstimateFee(nBlocks)
 			if err != nil {
@@ -251,18 +368,19 @@ func (p *ClientPool) EstimateFee(nBlocks int) (int, error) {
 func (p *ClientPool) GetBestBlock() (*model.Block, error) {
 	var (
 		block     *model.Block
-		queryFunc = func(c *blockbook.BlockBookClient) error {
+		queryFunc = func(c backendClient, target RotationTarget) error {
 			Log.Debugf("(%s) request best block info", c.EndpointURL().String())
 			r, err := c.GetBestBlock()
 			if err != nil {
 				return clientErr.MakeRetryable(err)
 			}
+			p.poolManager.UpdateBlockHeight(target, r.Height)
 			block = r
 			return err
 		}
 	)
 
-	err := p.executeRequest(queryFunc)
+	err := p.executeTargetRequest(queryFunc)
 	return block, err
 }
 
@@ -270,7 +388,7 @@ func (p *ClientPool) GetBestBlock() (*model.Block, error) {
 func (p *ClientPool) GetInfo() (*model.Info, error) {
 	var (
 		info      *model.Info
-		queryFunc = func(c *blockbook.BlockBookClient) error {
+		queryFunc = func(c backendClient) error {
 			Log.Debugf("(%s) request backend info", c.EndpointURL().String())
 			r, err := c.GetInfo()
 			if err != nil {
@@ -289,7 +407,7 @@ func (p *ClientPool) GetInfo() (*model.Info, error) {
 func (p *ClientPool) GetRawTransaction(txid string) ([]byte, error) {
 	var (
 		tx        []byte
-		queryFunc = func(c *blockbook.BlockBookClient) error {
+		queryFunc = func(c backendClient) error {
 			Log.Debugf("(%s) request transaction info, txid: %s", c.EndpointURL().String(), txid)
 			r, err := c.GetRawTransaction(txid)
 			if err != nil {
@@ -307,7 +425,7 @@ func (p *ClientPool) GetRawTransaction(txid string) ([]byte, error) {
 func (p *ClientPool) GetTransactions(addrs []btcutil.Address) ([]model.Transaction, error) {
 	var (
 		txs       []model.Transaction
-		queryFunc = func(c *blockbook.BlockBookClient) error {
+		queryFunc = func(c backendClient) error {
 			var addrStrings []string
 			for _, a := range addrs {
 				addrStrings = append(addrStrings, a.String())
@@ -331,7 +449,7 @@ func (p *ClientPool) GetTransactions(addrs []btcutil.Address) ([]model.Transacti
 func (p *ClientPool) GetTransaction(txid string) (*model.Transaction, error) {
 	var (
 		tx        *model.Transaction
-		queryFunc = func(c *blockbook.BlockBookClient) error {
+		queryFunc = func(c backendClient) error {
 			Log.Debugf("(%s) request transaction data, txid: %s", c.EndpointURL().String(), txid)
 			r, err := c.GetTransaction(txid)
 			if err != nil {
@@ -350,7 +468,7 @@ func (p *ClientPool) GetTransaction(txid string) (*model.Transaction, error) {
 func (p *ClientPool) GetUtxos(addrs []btcutil.Address) ([]model.Utxo, error) {
 	var (
 		utxos     []model.Utxo
-		queryFunc = func(c *blockbook.BlockBookClient) error {
+		queryFunc = func(c backendClient) error {
 			var addrStrings []string
 			for _, a := range addrs {
 				addrStrings = append(addrStrings, a.String())
@@ -10,6 +10,7 @@ import (
 	"github.com/muecoin/multiwallet/model"
 	"github.com/muecoin/multiwallet/model/mock"
 	"github.com/muecoin/multiwallet/test/factory"
+	"golang.org/x/net/proxy"
 	"gopkg.in/jarcoal/httpmock.v1"
 )
 
@@ -17,6 +18,9 @@ func replaceHTTPClientOnClientPool(p *client.ClientPool, c http.Client) {
 	for _, cp := range p.Clients() {
 		cp.HTTPClient = c
 	}
+	for _, cp := range p.InsightClients() {
+		cp.HTTPClient = c
+	}
 	p.HTTPClient = c
 }
 
@@ -252,3 +256,209 @@ func TestTransactionNotifyWorksAfterRotation(t *testing.T) {
 	}
 	ticker.Stop()
 }
+
+func TestPoolSelectsLowestLatencyServer(t *testing.T) {
+	var (
+		endpointOne = "http://localhost:8332"
+		endpointTwo = "http://localhost:8336"
+		p, err      = client.NewClientPool([]string{endpointOne, endpointTwo}, nil)
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := p.PoolManager()
+	m.RecordResult(client.RotationTarget(endpointOne), 800*time.Millisecond, nil)
+	m.RecordResult(client.RotationTarget(endpointTwo), 50*time.Millisecond, nil)
+	m.SelectNext()
+
+	status := p.BackendStatus()
+	if len(status) != 2 {
+		t.Fatalf("expected 2 backends in scoreboard, got %d", len(status))
+	}
+	if status[0].Target != client.RotationTarget(endpointTwo) || !status[0].Current {
+		t.Errorf("expected %s to be selected as the fastest server, but was not", endpointTwo)
+	}
+	if status[0].Latency != 50*time.Millisecond {
+		t.Errorf("expected latency of 50ms, got %s", status[0].Latency)
+	}
+}
+
+func TestPoolPenalizesServerErrors(t *testing.T) {
+	var (
+		endpointOne = "http://localhost:8332"
+		endpointTwo = "http://localhost:8336"
+		p, err      = client.NewClientPool([]string{endpointOne, endpointTwo}, nil)
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := p.PoolManager()
+	m.RecordResult(client.RotationTarget(endpointOne), 300*time.Millisecond, nil)
+	m.RecordResult(client.RotationTarget(endpointTwo), 100*time.Millisecond, nil)
+	for i := 0; i < 5; i++ {
+		m.RecordResult(client.RotationTarget(endpointTwo), 100*time.Millisecond, fmt.Errorf("server error"))
+	}
+	m.SelectNext()
+
+	status := p.BackendStatus()
+	if status[0].Target != client.RotationTarget(endpointOne) || !status[0].Current {
+		t.Errorf("expected reliable server %s to be selected, but was not", endpointOne)
+	}
+	if status[1].ErrorRate <= 0 {
+		t.Errorf("expected error rate of %s to be recorded, but was zero", endpointTwo)
+	}
+}
+
+func TestPoolAvoidsLaggingServer(t *testing.T) {
+	var (
+		endpointOne = "http://localhost:8332"
+		endpointTwo = "http://localhost:8336"
+		p, err      = client.NewClientPool([]string{endpointOne, endpointTwo}, nil)
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := p.PoolManager()
+	m.RecordResult(client.RotationTarget(endpointOne), 500*time.Millisecond, nil)
+	m.RecordResult(client.RotationTarget(endpointTwo), 10*time.Millisecond, nil)
+	m.UpdateBlockHeight(client.RotationTarget(endpointOne), 600000)
+	m.UpdateBlockHeight(client.RotationTarget(endpointTwo), 599990)
+	m.SelectNext()
+
+	if m.CurrentIsLagging() {
+		t.Error("expected selected server to be keeping up with the tip")
+	}
+	for _, s := range p.BackendStatus() {
+		switch s.Target {
+		case client.RotationTarget(endpointOne):
+			if !s.Current || !s.Healthy {
+				t.Errorf("expected %s to be the healthy current server", endpointOne)
+			}
+		case client.RotationTarget(endpointTwo):
+			if s.Healthy || s.BlocksBehind != 10 {
+				t.Errorf("expected %s to be unhealthy and 10 blocks behind, got healthy=%t behind=%d", endpointTwo, s.Healthy, s.BlocksBehind)
+			}
+		}
+	}
+
+	p.SetMaxBlockLag(20)
+	for _, s := range p.BackendStatus() {
+		if !s.Healthy {
+			t.Errorf("expected %s to be healthy with a larger lag allowance", s.Target)
+		}
+	}
+}
+
+func TestPoolMaxBlockLagOption(t *testing.T) {
+	var (
+		endpointOne = "http://localhost:8332"
+		endpointTwo = "http://localhost:8336"
+		options     = map[string]interface{}{client.OptionMaxBlockLag: 20}
+		p, err      = client.NewClientPoolWithOptions([]string{endpointOne, endpointTwo}, options, nil)
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := p.PoolManager()
+	m.RecordResult(client.RotationTarget(endpointOne), 500*time.Millisecond, nil)
+	m.RecordResult(client.RotationTarget(endpointTwo), 10*time.Millisecond, nil)
+	m.UpdateBlockHeight(client.RotationTarget(endpointOne), 600000)
+	m.UpdateBlockHeight(client.RotationTarget(endpointTwo), 599990)
+	m.SelectNext()
+
+	for _, s := range p.BackendStatus() {
+		if !s.Healthy {
+			t.Errorf("expected %s to be healthy with a lag allowance of 20 blocks", s.Target)
+		}
+	}
+
+	for _, v := range []interface{}{-1, 2.5, "three", true} {
+		options := map[string]interface{}{client.OptionMaxBlockLag: v}
+		if _, err := client.NewClientPoolWithOptions([]string{endpointOne}, options, nil); err == nil {
+			t.Errorf("expected %s of %v to be rejected", client.OptionMaxBlockLag, v)
+		}
+	}
+}
+
+func TestParseEndpoint(t *testing.T) {
+	var tests = []struct {
+		endpoint string
+		backend  client.BackendType
+		url      string
+		err      bool
+	}{
+		{"https://btc.blockbook.api.openbazaar.org/api", client.BackendBlockbook, "https://btc.blockbook.api.openbazaar.org/api", false},
+		{"blockbook+https://btc.blockbook.api.openbazaar.org/api", client.BackendBlockbook, "https://btc.blockbook.api.openbazaar.org/api", false},
+		{"insight+https://btc.insight.openbazaar.org/insight-api", client.BackendInsight, "https://btc.insight.openbazaar.org/insight-api", false},
+		{"INSIGHT+http://localhost:8334/api", client.BackendInsight, "http://localhost:8334/api", false},
+		{"https://example.com/api?a=b+c", client.BackendBlockbook, "https://example.com/api?a=b+c", false},
+		{"bitcoind+http://127.0.0.1:8332/wallet/watchonly", client.BackendBitcoind, "http://127.0.0.1:8332/wallet/watchonly", false},
+		{"gopher+https://example.com", "", "", true},
+	}
+	for _, test := range tests {
+		backend, u, err := client.ParseEndpoint(test.endpoint)
+		if test.err {
+			if err == nil {
+				t.Errorf("expected error parsing %s, but got none", test.endpoint)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("unexpected error parsing %s: %s", test.endpoint, err.Error())
+			continue
+		}
+		if backend != test.backend || u != test.url {
+			t.Errorf("expected (%s, %s) for %s, got (%s, %s)", test.backend, test.url, test.endpoint, backend, u)
+		}
+	}
+}
+
+func TestNewClientPool_BitcoindBehindProxy(t *testing.T) {
+	for _, endpoint := range []string{"bitcoind+http://127.0.0.1:8332", "bitcoind+http://localhost:8332", "bitcoind+http://[::1]:8332"} {
+		if _, err := client.NewClientPool([]string{endpoint}, proxy.Direct); err != nil {
+			t.Errorf("unexpected error for %s behind a proxy: %s", endpoint, err.Error())
+		}
+	}
+	endpoint := "bitcoind+http://203.0.113.7:8332"
+	if _, err := client.NewClientPool([]string{endpoint}, proxy.Direct); err == nil {
+		t.Errorf("expected %s to be refused behind a proxy", endpoint)
+	}
+	if _, err := client.NewClientPool([]string{endpoint}, nil); err != nil {
+		t.Errorf("unexpected error for %s without a proxy: %s", endpoint, err.Error())
+	}
+}
+
+func TestRequestRotatesFromBlockbookToInsight(t *testing.T) {
+	var (
+		blockbookEndpoint = "http://localhost:8332"
+		insightEndpoint   = "http://localhost:8334"
+		p, cleanup        = mustPrepareClientPool([]string{blockbookEndpoint, "insight+" + insightEndpoint})
+		expectedTx        = factory.NewTransaction()
+		txid              = "1be612e4f2b79af279e0b307337924072b819b3aca09fcb20370dd9492b83428"
+	)
+	defer cleanup()
+
+	if len(p.Clients()) != 1 || len(p.InsightClients()) != 1 {
+		t.Fatalf("expected one blockbook and one insight client, got %d and %d", len(p.Clients()), len(p.InsightClients()))
+	}
+
+	httpmock.RegisterResponder(http.MethodGet, fmt.Sprintf("%s/tx/%s", blockbookEndpoint, txid),
+		func(req *http.Request) (*http.Response, error) {
+			return httpmock.NewJsonResponse(http.StatusInternalServerError, expectedTx)
+		},
+	)
+	httpmock.RegisterResponder(http.MethodGet, fmt.Sprintf("%s/tx/%s", insightEndpoint, txid),
+		func(req *http.Request) (*http.Response, error) {
+			return httpmock.NewJsonResponse(http.StatusOK, expectedTx)
+		},
+	)
+
+	_, err := p.GetTransaction(txid)
+	if err != nil {
+		t.Errorf("expected successful transaction, but got error: %s", err.Error())
+	}
+}
@@ -2,18 +2,61 @@ package client
 
 import (
 	"errors"
+	"sort"
 	"sync"
 	"time"
 
-	"github.com/muecoin/multiwallet/client/blockbook"
 	"golang.org/x/net/proxy"
 )
 
 var maximumBackoff = 60 * time.Second
 
+const (
+	// defaultMaxBlockLag is the number of blocks a target may trail the best
+	// known tip before it is rotated away from
+	defaultMaxBlockLag = 3
+
+	// latencyWeight and errorRateWeight control how quickly the rolling
+	// averages respond to new samples
+	latencyWeight   = 0.3
+	errorRateWeight = 0.1
+
+	// errorPenalty scales the latency score of a target by its error rate so that
+	// a fast but unreliable server ranks below a slower reliable one
+	errorPenalty = 10.0
+)
+
 type healthState struct {
 	lastFailedAt    time.Time
 	backoffDuration time.Duration
+
+	latency     time.Duration
+	errorRate   float64
+	blockHeight int
+	requests    uint64
+}
+
+// record folds the outcome of a single request into the rolling latency and
+// error rate averages
+func (h *healthState) record(latency time.Duration, failed bool) {
+	var failure float64
+	if failed {
+		failure = 1
+	}
+	if h.requests == 0 {
+		h.latency = latency
+		h.errorRate = failure
+	} else {
+		h.latency = time.Duration(latencyWeight*float64(latency) + (1-latencyWeight)*float64(h.latency))
+		h.errorRate = errorRateWeight*failure + (1-errorRateWeight)*h.errorRate
+	}
+	h.requests++
+}
+
+// score ranks the target against its peers. Lower is better and targets
+// which have not served a request yet score zero so they are tried first.
+func (h *healthState) score() float64 {
+	return float64(h.latency) / float64(time.Millisecond) * (1 + errorPenalty*h.errorRate)
 }
 
 func (h *healthState) markUnhealthy() {
@@ -46,21 +89,36 @@ const nilTarget = RotationTarget("")
 type (
 	RotationTarget  string
 	rotationManager struct {
-		clientCache   map[RotationTarget]*blockbook.BlockBookClient
+		clientCache   map[RotationTarget]backendClient
 		currentTarget RotationTarget
 		targetHealth  map[RotationTarget]*healthState
 		rotateLock    sync.RWMutex
+		statsLock     sync.Mutex
+		maxBlockLag   int
 		started       bool
 	}
 )
 
-func newRotationManager(targets []string, proxyDialer proxy.Dialer) (*rotationManager, error) {
+// BackendStatus is a snapshot of the health of a single rotation target
+type BackendStatus struct {
+	Target       RotationTarget
+	Current      bool
+	Healthy      bool
+	Latency      time.Duration
+	ErrorRate    float64
+	BlockHeight  int
+	BlocksBehind int
+	Requests     uint64
+	Score        float64
+}
+
+func newRotationManager(targets []string, options map[string]interface{}, proxyDialer proxy.Dialer) (*rotationManager, error) {
 	var (
 		targetHealth = make(map[RotationTarget]*healthState)
-		clients      = make(map[RotationTarget]*blockbook.BlockBookClient)
+		clients      = make(map[RotationTarget]backendClient)
 	)
 	for _, apiUrl := range targets {
-		c, err := blockbook.NewBlockBookClient(apiUrl, proxyDialer)
+		c, err := newBackendClient(apiUrl, options, proxyDialer)
 		if err != nil {
 			return nil, err
 		}
@@ -71,6 +129,7 @@ func newRotationManager(targets []string, proxyDialer proxy.Dialer) (*rotationMa
 		clientCache:   clients,
 		currentTarget: nilTarget,
 		targetHealth:  targetHealth,
+		maxBlockLag:   defaultMaxBlockLag,
 	}
 	return m, nil
 }
@@ -78,7 +137,14 @@ func newRotationManager(targets []string, proxyDialer proxy.Dialer) (*rotationMa
 // AcquireCurrent locks the current client for reading and returns a pointer to the current
 // client. ReleaseCurrent is required at the end of using the active client to ensure rotation
 // does not lock indefinitely.
-func (r *rotationManager) AcquireCurrent() *blockbook.BlockBookClient {
+func (r *rotationManager) AcquireCurrent() backendClient {
+	client, _ := r.acquireCurrentTarget()
+	return client
+}
+
+// acquireCurrentTarget is AcquireCurrent also returning the target of the client, read while
+// the lock is held.
+func (r *rotationManager) acquireCurrentTarget() (backendClient, RotationTarget) {
 	for {
 		r.rLock()
 		if client, ok := r.clientCache[r.currentTarget]; !ok {
@@ -86,25 +152,45 @@ func (r *rotationManager) AcquireCurrent() *blockbook.BlockBookClient {
 			r.SelectNext()
 			continue
 		} else {
-			return client
+			return client, r.currentTarget
 		}
 	}
 }
 
 // AcquireCurrentWhenReady will block until the current client is ready for use. This method
 // should always be used before the AcquireCurrent variety to minimize time within a read lock.
-func (r *rotationManager) AcquireCurrentWhenReady() *blockbook.BlockBookClient {
-	if r.started {
-		return r.AcquireCurrent()
+func (r *rotationManager) AcquireCurrentWhenReady() backendClient {
+	client, _ := r.acquireCurrentTargetWhenReady()
+	return client
+}
+
+// acquireCurrentTargetWhenReady is AcquireCurrentWhenReady also returning the target of the
+// client, read while the lock is held.
+func (r *rotationManager) acquireCurrentTargetWhenReady() (backendClient, RotationTarget) {
+	if r.isStarted() {
+		return r.acquireCurrentTarget()
 	}
 	var t = time.NewTicker(1 * time.Second)
 	defer t.Stop()
 	for range t.C {
-		if r.started {
+		if r.isStarted() {
 			break
 		}
 	}
-	return r.AcquireCurrent()
+	return r.acquireCurrentTarget()
+}
+
+func (r *rotationManager) isStarted() bool {
+	r.rLock()
+	defer r.rUnlock()
+	return r.started
+}
+
+// CurrentTarget returns the target of the current client
+func (r *rotationManager) CurrentTarget() RotationTarget {
+	r.rLock()
+	defer r.rUnlock()
+	return r.currentTarget
 }
 
 // ReleaseCurrent unlocks the current client for reading and cleans up outstanding resources as
@@ -163,29 +249,158 @@ func (r *rotationManager) FailCurrent() {
 	}
 }
 
-// SelectNext finds the next healthy and available server to activate with StartCurrent. This call will
-// block until a server is healthy and available.
+// SelectNext finds the best scoring healthy and available server to activate with StartCurrent.
+// Servers which trail the best known tip by more than the maximum block lag are only used when
+// no other server is available. This call will block until a server is healthy and available.
 func (r *rotationManager) SelectNext() {
 	r.lock()
 	defer r.unlock()
 
 	if r.currentTarget == nilTarget {
-		var nextAvailableAt time.Time
 		for {
-			if time.Now().Before(nextAvailableAt) {
-				continue
+			target, nextAvailableAt := r.bestTarget()
+			if target != nilTarget {
+				r.currentTarget = target
+				return
+			}
+			time.Sleep(time.Until(nextAvailableAt))
+		}
+	}
+}
+
+// bestTarget returns the healthy target with the lowest score, preferring targets which are
+// not lagging behind the tip. When no target is healthy it returns nilTarget and the time at
+// which the first target becomes available again.
+func (r *rotationManager) bestTarget() (RotationTarget, time.Time) {
+	r.statsLock.Lock()
+	defer r.statsLock.Unlock()
+
+	var (
+		best, bestLagging           = nilTarget, nilTarget
+		bestScore, bestLaggingScore float64
+		nextAvailableAt             time.Time
+		tip                         = r.bestHeight()
+	)
+	for target, health := range r.targetHealth {
+		if !health.isHealthy() {
+			if nextAvailableAt.IsZero() || health.nextAvailable().Before(nextAvailableAt) {
+				nextAvailableAt = health.nextAvailable()
 			}
-			for target, health := range r.targetHealth {
-				if health.isHealthy() {
-					r.currentTarget = target
-					return
-				}
-				if health.nextAvailable().After(nextAvailableAt) {
-					nextAvailableAt = health.nextAvailable()
-				}
+			continue
+		}
+		score := health.score()
+		if r.isLagging(health, tip) {
+			if bestLagging == nilTarget || score < bestLaggingScore || (score == bestLaggingScore && target < bestLagging) {
+				bestLagging, bestLaggingScore = target, score
 			}
+			continue
+		}
+		if best == nilTarget || score < bestScore || (score == bestScore && target < best) {
+			best, bestScore = target, score
 		}
 	}
+	if best == nilTarget {
+		best = bestLagging
+	}
+	return best, nextAvailableAt
+}
+
+// RecordResult folds the latency and outcome of a request made against target into its
+// rolling health statistics.
+func (r *rotationManager) RecordResult(target RotationTarget, latency time.Duration, err error) {
+	r.statsLock.Lock()
+	defer r.statsLock.Unlock()
+
+	if hs, ok := r.targetHealth[target]; ok {
+		hs.record(latency, err != nil)
+	}
+}
+
+// UpdateBlockHeight records the tip reported by target.
+func (r *rotationManager) UpdateBlockHeight(target RotationTarget, height int) {
+	r.statsLock.Lock()
+	defer r.statsLock.Unlock()
+
+	if hs, ok := r.targetHealth[target]; ok && height > hs.blockHeight {
+		hs.blockHeight = height
+	}
+}
+
+// SetMaxBlockLag sets the number of blocks a target may trail the best known tip before it
+// is considered to be lagging.
+func (r *rotationManager) SetMaxBlockLag(blocks int) {
+	r.statsLock.Lock()
+	defer r.statsLock.Unlock()
+	r.maxBlockLag = blocks
+}
+
+// CurrentIsLagging returns true if the active target trails the best known tip by more than
+// the maximum block lag.
+func (r *rotationManager) CurrentIsLagging() bool {
+	r.rLock()
+	defer r.rUnlock()
+	r.statsLock.Lock()
+	defer r.statsLock.Unlock()
+
+	hs, ok := r.targetHealth[r.currentTarget]
+	if !ok {
+		return false
+	}
+	return r.isLagging(hs, r.bestHeight())
+}
+
+// Scoreboard returns the status of every target ordered from best to worst score.
+func (r *rotationManager) Scoreboard() []BackendStatus {
+	r.rLock()
+	defer r.rUnlock()
+	r.statsLock.Lock()
+	defer r.statsLock.Unlock()
+
+	var (
+		tip    = r.bestHeight()
+		status = make([]BackendStatus, 0, len(r.targetHealth))
+	)
+	for target, health := range r.targetHealth {
+		var behind int
+		if health.blockHeight > 0 {
+			behind = tip - health.blockHeight
+		}
+		status = append(status, BackendStatus{
+			Target:       target,
+			Current:      target == r.currentTarget,
+			Healthy:      health.isHealthy() && !r.isLagging(health, tip),
+			Latency:      health.latency,
+			ErrorRate:    health.errorRate,
+			BlockHeight:  health.blockHeight,
+			BlocksBehind: behind,
+			Requests:     health.requests,
+			Score:        health.score(),
+		})
+	}
+	sort.Slice(status, func(i, j int) bool {
+		if status[i].Score == status[j].Score {
+			return status[i].Target < status[j].Target
+		}
+		return status[i].Score < status[j].Score
+	})
+	return status
+}
+
+// bestHeight returns the highest tip reported by any target. statsLock must be held.
+func (r *rotationManager) bestHeight() int {
+	var tip int
+	for _, health := range r.targetHealth {
+		if health.blockHeight > tip {
+			tip = health.blockHeight
+		}
+	}
+	return tip
+}
+
+// isLagging reports whether health trails tip by more than the maximum block lag. Targets
+// which have not reported a height yet are not considered lagging. statsLock must be held.
+func (r *rotationManager) isLagging(health *healthState, tip int) bool {
+	return health.blockHeight > 0 && tip-health.blockHeight > r.maxBlockLag
 }
 
 func (r *rotationManager) lock() {
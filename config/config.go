@@ -49,7 +49,8 @@ type Config struct {
 }
 
 type CoinConfig struct {
-	// The type of coin to configure
+	// The type of coin to configure. The coin must be registered with the
+	// registry package, which importing its package does.
 	CoinType util.ExtCoinType
 
 	// The default fee-per-byte for each level
@@ -57,22 +58,51 @@ type CoinConfig struct {
 	MediumFee uint64
 	HighFee   uint64
 
-	// The highest allowable fee-per-byte
+	// The highest allowable fee-per-byte. The Ethereum wallet prices gas from its
+	// node and caps the fee per gas at MaxFee gwei.
 	MaxFee uint64
 
+	// The model used to price transactions. If nil the Zcash wallet uses the
+	// ZIP-317 conventional fee. The other coins charge a fee per byte and their
+	// constructors return an error for any model but util.SizeFeeModel; the
+	// Ethereum wallet accepts none.
+	FeeModel util.FeeModel
+
 	// External API to query to look up fees. If this field is nil then the default fees will be used.
 	// If the API is unreachable then the default fees will likewise be used. If the API returns a fee
 	// greater than MaxFee then the MaxFee will be used in place. The API response must be formatted as
 	// { "fastestFee": 40, "halfHourFee": 20, "hourFee": 10 }
 	FeeAPI string
 
+	// The price APIs whose quotes are aggregated into the exchange rates of the coin. Each entry
+	// declares its API with a scheme prefix, for example "coingecko+https://api.coingecko.com/api/v3/coins/litecoin"
+	// or "kraken+https://api.kraken.com/0/public/Ticker?pair=LTCXBT". If empty the default providers
+	// of the coin are used.
+	PriceAPIs []string
+
 	// The trusted APIs to use for querying for balances and listening to blockchain events.
+	// Each entry may declare its server type with a scheme prefix, for example
+	// "insight+https://example.com/api", "electrum+ssl://example.com:50002" or
+	// "bitcoind+http://127.0.0.1:8332". Entries without a prefix are Blockbook
+	// servers. The RPC credentials of bitcoind endpoints are read from the
+	// RPCUser and RPCPassword Options, and with a proxy only bitcoind
+	// endpoints on localhost are accepted. The Ethereum wallet takes the URLs
+	// of JSON-RPC nodes.
 	ClientAPIs []string
 
-	// An implementation of the Datastore interface for each desired coin
+	// An implementation of the Datastore interface for each desired coin. The
+	// spend request IDs, transaction metadata and rate history of the UTXO
+	// wallets are only available if it also implements datastore.RecordStore.
 	DB wallet.Datastore
 
-	// Custom options for wallet to use
+	// Custom options for wallet to use. The Zcash wallet reads ExpiryDelta, the
+	// number of blocks after which its unmined transactions expire (default 40,
+	// 0 disables expiry). The Bitcoin Cash wallet reads SignatureScheme, either
+	// "ecdsa" (default) or "schnorr". The Bitcoin wallet reads AddressType,
+	// either "p2pkh" (default) or "p2tr" for BIP86 taproot keys. The client
+	// pool of every coin but Ethereum reads MaxBlockLag, the number of blocks
+	// a server may trail the best known tip before it is rotated away from
+	// (default 3).
 	Options map[string]interface{}
 }
 
@@ -117,14 +147,14 @@ func NewDefaultConfig(coinTypes map[wallet.CoinType]bool, params *chaincfg.Param
 			apiEndpoints = []string{
 				"https://btc.blockbook.api.openbazaar.org/api",
 				// temporarily deprecated Insight endpoints
-				//"https://btc.bloqapi.net/insight-api",
-				//"https://btc.insight.openbazaar.org/insight-api",
+				//"insight+https://btc.bloqapi.net/insight-api",
+				//"insight+https://btc.insight.openbazaar.org/insight-api",
 			}
 		} else {
 			apiEndpoints = []string{
 				"https://tbtc.blockbook.api.openbazaar.org/api",
 				// temporarily deprecated Insight endpoints
-				//"https://test-insight.bitpay.com/api",
+				//"insight+https://test-insight.bitpay.com/api",
 			}
 		}
 		feeApi := "https://btc.fees.openbazaar.org"
@@ -147,13 +177,13 @@ func NewDefaultConfig(coinTypes map[wallet.CoinType]bool, params *chaincfg.Param
 			apiEndpoints = []string{
 				"https://bch.blockbook.api.openbazaar.org/api",
 				// temporarily deprecated Insight endpoints
-				//"https://bitcoincash.blockexplorer.com/api",
+				//"insight+https://bitcoincash.blockexplorer.com/api",
 			}
 		} else {
 			apiEndpoints = []string{
 				"https://tbch.blockbook.api.openbazaar.org/api",
 				// temporarily deprecated Insight endpoints
-				//"https://test-bch-insight.bitpay.com/api",
+				//"insight+https://test-bch-insight.bitpay.com/api",
 			}
 		}
 		db, _ := mockDB.GetDatastoreForWallet(wallet.BitcoinCash)
@@ -175,13 +205,13 @@ func NewDefaultConfig(coinTypes map[wallet.CoinType]bool, params *chaincfg.Param
 			apiEndpoints = []string{
 				"https://zec.blockbook.api.openbazaar.org/api",
 				// temporarily deprecated Insight endpoints
-				//"https://zcashnetwork.info/api",
+				//"insight+https://zcashnetwork.info/api",
 			}
 		} else {
 			apiEndpoints = []string{
 				"https://tzec.blockbook.api.openbazaar.org/api",
 				// temporarily deprecated Insight endpoints
-				//"https://explorer.testnet.z.cash/api",
+				//"insight+https://explorer.testnet.z.cash/api",
 			}
 		}
 		db, _ := mockDB.GetDatastoreForWallet(wallet.Zcash)
@@ -203,14 +233,14 @@ func NewDefaultConfig(coinTypes map[wallet.CoinType]bool, params *chaincfg.Param
 			apiEndpoints = []string{
 				"https://ltc.blockbook.api.openbazaar.org/api",
 				// temporarily deprecated Insight endpoints
-				//"https://ltc.coin.space/api",
-				//"https://ltc.insight.openbazaar.org/insight-lite-api",
+				//"insight+https://ltc.coin.space/api",
+				//"insight+https://ltc.insight.openbazaar.org/insight-lite-api",
 			}
 		} else {
 			apiEndpoints = []string{
 				"https://tltc.blockbook.api.openbazaar.org/api",
 				// temporarily deprecated Insight endpoints
-				//"https://testnet.litecore.io/api",
+				//"insight+https://testnet.litecore.io/api",
 			}
 		}
 		db, _ := mockDB.GetDatastoreForWallet(wallet.Litecoin)
@@ -226,6 +256,29 @@ func NewDefaultConfig(coinTypes map[wallet.CoinType]bool, params *chaincfg.Param
 		}
 		cfg.Coins = append(cfg.Coins, ltcCfg)
 	}
+	if coinTypes[util.CoinTypeDogecoin.ToCoinType()] {
+		var apiEndpoints []string
+		if !testnet {
+			apiEndpoints = []string{
+				"https://doge1.trezor.io/api",
+				"https://doge2.trezor.io/api",
+			}
+		}
+		// There is no public Blockbook for the Dogecoin testnet. Testnet
+		// wallets must be configured with their own ClientAPIs.
+		db, _ := mockDB.GetDatastoreForWallet(util.CoinTypeDogecoin.ToCoinType())
+		dogeCfg := CoinConfig{
+			CoinType:   util.CoinTypeDogecoin,
+			FeeAPI:     "",
+			LowFee:     1000,
+			MediumFee:  1500,
+			HighFee:    2500,
+			MaxFee:     100000,
+			ClientAPIs: apiEndpoints,
+			DB:         db,
+		}
+		cfg.Coins = append(cfg.Coins, dogeCfg)
+	}
 	if coinTypes[wallet.Ethereum] {
 		var apiEndpoints []string
 		if !testnet {
@@ -21,6 +21,7 @@ type MockDatastore struct {
 	stxos          wallet.Stxos
 	txns           wallet.Txns
 	watchedScripts wallet.WatchedScripts
+	records        Records
 }
 
 type MockMultiwalletDatastore struct {
@@ -46,6 +47,15 @@ func NewMockMultiwalletDatastore() *MockMultiwalletDatastore {
 		&MockStxoStore{stxos: make(map[string]*wallet.Stxo)},
 		&MockTxnStore{txns: make(map[string]*txnStoreEntry)},
 		&MockWatchedScriptsStore{scripts: make(map[string][]byte)},
+		&MockRecords{records: make(map[string][]byte)},
+	})
+	db[wallet.Bitcoin] = wallet.Datastore(&MockDatastore{
+		&MockKeyStore{Keys: make(map[string]*KeyStoreEntry)},
+		&MockUtxoStore{utxos: make(map[string]*wallet.Utxo)},
+		&MockStxoStore{stxos: make(map[string]*wallet.Stxo)},
+		&MockTxnStore{txns: make(map[string]*txnStoreEntry)},
+		&MockWatchedScriptsStore{scripts: make(map[string][]byte)},
+		&MockRecords{records: make(map[string][]byte)},
 	})
 	db[wallet.BitcoinCash] = wallet.Datastore(&MockDatastore{
 		&MockKeyStore{Keys: make(map[string]*KeyStoreEntry)},
@@ -53,6 +63,7 @@ func NewMockMultiwalletDatastore() *MockMultiwalletDatastore {
 		&MockStxoStore{stxos: make(map[string]*wallet.Stxo)},
 		&MockTxnStore{txns: make(map[string]*txnStoreEntry)},
 		&MockWatchedScriptsStore{scripts: make(map[string][]byte)},
+		&MockRecords{records: make(map[string][]byte)},
 	})
 	db[wallet.Zcash] = wallet.Datastore(&MockDatastore{
 		&MockKeyStore{Keys: make(map[string]*KeyStoreEntry)},
@@ -60,6 +71,7 @@ func NewMockMultiwalletDatastore() *MockMultiwalletDatastore {
 		&MockStxoStore{stxos: make(map[string]*wallet.Stxo)},
 		&MockTxnStore{txns: make(map[string]*txnStoreEntry)},
 		&MockWatchedScriptsStore{scripts: make(map[string][]byte)},
+		&MockRecords{records: make(map[string][]byte)},
 	})
 	db[wallet.Litecoin] = wallet.Datastore(&MockDatastore{
 		&MockKeyStore{Keys: make(map[string]*KeyStoreEntry)},
@@ -67,6 +79,15 @@ func NewMockMultiwalletDatastore() *MockMultiwalletDatastore {
 		&MockStxoStore{stxos: make(map[string]*wallet.Stxo)},
 		&MockTxnStore{txns: make(map[string]*txnStoreEntry)},
 		&MockWatchedScriptsStore{scripts: make(map[string][]byte)},
+		&MockRecords{records: make(map[string][]byte)},
+	})
+	db[util.CoinTypeDogecoin.ToCoinType()] = wallet.Datastore(&MockDatastore{
+		&MockKeyStore{Keys: make(map[string]*KeyStoreEntry)},
+		&MockUtxoStore{utxos: make(map[string]*wallet.Utxo)},
+		&MockStxoStore{stxos: make(map[string]*wallet.Stxo)},
+		&MockTxnStore{txns: make(map[string]*txnStoreEntry)},
+		&MockWatchedScriptsStore{scripts: make(map[string][]byte)},
+		&MockRecords{records: make(map[string][]byte)},
 	})
 	db[wallet.Ethereum] = wallet.Datastore(&MockDatastore{
 		&MockKeyStore{Keys: make(map[string]*KeyStoreEntry)},
@@ -74,6 +95,7 @@ func NewMockMultiwalletDatastore() *MockMultiwalletDatastore {
 		&MockStxoStore{stxos: make(map[string]*wallet.Stxo)},
 		&MockTxnStore{txns: make(map[string]*txnStoreEntry)},
 		&MockWatchedScriptsStore{scripts: make(map[string][]byte)},
+		&MockRecords{records: make(map[string][]byte)},
 	})
 	return &MockMultiwalletDatastore{db: db}
 }
@@ -98,6 +120,10 @@ func (m *MockDatastore) WatchedScripts() wallet.WatchedScripts {
 	return m.watchedScripts
 }
 
+func (m *MockDatastore) Records() Records {
+	return m.records
+}
+
 type KeyStoreEntry struct {
 	ScriptAddress []byte
 	Path          wallet.KeyPath
@@ -326,8 +352,19 @@ type txnStoreEntry struct {
 	watchOnly bool
 }
 
+func (t *txnStoreEntry) toTxn(txid string) wallet.Txn {
+	return wallet.Txn{
+		Txid:      txid,
+		Value:     int64(t.value),
+		Height:    int32(t.height),
+		Timestamp: t.timestamp,
+		WatchOnly: t.watchOnly,
+	}
+}
+
 type MockTxnStore struct {
-	txns map[string]*txnStoreEntry
+	txns  map[string]*txnStoreEntry
+	index TxnIndex
 	sync.Mutex
 }
 
@@ -341,6 +378,7 @@ func (m *MockTxnStore) Put(tx []byte, txid string, value, height int, timestamp
 		timestamp: timestamp,
 		watchOnly: watchOnly,
 	}
+	m.index.Put(m.txns[txid].toTxn(txid))
 	return nil
 }
 
@@ -379,6 +417,20 @@ func (m *MockTxnStore) GetAll(includeWatchOnly bool) ([]wallet.Txn, error) {
 	return txns, nil
 }
 
+// Query implements TxnQuerier by paging through the index of the store.
+// Transactions are filtered before their serialization is copied.
+func (m *MockTxnStore) Query(q TxnQuery) ([]wallet.Txn, string, error) {
+	return QueryIndex(q, func(after *wallet.Txn, n int) ([]wallet.Txn, bool) {
+		m.Lock()
+		defer m.Unlock()
+		page, more := m.index.Page(q, after, n)
+		for i := range page {
+			page[i].Bytes = m.txns[page[i].Txid].txn
+		}
+		return page, more
+	})
+}
+
 func (m *MockTxnStore) UpdateHeight(txid chainhash.Hash, height int, timestamp time.Time) error {
 	m.Lock()
 	defer m.Unlock()
@@ -389,6 +441,7 @@ func (m *MockTxnStore) UpdateHeight(txid chainhash.Hash, height int, timestamp t
 	txn.height = height
 	txn.timestamp = timestamp
 	m.txns[txid.String()] = txn
+	m.index.Put(txn.toTxn(txid.String()))
 	return nil
 }
 
@@ -400,6 +453,7 @@ func (m *MockTxnStore) Delete(txid *chainhash.Hash) error {
 		return errors.New("Not found")
 	}
 	delete(m.txns, txid.String())
+	m.index.Delete(txid.String())
 	return nil
 }
 
@@ -436,3 +490,29 @@ func (m *MockWatchedScriptsStore) Delete(scriptPubKey []byte) error {
 	delete(m.scripts, enc)
 	return nil
 }
+
+type MockRecords struct {
+	records map[string][]byte
+	sync.Mutex
+}
+
+func NewMockRecords() *MockRecords {
+	return &MockRecords{records: make(map[string][]byte)}
+}
+
+func (m *MockRecords) Get(key string) ([]byte, error) {
+	m.Lock()
+	defer m.Unlock()
+	value, ok := m.records[key]
+	if !ok {
+		return nil, ErrNoRecord
+	}
+	return append([]byte(nil), value...), nil
+}
+
+func (m *MockRecords) Put(key string, value []byte) error {
+	m.Lock()
+	defer m.Unlock()
+	m.records[key] = append([]byte(nil), value...)
+	return nil
+}
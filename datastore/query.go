@@ -0,0 +1,278 @@
+package datastore
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/OpenBazaar/wallet-interface"
+)
+
+// ErrInvalidCursor is returned when a query continues from a cursor no query
+// returned.
+var ErrInvalidCursor = errors.New("invalid transaction cursor")
+
+// TxnQuery selects a page of transactions ordered from the most recent. Zero
+// bounds don't filter. Unconfirmed transactions are left out when a height
+// bound is set.
+type TxnQuery struct {
+	From, To             time.Time
+	MinHeight, MaxHeight int32
+	IncludeWatchOnly     bool
+
+	// MinValue is the minimum absolute value of the transactions
+	MinValue int64
+
+	// Match, if set, is applied to the transactions passing the other filters
+	Match func(txn wallet.Txn) bool
+
+	// Cursor is the cursor returned with the previous page, or empty for the
+	// first page.
+	Cursor string
+
+	// Limit is the maximum number of transactions to return. Zero returns all
+	// of them.
+	Limit int
+}
+
+// TxnQuerier is implemented by transaction stores that can select a page of
+// transactions without loading them all.
+type TxnQuerier interface {
+	// Query returns the transactions selected by q and the cursor of the next
+	// page, which is empty after the last page.
+	Query(q TxnQuery) ([]wallet.Txn, string, error)
+}
+
+// QueryTxns queries store, filtering every transaction of the store if it
+// doesn't implement TxnQuerier. Stores keeping a TxnIndex implement
+// TxnQuerier with QueryIndex.
+func QueryTxns(store wallet.Txns, q TxnQuery) ([]wallet.Txn, string, error) {
+	if querier, ok := store.(TxnQuerier); ok {
+		return querier.Query(q)
+	}
+	txns, err := store.GetAll(q.IncludeWatchOnly)
+	if err != nil {
+		return nil, "", err
+	}
+	return PageTxns(txns, q)
+}
+
+// PageTxns selects the page of txns queried by q
+func PageTxns(txns []wallet.Txn, q TxnQuery) ([]wallet.Txn, string, error) {
+	var (
+		after     wallet.Txn
+		hasCursor bool
+	)
+	if q.Cursor != "" {
+		var err error
+		if after, err = parseTxnCursor(q.Cursor); err != nil {
+			return nil, "", err
+		}
+		hasCursor = true
+	}
+	sorted := make([]wallet.Txn, len(txns))
+	copy(sorted, txns)
+	sort.Slice(sorted, func(i, j int) bool {
+		return txnBefore(sorted[i], sorted[j])
+	})
+	var page []wallet.Txn
+	for _, txn := range sorted {
+		if hasCursor && !txnBefore(after, txn) {
+			continue
+		}
+		if !q.Matches(txn) {
+			continue
+		}
+		if q.Limit > 0 && len(page) == q.Limit {
+			return page, TxnCursor(page[len(page)-1]), nil
+		}
+		page = append(page, txn)
+	}
+	return page, "", nil
+}
+
+// Matches returns whether txn passes the filters of q
+func (q TxnQuery) Matches(txn wallet.Txn) bool {
+	return q.matchesBounds(txn) && (q.Match == nil || q.Match(txn))
+}
+
+// matchesBounds returns whether txn passes the filters of q other than Match
+func (q TxnQuery) matchesBounds(txn wallet.Txn) bool {
+	if txn.WatchOnly && !q.IncludeWatchOnly {
+		return false
+	}
+	if !q.From.IsZero() && txn.Timestamp.Before(q.From) {
+		return false
+	}
+	if !q.To.IsZero() && txn.Timestamp.After(q.To) {
+		return false
+	}
+	if (q.MinHeight > 0 || q.MaxHeight > 0) && txn.Height <= 0 {
+		return false
+	}
+	if q.MinHeight > 0 && txn.Height < q.MinHeight {
+		return false
+	}
+	if q.MaxHeight > 0 && txn.Height > q.MaxHeight {
+		return false
+	}
+	if q.MinValue > 0 && txn.Value < q.MinValue && -txn.Value < q.MinValue {
+		return false
+	}
+	return true
+}
+
+// queryBatch is the number of transactions QueryIndex reads from an index at
+// once
+const queryBatch = 100
+
+// TxnIndex keeps transactions in query order so that a store can page
+// through them without sorting them all. It holds the transactions without
+// their serialization. The zero value is an empty index. A TxnIndex is not
+// safe for concurrent use.
+type TxnIndex struct {
+	sorted []wallet.Txn
+	byID   map[string]wallet.Txn
+}
+
+// Put adds txn to the index or updates it
+func (x *TxnIndex) Put(txn wallet.Txn) {
+	if x.byID == nil {
+		x.byID = make(map[string]wallet.Txn)
+	}
+	x.Delete(txn.Txid)
+	txn.Bytes = nil
+	i := x.search(txn)
+	x.sorted = append(x.sorted, wallet.Txn{})
+	copy(x.sorted[i+1:], x.sorted[i:])
+	x.sorted[i] = txn
+	x.byID[txn.Txid] = txn
+}
+
+// Delete removes the transaction txid from the index
+func (x *TxnIndex) Delete(txid string) {
+	txn, ok := x.byID[txid]
+	if !ok {
+		return
+	}
+	i := x.search(txn)
+	x.sorted = append(x.sorted[:i], x.sorted[i+1:]...)
+	delete(x.byID, txid)
+}
+
+// Len returns the number of transactions in the index
+func (x *TxnIndex) Len() int {
+	return len(x.sorted)
+}
+
+// Page returns up to n transactions following after, or from the most recent
+// if after is nil, which pass the filters of q other than Match. It also
+// returns whether the index may hold more of them. The time bounds of q are
+// looked up rather than filtered.
+func (x *TxnIndex) Page(q TxnQuery, after *wallet.Txn, n int) ([]wallet.Txn, bool) {
+	start := 0
+	if after != nil {
+		start = sort.Search(len(x.sorted), func(i int) bool {
+			return txnBefore(*after, x.sorted[i])
+		})
+	}
+	if !q.To.IsZero() {
+		// The most recent transaction no later than To
+		if i := sort.Search(len(x.sorted), func(i int) bool {
+			return !x.sorted[i].Timestamp.After(q.To)
+		}); i > start {
+			start = i
+		}
+	}
+	var page []wallet.Txn
+	for _, txn := range x.sorted[start:] {
+		if !q.From.IsZero() && txn.Timestamp.Before(q.From) {
+			return page, false
+		}
+		if !q.matchesBounds(txn) {
+			continue
+		}
+		if len(page) == n {
+			return page, true
+		}
+		page = append(page, txn)
+	}
+	return page, false
+}
+
+// search returns the position of txn in the index, or where it belongs
+func (x *TxnIndex) search(txn wallet.Txn) int {
+	return sort.Search(len(x.sorted), func(i int) bool {
+		return !txnBefore(x.sorted[i], txn)
+	})
+}
+
+// QueryIndex selects the page of transactions queried by q from a store
+// keeping a TxnIndex. page is called with the lock of the store held and
+// returns the transactions of TxnIndex.Page with their serialization. Match
+// is applied after it returns, so it runs without the lock.
+func QueryIndex(q TxnQuery, page func(after *wallet.Txn, n int) ([]wallet.Txn, bool)) ([]wallet.Txn, string, error) {
+	var after *wallet.Txn
+	if q.Cursor != "" {
+		txn, err := parseTxnCursor(q.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		after = &txn
+	}
+	n := queryBatch
+	if q.Limit >= n {
+		n = q.Limit + 1
+	}
+	var matched []wallet.Txn
+	for {
+		batch, more := page(after, n)
+		for i, txn := range batch {
+			after = &batch[i]
+			if q.Match != nil && !q.Match(txn) {
+				continue
+			}
+			if q.Limit > 0 && len(matched) == q.Limit {
+				return matched, TxnCursor(matched[len(matched)-1]), nil
+			}
+			matched = append(matched, txn)
+		}
+		if !more {
+			return matched, "", nil
+		}
+	}
+}
+
+// TxnCursor returns the cursor of the page following txn
+func TxnCursor(txn wallet.Txn) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%s", txn.Timestamp.UnixNano(), txn.Txid)))
+}
+
+func parseTxnCursor(cursor string) (wallet.Txn, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return wallet.Txn{}, ErrInvalidCursor
+	}
+	parts := strings.SplitN(string(b), ":", 2)
+	if len(parts) != 2 {
+		return wallet.Txn{}, ErrInvalidCursor
+	}
+	ts, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return wallet.Txn{}, ErrInvalidCursor
+	}
+	return wallet.Txn{Txid: parts[1], Timestamp: time.Unix(0, ts)}, nil
+}
+
+// txnBefore returns whether a comes before b in query order, from the most
+// recent with ties broken by txid.
+func txnBefore(a, b wallet.Txn) bool {
+	if !a.Timestamp.Equal(b.Timestamp) {
+		return a.Timestamp.After(b.Timestamp)
+	}
+	return a.Txid > b.Txid
+}
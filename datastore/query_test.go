@@ -0,0 +1,174 @@
+package datastore
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/OpenBazaar/wallet-interface"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+)
+
+func TestMockTxnStore_Query(t *testing.T) {
+	store := &MockTxnStore{txns: make(map[string]*txnStoreEntry)}
+	start := time.Unix(1700000000, 0)
+	for i := 0; i < 10; i++ {
+		value := 1000 * (i + 1)
+		if i%2 == 1 {
+			value = -value
+		}
+		if err := store.Put([]byte{byte(i)}, strconv.Itoa(i), value, 100+i, start.Add(time.Duration(i)*time.Hour), i == 9); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Page through every transaction but the watch-only one
+	var (
+		all    []wallet.Txn
+		cursor string
+	)
+	for pages := 0; ; pages++ {
+		if pages > 3 {
+			t.Fatal("Paged past the last page")
+		}
+		page, next, err := QueryTxns(store, TxnQuery{Limit: 4, Cursor: cursor})
+		if err != nil {
+			t.Fatal(err)
+		}
+		all = append(all, page...)
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	if len(all) != 9 {
+		t.Fatalf("Expected 9 transactions but had %d", len(all))
+	}
+	for i, txn := range all {
+		if txn.Txid != strconv.Itoa(8-i) {
+			t.Errorf("Expected tx %d at position %d but had %s", 8-i, i, txn.Txid)
+		}
+		if len(txn.Bytes) != 1 || txn.Bytes[0] != byte(8-i) {
+			t.Errorf("Returned incorrect bytes for tx %s", txn.Txid)
+		}
+	}
+
+	page, next, err := QueryTxns(store, TxnQuery{
+		From:             start.Add(2 * time.Hour),
+		MaxHeight:        108,
+		MinValue:         4000,
+		IncludeWatchOnly: true,
+		Match: func(txn wallet.Txn) bool {
+			return txn.Value < 0
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next != "" {
+		t.Error("Returned a cursor without a limit")
+	}
+	if len(page) != 3 || page[0].Txid != "7" || page[1].Txid != "5" || page[2].Txid != "3" {
+		t.Errorf("Returned incorrect transactions %v", page)
+	}
+
+	// Match runs without the lock of the store, so it may use the store
+	page, _, err = QueryTxns(store, TxnQuery{
+		To: start.Add(5 * time.Hour),
+		Match: func(txn wallet.Txn) bool {
+			_, err := store.GetAll(false)
+			return err == nil
+		},
+		Limit: 2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page) != 2 || page[0].Txid != "5" || page[1].Txid != "4" {
+		t.Errorf("Returned incorrect transactions %v", page)
+	}
+
+	if _, _, err := QueryTxns(store, TxnQuery{Cursor: "not a cursor"}); err != ErrInvalidCursor {
+		t.Errorf("Expected ErrInvalidCursor but had %v", err)
+	}
+}
+
+func TestMockTxnStore_QueryIndex(t *testing.T) {
+	store := &MockTxnStore{txns: make(map[string]*txnStoreEntry)}
+	start := time.Unix(1700000000, 0)
+	n := 3*queryBatch + 7
+	txids := make([]chainhash.Hash, n)
+	positions := make(map[string]int)
+	for i := range txids {
+		txids[i] = chainhash.Hash{byte(i), byte(i >> 8)}
+		positions[txids[i].String()] = i
+		if err := store.Put([]byte{byte(i)}, txids[i].String(), 1000, 100, start.Add(time.Duration(i)*time.Minute), false); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Confirm the oldest transaction last and drop the most recent one
+	if err := store.UpdateHeight(txids[0], 200, start.Add(time.Duration(n)*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Delete(&txids[n-1]); err != nil {
+		t.Fatal(err)
+	}
+	if store.index.Len() != n-1 {
+		t.Fatalf("Expected %d indexed transactions but had %d", n-1, store.index.Len())
+	}
+
+	// Only every tenth transaction matches, so pages span several batches
+	var (
+		all    []wallet.Txn
+		cursor string
+	)
+	for {
+		page, next, err := QueryTxns(store, TxnQuery{
+			Match: func(txn wallet.Txn) bool {
+				return positions[txn.Txid]%10 == 0
+			},
+			Cursor: cursor,
+			Limit:  7,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		all = append(all, page...)
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	if len(all) != n/10+1 {
+		t.Fatalf("Expected %d transactions but had %d", n/10+1, len(all))
+	}
+	if all[0].Txid != txids[0].String() || all[0].Height != 200 {
+		t.Errorf("Expected the updated transaction first but had %+v", all[0])
+	}
+	for i, txn := range all[1:] {
+		if positions[txn.Txid] != (n/10-i)*10 {
+			t.Errorf("Expected tx %d at position %d but had %d", (n/10-i)*10, i+1, positions[txn.Txid])
+		}
+	}
+}
+
+func TestTxnQuery_Matches(t *testing.T) {
+	unconfirmed := wallet.Txn{Txid: "a", Height: 0, Value: 500, Timestamp: time.Now()}
+	if !(TxnQuery{}).Matches(unconfirmed) {
+		t.Error("Empty query filtered a transaction")
+	}
+	if (TxnQuery{MinHeight: 1}).Matches(unconfirmed) {
+		t.Error("Height bound matched an unconfirmed transaction")
+	}
+	if (TxnQuery{MinValue: 501}).Matches(unconfirmed) {
+		t.Error("Matched a transaction below the minimum value")
+	}
+	unconfirmed.Value = -600
+	if !(TxnQuery{MinValue: 501}).Matches(unconfirmed) {
+		t.Error("Minimum value does not apply to the absolute value")
+	}
+	unconfirmed.WatchOnly = true
+	if (TxnQuery{}).Matches(unconfirmed) {
+		t.Error("Matched a watch-only transaction")
+	}
+}
@@ -0,0 +1,28 @@
+package datastore
+
+import "errors"
+
+var (
+	// ErrNoRecord is returned when getting a record which was never put
+	ErrNoRecord = errors.New("record not found")
+
+	// ErrNoRecordStore is returned by the wallet features needing records
+	// when the datastore does not implement RecordStore
+	ErrNoRecordStore = errors.New("the datastore does not keep records, which spend request IDs, transaction metadata and the rate history need")
+)
+
+// Records holds the state a wallet keeps beside the wallet-interface stores,
+// such as spend requests and transaction metadata. Unlike the cache, which
+// may be lost at any time, records must be kept as long as the transactions.
+type Records interface {
+	// Get returns the value of key, or ErrNoRecord if there is none
+	Get(key string) ([]byte, error)
+
+	// Put sets the value of key
+	Put(key string, value []byte) error
+}
+
+// RecordStore is implemented by datastores which keep Records
+type RecordStore interface {
+	Records() Records
+}
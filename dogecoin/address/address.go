@@ -0,0 +1,223 @@
+package address
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcutil/base58"
+	"golang.org/x/crypto/ripemd160"
+)
+
+var (
+	// ErrChecksumMismatch describes an error where decoding failed due
+	// to a bad checksum.
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+
+	// ErrUnknownAddressType describes an error where an address can not
+	// decoded as a specific address type due to the string encoding
+	// begining with an identifier byte unknown to the network.
+	ErrUnknownAddressType = errors.New("unknown address type")
+
+	// ErrInvalidFormat describes an error where decoding failed due to invalid version
+	ErrInvalidFormat = errors.New("invalid format: version and/or checksum bytes missing")
+
+	// NetIDs holds the address version bytes of each network keyed by the
+	// name of the bitcoin params the wallet is configured with.
+	NetIDs map[string]NetID
+)
+
+type NetID struct {
+	AddressPubKeyHash byte
+	AddressScriptHash byte
+}
+
+func init() {
+	NetIDs = make(map[string]NetID)
+	NetIDs[chaincfg.MainNetParams.Name] = NetID{0x1e, 0x16}
+	NetIDs[chaincfg.TestNet3Params.Name] = NetID{0x71, 0xc4}
+	NetIDs[chaincfg.RegressionNetParams.Name] = NetID{0x6f, 0xc4}
+}
+
+func netID(net *chaincfg.Params) (NetID, error) {
+	id, ok := NetIDs[net.Name]
+	if !ok {
+		return NetID{}, errors.New("unknown network parameters")
+	}
+	return id, nil
+}
+
+// DecodeAddress decodes the string encoding of an address and returns
+// the Address if addr is a valid encoding for a known address type of the
+// dogecoin network of defaultNet.
+func DecodeAddress(addr string, defaultNet *chaincfg.Params) (btcutil.Address, error) {
+	checkID, err := netID(defaultNet)
+	if err != nil {
+		return nil, err
+	}
+	decoded, version, err := base58.CheckDecode(addr)
+	if err != nil {
+		if err == base58.ErrChecksum {
+			return nil, ErrChecksumMismatch
+		}
+		return nil, ErrInvalidFormat
+	}
+	if len(decoded) != ripemd160.Size {
+		return nil, errors.New("decoded address is of unknown size")
+	}
+	switch version {
+	case checkID.AddressPubKeyHash:
+		return NewAddressPubKeyHash(decoded, defaultNet)
+	case checkID.AddressScriptHash:
+		return NewAddressScriptHashFromHash(decoded, defaultNet)
+	default:
+		return nil, ErrUnknownAddressType
+	}
+}
+
+// AddressPubKeyHash is an Address for a pay-to-pubkey-hash (P2PKH)
+// transaction.
+type AddressPubKeyHash struct {
+	hash  [ripemd160.Size]byte
+	netID byte
+}
+
+// NewAddressPubKeyHash returns a new AddressPubKeyHash.  pkHash must be 20
+// bytes.
+func NewAddressPubKeyHash(pkHash []byte, net *chaincfg.Params) (*AddressPubKeyHash, error) {
+	if len(pkHash) != ripemd160.Size {
+		return nil, errors.New("pkHash must be 20 bytes")
+	}
+	id, err := netID(net)
+	if err != nil {
+		return nil, err
+	}
+	addr := &AddressPubKeyHash{netID: id.AddressPubKeyHash}
+	copy(addr.hash[:], pkHash)
+	return addr, nil
+}
+
+// EncodeAddress returns the string encoding of a pay-to-pubkey-hash
+// address.  Part of the Address interface.
+func (a *AddressPubKeyHash) EncodeAddress() string {
+	return base58.CheckEncode(a.hash[:], a.netID)
+}
+
+// ScriptAddress returns the bytes to be included in a txout script to pay
+// to a pubkey hash.  Part of the Address interface.
+func (a *AddressPubKeyHash) ScriptAddress() []byte {
+	return a.hash[:]
+}
+
+// IsForNet returns whether or not the pay-to-pubkey-hash address is associated
+// with the passed dogecoin network.
+func (a *AddressPubKeyHash) IsForNet(net *chaincfg.Params) bool {
+	id, err := netID(net)
+	return err == nil && a.netID == id.AddressPubKeyHash
+}
+
+// String returns a human-readable string for the pay-to-pubkey-hash address.
+func (a *AddressPubKeyHash) String() string {
+	return a.EncodeAddress()
+}
+
+// Hash160 returns the underlying array of the pubkey hash.
+func (a *AddressPubKeyHash) Hash160() *[ripemd160.Size]byte {
+	return &a.hash
+}
+
+// AddressScriptHash is an Address for a pay-to-script-hash (P2SH)
+// transaction.
+type AddressScriptHash struct {
+	hash  [ripemd160.Size]byte
+	netID byte
+}
+
+// NewAddressScriptHash returns a new AddressScriptHash.
+func NewAddressScriptHash(serializedScript []byte, net *chaincfg.Params) (*AddressScriptHash, error) {
+	return NewAddressScriptHashFromHash(btcutil.Hash160(serializedScript), net)
+}
+
+// NewAddressScriptHashFromHash returns a new AddressScriptHash.  scriptHash
+// must be 20 bytes.
+func NewAddressScriptHashFromHash(scriptHash []byte, net *chaincfg.Params) (*AddressScriptHash, error) {
+	if len(scriptHash) != ripemd160.Size {
+		return nil, errors.New("scriptHash must be 20 bytes")
+	}
+	id, err := netID(net)
+	if err != nil {
+		return nil, err
+	}
+	addr := &AddressScriptHash{netID: id.AddressScriptHash}
+	copy(addr.hash[:], scriptHash)
+	return addr, nil
+}
+
+// EncodeAddress returns the string encoding of a pay-to-script-hash
+// address.  Part of the Address interface.
+func (a *AddressScriptHash) EncodeAddress() string {
+	return base58.CheckEncode(a.hash[:], a.netID)
+}
+
+// ScriptAddress returns the bytes to be included in a txout script to pay
+// to a script hash.  Part of the Address interface.
+func (a *AddressScriptHash) ScriptAddress() []byte {
+	return a.hash[:]
+}
+
+// IsForNet returns whether or not the pay-to-script-hash address is associated
+// with the passed dogecoin network.
+func (a *AddressScriptHash) IsForNet(net *chaincfg.Params) bool {
+	id, err := netID(net)
+	return err == nil && a.netID == id.AddressScriptHash
+}
+
+// String returns a human-readable string for the pay-to-script-hash address.
+func (a *AddressScriptHash) String() string {
+	return a.EncodeAddress()
+}
+
+// Hash160 returns the underlying array of the script hash.
+func (a *AddressScriptHash) Hash160() *[ripemd160.Size]byte {
+	return &a.hash
+}
+
+// PayToAddrScript creates a new script to pay a transaction output to a the
+// specified address.
+func PayToAddrScript(addr btcutil.Address) ([]byte, error) {
+	const nilAddrErrStr = "unable to generate payment script for nil address"
+
+	switch addr := addr.(type) {
+	case *AddressPubKeyHash:
+		if addr == nil {
+			return nil, errors.New(nilAddrErrStr)
+		}
+		return txscript.NewScriptBuilder().AddOp(txscript.OP_DUP).AddOp(txscript.OP_HASH160).
+			AddData(addr.ScriptAddress()).AddOp(txscript.OP_EQUALVERIFY).AddOp(txscript.OP_CHECKSIG).
+			Script()
+
+	case *AddressScriptHash:
+		if addr == nil {
+			return nil, errors.New(nilAddrErrStr)
+		}
+		return txscript.NewScriptBuilder().AddOp(txscript.OP_HASH160).AddData(addr.ScriptAddress()).
+			AddOp(txscript.OP_EQUAL).Script()
+	}
+	return nil, fmt.Errorf("unable to generate payment script for unsupported "+
+		"address type %T", addr)
+}
+
+// ExtractPkScriptAddrs returns the address paid by a P2PKH or P2SH output
+// script. Dogecoin has no segwit so these are the only standard scripts
+// paying to an address.
+func ExtractPkScriptAddrs(pkScript []byte, chainParams *chaincfg.Params) (btcutil.Address, error) {
+	if len(pkScript) == 1+1+20+1 && pkScript[0] == txscript.OP_HASH160 && pkScript[1] == txscript.OP_DATA_20 && pkScript[22] == txscript.OP_EQUAL {
+		return NewAddressScriptHashFromHash(pkScript[2:22], chainParams)
+	} else if len(pkScript) == 1+1+1+20+1+1 && pkScript[0] == txscript.OP_DUP && pkScript[1] == txscript.OP_HASH160 &&
+		pkScript[2] == txscript.OP_DATA_20 && pkScript[23] == txscript.OP_EQUALVERIFY && pkScript[24] == txscript.OP_CHECKSIG {
+		return NewAddressPubKeyHash(pkScript[3:23], chainParams)
+	}
+	return nil, errors.New("unknown script type")
+}
@@ -0,0 +1,97 @@
+package address
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+var dataElement = []byte{203, 72, 18, 50, 41, 156, 213, 116, 49, 81, 172, 75, 45, 99, 174, 25, 142, 123, 176, 169}
+
+var dataElement2 = []byte{118, 160, 64, 83, 189, 160, 168, 139, 218, 81, 119, 184, 106, 21, 195, 178, 159, 85, 152, 115}
+
+func TestDecodeDogecoinAddress(t *testing.T) {
+	tests := []struct {
+		addr   string
+		params *chaincfg.Params
+	}{
+		{"DPfx3nZMidTmMR81WVLZmN7UCM4qY2F33t", &chaincfg.MainNetParams},
+		{"A3FWNUmzq8fXceLoG8DM7PAxPQbNUjae2B", &chaincfg.MainNetParams},
+		{"nnj1moJGebvVEPhCYJz21mhmSDT8ZBuq9p", &chaincfg.TestNet3Params},
+		{"2N44ThNe8NXHyv4bsX8AoVCXquBRW94Ls7W", &chaincfg.TestNet3Params},
+	}
+	for _, test := range tests {
+		addr, err := DecodeAddress(test.addr, test.params)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if addr.String() != test.addr {
+			t.Errorf("Expected %s but had %s", test.addr, addr)
+		}
+		if !addr.IsForNet(test.params) {
+			t.Errorf("Address %s isn't for %s", test.addr, test.params.Name)
+		}
+	}
+	// A mainnet address on testnet
+	if _, err := DecodeAddress("DPfx3nZMidTmMR81WVLZmN7UCM4qY2F33t", &chaincfg.TestNet3Params); err != ErrUnknownAddressType {
+		t.Errorf("Expected ErrUnknownAddressType but had %v", err)
+	}
+	if _, err := DecodeAddress("DPfx3nZMidTmMR81WVLZmN7UCM4qY2F33u", &chaincfg.MainNetParams); err != ErrChecksumMismatch {
+		t.Errorf("Expected ErrChecksumMismatch but had %v", err)
+	}
+}
+
+func TestAddressPubKeyHash_EncodeAddress(t *testing.T) {
+	addr, err := NewAddressPubKeyHash(dataElement, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Error(err)
+	}
+	if addr.String() != "DPfx3nZMidTmMR81WVLZmN7UCM4qY2F33t" {
+		t.Error("Address encoding error")
+	}
+	addr, err = NewAddressPubKeyHash(dataElement, &chaincfg.TestNet3Params)
+	if err != nil {
+		t.Error(err)
+	}
+	if addr.String() != "nnj1moJGebvVEPhCYJz21mhmSDT8ZBuq9p" {
+		t.Error("Address encoding error")
+	}
+}
+
+func TestAddressScriptHash_EncodeAddress(t *testing.T) {
+	addr, err := NewAddressScriptHashFromHash(dataElement2, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Error(err)
+	}
+	if addr.String() != "A3FWNUmzq8fXceLoG8DM7PAxPQbNUjae2B" {
+		t.Error("Address encoding error")
+	}
+	addr, err = NewAddressScriptHashFromHash(dataElement2, &chaincfg.TestNet3Params)
+	if err != nil {
+		t.Error(err)
+	}
+	if addr.String() != "2N44ThNe8NXHyv4bsX8AoVCXquBRW94Ls7W" {
+		t.Error("Address encoding error")
+	}
+}
+
+func TestScriptParsing(t *testing.T) {
+	for _, s := range []string{"DPfx3nZMidTmMR81WVLZmN7UCM4qY2F33t", "A3FWNUmzq8fXceLoG8DM7PAxPQbNUjae2B"} {
+		addr, err := DecodeAddress(s, &chaincfg.MainNetParams)
+		if err != nil {
+			t.Fatal(err)
+		}
+		script, err := PayToAddrScript(addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		addr2, err := ExtractPkScriptAddrs(script, &chaincfg.MainNetParams)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if addr.String() != addr2.String() {
+			t.Error("Failed to convert script back into address")
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package dogecoin
+
+// Relay policy of Dogecoin Core 1.14, in koinu. Dogecoin fees are two orders
+// of magnitude above the bitcoin defaults assumed by btcwallet's txrules, so
+// the wallet applies these rules instead.
+const (
+	// KoinuPerDoge is the number of koinu, the smallest unit, in one DOGE
+	KoinuPerDoge = 100000000
+
+	// DustLimit is the smallest output the wallet creates, 0.01 DOGE. Nodes
+	// only relay transactions with smaller outputs if they pay the dust limit
+	// again as fee for each of them.
+	DustLimit = KoinuPerDoge / 100
+
+	// MinRelayFeePerKB is the lowest fee rate nodes relay, 0.001 DOGE per
+	// kilobyte.
+	MinRelayFeePerKB = KoinuPerDoge / 1000
+
+	// blocksPerHour is the number of blocks mined in an hour at Dogecoin's
+	// one minute target spacing.
+	blocksPerHour = 60
+)
+
+// isDust reports whether an output of amount is below the dust limit
+func isDust(amount int64) bool {
+	return amount < DustLimit
+}
+
+// relayFeePerByte returns feePerByte raised to the minimum relay fee rate
+func relayFeePerByte(feePerByte uint64) uint64 {
+	if min := uint64(MinRelayFeePerKB / 1000); feePerByte < min {
+		return min
+	}
+	return feePerByte
+}
@@ -0,0 +1,981 @@
+package dogecoin
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg"
+
+	"github.com/OpenBazaar/spvwallet"
+	wi "github.com/OpenBazaar/wallet-interface"
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	btc "github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcutil/coinset"
+	hd "github.com/btcsuite/btcutil/hdkeychain"
+	"github.com/btcsuite/btcutil/txsort"
+	"github.com/btcsuite/btcwallet/wallet/txauthor"
+	"github.com/btcsuite/btcwallet/wallet/txrules"
+
+	daddr "github.com/muecoin/multiwallet/dogecoin/address"
+	"github.com/muecoin/multiwallet/htlc"
+	"github.com/muecoin/multiwallet/multisig"
+	"github.com/muecoin/multiwallet/util"
+)
+
+func (w *DogecoinWallet) buildTx(amount int64, addr btc.Address, feeLevel wi.FeeLevel, optionalOutput *wire.TxOut) (*wire.MsgTx, error) {
+	return w.buildBatchTx([]wi.TransactionOutput{{Address: addr, Value: amount}}, feeLevel, optionalOutput)
+}
+
+// buildBatchTx builds a transaction paying every payment and optionalOutput
+// if set.
+func (w *DogecoinWallet) buildBatchTx(payments []wi.TransactionOutput, feeLevel wi.FeeLevel, optionalOutput *wire.TxOut) (*wire.MsgTx, error) {
+	plan, err := w.planBatchTx(payments, feeLevel, optionalOutput)
+	if err != nil {
+		return nil, err
+	}
+	return w.signPlan(plan)
+}
+
+func (w *DogecoinWallet) planBatchTx(payments []wi.TransactionOutput, feeLevel wi.FeeLevel, optionalOutput *wire.TxOut) (*util.SpendPlan, error) {
+	if len(payments) == 0 {
+		return nil, errors.New("no payments to send")
+	}
+	var outputs []*wire.TxOut
+	for _, payment := range payments {
+		// Check for dust
+		script, err := daddr.PayToAddrScript(payment.Address)
+		if err != nil {
+			return nil, err
+		}
+		if isDust(payment.Value) {
+			return nil, wi.ErrorDustAmount
+		}
+		outputs = append(outputs, wire.NewTxOut(payment.Value, script))
+	}
+	if optionalOutput != nil {
+		outputs = append(outputs, optionalOutput)
+	}
+	return w.planTxWithOutputs(outputs, feeLevel)
+}
+
+// planTxWithOutputs selects the coins paying outputs at feeLevel and adds a
+// change output unless the change is dust.
+func (w *DogecoinWallet) planTxWithOutputs(outputs []*wire.TxOut, feeLevel wi.FeeLevel) (*util.SpendPlan, error) {
+	var prevOuts map[wire.OutPoint]*wire.TxOut
+
+	// Create input source
+	height, _ := w.ws.ChainTip()
+	utxos, err := w.db.Utxos().GetAll()
+	if err != nil {
+		return nil, err
+	}
+	coinMap := util.GatherCoins(height, utxos, w.ScriptToAddress, w.km.GetKeyForScript)
+
+	coins := make([]coinset.Coin, 0, len(coinMap))
+	for k := range coinMap {
+		coins = append(coins, k)
+	}
+	inputSource := func(target btc.Amount) (total btc.Amount, inputs []*wire.TxIn, inputValues []btc.Amount, scripts [][]byte, err error) {
+		coinSelector := coinset.MaxValueAgeCoinSelector{MaxInputs: 10000, MinChangeAmount: btc.Amount(0)}
+		coins, err := coinSelector.CoinSelect(target, coins)
+		if err != nil {
+			return total, inputs, inputValues, scripts, wi.ErrorInsuffientFunds
+		}
+		prevOuts = make(map[wire.OutPoint]*wire.TxOut)
+		for _, c := range coins.Coins() {
+			total += c.Value()
+			outpoint := wire.NewOutPoint(c.Hash(), c.Index())
+			in := wire.NewTxIn(outpoint, []byte{}, [][]byte{})
+			in.Sequence = 0 // Opt-in RBF so we can bump fees
+			inputs = append(inputs, in)
+			prevOuts[*outpoint] = wire.NewTxOut(int64(c.Value()), c.PkScript())
+		}
+		return total, inputs, inputValues, scripts, nil
+	}
+
+	// Get the fee per kilobyte
+	feePerKB := int64(w.GetFeePerByte(feeLevel)) * 1000
+
+	// Create change source
+	var changeScript []byte
+	changeSource := func() ([]byte, error) {
+		addr := w.CurrentAddress(wi.INTERNAL)
+		script, err := daddr.PayToAddrScript(addr)
+		if err != nil {
+			return []byte{}, err
+		}
+		changeScript = script
+		return script, nil
+	}
+
+	authoredTx, err := newUnsignedTransaction(outputs, btc.Amount(feePerKB), inputSource, changeSource)
+	if err != nil {
+		return nil, err
+	}
+
+	// BIP 69 sorting
+	txsort.InPlaceSort(authoredTx.Tx)
+
+	plan, err := util.NewSpendPlan(authoredTx.Tx, prevOuts, changeScript, w.ScriptToAddress)
+	if err != nil {
+		return nil, err
+	}
+	plan.FeePerByte = w.GetFeePerByte(feeLevel)
+	plan.VSize = EstimateSerializeSize(len(plan.Inputs), authoredTx.Tx.TxOut, false, P2PKH)
+	if authoredTx.ChangeIndex < 0 {
+		requiredFee := txrules.FeeForSerializeSize(btc.Amount(feePerKB), EstimateSerializeSize(len(plan.Inputs), outputs, true, P2PKH))
+		plan.DroppedChange = plan.Fee - int64(requiredFee)
+	}
+	return plan, nil
+}
+
+func (w *DogecoinWallet) buildSpendAllTx(addr btc.Address, feeLevel wi.FeeLevel, optionalOutput *wire.TxOut) (*wire.MsgTx, error) {
+	plan, err := w.planSpendAllTx(addr, feeLevel, optionalOutput)
+	if err != nil {
+		return nil, err
+	}
+	return w.signPlan(plan)
+}
+
+func (w *DogecoinWallet) planSpendAllTx(addr btc.Address, feeLevel wi.FeeLevel, optionalOutput *wire.TxOut) (*util.SpendPlan, error) {
+	tx := wire.NewMsgTx(1)
+
+	height, _ := w.ws.ChainTip()
+	utxos, err := w.db.Utxos().GetAll()
+	if err != nil {
+		return nil, err
+	}
+	coinMap := util.GatherCoins(height, utxos, w.ScriptToAddress, w.km.GetKeyForScript)
+
+	totalIn, inVals, additionalPrevScripts, _ := util.LoadAllInputs(tx, coinMap, w.params)
+	prevOuts := make(map[wire.OutPoint]*wire.TxOut)
+	for op, script := range additionalPrevScripts {
+		prevOuts[op] = wire.NewTxOut(inVals[op], script)
+	}
+
+	// outputs
+	script, err := daddr.PayToAddrScript(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs := []*wire.TxOut{wire.NewTxOut(0, script)}
+	if optionalOutput != nil {
+		outputs = append(outputs, optionalOutput)
+	}
+
+	// Get the fee
+	feePerByte := int64(w.GetFeePerByte(feeLevel))
+	estimatedSize := EstimateSerializeSize(len(tx.TxIn), outputs, false, P2PKH)
+	fee := int64(estimatedSize) * feePerByte
+
+	// Check for dust output
+	if isDust(totalIn - fee) {
+		return nil, wi.ErrorDustAmount
+	}
+
+	// Build the output
+	out := wire.NewTxOut(totalIn-fee, script)
+	tx.TxOut = append(tx.TxOut, out)
+	if optionalOutput != nil {
+		tx.TxOut = append(tx.TxOut, optionalOutput)
+	}
+
+	// BIP 69 sorting
+	txsort.InPlaceSort(tx)
+
+	plan, err := util.NewSpendPlan(tx, prevOuts, nil, w.ScriptToAddress)
+	if err != nil {
+		return nil, err
+	}
+	plan.FeePerByte = uint64(feePerByte)
+	plan.VSize = EstimateSerializeSize(len(tx.TxIn), tx.TxOut, false, P2PKH)
+	return plan, nil
+}
+
+// signPlan signs the transaction of plan. It fails if the wallet can no
+// longer spend the inputs of the plan.
+func (w *DogecoinWallet) signPlan(plan *util.SpendPlan) (*wire.MsgTx, error) {
+	height, _ := w.ws.ChainTip()
+	utxos, err := w.db.Utxos().GetAll()
+	if err != nil {
+		return nil, err
+	}
+	coinMap, err := plan.Coins(util.GatherCoins(height, utxos, w.ScriptToAddress, w.km.GetKeyForScript))
+	if err != nil {
+		return nil, err
+	}
+	_, _, additionalPrevScripts, additionalKeysByAddress := util.LoadAllInputs(wire.NewMsgTx(1), coinMap, w.params)
+
+	// Sign. The scripts are parsed with the bitcoin params so the keys are
+	// looked up by their bitcoin encoded addresses.
+	tx := plan.Tx()
+	getKey := txscript.KeyClosure(func(addr btc.Address) (*btcec.PrivateKey, bool, error) {
+		addrStr := addr.EncodeAddress()
+		wif, ok := additionalKeysByAddress[addrStr]
+		if !ok {
+			return nil, false, errors.New("key not found")
+		}
+		return wif.PrivKey, wif.CompressPubKey, nil
+	})
+	getScript := txscript.ScriptClosure(func(
+		addr btc.Address) ([]byte, error) {
+		return []byte{}, nil
+	})
+	for i, txIn := range tx.TxIn {
+		prevOutScript := additionalPrevScripts[txIn.PreviousOutPoint]
+		script, err := txscript.SignTxOutput(w.params,
+			tx, i, prevOutScript, txscript.SigHashAll, getKey,
+			getScript, txIn.SignatureScript)
+		if err != nil {
+			return nil, errors.New("failed to sign transaction")
+		}
+		txIn.SignatureScript = script
+	}
+	return tx, nil
+}
+
+func newUnsignedTransaction(outputs []*wire.TxOut, feePerKb btc.Amount, fetchInputs txauthor.InputSource, fetchChange txauthor.ChangeSource) (*txauthor.AuthoredTx, error) {
+
+	var targetAmount btc.Amount
+	for _, txOut := range outputs {
+		targetAmount += btc.Amount(txOut.Value)
+	}
+
+	estimatedSize := EstimateSerializeSize(1, outputs, true, P2PKH)
+	targetFee := txrules.FeeForSerializeSize(feePerKb, estimatedSize)
+
+	for {
+		inputAmount, inputs, _, scripts, err := fetchInputs(targetAmount + targetFee)
+		if err != nil {
+			return nil, err
+		}
+		if inputAmount < targetAmount+targetFee {
+			return nil, errors.New("insufficient funds available to construct transaction")
+		}
+
+		maxSignedSize := EstimateSerializeSize(len(inputs), outputs, true, P2PKH)
+		maxRequiredFee := txrules.FeeForSerializeSize(feePerKb, maxSignedSize)
+		remainingAmount := inputAmount - targetAmount
+		if remainingAmount < maxRequiredFee {
+			targetFee = maxRequiredFee
+			continue
+		}
+
+		unsignedTransaction := &wire.MsgTx{
+			Version:  wire.TxVersion,
+			TxIn:     inputs,
+			TxOut:    outputs,
+			LockTime: 0,
+		}
+		changeIndex := -1
+		changeAmount := inputAmount - targetAmount - maxRequiredFee
+		if changeAmount != 0 && !isDust(int64(changeAmount)) {
+			changeScript, err := fetchChange()
+			if err != nil {
+				return nil, err
+			}
+			if len(changeScript) > P2PKHPkScriptSize {
+				return nil, errors.New("fee estimation requires change " +
+					"scripts no larger than P2PKH output scripts")
+			}
+			change := wire.NewTxOut(int64(changeAmount), changeScript)
+			l := len(outputs)
+			unsignedTransaction.TxOut = append(outputs[:l:l], change)
+			changeIndex = l
+		}
+
+		return &txauthor.AuthoredTx{
+			Tx:          unsignedTransaction,
+			PrevScripts: scripts,
+			TotalInput:  inputAmount,
+			ChangeIndex: changeIndex,
+		}, nil
+	}
+}
+
+func (w *DogecoinWallet) bumpFee(txid chainhash.Hash) (*chainhash.Hash, error) {
+	txn, err := w.db.Txns().Get(txid)
+	if err != nil {
+		return nil, err
+	}
+	if txn.Height > 0 {
+		return nil, spvwallet.BumpFeeAlreadyConfirmedError
+	}
+	if txn.Height < 0 {
+		return nil, spvwallet.BumpFeeTransactionDeadError
+	}
+	// Check utxos for CPFP
+	utxos, _ := w.db.Utxos().GetAll()
+	for _, u := range utxos {
+		if u.Op.Hash.IsEqual(&txid) && u.AtHeight == 0 {
+			addr, err := w.ScriptToAddress(u.ScriptPubkey)
+			if err != nil {
+				return nil, err
+			}
+			key, err := w.km.GetKeyForScript(addr.ScriptAddress())
+			if err != nil {
+				return nil, err
+			}
+			h, err := hex.DecodeString(u.Op.Hash.String())
+			if err != nil {
+				return nil, err
+			}
+			in := wi.TransactionInput{
+				LinkedAddress: addr,
+				OutpointIndex: u.Op.Index,
+				OutpointHash:  h,
+				Value:         int64(u.Value),
+			}
+			transactionID, err := w.sweepAddress([]wi.TransactionInput{in}, nil, key, nil, wi.FEE_BUMP)
+			if err != nil {
+				return nil, err
+			}
+			return transactionID, nil
+		}
+	}
+	return nil, spvwallet.BumpFeeNotFoundError
+}
+
+func (w *DogecoinWallet) sweepAddress(ins []wi.TransactionInput, address *btc.Address, key *hd.ExtendedKey, redeemScript *[]byte, feeLevel wi.FeeLevel) (*chainhash.Hash, error) {
+	var internalAddr btc.Address
+	if address != nil {
+		internalAddr = *address
+	} else {
+		internalAddr = w.CurrentAddress(wi.INTERNAL)
+	}
+	script, err := daddr.PayToAddrScript(internalAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	var val int64
+	var inputs []*wire.TxIn
+	additionalPrevScripts := make(map[wire.OutPoint][]byte)
+	for _, in := range ins {
+		val += in.Value
+		ch, err := chainhash.NewHashFromStr(hex.EncodeToString(in.OutpointHash))
+		if err != nil {
+			return nil, err
+		}
+		script, err := daddr.PayToAddrScript(in.LinkedAddress)
+		if err != nil {
+			return nil, err
+		}
+		outpoint := wire.NewOutPoint(ch, in.OutpointIndex)
+		input := wire.NewTxIn(outpoint, []byte{}, [][]byte{})
+		inputs = append(inputs, input)
+		additionalPrevScripts[*outpoint] = script
+	}
+	out := wire.NewTxOut(val, script)
+
+	txType := P2PKH
+	if redeemScript != nil {
+		txType = P2SH_1of2_Multisig
+		_, err := spvwallet.LockTimeFromRedeemScript(*redeemScript)
+		if err == nil {
+			txType = P2SH_Multisig_Timelock_1Sig
+		}
+	}
+	estimatedSize := EstimateSerializeSize(len(ins), []*wire.TxOut{out}, false, txType)
+
+	// Calculate the fee
+	feePerByte := int(w.GetFeePerByte(feeLevel))
+	fee := estimatedSize * feePerByte
+
+	outVal := val - int64(fee)
+	if outVal < 0 {
+		outVal = 0
+	}
+	out.Value = outVal
+
+	tx := &wire.MsgTx{
+		Version:  wire.TxVersion,
+		TxIn:     inputs,
+		TxOut:    []*wire.TxOut{out},
+		LockTime: 0,
+	}
+
+	// BIP 69 sorting
+	txsort.InPlaceSort(tx)
+
+	// Sign tx
+	privKey, err := key.ECPrivKey()
+	if err != nil {
+		return nil, fmt.Errorf("retrieving private key: %s", err.Error())
+	}
+	pk := privKey.PubKey().SerializeCompressed()
+	addressPub, err := btc.NewAddressPubKey(pk, w.params)
+	if err != nil {
+		return nil, fmt.Errorf("generating address pub key: %s", err.Error())
+	}
+
+	getKey := txscript.KeyClosure(func(addr btc.Address) (*btcec.PrivateKey, bool, error) {
+		if addressPub.EncodeAddress() == addr.EncodeAddress() {
+			wif, err := btc.NewWIF(privKey, w.params, true)
+			if err != nil {
+				return nil, false, err
+			}
+			return wif.PrivKey, wif.CompressPubKey, nil
+		}
+		return nil, false, errors.New("Not found")
+	})
+	getScript := txscript.ScriptClosure(func(addr btc.Address) ([]byte, error) {
+		if redeemScript == nil {
+			return []byte{}, nil
+		}
+		return *redeemScript, nil
+	})
+
+	// Check if time locked
+	var timeLocked bool
+	if redeemScript != nil {
+		rs := *redeemScript
+		if rs[0] == txscript.OP_IF {
+			timeLocked = true
+			tx.Version = 2
+			for _, txIn := range tx.TxIn {
+				locktime, err := spvwallet.LockTimeFromRedeemScript(*redeemScript)
+				if err != nil {
+					return nil, err
+				}
+				txIn.Sequence = locktime
+			}
+		}
+	}
+
+	for i, txIn := range tx.TxIn {
+		if !timeLocked {
+			prevOutScript := additionalPrevScripts[txIn.PreviousOutPoint]
+			script, err := txscript.SignTxOutput(w.params,
+				tx, i, prevOutScript, txscript.SigHashAll, getKey,
+				getScript, txIn.SignatureScript)
+			if err != nil {
+				return nil, errors.New("Failed to sign transaction")
+			}
+			txIn.SignatureScript = script
+		} else {
+			sig, err := txscript.RawTxInSignature(tx, i, *redeemScript, txscript.SigHashAll, privKey)
+			if err != nil {
+				return nil, err
+			}
+			builder := txscript.NewScriptBuilder().
+				AddData(sig).
+				AddOp(txscript.OP_0).
+				AddData(*redeemScript)
+			scriptSig, _ := builder.Script()
+			txIn.SignatureScript = scriptSig
+		}
+	}
+
+	// broadcast
+	if err := w.Broadcast(tx); err != nil {
+		return nil, err
+	}
+	txid := tx.TxHash()
+	return &txid, nil
+}
+
+// newMultisigTx returns the unsigned transaction the cosigners of the
+// redeem script sign, with the fee subtracted from the outputs.
+func (w *DogecoinWallet) newMultisigTx(ins []wi.TransactionInput, outs []wi.TransactionOutput, rs *multisig.RedeemScript, feePerByte uint64) (*wire.MsgTx, error) {
+	tx := wire.NewMsgTx(1)
+	for _, in := range ins {
+		ch, err := chainhash.NewHashFromStr(hex.EncodeToString(in.OutpointHash))
+		if err != nil {
+			return nil, err
+		}
+		outpoint := wire.NewOutPoint(ch, in.OutpointIndex)
+		input := wire.NewTxIn(outpoint, []byte{}, [][]byte{})
+		tx.TxIn = append(tx.TxIn, input)
+	}
+	for _, out := range outs {
+		scriptPubkey, err := daddr.PayToAddrScript(out.Address)
+		if err != nil {
+			return nil, err
+		}
+		output := wire.NewTxOut(out.Value, scriptPubkey)
+		tx.TxOut = append(tx.TxOut, output)
+	}
+
+	// Subtract fee
+	estimatedSize := EstimateMultisigSerializeSize(len(ins), tx.TxOut, rs)
+	fee := estimatedSize * int(feePerByte)
+	if len(tx.TxOut) > 0 {
+		feePerOutput := fee / len(tx.TxOut)
+		for _, output := range tx.TxOut {
+			output.Value -= int64(feePerOutput)
+		}
+	}
+
+	// BIP 69 sorting
+	txsort.InPlaceSort(tx)
+	return tx, nil
+}
+
+func (w *DogecoinWallet) createMultisigSignature(ins []wi.TransactionInput, outs []wi.TransactionOutput, key *hd.ExtendedKey, redeemScript []byte, feePerByte uint64) ([]wi.Signature, error) {
+	var sigs []wi.Signature
+	rs, err := multisig.ParseRedeemScript(redeemScript)
+	if err != nil {
+		return sigs, err
+	}
+	tx, err := w.newMultisigTx(ins, outs, rs, feePerByte)
+	if err != nil {
+		return sigs, err
+	}
+
+	signingKey, err := key.ECPrivKey()
+	if err != nil {
+		return sigs, err
+	}
+
+	for i := range tx.TxIn {
+		sig, err := txscript.RawTxInSignature(tx, i, redeemScript, txscript.SigHashAll, signingKey)
+		if err != nil {
+			continue
+		}
+		bs := wi.Signature{InputIndex: uint32(i), Signature: sig}
+		sigs = append(sigs, bs)
+	}
+	return sigs, nil
+}
+
+func (w *DogecoinWallet) multisign(ins []wi.TransactionInput, outs []wi.TransactionOutput, sigs1 []wi.Signature, sigs2 []wi.Signature, redeemScript []byte, feePerByte uint64, broadcast bool) ([]byte, error) {
+	rs, err := multisig.ParseRedeemScript(redeemScript)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := w.newMultisigTx(ins, outs, rs, feePerByte)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, input := range tx.TxIn {
+		var sig1 []byte
+		var sig2 []byte
+		for _, sig := range sigs1 {
+			if int(sig.InputIndex) == i {
+				sig1 = sig.Signature
+			}
+		}
+		for _, sig := range sigs2 {
+			if int(sig.InputIndex) == i {
+				sig2 = sig.Signature
+			}
+		}
+		builder := txscript.NewScriptBuilder()
+		builder.AddOp(txscript.OP_0)
+		builder.AddData(sig1)
+		builder.AddData(sig2)
+
+		if rs.Timelocked {
+			builder.AddOp(txscript.OP_1)
+		}
+
+		builder.AddData(redeemScript)
+		scriptSig, err := builder.Script()
+		if err != nil {
+			return nil, err
+		}
+		input.SignatureScript = scriptSig
+	}
+	// broadcast
+	var buf bytes.Buffer
+	tx.BtcEncode(&buf, wire.ProtocolVersion, wire.BaseEncoding)
+	if broadcast {
+		if err := w.Broadcast(tx); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// multisignWithSignatures signs the transaction with the signatures of any
+// number of cosigners of an m of n multisig. Until the threshold is met the
+// inputs hold partial signature scripts and complete is false.
+func (w *DogecoinWallet) multisignWithSignatures(ins []wi.TransactionInput, outs []wi.TransactionOutput, sigs multisig.Signatures, redeemScript []byte, feePerByte uint64, broadcast bool) ([]byte, bool, error) {
+	rs, err := multisig.ParseRedeemScript(redeemScript)
+	if err != nil {
+		return nil, false, err
+	}
+	tx, err := w.newMultisigTx(ins, outs, rs, feePerByte)
+	if err != nil {
+		return nil, false, err
+	}
+
+	complete := true
+	for i, input := range tx.TxIn {
+		slots, err := rs.Slots(sigs, i)
+		if err != nil {
+			return nil, false, err
+		}
+		stack, ok := rs.Stack(slots)
+		scriptSig, err := multisig.SignatureScript(stack)
+		if err != nil {
+			return nil, false, err
+		}
+		input.SignatureScript = scriptSig
+		complete = complete && ok
+	}
+	return w.finishMultisig(tx, complete, broadcast)
+}
+
+// mergeMultisig combines the signatures of partially signed transactions
+// spending the outputs of the redeem script.
+func (w *DogecoinWallet) mergeMultisig(redeemScript []byte, txs [][]byte, broadcast bool) ([]byte, bool, error) {
+	rs, err := multisig.ParseRedeemScript(redeemScript)
+	if err != nil {
+		return nil, false, err
+	}
+	var merged *wire.MsgTx
+	var slots [][][]byte
+	var finals [][]byte
+	for _, raw := range txs {
+		tx := wire.NewMsgTx(1)
+		if err := tx.BtcDecode(bytes.NewReader(raw), wire.ProtocolVersion, wire.BaseEncoding); err != nil {
+			return nil, false, err
+		}
+		if merged == nil {
+			merged = tx
+			slots = make([][][]byte, len(tx.TxIn))
+			finals = make([][]byte, len(tx.TxIn))
+		} else if multisig.UnsignedTxHash(tx) != multisig.UnsignedTxHash(merged) {
+			return nil, false, errors.New("partially signed transactions differ")
+		}
+		for i, in := range tx.TxIn {
+			stack, err := multisig.ScriptStack(in.SignatureScript)
+			if err != nil {
+				return nil, false, fmt.Errorf("input %d: %s", i, err)
+			}
+			s, complete, err := rs.ParseStack(stack)
+			if err != nil {
+				return nil, false, fmt.Errorf("input %d: %s", i, err)
+			}
+			if complete {
+				finals[i] = in.SignatureScript
+			} else if slots[i] == nil {
+				slots[i] = s
+			} else if slots[i], err = multisig.MergeSlots(slots[i], s); err != nil {
+				return nil, false, err
+			}
+		}
+	}
+	if merged == nil {
+		return nil, false, errors.New("no transactions to merge")
+	}
+
+	complete := true
+	for i, input := range merged.TxIn {
+		if finals[i] != nil {
+			input.SignatureScript = finals[i]
+			continue
+		}
+		stack, ok := rs.Stack(slots[i])
+		scriptSig, err := multisig.SignatureScript(stack)
+		if err != nil {
+			return nil, false, err
+		}
+		input.SignatureScript = scriptSig
+		complete = complete && ok
+	}
+	return w.finishMultisig(merged, complete, broadcast)
+}
+
+// finishMultisig serializes a multisig transaction, broadcasting it if
+// requested once every input is fully signed.
+func (w *DogecoinWallet) finishMultisig(tx *wire.MsgTx, complete, broadcast bool) ([]byte, bool, error) {
+	if broadcast && complete {
+		if err := w.Broadcast(tx); err != nil {
+			return nil, false, err
+		}
+	}
+	var buf bytes.Buffer
+	tx.BtcEncode(&buf, wire.ProtocolVersion, wire.BaseEncoding)
+	return buf.Bytes(), complete, nil
+}
+
+// releaseAfterTimeout spends the outputs of an escrow through the timeout
+// branch of its redeem script, signed by the timeout key, once its relative
+// lock time has passed.
+func (w *DogecoinWallet) releaseAfterTimeout(ins []wi.TransactionInput, address btc.Address, timeoutKey *hd.ExtendedKey, redeemScript []byte, feeLevel wi.FeeLevel) (*chainhash.Hash, error) {
+	rs, err := multisig.ParseRedeemScript(redeemScript)
+	if err != nil {
+		return nil, err
+	}
+	blocks, err := rs.TimeoutBlocks()
+	if err != nil {
+		return nil, err
+	}
+	privKey, err := timeoutKey.ECPrivKey()
+	if err != nil {
+		return nil, fmt.Errorf("retrieving private key: %s", err.Error())
+	}
+	if !bytes.Equal(privKey.PubKey().SerializeCompressed(), rs.TimeoutKey) {
+		return nil, errors.New("key is not the timeout key of the redeem script")
+	}
+	if address == nil {
+		address = w.CurrentAddress(wi.INTERNAL)
+	}
+	script, err := w.AddressToScript(address)
+	if err != nil {
+		return nil, err
+	}
+
+	// The relative lock time is only enforced on version 2 transactions
+	tx := wire.NewMsgTx(2)
+	var val int64
+	var outpoints []wire.OutPoint
+	for _, in := range ins {
+		ch, err := chainhash.NewHashFromStr(hex.EncodeToString(in.OutpointHash))
+		if err != nil {
+			return nil, err
+		}
+		outpoint := wire.NewOutPoint(ch, in.OutpointIndex)
+		input := wire.NewTxIn(outpoint, []byte{}, [][]byte{})
+		input.Sequence = rs.Timeout
+		tx.TxIn = append(tx.TxIn, input)
+		outpoints = append(outpoints, *outpoint)
+		val += in.Value
+	}
+	if err := w.ws.CheckEscrowTimeout(outpoints, blocks); err != nil {
+		return nil, err
+	}
+
+	out := wire.NewTxOut(val, script)
+	tx.TxOut = append(tx.TxOut, out)
+	fee := int64(EstimateTimeoutSerializeSize(len(ins), tx.TxOut, rs)) * int64(w.GetFeePerByte(feeLevel))
+	out.Value = val - fee
+	if w.IsDust(out.Value) {
+		return nil, wi.ErrorDustAmount
+	}
+
+	// BIP 69 sorting
+	txsort.InPlaceSort(tx)
+
+	for i, txIn := range tx.TxIn {
+		sig, err := txscript.RawTxInSignature(tx, i, redeemScript, txscript.SigHashAll, privKey)
+		if err != nil {
+			return nil, err
+		}
+		scriptSig, err := multisig.SignatureScript(rs.TimeoutStack(sig))
+		if err != nil {
+			return nil, err
+		}
+		txIn.SignatureScript = scriptSig
+	}
+
+	// broadcast
+	if err := w.Broadcast(tx); err != nil {
+		return nil, err
+	}
+	txid := tx.TxHash()
+	return &txid, nil
+}
+
+// generateHTLCScript returns the P2SH address and script of a hash time locked
+// contract.
+func (w *DogecoinWallet) generateHTLCScript(secretHash, recipientKey, refundKey []byte, lockTime uint32) (btc.Address, []byte, error) {
+	contract, err := htlc.NewContract(secretHash, recipientKey, refundKey, lockTime)
+	if err != nil {
+		return nil, nil, err
+	}
+	addr, err := daddr.NewAddressScriptHash(contract.Script, w.params)
+	if err != nil {
+		return nil, nil, err
+	}
+	return addr, contract.Script, nil
+}
+
+// spendHTLC spends the outputs of a hash time locked contract to address. The
+// contract is redeemed by the recipient key if a secret is given and refunded
+// by the refund key otherwise.
+func (w *DogecoinWallet) spendHTLC(ins []wi.TransactionInput, address btc.Address, key *hd.ExtendedKey, script []byte, secret []byte, feeLevel wi.FeeLevel) (*chainhash.Hash, error) {
+	contract, err := htlc.ParseContract(script)
+	if err != nil {
+		return nil, err
+	}
+	privKey, err := key.ECPrivKey()
+	if err != nil {
+		return nil, fmt.Errorf("retrieving private key: %s", err.Error())
+	}
+	pubKey := privKey.PubKey().SerializeCompressed()
+	redeem := secret != nil
+	if redeem {
+		if !contract.Matches(secret) {
+			return nil, htlc.ErrSecretMismatch
+		}
+		if !bytes.Equal(pubKey, contract.RecipientKey) {
+			return nil, errors.New("key is not the recipient key of the contract")
+		}
+	} else {
+		if !bytes.Equal(pubKey, contract.RefundKey) {
+			return nil, errors.New("key is not the refund key of the contract")
+		}
+		if tip, _ := w.ChainTip(); !contract.Refundable(tip) {
+			return nil, htlc.ErrLockTimeNotReached
+		}
+	}
+	if address == nil {
+		address = w.CurrentAddress(wi.INTERNAL)
+	}
+	outScript, err := w.AddressToScript(address)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := wire.NewMsgTx(wire.TxVersion)
+	var val int64
+	for _, in := range ins {
+		ch, err := chainhash.NewHashFromStr(hex.EncodeToString(in.OutpointHash))
+		if err != nil {
+			return nil, err
+		}
+		outpoint := wire.NewOutPoint(ch, in.OutpointIndex)
+		input := wire.NewTxIn(outpoint, []byte{}, [][]byte{})
+		if !redeem {
+			// The lock time is only enforced on inputs which are not final
+			input.Sequence = wire.MaxTxInSequenceNum - 1
+		}
+		tx.TxIn = append(tx.TxIn, input)
+		val += in.Value
+	}
+	if !redeem {
+		tx.LockTime = contract.LockTime
+	}
+
+	out := wire.NewTxOut(val, outScript)
+	tx.TxOut = append(tx.TxOut, out)
+	fee := int64(EstimateHTLCSerializeSize(len(ins), tx.TxOut, redeem)) * int64(w.GetFeePerByte(feeLevel))
+	out.Value = val - fee
+	if w.IsDust(out.Value) {
+		return nil, wi.ErrorDustAmount
+	}
+
+	// BIP 69 sorting
+	txsort.InPlaceSort(tx)
+
+	for i, txIn := range tx.TxIn {
+		sig, err := txscript.RawTxInSignature(tx, i, script, txscript.SigHashAll, privKey)
+		if err != nil {
+			return nil, err
+		}
+		stack := contract.RefundStack(sig)
+		if redeem {
+			stack = contract.RedeemStack(sig, secret)
+		}
+		scriptSig, err := multisig.SignatureScript(stack)
+		if err != nil {
+			return nil, err
+		}
+		txIn.SignatureScript = scriptSig
+	}
+
+	// broadcast
+	if err := w.Broadcast(tx); err != nil {
+		return nil, err
+	}
+	txid := tx.TxHash()
+	return &txid, nil
+}
+
+func (w *DogecoinWallet) generateMultisigScript(keys []hd.ExtendedKey, threshold int, timeout time.Duration, timeoutKey *hd.ExtendedKey) (addr btc.Address, redeemScript []byte, err error) {
+	if uint32(timeout.Hours()) > 0 && timeoutKey == nil {
+		return nil, nil, errors.New("Timeout key must be non nil when using an escrow timeout")
+	}
+
+	if len(keys) < threshold {
+		return nil, nil, fmt.Errorf("unable to generate multisig script with "+
+			"%d required signatures when there are only %d public "+
+			"keys available", threshold, len(keys))
+	}
+
+	var ecKeys []*btcec.PublicKey
+	for _, key := range keys {
+		ecKey, err := key.ECPubKey()
+		if err != nil {
+			return nil, nil, err
+		}
+		ecKeys = append(ecKeys, ecKey)
+	}
+
+	builder := txscript.NewScriptBuilder()
+	if uint32(timeout.Hours()) == 0 {
+
+		builder.AddInt64(int64(threshold))
+		for _, key := range ecKeys {
+			builder.AddData(key.SerializeCompressed())
+		}
+		builder.AddInt64(int64(len(ecKeys)))
+		builder.AddOp(txscript.OP_CHECKMULTISIG)
+
+	} else {
+		ecKey, err := timeoutKey.ECPubKey()
+		if err != nil {
+			return nil, nil, err
+		}
+		sequenceLock := blockchain.LockTimeToSequence(false, uint32(timeout.Hours()*blocksPerHour))
+		builder.AddOp(txscript.OP_IF)
+		builder.AddInt64(int64(threshold))
+		for _, key := range ecKeys {
+			builder.AddData(key.SerializeCompressed())
+		}
+		builder.AddInt64(int64(len(ecKeys)))
+		builder.AddOp(txscript.OP_CHECKMULTISIG)
+		builder.AddOp(txscript.OP_ELSE).
+			AddInt64(int64(sequenceLock)).
+			AddOp(txscript.OP_CHECKSEQUENCEVERIFY).
+			AddOp(txscript.OP_DROP).
+			AddData(ecKey.SerializeCompressed()).
+			AddOp(txscript.OP_CHECKSIG).
+			AddOp(txscript.OP_ENDIF)
+	}
+	redeemScript, err = builder.Script()
+	if err != nil {
+		return nil, nil, err
+	}
+	addr, err = daddr.NewAddressScriptHash(redeemScript, w.params)
+	if err != nil {
+		return nil, nil, err
+	}
+	return addr, redeemScript, nil
+}
+
+func (w *DogecoinWallet) estimateSpendFee(amount int64, feeLevel wi.FeeLevel) (uint64, error) {
+	// Since this is an estimate we can use a dummy output address. Let's use a long one so we don't under estimate.
+	addr, err := daddr.DecodeAddress("A3FWNUmzq8fXceLoG8DM7PAxPQbNUjae2B", &chaincfg.MainNetParams)
+	if err != nil {
+		return 0, err
+	}
+	tx, err := w.buildTx(amount, addr, feeLevel, nil)
+	if err != nil {
+		return 0, err
+	}
+	var outval int64
+	for _, output := range tx.TxOut {
+		outval += output.Value
+	}
+	var inval int64
+	utxos, err := w.db.Utxos().GetAll()
+	if err != nil {
+		return 0, err
+	}
+	for _, input := range tx.TxIn {
+		for _, utxo := range utxos {
+			if utxo.Op.Hash.IsEqual(&input.PreviousOutPoint.Hash) && utxo.Op.Index == input.PreviousOutPoint.Index {
+				inval += utxo.Value
+				break
+			}
+		}
+	}
+	if inval < outval {
+		return 0, errors.New("Error building transaction: inputs less than outputs")
+	}
+	return uint64(inval - outval), err
+}
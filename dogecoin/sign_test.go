@@ -0,0 +1,936 @@
+package dogecoin
+
+import (
+	"bytes"
+	"encoding/hex"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/muecoin/multiwallet/cache"
+	"github.com/muecoin/multiwallet/datastore"
+	daddr "github.com/muecoin/multiwallet/dogecoin/address"
+	"github.com/muecoin/multiwallet/htlc"
+	"github.com/muecoin/multiwallet/keys"
+	"github.com/muecoin/multiwallet/model/mock"
+	"github.com/muecoin/multiwallet/multisig"
+	"github.com/muecoin/multiwallet/service"
+	"github.com/muecoin/multiwallet/util"
+	"github.com/OpenBazaar/wallet-interface"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcutil/hdkeychain"
+)
+
+func newMockWallet() (*DogecoinWallet, error) {
+	mockDb := datastore.NewMockMultiwalletDatastore()
+
+	db, err := mockDb.GetDatastoreForWallet(util.CoinTypeDogecoin.ToCoinType())
+	if err != nil {
+		return nil, err
+	}
+	params := &chaincfg.MainNetParams
+
+	seed, err := hex.DecodeString("16c034c59522326867593487c03a8f9615fb248406dd0d4ffb3a6b976a248403")
+	if err != nil {
+		return nil, err
+	}
+	master, err := hdkeychain.NewMaster(seed, params)
+	if err != nil {
+		return nil, err
+	}
+	km, err := keys.NewKeyManager(db.Keys(), params, master, util.CoinTypeDogecoin, dogecoinAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	fp := util.NewFeeDefaultProvider(2000, 300, 200, 100)
+
+	bw := &DogecoinWallet{
+		params: params,
+		km:     km,
+		db:     db,
+		fp:     fp,
+	}
+	cli := mock.NewMockApiClient(bw.AddressToScript)
+	ws, err := service.NewWalletService(db, km, cli, params, util.CoinTypeDogecoin, cache.NewMockCacher())
+	if err != nil {
+		return nil, err
+	}
+	bw.client = cli
+	bw.ws = ws
+	return bw, nil
+}
+
+func waitForTxnSync(t *testing.T, txnStore wallet.Txns) {
+	// Look for a known txn, this sucks a bit. It would be better to check if the
+	// number of stored txns matched the expected, but not all the mock
+	// transactions are relevant, so the numbers don't add up.
+	// Even better would be for the wallet to signal that the initial sync was
+	// done.
+	lastTxn := mock.MockTransactions[len(mock.MockTransactions)-2]
+	txHash, err := chainhash.NewHashFromStr(lastTxn.Txid)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 100; i++ {
+		if _, err := txnStore.Get(*txHash); err == nil {
+			return
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	t.Fatal("timeout waiting for wallet to sync transactions")
+}
+
+func TestDogecoinWallet_buildTx(t *testing.T) {
+	w, err := newMockWallet()
+	if err != nil {
+		t.Error(err)
+	}
+	w.ws.Start()
+	time.Sleep(time.Second / 2)
+
+	addr, err := w.DecodeAddress("DPfx3nZMidTmMR81WVLZmN7UCM4qY2F33t")
+	if err != nil {
+		t.Error(err)
+	}
+	// Test build normal tx
+	tx, err := w.buildTx(1500000, addr, wallet.NORMAL, nil)
+	if err != nil {
+		w.DumpTables(os.Stdout)
+		t.Error(err)
+		return
+	}
+	if !containsOutput(tx, addr) {
+		t.Error("Built tx does not contain the requested output")
+	}
+	if !validInputs(tx, w.db) {
+		t.Error("Built tx does not contain valid inputs")
+	}
+	if !validChangeAddress(tx, w.db, w.params) {
+		t.Error("Built tx does not contain a valid change output")
+	}
+
+	// Data output
+	dataScript, err := txscript.NullDataScript([]byte("order 1a3w"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dataOut := wire.NewTxOut(0, dataScript)
+	tx, err = w.buildTx(1500000, addr, wallet.NORMAL, dataOut)
+	if err != nil {
+		t.Error(err)
+	}
+	if !containsOutput(tx, addr) {
+		t.Error("Built tx does not contain the requested output")
+	}
+	hasData := false
+	for _, out := range tx.TxOut {
+		if bytes.Equal(out.PkScript, dataOut.PkScript) && out.Value == 0 {
+			hasData = true
+		}
+	}
+	if !hasData {
+		t.Error("Built tx does not contain the data output")
+	}
+
+	// Insuffient funds
+	_, err = w.buildTx(1000000000, addr, wallet.NORMAL, nil)
+	if err != wallet.ErrorInsuffientFunds {
+		t.Error("Failed to throw insuffient funds error")
+	}
+
+	// Dust. Dogecoin's dust limit is far above bitcoin's.
+	_, err = w.buildTx(DustLimit-1, addr, wallet.NORMAL, nil)
+	if err != wallet.ErrorDustAmount {
+		t.Error("Failed to throw dust error")
+	}
+}
+
+func TestDogecoinWallet_GetFeePerByte(t *testing.T) {
+	w, err := newMockWallet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fee := w.GetFeePerByte(wallet.NORMAL); fee != 200 {
+		t.Errorf("Expected a fee of 200 koinu per byte but had %d", fee)
+	}
+	w.fp = util.NewFeeDefaultProvider(2000, 0, 0, 0)
+	if fee := w.GetFeePerByte(wallet.ECONOMIC); fee != MinRelayFeePerKB/1000 {
+		t.Errorf("Expected the minimum relay fee but had %d", fee)
+	}
+}
+
+func TestDogecoinWallet_buildSpendAllTx(t *testing.T) {
+	w, err := newMockWallet()
+	if err != nil {
+		t.Error(err)
+	}
+	w.ws.Start()
+	time.Sleep(time.Second / 2)
+
+	waitForTxnSync(t, w.db.Txns())
+	addr, err := w.DecodeAddress("A3FWNUmzq8fXceLoG8DM7PAxPQbNUjae2B")
+	if err != nil {
+		t.Error(err)
+	}
+
+	// Test build spendAll tx
+	tx, err := w.buildSpendAllTx(addr, wallet.NORMAL, nil)
+	if err != nil {
+		t.Error(err)
+	}
+	utxos, err := w.db.Utxos().GetAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	spendableUtxos := 0
+	for _, u := range utxos {
+		if !u.WatchOnly {
+			spendableUtxos++
+		}
+	}
+	if len(tx.TxIn) != spendableUtxos {
+		t.Error("Built tx does not spend all available utxos")
+	}
+	if !containsOutput(tx, addr) {
+		t.Error("Built tx does not contain the requested output")
+	}
+	if !validInputs(tx, w.db) {
+		t.Error("Built tx does not contain valid inputs")
+	}
+	if len(tx.TxOut) != 1 {
+		t.Error("Built tx should only have one output")
+	}
+
+	// Verify the signatures on each input using the scripting engine
+	for i, in := range tx.TxIn {
+		var prevScript []byte
+		for _, u := range utxos {
+			if util.OutPointsEqual(u.Op, in.PreviousOutPoint) {
+				prevScript = u.ScriptPubkey
+				break
+			}
+		}
+		vm, err := txscript.NewEngine(prevScript, tx, i, txscript.StandardVerifyFlags, nil, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := vm.Execute(); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func containsOutput(tx *wire.MsgTx, addr btcutil.Address) bool {
+	for _, o := range tx.TxOut {
+		script, _ := daddr.PayToAddrScript(addr)
+		if bytes.Equal(script, o.PkScript) {
+			return true
+		}
+	}
+	return false
+}
+
+func validInputs(tx *wire.MsgTx, db wallet.Datastore) bool {
+	utxos, _ := db.Utxos().GetAll()
+	uMap := make(map[wire.OutPoint]bool)
+	for _, u := range utxos {
+		uMap[u.Op] = true
+	}
+	for _, in := range tx.TxIn {
+		if !uMap[in.PreviousOutPoint] {
+			return false
+		}
+	}
+	return true
+}
+
+func validChangeAddress(tx *wire.MsgTx, db wallet.Datastore, params *chaincfg.Params) bool {
+	for _, out := range tx.TxOut {
+		addr, err := daddr.ExtractPkScriptAddrs(out.PkScript, params)
+		if err != nil {
+			continue
+		}
+		_, err = db.Keys().GetPathForKey(addr.ScriptAddress())
+		if err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func TestDogecoinWallet_GenerateMultisigScript(t *testing.T) {
+	w, err := newMockWallet()
+	if err != nil {
+		t.Error(err)
+	}
+	key1, err := w.km.GetFreshKey(wallet.INTERNAL)
+	if err != nil {
+		t.Error(err)
+	}
+	pubkey1, err := key1.ECPubKey()
+	if err != nil {
+		t.Error(err)
+	}
+	key2, err := w.km.GetFreshKey(wallet.INTERNAL)
+	if err != nil {
+		t.Error(err)
+	}
+	pubkey2, err := key2.ECPubKey()
+	if err != nil {
+		t.Error(err)
+	}
+	key3, err := w.km.GetFreshKey(wallet.INTERNAL)
+	if err != nil {
+		t.Error(err)
+	}
+	pubkey3, err := key3.ECPubKey()
+	if err != nil {
+		t.Error(err)
+	}
+	keys := []hdkeychain.ExtendedKey{*key1, *key2, *key3}
+
+	// test without timeout
+	addr, redeemScript, err := w.generateMultisigScript(keys, 2, 0, nil)
+	if err != nil {
+		t.Error(err)
+	}
+	if addr.String() != "9xA1ReS8mHLnd4ns4hTnfGpF3KR5w5dPYZ" {
+		t.Error("Returned invalid address")
+	}
+
+	rs := "52" + // OP_2
+		"21" + // OP_PUSHDATA(33)
+		hex.EncodeToString(pubkey1.SerializeCompressed()) + // pubkey1
+		"21" + // OP_PUSHDATA(33)
+		hex.EncodeToString(pubkey2.SerializeCompressed()) + // pubkey2
+		"21" + // OP_PUSHDATA(33)
+		hex.EncodeToString(pubkey3.SerializeCompressed()) + // pubkey3
+		"53" + // OP_3
+		"ae" // OP_CHECKMULTISIG
+	rsBytes, err := hex.DecodeString(rs)
+	if !bytes.Equal(rsBytes, redeemScript) {
+		t.Error("Returned invalid redeem script")
+	}
+
+	// test with timeout
+	key4, err := w.km.GetFreshKey(wallet.INTERNAL)
+	if err != nil {
+		t.Error(err)
+	}
+	pubkey4, err := key4.ECPubKey()
+	if err != nil {
+		t.Error(err)
+	}
+	addr, redeemScript, err = w.generateMultisigScript(keys, 2, time.Hour*10, key4)
+	if err != nil {
+		t.Error(err)
+	}
+	if addr.String() != "AB4MU95XdP2Po7rUN8sXL4xVDUMp7rgthC" {
+		t.Error("Returned invalid address")
+	}
+
+	rs = "63" + // OP_IF
+		"52" + // OP_2
+		"21" + // OP_PUSHDATA(33)
+		hex.EncodeToString(pubkey1.SerializeCompressed()) + // pubkey1
+		"21" + // OP_PUSHDATA(33)
+		hex.EncodeToString(pubkey2.SerializeCompressed()) + // pubkey2
+		"21" + // OP_PUSHDATA(33)
+		hex.EncodeToString(pubkey3.SerializeCompressed()) + // pubkey3
+		"53" + // OP_3
+		"ae" + // OP_CHECKMULTISIG
+		"67" + // OP_ELSE
+		"02" + // OP_PUSHDATA(2)
+		"5802" + // 600 blocks
+		"b2" + // OP_CHECKSEQUENCEVERIFY
+		"75" + // OP_DROP
+		"21" + // OP_PUSHDATA(33)
+		hex.EncodeToString(pubkey4.SerializeCompressed()) + // timeout pubkey
+		"ac" + // OP_CHECKSIG
+		"68" // OP_ENDIF
+	rsBytes, err = hex.DecodeString(rs)
+	if !bytes.Equal(rsBytes, redeemScript) {
+		t.Error("Returned invalid redeem script")
+	}
+}
+
+func TestDogecoinWallet_newUnsignedTransaction(t *testing.T) {
+	w, err := newMockWallet()
+	w.ws.Start()
+	time.Sleep(time.Second / 2)
+	if err != nil {
+		t.Error(err)
+	}
+	utxos, err := w.db.Utxos().GetAll()
+	if err != nil {
+		t.Error(err)
+	}
+	addr, err := w.DecodeAddress("A3FWNUmzq8fXceLoG8DM7PAxPQbNUjae2B")
+	if err != nil {
+		t.Error(err)
+	}
+
+	script, err := daddr.PayToAddrScript(addr)
+	if err != nil {
+		t.Error(err)
+	}
+	out := wire.NewTxOut(DustLimit, script)
+	outputs := []*wire.TxOut{out}
+
+	changeSource := func() ([]byte, error) {
+		addr := w.CurrentAddress(wallet.INTERNAL)
+		script, err := daddr.PayToAddrScript(addr)
+		if err != nil {
+			return []byte{}, err
+		}
+		return script, nil
+	}
+
+	var input wallet.Utxo
+	for _, u := range utxos {
+		if u.Value > 2*DustLimit {
+			input = u
+			break
+		}
+	}
+	inputSource := func(target btcutil.Amount) (total btcutil.Amount, inputs []*wire.TxIn, inputValues []btcutil.Amount, scripts [][]byte, err error) {
+		total += btcutil.Amount(input.Value)
+		in := wire.NewTxIn(&input.Op, []byte{}, [][]byte{})
+		in.Sequence = 0 // Opt-in RBF so we can bump fees
+		inputs = append(inputs, in)
+		return total, inputs, inputValues, scripts, nil
+	}
+
+	// Regular transaction
+	authoredTx, err := newUnsignedTransaction(outputs, btcutil.Amount(MinRelayFeePerKB), inputSource, changeSource)
+	if err != nil {
+		t.Error(err)
+	}
+	if len(authoredTx.Tx.TxOut) != 2 {
+		t.Error("Returned incorrect number of outputs")
+	}
+	if len(authoredTx.Tx.TxIn) != 1 {
+		t.Error("Returned incorrect number of inputs")
+	}
+
+	// Change below the dust limit goes to the fee
+	outputs[0].Value = input.Value - DustLimit
+	authoredTx, err = newUnsignedTransaction(outputs, btcutil.Amount(MinRelayFeePerKB), inputSource, changeSource)
+	if err != nil {
+		t.Error(err)
+	}
+	if len(authoredTx.Tx.TxOut) != 1 || authoredTx.ChangeIndex != -1 {
+		t.Error("Returned a dust change output")
+	}
+
+	// Insufficient funds
+	outputs[0].Value = 1000000000
+	_, err = newUnsignedTransaction(outputs, btcutil.Amount(MinRelayFeePerKB), inputSource, changeSource)
+	if err == nil {
+		t.Error("Failed to return insuffient funds error")
+	}
+}
+
+func TestDogecoinWallet_CreateMultisigSignature(t *testing.T) {
+	w, err := newMockWallet()
+	if err != nil {
+		t.Error(err)
+	}
+	ins, outs, redeemScript, err := buildTxData(w)
+	if err != nil {
+		t.Error(err)
+	}
+
+	key1, err := w.km.GetFreshKey(wallet.INTERNAL)
+	if err != nil {
+		t.Error(err)
+	}
+
+	sigs, err := w.CreateMultisigSignature(ins, outs, key1, redeemScript, 50)
+	if err != nil {
+		t.Error(err)
+	}
+	if len(sigs) != 2 {
+		t.Error(err)
+	}
+	for _, sig := range sigs {
+		if len(sig.Signature) == 0 {
+			t.Error("Returned empty signature")
+		}
+	}
+}
+
+func buildTxData(w *DogecoinWallet) ([]wallet.TransactionInput, []wallet.TransactionOutput, []byte, error) {
+	redeemScript := "522103c157f2a7c178430972263232c9306110090c50b44d4e906ecd6d377eec89a53c210205b02b9dbe570f36d1c12e3100e55586b2b9dc61d6778c1d24a8eaca03625e7e21030c83b025cd6bdd8c06e93a2b953b821b4a8c29da211335048d7dc3389706d7e853ae"
+	redeemScriptBytes, err := hex.DecodeString(redeemScript)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	h1, err := hex.DecodeString("1a20f4299b4fa1f209428dace31ebf4f23f13abd8ed669cebede118343a6ae05")
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	in1 := wallet.TransactionInput{
+		OutpointHash:  h1,
+		OutpointIndex: 1,
+	}
+	h2, err := hex.DecodeString("458d88b4ae9eb4a347f2e7f5592f1da3b9ddf7d40f307f6e5d7bc107a9b3e90e")
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	in2 := wallet.TransactionInput{
+		OutpointHash:  h2,
+		OutpointIndex: 0,
+	}
+	addr, err := w.DecodeAddress("ABz9vhwbhNak16iUBkVfjaDMwpBkHxEUtt")
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	out := wallet.TransactionOutput{
+		Value:   20000000,
+		Address: addr,
+	}
+	return []wallet.TransactionInput{in1, in2}, []wallet.TransactionOutput{out}, redeemScriptBytes, nil
+}
+
+func TestDogecoinWallet_Multisign(t *testing.T) {
+	w, err := newMockWallet()
+	if err != nil {
+		t.Error(err)
+	}
+	ins, outs, redeemScript, err := buildTxData(w)
+	if err != nil {
+		t.Error(err)
+	}
+
+	key1, err := w.km.GetFreshKey(wallet.INTERNAL)
+	if err != nil {
+		t.Error(err)
+	}
+
+	key2, err := w.km.GetFreshKey(wallet.INTERNAL)
+	if err != nil {
+		t.Error(err)
+	}
+
+	sigs1, err := w.CreateMultisigSignature(ins, outs, key1, redeemScript, 50)
+	if err != nil {
+		t.Error(err)
+	}
+	if len(sigs1) != 2 {
+		t.Error(err)
+	}
+	sigs2, err := w.CreateMultisigSignature(ins, outs, key2, redeemScript, 50)
+	if err != nil {
+		t.Error(err)
+	}
+	if len(sigs2) != 2 {
+		t.Error(err)
+	}
+	txBytes, err := w.Multisign(ins, outs, sigs1, sigs2, redeemScript, 50, false)
+	if err != nil {
+		t.Error(err)
+	}
+
+	tx := wire.NewMsgTx(0)
+	tx.BtcDecode(bytes.NewReader(txBytes), wire.ProtocolVersion, wire.BaseEncoding)
+	if len(tx.TxIn) != 2 {
+		t.Error("Transactions has incorrect number of inputs")
+	}
+	if len(tx.TxOut) != 1 {
+		t.Error("Transactions has incorrect number of outputs")
+	}
+	for _, in := range tx.TxIn {
+		if len(in.SignatureScript) == 0 {
+			t.Error("Input script has zero length")
+		}
+	}
+}
+
+func TestDogecoinWallet_MultisignWithSignatures(t *testing.T) {
+	w, err := newMockWallet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var keys []hdkeychain.ExtendedKey
+	for i := 0; i < 5; i++ {
+		key, err := w.km.GetFreshKey(wallet.INTERNAL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, *key)
+	}
+	addr, redeemScript, err := w.generateMultisigScript(keys, 3, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ins, outs, _, err := buildTxData(w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ins[0].Value = 40000000
+	ins[1].Value = 60000000
+
+	// Three of the five cosigners sign, out of key order
+	var partials [][]byte
+	sigs := make(multisig.Signatures)
+	for _, i := range []int{4, 0, 2} {
+		keySigs, err := w.CreateMultisigSignature(ins, outs, &keys[i], redeemScript, 50)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pubKey, err := keys[i].ECPubKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		cosigner := make(multisig.Signatures)
+		cosigner.Add(pubKey.SerializeCompressed(), keySigs)
+		sigs.Merge(cosigner)
+
+		partial, complete, err := w.MultisignWithSignatures(ins, outs, cosigner, redeemScript, 50, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if complete {
+			t.Error("Transaction with a single signature reported as complete")
+		}
+		partials = append(partials, partial)
+	}
+
+	txBytes, complete, err := w.MergeMultisig(redeemScript, partials[:2], false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if complete {
+		t.Error("Transaction with two signatures reported as complete")
+	}
+	txBytes, complete, err = w.MergeMultisig(redeemScript, [][]byte{txBytes, partials[2]}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !complete {
+		t.Error("Transaction with three signatures reported as partial")
+	}
+	direct, complete, err := w.MultisignWithSignatures(ins, outs, sigs, redeemScript, 50, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !complete || !bytes.Equal(direct, txBytes) {
+		t.Error("Merged transaction differs from the one signed with every signature")
+	}
+
+	tx := wire.NewMsgTx(1)
+	if err := tx.BtcDecode(bytes.NewReader(txBytes), wire.ProtocolVersion, wire.BaseEncoding); err != nil {
+		t.Fatal(err)
+	}
+	pkScript, err := w.AddressToScript(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range tx.TxIn {
+		vm, err := txscript.NewEngine(pkScript, tx, i, txscript.StandardVerifyFlags, nil, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := vm.Execute(); err != nil {
+			t.Errorf("Input %d: %s", i, err)
+		}
+	}
+}
+
+func TestDogecoinWallet_ReleaseAfterTimeout(t *testing.T) {
+	w, err := newMockWallet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.ws.Start()
+	waitForTxnSync(t, w.db.Txns())
+	time.Sleep(time.Second / 2)
+
+	var keys []hdkeychain.ExtendedKey
+	for i := 0; i < 4; i++ {
+		key, err := w.km.GetFreshKey(wallet.INTERNAL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, *key)
+	}
+	timeoutKey := &keys[3]
+	addr, redeemScript, err := w.generateMultisigScript(keys[:3], 2, time.Hour, timeoutKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkScript, err := w.AddressToScript(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// An hour is 60 blocks
+	tip, _ := w.ChainTip()
+	op := wire.NewOutPoint(&chainhash.Hash{0x0e}, 1)
+	utxo := wallet.Utxo{Op: *op, Value: 10000000, ScriptPubkey: pkScript, AtHeight: int32(tip) - 58, WatchOnly: true}
+	if err := w.db.Utxos().Put(utxo); err != nil {
+		t.Fatal(err)
+	}
+	h, _ := hex.DecodeString(op.Hash.String())
+	ins := []wallet.TransactionInput{{OutpointHash: h, OutpointIndex: op.Index, Value: utxo.Value, LinkedAddress: addr}}
+
+	if _, err := w.ReleaseAfterTimeout(ins, nil, timeoutKey, redeemScript, wallet.NORMAL); err != multisig.ErrTimeoutNotMatured {
+		t.Errorf("Expected ErrTimeoutNotMatured but had %v", err)
+	}
+	utxo.AtHeight--
+	if err := w.db.Utxos().Put(utxo); err != nil {
+		t.Fatal(err)
+	}
+	txid, err := w.ReleaseAfterTimeout(ins, nil, timeoutKey, redeemScript, wallet.NORMAL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txn, err := w.db.Txns().Get(*txid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx := wire.NewMsgTx(1)
+	if err := tx.BtcDecode(bytes.NewReader(txn.Bytes), wire.ProtocolVersion, wire.BaseEncoding); err != nil {
+		t.Fatal(err)
+	}
+	vm, err := txscript.NewEngine(pkScript, tx, 0, txscript.StandardVerifyFlags, nil, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := vm.Execute(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDogecoinWallet_HTLC(t *testing.T) {
+	w, err := newMockWallet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.ws.Start()
+	waitForTxnSync(t, w.db.Txns())
+	time.Sleep(time.Second / 2)
+
+	recipientKey, err := w.km.GetFreshKey(wallet.INTERNAL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	refundKey, err := w.km.GetFreshKey(wallet.INTERNAL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recipientPub, err := recipientKey.ECPubKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	refundPub, err := refundKey.ECPubKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret, secretHash, err := htlc.NewSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	verify := func(lockTime uint32, spend func(ins []wallet.TransactionInput, contract []byte) (*chainhash.Hash, error)) {
+		addr, contract, err := w.GenerateHTLCScript(secretHash, recipientPub.SerializeCompressed(), refundPub.SerializeCompressed(), lockTime)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pkScript, err := w.AddressToScript(addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		h, _ := hex.DecodeString(chainhash.Hash{0x0f}.String())
+		ins := []wallet.TransactionInput{{OutpointHash: h, OutpointIndex: lockTime, Value: 10000000, LinkedAddress: addr}}
+		txid, err := spend(ins, contract)
+		if err != nil {
+			t.Fatal(err)
+		}
+		txn, err := w.db.Txns().Get(*txid)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tx := wire.NewMsgTx(1)
+		if err := tx.BtcDecode(bytes.NewReader(txn.Bytes), wire.ProtocolVersion, wire.BaseEncoding); err != nil {
+			t.Fatal(err)
+		}
+		vm, err := txscript.NewEngine(pkScript, tx, 0, txscript.StandardVerifyFlags, nil, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := vm.Execute(); err != nil {
+			t.Error(err)
+		}
+	}
+
+	tip, _ := w.ChainTip()
+	verify(tip+10, func(ins []wallet.TransactionInput, contract []byte) (*chainhash.Hash, error) {
+		return w.RedeemHTLC(ins, nil, recipientKey, contract, secret, wallet.NORMAL)
+	})
+	verify(tip, func(ins []wallet.TransactionInput, contract []byte) (*chainhash.Hash, error) {
+		return w.RefundHTLC(ins, nil, refundKey, contract, wallet.NORMAL)
+	})
+
+	if _, _, err := w.GenerateHTLCScript(secretHash, recipientPub.SerializeCompressed(), refundPub.SerializeCompressed(), 0); err == nil {
+		t.Error("Generated a contract without a lock time")
+	}
+	_, contract, err := w.GenerateHTLCScript(secretHash, recipientPub.SerializeCompressed(), refundPub.SerializeCompressed(), tip+1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.RefundHTLC(nil, nil, refundKey, contract, wallet.NORMAL); err != htlc.ErrLockTimeNotReached {
+		t.Errorf("Expected ErrLockTimeNotReached but had %v", err)
+	}
+}
+
+func TestDogecoinWallet_bumpFee(t *testing.T) {
+	w, err := newMockWallet()
+	w.ws.Start()
+	time.Sleep(time.Second / 2)
+	if err != nil {
+		t.Error(err)
+	}
+	txns, err := w.db.Txns().GetAll(false)
+	if err != nil {
+		t.Error(err)
+	}
+	ch, err := chainhash.NewHashFromStr(txns[2].Txid)
+	if err != nil {
+		t.Error(err)
+	}
+	utxos, err := w.db.Utxos().GetAll()
+	if err != nil {
+		t.Error(err)
+	}
+	for _, u := range utxos {
+		if u.Op.Hash.IsEqual(ch) {
+			u.AtHeight = 0
+			w.db.Utxos().Put(u)
+		}
+	}
+
+	w.db.Txns().UpdateHeight(*ch, 0, time.Now())
+
+	// Test unconfirmed
+	_, err = w.bumpFee(*ch)
+	if err != nil {
+		t.Error(err)
+	}
+
+	err = w.db.Txns().UpdateHeight(*ch, 1289597, time.Now())
+	if err != nil {
+		t.Error(err)
+	}
+
+	// Test confirmed
+	_, err = w.bumpFee(*ch)
+	if err == nil {
+		t.Error("Should not be able to bump fee of confirmed txs")
+	}
+}
+
+func TestDogecoinWallet_sweepAddress(t *testing.T) {
+	w, err := newMockWallet()
+	w.ws.Start()
+	time.Sleep(time.Second / 2)
+	if err != nil {
+		t.Error(err)
+	}
+	utxos, err := w.db.Utxos().GetAll()
+	if err != nil {
+		t.Error(err)
+	}
+	var in wallet.TransactionInput
+	var key *hdkeychain.ExtendedKey
+	for _, ut := range utxos {
+		if ut.Value > 0 && !ut.WatchOnly {
+			addr, err := w.ScriptToAddress(ut.ScriptPubkey)
+			if err != nil {
+				t.Error(err)
+			}
+			key, err = w.km.GetKeyForScript(addr.ScriptAddress())
+			if err != nil {
+				t.Error(err)
+			}
+			h, err := hex.DecodeString(ut.Op.Hash.String())
+			if err != nil {
+				t.Error(err)
+			}
+			in = wallet.TransactionInput{
+				LinkedAddress: addr,
+				Value:         ut.Value,
+				OutpointIndex: ut.Op.Index,
+				OutpointHash:  h,
+			}
+			break
+		}
+	}
+	// P2PKH addr
+	_, err = w.sweepAddress([]wallet.TransactionInput{in}, nil, key, nil, wallet.NORMAL)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	// 1 of 2 P2SH
+	for _, ut := range utxos {
+		if ut.Value > 0 && ut.WatchOnly {
+			h, err := hex.DecodeString(ut.Op.Hash.String())
+			if err != nil {
+				t.Error(err)
+			}
+			addr, err := w.ScriptToAddress(ut.ScriptPubkey)
+			if err != nil {
+				t.Error(err)
+			}
+			in = wallet.TransactionInput{
+				LinkedAddress: addr,
+				Value:         ut.Value,
+				OutpointIndex: ut.Op.Index,
+				OutpointHash:  h,
+			}
+		}
+	}
+	key1, err := w.km.GetFreshKey(wallet.INTERNAL)
+	if err != nil {
+		t.Error(err)
+	}
+
+	key2, err := w.km.GetFreshKey(wallet.INTERNAL)
+	if err != nil {
+		t.Error(err)
+	}
+	_, redeemScript, err := w.GenerateMultisigScript([]hdkeychain.ExtendedKey{*key1, *key2}, 1, 0, nil)
+	if err != nil {
+		t.Error(err)
+	}
+	_, err = w.sweepAddress([]wallet.TransactionInput{in}, nil, key1, &redeemScript, wallet.NORMAL)
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDogecoinWallet_estimateSpendFee(t *testing.T) {
+	w, err := newMockWallet()
+	w.ws.Start()
+	time.Sleep(time.Second / 2)
+	if err != nil {
+		t.Error(err)
+	}
+	fee, err := w.estimateSpendFee(DustLimit, wallet.NORMAL)
+	if err != nil {
+		t.Error(err)
+	}
+	if fee <= 0 {
+		t.Error("Returned incorrect fee")
+	}
+}
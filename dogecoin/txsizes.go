@@ -0,0 +1,172 @@
+package dogecoin
+
+import (
+	"github.com/btcsuite/btcd/wire"
+
+	"github.com/muecoin/multiwallet/htlc"
+	"github.com/muecoin/multiwallet/multisig"
+)
+
+// Worst case script and input/output size estimates. Dogecoin has no
+// segregated witness so signature scripts count in full towards the fee.
+const (
+	// RedeemP2PKHSigScriptSize is the worst case (largest) serialize size
+	// of a transaction input script that redeems a compressed P2PKH output.
+	// It is calculated as:
+	//
+	//   - OP_DATA_73
+	//   - 72 bytes DER signature + 1 byte sighash
+	//   - OP_DATA_33
+	//   - 33 bytes serialized compressed pubkey
+	RedeemP2PKHSigScriptSize = 1 + 73 + 1 + 33
+
+	// P2PKHPkScriptSize is the size of a transaction output script that
+	// pays to a compressed pubkey hash.  It is calculated as:
+	//
+	//   - OP_DUP
+	//   - OP_HASH160
+	//   - OP_DATA_20
+	//   - 20 bytes pubkey hash
+	//   - OP_EQUALVERIFY
+	//   - OP_CHECKSIG
+	P2PKHPkScriptSize = 1 + 1 + 1 + 20 + 1 + 1
+
+	// RedeemP2PKHInputSize is the worst case (largest) serialize size of a
+	// transaction input redeeming a compressed P2PKH output.  It is
+	// calculated as:
+	//
+	//   - 32 bytes previous tx
+	//   - 4 bytes output index
+	//   - 1 byte script len
+	//   - signature script
+	//   - 4 bytes sequence
+	RedeemP2PKHInputSize = 32 + 4 + 1 + RedeemP2PKHSigScriptSize + 4
+
+	// P2PKHOutputSize is the serialize size of a transaction output with a
+	// P2PKH output script.  It is calculated as:
+	//
+	//   - 8 bytes output value
+	//   - 1 byte compact int encoding value 25
+	//   - 25 bytes P2PKH output script
+	P2PKHOutputSize = 8 + 1 + P2PKHPkScriptSize
+)
+
+type InputType int
+
+const (
+	P2PKH InputType = iota
+	P2SH_1of2_Multisig
+	P2SH_2of3_Multisig
+	P2SH_Multisig_Timelock_1Sig
+	P2SH_Multisig_Timelock_2Sigs
+)
+
+// EstimateSerializeSize returns a worst case serialize size estimate for a
+// signed transaction that spends inputCount number of inputs of the given
+// type and contains each transaction output from txOuts.  The estimated size
+// is incremented for an additional P2PKH change output if addChangeOutput is
+// true.
+func EstimateSerializeSize(inputCount int, txOuts []*wire.TxOut, addChangeOutput bool, inputType InputType) int {
+	changeSize := 0
+	outputCount := len(txOuts)
+	if addChangeOutput {
+		changeSize = P2PKHOutputSize
+		outputCount++
+	}
+
+	// 8 additional bytes are for version and locktime
+	return 8 + wire.VarIntSerializeSize(uint64(inputCount)) +
+		wire.VarIntSerializeSize(uint64(outputCount)) +
+		inputCount*RedeemInputSize(inputType) +
+		SumOutputSerializeSizes(txOuts) +
+		changeSize
+}
+
+// RedeemInputSize returns the worst case serialize size of an input of the
+// given type.
+func RedeemInputSize(inputType InputType) int {
+	switch inputType {
+	case P2SH_1of2_Multisig:
+		return RedeemMultisigInputSize(1, 2, false)
+	case P2SH_2of3_Multisig:
+		return RedeemMultisigInputSize(2, 3, false)
+	case P2SH_Multisig_Timelock_1Sig:
+		return RedeemTimeoutInputSize(2, 3)
+	case P2SH_Multisig_Timelock_2Sigs:
+		return RedeemMultisigInputSize(2, 3, true)
+	}
+	return RedeemP2PKHInputSize
+}
+
+// RedeemMultisigInputSize returns the worst case serialize size of a
+// transaction input redeeming an m of n P2SH multisig output through the
+// multisig branch.
+func RedeemMultisigInputSize(m, n int, timelocked bool) int {
+	return inputSize(multisig.SigScriptSize(m, n, timelocked, multisig.ECDSASignatureSize))
+}
+
+// EstimateMultisigSerializeSize returns a worst case serialize size estimate
+// for a transaction spending inputCount outputs of the multisig redeem script
+// to txOuts.
+func EstimateMultisigSerializeSize(inputCount int, txOuts []*wire.TxOut, rs *multisig.RedeemScript) int {
+	return 8 + wire.VarIntSerializeSize(uint64(inputCount)) +
+		wire.VarIntSerializeSize(uint64(len(txOuts))) +
+		inputCount*RedeemMultisigInputSize(rs.Threshold, len(rs.PubKeys), rs.Timelocked) +
+		SumOutputSerializeSizes(txOuts)
+}
+
+// RedeemTimeoutInputSize returns the worst case serialize size of a
+// transaction input spending the timeout branch of an m of n P2SH escrow.
+func RedeemTimeoutInputSize(m, n int) int {
+	return inputSize(multisig.TimeoutSigScriptSize(m, n, multisig.ECDSASignatureSize))
+}
+
+// EstimateTimeoutSerializeSize returns a worst case serialize size estimate
+// for a transaction spending inputCount outputs of the escrow redeem script
+// through its timeout branch to txOuts.
+func EstimateTimeoutSerializeSize(inputCount int, txOuts []*wire.TxOut, rs *multisig.RedeemScript) int {
+	return 8 + wire.VarIntSerializeSize(uint64(inputCount)) +
+		wire.VarIntSerializeSize(uint64(len(txOuts))) +
+		inputCount*RedeemTimeoutInputSize(rs.Threshold, len(rs.PubKeys)) +
+		SumOutputSerializeSizes(txOuts)
+}
+
+// RedeemHTLCInputSize returns the worst case serialize size of a transaction
+// input spending a P2SH hash time locked contract, either redeeming it with
+// the secret or refunding it.
+func RedeemHTLCInputSize(redeem bool) int {
+	if redeem {
+		return inputSize(htlc.RedeemSigScriptSize(htlc.ECDSASignatureSize))
+	}
+	return inputSize(htlc.RefundSigScriptSize(htlc.ECDSASignatureSize))
+}
+
+// EstimateHTLCSerializeSize returns a worst case serialize size estimate for
+// a transaction spending inputCount outputs of a hash time locked contract to
+// txOuts.
+func EstimateHTLCSerializeSize(inputCount int, txOuts []*wire.TxOut, redeem bool) int {
+	return 8 + wire.VarIntSerializeSize(uint64(inputCount)) +
+		wire.VarIntSerializeSize(uint64(len(txOuts))) +
+		inputCount*RedeemHTLCInputSize(redeem) +
+		SumOutputSerializeSizes(txOuts)
+}
+
+// inputSize returns the serialize size of an input with a signature script
+// of the given size. It is calculated as:
+//
+//   - 32 bytes previous tx
+//   - 4 bytes output index
+//   - compact int encoding the script length
+//   - signature script
+//   - 4 bytes sequence
+func inputSize(sigScriptSize int) int {
+	return 32 + 4 + wire.VarIntSerializeSize(uint64(sigScriptSize)) + sigScriptSize + 4
+}
+
+// SumOutputSerializeSizes sums up the serialized size of the supplied outputs.
+func SumOutputSerializeSizes(outputs []*wire.TxOut) (serializeSize int) {
+	for _, txOut := range outputs {
+		serializeSize += txOut.SerializeSize()
+	}
+	return serializeSize
+}
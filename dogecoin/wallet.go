@@ -0,0 +1,771 @@
+package dogecoin
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/muecoin/multiwallet/cache"
+	"github.com/muecoin/multiwallet/client"
+	"github.com/muecoin/multiwallet/config"
+	daddr "github.com/muecoin/multiwallet/dogecoin/address"
+	"github.com/muecoin/multiwallet/htlc"
+	"github.com/muecoin/multiwallet/keys"
+	"github.com/muecoin/multiwallet/model"
+	"github.com/muecoin/multiwallet/multisig"
+	"github.com/muecoin/multiwallet/rates"
+	"github.com/muecoin/multiwallet/registry"
+	"github.com/muecoin/multiwallet/service"
+	"github.com/muecoin/multiwallet/util"
+	wi "github.com/OpenBazaar/wallet-interface"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	hd "github.com/btcsuite/btcutil/hdkeychain"
+	"github.com/tyler-smith/go-bip39"
+	"golang.org/x/net/proxy"
+)
+
+type DogecoinWallet struct {
+	db     wi.Datastore
+	km     *keys.KeyManager
+	params *chaincfg.Params
+	client model.APIClient
+	ws     *service.WalletService
+	fp     *util.FeeProvider
+
+	mPrivKey *hd.ExtendedKey
+	mPubKey  *hd.ExtendedKey
+
+	exchangeRates wi.ExchangeRates
+}
+
+func init() {
+	registry.Register(registry.Coin{
+		Name:                "Dogecoin",
+		CoinType:            util.CoinTypeDogecoin,
+		TestnetCoinType:     util.CoinTypeDogecoinTest,
+		CurrencyCode:        "DOGE",
+		TestnetCurrencyCode: "TDOGE",
+		NewWallet:           newWallet,
+		PriceProviders: []string{
+			"kraken+https://api.kraken.com/0/public/Ticker?pair=XDGXBT",
+			"coinmarketcap+https://api.coinmarketcap.com/v2/ticker/74/?convert=BTC",
+			"coingecko+https://api.coingecko.com/api/v3/coins/dogecoin?tickers=false&community_data=false&developer_data=false&sparkline=false",
+		},
+		BlockInterval:   time.Minute,
+		DecodeAddress:   daddr.DecodeAddress,
+		ScriptToAddress: daddr.ExtractPkScriptAddrs,
+		AddressToScript: daddr.PayToAddrScript,
+	})
+}
+
+func newWallet(cfg config.CoinConfig, mnemonic string, params *chaincfg.Params, proxy proxy.Dialer, cache cache.Cacher, disableExchangeRates bool) (wi.Wallet, error) {
+	w, err := NewDogecoinWallet(cfg, mnemonic, params, proxy, cache, disableExchangeRates)
+	if err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func NewDogecoinWallet(cfg config.CoinConfig, mnemonic string, params *chaincfg.Params, proxy proxy.Dialer, cache cache.Cacher, disableExchangeRates bool) (*DogecoinWallet, error) {
+	if err := util.RequireSizeFeeModel(cfg.FeeModel); err != nil {
+		return nil, err
+	}
+	seed := bip39.NewSeed(mnemonic, "")
+
+	mPrivKey, err := hd.NewMaster(seed, params)
+	if err != nil {
+		return nil, err
+	}
+	mPubKey, err := mPrivKey.Neuter()
+	if err != nil {
+		return nil, err
+	}
+	km, err := keys.NewKeyManager(cfg.DB.Keys(), params, mPrivKey, util.CoinTypeDogecoin, dogecoinAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := client.NewClientPoolWithOptions(cfg.ClientAPIs, cfg.Options, proxy)
+	if err != nil {
+		return nil, err
+	}
+
+	wm, err := service.NewWalletService(cfg.DB, km, c, params, util.CoinTypeDogecoin, cache)
+	if err != nil {
+		return nil, err
+	}
+	var er wi.ExchangeRates
+	if !disableExchangeRates {
+		fetcher, err := rates.NewPriceFetcher(cfg.CoinType, cfg.PriceAPIs, proxy)
+		if err != nil {
+			return nil, err
+		}
+		go fetcher.Run()
+		er = fetcher
+		wm.SetExchangeRates(er)
+	}
+
+	fp := util.NewFeeDefaultProvider(cfg.MaxFee, cfg.HighFee, cfg.MediumFee, cfg.LowFee)
+
+	return &DogecoinWallet{cfg.DB, km, params, c, wm, fp, mPrivKey, mPubKey, er}, nil
+}
+
+func dogecoinAddress(key *hd.ExtendedKey, params *chaincfg.Params) (btcutil.Address, error) {
+	addr, err := key.Address(params)
+	if err != nil {
+		return nil, err
+	}
+	return daddr.NewAddressPubKeyHash(addr.ScriptAddress(), params)
+}
+
+func (w *DogecoinWallet) Start() {
+	w.client.Start()
+	w.ws.Start()
+}
+
+func (w *DogecoinWallet) Params() *chaincfg.Params {
+	return w.params
+}
+
+func (w *DogecoinWallet) CurrencyCode() string {
+	if w.params.Name == chaincfg.MainNetParams.Name {
+		return "doge"
+	} else {
+		return "tdoge"
+	}
+}
+
+func (w *DogecoinWallet) IsDust(amount int64) bool {
+	return isDust(amount)
+}
+
+func (w *DogecoinWallet) MasterPrivateKey() *hd.ExtendedKey {
+	return w.mPrivKey
+}
+
+func (w *DogecoinWallet) MasterPublicKey() *hd.ExtendedKey {
+	return w.mPubKey
+}
+
+func (w *DogecoinWallet) ChildKey(keyBytes []byte, chaincode []byte, isPrivateKey bool) (*hd.ExtendedKey, error) {
+	parentFP := []byte{0x00, 0x00, 0x00, 0x00}
+	var id []byte
+	if isPrivateKey {
+		id = w.params.HDPrivateKeyID[:]
+	} else {
+		id = w.params.HDPublicKeyID[:]
+	}
+	hdKey := hd.NewExtendedKey(
+		id,
+		keyBytes,
+		chaincode,
+		parentFP,
+		0,
+		0,
+		isPrivateKey)
+	return hdKey.Child(0)
+}
+
+func (w *DogecoinWallet) CurrentAddress(purpose wi.KeyPurpose) btcutil.Address {
+	key, _ := w.km.GetCurrentKey(purpose)
+	addr, _ := w.km.KeyToAddress(key)
+	return btcutil.Address(addr)
+}
+
+func (w *DogecoinWallet) NewAddress(purpose wi.KeyPurpose) btcutil.Address {
+	i, _ := w.db.Keys().GetUnused(purpose)
+	key, _ := w.km.GenerateChildKey(purpose, uint32(i[1]))
+	addr, _ := w.km.KeyToAddress(key)
+	w.db.Keys().MarkKeyAsUsed(addr.ScriptAddress())
+	return btcutil.Address(addr)
+}
+
+func (w *DogecoinWallet) DecodeAddress(addr string) (btcutil.Address, error) {
+	return daddr.DecodeAddress(addr, w.params)
+}
+
+func (w *DogecoinWallet) ScriptToAddress(script []byte) (btcutil.Address, error) {
+	return daddr.ExtractPkScriptAddrs(script, w.params)
+}
+
+func (w *DogecoinWallet) AddressToScript(addr btcutil.Address) ([]byte, error) {
+	return daddr.PayToAddrScript(addr)
+}
+
+func (w *DogecoinWallet) HasKey(addr btcutil.Address) bool {
+	_, err := w.km.GetKeyForScript(addr.ScriptAddress())
+	if err != nil {
+		return false
+	}
+	return true
+}
+
+func (w *DogecoinWallet) Balance() (confirmed, unconfirmed int64) {
+	utxos, _ := w.db.Utxos().GetAll()
+	txns, _ := w.db.Txns().GetAll(false)
+	return util.CalcBalance(utxos, txns)
+}
+
+func (w *DogecoinWallet) Transactions() ([]wi.Txn, error) {
+	height, _ := w.ChainTip()
+	txns, err := w.db.Txns().GetAll(false)
+	if err != nil {
+		return txns, err
+	}
+	for i, tx := range txns {
+		var confirmations int32
+		var status wi.StatusCode
+		confs := int32(height) - tx.Height + 1
+		if tx.Height <= 0 {
+			confs = tx.Height
+		}
+		switch {
+		case confs < 0:
+			status = wi.StatusDead
+		case confs == 0 && time.Since(tx.Timestamp) <= time.Hour*6:
+			status = wi.StatusUnconfirmed
+		case confs == 0 && time.Since(tx.Timestamp) > time.Hour*6:
+			status = wi.StatusDead
+		case confs > 0 && confs < 60:
+			status = wi.StatusPending
+			confirmations = confs
+		case confs > 59:
+			status = wi.StatusConfirmed
+			confirmations = confs
+		}
+		tx.Confirmations = int64(confirmations)
+		tx.Status = status
+		txns[i] = tx
+	}
+	return txns, nil
+}
+
+func (w *DogecoinWallet) GetTransaction(txid chainhash.Hash) (wi.Txn, error) {
+	txn, err := w.db.Txns().Get(txid)
+	return txn, err
+}
+
+// TransactionData returns the payloads of the OP_RETURN outputs of a wallet
+// transaction.
+func (w *DogecoinWallet) TransactionData(txid chainhash.Hash) ([][]byte, error) {
+	return w.ws.NullData(txid)
+}
+
+// TransactionMetadata returns the reference ID, memo, labels and counterparty
+// recorded for a wallet transaction.
+func (w *DogecoinWallet) TransactionMetadata(txid chainhash.Hash) (service.TxMetadata, bool) {
+	return w.ws.TxMetadata(txid.String())
+}
+
+// SetTransactionMetadata replaces the metadata recorded for a transaction
+func (w *DogecoinWallet) SetTransactionMetadata(md service.TxMetadata) error {
+	return w.ws.SetTxMetadata(md)
+}
+
+// SetAddressLabel labels incoming payments to addr with label
+func (w *DogecoinWallet) SetAddressLabel(addr btcutil.Address, label string) error {
+	return w.ws.SetAddressLabel(addr, label)
+}
+
+// TransactionByReference returns the transaction spent with referenceID
+func (w *DogecoinWallet) TransactionByReference(referenceID string) (wi.Txn, error) {
+	return w.ws.TransactionByReference(referenceID)
+}
+
+// TransactionsWithLabel returns the transactions labeled with label
+func (w *DogecoinWallet) TransactionsWithLabel(label string) ([]wi.Txn, error) {
+	return w.ws.TransactionsWithLabel(label)
+}
+
+// TransactionPage returns a page of the transactions selected by filter, most
+// recent first, and the cursor of the next page.
+func (w *DogecoinWallet) TransactionPage(filter service.TxFilter) ([]wi.Txn, string, error) {
+	return w.ws.TransactionPage(filter)
+}
+
+// ExportTransactions returns the transactions of the wallet from the oldest
+// with their fee, the running balance and their value in currency.
+func (w *DogecoinWallet) ExportTransactions(currency string) ([]service.ExportRow, error) {
+	return w.ws.ExportTransactions(currency)
+}
+
+func (w *DogecoinWallet) ChainTip() (uint32, chainhash.Hash) {
+	return w.ws.ChainTip()
+}
+
+// GetFeePerByte returns the fee rate of feeLevel, no lower than the minimum
+// relay fee rate of Dogecoin nodes.
+func (w *DogecoinWallet) GetFeePerByte(feeLevel wi.FeeLevel) uint64 {
+	return relayFeePerByte(w.fp.GetFeePerByte(feeLevel))
+}
+
+func (w *DogecoinWallet) Spend(amount int64, addr btcutil.Address, feeLevel wi.FeeLevel, referenceID string, spendAll bool) (*chainhash.Hash, error) {
+	return w.SpendWithData(amount, addr, feeLevel, referenceID, nil, spendAll)
+}
+
+// SpendWithData spends like Spend and attaches data of up to 80 bytes to the
+// transaction in an OP_RETURN output.
+func (w *DogecoinWallet) SpendWithData(amount int64, addr btcutil.Address, feeLevel wi.FeeLevel, referenceID string, data []byte, spendAll bool) (*chainhash.Hash, error) {
+	tx, err := w.buildSpendTx(amount, addr, feeLevel, data, spendAll)
+	if err != nil {
+		return nil, err
+	}
+	// Broadcast
+	if err := w.Broadcast(tx); err != nil {
+		return nil, err
+	}
+	ch := tx.TxHash()
+	w.ws.RecordSpend(ch.String(), referenceID, addr)
+	return &ch, nil
+}
+
+// buildSpendTx builds and signs the transaction of SpendWithData
+func (w *DogecoinWallet) buildSpendTx(amount int64, addr btcutil.Address, feeLevel wi.FeeLevel, data []byte, spendAll bool) (*wire.MsgTx, error) {
+	var dataOutput *wire.TxOut
+	if len(data) > 0 {
+		var err error
+		dataOutput, err = util.NullDataOutput(data)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if spendAll {
+		return w.buildSpendAllTx(addr, feeLevel, dataOutput)
+	}
+	return w.buildTx(amount, addr, feeLevel, dataOutput)
+}
+
+// SpendWithRequestID spends like SpendWithData once per request ID. Retrying
+// a request ID returns the txid of its transaction, broadcasting the same
+// transaction again if its broadcast failed.
+func (w *DogecoinWallet) SpendWithRequestID(requestID string, amount int64, addr btcutil.Address, feeLevel wi.FeeLevel, referenceID string, data []byte, spendAll bool) (*chainhash.Hash, error) {
+	return w.spendOnce(requestID, referenceID, []btcutil.Address{addr}, func() (*wire.MsgTx, error) {
+		return w.buildSpendTx(amount, addr, feeLevel, data, spendAll)
+	})
+}
+
+// SpendBatch pays every payment in a single transaction. With a request ID
+// the payments are sent once like SpendWithRequestID.
+func (w *DogecoinWallet) SpendBatch(requestID string, payments []wi.TransactionOutput, feeLevel wi.FeeLevel, referenceID string) (*chainhash.Hash, error) {
+	recipients := make([]btcutil.Address, 0, len(payments))
+	for _, payment := range payments {
+		recipients = append(recipients, payment.Address)
+	}
+	return w.spendOnce(requestID, referenceID, recipients, func() (*wire.MsgTx, error) {
+		return w.buildBatchTx(payments, feeLevel, nil)
+	})
+}
+
+// AbandonSpendRequest gives up the transaction of a request ID whose
+// broadcast failed, so that it is never broadcast and its inputs are left to
+// other spends.
+func (w *DogecoinWallet) AbandonSpendRequest(requestID string) error {
+	return w.ws.AbandonSpendRequest(requestID)
+}
+
+// ConvertFiatOnce converts a fiat amount at the current exchange rate once per
+// request ID, returning the first conversion again for later calls with the
+// same request ID.
+func (w *DogecoinWallet) ConvertFiatOnce(requestID string, fiat rates.FiatAmount) (rates.Conversion, error) {
+	return w.ws.ConvertOnce(requestID, func() (rates.Conversion, error) {
+		return rates.Convert(w.ExchangeRates(), fiat)
+	})
+}
+
+// PlanSpend returns the transaction SpendWithData would build without signing
+// or broadcasting it.
+func (w *DogecoinWallet) PlanSpend(amount int64, addr btcutil.Address, feeLevel wi.FeeLevel, data []byte, spendAll bool) (*util.SpendPlan, error) {
+	var dataOutput *wire.TxOut
+	if len(data) > 0 {
+		var err error
+		dataOutput, err = util.NullDataOutput(data)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if spendAll {
+		return w.planSpendAllTx(addr, feeLevel, dataOutput)
+	}
+	return w.planBatchTx([]wi.TransactionOutput{{Address: addr, Value: amount}}, feeLevel, dataOutput)
+}
+
+// ExecuteSpendPlan signs and broadcasts the transaction of a plan returned by
+// PlanSpend as is. With a request ID the plan is executed once like
+// SpendWithRequestID.
+func (w *DogecoinWallet) ExecuteSpendPlan(requestID string, plan *util.SpendPlan, referenceID string) (*chainhash.Hash, error) {
+	return w.spendOnce(requestID, referenceID, plan.Recipients(), func() (*wire.MsgTx, error) {
+		return w.signPlan(plan)
+	})
+}
+
+// spendOnce signs the transaction returned by build and broadcasts it once per
+// request ID like service.WalletService.SpendOnce
+func (w *DogecoinWallet) spendOnce(requestID, referenceID string, recipients []btcutil.Address, build func() (*wire.MsgTx, error)) (*chainhash.Hash, error) {
+	return w.ws.SpendOnce(requestID, func() (*service.SignedSpend, error) {
+		tx, err := build()
+		if err != nil {
+			return nil, err
+		}
+		var buf bytes.Buffer
+		if err := tx.BtcEncode(&buf, wire.ProtocolVersion, wire.BaseEncoding); err != nil {
+			return nil, err
+		}
+		return service.NewSignedSpend(buf.Bytes(), tx.TxHash(), tx), nil
+	}, func(raw []byte) (*chainhash.Hash, error) {
+		txid, err := w.BroadcastRawTx(raw)
+		if err != nil {
+			return nil, err
+		}
+		w.ws.RecordSpend(txid.String(), referenceID, recipients...)
+		return txid, nil
+	})
+}
+
+func (w *DogecoinWallet) BumpFee(txid chainhash.Hash) (*chainhash.Hash, error) {
+	return w.bumpFee(txid)
+}
+
+func (w *DogecoinWallet) EstimateFee(ins []wi.TransactionInput, outs []wi.TransactionOutput, feePerByte uint64) uint64 {
+	tx := new(wire.MsgTx)
+	for _, out := range outs {
+		scriptPubKey, _ := daddr.PayToAddrScript(out.Address)
+		output := wire.NewTxOut(out.Value, scriptPubKey)
+		tx.TxOut = append(tx.TxOut, output)
+	}
+	estimatedSize := EstimateSerializeSize(len(ins), tx.TxOut, false, P2PKH)
+	fee := estimatedSize * int(feePerByte)
+	return uint64(fee)
+}
+
+func (w *DogecoinWallet) EstimateSpendFee(amount int64, feeLevel wi.FeeLevel) (uint64, error) {
+	return w.estimateSpendFee(amount, feeLevel)
+}
+
+func (w *DogecoinWallet) SweepAddress(ins []wi.TransactionInput, address *btcutil.Address, key *hd.ExtendedKey, redeemScript *[]byte, feeLevel wi.FeeLevel) (*chainhash.Hash, error) {
+	return w.sweepAddress(ins, address, key, redeemScript, feeLevel)
+}
+
+func (w *DogecoinWallet) CreateMultisigSignature(ins []wi.TransactionInput, outs []wi.TransactionOutput, key *hd.ExtendedKey, redeemScript []byte, feePerByte uint64) ([]wi.Signature, error) {
+	return w.createMultisigSignature(ins, outs, key, redeemScript, feePerByte)
+}
+
+func (w *DogecoinWallet) Multisign(ins []wi.TransactionInput, outs []wi.TransactionOutput, sigs1 []wi.Signature, sigs2 []wi.Signature, redeemScript []byte, feePerByte uint64, broadcast bool) ([]byte, error) {
+	return w.multisign(ins, outs, sigs1, sigs2, redeemScript, feePerByte, broadcast)
+}
+
+func (w *DogecoinWallet) MultisignWithSignatures(ins []wi.TransactionInput, outs []wi.TransactionOutput, sigs multisig.Signatures, redeemScript []byte, feePerByte uint64, broadcast bool) ([]byte, bool, error) {
+	return w.multisignWithSignatures(ins, outs, sigs, redeemScript, feePerByte, broadcast)
+}
+
+func (w *DogecoinWallet) MergeMultisig(redeemScript []byte, txs [][]byte, broadcast bool) ([]byte, bool, error) {
+	return w.mergeMultisig(redeemScript, txs, broadcast)
+}
+
+func (w *DogecoinWallet) ReleaseAfterTimeout(ins []wi.TransactionInput, address btcutil.Address, timeoutKey *hd.ExtendedKey, redeemScript []byte, feeLevel wi.FeeLevel) (*chainhash.Hash, error) {
+	return w.releaseAfterTimeout(ins, address, timeoutKey, redeemScript, feeLevel)
+}
+
+// GenerateHTLCScript returns the address and script of a hash time locked
+// contract paying recipientKey for the preimage of secretHash, or refundKey
+// once the chain reached the lockTime height.
+func (w *DogecoinWallet) GenerateHTLCScript(secretHash, recipientKey, refundKey []byte, lockTime uint32) (btcutil.Address, []byte, error) {
+	return w.generateHTLCScript(secretHash, recipientKey, refundKey, lockTime)
+}
+
+func (w *DogecoinWallet) RedeemHTLC(ins []wi.TransactionInput, address btcutil.Address, key *hd.ExtendedKey, contract []byte, secret []byte, feeLevel wi.FeeLevel) (*chainhash.Hash, error) {
+	if secret == nil {
+		return nil, htlc.ErrSecretMismatch
+	}
+	return w.spendHTLC(ins, address, key, contract, secret, feeLevel)
+}
+
+func (w *DogecoinWallet) RefundHTLC(ins []wi.TransactionInput, address btcutil.Address, key *hd.ExtendedKey, contract []byte, feeLevel wi.FeeLevel) (*chainhash.Hash, error) {
+	return w.spendHTLC(ins, address, key, contract, nil, feeLevel)
+}
+
+func (w *DogecoinWallet) GenerateMultisigScript(keys []hd.ExtendedKey, threshold int, timeout time.Duration, timeoutKey *hd.ExtendedKey) (addr btcutil.Address, redeemScript []byte, err error) {
+	return w.generateMultisigScript(keys, threshold, timeout, timeoutKey)
+}
+
+func (w *DogecoinWallet) AddWatchedAddress(addr btcutil.Address) error {
+	script, err := w.AddressToScript(addr)
+	if err != nil {
+		return err
+	}
+	err = w.db.WatchedScripts().Put(script)
+	if err != nil {
+		return err
+	}
+	w.client.ListenAddress(addr)
+	return nil
+}
+
+func (w *DogecoinWallet) AddWatchedScript(script []byte) error {
+	err := w.db.WatchedScripts().Put(script)
+	if err != nil {
+		return err
+	}
+	addr, err := w.ScriptToAddress(script)
+	if err != nil {
+		return err
+	}
+	w.client.ListenAddress(addr)
+	return nil
+}
+
+func (w *DogecoinWallet) AddTransactionListener(callback func(wi.TransactionCallback)) {
+	w.ws.AddTransactionListener(callback)
+}
+
+// WatchEscrowTimeout watches the escrow address and notifies the escrow
+// timeout listeners once its outputs can be released after the timeout.
+func (w *DogecoinWallet) WatchEscrowTimeout(addr btcutil.Address, redeemScript []byte) error {
+	if err := w.AddWatchedAddress(addr); err != nil {
+		return err
+	}
+	script, err := w.AddressToScript(addr)
+	if err != nil {
+		return err
+	}
+	return w.ws.WatchEscrowTimeout(script, redeemScript)
+}
+
+func (w *DogecoinWallet) AddEscrowTimeoutListener(callback func(service.EscrowTimeout)) {
+	w.ws.AddEscrowTimeoutListener(callback)
+}
+
+// WatchHTLC watches the contract address and calls callback for each input
+// spending its outputs, revealing the secret of a redeem.
+func (w *DogecoinWallet) WatchHTLC(addr btcutil.Address, callback func(service.ScriptSpend)) error {
+	if err := w.AddWatchedAddress(addr); err != nil {
+		return err
+	}
+	w.ws.WatchSpends(addr, callback)
+	return nil
+}
+
+func (w *DogecoinWallet) ReSyncBlockchain(fromTime time.Time) {
+	go w.ws.UpdateState()
+}
+
+func (w *DogecoinWallet) GetConfirmations(txid chainhash.Hash) (uint32, uint32, error) {
+	txn, err := w.db.Txns().Get(txid)
+	if err != nil {
+		return 0, 0, err
+	}
+	if txn.Height == 0 {
+		return 0, 0, nil
+	}
+	chainTip, _ := w.ChainTip()
+	return chainTip - uint32(txn.Height) + 1, uint32(txn.Height), nil
+}
+
+func (w *DogecoinWallet) Close() {
+	w.ws.Stop()
+	w.client.Close()
+	if f, ok := w.exchangeRates.(*rates.Fetcher); ok {
+		f.Stop()
+	}
+}
+
+func (w *DogecoinWallet) ExchangeRates() wi.ExchangeRates {
+	return w.exchangeRates
+}
+
+// BackendStatus returns the health scoreboard of the API servers used by the wallet
+func (w *DogecoinWallet) BackendStatus() []client.BackendStatus {
+	if pool, ok := w.client.(*client.ClientPool); ok {
+		return pool.BackendStatus()
+	}
+	return nil
+}
+
+func (w *DogecoinWallet) DumpTables(wr io.Writer) {
+	fmt.Fprintln(wr, "Transactions-----")
+	txns, _ := w.db.Txns().GetAll(true)
+	for _, tx := range txns {
+		fmt.Fprintf(wr, "Hash: %s, Height: %d, Value: %d, WatchOnly: %t\n", tx.Txid, int(tx.Height), int(tx.Value), tx.WatchOnly)
+	}
+	fmt.Fprintln(wr, "\nUtxos-----")
+	utxos, _ := w.db.Utxos().GetAll()
+	for _, u := range utxos {
+		fmt.Fprintf(wr, "Hash: %s, Index: %d, Height: %d, Value: %d, WatchOnly: %t\n", u.Op.Hash.String(), int(u.Op.Index), int(u.AtHeight), int(u.Value), u.WatchOnly)
+	}
+}
+
+// Build a client.Transaction so we can ingest it into the wallet service then broadcast
+func (w *DogecoinWallet) Broadcast(tx *wire.MsgTx) error {
+	var buf bytes.Buffer
+	tx.BtcEncode(&buf, wire.ProtocolVersion, wire.BaseEncoding)
+	cTxn, err := w.modelTransaction(tx, tx.TxHash().String(), buf.Bytes())
+	if err != nil {
+		return err
+	}
+	_, err = w.client.Broadcast(buf.Bytes())
+	if err != nil {
+		return err
+	}
+	w.ws.ProcessIncomingTransaction(cTxn)
+	return nil
+}
+
+// modelTransaction converts tx into the form the wallet service ingests.
+// Inputs spending outputs the wallet doesn't hold are left without an address,
+// as are outputs whose script doesn't pay to one.
+func (w *DogecoinWallet) modelTransaction(tx *wire.MsgTx, txid string, raw []byte) (model.Transaction, error) {
+	cTxn := model.Transaction{
+		Txid:          txid,
+		Locktime:      int(tx.LockTime),
+		Version:       int(tx.Version),
+		Confirmations: 0,
+		Time:          time.Now().Unix(),
+		RawBytes:      raw,
+	}
+	utxos, err := w.db.Utxos().GetAll()
+	if err != nil {
+		return cTxn, err
+	}
+	for n, in := range tx.TxIn {
+		input := model.Input{
+			Txid: in.PreviousOutPoint.Hash.String(),
+			Vout: int(in.PreviousOutPoint.Index),
+			ScriptSig: model.Script{
+				Hex: hex.EncodeToString(in.SignatureScript),
+			},
+			Sequence: uint32(in.Sequence),
+			N:        n,
+		}
+		for _, u := range utxos {
+			if !util.OutPointsEqual(u.Op, in.PreviousOutPoint) {
+				continue
+			}
+			if addr, err := w.ScriptToAddress(u.ScriptPubkey); err == nil {
+				input.Addr = addr.String()
+			}
+			input.Satoshis = u.Value
+			input.Value = float64(u.Value) / util.SatoshisPerCoin(util.CoinTypeDogecoin.ToCoinType())
+			break
+		}
+		cTxn.Inputs = append(cTxn.Inputs, input)
+	}
+	for n, out := range tx.TxOut {
+		output := model.Output{
+			N: n,
+			ScriptPubKey: model.OutScript{
+				Script: model.Script{
+					Hex: hex.EncodeToString(out.PkScript),
+				},
+			},
+			Value: float64(out.Value) / util.SatoshisPerCoin(util.CoinTypeDogecoin.ToCoinType()),
+		}
+		if addr, err := w.ScriptToAddress(out.PkScript); err == nil {
+			output.ScriptPubKey.Addresses = []string{addr.String()}
+		} else if _, ok := util.ExtractNullData(out.PkScript); ok {
+			output.ScriptPubKey.Type = "nulldata"
+		}
+		cTxn.Outputs = append(cTxn.Outputs, output)
+	}
+	return cTxn, nil
+}
+
+// DecodeRawTx decodes a serialized transaction. Its fee is only computed when
+// the wallet knows every output it spends.
+func (w *DogecoinWallet) DecodeRawTx(raw []byte) (*util.DecodedTx, error) {
+	return w.decodeTx(raw, false)
+}
+
+// TransactionDetails decodes a wallet transaction and tells which of its
+// outputs pay the wallet. Outputs it spends the wallet doesn't store are
+// fetched from the backend.
+func (w *DogecoinWallet) TransactionDetails(txid chainhash.Hash) (*util.TxDetails, error) {
+	txn, err := w.db.Txns().Get(txid)
+	if err != nil {
+		return nil, err
+	}
+	decoded, err := w.decodeTx(txn.Bytes, true)
+	if err != nil {
+		return nil, err
+	}
+	confirmations, _, err := w.GetConfirmations(txid)
+	if err != nil {
+		return nil, err
+	}
+	return util.NewTxDetails(decoded, confirmations, w.outputOwner), nil
+}
+
+func (w *DogecoinWallet) decodeTx(raw []byte, fetchPrevOuts bool) (*util.DecodedTx, error) {
+	tx, vsize, err := parseRawTx(raw)
+	if err != nil {
+		return nil, err
+	}
+	utxos, err := w.db.Utxos().GetAll()
+	if err != nil {
+		return nil, err
+	}
+	txns, err := w.db.Txns().GetAll(true)
+	if err != nil {
+		return nil, err
+	}
+	parse := func(b []byte) (*wire.MsgTx, error) {
+		prev, _, err := parseRawTx(b)
+		return prev, err
+	}
+	prevOuts := util.KnownPrevOuts(tx, utxos, txns, parse)
+	if fetchPrevOuts {
+		util.FetchPrevOuts(tx, prevOuts, w.client.GetRawTransaction, parse)
+	}
+	return util.DecodeTx(tx, tx.TxHash(), len(raw), vsize, prevOuts, w.ScriptToAddress), nil
+}
+
+// outputOwner tells whether addr is a receiving or a change address of the
+// wallet.
+func (w *DogecoinWallet) outputOwner(addr btcutil.Address) util.OutputOwner {
+	path, err := w.db.Keys().GetPathForKey(addr.ScriptAddress())
+	if err != nil {
+		return util.OwnerExternal
+	}
+	if path.Purpose == wi.INTERNAL {
+		return util.OwnerChange
+	}
+	return util.OwnerWallet
+}
+
+// BroadcastRawTx broadcasts a serialized transaction built outside the wallet.
+// It's ingested like the wallet's own transactions if it touches our addresses.
+func (w *DogecoinWallet) BroadcastRawTx(raw []byte) (*chainhash.Hash, error) {
+	tx, _, err := parseRawTx(raw)
+	if err != nil {
+		return nil, err
+	}
+	txid := tx.TxHash()
+	cTxn, err := w.modelTransaction(tx, txid.String(), raw)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.client.Broadcast(raw); err != nil {
+		return nil, err
+	}
+	if w.ws.IsRelevant(cTxn) {
+		w.ws.ProcessIncomingTransaction(cTxn)
+	}
+	return &txid, nil
+}
+
+// parseRawTx deserializes a raw transaction and returns it with its virtual
+// size, which is its size as Dogecoin has no witness data.
+func parseRawTx(raw []byte) (*wire.MsgTx, int, error) {
+	tx := wire.NewMsgTx(wire.TxVersion)
+	if err := tx.BtcDecode(bytes.NewReader(raw), wire.ProtocolVersion, wire.BaseEncoding); err != nil {
+		return nil, 0, err
+	}
+	if tx.SerializeSize() != len(raw) {
+		return nil, 0, errors.New("transaction has trailing bytes")
+	}
+	return tx, len(raw), nil
+}
@@ -0,0 +1,94 @@
+package ethereum
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/chaincfg"
+	"golang.org/x/crypto/sha3"
+)
+
+// AddressLength is the length in bytes of an Ethereum address
+const AddressLength = 20
+
+// ErrChecksumMismatch is returned when decoding a mixed-case address whose
+// case doesn't match its EIP-55 checksum.
+var ErrChecksumMismatch = errors.New("address checksum mismatch")
+
+// Address is an Ethereum account address. It implements btcutil.Address so
+// it can be passed through the wallet interface. Ethereum addresses are the
+// same on every network.
+type Address [AddressLength]byte
+
+// NewAddress returns the address of a 20 byte slice
+func NewAddress(b []byte) (*Address, error) {
+	if len(b) != AddressLength {
+		return nil, errors.New("address must be 20 bytes")
+	}
+	var addr Address
+	copy(addr[:], b)
+	return &addr, nil
+}
+
+// PubKeyToAddress returns the address of a public key, the last 20 bytes of
+// the Keccak-256 hash of its uncompressed encoding.
+func PubKeyToAddress(pub *btcec.PublicKey) *Address {
+	var addr Address
+	copy(addr[:], keccak256(pub.SerializeUncompressed()[1:])[12:])
+	return &addr
+}
+
+// DecodeAddress decodes a hex address with an optional 0x prefix. Mixed-case
+// addresses must carry a valid EIP-55 checksum.
+func DecodeAddress(s string) (*Address, error) {
+	h := strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X")
+	if len(h) != 2*AddressLength {
+		return nil, errors.New("address must be 40 hex characters")
+	}
+	b, err := hex.DecodeString(h)
+	if err != nil {
+		return nil, err
+	}
+	addr, _ := NewAddress(b)
+	if h != strings.ToLower(h) && h != strings.ToUpper(h) && addr.String()[2:] != h {
+		return nil, ErrChecksumMismatch
+	}
+	return addr, nil
+}
+
+// String returns the address with an EIP-55 checksum
+func (a *Address) String() string {
+	h := []byte(hex.EncodeToString(a[:]))
+	sum := keccak256(h)
+	for i, c := range h {
+		if c >= 'a' && sum[i/2]>>(4*uint(1-i%2))&0x0f >= 8 {
+			h[i] = c - 'a' + 'A'
+		}
+	}
+	return "0x" + string(h)
+}
+
+// EncodeAddress returns the same checksummed encoding as String
+func (a *Address) EncodeAddress() string {
+	return a.String()
+}
+
+// ScriptAddress returns the 20 bytes of the address
+func (a *Address) ScriptAddress() []byte {
+	return a[:]
+}
+
+// IsForNet is true for every network
+func (a *Address) IsForNet(*chaincfg.Params) bool {
+	return true
+}
+
+func keccak256(data ...[]byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	for _, b := range data {
+		h.Write(b)
+	}
+	return h.Sum(nil)
+}
@@ -0,0 +1,495 @@
+package ethereum
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+)
+
+// simulatedChain is an in-process Ethereum node serving the JSON-RPC methods
+// the wallet uses. Sent transactions are validated like a node would and
+// mined in a block of their own, unless mining is paused. It knows ERC-20
+// token contracts by their balances.
+type simulatedChain struct {
+	t      *testing.T
+	server *httptest.Server
+
+	lock     sync.Mutex
+	chainID  *big.Int
+	baseFee  *big.Int
+	tip      *big.Int
+	state    *simState
+	pending  []*Transaction
+	blocks   []*simBlock
+	receipts map[string]*simReceipt
+	logs     []simLog
+
+	// paused leaves sent transactions pending until mine is called, and
+	// laggingNonce hides them from the pending nonce.
+	paused       bool
+	laggingNonce bool
+}
+
+type simState struct {
+	balances map[Address]*big.Int
+	nonces   map[Address]uint64
+	tokens   map[Address]map[Address]*big.Int
+}
+
+type simBlock struct {
+	number uint64
+	time   int64
+	txs    []*Transaction
+}
+
+type simReceipt struct {
+	block   uint64
+	status  uint64
+	gasUsed uint64
+	price   *big.Int
+}
+
+type simLog struct {
+	block    uint64
+	txHash   string
+	contract Address
+	from, to Address
+	amount   *big.Int
+}
+
+const tokenTransferGas = 50000
+
+func newSimulatedChain(t *testing.T) *simulatedChain {
+	c := &simulatedChain{
+		t:       t,
+		chainID: big.NewInt(1337),
+		baseFee: big.NewInt(10e9),
+		tip:     big.NewInt(1e9),
+		state: &simState{
+			balances: make(map[Address]*big.Int),
+			nonces:   make(map[Address]uint64),
+			tokens:   make(map[Address]map[Address]*big.Int),
+		},
+		blocks:   []*simBlock{{number: 0, time: 1600000000}},
+		receipts: make(map[string]*simReceipt),
+	}
+	c.server = httptest.NewServer(http.HandlerFunc(c.serve))
+	return c
+}
+
+func (c *simulatedChain) Close() {
+	c.server.Close()
+}
+
+func (c *simulatedChain) client() *Client {
+	client, err := NewClient([]string{c.server.URL}, nil)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	return client
+}
+
+// fund credits addr with wei
+func (c *simulatedChain) fund(addr *Address, wei *big.Int) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.state.balance(*addr).Add(c.state.balance(*addr), wei)
+}
+
+// deployToken creates a token contract holding amount for owner
+func (c *simulatedChain) deployToken(token, owner *Address, amount *big.Int) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.state.tokens[*token] = map[Address]*big.Int{*owner: new(big.Int).Set(amount)}
+}
+
+func (c *simulatedChain) balance(addr *Address) *big.Int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return new(big.Int).Set(c.state.balance(*addr))
+}
+
+func (c *simulatedChain) tokenBalance(token, addr *Address) *big.Int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.state.tokenBalance(*token, *addr)
+}
+
+func (s *simState) balance(addr Address) *big.Int {
+	if s.balances[addr] == nil {
+		s.balances[addr] = new(big.Int)
+	}
+	return s.balances[addr]
+}
+
+func (s *simState) tokenBalance(token, addr Address) *big.Int {
+	if b := s.tokens[token][addr]; b != nil {
+		return new(big.Int).Set(b)
+	}
+	return new(big.Int)
+}
+
+func (s *simState) copy() *simState {
+	cp := &simState{
+		balances: make(map[Address]*big.Int),
+		nonces:   make(map[Address]uint64),
+		tokens:   make(map[Address]map[Address]*big.Int),
+	}
+	for a, b := range s.balances {
+		cp.balances[a] = new(big.Int).Set(b)
+	}
+	for a, n := range s.nonces {
+		cp.nonces[a] = n
+	}
+	for token, balances := range s.tokens {
+		cp.tokens[token] = make(map[Address]*big.Int)
+		for a, b := range balances {
+			cp.tokens[token][a] = new(big.Int).Set(b)
+		}
+	}
+	return cp
+}
+
+// pendingState is the state after the pending transactions
+func (c *simulatedChain) pendingState() *simState {
+	s := c.state.copy()
+	for _, tx := range c.pending {
+		c.execute(s, tx, 0)
+	}
+	return s
+}
+
+// execute applies tx to s and returns its receipt and transfer log
+func (c *simulatedChain) execute(s *simState, tx *Transaction, block uint64) (*simReceipt, *simLog) {
+	from, _ := tx.Sender()
+	price := new(big.Int).Add(c.baseFee, tx.GasTipCap)
+	if price.Cmp(tx.GasFeeCap) > 0 {
+		price.Set(tx.GasFeeCap)
+	}
+	r := &simReceipt{block: block, status: 1, gasUsed: transferGas, price: price}
+	balances, isToken := s.tokens[*tx.To]
+	if isToken {
+		r.gasUsed = tokenTransferGas
+	}
+	fee := new(big.Int).Mul(price, new(big.Int).SetUint64(r.gasUsed))
+	s.balance(*from).Sub(s.balance(*from), fee)
+	s.nonces[*from]++
+
+	if !isToken {
+		s.balance(*from).Sub(s.balance(*from), tx.Value)
+		s.balance(*tx.To).Add(s.balance(*tx.To), tx.Value)
+		return r, nil
+	}
+	if len(tx.Data) != 68 || !bytes.Equal(tx.Data[:4], transferSelector) {
+		r.status = 0
+		return r, nil
+	}
+	to, _ := NewAddress(tx.Data[16:36])
+	amount := new(big.Int).SetBytes(tx.Data[36:68])
+	if s.tokenBalance(*tx.To, *from).Cmp(amount) < 0 {
+		r.status = 0
+		return r, nil
+	}
+	balances[*from] = new(big.Int).Sub(s.tokenBalance(*tx.To, *from), amount)
+	balances[*to] = new(big.Int).Add(s.tokenBalance(*tx.To, *to), amount)
+	hash, _ := tx.Hash()
+	return r, &simLog{block: block, txHash: hash, contract: *tx.To, from: *from, to: *to, amount: amount}
+}
+
+// mine includes the pending transactions in a new block
+func (c *simulatedChain) mine() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.mineLocked()
+}
+
+func (c *simulatedChain) mineLocked() {
+	prev := c.blocks[len(c.blocks)-1]
+	block := &simBlock{number: prev.number + 1, time: prev.time + 12, txs: c.pending}
+	for _, tx := range c.pending {
+		r, l := c.execute(c.state, tx, block.number)
+		hash, _ := tx.Hash()
+		c.receipts[hash] = r
+		if l != nil {
+			c.logs = append(c.logs, *l)
+		}
+	}
+	c.pending = nil
+	c.blocks = append(c.blocks, block)
+}
+
+// send validates a raw transaction and adds it to the pending transactions.
+// A pending transaction with the same nonce is replaced if the new one pays
+// at least 10% more.
+func (c *simulatedChain) send(raw []byte) (string, error) {
+	tx, err := DecodeTransaction(raw)
+	if err != nil {
+		return "", err
+	}
+	from, err := tx.Sender()
+	if err != nil {
+		return "", err
+	}
+	if tx.ChainID.Cmp(c.chainID) != 0 {
+		return "", errors.New("invalid chain id")
+	}
+	if tx.GasFeeCap.Cmp(c.baseFee) < 0 {
+		return "", errors.New("max fee per gas less than block base fee")
+	}
+	if tx.GasTipCap.Cmp(tx.GasFeeCap) > 0 {
+		return "", errors.New("max priority fee per gas higher than max fee per gas")
+	}
+	if tx.To == nil {
+		return "", errors.New("contract creation is not simulated")
+	}
+	state := c.state.copy()
+	var replaced = -1
+	for i, p := range c.pending {
+		sender, _ := p.Sender()
+		if *sender == *from && p.Nonce == tx.Nonce {
+			if tx.GasTipCap.Cmp(bumpTen(p.GasTipCap)) < 0 || tx.GasFeeCap.Cmp(bumpTen(p.GasFeeCap)) < 0 {
+				return "", errors.New("replacement transaction underpriced")
+			}
+			replaced = i
+			continue
+		}
+		c.execute(state, p, 0)
+	}
+	if replaced < 0 && tx.Nonce != state.nonces[*from] {
+		if tx.Nonce < state.nonces[*from] {
+			return "", errors.New("nonce too low")
+		}
+		return "", errors.New("nonce too high")
+	}
+	if state.balance(*from).Cmp(tx.Cost()) < 0 {
+		return "", errors.New("insufficient funds for gas * price + value")
+	}
+	if replaced >= 0 {
+		c.pending[replaced] = tx
+	} else {
+		c.pending = append(c.pending, tx)
+	}
+	if !c.paused {
+		c.mineLocked()
+	}
+	return tx.Hash()
+}
+
+func bumpTen(fee *big.Int) *big.Int {
+	return new(big.Int).Div(new(big.Int).Mul(fee, big.NewInt(110)), big.NewInt(100))
+}
+
+func (c *simulatedChain) serve(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ID     uint64            `json:"id"`
+		Method string            `json:"method"`
+		Params []json.RawMessage `json:"params"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	c.lock.Lock()
+	result, err := c.handle(req.Method, req.Params)
+	c.lock.Unlock()
+	resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result}
+	if err != nil {
+		resp["error"] = map[string]interface{}{"code": -32000, "message": err.Error()}
+		delete(resp, "result")
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (c *simulatedChain) handle(method string, params []json.RawMessage) (interface{}, error) {
+	str := func(i int) string {
+		var s string
+		if i < len(params) {
+			json.Unmarshal(params[i], &s)
+		}
+		return s
+	}
+	addr := func(s string) Address {
+		a, err := DecodeAddress(s)
+		if err != nil {
+			c.t.Errorf("%s: invalid address %q", method, s)
+			return Address{}
+		}
+		return *a
+	}
+	state := func(tag string) *simState {
+		if tag == "pending" {
+			return c.pendingState()
+		}
+		return c.state
+	}
+	switch method {
+	case "eth_chainId":
+		return encodeBig(c.chainID), nil
+	case "eth_blockNumber":
+		return encodeUint(uint64(len(c.blocks) - 1)), nil
+	case "eth_maxPriorityFeePerGas":
+		return encodeBig(c.tip), nil
+	case "eth_getBalance":
+		return encodeBig(state(str(1)).balance(addr(str(0)))), nil
+	case "eth_getTransactionCount":
+		tag := str(1)
+		if c.laggingNonce {
+			tag = "latest"
+		}
+		return encodeUint(state(tag).nonces[addr(str(0))]), nil
+	case "eth_getBlockByNumber":
+		var full bool
+		json.Unmarshal(params[1], &full)
+		block := c.blocks[len(c.blocks)-1]
+		if tag := str(0); tag != "latest" {
+			n, err := decodeQuantity(tag)
+			if err != nil || n >= uint64(len(c.blocks)) {
+				return nil, nil
+			}
+			block = c.blocks[n]
+		}
+		return c.blockJSON(block, full), nil
+	case "eth_sendRawTransaction":
+		raw, err := hex.DecodeString(strings.TrimPrefix(str(0), "0x"))
+		if err != nil {
+			return nil, err
+		}
+		hash, err := c.send(raw)
+		if err != nil {
+			return nil, err
+		}
+		return "0x" + hash, nil
+	case "eth_getTransactionReceipt":
+		receipt, ok := c.receipts[strings.TrimPrefix(str(0), "0x")]
+		if !ok {
+			return nil, nil
+		}
+		return map[string]string{
+			"blockNumber":       encodeUint(receipt.block),
+			"status":            encodeUint(receipt.status),
+			"gasUsed":           encodeUint(receipt.gasUsed),
+			"effectiveGasPrice": encodeBig(receipt.price),
+		}, nil
+	case "eth_estimateGas", "eth_call":
+		var msg map[string]string
+		json.Unmarshal(params[0], &msg)
+		to := addr(msg["to"])
+		_, isToken := c.state.tokens[to]
+		data, _ := hex.DecodeString(strings.TrimPrefix(msg["data"], "0x"))
+		if method == "eth_estimateGas" {
+			if isToken {
+				return encodeUint(tokenTransferGas), nil
+			}
+			return encodeUint(transferGas), nil
+		}
+		if !isToken || len(data) < 4 {
+			return "0x", nil
+		}
+		switch {
+		case bytes.Equal(data[:4], balanceOfSelector) && len(data) == 36:
+			owner, _ := NewAddress(data[16:36])
+			return "0x" + hex.EncodeToString(padBytes(c.state.tokenBalance(to, *owner), 32)), nil
+		case bytes.Equal(data[:4], decimalsSelector):
+			return "0x" + hex.EncodeToString(padBytes(big.NewInt(18), 32)), nil
+		}
+		return nil, errors.New("execution reverted")
+	case "eth_getLogs":
+		var filter struct {
+			Address   string          `json:"address"`
+			FromBlock string          `json:"fromBlock"`
+			ToBlock   string          `json:"toBlock"`
+			Topics    [][]interface{} `json:"topics"`
+		}
+		if err := json.Unmarshal(params[0], &filter); err != nil {
+			return nil, err
+		}
+		from, _ := decodeQuantity(filter.FromBlock)
+		to, _ := decodeQuantity(filter.ToBlock)
+		logs := []map[string]interface{}{}
+		for _, l := range c.logs {
+			topics := []string{
+				"0x" + hex.EncodeToString(transferTopic),
+				"0x" + hex.EncodeToString(abiAddress(&l.from)),
+				"0x" + hex.EncodeToString(abiAddress(&l.to)),
+			}
+			if l.contract != addr(filter.Address) || l.block < from || l.block > to || !matchTopics(topics, filter.Topics) {
+				continue
+			}
+			logs = append(logs, map[string]interface{}{
+				"address":         l.contract.String(),
+				"topics":          topics,
+				"data":            "0x" + hex.EncodeToString(padBytes(l.amount, 32)),
+				"blockNumber":     encodeUint(l.block),
+				"transactionHash": "0x" + l.txHash,
+			})
+		}
+		return logs, nil
+	}
+	return nil, errors.New("the method " + method + " does not exist")
+}
+
+func matchTopics(topics []string, filter [][]interface{}) bool {
+	for i, accepted := range filter {
+		if len(accepted) == 0 {
+			continue
+		}
+		match := false
+		for _, t := range accepted {
+			match = match || t == topics[i]
+		}
+		if !match {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *simulatedChain) blockJSON(block *simBlock, full bool) map[string]interface{} {
+	txs := []interface{}{}
+	for _, tx := range block.txs {
+		hash, _ := tx.Hash()
+		if !full {
+			txs = append(txs, "0x"+hash)
+			continue
+		}
+		from, _ := tx.Sender()
+		txs = append(txs, map[string]interface{}{
+			"hash":  "0x" + hash,
+			"from":  from.String(),
+			"to":    tx.To.String(),
+			"nonce": encodeUint(tx.Nonce),
+			"value": encodeBig(tx.Value),
+			"input": "0x" + hex.EncodeToString(tx.Data),
+		})
+	}
+	hash := keccak256(big.NewInt(int64(block.number)).Bytes())
+	return map[string]interface{}{
+		"number":        encodeUint(block.number),
+		"hash":          "0x" + hex.EncodeToString(hash),
+		"timestamp":     encodeUint(uint64(block.time)),
+		"baseFeePerGas": encodeBig(c.baseFee),
+		"transactions":  txs,
+	}
+}
+
+func decodeQuantity(s string) (uint64, error) {
+	var n hexUint
+	err := n.UnmarshalJSON([]byte(`"` + s + `"`))
+	return uint64(n), err
+}
+
+// newKey returns a key and its address from a seed byte
+func newKey(t *testing.T, seed byte) (*btcec.PrivateKey, *Address) {
+	t.Helper()
+	key, _ := btcec.PrivKeyFromBytes(btcec.S256(), bytes.Repeat([]byte{seed}, 32))
+	return key, PubKeyToAddress(key.PubKey())
+}
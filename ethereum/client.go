@@ -0,0 +1,455 @@
+package ethereum
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/proxy"
+)
+
+// ErrNotFound is returned for blocks and receipts the node doesn't know
+var ErrNotFound = errors.New("not found")
+
+// RPCError is an error returned by the node
+type RPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("json-rpc error %d: %s", e.Code, e.Message)
+}
+
+// Client is a JSON-RPC client of Ethereum nodes. A request is sent to the
+// next endpoint when an endpoint can't be reached; errors returned by a node
+// are final.
+type Client struct {
+	endpoints []string
+	http      *http.Client
+	id        uint64
+}
+
+// NewClient returns a client of the JSON-RPC endpoints
+func NewClient(endpoints []string, dialer proxy.Dialer) (*Client, error) {
+	if len(endpoints) == 0 {
+		return nil, errors.New("no JSON-RPC endpoints")
+	}
+	dial := net.Dial
+	if dialer != nil {
+		dial = dialer.Dial
+	}
+	return &Client{
+		endpoints: endpoints,
+		http:      &http.Client{Transport: &http.Transport{Dial: dial}, Timeout: time.Minute},
+	}, nil
+}
+
+type rpcRequest struct {
+	JSONRPC string        `json:"jsonrpc"`
+	ID      uint64        `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+type rpcResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *RPCError       `json:"error"`
+}
+
+// call calls method with params and decodes its result into result. A null
+// result returns ErrNotFound.
+func (c *Client) call(result interface{}, method string, params ...interface{}) error {
+	if params == nil {
+		params = []interface{}{}
+	}
+	body, err := json.Marshal(rpcRequest{"2.0", atomic.AddUint64(&c.id, 1), method, params})
+	if err != nil {
+		return err
+	}
+	var resp *rpcResponse
+	for _, endpoint := range c.endpoints {
+		resp, err = c.post(endpoint, body)
+		if err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if resp.Error != nil {
+		return resp.Error
+	}
+	if len(resp.Result) == 0 || string(resp.Result) == "null" {
+		return ErrNotFound
+	}
+	return json.Unmarshal(resp.Result, result)
+}
+
+func (c *Client) post(endpoint string, body []byte) (*rpcResponse, error) {
+	resp, err := c.http.Post(endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned status %s", endpoint, resp.Status)
+	}
+	var r rpcResponse
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// ChainID returns the EIP-155 chain ID signed into transactions
+func (c *Client) ChainID() (*big.Int, error) {
+	var id hexBig
+	err := c.call(&id, "eth_chainId")
+	return id.Int(), err
+}
+
+// BlockNumber returns the height of the chain tip
+func (c *Client) BlockNumber() (uint64, error) {
+	var n hexUint
+	err := c.call(&n, "eth_blockNumber")
+	return uint64(n), err
+}
+
+// Block is a block with the transactions it includes
+type Block struct {
+	Number       uint64
+	Hash         string
+	Timestamp    time.Time
+	BaseFee      *big.Int
+	Transactions []RPCTransaction
+}
+
+// RPCTransaction is a transaction returned by the node
+type RPCTransaction struct {
+	Hash  string
+	From  *Address
+	To    *Address
+	Nonce uint64
+	Value *big.Int
+	Input []byte
+}
+
+type jsonHeader struct {
+	Number    hexUint  `json:"number"`
+	Hash      hexBytes `json:"hash"`
+	Timestamp hexUint  `json:"timestamp"`
+	BaseFee   *hexBig  `json:"baseFeePerGas"`
+}
+
+type jsonBlock struct {
+	jsonHeader
+	Transactions []struct {
+		Hash  hexBytes `json:"hash"`
+		From  *Address `json:"from"`
+		To    *Address `json:"to"`
+		Nonce hexUint  `json:"nonce"`
+		Value hexBig   `json:"value"`
+		Input hexBytes `json:"input"`
+	} `json:"transactions"`
+}
+
+// BlockByNumber returns the block at height with its transactions
+func (c *Client) BlockByNumber(height uint64) (*Block, error) {
+	return c.block(encodeUint(height), true)
+}
+
+// LatestBlock returns the chain tip without its transactions
+func (c *Client) LatestBlock() (*Block, error) {
+	return c.block("latest", false)
+}
+
+func (c *Client) block(tag string, full bool) (*Block, error) {
+	// Without its transactions a block lists their hashes
+	var b jsonBlock
+	var result interface{} = &b
+	if !full {
+		result = &b.jsonHeader
+	}
+	if err := c.call(result, "eth_getBlockByNumber", tag, full); err != nil {
+		return nil, err
+	}
+	block := &Block{
+		Number:    uint64(b.Number),
+		Hash:      hex.EncodeToString(b.Hash),
+		Timestamp: time.Unix(int64(b.Timestamp), 0),
+	}
+	if b.BaseFee != nil {
+		block.BaseFee = b.BaseFee.Int()
+	}
+	for _, tx := range b.Transactions {
+		block.Transactions = append(block.Transactions, RPCTransaction{
+			Hash:  hex.EncodeToString(tx.Hash),
+			From:  tx.From,
+			To:    tx.To,
+			Nonce: uint64(tx.Nonce),
+			Value: tx.Value.Int(),
+			Input: tx.Input,
+		})
+	}
+	return block, nil
+}
+
+// BalanceAt returns the balance in wei of addr at the "latest" or "pending"
+// block.
+func (c *Client) BalanceAt(addr *Address, tag string) (*big.Int, error) {
+	var balance hexBig
+	err := c.call(&balance, "eth_getBalance", addr.String(), tag)
+	return balance.Int(), err
+}
+
+// NonceAt returns the number of transactions sent by addr at the "latest" or
+// "pending" block.
+func (c *Client) NonceAt(addr *Address, tag string) (uint64, error) {
+	var nonce hexUint
+	err := c.call(&nonce, "eth_getTransactionCount", addr.String(), tag)
+	return uint64(nonce), err
+}
+
+// SuggestGasTipCap returns the priority fee per gas in wei the node suggests
+func (c *Client) SuggestGasTipCap() (*big.Int, error) {
+	var tip hexBig
+	err := c.call(&tip, "eth_maxPriorityFeePerGas")
+	return tip.Int(), err
+}
+
+// CallMsg is a message executed by EstimateGas and CallContract
+type CallMsg struct {
+	From  *Address
+	To    *Address
+	Value *big.Int
+	Data  []byte
+}
+
+func (msg CallMsg) args() map[string]string {
+	args := make(map[string]string)
+	if msg.From != nil {
+		args["from"] = msg.From.String()
+	}
+	if msg.To != nil {
+		args["to"] = msg.To.String()
+	}
+	if msg.Value != nil {
+		args["value"] = encodeBig(msg.Value)
+	}
+	if len(msg.Data) > 0 {
+		args["data"] = "0x" + hex.EncodeToString(msg.Data)
+	}
+	return args
+}
+
+// EstimateGas returns the gas needed to execute msg
+func (c *Client) EstimateGas(msg CallMsg) (uint64, error) {
+	var gas hexUint
+	err := c.call(&gas, "eth_estimateGas", msg.args())
+	return uint64(gas), err
+}
+
+// CallContract executes msg at the latest block without a transaction and
+// returns its output.
+func (c *Client) CallContract(msg CallMsg) ([]byte, error) {
+	var out hexBytes
+	err := c.call(&out, "eth_call", msg.args(), "latest")
+	return out, err
+}
+
+// SendRawTransaction broadcasts a signed transaction and returns its hash
+func (c *Client) SendRawTransaction(raw []byte) (string, error) {
+	var hash hexBytes
+	err := c.call(&hash, "eth_sendRawTransaction", "0x"+hex.EncodeToString(raw))
+	return hex.EncodeToString(hash), err
+}
+
+// Receipt is the outcome of a mined transaction
+type Receipt struct {
+	BlockNumber uint64
+	// Status is 1 if the transaction succeeded and 0 if it reverted
+	Status            uint64
+	GasUsed           uint64
+	EffectiveGasPrice *big.Int
+}
+
+// Fee returns the fee in wei paid by the transaction
+func (r *Receipt) Fee() *big.Int {
+	return new(big.Int).Mul(r.EffectiveGasPrice, new(big.Int).SetUint64(r.GasUsed))
+}
+
+// TransactionReceipt returns the receipt of a mined transaction, or
+// ErrNotFound if it isn't mined.
+func (c *Client) TransactionReceipt(hash string) (*Receipt, error) {
+	var r struct {
+		BlockNumber       hexUint `json:"blockNumber"`
+		Status            hexUint `json:"status"`
+		GasUsed           hexUint `json:"gasUsed"`
+		EffectiveGasPrice hexBig  `json:"effectiveGasPrice"`
+	}
+	if err := c.call(&r, "eth_getTransactionReceipt", "0x"+strings.TrimPrefix(hash, "0x")); err != nil {
+		return nil, err
+	}
+	return &Receipt{
+		BlockNumber:       uint64(r.BlockNumber),
+		Status:            uint64(r.Status),
+		GasUsed:           uint64(r.GasUsed),
+		EffectiveGasPrice: r.EffectiveGasPrice.Int(),
+	}, nil
+}
+
+// EventLog is an event emitted by a contract
+type EventLog struct {
+	Address     *Address
+	Topics      [][]byte
+	Data        []byte
+	BlockNumber uint64
+	TxHash      string
+}
+
+// FilterLogs returns the logs of contract between the fromBlock and toBlock
+// heights. Each entry of topics lists the accepted values of the topic at
+// its position; an empty entry accepts any value.
+func (c *Client) FilterLogs(contract *Address, fromBlock, toBlock uint64, topics [][][]byte) ([]EventLog, error) {
+	var filterTopics []interface{}
+	for _, accepted := range topics {
+		if len(accepted) == 0 {
+			filterTopics = append(filterTopics, nil)
+			continue
+		}
+		var values []string
+		for _, t := range accepted {
+			values = append(values, "0x"+hex.EncodeToString(t))
+		}
+		filterTopics = append(filterTopics, values)
+	}
+	filter := map[string]interface{}{
+		"address":   contract.String(),
+		"fromBlock": encodeUint(fromBlock),
+		"toBlock":   encodeUint(toBlock),
+		"topics":    filterTopics,
+	}
+	var logs []struct {
+		Address     *Address   `json:"address"`
+		Topics      []hexBytes `json:"topics"`
+		Data        hexBytes   `json:"data"`
+		BlockNumber hexUint    `json:"blockNumber"`
+		TxHash      hexBytes   `json:"transactionHash"`
+	}
+	if err := c.call(&logs, "eth_getLogs", filter); err != nil && err != ErrNotFound {
+		return nil, err
+	}
+	var ret []EventLog
+	for _, l := range logs {
+		entry := EventLog{
+			Address:     l.Address,
+			Data:        l.Data,
+			BlockNumber: uint64(l.BlockNumber),
+			TxHash:      hex.EncodeToString(l.TxHash),
+		}
+		for _, t := range l.Topics {
+			entry.Topics = append(entry.Topics, []byte(t))
+		}
+		ret = append(ret, entry)
+	}
+	return ret, nil
+}
+
+// The node encodes quantities and byte strings as 0x prefixed hex
+
+type hexUint uint64
+
+func (u *hexUint) UnmarshalJSON(b []byte) error {
+	s, err := unquoteHex(b)
+	if err != nil {
+		return err
+	}
+	n, err := strconv.ParseUint(s, 16, 64)
+	if err != nil {
+		return err
+	}
+	*u = hexUint(n)
+	return nil
+}
+
+type hexBig big.Int
+
+func (h *hexBig) UnmarshalJSON(b []byte) error {
+	s, err := unquoteHex(b)
+	if err != nil {
+		return err
+	}
+	if _, ok := (*big.Int)(h).SetString(s, 16); !ok {
+		return fmt.Errorf("invalid quantity %s", b)
+	}
+	return nil
+}
+
+// Int returns the value of h, or zero if h is nil
+func (h *hexBig) Int() *big.Int {
+	if h == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Set((*big.Int)(h))
+}
+
+type hexBytes []byte
+
+func (h *hexBytes) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	if !strings.HasPrefix(s, "0x") {
+		return fmt.Errorf("hex string %s lacks the 0x prefix", s)
+	}
+	decoded, err := hex.DecodeString(s[2:])
+	if err != nil {
+		return err
+	}
+	*h = decoded
+	return nil
+}
+
+// UnmarshalJSON decodes a hex address
+func (a *Address) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	addr, err := DecodeAddress(s)
+	if err != nil {
+		return err
+	}
+	*a = *addr
+	return nil
+}
+
+func unquoteHex(b []byte) (string, error) {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return "", err
+	}
+	if !strings.HasPrefix(s, "0x") || len(s) == 2 {
+		return "", fmt.Errorf("invalid quantity %s", s)
+	}
+	return s[2:], nil
+}
+
+func encodeUint(n uint64) string {
+	return "0x" + strconv.FormatUint(n, 16)
+}
+
+func encodeBig(n *big.Int) string {
+	return "0x" + n.Text(16)
+}
@@ -0,0 +1,147 @@
+package ethereum
+
+import (
+	"errors"
+	"math/big"
+	"sort"
+
+	wi "github.com/OpenBazaar/wallet-interface"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcutil"
+)
+
+// The ERC-20 functions and event used by the wallet, identified by the
+// Keccak-256 hash of their signature.
+var (
+	balanceOfSelector = keccak256([]byte("balanceOf(address)"))[:4]
+	transferSelector  = keccak256([]byte("transfer(address,uint256)"))[:4]
+	decimalsSelector  = keccak256([]byte("decimals()"))[:4]
+	transferTopic     = keccak256([]byte("Transfer(address,address,uint256)"))
+)
+
+// TokenTransfer is a transfer of ERC-20 tokens to or from the wallet
+type TokenTransfer struct {
+	Txid   string
+	Height uint64
+	From   *Address
+	To     *Address
+	// Amount is in the smallest unit of the token
+	Amount *big.Int
+}
+
+// TokenBalance returns the balance of the wallet in the smallest unit of the
+// token contract.
+func (w *EthereumWallet) TokenBalance(token *Address) (*big.Int, error) {
+	out, err := w.client.CallContract(CallMsg{To: token, Data: abiCall(balanceOfSelector, abiAddress(w.addr))})
+	if err != nil {
+		return nil, err
+	}
+	return abiUint(out)
+}
+
+// TokenDecimals returns the number of decimals of the token contract
+func (w *EthereumWallet) TokenDecimals(token *Address) (uint8, error) {
+	out, err := w.client.CallContract(CallMsg{To: token, Data: abiCall(decimalsSelector)})
+	if err != nil {
+		return 0, err
+	}
+	decimals, err := abiUint(out)
+	if err != nil {
+		return 0, err
+	}
+	if decimals.BitLen() > 8 {
+		return 0, errors.New("invalid token decimals")
+	}
+	return uint8(decimals.Uint64()), nil
+}
+
+// TransferToken sends amount of the token contract to addr. The fee is paid
+// in ether.
+func (w *EthereumWallet) TransferToken(token *Address, amount *big.Int, addr btcutil.Address, feeLevel wi.FeeLevel, referenceID string) (*chainhash.Hash, error) {
+	to, err := toAddress(addr)
+	if err != nil {
+		return nil, err
+	}
+	if amount.Sign() <= 0 || amount.BitLen() > 256 {
+		return nil, errors.New("invalid token amount")
+	}
+	balance, err := w.TokenBalance(token)
+	if err != nil {
+		return nil, err
+	}
+	if balance.Cmp(amount) < 0 {
+		return nil, wi.ErrorInsuffientFunds
+	}
+	return w.send(token, new(big.Int), abiCall(transferSelector, abiAddress(to), padBytes(amount, 32)), feeLevel, false, referenceID)
+}
+
+// TokenTransfers returns the transfers of the token contract to and from the
+// wallet since fromHeight, oldest first.
+func (w *EthereumWallet) TokenTransfers(token *Address, fromHeight uint64) ([]TokenTransfer, error) {
+	tip, err := w.client.BlockNumber()
+	if err != nil {
+		return nil, err
+	}
+	topic := [][]byte{transferTopic}
+	own := [][]byte{abiAddress(w.addr)}
+	sent, err := w.client.FilterLogs(token, fromHeight, tip, [][][]byte{topic, own})
+	if err != nil {
+		return nil, err
+	}
+	received, err := w.client.FilterLogs(token, fromHeight, tip, [][][]byte{topic, nil, own})
+	if err != nil {
+		return nil, err
+	}
+	var transfers []TokenTransfer
+	seen := make(map[string]bool)
+	for _, l := range append(sent, received...) {
+		if len(l.Topics) != 3 || len(l.Topics[1]) != 32 || len(l.Topics[2]) != 32 {
+			continue
+		}
+		// A transfer to self is returned by both queries
+		key := l.TxHash + string(l.Topics[1]) + string(l.Topics[2]) + string(l.Data)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		from, _ := NewAddress(l.Topics[1][12:])
+		to, _ := NewAddress(l.Topics[2][12:])
+		amount, err := abiUint(l.Data)
+		if err != nil {
+			continue
+		}
+		transfers = append(transfers, TokenTransfer{
+			Txid:   l.TxHash,
+			Height: l.BlockNumber,
+			From:   from,
+			To:     to,
+			Amount: amount,
+		})
+	}
+	sort.SliceStable(transfers, func(i, j int) bool {
+		return transfers[i].Height < transfers[j].Height
+	})
+	return transfers, nil
+}
+
+// abiCall encodes the call of the function with selector
+func abiCall(selector []byte, args ...[]byte) []byte {
+	data := append([]byte{}, selector...)
+	for _, arg := range args {
+		data = append(data, arg...)
+	}
+	return data
+}
+
+// abiAddress encodes an address as a 32 byte ABI word
+func abiAddress(addr *Address) []byte {
+	return append(make([]byte, 12), addr[:]...)
+}
+
+// abiUint decodes a uint256 ABI word
+func abiUint(word []byte) (*big.Int, error) {
+	if len(word) != 32 {
+		return nil, errors.New("invalid uint256 encoding")
+	}
+	return new(big.Int).SetBytes(word), nil
+}
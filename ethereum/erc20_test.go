@@ -0,0 +1,59 @@
+package ethereum
+
+import (
+	"math/big"
+	"testing"
+
+	wi "github.com/OpenBazaar/wallet-interface"
+)
+
+func TestEthereumWallet_Tokens(t *testing.T) {
+	chain := newSimulatedChain(t)
+	defer chain.Close()
+	w := newMockWallet(t, chain)
+	chain.fund(w.addr, ether)
+	_, token := newKey(t, 9)
+	_, to := newKey(t, 2)
+	chain.deployToken(token, w.addr, big.NewInt(1000))
+
+	balance, err := w.TokenBalance(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balance.Int64() != 1000 {
+		t.Errorf("Expected a token balance of 1000 but had %s", balance)
+	}
+	decimals, err := w.TokenDecimals(token)
+	if err != nil || decimals != 18 {
+		t.Errorf("Unexpected decimals %d (%v)", decimals, err)
+	}
+
+	txid, err := w.TransferToken(token, big.NewInt(400), to, wi.NORMAL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := chain.tokenBalance(token, to); got.Int64() != 400 {
+		t.Errorf("Recipient received %s tokens", got)
+	}
+	if _, err := w.TransferToken(token, big.NewInt(601), to, wi.NORMAL, ""); err != wi.ErrorInsuffientFunds {
+		t.Errorf("Expected ErrorInsuffientFunds but had %v", err)
+	}
+
+	// The fee of the transfer is paid in ether
+	w.sync()
+	txn, err := w.GetTransaction(*txid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if txn.Value != -11*tokenTransferGas {
+		t.Errorf("Expected the transaction to cost its fee but had %d", txn.Value)
+	}
+
+	transfers, err := w.TokenTransfers(token, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(transfers) != 1 || transfers[0].Txid != txid.String() || *transfers[0].From != *w.addr || *transfers[0].To != *to || transfers[0].Amount.Int64() != 400 {
+		t.Errorf("Unexpected transfers %+v", transfers)
+	}
+}
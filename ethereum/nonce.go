@@ -0,0 +1,36 @@
+package ethereum
+
+import "sync"
+
+// nonceManager assigns the nonces of the wallet's transactions. A node may
+// not count a transaction it was just sent in its pending nonce yet, so the
+// nonce following the last broadcast is kept as well and the higher of the two
+// is used. Spends hold the lock from taking a nonce until the broadcast.
+type nonceManager struct {
+	sync.Mutex
+	next  uint64
+	known bool
+}
+
+// nonce returns the nonce of the next transaction given the pending nonce of
+// the node.
+func (n *nonceManager) nonce(pending uint64) uint64 {
+	if !n.known || pending > n.next {
+		return pending
+	}
+	return n.next
+}
+
+// used records the broadcast of a transaction with nonce
+func (n *nonceManager) used(nonce uint64) {
+	if !n.known || nonce >= n.next {
+		n.next = nonce + 1
+		n.known = true
+	}
+}
+
+// reset trusts the node again. It's used once a transaction was rejected
+// for its nonce, as when the node dropped an earlier transaction.
+func (n *nonceManager) reset() {
+	n.known = false
+}
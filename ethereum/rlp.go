@@ -0,0 +1,130 @@
+package ethereum
+
+import (
+	"errors"
+	"math/big"
+)
+
+// The RLP encoding of https://ethereum.org/en/developers/docs/data-structures-and-encoding/rlp/
+// for the items of transactions: byte strings, unsigned integers and lists.
+
+var errRLP = errors.New("invalid rlp encoding")
+
+// rlpEncode encodes a []byte, uint64, *big.Int, *Address or a []interface{}
+// list of them. A nil *big.Int or *Address encodes as the empty string.
+func rlpEncode(item interface{}) []byte {
+	switch v := item.(type) {
+	case []byte:
+		if len(v) == 1 && v[0] < 0x80 {
+			return []byte{v[0]}
+		}
+		return append(rlpHeader(0x80, len(v)), v...)
+	case uint64:
+		return rlpEncode(new(big.Int).SetUint64(v))
+	case *big.Int:
+		if v == nil {
+			return rlpEncode([]byte{})
+		}
+		return rlpEncode(v.Bytes())
+	case *Address:
+		if v == nil {
+			return rlpEncode([]byte{})
+		}
+		return rlpEncode(v[:])
+	case []interface{}:
+		var payload []byte
+		for _, elem := range v {
+			payload = append(payload, rlpEncode(elem)...)
+		}
+		return append(rlpHeader(0xc0, len(payload)), payload...)
+	}
+	panic("rlp: unsupported type")
+}
+
+func rlpHeader(offset byte, length int) []byte {
+	if length < 56 {
+		return []byte{offset + byte(length)}
+	}
+	l := big.NewInt(int64(length)).Bytes()
+	return append([]byte{offset + 55 + byte(len(l))}, l...)
+}
+
+// rlpDecode decodes an item that spans all of b. Strings decode to []byte and
+// lists to []interface{}.
+func rlpDecode(b []byte) (interface{}, error) {
+	item, rest, err := rlpSplit(b)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) > 0 {
+		return nil, errRLP
+	}
+	return item, nil
+}
+
+func rlpSplit(b []byte) (item interface{}, rest []byte, err error) {
+	if len(b) == 0 {
+		return nil, nil, errRLP
+	}
+	prefix := b[0]
+	switch {
+	case prefix < 0x80:
+		return b[:1], b[1:], nil
+	case prefix < 0xc0:
+		content, rest, err := rlpContent(b, 0x80)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(content) == 1 && content[0] < 0x80 {
+			return nil, nil, errRLP
+		}
+		return content, rest, nil
+	default:
+		content, rest, err := rlpContent(b, 0xc0)
+		if err != nil {
+			return nil, nil, err
+		}
+		list := []interface{}{}
+		for len(content) > 0 {
+			var elem interface{}
+			elem, content, err = rlpSplit(content)
+			if err != nil {
+				return nil, nil, err
+			}
+			list = append(list, elem)
+		}
+		return list, rest, nil
+	}
+}
+
+func rlpContent(b []byte, offset byte) (content, rest []byte, err error) {
+	size := int(b[0] - offset)
+	start := 1
+	if size > 55 {
+		n := size - 55
+		if n > 4 || len(b) < 1+n || b[1] == 0 {
+			return nil, nil, errRLP
+		}
+		size = 0
+		for _, c := range b[1 : 1+n] {
+			size = size<<8 | int(c)
+		}
+		if size < 56 {
+			return nil, nil, errRLP
+		}
+		start += n
+	}
+	if len(b)-start < size {
+		return nil, nil, errRLP
+	}
+	return b[start : start+size], b[start+size:], nil
+}
+
+// rlpUint returns the unsigned integer of a decoded string
+func rlpUint(item interface{}) (*big.Int, error) {
+	b, ok := item.([]byte)
+	if !ok || len(b) > 32 || (len(b) > 0 && b[0] == 0) {
+		return nil, errRLP
+	}
+	return new(big.Int).SetBytes(b), nil
+}
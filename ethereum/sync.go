@@ -0,0 +1,163 @@
+package ethereum
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"time"
+
+	wi "github.com/OpenBazaar/wallet-interface"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+)
+
+const (
+	syncInterval = 15 * time.Second
+
+	// averageBlockTime estimates the height of a point in time
+	averageBlockTime = 12 * time.Second
+
+	// maxScanBlocks is the number of blocks scanned in a pass. Catching up
+	// on a long gap takes several passes.
+	maxScanBlocks = 500
+)
+
+// A node has no index of the transactions of an account, so the wallet scans
+// each block for transactions sending to or from its address and the watched
+// addresses. The first pass starts at the chain tip; ReSyncBlockchain goes
+// back further. Reorganizations aren't followed, a transaction stays at the
+// height it was first seen at.
+
+func (w *EthereumWallet) run() {
+	t := time.NewTicker(syncInterval)
+	defer t.Stop()
+	for {
+		w.sync()
+		select {
+		case <-t.C:
+		case <-w.done:
+			return
+		}
+	}
+}
+
+// sync scans the blocks following the last scanned block
+func (w *EthereumWallet) sync() {
+	w.syncLock.Lock()
+	defer w.syncLock.Unlock()
+	head, err := w.client.LatestBlock()
+	if err != nil {
+		Log.Errorf("error fetching the ethereum chain tip: %s", err)
+		return
+	}
+	w.setTip(head)
+
+	from, ok := w.scanHeight()
+	if !ok {
+		from = head.Number
+	}
+	watched := w.watchedAddresses()
+	for height := from; height <= head.Number && height < from+maxScanBlocks; height++ {
+		block, err := w.client.BlockByNumber(height)
+		if err != nil {
+			Log.Errorf("error fetching ethereum block %d: %s", height, err)
+			return
+		}
+		for _, tx := range block.Transactions {
+			if err := w.processTransaction(block, tx, watched); err != nil {
+				Log.Errorf("error processing ethereum transaction %s: %s", tx.Hash, err)
+				return
+			}
+		}
+		w.setScanHeight(height + 1)
+	}
+}
+
+// processTransaction records tx if it moves ether of the wallet or of a
+// watched address. Its value is the change of the balance, which for a
+// reverted transaction is only its fee.
+func (w *EthereumWallet) processTransaction(block *Block, tx RPCTransaction, watched map[Address]bool) error {
+	fromUs := tx.From != nil && *tx.From == *w.addr
+	toUs := tx.To != nil && *tx.To == *w.addr
+	watchOnly := !fromUs && !toUs
+	if watchOnly {
+		fromUs = tx.From != nil && watched[*tx.From]
+		toUs = tx.To != nil && watched[*tx.To]
+		if !fromUs && !toUs {
+			return nil
+		}
+	}
+	receipt, err := w.client.TransactionReceipt(tx.Hash)
+	if err != nil {
+		return err
+	}
+	value := new(big.Int)
+	if receipt.Status == 1 {
+		if toUs {
+			value.Add(value, tx.Value)
+		}
+		if fromUs {
+			value.Sub(value, tx.Value)
+		}
+	}
+	if fromUs {
+		value.Sub(value, receipt.Fee())
+	}
+	if value.Sign() == 0 && !fromUs {
+		return nil
+	}
+
+	txid, err := chainhash.NewHashFromStr(tx.Hash)
+	if err != nil {
+		return err
+	}
+	var raw []byte
+	if txn, err := w.db.Txns().Get(*txid); err == nil {
+		raw = txn.Bytes
+	}
+	gwei := toGwei(value)
+	if err := w.db.Txns().Put(raw, txid.String(), int(gwei), int(block.Number), block.Timestamp, watchOnly); err != nil {
+		return err
+	}
+	w.notify(wi.TransactionCallback{
+		Txid:      txid.String(),
+		Value:     gwei,
+		Height:    int32(block.Number),
+		Timestamp: block.Timestamp,
+		WatchOnly: watchOnly,
+	})
+	return nil
+}
+
+func (w *EthereumWallet) watchedAddresses() map[Address]bool {
+	watched := make(map[Address]bool)
+	scripts, err := w.db.WatchedScripts().GetAll()
+	if err != nil {
+		return watched
+	}
+	for _, script := range scripts {
+		if addr, err := NewAddress(script); err == nil {
+			watched[*addr] = true
+		}
+	}
+	return watched
+}
+
+func (w *EthereumWallet) scanKey() string {
+	return fmt.Sprintf("scan-height-%s-%s", w.CurrencyCode(), w.addr)
+}
+
+// scanHeight returns the height of the next block to scan
+func (w *EthereumWallet) scanHeight() (uint64, bool) {
+	b, err := w.cache.Get(w.scanKey())
+	if err != nil {
+		return 0, false
+	}
+	height, err := strconv.ParseUint(string(b), 10, 64)
+	return height, err == nil
+}
+
+func (w *EthereumWallet) setScanHeight(height uint64) {
+	if err := w.cache.Set(w.scanKey(), []byte(strconv.FormatUint(height, 10))); err != nil {
+		Log.Errorf("error saving the ethereum scan height: %s", err)
+	}
+}
@@ -0,0 +1,167 @@
+package ethereum
+
+import (
+	"encoding/hex"
+	"errors"
+	"math/big"
+
+	"github.com/btcsuite/btcd/btcec"
+)
+
+// DynamicFeeTxType is the EIP-2718 type of EIP-1559 transactions
+const DynamicFeeTxType = 0x02
+
+// Transaction is an EIP-1559 transaction. Amounts are in wei.
+type Transaction struct {
+	ChainID *big.Int
+	Nonce   uint64
+
+	// GasTipCap is the maximum priority fee per gas paid to the miner and
+	// GasFeeCap the maximum total fee per gas, base fee included.
+	GasTipCap *big.Int
+	GasFeeCap *big.Int
+	Gas       uint64
+
+	To    *Address
+	Value *big.Int
+	Data  []byte
+
+	// V is the parity of the y coordinate of the signature's R point
+	V, R, S *big.Int
+}
+
+// Hash returns the hex hash identifying a signed transaction
+func (tx *Transaction) Hash() (string, error) {
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(keccak256(raw)), nil
+}
+
+// MarshalBinary returns the typed envelope of a signed transaction, the
+// encoding broadcast to the network.
+func (tx *Transaction) MarshalBinary() ([]byte, error) {
+	if tx.R == nil || tx.S == nil || tx.V == nil {
+		return nil, errors.New("transaction is not signed")
+	}
+	return append([]byte{DynamicFeeTxType}, rlpEncode(append(tx.fields(), tx.V, tx.R, tx.S))...), nil
+}
+
+// Cost returns the most the transaction can spend, its value and gas at the
+// fee cap.
+func (tx *Transaction) Cost() *big.Int {
+	cost := new(big.Int).Mul(tx.GasFeeCap, new(big.Int).SetUint64(tx.Gas))
+	return cost.Add(cost, tx.Value)
+}
+
+func (tx *Transaction) fields() []interface{} {
+	return []interface{}{
+		tx.ChainID,
+		tx.Nonce,
+		tx.GasTipCap,
+		tx.GasFeeCap,
+		tx.Gas,
+		tx.To,
+		tx.Value,
+		tx.Data,
+		[]interface{}{}, // access list
+	}
+}
+
+func (tx *Transaction) sigHash() []byte {
+	return keccak256([]byte{DynamicFeeTxType}, rlpEncode(tx.fields()))
+}
+
+// Sign signs the transaction with key
+func (tx *Transaction) Sign(key *btcec.PrivateKey) error {
+	sig, err := btcec.SignCompact(btcec.S256(), key, tx.sigHash(), false)
+	if err != nil {
+		return err
+	}
+	// The compact signature is the recovery ID plus 27, R and S
+	tx.V = big.NewInt(int64(sig[0] - 27))
+	tx.R = new(big.Int).SetBytes(sig[1:33])
+	tx.S = new(big.Int).SetBytes(sig[33:65])
+	return nil
+}
+
+// Sender recovers the address that signed the transaction
+func (tx *Transaction) Sender() (*Address, error) {
+	if tx.R == nil || tx.S == nil || tx.V == nil || tx.V.Uint64() > 1 {
+		return nil, errors.New("transaction is not signed")
+	}
+	if tx.R.BitLen() > 256 || tx.S.BitLen() > 256 {
+		return nil, errors.New("invalid signature")
+	}
+	sig := make([]byte, 65)
+	sig[0] = 27 + byte(tx.V.Uint64())
+	copy(sig[1:33], padBytes(tx.R, 32))
+	copy(sig[33:65], padBytes(tx.S, 32))
+	pub, _, err := btcec.RecoverCompact(btcec.S256(), sig, tx.sigHash())
+	if err != nil {
+		return nil, err
+	}
+	return PubKeyToAddress(pub), nil
+}
+
+// DecodeTransaction decodes the typed envelope of a signed EIP-1559
+// transaction.
+func DecodeTransaction(raw []byte) (*Transaction, error) {
+	if len(raw) == 0 || raw[0] != DynamicFeeTxType {
+		return nil, errors.New("not an EIP-1559 transaction")
+	}
+	item, err := rlpDecode(raw[1:])
+	if err != nil {
+		return nil, err
+	}
+	fields, ok := item.([]interface{})
+	if !ok || len(fields) != 12 {
+		return nil, errRLP
+	}
+	ints := make([]*big.Int, 12)
+	for _, i := range []int{0, 1, 2, 3, 4, 6, 9, 10, 11} {
+		if ints[i], err = rlpUint(fields[i]); err != nil {
+			return nil, err
+		}
+	}
+	if !ints[1].IsUint64() || !ints[4].IsUint64() {
+		return nil, errRLP
+	}
+	tx := &Transaction{
+		ChainID:   ints[0],
+		Nonce:     ints[1].Uint64(),
+		GasTipCap: ints[2],
+		GasFeeCap: ints[3],
+		Gas:       ints[4].Uint64(),
+		Value:     ints[6],
+		V:         ints[9],
+		R:         ints[10],
+		S:         ints[11],
+	}
+	to, ok := fields[5].([]byte)
+	if !ok {
+		return nil, errRLP
+	}
+	if len(to) > 0 {
+		if tx.To, err = NewAddress(to); err != nil {
+			return nil, err
+		}
+	}
+	if tx.Data, ok = fields[7].([]byte); !ok {
+		return nil, errRLP
+	}
+	if accessList, ok := fields[8].([]interface{}); !ok || len(accessList) > 0 {
+		return nil, errors.New("access lists are not supported")
+	}
+	return tx, nil
+}
+
+// padBytes returns the big-endian bytes of n left padded to size
+func padBytes(n *big.Int, size int) []byte {
+	b := n.Bytes()
+	if len(b) >= size {
+		return b
+	}
+	return append(make([]byte, size-len(b)), b...)
+}
@@ -0,0 +1,157 @@
+package ethereum
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	hd "github.com/btcsuite/btcutil/hdkeychain"
+	"github.com/tyler-smith/go-bip39"
+)
+
+func TestKeccak256(t *testing.T) {
+	if h := hex.EncodeToString(keccak256(nil)); h != "c5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470" {
+		t.Errorf("Unexpected hash of the empty string %s", h)
+	}
+	if h := hex.EncodeToString(transferSelector); h != "a9059cbb" {
+		t.Errorf("Unexpected transfer selector %s", h)
+	}
+}
+
+func TestAddress(t *testing.T) {
+	// The test vectors of EIP-55
+	for _, s := range []string{
+		"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+		"0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359",
+		"0xdbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB",
+		"0xD1220A0cf47c7B9Be7A2E6BA89F429762e7b9aDb",
+	} {
+		addr, err := DecodeAddress(strings.ToLower(s))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if addr.String() != s {
+			t.Errorf("Expected %s but had %s", s, addr)
+		}
+		if _, err := DecodeAddress(s); err != nil {
+			t.Errorf("Failed to decode checksummed address %s: %s", s, err)
+		}
+	}
+	if _, err := DecodeAddress("0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD"); err != ErrChecksumMismatch {
+		t.Errorf("Expected ErrChecksumMismatch but had %v", err)
+	}
+	if _, err := DecodeAddress("0x5aaeb6053f3e94c9b9a09f33669435e7ef1bea"); err == nil {
+		t.Error("Decoded a short address")
+	}
+}
+
+func TestBip44Key(t *testing.T) {
+	seed := bip39.NewSeed("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about", "")
+	master, err := hd.NewMaster(seed, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := newEthereumWallet(nil, nil, &chaincfg.MainNetParams, nil, master)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// m/44'/60'/0'/0/0 of the mnemonic
+	if w.addr.String() != "0x9858EfFD232B4033E47d90003D41EC34EcaEda94" {
+		t.Errorf("Unexpected address %s", w.addr)
+	}
+}
+
+func TestRLP(t *testing.T) {
+	long := bytes.Repeat([]byte{'a'}, 56)
+	tests := []struct {
+		item interface{}
+		hex  string
+	}{
+		{[]byte("dog"), "83646f67"},
+		{[]interface{}{[]byte("cat"), []byte("dog")}, "c88363617483646f67"},
+		{[]byte{}, "80"},
+		{[]interface{}{}, "c0"},
+		{uint64(0), "80"},
+		{uint64(15), "0f"},
+		{uint64(1024), "820400"},
+		{long, "b838" + hex.EncodeToString(long)},
+		{[]interface{}{[]interface{}{}, []interface{}{[]interface{}{}}}, "c3c0c1c0"},
+	}
+	for _, test := range tests {
+		encoded := rlpEncode(test.item)
+		if hex.EncodeToString(encoded) != test.hex {
+			t.Errorf("Expected %s but had %x", test.hex, encoded)
+		}
+		if _, err := rlpDecode(encoded); err != nil {
+			t.Errorf("Failed to decode %x: %s", encoded, err)
+		}
+	}
+	for _, invalid := range []string{"", "81", "8100", "b800", "c3c0", "83646f6700"} {
+		b, _ := hex.DecodeString(invalid)
+		if _, err := rlpDecode(b); err == nil {
+			t.Errorf("Decoded invalid encoding %s", invalid)
+		}
+	}
+}
+
+func TestTransaction(t *testing.T) {
+	key, from := newKey(t, 1)
+	_, to := newKey(t, 2)
+	tx := &Transaction{
+		ChainID:   big.NewInt(1),
+		Nonce:     7,
+		GasTipCap: big.NewInt(2e9),
+		GasFeeCap: big.NewInt(30e9),
+		Gas:       transferGas,
+		To:        to,
+		Value:     big.NewInt(1e18),
+	}
+	if _, err := tx.MarshalBinary(); err == nil {
+		t.Error("Encoded an unsigned transaction")
+	}
+	if err := tx.Sign(key); err != nil {
+		t.Fatal(err)
+	}
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if raw[0] != DynamicFeeTxType {
+		t.Errorf("Unexpected transaction type %d", raw[0])
+	}
+
+	decoded, err := DecodeTransaction(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sender, err := decoded.Sender()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *sender != *from {
+		t.Errorf("Recovered sender %s instead of %s", sender, from)
+	}
+	if decoded.Nonce != 7 || decoded.Gas != transferGas || *decoded.To != *to || decoded.Value.Cmp(tx.Value) != 0 || decoded.GasFeeCap.Cmp(tx.GasFeeCap) != 0 {
+		t.Errorf("Decoded transaction %+v doesn't match %+v", decoded, tx)
+	}
+	if cost := decoded.Cost(); cost.Cmp(big.NewInt(1e18+30e9*transferGas)) != 0 {
+		t.Errorf("Unexpected cost %s", cost)
+	}
+	h1, _ := tx.Hash()
+	h2, _ := decoded.Hash()
+	if h1 != h2 {
+		t.Errorf("Hash changed from %s to %s", h1, h2)
+	}
+
+	// Changing a signed field changes the sender
+	decoded.Value = big.NewInt(2e18)
+	if sender, err := decoded.Sender(); err == nil && *sender == *from {
+		t.Error("Recovered the sender of a modified transaction")
+	}
+	if _, err := DecodeTransaction(raw[1:]); err == nil {
+		t.Error("Decoded a transaction without its type")
+	}
+}
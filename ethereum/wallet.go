@@ -0,0 +1,711 @@
+package ethereum
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/muecoin/multiwallet/cache"
+	"github.com/muecoin/multiwallet/config"
+	"github.com/muecoin/multiwallet/keys"
+	"github.com/muecoin/multiwallet/rates"
+	"github.com/muecoin/multiwallet/registry"
+	"github.com/muecoin/multiwallet/util"
+	wi "github.com/OpenBazaar/wallet-interface"
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcutil"
+	hd "github.com/btcsuite/btcutil/hdkeychain"
+	"github.com/op/go-logging"
+	"github.com/tyler-smith/go-bip39"
+	"golang.org/x/net/proxy"
+)
+
+var Log = logging.MustGetLogger("ethereum")
+
+// ErrNotSupported is returned by the UTXO and script based methods of the
+// wallet interface, which have no counterpart in Ethereum accounts.
+var ErrNotSupported = errors.New("not supported by the Ethereum wallet")
+
+// GweiPerEther is the number of base units of the wallet in one ether. The
+// amounts of the wallet interface are int64s, which can't hold balances in
+// wei, so the wallet counts in gwei and drops the remainder.
+const GweiPerEther = 1000000000
+
+var weiPerGwei = big.NewInt(1000000000)
+
+// EthereumWallet is an account wallet of the first BIP44 key of Ethereum,
+// m/44'/60'/0'/0/0, backed by the JSON-RPC API of a node. It sends EIP-1559
+// transactions of ether and ERC-20 tokens.
+type EthereumWallet struct {
+	db     wi.Datastore
+	client *Client
+	params *chaincfg.Params
+	cache  cache.Cacher
+
+	mPrivKey *hd.ExtendedKey
+	mPubKey  *hd.ExtendedKey
+	key      *btcec.PrivateKey
+	addr     *Address
+
+	// maxFee caps the fee per gas in gwei, if not zero
+	maxFee uint64
+
+	nonces   nonceManager
+	syncLock sync.Mutex
+
+	lock      sync.RWMutex
+	chainID   *big.Int
+	tip       uint32
+	tipHash   chainhash.Hash
+	listeners []func(wi.TransactionCallback)
+	done      chan struct{}
+
+	exchangeRates wi.ExchangeRates
+}
+
+func init() {
+	registry.Register(registry.Coin{
+		Name:                "Ethereum",
+		CoinType:            util.ExtendCoinType(wi.Ethereum),
+		TestnetCoinType:     util.ExtendCoinType(wi.Ethereum),
+		CurrencyCode:        "ETH",
+		TestnetCurrencyCode: "TETH",
+		NewWallet:           newWallet,
+		PriceProviders: []string{
+			"kraken+https://api.kraken.com/0/public/Ticker?pair=ETHXBT",
+			"bitfinex+https://api.bitfinex.com/v1/pubticker/ethbtc",
+			"coingecko+https://api.coingecko.com/api/v3/coins/ethereum?tickers=false&community_data=false&developer_data=false&sparkline=false",
+		},
+		DecodeAddress:   decodeAddress,
+		ScriptToAddress: scriptToAddress,
+		AddressToScript: addressToScript,
+	})
+}
+
+func newWallet(cfg config.CoinConfig, mnemonic string, params *chaincfg.Params, proxy proxy.Dialer, cache cache.Cacher, disableExchangeRates bool) (wi.Wallet, error) {
+	w, err := NewEthereumWallet(cfg, mnemonic, params, proxy, cache, disableExchangeRates)
+	if err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func NewEthereumWallet(cfg config.CoinConfig, mnemonic string, params *chaincfg.Params, proxy proxy.Dialer, cache cache.Cacher, disableExchangeRates bool) (*EthereumWallet, error) {
+	if cfg.FeeModel != nil {
+		return nil, fmt.Errorf("unsupported fee model %T: the wallet pays for gas", cfg.FeeModel)
+	}
+	seed := bip39.NewSeed(mnemonic, "")
+
+	mPrivKey, err := hd.NewMaster(seed, params)
+	if err != nil {
+		return nil, err
+	}
+	mPubKey, err := mPrivKey.Neuter()
+	if err != nil {
+		return nil, err
+	}
+	c, err := NewClient(cfg.ClientAPIs, proxy)
+	if err != nil {
+		return nil, err
+	}
+	w, err := newEthereumWallet(cfg.DB, c, params, cache, mPrivKey)
+	if err != nil {
+		return nil, err
+	}
+	w.mPubKey = mPubKey
+	w.maxFee = cfg.MaxFee
+
+	if !disableExchangeRates {
+		fetcher, err := rates.NewPriceFetcher(cfg.CoinType, cfg.PriceAPIs, proxy)
+		if err != nil {
+			return nil, err
+		}
+		go fetcher.Run()
+		w.exchangeRates = fetcher
+	}
+	return w, nil
+}
+
+func newEthereumWallet(db wi.Datastore, c *Client, params *chaincfg.Params, cache cache.Cacher, mPrivKey *hd.ExtendedKey) (*EthereumWallet, error) {
+	_, external, err := keys.Bip44Derivation(mPrivKey, util.ExtendCoinType(wi.Ethereum))
+	if err != nil {
+		return nil, err
+	}
+	child, err := external.Child(0)
+	if err != nil {
+		return nil, err
+	}
+	key, err := child.ECPrivKey()
+	if err != nil {
+		return nil, err
+	}
+	return &EthereumWallet{
+		db:       db,
+		client:   c,
+		params:   params,
+		cache:    cache,
+		mPrivKey: mPrivKey,
+		key:      key,
+		addr:     PubKeyToAddress(key.PubKey()),
+		done:     make(chan struct{}),
+	}, nil
+}
+
+func (w *EthereumWallet) Start() {
+	go w.run()
+}
+
+func (w *EthereumWallet) Params() *chaincfg.Params {
+	return w.params
+}
+
+func (w *EthereumWallet) CurrencyCode() string {
+	if w.params.Name == chaincfg.MainNetParams.Name {
+		return "eth"
+	} else {
+		return "teth"
+	}
+}
+
+// IsDust is false for any positive amount, Ethereum has no dust limit
+func (w *EthereumWallet) IsDust(amount int64) bool {
+	return amount <= 0
+}
+
+func (w *EthereumWallet) MasterPrivateKey() *hd.ExtendedKey {
+	return w.mPrivKey
+}
+
+func (w *EthereumWallet) MasterPublicKey() *hd.ExtendedKey {
+	return w.mPubKey
+}
+
+func (w *EthereumWallet) ChildKey(keyBytes []byte, chaincode []byte, isPrivateKey bool) (*hd.ExtendedKey, error) {
+	parentFP := []byte{0x00, 0x00, 0x00, 0x00}
+	var id []byte
+	if isPrivateKey {
+		id = w.params.HDPrivateKeyID[:]
+	} else {
+		id = w.params.HDPublicKeyID[:]
+	}
+	hdKey := hd.NewExtendedKey(
+		id,
+		keyBytes,
+		chaincode,
+		parentFP,
+		0,
+		0,
+		isPrivateKey)
+	return hdKey.Child(0)
+}
+
+// CurrentAddress returns the address of the account. Ethereum wallets reuse
+// their address, so it's the same for every purpose.
+func (w *EthereumWallet) CurrentAddress(purpose wi.KeyPurpose) btcutil.Address {
+	return w.addr
+}
+
+// NewAddress returns the address of the account like CurrentAddress
+func (w *EthereumWallet) NewAddress(purpose wi.KeyPurpose) btcutil.Address {
+	return w.addr
+}
+
+func (w *EthereumWallet) DecodeAddress(addr string) (btcutil.Address, error) {
+	return decodeAddress(addr, w.params)
+}
+
+// ScriptToAddress returns the address of a 20 byte script, the form
+// AddressToScript gives to addresses.
+func (w *EthereumWallet) ScriptToAddress(script []byte) (btcutil.Address, error) {
+	return scriptToAddress(script, w.params)
+}
+
+func (w *EthereumWallet) AddressToScript(addr btcutil.Address) ([]byte, error) {
+	return addressToScript(addr)
+}
+
+// decodeAddress, scriptToAddress and addressToScript are the address codecs
+// of the registry. Ethereum addresses are the same on every network.
+func decodeAddress(addr string, params *chaincfg.Params) (btcutil.Address, error) {
+	a, err := DecodeAddress(addr)
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func scriptToAddress(script []byte, params *chaincfg.Params) (btcutil.Address, error) {
+	a, err := NewAddress(script)
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func addressToScript(addr btcutil.Address) ([]byte, error) {
+	a, err := toAddress(addr)
+	if err != nil {
+		return nil, err
+	}
+	return a.ScriptAddress(), nil
+}
+
+func (w *EthereumWallet) HasKey(addr btcutil.Address) bool {
+	return bytes.Equal(addr.ScriptAddress(), w.addr[:])
+}
+
+// Balance returns the balance in gwei at the latest block as confirmed and the
+// difference the pending transactions make as unconfirmed.
+func (w *EthereumWallet) Balance() (confirmed, unconfirmed int64) {
+	latest, err := w.client.BalanceAt(w.addr, "latest")
+	if err != nil {
+		Log.Errorf("error fetching the balance of %s: %s", w.addr, err)
+		return 0, 0
+	}
+	pending, err := w.client.BalanceAt(w.addr, "pending")
+	if err != nil {
+		pending = latest
+	}
+	return toGwei(latest), toGwei(pending) - toGwei(latest)
+}
+
+func (w *EthereumWallet) Transactions() ([]wi.Txn, error) {
+	height, _ := w.ChainTip()
+	txns, err := w.db.Txns().GetAll(false)
+	if err != nil {
+		return txns, err
+	}
+	for i, tx := range txns {
+		var confirmations int32
+		var status wi.StatusCode
+		confs := int32(height) - tx.Height + 1
+		if tx.Height <= 0 {
+			confs = tx.Height
+		}
+		switch {
+		case confs < 0:
+			status = wi.StatusDead
+		case confs == 0 && time.Since(tx.Timestamp) <= time.Hour*6:
+			status = wi.StatusUnconfirmed
+		case confs == 0 && time.Since(tx.Timestamp) > time.Hour*6:
+			status = wi.StatusDead
+		case confs > 0 && confs < 24:
+			status = wi.StatusPending
+			confirmations = confs
+		case confs > 23:
+			status = wi.StatusConfirmed
+			confirmations = confs
+		}
+		tx.Confirmations = int64(confirmations)
+		tx.Status = status
+		txns[i] = tx
+	}
+	return txns, nil
+}
+
+// GetTransaction returns a wallet transaction. Transaction hashes are stored
+// as the chainhash whose string is the hex hash.
+func (w *EthereumWallet) GetTransaction(txid chainhash.Hash) (wi.Txn, error) {
+	return w.db.Txns().Get(txid)
+}
+
+// TransactionByReference returns the transaction sent with referenceID
+func (w *EthereumWallet) TransactionByReference(referenceID string) (wi.Txn, error) {
+	b, err := w.cache.Get(w.referenceKey(referenceID))
+	if err != nil {
+		return wi.Txn{}, err
+	}
+	txid, err := chainhash.NewHashFromStr(string(b))
+	if err != nil {
+		return wi.Txn{}, err
+	}
+	return w.db.Txns().Get(*txid)
+}
+
+func (w *EthereumWallet) referenceKey(referenceID string) string {
+	return fmt.Sprintf("reference-%s-%s", w.CurrencyCode(), referenceID)
+}
+
+func (w *EthereumWallet) ChainTip() (uint32, chainhash.Hash) {
+	w.lock.RLock()
+	tip, hash := w.tip, w.tipHash
+	w.lock.RUnlock()
+	if tip > 0 {
+		return tip, hash
+	}
+	block, err := w.client.LatestBlock()
+	if err != nil {
+		return 0, chainhash.Hash{}
+	}
+	w.setTip(block)
+	return w.ChainTip()
+}
+
+func (w *EthereumWallet) setTip(block *Block) {
+	hash, err := chainhash.NewHashFromStr(block.Hash)
+	if err != nil {
+		return
+	}
+	w.lock.Lock()
+	w.tip, w.tipHash = uint32(block.Number), *hash
+	w.lock.Unlock()
+}
+
+// GetFeePerByte returns the fee cap per gas in gwei of a transaction sent at
+// feeLevel, or zero if the node can't be reached.
+func (w *EthereumWallet) GetFeePerByte(feeLevel wi.FeeLevel) uint64 {
+	_, feeCap, err := w.fees(feeLevel)
+	if err != nil {
+		Log.Errorf("error fetching ethereum fees: %s", err)
+		return 0
+	}
+	return toGweiCeil(feeCap)
+}
+
+// fees returns the priority fee and the fee cap per gas in wei of feeLevel.
+// The cap leaves room for the base fee to double before the transaction is
+// mined.
+func (w *EthereumWallet) fees(feeLevel wi.FeeLevel) (tip, feeCap *big.Int, err error) {
+	head, err := w.client.LatestBlock()
+	if err != nil {
+		return nil, nil, err
+	}
+	if head.BaseFee == nil {
+		return nil, nil, errors.New("the node doesn't support EIP-1559 transactions")
+	}
+	if tip, err = w.client.SuggestGasTipCap(); err != nil {
+		return nil, nil, err
+	}
+	switch feeLevel {
+	case wi.PRIOIRTY, wi.FEE_BUMP:
+		tip.Mul(tip, big.NewInt(2))
+	case wi.ECONOMIC:
+		tip.Div(tip, big.NewInt(2))
+	}
+	feeCap = new(big.Int).Mul(head.BaseFee, big.NewInt(2))
+	feeCap.Add(feeCap, tip)
+	if w.maxFee > 0 {
+		max := new(big.Int).Mul(new(big.Int).SetUint64(w.maxFee), weiPerGwei)
+		if feeCap.Cmp(max) > 0 {
+			feeCap = max
+		}
+		if feeCap.Cmp(head.BaseFee) < 0 {
+			return nil, nil, errors.New("base fee exceeds the maximum fee")
+		}
+		if tip.Cmp(feeCap) > 0 {
+			tip.Set(feeCap)
+		}
+	}
+	return tip, feeCap, nil
+}
+
+func (w *EthereumWallet) Spend(amount int64, addr btcutil.Address, feeLevel wi.FeeLevel, referenceID string, spendAll bool) (*chainhash.Hash, error) {
+	to, err := toAddress(addr)
+	if err != nil {
+		return nil, err
+	}
+	if amount <= 0 && !spendAll {
+		return nil, errors.New("amount must be positive")
+	}
+	return w.send(to, new(big.Int).Mul(big.NewInt(amount), weiPerGwei), nil, feeLevel, spendAll, referenceID)
+}
+
+// send signs and broadcasts a transaction of value wei and data to addr. With
+// spendAll the value is the balance left after the fee.
+func (w *EthereumWallet) send(to *Address, value *big.Int, data []byte, feeLevel wi.FeeLevel, spendAll bool, referenceID string) (*chainhash.Hash, error) {
+	tip, feeCap, err := w.fees(feeLevel)
+	if err != nil {
+		return nil, err
+	}
+	msg := CallMsg{From: w.addr, To: to, Data: data}
+	if !spendAll {
+		msg.Value = value
+	}
+	gas, err := w.client.EstimateGas(msg)
+	if err != nil {
+		return nil, err
+	}
+	chainID, err := w.chainIDOf()
+	if err != nil {
+		return nil, err
+	}
+
+	w.nonces.Lock()
+	defer w.nonces.Unlock()
+	pending, err := w.client.NonceAt(w.addr, "pending")
+	if err != nil {
+		return nil, err
+	}
+	balance, err := w.client.BalanceAt(w.addr, "pending")
+	if err != nil {
+		return nil, err
+	}
+	tx := &Transaction{
+		ChainID:   chainID,
+		Nonce:     w.nonces.nonce(pending),
+		GasTipCap: tip,
+		GasFeeCap: feeCap,
+		Gas:       gas,
+		To:        to,
+		Value:     value,
+		Data:      data,
+	}
+	if spendAll {
+		tx.Value = new(big.Int).Sub(balance, new(big.Int).Mul(feeCap, new(big.Int).SetUint64(gas)))
+		if tx.Value.Sign() <= 0 {
+			return nil, wi.ErrorInsuffientFunds
+		}
+	}
+	if balance.Cmp(tx.Cost()) < 0 {
+		return nil, wi.ErrorInsuffientFunds
+	}
+	txid, err := w.broadcast(tx)
+	if err != nil {
+		return nil, err
+	}
+	if referenceID != "" {
+		if err := w.cache.Set(w.referenceKey(referenceID), []byte(txid.String())); err != nil {
+			Log.Errorf("error saving the reference of ethereum transaction %s: %s", txid, err)
+		}
+	}
+	return txid, nil
+}
+
+// broadcast signs and broadcasts tx, then records it as an unconfirmed
+// transaction valued at its cost. Its fee is settled once it's mined.
+func (w *EthereumWallet) broadcast(tx *Transaction) (*chainhash.Hash, error) {
+	if err := tx.Sign(w.key); err != nil {
+		return nil, err
+	}
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.client.SendRawTransaction(raw); err != nil {
+		if rpcErr, ok := err.(*RPCError); ok && strings.Contains(rpcErr.Message, "nonce") {
+			w.nonces.reset()
+		}
+		return nil, err
+	}
+	w.nonces.used(tx.Nonce)
+	hash, err := tx.Hash()
+	if err != nil {
+		return nil, err
+	}
+	txid, err := chainhash.NewHashFromStr(hash)
+	if err != nil {
+		return nil, err
+	}
+	value := -toGwei(tx.Cost())
+	if tx.To != nil && *tx.To == *w.addr {
+		value += toGwei(tx.Value)
+	}
+	now := time.Now()
+	if err := w.db.Txns().Put(raw, txid.String(), int(value), 0, now, false); err != nil {
+		return nil, err
+	}
+	w.notify(wi.TransactionCallback{Txid: txid.String(), Value: value, Timestamp: now})
+	return txid, nil
+}
+
+func (w *EthereumWallet) chainIDOf() (*big.Int, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.chainID == nil {
+		id, err := w.client.ChainID()
+		if err != nil {
+			return nil, err
+		}
+		w.chainID = id
+	}
+	return w.chainID, nil
+}
+
+// BumpFee replaces an unconfirmed transaction of the wallet with a copy
+// paying a higher fee. The replacement has the same nonce, so only one of the
+// two can be mined; the original is marked dead.
+func (w *EthereumWallet) BumpFee(txid chainhash.Hash) (*chainhash.Hash, error) {
+	txn, err := w.db.Txns().Get(txid)
+	if err != nil {
+		return nil, err
+	}
+	if txn.Height > 0 {
+		return nil, errors.New("transaction is already mined")
+	}
+	if txn.Height < 0 {
+		return nil, errors.New("transaction was replaced")
+	}
+	tx, err := DecodeTransaction(txn.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	tip, feeCap, err := w.fees(wi.FEE_BUMP)
+	if err != nil {
+		return nil, err
+	}
+	// Nodes accept a replacement paying at least 10% more
+	tx.GasTipCap = maxInt(bump(tx.GasTipCap), tip)
+	tx.GasFeeCap = maxInt(bump(tx.GasFeeCap), feeCap)
+	if tx.GasTipCap.Cmp(tx.GasFeeCap) > 0 {
+		tx.GasFeeCap = tx.GasTipCap
+	}
+
+	w.nonces.Lock()
+	defer w.nonces.Unlock()
+	balance, err := w.client.BalanceAt(w.addr, "latest")
+	if err != nil {
+		return nil, err
+	}
+	if balance.Cmp(tx.Cost()) < 0 {
+		return nil, wi.ErrorInsuffientFunds
+	}
+	replacement, err := w.broadcast(tx)
+	if err != nil {
+		return nil, err
+	}
+	if err := w.db.Txns().UpdateHeight(txid, -1, time.Now()); err != nil {
+		return nil, err
+	}
+	return replacement, nil
+}
+
+// bump raises a fee by an eighth, rounded up
+func bump(fee *big.Int) *big.Int {
+	inc := new(big.Int).Add(fee, big.NewInt(7))
+	inc.Div(inc, big.NewInt(8))
+	return inc.Add(inc, fee)
+}
+
+func maxInt(a, b *big.Int) *big.Int {
+	if a.Cmp(b) >= 0 {
+		return a
+	}
+	return b
+}
+
+// EstimateFee returns the most a plain transfer to each output costs in gwei
+// at feePerByte gwei per gas. Inputs are ignored.
+func (w *EthereumWallet) EstimateFee(ins []wi.TransactionInput, outs []wi.TransactionOutput, feePerByte uint64) uint64 {
+	return uint64(len(outs)) * transferGas * feePerByte
+}
+
+func (w *EthereumWallet) EstimateSpendFee(amount int64, feeLevel wi.FeeLevel) (uint64, error) {
+	_, feeCap, err := w.fees(feeLevel)
+	if err != nil {
+		return 0, err
+	}
+	return toGweiCeil(new(big.Int).Mul(feeCap, big.NewInt(transferGas))), nil
+}
+
+// transferGas is the gas used by a transfer of ether to an account
+const transferGas = 21000
+
+func (w *EthereumWallet) SweepAddress(ins []wi.TransactionInput, address *btcutil.Address, key *hd.ExtendedKey, redeemScript *[]byte, feeLevel wi.FeeLevel) (*chainhash.Hash, error) {
+	return nil, ErrNotSupported
+}
+
+func (w *EthereumWallet) CreateMultisigSignature(ins []wi.TransactionInput, outs []wi.TransactionOutput, key *hd.ExtendedKey, redeemScript []byte, feePerByte uint64) ([]wi.Signature, error) {
+	return nil, ErrNotSupported
+}
+
+func (w *EthereumWallet) Multisign(ins []wi.TransactionInput, outs []wi.TransactionOutput, sigs1 []wi.Signature, sigs2 []wi.Signature, redeemScript []byte, feePerByte uint64, broadcast bool) ([]byte, error) {
+	return nil, ErrNotSupported
+}
+
+func (w *EthereumWallet) GenerateMultisigScript(keys []hd.ExtendedKey, threshold int, timeout time.Duration, timeoutKey *hd.ExtendedKey) (addr btcutil.Address, redeemScript []byte, err error) {
+	return nil, nil, ErrNotSupported
+}
+
+// AddWatchedAddress records the transactions to and from addr as watch-only
+func (w *EthereumWallet) AddWatchedAddress(addr btcutil.Address) error {
+	script, err := w.AddressToScript(addr)
+	if err != nil {
+		return err
+	}
+	return w.db.WatchedScripts().Put(script)
+}
+
+func (w *EthereumWallet) AddWatchedScript(script []byte) error {
+	if _, err := NewAddress(script); err != nil {
+		return err
+	}
+	return w.db.WatchedScripts().Put(script)
+}
+
+func (w *EthereumWallet) AddTransactionListener(callback func(wi.TransactionCallback)) {
+	w.lock.Lock()
+	w.listeners = append(w.listeners, callback)
+	w.lock.Unlock()
+}
+
+func (w *EthereumWallet) notify(cb wi.TransactionCallback) {
+	w.lock.RLock()
+	listeners := w.listeners
+	w.lock.RUnlock()
+	for _, l := range listeners {
+		l(cb)
+	}
+}
+
+// ReSyncBlockchain scans the blocks again from about fromTime, estimated from
+// the average block time.
+func (w *EthereumWallet) ReSyncBlockchain(fromTime time.Time) {
+	tip, _ := w.ChainTip()
+	back := uint64(time.Since(fromTime) / averageBlockTime)
+	from := uint64(0)
+	if back < uint64(tip) {
+		from = uint64(tip) - back
+	}
+	w.setScanHeight(from)
+	w.nonces.Lock()
+	w.nonces.reset()
+	w.nonces.Unlock()
+	go w.sync()
+}
+
+func (w *EthereumWallet) GetConfirmations(txid chainhash.Hash) (uint32, uint32, error) {
+	txn, err := w.db.Txns().Get(txid)
+	if err != nil {
+		return 0, 0, err
+	}
+	if txn.Height <= 0 {
+		return 0, 0, nil
+	}
+	chainTip, _ := w.ChainTip()
+	return chainTip - uint32(txn.Height) + 1, uint32(txn.Height), nil
+}
+
+func (w *EthereumWallet) Close() {
+	close(w.done)
+	if f, ok := w.exchangeRates.(*rates.Fetcher); ok {
+		f.Stop()
+	}
+}
+
+func (w *EthereumWallet) ExchangeRates() wi.ExchangeRates {
+	return w.exchangeRates
+}
+
+func toAddress(addr btcutil.Address) (*Address, error) {
+	if a, ok := addr.(*Address); ok {
+		return a, nil
+	}
+	return DecodeAddress(addr.String())
+}
+
+// toGwei converts wei to gwei, dropping the remainder
+func toGwei(wei *big.Int) int64 {
+	return new(big.Int).Quo(wei, weiPerGwei).Int64()
+}
+
+// toGweiCeil converts wei to gwei, rounding up
+func toGweiCeil(wei *big.Int) uint64 {
+	gwei := new(big.Int).Add(wei, new(big.Int).Sub(weiPerGwei, big.NewInt(1)))
+	return gwei.Quo(gwei, weiPerGwei).Uint64()
+}
@@ -0,0 +1,275 @@
+package ethereum
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/muecoin/multiwallet/cache"
+	"github.com/muecoin/multiwallet/datastore"
+	wi "github.com/OpenBazaar/wallet-interface"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	hd "github.com/btcsuite/btcutil/hdkeychain"
+	"github.com/tyler-smith/go-bip39"
+)
+
+var _ wi.Wallet = (*EthereumWallet)(nil)
+
+var ether = big.NewInt(1e18)
+
+func newMockWallet(t *testing.T, chain *simulatedChain) *EthereumWallet {
+	t.Helper()
+	db, err := datastore.NewMockMultiwalletDatastore().GetDatastoreForWallet(wi.Ethereum)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seed := bip39.NewSeed("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about", "")
+	master, err := hd.NewMaster(seed, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := newEthereumWallet(db, chain.client(), &chaincfg.MainNetParams, cache.NewMockCacher(), master)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return w
+}
+
+func TestEthereumWallet_Spend(t *testing.T) {
+	chain := newSimulatedChain(t)
+	defer chain.Close()
+	w := newMockWallet(t, chain)
+	w.sync()
+	chain.fund(w.addr, ether)
+	_, to := newKey(t, 2)
+
+	var callbacks []wi.TransactionCallback
+	w.AddTransactionListener(func(cb wi.TransactionCallback) {
+		callbacks = append(callbacks, cb)
+	})
+
+	// 0.25 ether
+	txid, err := w.Spend(250000000, to, wi.NORMAL, "order-1", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := chain.balance(to); got.Cmp(big.NewInt(25e16)) != 0 {
+		t.Errorf("Recipient received %s wei", got)
+	}
+	// The gas is priced at the base fee plus the tip, 11 gwei
+	fee := int64(11 * transferGas)
+	confirmed, unconfirmed := w.Balance()
+	if confirmed != 750000000-fee || unconfirmed != 0 {
+		t.Errorf("Unexpected balance %d, %d", confirmed, unconfirmed)
+	}
+
+	// The spend is recorded at its cost with the fee cap until it's mined
+	txn, err := w.GetTransaction(*txid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if txn.Height != 0 || txn.Value != -250000000-21*transferGas {
+		t.Errorf("Unexpected unconfirmed transaction %+v", txn)
+	}
+	if ref, err := w.TransactionByReference("order-1"); err != nil || ref.Txid != txid.String() {
+		t.Errorf("Transaction of the reference not found: %v", err)
+	}
+
+	w.sync()
+	txn, err = w.GetTransaction(*txid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if txn.Height != 1 || txn.Value != -250000000-fee {
+		t.Errorf("Unexpected mined transaction %+v", txn)
+	}
+	confirmations, height, err := w.GetConfirmations(*txid)
+	if err != nil || confirmations != 1 || height != 1 {
+		t.Errorf("Unexpected confirmations %d at height %d (%v)", confirmations, height, err)
+	}
+	if len(callbacks) != 2 || callbacks[1].Height != 1 {
+		t.Errorf("Unexpected callbacks %+v", callbacks)
+	}
+
+	if _, err := w.Spend(2000000000, to, wi.NORMAL, "", false); err != wi.ErrorInsuffientFunds {
+		t.Errorf("Expected ErrorInsuffientFunds but had %v", err)
+	}
+}
+
+func TestEthereumWallet_SpendAll(t *testing.T) {
+	chain := newSimulatedChain(t)
+	defer chain.Close()
+	w := newMockWallet(t, chain)
+	chain.fund(w.addr, ether)
+	_, to := newKey(t, 2)
+
+	if _, err := w.Spend(0, to, wi.PRIOIRTY, "", true); err != nil {
+		t.Fatal(err)
+	}
+	// The priority tip is doubled and the value leaves the cap of twice the
+	// base fee plus the tip for the gas.
+	feeCap := big.NewInt(22e9)
+	sent := new(big.Int).Sub(ether, new(big.Int).Mul(feeCap, big.NewInt(transferGas)))
+	if got := chain.balance(to); got.Cmp(sent) != 0 {
+		t.Errorf("Expected the recipient to receive %s but had %s", sent, got)
+	}
+	// What the cap didn't use is refunded
+	refund := big.NewInt((22e9 - 12e9) * transferGas)
+	if got := chain.balance(w.addr); got.Cmp(refund) != 0 {
+		t.Errorf("Expected a balance of %s but had %s", refund, got)
+	}
+}
+
+func TestEthereumWallet_Nonces(t *testing.T) {
+	chain := newSimulatedChain(t)
+	defer chain.Close()
+	w := newMockWallet(t, chain)
+	chain.fund(w.addr, ether)
+	_, to := newKey(t, 2)
+
+	// The node doesn't count the pending transactions in the pending nonce
+	chain.paused = true
+	chain.laggingNonce = true
+	for i := 0; i < 3; i++ {
+		if _, err := w.Spend(1000000, to, wi.NORMAL, "", false); err != nil {
+			t.Fatalf("Spend %d failed: %s", i, err)
+		}
+	}
+	chain.mine()
+	if got := chain.balance(to); got.Cmp(big.NewInt(3e15)) != 0 {
+		t.Errorf("Expected the three spends to be mined, recipient has %s", got)
+	}
+
+	// After the node lost track of a transaction the next spend fails on its
+	// nonce and the one after falls back to the nonce of the node.
+	w.nonces.Lock()
+	w.nonces.next = 10
+	w.nonces.Unlock()
+	if _, err := w.Spend(1000000, to, wi.NORMAL, "", false); err == nil {
+		t.Error("Sent a transaction with a nonce gap")
+	}
+	if _, err := w.Spend(1000000, to, wi.NORMAL, "", false); err != nil {
+		t.Errorf("Nonce wasn't reset: %s", err)
+	}
+}
+
+func TestEthereumWallet_BumpFee(t *testing.T) {
+	chain := newSimulatedChain(t)
+	defer chain.Close()
+	w := newMockWallet(t, chain)
+	w.sync()
+	chain.fund(w.addr, ether)
+	_, to := newKey(t, 2)
+
+	chain.paused = true
+	txid, err := w.Spend(1000000, to, wi.ECONOMIC, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bumped, err := w.BumpFee(*txid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain.mine()
+	w.sync()
+
+	original, _ := w.GetTransaction(*txid)
+	if original.Height != -1 {
+		t.Errorf("Replaced transaction at height %d", original.Height)
+	}
+	replacement, err := w.GetTransaction(*bumped)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replacement.Height != 1 {
+		t.Errorf("Replacement wasn't mined, height %d", replacement.Height)
+	}
+	if got := chain.balance(to); got.Cmp(big.NewInt(1e15)) != 0 {
+		t.Errorf("Recipient received %s", got)
+	}
+	if _, err := w.BumpFee(*bumped); err == nil {
+		t.Error("Bumped the fee of a mined transaction")
+	}
+	txns, err := w.Transactions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, txn := range txns {
+		if txn.Txid == txid.String() && txn.Status != wi.StatusDead {
+			t.Errorf("Replaced transaction has status %s", txn.Status)
+		}
+	}
+}
+
+func TestEthereumWallet_Sync(t *testing.T) {
+	chain := newSimulatedChain(t)
+	defer chain.Close()
+	w := newMockWallet(t, chain)
+	w.sync()
+
+	key, sender := newKey(t, 3)
+	_, watched := newKey(t, 4)
+	if err := w.AddWatchedAddress(watched); err != nil {
+		t.Fatal(err)
+	}
+	chain.fund(sender, ether)
+	sendFrom := func(to *Address, value *big.Int, nonce uint64) string {
+		tx := &Transaction{
+			ChainID:   chain.chainID,
+			Nonce:     nonce,
+			GasTipCap: big.NewInt(1e9),
+			GasFeeCap: big.NewInt(20e9),
+			Gas:       transferGas,
+			To:        to,
+			Value:     value,
+		}
+		if err := tx.Sign(key); err != nil {
+			t.Fatal(err)
+		}
+		raw, _ := tx.MarshalBinary()
+		hash, err := chain.client().SendRawTransaction(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return hash
+	}
+	incoming := sendFrom(w.addr, big.NewInt(5e17), 0)
+	other := sendFrom(watched, big.NewInt(1e17), 1)
+	_, stranger := newKey(t, 5)
+	sendFrom(stranger, big.NewInt(1e17), 2)
+
+	w.sync()
+	incomingTxid, _ := chainhash.NewHashFromStr(incoming)
+	if txn, err := w.GetTransaction(*incomingTxid); err != nil || txn.WatchOnly || txn.Value != 500000000 || txn.Height != 1 {
+		t.Errorf("Unexpected incoming transaction %+v (%v)", txn, err)
+	}
+	otherTxid, _ := chainhash.NewHashFromStr(other)
+	if txn, err := w.GetTransaction(*otherTxid); err != nil || !txn.WatchOnly || txn.Value != 100000000 {
+		t.Errorf("Unexpected watched transaction %+v (%v)", txn, err)
+	}
+	txns, err := w.Transactions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txns) != 2 {
+		t.Errorf("Expected the transaction between strangers to be skipped, had %+v", txns)
+	}
+	for _, txn := range txns {
+		if txn.Txid == incoming && (txn.Status != wi.StatusPending || txn.Confirmations != 3) {
+			t.Errorf("Unexpected status %s with %d confirmations", txn.Status, txn.Confirmations)
+		}
+	}
+
+	// A resync scans the blocks again from about the given time
+	w.ReSyncBlockchain(time.Now().Add(-time.Minute))
+	for i := 0; i < 100; i++ {
+		if height, _ := w.scanHeight(); height == 4 {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if height, _ := w.scanHeight(); height != 4 {
+		t.Errorf("Expected scan height 4 after the resync but had %d", height)
+	}
+}
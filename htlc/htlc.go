@@ -0,0 +1,289 @@
+package htlc
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/txscript"
+)
+
+var (
+	// ErrNotHTLC is returned when parsing a script which is not a hash time
+	// locked contract.
+	ErrNotHTLC = errors.New("script is not a hash time locked contract")
+
+	// ErrSecretMismatch is returned when redeeming a contract with a secret
+	// which does not hash to the secret hash of the contract.
+	ErrSecretMismatch = errors.New("secret does not match the contract secret hash")
+
+	// ErrLockTimeNotReached is returned when refunding a contract before its
+	// lock time has passed.
+	ErrLockTimeNotReached = errors.New("contract lock time has not been reached")
+)
+
+const (
+	// SecretSize is the size of the secret the contracts are locked with
+	SecretSize = 32
+
+	// ContractSize is the worst case size of a contract script. It is
+	// calculated as:
+	//
+	//   - OP_IF
+	//   - OP_SIZE
+	//   - OP_DATA_1
+	//   - 1 byte secret size
+	//   - OP_EQUALVERIFY
+	//   - OP_SHA256
+	//   - OP_DATA_32
+	//   - 32 bytes secret hash
+	//   - OP_EQUALVERIFY
+	//   - OP_DATA_33
+	//   - 33 bytes recipient pubkey
+	//   - OP_ELSE
+	//   - OP_DATA_4
+	//   - 4 bytes lock time
+	//   - OP_CHECKLOCKTIMEVERIFY
+	//   - OP_DROP
+	//   - OP_DATA_33
+	//   - 33 bytes refund pubkey
+	//   - OP_ENDIF
+	//   - OP_CHECKSIG
+	ContractSize = 1 + 1 + 1 + 1 + 1 + 1 + 1 + 32 + 1 + 1 + 33 + 1 + 1 + 4 + 1 + 1 + 1 + 33 + 1 + 1
+
+	// ECDSASignatureSize is the worst case size of a DER signature plus the
+	// sighash byte
+	ECDSASignatureSize = 72 + 1
+
+	// maxLockTime is the largest lock time interpreted as a block height
+	maxLockTime = txscript.LockTimeThreshold - 1
+)
+
+// Contract is a hash time locked contract. Its outputs can be spent by the
+// recipient key together with the preimage of the secret hash, or by the
+// refund key once the chain reached the lock time height.
+type Contract struct {
+	Script       []byte
+	SecretHash   []byte
+	RecipientKey []byte
+	RefundKey    []byte
+	LockTime     uint32
+}
+
+// NewSecret returns a random secret and its sha256 hash
+func NewSecret() (secret []byte, secretHash []byte, err error) {
+	secret = make([]byte, SecretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, nil, err
+	}
+	h := sha256.Sum256(secret)
+	return secret, h[:], nil
+}
+
+// NewContract builds the script of a contract paying the recipient key for
+// the preimage of secretHash and refunding the refund key at the lock time
+// height:
+//
+//	OP_IF
+//	  OP_SIZE 32 OP_EQUALVERIFY OP_SHA256 <secret hash> OP_EQUALVERIFY <recipient key>
+//	OP_ELSE
+//	  <lock time> OP_CHECKLOCKTIMEVERIFY OP_DROP <refund key>
+//	OP_ENDIF
+//	OP_CHECKSIG
+func NewContract(secretHash, recipientKey, refundKey []byte, lockTime uint32) (*Contract, error) {
+	if len(secretHash) != sha256.Size {
+		return nil, errors.New("secret hash must be 32 bytes")
+	}
+	if len(recipientKey) != btcec.PubKeyBytesLenCompressed || len(refundKey) != btcec.PubKeyBytesLenCompressed {
+		return nil, errors.New("contract keys must be compressed public keys")
+	}
+	if lockTime == 0 || lockTime > maxLockTime {
+		return nil, errors.New("contract lock time must be a block height")
+	}
+	builder := txscript.NewScriptBuilder()
+	builder.AddOp(txscript.OP_IF)
+	builder.AddOp(txscript.OP_SIZE)
+	builder.AddInt64(SecretSize)
+	builder.AddOp(txscript.OP_EQUALVERIFY)
+	builder.AddOp(txscript.OP_SHA256)
+	builder.AddData(secretHash)
+	builder.AddOp(txscript.OP_EQUALVERIFY)
+	builder.AddData(recipientKey)
+	builder.AddOp(txscript.OP_ELSE)
+	builder.AddInt64(int64(lockTime))
+	builder.AddOp(txscript.OP_CHECKLOCKTIMEVERIFY)
+	builder.AddOp(txscript.OP_DROP)
+	builder.AddData(refundKey)
+	builder.AddOp(txscript.OP_ENDIF)
+	builder.AddOp(txscript.OP_CHECKSIG)
+	script, err := builder.Script()
+	if err != nil {
+		return nil, err
+	}
+	return &Contract{
+		Script:       script,
+		SecretHash:   secretHash,
+		RecipientKey: recipientKey,
+		RefundKey:    refundKey,
+		LockTime:     lockTime,
+	}, nil
+}
+
+// ParseContract parses a contract script built by NewContract
+func ParseContract(script []byte) (*Contract, error) {
+	var pushes [][]byte
+	pos := 0
+	expectOp := func(ops ...byte) bool {
+		for _, op := range ops {
+			if pos >= len(script) || script[pos] != op {
+				return false
+			}
+			pos++
+		}
+		return true
+	}
+	expectPush := func(size int) bool {
+		if pos >= len(script) || int(script[pos]) != size || pos+1+size > len(script) {
+			return false
+		}
+		pushes = append(pushes, script[pos+1:pos+1+size])
+		pos += 1 + size
+		return true
+	}
+	if !expectOp(txscript.OP_IF, txscript.OP_SIZE, txscript.OP_DATA_1, SecretSize, txscript.OP_EQUALVERIFY, txscript.OP_SHA256) ||
+		!expectPush(sha256.Size) ||
+		!expectOp(txscript.OP_EQUALVERIFY) ||
+		!expectPush(btcec.PubKeyBytesLenCompressed) ||
+		!expectOp(txscript.OP_ELSE) {
+		return nil, ErrNotHTLC
+	}
+	if pos >= len(script) {
+		return nil, ErrNotHTLC
+	}
+	var lockTime int64
+	switch op := script[pos]; {
+	case op >= txscript.OP_1 && op <= txscript.OP_16:
+		// Lock times up to 16 are pushed as small integers
+		lockTime = int64(op - (txscript.OP_1 - 1))
+		pos++
+	case op >= txscript.OP_DATA_1 && op <= txscript.OP_DATA_4:
+		if pos+1+int(op) > len(script) {
+			return nil, ErrNotHTLC
+		}
+		// Larger lock times are minimally encoded little-endian script
+		// numbers, positive so their sign bit is clear
+		num := script[pos+1 : pos+1+int(op)]
+		last := num[len(num)-1]
+		if last&0x80 != 0 || last == 0 && (len(num) == 1 || num[len(num)-2]&0x80 == 0) {
+			return nil, ErrNotHTLC
+		}
+		for i, b := range num {
+			lockTime |= int64(b) << uint(8*i)
+		}
+		pos += 1 + int(op)
+	default:
+		return nil, ErrNotHTLC
+	}
+	if lockTime <= 0 || lockTime > maxLockTime {
+		return nil, ErrNotHTLC
+	}
+	if !expectOp(txscript.OP_CHECKLOCKTIMEVERIFY, txscript.OP_DROP) ||
+		!expectPush(btcec.PubKeyBytesLenCompressed) ||
+		!expectOp(txscript.OP_ENDIF, txscript.OP_CHECKSIG) ||
+		pos != len(script) {
+		return nil, ErrNotHTLC
+	}
+	return &Contract{
+		Script:       script,
+		SecretHash:   pushes[0],
+		RecipientKey: pushes[1],
+		RefundKey:    pushes[2],
+		LockTime:     uint32(lockTime),
+	}, nil
+}
+
+// Matches returns whether secret is the preimage of the secret hash
+func (c *Contract) Matches(secret []byte) bool {
+	if len(secret) != SecretSize {
+		return false
+	}
+	h := sha256.Sum256(secret)
+	return bytes.Equal(h[:], c.SecretHash)
+}
+
+// Refundable returns whether a refund of the contract can be mined in the
+// block after tip.
+func (c *Contract) Refundable(tip uint32) bool {
+	return tip >= c.LockTime
+}
+
+// RedeemStack returns the stack spending the contract with the recipient
+// signature and the secret.
+func (c *Contract) RedeemStack(sig, secret []byte) [][]byte {
+	return [][]byte{sig, secret, {1}, c.Script}
+}
+
+// RefundStack returns the stack spending the contract with the refund
+// signature after the lock time.
+func (c *Contract) RefundStack(sig []byte) [][]byte {
+	return [][]byte{sig, {}, c.Script}
+}
+
+// ExtractSecret returns the secret revealed by the stack of an input spending
+// the contract, if any.
+func (c *Contract) ExtractSecret(stack [][]byte) ([]byte, bool) {
+	for _, item := range stack {
+		if c.Matches(item) {
+			return item, true
+		}
+	}
+	return nil, false
+}
+
+// RedeemSigScriptSize returns the worst case size of a signature script
+// redeeming a P2SH contract with a signature of sigSize bytes. It is
+// calculated as:
+//
+//   - signature push
+//   - secret push
+//   - OP_1 selecting the redeem branch
+//   - contract push
+func RedeemSigScriptSize(sigSize int) int {
+	return 1 + sigSize + 1 + SecretSize + 1 + 2 + ContractSize
+}
+
+// RefundSigScriptSize returns the worst case size of a signature script
+// refunding a P2SH contract with a signature of sigSize bytes. It is
+// calculated as:
+//
+//   - signature push
+//   - OP_0 selecting the refund branch
+//   - contract push
+func RefundSigScriptSize(sigSize int) int {
+	return 1 + sigSize + 1 + 2 + ContractSize
+}
+
+// RedeemWitnessSize returns the worst case size of a witness redeeming a
+// P2WSH contract. It is calculated as:
+//
+//   - 1 byte item count
+//   - signature with its length
+//   - secret with its length
+//   - 2 bytes branch selector
+//   - contract with its length
+func RedeemWitnessSize() int {
+	return 1 + 1 + ECDSASignatureSize + 1 + SecretSize + 2 + 1 + ContractSize
+}
+
+// RefundWitnessSize returns the worst case size of a witness refunding a
+// P2WSH contract. It is calculated as:
+//
+//   - 1 byte item count
+//   - signature with its length
+//   - 1 byte empty branch selector
+//   - contract with its length
+func RefundWitnessSize() int {
+	return 1 + 1 + ECDSASignatureSize + 1 + 1 + ContractSize
+}
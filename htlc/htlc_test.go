@@ -0,0 +1,150 @@
+package htlc
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+)
+
+func testKeys(t *testing.T) (recipient, refund []byte) {
+	recipientKey, err := btcec.NewPrivateKey(btcec.S256())
+	if err != nil {
+		t.Fatal(err)
+	}
+	refundKey, err := btcec.NewPrivateKey(btcec.S256())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return recipientKey.PubKey().SerializeCompressed(), refundKey.PubKey().SerializeCompressed()
+}
+
+func TestNewSecret(t *testing.T) {
+	secret, secretHash, err := NewSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(secret) != SecretSize {
+		t.Errorf("Expected a %d byte secret but had %d bytes", SecretSize, len(secret))
+	}
+	h := sha256.Sum256(secret)
+	if !bytes.Equal(h[:], secretHash) {
+		t.Error("Secret hash is not the sha256 of the secret")
+	}
+	other, _, err := NewSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(secret, other) {
+		t.Error("Generated the same secret twice")
+	}
+}
+
+func TestParseContract(t *testing.T) {
+	recipient, refund := testKeys(t)
+	secret, secretHash, err := NewSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, lockTime := range []uint32{1, 17, 128, 600000, maxLockTime} {
+		contract, err := NewContract(secretHash, recipient, refund, lockTime)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(contract.Script) > ContractSize {
+			t.Errorf("Contract is %d bytes, larger than the worst case %d", len(contract.Script), ContractSize)
+		}
+		parsed, err := ParseContract(contract.Script)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(parsed.SecretHash, secretHash) || !bytes.Equal(parsed.RecipientKey, recipient) ||
+			!bytes.Equal(parsed.RefundKey, refund) || parsed.LockTime != lockTime {
+			t.Errorf("Parsed contract %+v does not match lock time %d", parsed, lockTime)
+		}
+		if !parsed.Matches(secret) {
+			t.Error("Contract does not match its secret")
+		}
+	}
+	contract, err := NewContract(secretHash, recipient, refund, maxLockTime)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(contract.Script) != ContractSize {
+		t.Errorf("Expected a %d byte contract but had %d bytes", ContractSize, len(contract.Script))
+	}
+
+	if _, err := NewContract(secretHash, recipient, refund, maxLockTime+1); err == nil {
+		t.Error("Built a contract locked until a timestamp")
+	}
+	if _, err := NewContract(secretHash[:20], recipient, refund, 100); err == nil {
+		t.Error("Built a contract with a 20 byte secret hash")
+	}
+	if _, err := ParseContract(contract.Script[:len(contract.Script)-1]); err != ErrNotHTLC {
+		t.Errorf("Expected ErrNotHTLC for a truncated contract but had %v", err)
+	}
+	tampered := append([]byte{}, contract.Script...)
+	tampered[1] = 0x00
+	if _, err := ParseContract(tampered); err != ErrNotHTLC {
+		t.Errorf("Expected ErrNotHTLC for a tampered contract but had %v", err)
+	}
+
+	// Lock time 600000 is pushed as 03c02709
+	contract, err = NewContract(secretHash, recipient, refund, 600000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	push := []byte{0x03, 0xc0, 0x27, 0x09}
+	if !bytes.Contains(contract.Script, push) {
+		t.Fatalf("Lock time push %x not found in contract", push)
+	}
+	for _, encoding := range [][]byte{
+		{0x04, 0xc0, 0x27, 0x09, 0x00},
+		{0x03, 0xc0, 0x27, 0x89},
+	} {
+		script := bytes.Replace(contract.Script, push, encoding, 1)
+		if _, err := ParseContract(script); err != ErrNotHTLC {
+			t.Errorf("Expected ErrNotHTLC for lock time push %x but had %v", encoding, err)
+		}
+	}
+}
+
+func TestContract_ExtractSecret(t *testing.T) {
+	recipient, refund := testKeys(t)
+	secret, secretHash, err := NewSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	contract, err := NewContract(secretHash, recipient, refund, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig := make([]byte, ECDSASignatureSize)
+	revealed, ok := contract.ExtractSecret(contract.RedeemStack(sig, secret))
+	if !ok || !bytes.Equal(revealed, secret) {
+		t.Error("Failed to extract the secret from a redeem")
+	}
+	if _, ok := contract.ExtractSecret(contract.RefundStack(sig)); ok {
+		t.Error("Extracted a secret from a refund")
+	}
+	if contract.Refundable(99) || !contract.Refundable(100) {
+		t.Error("Contract is refundable before its lock time")
+	}
+}
+
+func TestSpendSizes(t *testing.T) {
+	// The worst case stacks are a 73 byte signature and a 118 byte contract
+	if size := RedeemWitnessSize(); size != 1+1+73+1+32+2+1+118 {
+		t.Errorf("Unexpected redeem witness size %d", size)
+	}
+	if size := RefundWitnessSize(); size != 1+1+73+1+1+118 {
+		t.Errorf("Unexpected refund witness size %d", size)
+	}
+	if size := RedeemSigScriptSize(ECDSASignatureSize); size != 1+73+1+32+1+2+118 {
+		t.Errorf("Unexpected redeem signature script size %d", size)
+	}
+	if size := RefundSigScriptSize(ECDSASignatureSize); size != 1+73+1+2+118 {
+		t.Errorf("Unexpected refund signature script size %d", size)
+	}
+}
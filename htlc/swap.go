@@ -0,0 +1,924 @@
+package htlc
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/muecoin/multiwallet/datastore"
+	"github.com/muecoin/multiwallet/multisig"
+	"github.com/muecoin/multiwallet/registry"
+	"github.com/muecoin/multiwallet/service"
+	"github.com/OpenBazaar/wallet-interface"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcutil/hdkeychain"
+	"github.com/op/go-logging"
+)
+
+var Log = logging.MustGetLogger("htlc")
+
+// ErrUnknownSwap is returned when looking up a swap which does not exist
+var ErrUnknownSwap = errors.New("unknown swap")
+
+// ErrNoChainTip is returned while a wallet of a swap has not synced its chain
+var ErrNoChainTip = errors.New("wallet has no chain tip")
+
+const (
+	// swapsKey is the record key of the swap IDs and the next swap key
+	swapsKey = "htlc-swaps"
+
+	// swapKeyPrefix prefixes the record key of the state of each swap
+	swapKeyPrefix = "htlc-swap-"
+
+	// swapKeyPurpose is the hardened child of the master key swap keys are
+	// derived from
+	swapKeyPurpose = hdkeychain.HardenedKeyStart + 0x53574150
+
+	// tickInterval is how often swaps are advanced to catch lock times
+	// passing between transactions
+	tickInterval = time.Minute
+
+	// DefaultMinLockBlocks and DefaultMinLockGap are the lock limits of a
+	// Swapper whose limits are left zero.
+	DefaultMinLockBlocks = 6
+	DefaultMinLockGap    = 6 * time.Hour
+)
+
+// LockLimits bound the lock times of the contracts of a swap
+type LockLimits struct {
+	// MinBlocks is the number of blocks a contract must stay locked for,
+	// both when it is created and when the counterparty contract is
+	// accepted.
+	MinBlocks uint32
+
+	// MinGap is how long before the contract of the initiator the contract
+	// of the participant must become refundable. It leaves the participant
+	// time to redeem the initiator contract once the secret is revealed.
+	MinGap time.Duration
+}
+
+// Wallet is a coin wallet able to build, redeem and refund hash time locked
+// contracts.
+type Wallet interface {
+	wallet.Wallet
+	GenerateHTLCScript(secretHash, recipientKey, refundKey []byte, lockTime uint32) (btcutil.Address, []byte, error)
+	RedeemHTLC(ins []wallet.TransactionInput, address btcutil.Address, key *hdkeychain.ExtendedKey, contract []byte, secret []byte, feeLevel wallet.FeeLevel) (*chainhash.Hash, error)
+	RefundHTLC(ins []wallet.TransactionInput, address btcutil.Address, key *hdkeychain.ExtendedKey, contract []byte, feeLevel wallet.FeeLevel) (*chainhash.Hash, error)
+	WatchHTLC(addr btcutil.Address, callback func(service.ScriptSpend)) error
+	SpendWithRequestID(requestID string, amount int64, addr btcutil.Address, feeLevel wallet.FeeLevel, referenceID string, data []byte, spendAll bool) (*chainhash.Hash, error)
+}
+
+// Role is the side of a swap this wallet is on
+type Role string
+
+const (
+	// Initiator picks the secret and funds the first contract
+	Initiator Role = "initiator"
+
+	// Participant funds its contract once the initiator's contract confirmed
+	// and redeems the initiator's contract with the secret revealed by the
+	// initiator's redeem.
+	Participant Role = "participant"
+)
+
+// State is the progress of a swap
+type State string
+
+const (
+	// StateCreated swaps wait to fund their contract. A participant waits
+	// for the contract of the initiator to confirm first.
+	StateCreated State = "created"
+
+	// StateFunding swaps are sending the funding transaction of their
+	// contract. It is sent with the swap ID as its spend request ID, so a
+	// swap whose funding failed or was interrupted by a restart sends it
+	// again without ever funding the contract twice.
+	StateFunding State = "funding"
+
+	// StateFunded swaps wait to redeem the counterparty contract, or to
+	// refund their own contract once its lock time passed.
+	StateFunded State = "funded"
+
+	// StateRedeemed swaps redeemed the counterparty contract
+	StateRedeemed State = "redeemed"
+
+	// StateRefunded swaps refunded their own contract after its lock time
+	StateRefunded State = "refunded"
+
+	// StateExpired swaps of a participant were never funded because the
+	// contract of the initiator expired first.
+	StateExpired State = "expired"
+)
+
+// Leg is one of the two contracts of a swap
+type Leg struct {
+	Coin     string `json:"coin"`
+	Amount   int64  `json:"amount"`
+	Address  string `json:"address,omitempty"`
+	Contract []byte `json:"contract,omitempty"`
+	LockTime uint32 `json:"lockTime,omitempty"`
+
+	FundingTxid   string `json:"fundingTxid,omitempty"`
+	FundingIndex  uint32 `json:"fundingIndex"`
+	FundingValue  int64  `json:"fundingValue,omitempty"`
+	FundingHeight int32  `json:"fundingHeight,omitempty"`
+	SpendTxid     string `json:"spendTxid,omitempty"`
+}
+
+// funded returns whether the contract is funded with its amount in a
+// confirmed transaction.
+func (l *Leg) funded() bool {
+	return l.FundingTxid != "" && l.FundingHeight > 0 && l.FundingValue >= l.Amount
+}
+
+// Swap is the persisted state of an atomic swap. Own is the contract this
+// wallet funds and Counterparty the contract paying this wallet.
+type Swap struct {
+	ID         string    `json:"id"`
+	Role       Role      `json:"role"`
+	State      State     `json:"state"`
+	SecretHash []byte    `json:"secretHash"`
+	Secret     []byte    `json:"secret,omitempty"`
+	Created    time.Time `json:"created"`
+	Error      string    `json:"error,omitempty"`
+
+	// RefundKey and ReceiveKey are the indexes of the swap keys refunding
+	// the own contract and redeeming the counterparty contract.
+	RefundKey  uint32 `json:"refundKey"`
+	ReceiveKey uint32 `json:"receiveKey"`
+
+	// ReceivePubKey is the public key the counterparty contract must pay.
+	// The initiator shares it with the participant.
+	ReceivePubKey []byte `json:"receivePubKey"`
+
+	Own          Leg `json:"own"`
+	Counterparty Leg `json:"counterparty"`
+}
+
+// Finished returns whether the swap reached a final state
+func (s *Swap) Finished() bool {
+	return s.State == StateRedeemed || s.State == StateRefunded || s.State == StateExpired
+}
+
+type persistedSwaps struct {
+	NextKey uint32   `json:"nextKey"`
+	IDs     []string `json:"ids"`
+}
+
+// Swapper runs atomic swaps between the coins of a MultiWallet. The state of
+// each swap is saved in the datastore records after every change and swaps
+// resume where they left off when a Swapper is created again.
+type Swapper struct {
+	wallets map[string]Wallet
+	records datastore.Records
+	limits  LockLimits
+
+	swaps     map[string]*Swap
+	nextKey   uint32
+	listeners []func(Swap)
+	lock      sync.Mutex
+
+	// advanceLock serializes the wallet actions taken by swaps so a
+	// contract is never funded, redeemed or refunded twice.
+	advanceLock sync.Mutex
+
+	doneChan chan struct{}
+}
+
+// NewSwapper returns a Swapper for the wallets and resumes the unfinished
+// swaps saved in records. Zero limits take their default value.
+func NewSwapper(wallets []Wallet, records datastore.Records, limits LockLimits) (*Swapper, error) {
+	if limits.MinBlocks == 0 {
+		limits.MinBlocks = DefaultMinLockBlocks
+	}
+	if limits.MinGap == 0 {
+		limits.MinGap = DefaultMinLockGap
+	}
+	s := &Swapper{
+		wallets:  make(map[string]Wallet),
+		records:  records,
+		limits:   limits,
+		swaps:    make(map[string]*Swap),
+		doneChan: make(chan struct{}),
+	}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	for _, w := range wallets {
+		code := coinCode(w.CurrencyCode())
+		s.wallets[code] = w
+		w.AddTransactionListener(func(cb wallet.TransactionCallback) {
+			s.onTransaction(code, cb)
+		})
+	}
+	for _, sw := range s.swaps {
+		if sw.Finished() {
+			continue
+		}
+		if err := s.watch(sw); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Start advances the swaps periodically until Close is called
+func (s *Swapper) Start() {
+	go func() {
+		t := time.NewTicker(tickInterval)
+		defer t.Stop()
+		s.advanceAll()
+		for {
+			select {
+			case <-t.C:
+				s.advanceAll()
+			case <-s.doneChan:
+				return
+			}
+		}
+	}()
+}
+
+func (s *Swapper) Close() {
+	close(s.doneChan)
+}
+
+// AddSwapListener calls callback with the swap after each of its changes
+func (s *Swapper) AddSwapListener(callback func(Swap)) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.listeners = append(s.listeners, callback)
+}
+
+// Swaps returns all swaps
+func (s *Swapper) Swaps() []Swap {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	var swaps []Swap
+	for _, sw := range s.swaps {
+		swaps = append(swaps, *sw)
+	}
+	return swaps
+}
+
+// GetSwap returns the swap with the given id
+func (s *Swapper) GetSwap(id string) (Swap, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	sw, ok := s.swaps[id]
+	if !ok {
+		return Swap{}, ErrUnknownSwap
+	}
+	return *sw, nil
+}
+
+// NewKey reserves a swap key of coin and returns its public key. A
+// participant shares it with the initiator, whose contract must pay it.
+func (s *Swapper) NewKey(coin string) ([]byte, error) {
+	w, err := s.wallet(coin)
+	if err != nil {
+		return nil, err
+	}
+	s.lock.Lock()
+	index := s.nextKey
+	s.nextKey++
+	err = s.saveIndex()
+	s.lock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	return swapPubKey(w, index)
+}
+
+// Initiate starts a swap of ownAmount of ownCoin for counterpartyAmount of
+// counterpartyCoin and funds its contract. The contract pays recipientKey,
+// the key of the participant on ownCoin, and can be refunded after
+// lockDuration. The contract and the ReceivePubKey of the returned swap are
+// sent to the participant.
+func (s *Swapper) Initiate(ownCoin string, ownAmount int64, counterpartyCoin string, counterpartyAmount int64, recipientKey []byte, lockDuration time.Duration) (*Swap, error) {
+	secret, secretHash, err := NewSecret()
+	if err != nil {
+		return nil, err
+	}
+	sw := &Swap{
+		ID:           hex.EncodeToString(secretHash),
+		Role:         Initiator,
+		State:        StateCreated,
+		SecretHash:   secretHash,
+		Secret:       secret,
+		Created:      time.Now(),
+		Own:          Leg{Coin: coinCode(ownCoin), Amount: ownAmount},
+		Counterparty: Leg{Coin: coinCode(counterpartyCoin), Amount: counterpartyAmount},
+	}
+	return s.create(sw, recipientKey, lockDuration)
+}
+
+// Participate joins the swap of the initiator's contract, which must pay a key
+// returned by NewKey. Its own contract pays recipientKey, the key of the
+// initiator on ownCoin, and can be refunded after lockDuration, which must
+// end at least the minimum gap before the initiator's contract can be
+// refunded. The contract is funded once the initiator's contract confirmed
+// with counterpartyAmount.
+func (s *Swapper) Participate(ownCoin string, ownAmount int64, counterpartyCoin string, counterpartyAmount int64, counterpartyContract []byte, recipientKey []byte, lockDuration time.Duration) (*Swap, error) {
+	cpWallet, err := s.wallet(counterpartyCoin)
+	if err != nil {
+		return nil, err
+	}
+	contract, err := ParseContract(counterpartyContract)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkLockTime(cpWallet, contract.LockTime); err != nil {
+		return nil, fmt.Errorf("initiator contract: %s", err.Error())
+	}
+	if remaining(cpWallet, contract.LockTime) < lockDuration+s.limits.MinGap {
+		return nil, fmt.Errorf("initiator contract can be refunded less than %s after the participant contract", s.limits.MinGap)
+	}
+	sw := &Swap{
+		ID:           hex.EncodeToString(contract.SecretHash),
+		Role:         Participant,
+		State:        StateCreated,
+		SecretHash:   contract.SecretHash,
+		Created:      time.Now(),
+		Own:          Leg{Coin: coinCode(ownCoin), Amount: ownAmount},
+		Counterparty: Leg{Coin: coinCode(counterpartyCoin), Amount: counterpartyAmount},
+	}
+	if sw.ReceiveKey, err = s.keyIndex(cpWallet, contract.RecipientKey); err != nil {
+		return nil, err
+	}
+	if err := s.setCounterpartyContract(sw, cpWallet, contract); err != nil {
+		return nil, err
+	}
+	return s.create(sw, recipientKey, lockDuration)
+}
+
+// Participated sets the contract of the participant of a swap this wallet
+// initiated. The contract must pay the ReceivePubKey of the swap, stay locked
+// for the minimum number of blocks and be refundable at least the minimum gap
+// before the contract of the initiator. It is redeemed once it confirmed with
+// the counterparty amount.
+func (s *Swapper) Participated(id string, counterpartyContract []byte) error {
+	sw, err := s.GetSwap(id)
+	if err != nil {
+		return err
+	}
+	if sw.Role != Initiator || sw.Counterparty.Contract != nil {
+		return errors.New("swap does not wait for a participant contract")
+	}
+	ownWallet, err := s.wallet(sw.Own.Coin)
+	if err != nil {
+		return err
+	}
+	cpWallet, err := s.wallet(sw.Counterparty.Coin)
+	if err != nil {
+		return err
+	}
+	contract, err := ParseContract(counterpartyContract)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(contract.SecretHash, sw.SecretHash) {
+		return errors.New("participant contract is locked with another secret")
+	}
+	if !bytes.Equal(contract.RecipientKey, sw.ReceivePubKey) {
+		return errors.New("participant contract does not pay the swap key")
+	}
+	if tip, _ := ownWallet.ChainTip(); tip == 0 {
+		return ErrNoChainTip
+	}
+	if err := s.checkLockTime(cpWallet, contract.LockTime); err != nil {
+		return fmt.Errorf("participant contract: %s", err.Error())
+	}
+	if remaining(cpWallet, contract.LockTime)+s.limits.MinGap > remaining(ownWallet, sw.Own.LockTime) {
+		return fmt.Errorf("participant contract can be refunded less than %s before the initiator contract", s.limits.MinGap)
+	}
+	if err := s.setCounterpartyContract(&sw, cpWallet, contract); err != nil {
+		return err
+	}
+	s.update(id, func(stored *Swap) {
+		stored.Counterparty = sw.Counterparty
+	})
+	if err := s.watchCounterparty(&sw, cpWallet); err != nil {
+		return err
+	}
+	go s.advance(id)
+	return nil
+}
+
+// create builds the own contract of sw, persists the swap and starts it
+func (s *Swapper) create(sw *Swap, recipientKey []byte, lockDuration time.Duration) (*Swap, error) {
+	ownWallet, err := s.wallet(sw.Own.Coin)
+	if err != nil {
+		return nil, err
+	}
+	cpWallet, err := s.wallet(sw.Counterparty.Coin)
+	if err != nil {
+		return nil, err
+	}
+	tip, _ := ownWallet.ChainTip()
+	if tip == 0 {
+		return nil, ErrNoChainTip
+	}
+	lockBlocks := blocks(ownWallet, lockDuration)
+	if lockBlocks < s.limits.MinBlocks {
+		return nil, fmt.Errorf("lock duration of %s is shorter than %d blocks", lockDuration, s.limits.MinBlocks)
+	}
+	s.lock.Lock()
+	if _, ok := s.swaps[sw.ID]; ok {
+		s.lock.Unlock()
+		return nil, errors.New("swap already exists")
+	}
+	sw.RefundKey = s.nextKey
+	s.nextKey++
+	if sw.Role == Initiator {
+		sw.ReceiveKey = s.nextKey
+		s.nextKey++
+	}
+	s.lock.Unlock()
+
+	if sw.ReceivePubKey, err = swapPubKey(cpWallet, sw.ReceiveKey); err != nil {
+		return nil, err
+	}
+	refundPubKey, err := swapPubKey(ownWallet, sw.RefundKey)
+	if err != nil {
+		return nil, err
+	}
+	sw.Own.LockTime = tip + lockBlocks
+	addr, contract, err := ownWallet.GenerateHTLCScript(sw.SecretHash, recipientKey, refundPubKey, sw.Own.LockTime)
+	if err != nil {
+		return nil, err
+	}
+	sw.Own.Address = addr.String()
+	sw.Own.Contract = contract
+
+	s.lock.Lock()
+	if err = s.saveSwap(sw); err == nil {
+		s.swaps[sw.ID] = sw
+		if err = s.saveIndex(); err != nil {
+			delete(s.swaps, sw.ID)
+		}
+	}
+	s.lock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.watchLeg(sw.ID, &sw.Own, true); err != nil {
+		return nil, err
+	}
+	if sw.Role == Participant {
+		if err := s.watchCounterparty(sw, cpWallet); err != nil {
+			return nil, err
+		}
+	}
+	s.advance(sw.ID)
+	created, err := s.GetSwap(sw.ID)
+	return &created, err
+}
+
+// setCounterpartyContract sets the counterparty leg of sw to the contract
+// after checking it is a contract of the swap.
+func (s *Swapper) setCounterpartyContract(sw *Swap, cpWallet Wallet, contract *Contract) error {
+	addr, script, err := cpWallet.GenerateHTLCScript(contract.SecretHash, contract.RecipientKey, contract.RefundKey, contract.LockTime)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(script, contract.Script) {
+		return ErrNotHTLC
+	}
+	sw.Counterparty.Address = addr.String()
+	sw.Counterparty.Contract = contract.Script
+	sw.Counterparty.LockTime = contract.LockTime
+	return nil
+}
+
+// watch watches the contract addresses of sw for their funding and spends
+func (s *Swapper) watch(sw *Swap) error {
+	if err := s.watchLeg(sw.ID, &sw.Own, true); err != nil {
+		return err
+	}
+	return s.watchLeg(sw.ID, &sw.Counterparty, false)
+}
+
+func (s *Swapper) watchLeg(id string, leg *Leg, own bool) error {
+	if leg.Address == "" {
+		return nil
+	}
+	w, err := s.wallet(leg.Coin)
+	if err != nil {
+		return err
+	}
+	addr, err := w.DecodeAddress(leg.Address)
+	if err != nil {
+		return err
+	}
+	return w.WatchHTLC(addr, func(spend service.ScriptSpend) {
+		s.onSpend(id, own, spend)
+	})
+}
+
+// watchCounterparty watches the counterparty contract of sw and resyncs its
+// wallet, as the counterparty may have funded it before it was watched.
+func (s *Swapper) watchCounterparty(sw *Swap, cpWallet Wallet) error {
+	if err := s.watchLeg(sw.ID, &sw.Counterparty, false); err != nil {
+		return err
+	}
+	cpWallet.ReSyncBlockchain(sw.Created)
+	return nil
+}
+
+// onTransaction records the funding of the contracts paid by cb
+func (s *Swapper) onTransaction(coin string, cb wallet.TransactionCallback) {
+	var changed []string
+	s.lock.Lock()
+	for _, sw := range s.swaps {
+		if sw.Finished() {
+			continue
+		}
+		for _, leg := range []*Leg{&sw.Own, &sw.Counterparty} {
+			if leg.Coin != coin || leg.Address == "" {
+				continue
+			}
+			if leg.FundingTxid != "" && leg.FundingTxid != cb.Txid {
+				continue
+			}
+			for _, out := range cb.Outputs {
+				if out.Address == nil || out.Address.String() != leg.Address {
+					continue
+				}
+				leg.FundingTxid = cb.Txid
+				leg.FundingIndex = out.Index
+				leg.FundingValue = out.Value
+				leg.FundingHeight = cb.Height
+				if leg == &sw.Own && sw.State == StateFunding {
+					sw.State = StateFunded
+				}
+				changed = append(changed, sw.ID)
+				break
+			}
+		}
+	}
+	for _, id := range changed {
+		if err := s.saveSwap(s.swaps[id]); err != nil {
+			Log.Errorf("saving swap %s: %s", id, err.Error())
+		}
+	}
+	s.lock.Unlock()
+	for _, id := range changed {
+		go s.advance(id)
+	}
+}
+
+// onSpend records a spend of a contract of a swap and the secret it reveals
+func (s *Swapper) onSpend(id string, own bool, spend service.ScriptSpend) {
+	stack := [][]byte(spend.Witness)
+	if len(stack) == 0 {
+		var err error
+		if stack, err = multisig.ScriptStack(spend.SignatureScript); err != nil {
+			Log.Warningf("reading spend %s of swap %s: %s", spend.Txid, id, err.Error())
+		}
+	}
+	s.update(id, func(sw *Swap) {
+		leg := &sw.Counterparty
+		if own {
+			leg = &sw.Own
+		}
+		if leg.SpendTxid == "" {
+			leg.SpendTxid = spend.Txid
+		}
+		if sw.Secret != nil || len(leg.Contract) == 0 {
+			return
+		}
+		contract, err := ParseContract(leg.Contract)
+		if err != nil {
+			return
+		}
+		if secret, ok := contract.ExtractSecret(stack); ok {
+			sw.Secret = secret
+		}
+	})
+	go s.advance(id)
+}
+
+func (s *Swapper) advanceAll() {
+	s.lock.Lock()
+	var ids []string
+	for id, sw := range s.swaps {
+		if !sw.Finished() {
+			ids = append(ids, id)
+		}
+	}
+	s.lock.Unlock()
+	for _, id := range ids {
+		s.advance(id)
+	}
+}
+
+// advance takes the next step of a swap
+func (s *Swapper) advance(id string) {
+	s.advanceLock.Lock()
+	defer s.advanceLock.Unlock()
+
+	sw, err := s.GetSwap(id)
+	if err != nil || sw.Finished() {
+		return
+	}
+	ownWallet, err := s.wallet(sw.Own.Coin)
+	if err != nil {
+		return
+	}
+	cpWallet, err := s.wallet(sw.Counterparty.Coin)
+	if err != nil {
+		return
+	}
+
+	switch sw.State {
+	case StateCreated:
+		if sw.Role == Participant && !sw.Counterparty.funded() {
+			if tip, _ := cpWallet.ChainTip(); tip >= sw.Counterparty.LockTime {
+				s.update(id, func(sw *Swap) { sw.State = StateExpired })
+			}
+			return
+		}
+		s.fund(sw, ownWallet)
+	case StateFunding:
+		s.fund(sw, ownWallet)
+	case StateFunded:
+		ownTip, _ := ownWallet.ChainTip()
+		switch {
+		case sw.Secret != nil && sw.Counterparty.funded() && sw.Counterparty.SpendTxid == "":
+			s.redeem(sw, cpWallet)
+		case sw.Own.FundingTxid != "" && sw.Own.SpendTxid == "" && ownTip >= sw.Own.LockTime:
+			s.refund(sw, ownWallet)
+		}
+	}
+}
+
+func (s *Swapper) fund(sw Swap, w Wallet) {
+	addr, err := w.DecodeAddress(sw.Own.Address)
+	if err != nil {
+		s.fail(sw.ID, err)
+		return
+	}
+	// Once funding started the swap is no longer created, so a participant
+	// never expires a swap whose funding may have been broadcast. The spend
+	// request ID sends the same transaction again if this one fails.
+	if sw.State != StateFunding {
+		s.update(sw.ID, func(sw *Swap) { sw.State = StateFunding })
+	}
+	txid, err := w.SpendWithRequestID(sw.ID, sw.Own.Amount, addr, wallet.NORMAL, sw.ID, nil, false)
+	if err != nil {
+		s.fail(sw.ID, err)
+		return
+	}
+	Log.Noticef("funded %s contract of swap %s in %s", sw.Own.Coin, sw.ID, txid)
+	s.update(sw.ID, func(sw *Swap) {
+		sw.State = StateFunded
+		sw.Error = ""
+		if sw.Own.FundingTxid == "" {
+			sw.Own.FundingTxid = txid.String()
+		}
+	})
+}
+
+func (s *Swapper) redeem(sw Swap, w Wallet) {
+	key, err := swapKey(w, sw.ReceiveKey)
+	if err != nil {
+		s.fail(sw.ID, err)
+		return
+	}
+	ins, err := legInputs(w, &sw.Counterparty)
+	if err != nil {
+		s.fail(sw.ID, err)
+		return
+	}
+	txid, err := w.RedeemHTLC(ins, nil, key, sw.Counterparty.Contract, sw.Secret, wallet.NORMAL)
+	if err != nil {
+		s.fail(sw.ID, err)
+		return
+	}
+	Log.Noticef("redeemed %s contract of swap %s in %s", sw.Counterparty.Coin, sw.ID, txid)
+	s.update(sw.ID, func(sw *Swap) {
+		sw.State = StateRedeemed
+		sw.Error = ""
+		sw.Counterparty.SpendTxid = txid.String()
+	})
+}
+
+func (s *Swapper) refund(sw Swap, w Wallet) {
+	key, err := swapKey(w, sw.RefundKey)
+	if err != nil {
+		s.fail(sw.ID, err)
+		return
+	}
+	ins, err := legInputs(w, &sw.Own)
+	if err != nil {
+		s.fail(sw.ID, err)
+		return
+	}
+	txid, err := w.RefundHTLC(ins, nil, key, sw.Own.Contract, wallet.NORMAL)
+	if err != nil {
+		s.fail(sw.ID, err)
+		return
+	}
+	Log.Noticef("refunded %s contract of swap %s in %s", sw.Own.Coin, sw.ID, txid)
+	s.update(sw.ID, func(sw *Swap) {
+		sw.State = StateRefunded
+		sw.Error = ""
+		sw.Own.SpendTxid = txid.String()
+	})
+}
+
+// fail records an error of a swap which is retried on its next advance
+func (s *Swapper) fail(id string, err error) {
+	Log.Errorf("swap %s: %s", id, err.Error())
+	s.update(id, func(sw *Swap) { sw.Error = err.Error() })
+}
+
+// update changes a swap, persists it and notifies the listeners
+func (s *Swapper) update(id string, change func(*Swap)) {
+	s.lock.Lock()
+	sw, ok := s.swaps[id]
+	if !ok {
+		s.lock.Unlock()
+		return
+	}
+	change(sw)
+	if err := s.saveSwap(sw); err != nil {
+		Log.Errorf("saving swap %s: %s", id, err.Error())
+	}
+	updated := *sw
+	listeners := s.listeners
+	s.lock.Unlock()
+	for _, l := range listeners {
+		go l(updated)
+	}
+}
+
+// load reads the saved swaps and the next swap key
+func (s *Swapper) load() error {
+	raw, err := s.records.Get(swapsKey)
+	if err == datastore.ErrNoRecord {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("loading swaps: %s", err.Error())
+	}
+	var persisted persistedSwaps
+	if err := json.Unmarshal(raw, &persisted); err != nil {
+		return fmt.Errorf("unmarshaling swaps: %s", err.Error())
+	}
+	s.nextKey = persisted.NextKey
+	for _, id := range persisted.IDs {
+		raw, err := s.records.Get(swapKeyPrefix + id)
+		if err != nil {
+			return fmt.Errorf("loading swap %s: %s", id, err.Error())
+		}
+		sw := new(Swap)
+		if err := json.Unmarshal(raw, sw); err != nil {
+			return fmt.Errorf("unmarshaling swap %s: %s", id, err.Error())
+		}
+		s.swaps[id] = sw
+	}
+	return nil
+}
+
+// saveIndex saves the swap IDs and the next swap key. The lock must be held.
+func (s *Swapper) saveIndex() error {
+	persisted := persistedSwaps{NextKey: s.nextKey}
+	for id := range s.swaps {
+		persisted.IDs = append(persisted.IDs, id)
+	}
+	raw, err := json.Marshal(persisted)
+	if err != nil {
+		return err
+	}
+	return s.records.Put(swapsKey, raw)
+}
+
+// saveSwap saves the state of sw. The lock must be held.
+func (s *Swapper) saveSwap(sw *Swap) error {
+	raw, err := json.Marshal(sw)
+	if err != nil {
+		return err
+	}
+	return s.records.Put(swapKeyPrefix+sw.ID, raw)
+}
+
+func (s *Swapper) wallet(coin string) (Wallet, error) {
+	w, ok := s.wallets[coinCode(coin)]
+	if !ok {
+		return nil, fmt.Errorf("no wallet supporting swaps for %s", coin)
+	}
+	return w, nil
+}
+
+// keyIndex returns the index of the reserved swap key of w with the given
+// public key.
+func (s *Swapper) keyIndex(w Wallet, pubKey []byte) (uint32, error) {
+	s.lock.Lock()
+	nextKey := s.nextKey
+	s.lock.Unlock()
+	for i := uint32(0); i < nextKey; i++ {
+		key, err := swapPubKey(w, i)
+		if err != nil {
+			return 0, err
+		}
+		if bytes.Equal(key, pubKey) {
+			return i, nil
+		}
+	}
+	return 0, errors.New("contract does not pay a swap key of this wallet")
+}
+
+// swapKey derives the swap key of w with the given index. Swap keys are
+// derived from the master key so they can be recovered after a restart from
+// the index persisted with the swap.
+func swapKey(w Wallet, index uint32) (*hdkeychain.ExtendedKey, error) {
+	purpose, err := w.MasterPrivateKey().Child(swapKeyPurpose)
+	if err != nil {
+		return nil, err
+	}
+	return purpose.Child(hdkeychain.HardenedKeyStart + index)
+}
+
+func swapPubKey(w Wallet, index uint32) ([]byte, error) {
+	key, err := swapKey(w, index)
+	if err != nil {
+		return nil, err
+	}
+	pubKey, err := key.ECPubKey()
+	if err != nil {
+		return nil, err
+	}
+	return pubKey.SerializeCompressed(), nil
+}
+
+// legInputs returns the funding output of a contract as a transaction input
+func legInputs(w Wallet, leg *Leg) ([]wallet.TransactionInput, error) {
+	h, err := hex.DecodeString(leg.FundingTxid)
+	if err != nil {
+		return nil, err
+	}
+	addr, err := w.DecodeAddress(leg.Address)
+	if err != nil {
+		return nil, err
+	}
+	return []wallet.TransactionInput{{
+		OutpointHash:  h,
+		OutpointIndex: leg.FundingIndex,
+		Value:         leg.FundingValue,
+		LinkedAddress: addr,
+	}}, nil
+}
+
+// coinCode returns the currency code of a coin without the testnet prefix
+func coinCode(code string) string {
+	if c, ok := registry.ByCurrencyCode(code); ok {
+		return c.CurrencyCode
+	}
+	return strings.ToUpper(code)
+}
+
+// blockInterval returns the block interval registered for the coin of w
+func blockInterval(w Wallet) time.Duration {
+	if c, ok := registry.ByCurrencyCode(w.CurrencyCode()); ok && c.BlockInterval > 0 {
+		return c.BlockInterval
+	}
+	return 10 * time.Minute
+}
+
+// blocks returns the number of blocks of w mined in d, rounded up
+func blocks(w Wallet, d time.Duration) uint32 {
+	interval := blockInterval(w)
+	return uint32((d + interval - 1) / interval)
+}
+
+// checkLockTime returns an error unless a contract on the chain of w locked
+// until lockTime stays locked for the minimum number of blocks.
+func (s *Swapper) checkLockTime(w Wallet, lockTime uint32) error {
+	tip, _ := w.ChainTip()
+	if tip == 0 {
+		return ErrNoChainTip
+	}
+	if lockTime <= tip {
+		return errors.New("lock time has passed")
+	}
+	if lockTime-tip < s.limits.MinBlocks {
+		return fmt.Errorf("refundable in %d blocks, fewer than %d", lockTime-tip, s.limits.MinBlocks)
+	}
+	return nil
+}
+
+// remaining returns the expected time until the chain of w reaches lockTime
+func remaining(w Wallet, lockTime uint32) time.Duration {
+	tip, _ := w.ChainTip()
+	if tip >= lockTime {
+		return 0
+	}
+	return time.Duration(lockTime-tip) * blockInterval(w)
+}
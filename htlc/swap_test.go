@@ -0,0 +1,476 @@
+package htlc
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/muecoin/multiwallet/cache"
+	"github.com/muecoin/multiwallet/config"
+	"github.com/muecoin/multiwallet/datastore"
+	"github.com/muecoin/multiwallet/registry"
+	"github.com/muecoin/multiwallet/service"
+	"github.com/OpenBazaar/wallet-interface"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcutil/hdkeychain"
+	"golang.org/x/net/proxy"
+)
+
+func newRegistryWallet(config.CoinConfig, string, *chaincfg.Params, proxy.Dialer, cache.Cacher, bool) (wallet.Wallet, error) {
+	return nil, errors.New("not implemented")
+}
+
+func init() {
+	registry.Register(registry.Coin{
+		Name:                "Test Bitcoin",
+		CoinType:            900101,
+		TestnetCoinType:     1900101,
+		CurrencyCode:        "BTC",
+		TestnetCurrencyCode: "TBTC",
+		NewWallet:           newRegistryWallet,
+		BlockInterval:       10 * time.Minute,
+	})
+	registry.Register(registry.Coin{
+		Name:                "Test Litecoin",
+		CoinType:            900102,
+		TestnetCoinType:     1900102,
+		CurrencyCode:        "LTC",
+		TestnetCurrencyCode: "TLTC",
+		NewWallet:           newRegistryWallet,
+		BlockInterval:       150 * time.Second,
+	})
+}
+
+// testChain is a chain shared by the wallets of both sides of a swap. Every
+// transaction is confirmed as soon as it is sent.
+type testChain struct {
+	code    string
+	tip     uint32
+	txs     []wallet.TransactionCallback
+	wallets []*testWallet
+	spends  map[string][]func(service.ScriptSpend)
+	lock    sync.Mutex
+}
+
+func newTestChain(code string) *testChain {
+	return &testChain{code: code, tip: 1000, spends: make(map[string][]func(service.ScriptSpend))}
+}
+
+func (c *testChain) setTip(tip uint32) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.tip = tip
+}
+
+func (c *testChain) send(addr btcutil.Address, value int64) *chainhash.Hash {
+	c.lock.Lock()
+	txid := chainhash.Hash{byte(len(c.txs) + 1), c.code[0]}
+	cb := wallet.TransactionCallback{
+		Txid:    txid.String(),
+		Height:  int32(c.tip),
+		Outputs: []wallet.TransactionOutput{{Address: addr, Value: value, Index: 1}},
+	}
+	c.txs = append(c.txs, cb)
+	wallets := c.wallets
+	c.lock.Unlock()
+	for _, w := range wallets {
+		w.notify(cb)
+	}
+	return &txid
+}
+
+func (c *testChain) spend(addr btcutil.Address, witness [][]byte) *chainhash.Hash {
+	c.lock.Lock()
+	txid := chainhash.Hash{byte(len(c.txs) + 1), c.code[0]}
+	c.txs = append(c.txs, wallet.TransactionCallback{Txid: txid.String(), Height: int32(c.tip)})
+	watchers := c.spends[addr.String()]
+	c.lock.Unlock()
+	for _, watcher := range watchers {
+		go watcher(service.ScriptSpend{Txid: txid.String(), Witness: witness})
+	}
+	return &txid
+}
+
+func (c *testChain) count() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return len(c.txs)
+}
+
+// testWallet implements the methods of Wallet used by the Swapper
+type testWallet struct {
+	wallet.Wallet
+	chain     *testChain
+	master    *hdkeychain.ExtendedKey
+	listeners []func(wallet.TransactionCallback)
+	requests  map[string]*chainhash.Hash
+	lock      sync.Mutex
+}
+
+func newTestWallet(t *testing.T, chain *testChain, seed byte) *testWallet {
+	master, err := hdkeychain.NewMaster(bytes.Repeat([]byte{seed}, 32), &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := &testWallet{chain: chain, master: master, requests: make(map[string]*chainhash.Hash)}
+	chain.lock.Lock()
+	chain.wallets = append(chain.wallets, w)
+	chain.lock.Unlock()
+	return w
+}
+
+func (w *testWallet) notify(cb wallet.TransactionCallback) {
+	w.lock.Lock()
+	listeners := w.listeners
+	w.lock.Unlock()
+	for _, l := range listeners {
+		l(cb)
+	}
+}
+
+func (w *testWallet) CurrencyCode() string { return w.chain.code }
+
+func (w *testWallet) MasterPrivateKey() *hdkeychain.ExtendedKey { return w.master }
+
+func (w *testWallet) ChainTip() (uint32, chainhash.Hash) {
+	w.chain.lock.Lock()
+	defer w.chain.lock.Unlock()
+	return w.chain.tip, chainhash.Hash{}
+}
+
+func (w *testWallet) DecodeAddress(addr string) (btcutil.Address, error) {
+	return btcutil.DecodeAddress(addr, &chaincfg.MainNetParams)
+}
+
+func (w *testWallet) AddTransactionListener(callback func(wallet.TransactionCallback)) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.listeners = append(w.listeners, callback)
+}
+
+func (w *testWallet) ReSyncBlockchain(fromTime time.Time) {
+	w.chain.lock.Lock()
+	txs := w.chain.txs
+	w.chain.lock.Unlock()
+	for _, cb := range txs {
+		w.notify(cb)
+	}
+}
+
+func (w *testWallet) Spend(amount int64, addr btcutil.Address, feeLevel wallet.FeeLevel, referenceID string, spendAll bool) (*chainhash.Hash, error) {
+	return w.chain.send(addr, amount), nil
+}
+
+// SpendWithRequestID sends a request ID once, like the coin wallets
+func (w *testWallet) SpendWithRequestID(requestID string, amount int64, addr btcutil.Address, feeLevel wallet.FeeLevel, referenceID string, data []byte, spendAll bool) (*chainhash.Hash, error) {
+	w.lock.Lock()
+	txid, ok := w.requests[requestID]
+	w.lock.Unlock()
+	if ok {
+		return txid, nil
+	}
+	txid = w.chain.send(addr, amount)
+	w.lock.Lock()
+	w.requests[requestID] = txid
+	w.lock.Unlock()
+	return txid, nil
+}
+
+func (w *testWallet) GenerateHTLCScript(secretHash, recipientKey, refundKey []byte, lockTime uint32) (btcutil.Address, []byte, error) {
+	contract, err := NewContract(secretHash, recipientKey, refundKey, lockTime)
+	if err != nil {
+		return nil, nil, err
+	}
+	addr, err := btcutil.NewAddressScriptHash(contract.Script, &chaincfg.MainNetParams)
+	if err != nil {
+		return nil, nil, err
+	}
+	return addr, contract.Script, nil
+}
+
+func (w *testWallet) RedeemHTLC(ins []wallet.TransactionInput, address btcutil.Address, key *hdkeychain.ExtendedKey, script []byte, secret []byte, feeLevel wallet.FeeLevel) (*chainhash.Hash, error) {
+	contract, err := ParseContract(script)
+	if err != nil {
+		return nil, err
+	}
+	if !contract.Matches(secret) {
+		return nil, ErrSecretMismatch
+	}
+	if err := checkKey(key, contract.RecipientKey); err != nil {
+		return nil, err
+	}
+	return w.chain.spend(ins[0].LinkedAddress, contract.RedeemStack([]byte{0x30}, secret)), nil
+}
+
+func (w *testWallet) RefundHTLC(ins []wallet.TransactionInput, address btcutil.Address, key *hdkeychain.ExtendedKey, script []byte, feeLevel wallet.FeeLevel) (*chainhash.Hash, error) {
+	contract, err := ParseContract(script)
+	if err != nil {
+		return nil, err
+	}
+	if tip, _ := w.ChainTip(); !contract.Refundable(tip) {
+		return nil, ErrLockTimeNotReached
+	}
+	if err := checkKey(key, contract.RefundKey); err != nil {
+		return nil, err
+	}
+	return w.chain.spend(ins[0].LinkedAddress, contract.RefundStack([]byte{0x30})), nil
+}
+
+func (w *testWallet) WatchHTLC(addr btcutil.Address, callback func(service.ScriptSpend)) error {
+	w.chain.lock.Lock()
+	defer w.chain.lock.Unlock()
+	w.chain.spends[addr.String()] = append(w.chain.spends[addr.String()], callback)
+	return nil
+}
+
+func checkKey(key *hdkeychain.ExtendedKey, pubKey []byte) error {
+	ecKey, err := key.ECPubKey()
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(ecKey.SerializeCompressed(), pubKey) {
+		return errors.New("wrong key")
+	}
+	return nil
+}
+
+func waitForState(t *testing.T, s *Swapper, id string, state State) Swap {
+	for i := 0; i < 100; i++ {
+		sw, err := s.GetSwap(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sw.State == state {
+			return sw
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	sw, _ := s.GetSwap(id)
+	t.Fatalf("Swap %s is %s instead of %s: %s", id, sw.State, state, sw.Error)
+	return sw
+}
+
+func TestSwapper_Swap(t *testing.T) {
+	btcChain, ltcChain := newTestChain("BTC"), newTestChain("LTC")
+	alice, err := NewSwapper([]Wallet{newTestWallet(t, btcChain, 1), newTestWallet(t, ltcChain, 1)}, datastore.NewMockRecords(), LockLimits{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bob, err := NewSwapper([]Wallet{newTestWallet(t, btcChain, 2), newTestWallet(t, ltcChain, 2)}, datastore.NewMockRecords(), LockLimits{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bobKey, err := bob.NewKey("BTC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	initiated, err := alice.Initiate("BTC", 100000, "LTC", 500000, bobKey, 48*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if initiated.State != StateFunded || initiated.Own.LockTime != 1000+288 {
+		t.Fatalf("Expected a funded contract locked until 1288 but had %s until %d", initiated.State, initiated.Own.LockTime)
+	}
+
+	if _, err := bob.Participate("LTC", 500000, "BTC", 100000, initiated.Own.Contract, initiated.ReceivePubKey, 48*time.Hour); err == nil {
+		t.Error("Participated with a contract refundable after the initiator contract")
+	}
+	participated, err := bob.Participate("LTC", 500000, "BTC", 100000, initiated.Own.Contract, initiated.ReceivePubKey, 24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if funded := waitForState(t, bob, participated.ID, StateFunded); funded.ID != initiated.ID || funded.Own.LockTime != 1000+576 {
+		t.Errorf("Expected participant contract locked until 1576 but had %d", funded.Own.LockTime)
+	}
+
+	if err := alice.Participated(initiated.ID, initiated.Own.Contract); err == nil {
+		t.Error("Accepted a participant contract which does not pay the initiator")
+	}
+	if err := alice.Participated(initiated.ID, participated.Own.Contract); err != nil {
+		t.Fatal(err)
+	}
+	redeemed := waitForState(t, alice, initiated.ID, StateRedeemed)
+	if redeemed.Counterparty.SpendTxid == "" {
+		t.Error("Initiator redeem was not recorded")
+	}
+
+	// The initiator's redeem reveals the secret to the participant
+	participantRedeemed := waitForState(t, bob, participated.ID, StateRedeemed)
+	if !bytes.Equal(participantRedeemed.Secret, redeemed.Secret) {
+		t.Error("Participant did not learn the secret from the initiator redeem")
+	}
+}
+
+func TestSwapper_Refund(t *testing.T) {
+	btcChain, ltcChain := newTestChain("BTC"), newTestChain("LTC")
+	alice, err := NewSwapper([]Wallet{newTestWallet(t, btcChain, 1), newTestWallet(t, ltcChain, 1)}, datastore.NewMockRecords(), LockLimits{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bobKey, _ := newTestWallet(t, btcChain, 2).master.ECPubKey()
+
+	initiated, err := alice.Initiate("BTC", 100000, "LTC", 500000, bobKey.SerializeCompressed(), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	alice.advanceAll()
+	if sw, _ := alice.GetSwap(initiated.ID); sw.State != StateFunded {
+		t.Errorf("Expected the swap to wait for the participant but it is %s", sw.State)
+	}
+
+	btcChain.setTip(initiated.Own.LockTime)
+	alice.advanceAll()
+	refunded := waitForState(t, alice, initiated.ID, StateRefunded)
+	if refunded.Own.SpendTxid == "" {
+		t.Error("Refund was not recorded")
+	}
+}
+
+func TestSwapper_Resume(t *testing.T) {
+	btcChain, ltcChain := newTestChain("BTC"), newTestChain("LTC")
+	wallets := []Wallet{newTestWallet(t, btcChain, 1), newTestWallet(t, ltcChain, 1)}
+	records := datastore.NewMockRecords()
+	alice, err := NewSwapper(wallets, records, LockLimits{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bobKey, _ := newTestWallet(t, btcChain, 2).master.ECPubKey()
+	initiated, err := alice.Initiate("BTC", 100000, "LTC", 500000, bobKey.SerializeCompressed(), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := alice.Initiate("BTC", 100000, "LTC", 500000, bobKey.SerializeCompressed(), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Crash after the funding of the second swap was broadcast, before it
+	// was recorded
+	alice.update(second.ID, func(sw *Swap) {
+		sw.State = StateFunding
+		sw.Own.FundingTxid = ""
+	})
+	sent := btcChain.count()
+
+	restarted, err := NewSwapper(wallets, records, LockLimits{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resumed, err := restarted.GetSwap(initiated.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resumed.State != StateFunded || !bytes.Equal(resumed.Secret, initiated.Secret) || resumed.Own.FundingTxid != initiated.Own.FundingTxid {
+		t.Errorf("Resumed swap %+v does not match %+v", resumed, initiated)
+	}
+	key, err := restarted.NewKey("BTC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := uint32(0); i < 4; i++ {
+		used, err := swapPubKey(wallets[0], i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bytes.Equal(key, used) {
+			t.Errorf("Reused swap key %d after a restart", i)
+		}
+	}
+
+	restarted.advanceAll()
+	if btcChain.count() != sent {
+		t.Error("Funded a contract again after a restart")
+	}
+	if sw, _ := restarted.GetSwap(second.ID); sw.State != StateFunded || sw.Own.FundingTxid != second.Own.FundingTxid {
+		t.Errorf("Expected the resumed funding to return %s but had %s in state %s", second.Own.FundingTxid, sw.Own.FundingTxid, sw.State)
+	}
+
+	btcChain.setTip(initiated.Own.LockTime)
+	restarted.advanceAll()
+	waitForState(t, restarted, initiated.ID, StateRefunded)
+}
+
+func TestSwapper_LockTimes(t *testing.T) {
+	btcChain, ltcChain := newTestChain("BTC"), newTestChain("LTC")
+	alice, err := NewSwapper([]Wallet{newTestWallet(t, btcChain, 1), newTestWallet(t, ltcChain, 1)}, datastore.NewMockRecords(), LockLimits{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bob, err := NewSwapper([]Wallet{newTestWallet(t, btcChain, 2), newTestWallet(t, ltcChain, 2)}, datastore.NewMockRecords(), LockLimits{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bobKey, err := bob.NewKey("BTC")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := alice.Initiate("BTC", 100000, "LTC", 500000, bobKey, 50*time.Minute); err == nil {
+		t.Error("Initiated a contract locked for fewer than the minimum blocks")
+	}
+	initiated, err := alice.Initiate("BTC", 100000, "LTC", 500000, bobKey, 48*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bob.Participate("LTC", 500000, "BTC", 100000, initiated.Own.Contract, initiated.ReceivePubKey, 43*time.Hour); err == nil {
+		t.Error("Participated with a contract refundable less than the minimum gap before the initiator contract")
+	}
+	if _, err := bob.Participate("LTC", 500000, "BTC", 100000, initiated.Own.Contract, initiated.ReceivePubKey, 10*time.Minute); err == nil {
+		t.Error("Participated with a contract locked for fewer than the minimum blocks")
+	}
+
+	// Litecoin mines 24 blocks an hour, the participant contract must be
+	// refundable in 6 blocks at least and in 42 hours at most.
+	refundKey, _ := newTestWallet(t, ltcChain, 3).master.ECPubKey()
+	contract := func(lockTime uint32) []byte {
+		c, err := NewContract(initiated.SecretHash, initiated.ReceivePubKey, refundKey.SerializeCompressed(), lockTime)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c.Script
+	}
+	for _, lockTime := range []uint32{999, 1000, 1000 + DefaultMinLockBlocks - 1, 1000 + 42*24 + 1} {
+		if err := alice.Participated(initiated.ID, contract(lockTime)); err == nil {
+			t.Errorf("Accepted a participant contract locked until %d", lockTime)
+		}
+	}
+	if err := alice.Participated(initiated.ID, contract(1000+42*24)); err != nil {
+		t.Error(err)
+	}
+
+	btcChain.setTip(initiated.Own.LockTime)
+	if _, err := bob.Participate("LTC", 500000, "BTC", 100000, initiated.Own.Contract, initiated.ReceivePubKey, 24*time.Hour); err == nil {
+		t.Error("Participated with an expired initiator contract")
+	}
+
+	btcChain.setTip(0)
+	if _, err := alice.Initiate("BTC", 100000, "LTC", 500000, bobKey, 48*time.Hour); err != ErrNoChainTip {
+		t.Errorf("Expected ErrNoChainTip without a chain tip but had %v", err)
+	}
+}
+
+func TestBlockInterval(t *testing.T) {
+	tests := []struct {
+		code     string
+		coin     string
+		interval time.Duration
+	}{
+		{"LTC", "LTC", 150 * time.Second},
+		{"tltc", "LTC", 150 * time.Second},
+		{"TBTC", "BTC", 10 * time.Minute},
+		{"XYZ", "XYZ", 10 * time.Minute},
+	}
+	for _, test := range tests {
+		if coin := coinCode(test.code); coin != test.coin {
+			t.Errorf("%s: expected coin %s, got %s", test.code, test.coin, coin)
+		}
+		w := &testWallet{chain: newTestChain(test.code)}
+		if interval := blockInterval(w); interval != test.interval {
+			t.Errorf("%s: expected interval %s, got %s", test.code, test.interval, interval)
+		}
+	}
+}
@@ -24,8 +24,16 @@ type KeyManager struct {
 
 type AddrFunc func(k *hd.ExtendedKey, net *chaincfg.Params) (btcutil.Address, error)
 
+// DerivationFunc returns the internal and external chain keys of an account
+type DerivationFunc func(masterPrivKey *hd.ExtendedKey, coinType util.ExtCoinType) (internal, external *hd.ExtendedKey, err error)
+
 func NewKeyManager(db wallet.Keys, params *chaincfg.Params, masterPrivKey *hd.ExtendedKey, coinType util.ExtCoinType, getAddr AddrFunc) (*KeyManager, error) {
-	internal, external, err := Bip44Derivation(masterPrivKey, coinType)
+	return NewKeyManagerWithDerivation(db, params, masterPrivKey, coinType, getAddr, Bip44Derivation)
+}
+
+// NewKeyManagerWithDerivation returns a KeyManager for the account derived by derive
+func NewKeyManagerWithDerivation(db wallet.Keys, params *chaincfg.Params, masterPrivKey *hd.ExtendedKey, coinType util.ExtCoinType, getAddr AddrFunc, derive DerivationFunc) (*KeyManager, error) {
+	internal, external, err := derive(masterPrivKey, coinType)
 	if err != nil {
 		return nil, err
 	}
@@ -45,13 +53,23 @@ func NewKeyManager(db wallet.Keys, params *chaincfg.Params, masterPrivKey *hd.Ex
 
 // m / purpose' / coin_type' / account' / change / address_index
 func Bip44Derivation(masterPrivKey *hd.ExtendedKey, coinType util.ExtCoinType) (internal, external *hd.ExtendedKey, err error) {
-	// Purpose = bip44
-	fourtyFour, err := masterPrivKey.Child(hd.HardenedKeyStart + 44)
+	return accountDerivation(masterPrivKey, 44, coinType)
+}
+
+// Bip86Derivation derives the account of single key taproot outputs,
+// m / 86' / coin_type' / account' / change / address_index
+func Bip86Derivation(masterPrivKey *hd.ExtendedKey, coinType util.ExtCoinType) (internal, external *hd.ExtendedKey, err error) {
+	return accountDerivation(masterPrivKey, 86, coinType)
+}
+
+func accountDerivation(masterPrivKey *hd.ExtendedKey, purpose uint32, coinType util.ExtCoinType) (internal, external *hd.ExtendedKey, err error) {
+	// Purpose
+	purposeKey, err := masterPrivKey.Child(hd.HardenedKeyStart + purpose)
 	if err != nil {
 		return nil, nil, err
 	}
 	// Cointype
-	bitcoin, err := fourtyFour.Child(hd.HardenedKeyStart + uint32(coinType))
+	bitcoin, err := purposeKey.Child(hd.HardenedKeyStart + uint32(coinType))
 	if err != nil {
 		return nil, nil, err
 	}
@@ -12,6 +12,7 @@ import (
 	"github.com/btcsuite/btcd/txscript"
 	"github.com/btcsuite/btcutil"
 	"github.com/btcsuite/btcutil/hdkeychain"
+	"github.com/tyler-smith/go-bip39"
 )
 
 func createKeyManager() (*KeyManager, error) {
@@ -74,6 +75,55 @@ func TestBip44Derivation(t *testing.T) {
 	}
 }
 
+func TestBip86Derivation(t *testing.T) {
+	// Test vectors of BIP86
+	seed := bip39.NewSeed("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about", "")
+	masterPrivKey, err := hdkeychain.NewMaster(seed, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	internal, external, err := Bip86Derivation(masterPrivKey, wallet.Bitcoin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		chain       *hdkeychain.ExtendedKey
+		internalKey string
+	}{
+		{external, "cc8a4bc64d897bddc5fbc2f670f7a8ba0b386779106cf1223c6fc5d7cd6fc115"},
+		{internal, "399f1b2f4393f29a18c937859c5dd8a77350103157eb880f02e8c08214277cef"},
+	} {
+		key, err := test.chain.Child(0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pubKey, err := key.ECPubKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		xOnly := pubKey.SerializeCompressed()[1:]
+		if hex.EncodeToString(xOnly) != test.internalKey {
+			t.Errorf("Incorrect Bip86 key derivation: expected %s but had %x", test.internalKey, xOnly)
+		}
+	}
+
+	// m/86'/0'/0'/0/0
+	key, err := external.Child(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if xprv := key.String(); xprv != "xprvA449goEeU9okwCzzZaxiy475EQGQzBkc65su82nXEvcwzfSskb2hAt2WymrjyRL6kpbVTGL3cKtp9herYXSjjQ1j4stsXXiRF7kXkCacK3T" {
+		t.Errorf("Incorrect Bip86 extended private key %s", xprv)
+	}
+	pub, err := key.Neuter()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if xpub := pub.String(); xpub != "xpub6H3W6JmYJXN49h5TfcVjLC3onS6uPeUTTJoVvRC8oG9vsTn2J8LwigLzq5tHbrwAzH9DGo6ThGUdWsqce8dGfwHVBxSbixjDADGGdzF7t2B" {
+		t.Errorf("Incorrect Bip86 extended public key %s", xpub)
+	}
+}
+
 func TestKeys_generateChildKey(t *testing.T) {
 	km, err := createKeyManager()
 	if err != nil {
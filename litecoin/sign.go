@@ -25,19 +25,52 @@ import (
 	"github.com/ltcsuite/ltcutil"
 	"github.com/ltcsuite/ltcwallet/wallet/txrules"
 
+	"github.com/muecoin/multiwallet/htlc"
 	laddr "github.com/muecoin/multiwallet/litecoin/address"
+	"github.com/muecoin/multiwallet/multisig"
 	"github.com/muecoin/multiwallet/util"
 )
 
 func (w *LitecoinWallet) buildTx(amount int64, addr btc.Address, feeLevel wi.FeeLevel, optionalOutput *wire.TxOut) (*wire.MsgTx, error) {
-	// Check for dust
-	script, _ := laddr.PayToAddrScript(addr)
-	if txrules.IsDustAmount(ltcutil.Amount(amount), len(script), txrules.DefaultRelayFeePerKb) {
-		return nil, wi.ErrorDustAmount
+	return w.buildBatchTx([]wi.TransactionOutput{{Address: addr, Value: amount}}, feeLevel, optionalOutput)
+}
+
+// buildBatchTx builds a transaction paying every payment and optionalOutput
+// if set.
+func (w *LitecoinWallet) buildBatchTx(payments []wi.TransactionOutput, feeLevel wi.FeeLevel, optionalOutput *wire.TxOut) (*wire.MsgTx, error) {
+	plan, err := w.planBatchTx(payments, feeLevel, optionalOutput)
+	if err != nil {
+		return nil, err
+	}
+	return w.signPlan(plan)
+}
+
+func (w *LitecoinWallet) planBatchTx(payments []wi.TransactionOutput, feeLevel wi.FeeLevel, optionalOutput *wire.TxOut) (*util.SpendPlan, error) {
+	if len(payments) == 0 {
+		return nil, errors.New("no payments to send")
+	}
+	var outputs []*wire.TxOut
+	for _, payment := range payments {
+		// Check for dust
+		script, err := laddr.PayToAddrScript(payment.Address)
+		if err != nil {
+			return nil, err
+		}
+		if txrules.IsDustAmount(ltcutil.Amount(payment.Value), len(script), txrules.DefaultRelayFeePerKb) {
+			return nil, wi.ErrorDustAmount
+		}
+		outputs = append(outputs, wire.NewTxOut(payment.Value, script))
 	}
+	if optionalOutput != nil {
+		outputs = append(outputs, optionalOutput)
+	}
+	return w.planTxWithOutputs(outputs, feeLevel)
+}
 
-	var additionalPrevScripts map[wire.OutPoint][]byte
-	var additionalKeysByAddress map[string]*btc.WIF
+// planTxWithOutputs selects the coins paying outputs at feeLevel and adds a
+// change output unless the change is dust.
+func (w *LitecoinWallet) planTxWithOutputs(outputs []*wire.TxOut, feeLevel wi.FeeLevel) (*util.SpendPlan, error) {
+	var prevOuts map[wire.OutPoint]*wire.TxOut
 
 	// Create input source
 	height, _ := w.ws.ChainTip()
@@ -57,26 +90,14 @@ func (w *LitecoinWallet) buildTx(amount int64, addr btc.Address, feeLevel wi.Fee
 		if err != nil {
 			return total, inputs, inputValues, scripts, wi.ErrorInsuffientFunds
 		}
-		additionalPrevScripts = make(map[wire.OutPoint][]byte)
-		additionalKeysByAddress = make(map[string]*btc.WIF)
+		prevOuts = make(map[wire.OutPoint]*wire.TxOut)
 		for _, c := range coins.Coins() {
 			total += c.Value()
 			outpoint := wire.NewOutPoint(c.Hash(), c.Index())
 			in := wire.NewTxIn(outpoint, []byte{}, [][]byte{})
 			in.Sequence = 0 // Opt-in RBF so we can bump fees
 			inputs = append(inputs, in)
-			additionalPrevScripts[*outpoint] = c.PkScript()
-			key := coinMap[c]
-			addr, err := w.km.KeyToAddress(key)
-			if err != nil {
-				continue
-			}
-			privKey, err := key.ECPrivKey()
-			if err != nil {
-				continue
-			}
-			wif, _ := btc.NewWIF(privKey, w.params, true)
-			additionalKeysByAddress[addr.EncodeAddress()] = wif
+			prevOuts[*outpoint] = wire.NewTxOut(int64(c.Value()), c.PkScript())
 		}
 		return total, inputs, inputValues, scripts, nil
 	}
@@ -84,23 +105,18 @@ func (w *LitecoinWallet) buildTx(amount int64, addr btc.Address, feeLevel wi.Fee
 	// Get the fee per kilobyte
 	feePerKB := int64(w.GetFeePerByte(feeLevel)) * 1000
 
-	// outputs
-	out := wire.NewTxOut(amount, script)
-
 	// Create change source
+	var changeScript []byte
 	changeSource := func() ([]byte, error) {
 		addr := w.CurrentAddress(wi.INTERNAL)
 		script, err := laddr.PayToAddrScript(addr)
 		if err != nil {
 			return []byte{}, err
 		}
+		changeScript = script
 		return script, nil
 	}
 
-	outputs := []*wire.TxOut{out}
-	if optionalOutput != nil {
-		outputs = append(outputs, optionalOutput)
-	}
 	authoredTx, err := newUnsignedTransaction(outputs, btc.Amount(feePerKB), inputSource, changeSource)
 	if err != nil {
 		return nil, err
@@ -109,33 +125,28 @@ func (w *LitecoinWallet) buildTx(amount int64, addr btc.Address, feeLevel wi.Fee
 	// BIP 69 sorting
 	txsort.InPlaceSort(authoredTx.Tx)
 
-	// Sign tx
-	getKey := txscript.KeyClosure(func(addr btc.Address) (*btcec.PrivateKey, bool, error) {
-		a, err := laddr.NewAddressPubKeyHash(addr.ScriptAddress(), w.params)
-		if err != nil {
-			return nil, false, err
-		}
-		wif := additionalKeysByAddress[a.EncodeAddress()]
-		return wif.PrivKey, wif.CompressPubKey, nil
-	})
-	getScript := txscript.ScriptClosure(func(
-		addr btc.Address) ([]byte, error) {
-		return []byte{}, nil
-	})
-	for i, txIn := range authoredTx.Tx.TxIn {
-		prevOutScript := additionalPrevScripts[txIn.PreviousOutPoint]
-		script, err := txscript.SignTxOutput(w.params,
-			authoredTx.Tx, i, prevOutScript, txscript.SigHashAll, getKey,
-			getScript, txIn.SignatureScript)
-		if err != nil {
-			return nil, errors.New("Failed to sign transaction")
-		}
-		txIn.SignatureScript = script
+	plan, err := util.NewSpendPlan(authoredTx.Tx, prevOuts, changeScript, w.ScriptToAddress)
+	if err != nil {
+		return nil, err
+	}
+	plan.FeePerByte = w.GetFeePerByte(feeLevel)
+	plan.VSize = EstimateSerializeSize(len(plan.Inputs), authoredTx.Tx.TxOut, false, P2PKH)
+	if authoredTx.ChangeIndex < 0 {
+		requiredFee := txrules.FeeForSerializeSize(ltcutil.Amount(feePerKB), EstimateSerializeSize(len(plan.Inputs), outputs, true, P2PKH))
+		plan.DroppedChange = plan.Fee - int64(requiredFee)
 	}
-	return authoredTx.Tx, nil
+	return plan, nil
 }
 
-func (w *LitecoinWallet) buildSpendAllTx(addr btc.Address, feeLevel wi.FeeLevel) (*wire.MsgTx, error) {
+func (w *LitecoinWallet) buildSpendAllTx(addr btc.Address, feeLevel wi.FeeLevel, optionalOutput *wire.TxOut) (*wire.MsgTx, error) {
+	plan, err := w.planSpendAllTx(addr, feeLevel, optionalOutput)
+	if err != nil {
+		return nil, err
+	}
+	return w.signPlan(plan)
+}
+
+func (w *LitecoinWallet) planSpendAllTx(addr btc.Address, feeLevel wi.FeeLevel, optionalOutput *wire.TxOut) (*util.SpendPlan, error) {
 	tx := wire.NewMsgTx(1)
 
 	height, _ := w.ws.ChainTip()
@@ -145,7 +156,11 @@ func (w *LitecoinWallet) buildSpendAllTx(addr btc.Address, feeLevel wi.FeeLevel)
 	}
 	coinMap := util.GatherCoins(height, utxos, w.ScriptToAddress, w.km.GetKeyForScript)
 
-	totalIn, _, additionalPrevScripts, additionalKeysByAddress := util.LoadAllInputs(tx, coinMap, w.params)
+	totalIn, inVals, additionalPrevScripts, _ := util.LoadAllInputs(tx, coinMap, w.params)
+	prevOuts := make(map[wire.OutPoint]*wire.TxOut)
+	for op, script := range additionalPrevScripts {
+		prevOuts[op] = wire.NewTxOut(inVals[op], script)
+	}
 
 	// outputs
 	script, err := laddr.PayToAddrScript(addr)
@@ -153,9 +168,14 @@ func (w *LitecoinWallet) buildSpendAllTx(addr btc.Address, feeLevel wi.FeeLevel)
 		return nil, err
 	}
 
+	outputs := []*wire.TxOut{wire.NewTxOut(0, script)}
+	if optionalOutput != nil {
+		outputs = append(outputs, optionalOutput)
+	}
+
 	// Get the fee
 	feePerByte := int64(w.GetFeePerByte(feeLevel))
-	estimatedSize := EstimateSerializeSize(1, []*wire.TxOut{wire.NewTxOut(0, script)}, false, P2PKH)
+	estimatedSize := EstimateSerializeSize(1, outputs, false, P2PKH)
 	fee := int64(estimatedSize) * feePerByte
 
 	// Check for dust output
@@ -166,11 +186,38 @@ func (w *LitecoinWallet) buildSpendAllTx(addr btc.Address, feeLevel wi.FeeLevel)
 	// Build the output
 	out := wire.NewTxOut(totalIn-fee, script)
 	tx.TxOut = append(tx.TxOut, out)
+	if optionalOutput != nil {
+		tx.TxOut = append(tx.TxOut, optionalOutput)
+	}
 
 	// BIP 69 sorting
 	txsort.InPlaceSort(tx)
 
+	plan, err := util.NewSpendPlan(tx, prevOuts, nil, w.ScriptToAddress)
+	if err != nil {
+		return nil, err
+	}
+	plan.FeePerByte = uint64(feePerByte)
+	plan.VSize = EstimateSerializeSize(len(tx.TxIn), tx.TxOut, false, P2PKH)
+	return plan, nil
+}
+
+// signPlan signs the transaction of plan. It fails if the wallet can no
+// longer spend the inputs of the plan.
+func (w *LitecoinWallet) signPlan(plan *util.SpendPlan) (*wire.MsgTx, error) {
+	height, _ := w.ws.ChainTip()
+	utxos, err := w.db.Utxos().GetAll()
+	if err != nil {
+		return nil, err
+	}
+	coinMap, err := plan.Coins(util.GatherCoins(height, utxos, w.ScriptToAddress, w.km.GetKeyForScript))
+	if err != nil {
+		return nil, err
+	}
+	_, _, additionalPrevScripts, additionalKeysByAddress := util.LoadAllInputs(wire.NewMsgTx(1), coinMap, w.params)
+
 	// Sign
+	tx := plan.Tx()
 	getKey := txscript.KeyClosure(func(addr btc.Address) (*btcec.PrivateKey, bool, error) {
 		addrStr := addr.EncodeAddress()
 		wif, ok := additionalKeysByAddress[addrStr]
@@ -441,34 +488,33 @@ func (w *LitecoinWallet) sweepAddress(ins []wi.TransactionInput, address *btc.Ad
 	return &txid, nil
 }
 
-func (w *LitecoinWallet) createMultisigSignature(ins []wi.TransactionInput, outs []wi.TransactionOutput, key *hd.ExtendedKey, redeemScript []byte, feePerByte uint64) ([]wi.Signature, error) {
-	var sigs []wi.Signature
+// newMultisigTx returns the unsigned transaction the cosigners of the
+// redeem script sign, with the fee subtracted from the outputs, and the
+// values of the outputs it spends.
+func (w *LitecoinWallet) newMultisigTx(ins []wi.TransactionInput, outs []wi.TransactionOutput, rs *multisig.RedeemScript, feePerByte uint64) (*wire.MsgTx, map[wire.OutPoint]int64, error) {
 	tx := wire.NewMsgTx(1)
+	inVals := make(map[wire.OutPoint]int64)
 	for _, in := range ins {
 		ch, err := chainhash.NewHashFromStr(hex.EncodeToString(in.OutpointHash))
 		if err != nil {
-			return sigs, err
+			return nil, nil, err
 		}
 		outpoint := wire.NewOutPoint(ch, in.OutpointIndex)
 		input := wire.NewTxIn(outpoint, []byte{}, [][]byte{})
 		tx.TxIn = append(tx.TxIn, input)
+		inVals[*outpoint] = in.Value
 	}
 	for _, out := range outs {
 		scriptPubkey, err := laddr.PayToAddrScript(out.Address)
 		if err != nil {
-			return sigs, err
+			return nil, nil, err
 		}
 		output := wire.NewTxOut(out.Value, scriptPubkey)
 		tx.TxOut = append(tx.TxOut, output)
 	}
 
 	// Subtract fee
-	txType := P2SH_2of3_Multisig
-	_, err := spvwallet.LockTimeFromRedeemScript(redeemScript)
-	if err == nil {
-		txType = P2SH_Multisig_Timelock_2Sigs
-	}
-	estimatedSize := EstimateSerializeSize(len(ins), tx.TxOut, false, txType)
+	estimatedSize := EstimateMultisigSerializeSize(len(ins), tx.TxOut, rs)
 	fee := estimatedSize * int(feePerByte)
 	if len(tx.TxOut) > 0 {
 		feePerOutput := fee / len(tx.TxOut)
@@ -479,6 +525,19 @@ func (w *LitecoinWallet) createMultisigSignature(ins []wi.TransactionInput, outs
 
 	// BIP 69 sorting
 	txsort.InPlaceSort(tx)
+	return tx, inVals, nil
+}
+
+func (w *LitecoinWallet) createMultisigSignature(ins []wi.TransactionInput, outs []wi.TransactionOutput, key *hd.ExtendedKey, redeemScript []byte, feePerByte uint64) ([]wi.Signature, error) {
+	var sigs []wi.Signature
+	rs, err := multisig.ParseRedeemScript(redeemScript)
+	if err != nil {
+		return sigs, err
+	}
+	tx, inVals, err := w.newMultisigTx(ins, outs, rs, feePerByte)
+	if err != nil {
+		return sigs, err
+	}
 
 	signingKey, err := key.ECPrivKey()
 	if err != nil {
@@ -486,8 +545,8 @@ func (w *LitecoinWallet) createMultisigSignature(ins []wi.TransactionInput, outs
 	}
 
 	hashes := txscript.NewTxSigHashes(tx)
-	for i := range tx.TxIn {
-		sig, err := txscript.RawTxInWitnessSignature(tx, hashes, i, ins[i].Value, redeemScript, txscript.SigHashAll, signingKey)
+	for i, txIn := range tx.TxIn {
+		sig, err := txscript.RawTxInWitnessSignature(tx, hashes, i, inVals[txIn.PreviousOutPoint], redeemScript, txscript.SigHashAll, signingKey)
 		if err != nil {
 			continue
 		}
@@ -498,47 +557,13 @@ func (w *LitecoinWallet) createMultisigSignature(ins []wi.TransactionInput, outs
 }
 
 func (w *LitecoinWallet) multisign(ins []wi.TransactionInput, outs []wi.TransactionOutput, sigs1 []wi.Signature, sigs2 []wi.Signature, redeemScript []byte, feePerByte uint64, broadcast bool) ([]byte, error) {
-	tx := wire.NewMsgTx(1)
-	for _, in := range ins {
-		ch, err := chainhash.NewHashFromStr(hex.EncodeToString(in.OutpointHash))
-		if err != nil {
-			return nil, err
-		}
-		outpoint := wire.NewOutPoint(ch, in.OutpointIndex)
-		input := wire.NewTxIn(outpoint, []byte{}, [][]byte{})
-		tx.TxIn = append(tx.TxIn, input)
-	}
-	for _, out := range outs {
-		scriptPubkey, err := laddr.PayToAddrScript(out.Address)
-		if err != nil {
-			return nil, err
-		}
-		output := wire.NewTxOut(out.Value, scriptPubkey)
-		tx.TxOut = append(tx.TxOut, output)
-	}
-
-	// Subtract fee
-	txType := P2SH_2of3_Multisig
-	_, err := spvwallet.LockTimeFromRedeemScript(redeemScript)
-	if err == nil {
-		txType = P2SH_Multisig_Timelock_2Sigs
-	}
-	estimatedSize := EstimateSerializeSize(len(ins), tx.TxOut, false, txType)
-	fee := estimatedSize * int(feePerByte)
-	if len(tx.TxOut) > 0 {
-		feePerOutput := fee / len(tx.TxOut)
-		for _, output := range tx.TxOut {
-			output.Value -= int64(feePerOutput)
-		}
+	rs, err := multisig.ParseRedeemScript(redeemScript)
+	if err != nil {
+		return nil, err
 	}
-
-	// BIP 69 sorting
-	txsort.InPlaceSort(tx)
-
-	// Check if time locked
-	var timeLocked bool
-	if redeemScript[0] == txscript.OP_IF {
-		timeLocked = true
+	tx, _, err := w.newMultisigTx(ins, outs, rs, feePerByte)
+	if err != nil {
+		return nil, err
 	}
 
 	for i, input := range tx.TxIn {
@@ -559,7 +584,7 @@ func (w *LitecoinWallet) multisign(ins []wi.TransactionInput, outs []wi.Transact
 
 		witness := wire.TxWitness{[]byte{}, sig1, sig2}
 
-		if timeLocked {
+		if rs.Timelocked {
 			witness = append(witness, []byte{0x01})
 		}
 		witness = append(witness, redeemScript)
@@ -576,6 +601,281 @@ func (w *LitecoinWallet) multisign(ins []wi.TransactionInput, outs []wi.Transact
 	return buf.Bytes(), nil
 }
 
+// multisignWithSignatures signs the transaction with the signatures of any
+// number of cosigners of an m of n multisig. Until the threshold is met the
+// inputs hold partial witnesses and complete is false.
+func (w *LitecoinWallet) multisignWithSignatures(ins []wi.TransactionInput, outs []wi.TransactionOutput, sigs multisig.Signatures, redeemScript []byte, feePerByte uint64, broadcast bool) ([]byte, bool, error) {
+	rs, err := multisig.ParseRedeemScript(redeemScript)
+	if err != nil {
+		return nil, false, err
+	}
+	tx, _, err := w.newMultisigTx(ins, outs, rs, feePerByte)
+	if err != nil {
+		return nil, false, err
+	}
+
+	complete := true
+	for i, input := range tx.TxIn {
+		slots, err := rs.Slots(sigs, i)
+		if err != nil {
+			return nil, false, err
+		}
+		stack, ok := rs.Stack(slots)
+		input.Witness = stack
+		complete = complete && ok
+	}
+	return w.finishMultisig(tx, complete, broadcast)
+}
+
+// mergeMultisig combines the signatures of partially signed transactions
+// spending the outputs of the redeem script.
+func (w *LitecoinWallet) mergeMultisig(redeemScript []byte, txs [][]byte, broadcast bool) ([]byte, bool, error) {
+	rs, err := multisig.ParseRedeemScript(redeemScript)
+	if err != nil {
+		return nil, false, err
+	}
+	var merged *wire.MsgTx
+	var slots [][][]byte
+	var finals []wire.TxWitness
+	for _, raw := range txs {
+		tx := wire.NewMsgTx(1)
+		if err := tx.Deserialize(bytes.NewReader(raw)); err != nil {
+			return nil, false, err
+		}
+		if merged == nil {
+			merged = tx
+			slots = make([][][]byte, len(tx.TxIn))
+			finals = make([]wire.TxWitness, len(tx.TxIn))
+		} else if multisig.UnsignedTxHash(tx) != multisig.UnsignedTxHash(merged) {
+			return nil, false, errors.New("partially signed transactions differ")
+		}
+		for i, in := range tx.TxIn {
+			s, complete, err := rs.ParseStack(in.Witness)
+			if err != nil {
+				return nil, false, fmt.Errorf("input %d: %s", i, err)
+			}
+			if complete {
+				finals[i] = in.Witness
+			} else if slots[i] == nil {
+				slots[i] = s
+			} else if slots[i], err = multisig.MergeSlots(slots[i], s); err != nil {
+				return nil, false, err
+			}
+		}
+	}
+	if merged == nil {
+		return nil, false, errors.New("no transactions to merge")
+	}
+
+	complete := true
+	for i, input := range merged.TxIn {
+		if finals[i] != nil {
+			input.Witness = finals[i]
+			continue
+		}
+		stack, ok := rs.Stack(slots[i])
+		input.Witness = stack
+		complete = complete && ok
+	}
+	return w.finishMultisig(merged, complete, broadcast)
+}
+
+// finishMultisig serializes a multisig transaction, broadcasting it if
+// requested once every input is fully signed.
+func (w *LitecoinWallet) finishMultisig(tx *wire.MsgTx, complete, broadcast bool) ([]byte, bool, error) {
+	if broadcast && complete {
+		if err := w.Broadcast(tx); err != nil {
+			return nil, false, err
+		}
+	}
+	var buf bytes.Buffer
+	tx.BtcEncode(&buf, wire.ProtocolVersion, wire.WitnessEncoding)
+	return buf.Bytes(), complete, nil
+}
+
+// releaseAfterTimeout spends the outputs of an escrow through the timeout
+// branch of its redeem script, signed by the timeout key, once its relative
+// lock time has passed.
+func (w *LitecoinWallet) releaseAfterTimeout(ins []wi.TransactionInput, address btc.Address, timeoutKey *hd.ExtendedKey, redeemScript []byte, feeLevel wi.FeeLevel) (*chainhash.Hash, error) {
+	rs, err := multisig.ParseRedeemScript(redeemScript)
+	if err != nil {
+		return nil, err
+	}
+	blocks, err := rs.TimeoutBlocks()
+	if err != nil {
+		return nil, err
+	}
+	privKey, err := timeoutKey.ECPrivKey()
+	if err != nil {
+		return nil, fmt.Errorf("retrieving private key: %s", err.Error())
+	}
+	if !bytes.Equal(privKey.PubKey().SerializeCompressed(), rs.TimeoutKey) {
+		return nil, errors.New("key is not the timeout key of the redeem script")
+	}
+	if address == nil {
+		address = w.CurrentAddress(wi.INTERNAL)
+	}
+	script, err := w.AddressToScript(address)
+	if err != nil {
+		return nil, err
+	}
+
+	// The relative lock time is only enforced on version 2 transactions
+	tx := wire.NewMsgTx(2)
+	var val int64
+	var outpoints []wire.OutPoint
+	inVals := make(map[wire.OutPoint]int64)
+	for _, in := range ins {
+		ch, err := chainhash.NewHashFromStr(hex.EncodeToString(in.OutpointHash))
+		if err != nil {
+			return nil, err
+		}
+		outpoint := wire.NewOutPoint(ch, in.OutpointIndex)
+		input := wire.NewTxIn(outpoint, []byte{}, [][]byte{})
+		input.Sequence = rs.Timeout
+		tx.TxIn = append(tx.TxIn, input)
+		outpoints = append(outpoints, *outpoint)
+		inVals[*outpoint] = in.Value
+		val += in.Value
+	}
+	if err := w.ws.CheckEscrowTimeout(outpoints, blocks); err != nil {
+		return nil, err
+	}
+
+	out := wire.NewTxOut(val, script)
+	tx.TxOut = append(tx.TxOut, out)
+	fee := int64(EstimateTimeoutSerializeSize(len(ins), tx.TxOut, rs)) * int64(w.GetFeePerByte(feeLevel))
+	out.Value = val - fee
+	if w.IsDust(out.Value) {
+		return nil, wi.ErrorDustAmount
+	}
+
+	// BIP 69 sorting
+	txsort.InPlaceSort(tx)
+
+	hashes := txscript.NewTxSigHashes(tx)
+	for i, txIn := range tx.TxIn {
+		sig, err := txscript.RawTxInWitnessSignature(tx, hashes, i, inVals[txIn.PreviousOutPoint], redeemScript, txscript.SigHashAll, privKey)
+		if err != nil {
+			return nil, err
+		}
+		txIn.Witness = rs.TimeoutStack(sig)
+	}
+
+	// broadcast
+	if err := w.Broadcast(tx); err != nil {
+		return nil, err
+	}
+	txid := tx.TxHash()
+	return &txid, nil
+}
+
+// generateHTLCScript returns the P2WSH address and script of a hash time
+// locked contract.
+func (w *LitecoinWallet) generateHTLCScript(secretHash, recipientKey, refundKey []byte, lockTime uint32) (btc.Address, []byte, error) {
+	contract, err := htlc.NewContract(secretHash, recipientKey, refundKey, lockTime)
+	if err != nil {
+		return nil, nil, err
+	}
+	witnessProgram := sha256.Sum256(contract.Script)
+	addr, err := laddr.NewAddressWitnessScriptHash(witnessProgram[:], w.params)
+	if err != nil {
+		return nil, nil, err
+	}
+	return addr, contract.Script, nil
+}
+
+// spendHTLC spends the outputs of a hash time locked contract to address. The
+// contract is redeemed by the recipient key if a secret is given and refunded
+// by the refund key otherwise.
+func (w *LitecoinWallet) spendHTLC(ins []wi.TransactionInput, address btc.Address, key *hd.ExtendedKey, script []byte, secret []byte, feeLevel wi.FeeLevel) (*chainhash.Hash, error) {
+	contract, err := htlc.ParseContract(script)
+	if err != nil {
+		return nil, err
+	}
+	privKey, err := key.ECPrivKey()
+	if err != nil {
+		return nil, fmt.Errorf("retrieving private key: %s", err.Error())
+	}
+	pubKey := privKey.PubKey().SerializeCompressed()
+	redeem := secret != nil
+	if redeem {
+		if !contract.Matches(secret) {
+			return nil, htlc.ErrSecretMismatch
+		}
+		if !bytes.Equal(pubKey, contract.RecipientKey) {
+			return nil, errors.New("key is not the recipient key of the contract")
+		}
+	} else {
+		if !bytes.Equal(pubKey, contract.RefundKey) {
+			return nil, errors.New("key is not the refund key of the contract")
+		}
+		if tip, _ := w.ChainTip(); !contract.Refundable(tip) {
+			return nil, htlc.ErrLockTimeNotReached
+		}
+	}
+	if address == nil {
+		address = w.CurrentAddress(wi.INTERNAL)
+	}
+	outScript, err := w.AddressToScript(address)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := wire.NewMsgTx(wire.TxVersion)
+	var val int64
+	inVals := make(map[wire.OutPoint]int64)
+	for _, in := range ins {
+		ch, err := chainhash.NewHashFromStr(hex.EncodeToString(in.OutpointHash))
+		if err != nil {
+			return nil, err
+		}
+		outpoint := wire.NewOutPoint(ch, in.OutpointIndex)
+		input := wire.NewTxIn(outpoint, []byte{}, [][]byte{})
+		if !redeem {
+			// The lock time is only enforced on inputs which are not final
+			input.Sequence = wire.MaxTxInSequenceNum - 1
+		}
+		tx.TxIn = append(tx.TxIn, input)
+		inVals[*outpoint] = in.Value
+		val += in.Value
+	}
+	if !redeem {
+		tx.LockTime = contract.LockTime
+	}
+
+	out := wire.NewTxOut(val, outScript)
+	tx.TxOut = append(tx.TxOut, out)
+	fee := int64(EstimateHTLCSerializeSize(len(ins), tx.TxOut, redeem)) * int64(w.GetFeePerByte(feeLevel))
+	out.Value = val - fee
+	if w.IsDust(out.Value) {
+		return nil, wi.ErrorDustAmount
+	}
+
+	// BIP 69 sorting
+	txsort.InPlaceSort(tx)
+
+	hashes := txscript.NewTxSigHashes(tx)
+	for i, txIn := range tx.TxIn {
+		sig, err := txscript.RawTxInWitnessSignature(tx, hashes, i, inVals[txIn.PreviousOutPoint], script, txscript.SigHashAll, privKey)
+		if err != nil {
+			return nil, err
+		}
+		if redeem {
+			txIn.Witness = contract.RedeemStack(sig, secret)
+		} else {
+			txIn.Witness = contract.RefundStack(sig)
+		}
+	}
+
+	// broadcast
+	if err := w.Broadcast(tx); err != nil {
+		return nil, err
+	}
+	txid := tx.TxHash()
+	return &txid, nil
+}
+
 func (w *LitecoinWallet) generateMultisigScript(keys []hd.ExtendedKey, threshold int, timeout time.Duration, timeoutKey *hd.ExtendedKey) (addr btc.Address, redeemScript []byte, err error) {
 	if uint32(timeout.Hours()) > 0 && timeoutKey == nil {
 		return nil, nil, errors.New("Timeout key must be non nil when using an escrow timeout")
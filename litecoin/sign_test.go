@@ -8,9 +8,11 @@ import (
 
 	"github.com/muecoin/multiwallet/cache"
 	"github.com/muecoin/multiwallet/datastore"
+	"github.com/muecoin/multiwallet/htlc"
 	"github.com/muecoin/multiwallet/keys"
 	laddr "github.com/muecoin/multiwallet/litecoin/address"
 	"github.com/muecoin/multiwallet/model/mock"
+	"github.com/muecoin/multiwallet/multisig"
 	"github.com/muecoin/multiwallet/service"
 	"github.com/muecoin/multiwallet/util"
 	"github.com/OpenBazaar/wallet-interface"
@@ -117,6 +119,28 @@ func TestLitecoinWallet_buildTx(t *testing.T) {
 		t.Error("Built tx does not contain a valid change output")
 	}
 
+	// Data output
+	dataOut, err := util.NullDataOutput([]byte("order 1a3w"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err = w.buildTx(1500000, addr, wallet.NORMAL, dataOut)
+	if err != nil {
+		t.Error(err)
+	}
+	if !containsOutput(tx, addr) {
+		t.Error("Built tx does not contain the requested output")
+	}
+	hasData := false
+	for _, out := range tx.TxOut {
+		if bytes.Equal(out.PkScript, dataOut.PkScript) && out.Value == 0 {
+			hasData = true
+		}
+	}
+	if !hasData {
+		t.Error("Built tx does not contain the data output")
+	}
+
 	// Insuffient funds
 	_, err = w.buildTx(1000000000, addr, wallet.NORMAL, nil)
 	if err != wallet.ErrorInsuffientFunds {
@@ -145,7 +169,7 @@ func TestLitecoinWallet_buildSpendAllTx(t *testing.T) {
 	}
 
 	// Test build spendAll tx
-	tx, err := w.buildSpendAllTx(addr, wallet.NORMAL)
+	tx, err := w.buildSpendAllTx(addr, wallet.NORMAL, nil)
 	if err != nil {
 		t.Error(err)
 	}
@@ -505,6 +529,278 @@ func TestLitecoinWallet_Multisign(t *testing.T) {
 	}
 }
 
+func TestLitecoinWallet_MultisignWithSignatures(t *testing.T) {
+	w, err := newMockWallet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var keys []hdkeychain.ExtendedKey
+	for i := 0; i < 5; i++ {
+		key, err := w.km.GetFreshKey(wallet.INTERNAL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, *key)
+	}
+	addr, redeemScript, err := w.generateMultisigScript(keys, 3, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ins, outs, _, err := buildTxData(w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ins[0].Value = 40000
+	ins[1].Value = 60000
+
+	// Three of the five cosigners sign, out of key order
+	var partials [][]byte
+	sigs := make(multisig.Signatures)
+	for _, i := range []int{4, 0, 2} {
+		keySigs, err := w.CreateMultisigSignature(ins, outs, &keys[i], redeemScript, 50)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pubKey, err := keys[i].ECPubKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		cosigner := make(multisig.Signatures)
+		cosigner.Add(pubKey.SerializeCompressed(), keySigs)
+		sigs.Merge(cosigner)
+
+		partial, complete, err := w.MultisignWithSignatures(ins, outs, cosigner, redeemScript, 50, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if complete {
+			t.Error("Transaction with a single signature reported as complete")
+		}
+		partials = append(partials, partial)
+	}
+
+	txBytes, complete, err := w.MergeMultisig(redeemScript, partials[:2], false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if complete {
+		t.Error("Transaction with two signatures reported as complete")
+	}
+	txBytes, complete, err = w.MergeMultisig(redeemScript, [][]byte{txBytes, partials[2]}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !complete {
+		t.Error("Transaction with three signatures reported as partial")
+	}
+	direct, complete, err := w.MultisignWithSignatures(ins, outs, sigs, redeemScript, 50, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !complete || !bytes.Equal(direct, txBytes) {
+		t.Error("Merged transaction differs from the one signed with every signature")
+	}
+
+	tx := wire.NewMsgTx(1)
+	if err := tx.Deserialize(bytes.NewReader(txBytes)); err != nil {
+		t.Fatal(err)
+	}
+	pkScript, err := w.AddressToScript(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inVals := map[wire.OutPoint]int64{}
+	for _, in := range ins {
+		ch, _ := chainhash.NewHashFromStr(hex.EncodeToString(in.OutpointHash))
+		inVals[*wire.NewOutPoint(ch, in.OutpointIndex)] = in.Value
+	}
+	hashes := txscript.NewTxSigHashes(tx)
+	for i, in := range tx.TxIn {
+		if len(in.Witness) != 5 {
+			t.Errorf("Input %d: expected 3 signatures in the witness but had %d elements", i, len(in.Witness))
+		}
+		vm, err := txscript.NewEngine(pkScript, tx, i, txscript.StandardVerifyFlags, nil, hashes, inVals[in.PreviousOutPoint])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := vm.Execute(); err != nil {
+			t.Errorf("Input %d: %s", i, err)
+		}
+	}
+
+}
+
+func TestLitecoinWallet_ReleaseAfterTimeout(t *testing.T) {
+	w, err := newMockWallet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.ws.Start()
+	waitForTxnSync(t, w.db.Txns())
+	time.Sleep(time.Second / 2)
+
+	var keys []hdkeychain.ExtendedKey
+	for i := 0; i < 4; i++ {
+		key, err := w.km.GetFreshKey(wallet.INTERNAL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, *key)
+	}
+	timeoutKey := &keys[3]
+	addr, redeemScript, err := w.generateMultisigScript(keys[:3], 2, time.Hour, timeoutKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkScript, err := w.AddressToScript(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tip, _ := w.ChainTip()
+	op := wire.NewOutPoint(&chainhash.Hash{0x0e}, 1)
+	utxo := wallet.Utxo{Op: *op, Value: 100000, ScriptPubkey: pkScript, AtHeight: int32(tip) - 4, WatchOnly: true}
+	if err := w.db.Utxos().Put(utxo); err != nil {
+		t.Fatal(err)
+	}
+	h, _ := hex.DecodeString(op.Hash.String())
+	ins := []wallet.TransactionInput{{OutpointHash: h, OutpointIndex: op.Index, Value: utxo.Value, LinkedAddress: addr}}
+
+	if _, err := w.ReleaseAfterTimeout(ins, nil, timeoutKey, redeemScript, wallet.NORMAL); err != multisig.ErrTimeoutNotMatured {
+		t.Errorf("Expected ErrTimeoutNotMatured but had %v", err)
+	}
+	utxo.AtHeight--
+	if err := w.db.Utxos().Put(utxo); err != nil {
+		t.Fatal(err)
+	}
+	txid, err := w.ReleaseAfterTimeout(ins, nil, timeoutKey, redeemScript, wallet.NORMAL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txn, err := w.db.Txns().Get(*txid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx := wire.NewMsgTx(1)
+	if err := tx.Deserialize(bytes.NewReader(txn.Bytes)); err != nil {
+		t.Fatal(err)
+	}
+	vm, err := txscript.NewEngine(pkScript, tx, 0, txscript.StandardVerifyFlags, nil, txscript.NewTxSigHashes(tx), utxo.Value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := vm.Execute(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestLitecoinWallet_HTLC(t *testing.T) {
+	w, err := newMockWallet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.ws.Start()
+	waitForTxnSync(t, w.db.Txns())
+	time.Sleep(time.Second / 2)
+
+	recipientKey, err := w.km.GetFreshKey(wallet.INTERNAL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	refundKey, err := w.km.GetFreshKey(wallet.INTERNAL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recipientPub, err := recipientKey.ECPubKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	refundPub, err := refundKey.ECPubKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret, secretHash, err := htlc.NewSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spend := func(lockTime uint32, txid func(ins []wallet.TransactionInput, contract []byte) (*chainhash.Hash, error)) (*wire.MsgTx, []byte, error) {
+		addr, contract, err := w.GenerateHTLCScript(secretHash, recipientPub.SerializeCompressed(), refundPub.SerializeCompressed(), lockTime)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := addr.(*laddr.AddressWitnessScriptHash); !ok {
+			t.Fatalf("Expected a P2WSH contract address but had %T", addr)
+		}
+		pkScript, err := w.AddressToScript(addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		h, _ := hex.DecodeString(chainhash.Hash{0x0f}.String())
+		ins := []wallet.TransactionInput{{OutpointHash: h, OutpointIndex: lockTime, Value: 100000, LinkedAddress: addr}}
+		hash, err := txid(ins, contract)
+		if err != nil {
+			return nil, nil, err
+		}
+		txn, err := w.db.Txns().Get(*hash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tx := wire.NewMsgTx(1)
+		if err := tx.Deserialize(bytes.NewReader(txn.Bytes)); err != nil {
+			t.Fatal(err)
+		}
+		return tx, pkScript, nil
+	}
+	verify := func(tx *wire.MsgTx, pkScript []byte) {
+		vm, err := txscript.NewEngine(pkScript, tx, 0, txscript.StandardVerifyFlags, nil, txscript.NewTxSigHashes(tx), 100000)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := vm.Execute(); err != nil {
+			t.Error(err)
+		}
+	}
+
+	tip, _ := w.ChainTip()
+	tx, pkScript, err := spend(tip+10, func(ins []wallet.TransactionInput, contract []byte) (*chainhash.Hash, error) {
+		if _, err := w.RedeemHTLC(ins, nil, recipientKey, contract, secretHash, wallet.NORMAL); err != htlc.ErrSecretMismatch {
+			t.Errorf("Expected ErrSecretMismatch but had %v", err)
+		}
+		if _, err := w.RedeemHTLC(ins, nil, refundKey, contract, secret, wallet.NORMAL); err == nil {
+			t.Error("Redeemed the contract with the refund key")
+		}
+		return w.RedeemHTLC(ins, nil, recipientKey, contract, secret, wallet.NORMAL)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	verify(tx, pkScript)
+	contract, err := htlc.ParseContract(tx.TxIn[0].Witness[3])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if revealed, ok := contract.ExtractSecret(tx.TxIn[0].Witness); !ok || !bytes.Equal(revealed, secret) {
+		t.Error("Failed to extract the secret from the redeem witness")
+	}
+
+	if _, _, err := spend(tip+1, func(ins []wallet.TransactionInput, contract []byte) (*chainhash.Hash, error) {
+		return w.RefundHTLC(ins, nil, refundKey, contract, wallet.NORMAL)
+	}); err != htlc.ErrLockTimeNotReached {
+		t.Errorf("Expected ErrLockTimeNotReached but had %v", err)
+	}
+	tx, pkScript, err = spend(tip, func(ins []wallet.TransactionInput, contract []byte) (*chainhash.Hash, error) {
+		return w.RefundHTLC(ins, nil, refundKey, contract, wallet.NORMAL)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx.LockTime != tip || tx.TxIn[0].Sequence == wire.MaxTxInSequenceNum {
+		t.Errorf("Expected a refund locked at %d but had lock time %d and sequence %d", tip, tx.LockTime, tx.TxIn[0].Sequence)
+	}
+	verify(tx, pkScript)
+}
+
 func TestLitecoinWallet_bumpFee(t *testing.T) {
 	w, err := newMockWallet()
 	if err != nil {
@@ -8,6 +8,9 @@ package litecoin
 
 import (
 	"github.com/btcsuite/btcd/wire"
+
+	"github.com/muecoin/multiwallet/htlc"
+	"github.com/muecoin/multiwallet/multisig"
 )
 
 // Worst case script and input/output size estimates.
@@ -240,6 +243,73 @@ func EstimateSerializeSize(inputCount int, txOuts []*wire.TxOut, addChangeOutput
 		changeSize
 }
 
+// RedeemMultisigInputSize returns the worst case serialize size of a
+// transaction input redeeming an m of n P2WSH multisig output through the
+// multisig branch.
+func RedeemMultisigInputSize(m, n int, timelocked bool) int {
+	return 32 + 4 + 1 + 4 + (multisig.WitnessSize(m, n, timelocked)+3)/4
+}
+
+// EstimateMultisigSerializeSize returns a worst case serialize size estimate
+// for a transaction spending inputCount outputs of the multisig redeem script
+// to txOuts. Multisigs of up to 2 of 3 keys use the 2 of 3 estimate they have
+// always been signed with, so cosigners running older versions subtract the
+// same fee.
+func EstimateMultisigSerializeSize(inputCount int, txOuts []*wire.TxOut, rs *multisig.RedeemScript) int {
+	if rs.Threshold <= 2 && len(rs.PubKeys) <= 3 {
+		inputType := P2SH_2of3_Multisig
+		if rs.Timelocked {
+			inputType = P2SH_Multisig_Timelock_2Sigs
+		}
+		return EstimateSerializeSize(inputCount, txOuts, false, inputType)
+	}
+
+	// 10 additional bytes are for version, locktime, and segwit flags
+	return 10 + wire.VarIntSerializeSize(uint64(inputCount)) +
+		wire.VarIntSerializeSize(uint64(len(txOuts))) +
+		inputCount*RedeemMultisigInputSize(rs.Threshold, len(rs.PubKeys), rs.Timelocked) +
+		SumOutputSerializeSizes(txOuts)
+}
+
+// RedeemTimeoutInputSize returns the worst case serialize size of a
+// transaction input spending the timeout branch of an m of n P2WSH escrow.
+func RedeemTimeoutInputSize(m, n int) int {
+	return 32 + 4 + 1 + 4 + (multisig.TimeoutWitnessSize(m, n)+3)/4
+}
+
+// EstimateTimeoutSerializeSize returns a worst case serialize size estimate
+// for a transaction spending inputCount outputs of the escrow redeem script
+// through its timeout branch to txOuts.
+func EstimateTimeoutSerializeSize(inputCount int, txOuts []*wire.TxOut, rs *multisig.RedeemScript) int {
+	// 10 additional bytes are for version, locktime, and segwit flags
+	return 10 + wire.VarIntSerializeSize(uint64(inputCount)) +
+		wire.VarIntSerializeSize(uint64(len(txOuts))) +
+		inputCount*RedeemTimeoutInputSize(rs.Threshold, len(rs.PubKeys)) +
+		SumOutputSerializeSizes(txOuts)
+}
+
+// RedeemHTLCInputSize returns the worst case serialize size of a transaction
+// input spending a P2WSH hash time locked contract, either redeeming it with
+// the secret or refunding it.
+func RedeemHTLCInputSize(redeem bool) int {
+	witnessSize := htlc.RefundWitnessSize()
+	if redeem {
+		witnessSize = htlc.RedeemWitnessSize()
+	}
+	return 32 + 4 + 1 + 4 + (witnessSize+3)/4
+}
+
+// EstimateHTLCSerializeSize returns a worst case serialize size estimate for
+// a transaction spending inputCount outputs of a hash time locked contract to
+// txOuts.
+func EstimateHTLCSerializeSize(inputCount int, txOuts []*wire.TxOut, redeem bool) int {
+	// 10 additional bytes are for version, locktime, and segwit flags
+	return 10 + wire.VarIntSerializeSize(uint64(inputCount)) +
+		wire.VarIntSerializeSize(uint64(len(txOuts))) +
+		inputCount*RedeemHTLCInputSize(redeem) +
+		SumOutputSerializeSizes(txOuts)
+}
+
 // SumOutputSerializeSizes sums up the serialized size of the supplied outputs.
 func SumOutputSerializeSizes(outputs []*wire.TxOut) (serializeSize int) {
 	for _, txOut := range outputs {
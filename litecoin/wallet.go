@@ -3,6 +3,7 @@ package litecoin
 import (
 	"bytes"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"github.com/ltcsuite/ltcutil"
 	"io"
@@ -11,9 +12,13 @@ import (
 	"github.com/muecoin/multiwallet/cache"
 	"github.com/muecoin/multiwallet/client"
 	"github.com/muecoin/multiwallet/config"
+	"github.com/muecoin/multiwallet/htlc"
 	"github.com/muecoin/multiwallet/keys"
 	laddr "github.com/muecoin/multiwallet/litecoin/address"
 	"github.com/muecoin/multiwallet/model"
+	"github.com/muecoin/multiwallet/multisig"
+	"github.com/muecoin/multiwallet/rates"
+	"github.com/muecoin/multiwallet/registry"
 	"github.com/muecoin/multiwallet/service"
 	"github.com/muecoin/multiwallet/util"
 	wi "github.com/OpenBazaar/wallet-interface"
@@ -41,7 +46,43 @@ type LitecoinWallet struct {
 	exchangeRates wi.ExchangeRates
 }
 
+func init() {
+	registry.Register(registry.Coin{
+		Name:                "Litecoin",
+		CoinType:            util.ExtendCoinType(wi.Litecoin),
+		TestnetCoinType:     util.ExtendCoinType(wi.TestnetLitecoin),
+		CurrencyCode:        "LTC",
+		TestnetCurrencyCode: "TLTC",
+		NewWallet:           newWallet,
+		PriceProviders: []string{
+			"kraken+https://api.kraken.com/0/public/Ticker?pair=LTCXBT",
+			"bitfinex+https://api.bitfinex.com/v1/pubticker/ltcbtc",
+			"coingecko+https://api.coingecko.com/api/v3/coins/litecoin?tickers=false&community_data=false&developer_data=false&sparkline=false",
+		},
+		BlockInterval:   150 * time.Second,
+		DecodeAddress:   decodeAddress,
+		ScriptToAddress: laddr.ExtractPkScriptAddrs,
+		AddressToScript: laddr.PayToAddrScript,
+	})
+}
+
+func newWallet(cfg config.CoinConfig, mnemonic string, params *chaincfg.Params, proxy proxy.Dialer, cache cache.Cacher, disableExchangeRates bool) (wi.Wallet, error) {
+	w, err := NewLitecoinWallet(cfg, mnemonic, params, proxy, cache, disableExchangeRates)
+	if err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// decodeAddress is laddr.DecodeAddress returning a btcutil.Address
+func decodeAddress(addr string, params *chaincfg.Params) (btcutil.Address, error) {
+	return laddr.DecodeAddress(addr, params)
+}
+
 func NewLitecoinWallet(cfg config.CoinConfig, mnemonic string, params *chaincfg.Params, proxy proxy.Dialer, cache cache.Cacher, disableExchangeRates bool) (*LitecoinWallet, error) {
+	if err := util.RequireSizeFeeModel(cfg.FeeModel); err != nil {
+		return nil, err
+	}
 	seed := bip39.NewSeed(mnemonic, "")
 
 	mPrivKey, err := hd.NewMaster(seed, params)
@@ -57,7 +98,7 @@ func NewLitecoinWallet(cfg config.CoinConfig, mnemonic string, params *chaincfg.
 		return nil, err
 	}
 
-	c, err := client.NewClientPool(cfg.ClientAPIs, proxy)
+	c, err := client.NewClientPoolWithOptions(cfg.ClientAPIs, cfg.Options, proxy)
 	if err != nil {
 		return nil, err
 	}
@@ -68,7 +109,13 @@ func NewLitecoinWallet(cfg config.CoinConfig, mnemonic string, params *chaincfg.
 	}
 	var er wi.ExchangeRates
 	if !disableExchangeRates {
-		er = NewLitecoinPriceFetcher(proxy)
+		fetcher, err := rates.NewPriceFetcher(cfg.CoinType, cfg.PriceAPIs, proxy)
+		if err != nil {
+			return nil, err
+		}
+		go fetcher.Run()
+		er = fetcher
+		wm.SetExchangeRates(er)
 	}
 
 	fp := util.NewFeeDefaultProvider(cfg.MaxFee, cfg.HighFee, cfg.MediumFee, cfg.LowFee)
@@ -210,6 +257,50 @@ func (w *LitecoinWallet) GetTransaction(txid chainhash.Hash) (wi.Txn, error) {
 	return txn, err
 }
 
+// TransactionData returns the payloads of the OP_RETURN outputs of a wallet
+// transaction.
+func (w *LitecoinWallet) TransactionData(txid chainhash.Hash) ([][]byte, error) {
+	return w.ws.NullData(txid)
+}
+
+// TransactionMetadata returns the reference ID, memo, labels and counterparty
+// recorded for a wallet transaction.
+func (w *LitecoinWallet) TransactionMetadata(txid chainhash.Hash) (service.TxMetadata, bool) {
+	return w.ws.TxMetadata(txid.String())
+}
+
+// SetTransactionMetadata replaces the metadata recorded for a transaction
+func (w *LitecoinWallet) SetTransactionMetadata(md service.TxMetadata) error {
+	return w.ws.SetTxMetadata(md)
+}
+
+// SetAddressLabel labels incoming payments to addr with label
+func (w *LitecoinWallet) SetAddressLabel(addr btcutil.Address, label string) error {
+	return w.ws.SetAddressLabel(addr, label)
+}
+
+// TransactionByReference returns the transaction spent with referenceID
+func (w *LitecoinWallet) TransactionByReference(referenceID string) (wi.Txn, error) {
+	return w.ws.TransactionByReference(referenceID)
+}
+
+// TransactionsWithLabel returns the transactions labeled with label
+func (w *LitecoinWallet) TransactionsWithLabel(label string) ([]wi.Txn, error) {
+	return w.ws.TransactionsWithLabel(label)
+}
+
+// TransactionPage returns a page of the transactions selected by filter, most
+// recent first, and the cursor of the next page.
+func (w *LitecoinWallet) TransactionPage(filter service.TxFilter) ([]wi.Txn, string, error) {
+	return w.ws.TransactionPage(filter)
+}
+
+// ExportTransactions returns the transactions of the wallet from the oldest
+// with their fee, the running balance and their value in currency.
+func (w *LitecoinWallet) ExportTransactions(currency string) ([]service.ExportRow, error) {
+	return w.ws.ExportTransactions(currency)
+}
+
 func (w *LitecoinWallet) ChainTip() (uint32, chainhash.Hash) {
 	return w.ws.ChainTip()
 }
@@ -219,29 +310,125 @@ func (w *LitecoinWallet) GetFeePerByte(feeLevel wi.FeeLevel) uint64 {
 }
 
 func (w *LitecoinWallet) Spend(amount int64, addr btcutil.Address, feeLevel wi.FeeLevel, referenceID string, spendAll bool) (*chainhash.Hash, error) {
-	var (
-		tx  *wire.MsgTx
-		err error
-	)
-	if spendAll {
-		tx, err = w.buildSpendAllTx(addr, feeLevel)
+	return w.SpendWithData(amount, addr, feeLevel, referenceID, nil, spendAll)
+}
+
+// SpendWithData spends like Spend and attaches data of up to 80 bytes to the
+// transaction in an OP_RETURN output.
+func (w *LitecoinWallet) SpendWithData(amount int64, addr btcutil.Address, feeLevel wi.FeeLevel, referenceID string, data []byte, spendAll bool) (*chainhash.Hash, error) {
+	tx, err := w.buildSpendTx(amount, addr, feeLevel, data, spendAll)
+	if err != nil {
+		return nil, err
+	}
+	// Broadcast
+	if err := w.Broadcast(tx); err != nil {
+		return nil, err
+	}
+	ch := tx.TxHash()
+	w.ws.RecordSpend(ch.String(), referenceID, addr)
+	return &ch, nil
+}
+
+// buildSpendTx builds and signs the transaction of SpendWithData
+func (w *LitecoinWallet) buildSpendTx(amount int64, addr btcutil.Address, feeLevel wi.FeeLevel, data []byte, spendAll bool) (*wire.MsgTx, error) {
+	var dataOutput *wire.TxOut
+	if len(data) > 0 {
+		var err error
+		dataOutput, err = util.NullDataOutput(data)
 		if err != nil {
 			return nil, err
 		}
-	} else {
-		tx, err = w.buildTx(amount, addr, feeLevel, nil)
+	}
+	if spendAll {
+		return w.buildSpendAllTx(addr, feeLevel, dataOutput)
+	}
+	return w.buildTx(amount, addr, feeLevel, dataOutput)
+}
+
+// SpendWithRequestID spends like SpendWithData once per request ID. Retrying
+// a request ID returns the txid of its transaction, broadcasting the same
+// transaction again if its broadcast failed.
+func (w *LitecoinWallet) SpendWithRequestID(requestID string, amount int64, addr btcutil.Address, feeLevel wi.FeeLevel, referenceID string, data []byte, spendAll bool) (*chainhash.Hash, error) {
+	return w.spendOnce(requestID, referenceID, []btcutil.Address{addr}, func() (*wire.MsgTx, error) {
+		return w.buildSpendTx(amount, addr, feeLevel, data, spendAll)
+	})
+}
+
+// SpendBatch pays every payment in a single transaction. With a request ID
+// the payments are sent once like SpendWithRequestID.
+func (w *LitecoinWallet) SpendBatch(requestID string, payments []wi.TransactionOutput, feeLevel wi.FeeLevel, referenceID string) (*chainhash.Hash, error) {
+	recipients := make([]btcutil.Address, 0, len(payments))
+	for _, payment := range payments {
+		recipients = append(recipients, payment.Address)
+	}
+	return w.spendOnce(requestID, referenceID, recipients, func() (*wire.MsgTx, error) {
+		return w.buildBatchTx(payments, feeLevel, nil)
+	})
+}
+
+// AbandonSpendRequest gives up the transaction of a request ID whose
+// broadcast failed, so that it is never broadcast and its inputs are left to
+// other spends.
+func (w *LitecoinWallet) AbandonSpendRequest(requestID string) error {
+	return w.ws.AbandonSpendRequest(requestID)
+}
+
+// ConvertFiatOnce converts a fiat amount at the current exchange rate once per
+// request ID, returning the first conversion again for later calls with the
+// same request ID.
+func (w *LitecoinWallet) ConvertFiatOnce(requestID string, fiat rates.FiatAmount) (rates.Conversion, error) {
+	return w.ws.ConvertOnce(requestID, func() (rates.Conversion, error) {
+		return rates.Convert(w.ExchangeRates(), fiat)
+	})
+}
+
+// PlanSpend returns the transaction SpendWithData would build without signing
+// or broadcasting it.
+func (w *LitecoinWallet) PlanSpend(amount int64, addr btcutil.Address, feeLevel wi.FeeLevel, data []byte, spendAll bool) (*util.SpendPlan, error) {
+	var dataOutput *wire.TxOut
+	if len(data) > 0 {
+		var err error
+		dataOutput, err = util.NullDataOutput(data)
 		if err != nil {
 			return nil, err
 		}
 	}
-
-	// Broadcast
-	if err := w.Broadcast(tx); err != nil {
-		return nil, err
+	if spendAll {
+		return w.planSpendAllTx(addr, feeLevel, dataOutput)
 	}
+	return w.planBatchTx([]wi.TransactionOutput{{Address: addr, Value: amount}}, feeLevel, dataOutput)
+}
 
-	ch := tx.TxHash()
-	return &ch, nil
+// ExecuteSpendPlan signs and broadcasts the transaction of a plan returned by
+// PlanSpend as is. With a request ID the plan is executed once like
+// SpendWithRequestID.
+func (w *LitecoinWallet) ExecuteSpendPlan(requestID string, plan *util.SpendPlan, referenceID string) (*chainhash.Hash, error) {
+	return w.spendOnce(requestID, referenceID, plan.Recipients(), func() (*wire.MsgTx, error) {
+		return w.signPlan(plan)
+	})
+}
+
+// spendOnce signs the transaction returned by build and broadcasts it once per
+// request ID like service.WalletService.SpendOnce
+func (w *LitecoinWallet) spendOnce(requestID, referenceID string, recipients []btcutil.Address, build func() (*wire.MsgTx, error)) (*chainhash.Hash, error) {
+	return w.ws.SpendOnce(requestID, func() (*service.SignedSpend, error) {
+		tx, err := build()
+		if err != nil {
+			return nil, err
+		}
+		var buf bytes.Buffer
+		if err := tx.BtcEncode(&buf, wire.ProtocolVersion, wire.WitnessEncoding); err != nil {
+			return nil, err
+		}
+		return service.NewSignedSpend(buf.Bytes(), tx.TxHash(), tx), nil
+	}, func(raw []byte) (*chainhash.Hash, error) {
+		txid, err := w.BroadcastRawTx(raw)
+		if err != nil {
+			return nil, err
+		}
+		w.ws.RecordSpend(txid.String(), referenceID, recipients...)
+		return txid, nil
+	})
 }
 
 func (w *LitecoinWallet) BumpFee(txid chainhash.Hash) (*chainhash.Hash, error) {
@@ -276,6 +463,36 @@ func (w *LitecoinWallet) Multisign(ins []wi.TransactionInput, outs []wi.Transact
 	return w.multisign(ins, outs, sigs1, sigs2, redeemScript, feePerByte, broadcast)
 }
 
+func (w *LitecoinWallet) MultisignWithSignatures(ins []wi.TransactionInput, outs []wi.TransactionOutput, sigs multisig.Signatures, redeemScript []byte, feePerByte uint64, broadcast bool) ([]byte, bool, error) {
+	return w.multisignWithSignatures(ins, outs, sigs, redeemScript, feePerByte, broadcast)
+}
+
+func (w *LitecoinWallet) MergeMultisig(redeemScript []byte, txs [][]byte, broadcast bool) ([]byte, bool, error) {
+	return w.mergeMultisig(redeemScript, txs, broadcast)
+}
+
+func (w *LitecoinWallet) ReleaseAfterTimeout(ins []wi.TransactionInput, address btcutil.Address, timeoutKey *hd.ExtendedKey, redeemScript []byte, feeLevel wi.FeeLevel) (*chainhash.Hash, error) {
+	return w.releaseAfterTimeout(ins, address, timeoutKey, redeemScript, feeLevel)
+}
+
+// GenerateHTLCScript returns the address and script of a hash time locked
+// contract paying recipientKey for the preimage of secretHash, or refundKey
+// once the chain reached the lockTime height.
+func (w *LitecoinWallet) GenerateHTLCScript(secretHash, recipientKey, refundKey []byte, lockTime uint32) (btcutil.Address, []byte, error) {
+	return w.generateHTLCScript(secretHash, recipientKey, refundKey, lockTime)
+}
+
+func (w *LitecoinWallet) RedeemHTLC(ins []wi.TransactionInput, address btcutil.Address, key *hd.ExtendedKey, contract []byte, secret []byte, feeLevel wi.FeeLevel) (*chainhash.Hash, error) {
+	if secret == nil {
+		return nil, htlc.ErrSecretMismatch
+	}
+	return w.spendHTLC(ins, address, key, contract, secret, feeLevel)
+}
+
+func (w *LitecoinWallet) RefundHTLC(ins []wi.TransactionInput, address btcutil.Address, key *hd.ExtendedKey, contract []byte, feeLevel wi.FeeLevel) (*chainhash.Hash, error) {
+	return w.spendHTLC(ins, address, key, contract, nil, feeLevel)
+}
+
 func (w *LitecoinWallet) GenerateMultisigScript(keys []hd.ExtendedKey, threshold int, timeout time.Duration, timeoutKey *hd.ExtendedKey) (addr btcutil.Address, redeemScript []byte, err error) {
 	return w.generateMultisigScript(keys, threshold, timeout, timeoutKey)
 }
@@ -310,6 +527,33 @@ func (w *LitecoinWallet) AddTransactionListener(callback func(wi.TransactionCall
 	w.ws.AddTransactionListener(callback)
 }
 
+// WatchEscrowTimeout watches the escrow address and notifies the escrow
+// timeout listeners once its outputs can be released after the timeout.
+func (w *LitecoinWallet) WatchEscrowTimeout(addr btcutil.Address, redeemScript []byte) error {
+	if err := w.AddWatchedAddress(addr); err != nil {
+		return err
+	}
+	script, err := w.AddressToScript(addr)
+	if err != nil {
+		return err
+	}
+	return w.ws.WatchEscrowTimeout(script, redeemScript)
+}
+
+func (w *LitecoinWallet) AddEscrowTimeoutListener(callback func(service.EscrowTimeout)) {
+	w.ws.AddEscrowTimeoutListener(callback)
+}
+
+// WatchHTLC watches the contract address and calls callback for each input
+// spending its outputs, revealing the secret of a redeem.
+func (w *LitecoinWallet) WatchHTLC(addr btcutil.Address, callback func(service.ScriptSpend)) error {
+	if err := w.AddWatchedAddress(addr); err != nil {
+		return err
+	}
+	w.ws.WatchSpends(addr, callback)
+	return nil
+}
+
 func (w *LitecoinWallet) ReSyncBlockchain(fromTime time.Time) {
 	go w.ws.UpdateState()
 }
@@ -329,12 +573,23 @@ func (w *LitecoinWallet) GetConfirmations(txid chainhash.Hash) (uint32, uint32,
 func (w *LitecoinWallet) Close() {
 	w.ws.Stop()
 	w.client.Close()
+	if f, ok := w.exchangeRates.(*rates.Fetcher); ok {
+		f.Stop()
+	}
 }
 
 func (w *LitecoinWallet) ExchangeRates() wi.ExchangeRates {
 	return w.exchangeRates
 }
 
+// BackendStatus returns the health scoreboard of the API servers used by the wallet
+func (w *LitecoinWallet) BackendStatus() []client.BackendStatus {
+	if pool, ok := w.client.(*client.ClientPool); ok {
+		return pool.BackendStatus()
+	}
+	return nil
+}
+
 func (w *LitecoinWallet) DumpTables(wr io.Writer) {
 	fmt.Fprintln(wr, "Transactions-----")
 	txns, _ := w.db.Txns().GetAll(true)
@@ -374,30 +629,35 @@ func (w *LitecoinWallet) DumpTables(wr io.Writer) {
 func (w *LitecoinWallet) Broadcast(tx *wire.MsgTx) error {
 	var buf bytes.Buffer
 	tx.BtcEncode(&buf, wire.ProtocolVersion, wire.WitnessEncoding)
+	cTxn, err := w.modelTransaction(tx, tx.TxHash().String(), buf.Bytes())
+	if err != nil {
+		return err
+	}
+	_, err = w.client.Broadcast(buf.Bytes())
+	if err != nil {
+		return err
+	}
+	w.ws.ProcessIncomingTransaction(cTxn)
+	return nil
+}
+
+// modelTransaction converts tx into the form the wallet service ingests.
+// Inputs spending outputs the wallet doesn't hold are left without an address,
+// as are outputs whose script doesn't pay to one.
+func (w *LitecoinWallet) modelTransaction(tx *wire.MsgTx, txid string, raw []byte) (model.Transaction, error) {
 	cTxn := model.Transaction{
-		Txid:          tx.TxHash().String(),
+		Txid:          txid,
 		Locktime:      int(tx.LockTime),
 		Version:       int(tx.Version),
 		Confirmations: 0,
 		Time:          time.Now().Unix(),
-		RawBytes:      buf.Bytes(),
+		RawBytes:      raw,
 	}
 	utxos, err := w.db.Utxos().GetAll()
 	if err != nil {
-		return err
+		return cTxn, err
 	}
 	for n, in := range tx.TxIn {
-		var u wi.Utxo
-		for _, ut := range utxos {
-			if util.OutPointsEqual(ut.Op, in.PreviousOutPoint) {
-				u = ut
-				break
-			}
-		}
-		addr, err := w.ScriptToAddress(u.ScriptPubkey)
-		if err != nil {
-			return err
-		}
 		input := model.Input{
 			Txid: in.PreviousOutPoint.Hash.String(),
 			Vout: int(in.PreviousOutPoint.Index),
@@ -406,33 +666,133 @@ func (w *LitecoinWallet) Broadcast(tx *wire.MsgTx) error {
 			},
 			Sequence: uint32(in.Sequence),
 			N:        n,
-			Addr:     addr.String(),
-			Satoshis: u.Value,
-			Value:    float64(u.Value) / util.SatoshisPerCoin(wi.Litecoin),
+		}
+		for _, u := range utxos {
+			if !util.OutPointsEqual(u.Op, in.PreviousOutPoint) {
+				continue
+			}
+			if addr, err := w.ScriptToAddress(u.ScriptPubkey); err == nil {
+				input.Addr = addr.String()
+			}
+			input.Satoshis = u.Value
+			input.Value = float64(u.Value) / util.SatoshisPerCoin(wi.Litecoin)
+			break
 		}
 		cTxn.Inputs = append(cTxn.Inputs, input)
 	}
 	for n, out := range tx.TxOut {
-		addr, err := w.ScriptToAddress(out.PkScript)
-		if err != nil {
-			return err
-		}
 		output := model.Output{
 			N: n,
 			ScriptPubKey: model.OutScript{
 				Script: model.Script{
 					Hex: hex.EncodeToString(out.PkScript),
 				},
-				Addresses: []string{addr.String()},
 			},
-			Value: float64(float64(out.Value) / util.SatoshisPerCoin(wi.Bitcoin)),
+			Value: float64(out.Value) / util.SatoshisPerCoin(wi.Litecoin),
+		}
+		if addr, err := w.ScriptToAddress(out.PkScript); err == nil {
+			output.ScriptPubKey.Addresses = []string{addr.String()}
+		} else if _, ok := util.ExtractNullData(out.PkScript); ok {
+			output.ScriptPubKey.Type = "nulldata"
 		}
 		cTxn.Outputs = append(cTxn.Outputs, output)
 	}
-	_, err = w.client.Broadcast(buf.Bytes())
+	return cTxn, nil
+}
+
+// DecodeRawTx decodes a serialized transaction. Its fee is only computed when
+// the wallet knows every output it spends.
+func (w *LitecoinWallet) DecodeRawTx(raw []byte) (*util.DecodedTx, error) {
+	return w.decodeTx(raw, false)
+}
+
+// TransactionDetails decodes a wallet transaction and tells which of its
+// outputs pay the wallet. Outputs it spends the wallet doesn't store are
+// fetched from the backend.
+func (w *LitecoinWallet) TransactionDetails(txid chainhash.Hash) (*util.TxDetails, error) {
+	txn, err := w.db.Txns().Get(txid)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	w.ws.ProcessIncomingTransaction(cTxn)
-	return nil
+	decoded, err := w.decodeTx(txn.Bytes, true)
+	if err != nil {
+		return nil, err
+	}
+	confirmations, _, err := w.GetConfirmations(txid)
+	if err != nil {
+		return nil, err
+	}
+	return util.NewTxDetails(decoded, confirmations, w.outputOwner), nil
+}
+
+func (w *LitecoinWallet) decodeTx(raw []byte, fetchPrevOuts bool) (*util.DecodedTx, error) {
+	tx, vsize, err := parseRawTx(raw)
+	if err != nil {
+		return nil, err
+	}
+	utxos, err := w.db.Utxos().GetAll()
+	if err != nil {
+		return nil, err
+	}
+	txns, err := w.db.Txns().GetAll(true)
+	if err != nil {
+		return nil, err
+	}
+	parse := func(b []byte) (*wire.MsgTx, error) {
+		prev, _, err := parseRawTx(b)
+		return prev, err
+	}
+	prevOuts := util.KnownPrevOuts(tx, utxos, txns, parse)
+	if fetchPrevOuts {
+		util.FetchPrevOuts(tx, prevOuts, w.client.GetRawTransaction, parse)
+	}
+	decoded := util.DecodeTx(tx, tx.TxHash(), len(raw), vsize, prevOuts, w.ScriptToAddress)
+	return decoded, nil
+}
+
+// outputOwner tells whether addr is a receiving or a change address of the
+// wallet.
+func (w *LitecoinWallet) outputOwner(addr btcutil.Address) util.OutputOwner {
+	path, err := w.db.Keys().GetPathForKey(addr.ScriptAddress())
+	if err != nil {
+		return util.OwnerExternal
+	}
+	if path.Purpose == wi.INTERNAL {
+		return util.OwnerChange
+	}
+	return util.OwnerWallet
+}
+
+// BroadcastRawTx broadcasts a serialized transaction built outside the wallet.
+// It's ingested like the wallet's own transactions if it touches our addresses.
+func (w *LitecoinWallet) BroadcastRawTx(raw []byte) (*chainhash.Hash, error) {
+	tx, _, err := parseRawTx(raw)
+	if err != nil {
+		return nil, err
+	}
+	txid := tx.TxHash()
+	cTxn, err := w.modelTransaction(tx, txid.String(), raw)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.client.Broadcast(raw); err != nil {
+		return nil, err
+	}
+	if w.ws.IsRelevant(cTxn) {
+		w.ws.ProcessIncomingTransaction(cTxn)
+	}
+	return &txid, nil
+}
+
+// parseRawTx deserializes a raw transaction and returns it with its virtual
+// size.
+func parseRawTx(raw []byte) (*wire.MsgTx, int, error) {
+	tx := wire.NewMsgTx(wire.TxVersion)
+	if err := tx.Deserialize(bytes.NewReader(raw)); err != nil {
+		return nil, 0, err
+	}
+	if tx.SerializeSize() != len(raw) {
+		return nil, 0, errors.New("transaction has trailing bytes")
+	}
+	return tx, (3*tx.SerializeSizeStripped() + len(raw) + 3) / 4, nil
 }
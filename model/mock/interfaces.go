@@ -117,6 +117,9 @@ func MockWebsocketClientOnClientPool(p *client.ClientPool) *MockSocketClient {
 	for _, c := range p.Clients() {
 		c.SocketClient = mockSocketClient
 	}
+	for _, c := range p.InsightClients() {
+		c.SocketClient = mockSocketClient
+	}
 	return mockSocketClient
 }
 
@@ -0,0 +1,136 @@
+package model
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// VerboseTransaction is the transaction format returned by the verbose form of
+// bitcoind's getrawtransaction. Electrum servers pass it through unchanged.
+type VerboseTransaction struct {
+	Txid          string          `json:"txid"`
+	Version       int             `json:"version"`
+	Locktime      int             `json:"locktime"`
+	Hex           string          `json:"hex"`
+	Vin           []VerboseInput  `json:"vin"`
+	Vout          []VerboseOutput `json:"vout"`
+	BlockHash     string          `json:"blockhash"`
+	Confirmations int             `json:"confirmations"`
+	Time          int64           `json:"time"`
+	BlockTime     int64           `json:"blocktime"`
+}
+
+type VerboseInput struct {
+	Txid      string `json:"txid"`
+	Vout      int    `json:"vout"`
+	Sequence  uint32 `json:"sequence"`
+	ScriptSig Script `json:"scriptSig"`
+	Coinbase  string `json:"coinbase"`
+}
+
+type VerboseOutput struct {
+	Value        float64       `json:"value"`
+	N            int           `json:"n"`
+	ScriptPubKey VerboseScript `json:"scriptPubKey"`
+}
+
+// VerboseScript accepts both the addresses list of older nodes and the
+// single address reported by newer ones.
+type VerboseScript struct {
+	OutScript
+	Address string `json:"address"`
+}
+
+// OutputAddresses returns the addresses paid by output n with any cashaddr
+// prefix removed.
+func (v *VerboseTransaction) OutputAddresses(n int) []string {
+	var (
+		out   = v.Vout[n].ScriptPubKey
+		addrs []string
+	)
+	if len(out.Addresses) > 0 {
+		addrs = append(addrs, out.Addresses...)
+	} else if out.Address != "" {
+		addrs = []string{out.Address}
+	}
+	for i := range addrs {
+		addrs[i] = strings.TrimPrefix(strings.TrimPrefix(addrs[i], "bchtest:"), "bitcoincash:")
+	}
+	return addrs
+}
+
+// ToTransaction converts the verbose transaction to a Transaction. The block
+// height is derived from the confirmations and tipHeight. The inputs are
+// resolved against their previous outputs using prevTx; if prevTx returns a
+// nil transaction without an error the input is left without an address and
+// value.
+func (v *VerboseTransaction) ToTransaction(tipHeight int, prevTx func(txid string) (*VerboseTransaction, error)) (*Transaction, error) {
+	raw, err := hex.DecodeString(v.Hex)
+	if err != nil {
+		return nil, fmt.Errorf("decoding transaction hex: %s", err.Error())
+	}
+	tx := &Transaction{
+		Txid:          v.Txid,
+		Version:       v.Version,
+		Locktime:      v.Locktime,
+		BlockHash:     v.BlockHash,
+		Confirmations: v.Confirmations,
+		Time:          v.Time,
+		BlockTime:     v.BlockTime,
+		RawBytes:      raw,
+	}
+	if tx.Time == 0 {
+		tx.Time = time.Now().Unix()
+	}
+	if v.Confirmations > 0 {
+		tx.BlockHeight = tipHeight - v.Confirmations + 1
+	}
+
+	prevTxs := make(map[string]*VerboseTransaction)
+	for n, in := range v.Vin {
+		input := Input{
+			Txid:      in.Txid,
+			Vout:      in.Vout,
+			Sequence:  in.Sequence,
+			N:         n,
+			ScriptSig: in.ScriptSig,
+		}
+		if in.Coinbase == "" {
+			prev, ok := prevTxs[in.Txid]
+			if !ok {
+				prev, err = prevTx(in.Txid)
+				if err != nil {
+					return nil, fmt.Errorf("resolving input %d: %s", n, err.Error())
+				}
+				prevTxs[in.Txid] = prev
+			}
+			if prev != nil {
+				if in.Vout >= len(prev.Vout) {
+					return nil, errors.New("transaction input references invalid output")
+				}
+				if addrs := prev.OutputAddresses(in.Vout); len(addrs) > 0 {
+					input.Addr = addrs[0]
+				}
+				input.Value = prev.Vout[in.Vout].Value
+				input.ValueIface = input.Value
+				input.Satoshis = int64(math.Round(input.Value * 1e8))
+			}
+		}
+		tx.Inputs = append(tx.Inputs, input)
+	}
+	for n, out := range v.Vout {
+		output := Output{
+			Value:      out.Value,
+			ValueIface: out.Value,
+			N:          out.N,
+		}
+		output.ScriptPubKey = out.ScriptPubKey.OutScript
+		output.ScriptPubKey.Addresses = v.OutputAddresses(n)
+		tx.Outputs = append(tx.Outputs, output)
+	}
+	return tx, nil
+}
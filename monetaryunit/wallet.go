@@ -15,6 +15,8 @@ import (
 	"github.com/muecoin/multiwallet/cache"
 	"github.com/muecoin/multiwallet/config"
 	"github.com/muecoin/multiwallet/keys"
+	"github.com/muecoin/multiwallet/rates"
+	"github.com/muecoin/multiwallet/registry"
 	"github.com/muecoin/multiwallet/util"
 
 	"github.com/OpenBazaar/spvwallet"
@@ -54,8 +56,44 @@ type RPCWallet struct {
 	notifications *NotificationListener
 }
 
+func init() {
+	registry.Register(registry.Coin{
+		Name:                "MonetaryUnit",
+		CoinType:            util.CoinTypeMonetaryUnit,
+		TestnetCoinType:     util.CoinTypeMonetaryUnitTest,
+		CurrencyCode:        "MUE",
+		TestnetCurrencyCode: "TMUE",
+		Params: map[string]*chaincfg.Params{
+			registry.MainNet:                  &MonetaryUnitMainNetParams,
+			MonetaryUnitMainNetParams.Name:    &MonetaryUnitMainNetParams,
+			chaincfg.TestNet3Params.Name:      &MonetaryUnitTestNetParams,
+			chaincfg.RegressionNetParams.Name: &MonetaryUnitTestNetParams,
+			chaincfg.SimNetParams.Name:        &MonetaryUnitTestNetParams,
+		},
+		NewWallet: newWallet,
+		PriceProviders: []string{
+			"coingecko+https://api.coingecko.com/api/v3/coins/monetaryunit?tickers=false&community_data=false&developer_data=false&sparkline=false",
+			"coinmarketcap+https://api.coinmarketcap.com/v2/ticker/706/?convert=BTC",
+		},
+		DecodeAddress:   btc.DecodeAddress,
+		ScriptToAddress: scriptToAddress,
+		AddressToScript: txscript.PayToAddrScript,
+	})
+}
+
+func newWallet(cfg config.CoinConfig, mnemonic string, params *chaincfg.Params, proxy proxy.Dialer, cache cache.Cacher, disableExchangeRates bool) (wallet.Wallet, error) {
+	w, err := NewMonetaryUnitWallet(cfg, mnemonic, params, proxy, cache, disableExchangeRates)
+	if err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
 // NewMonetaryUnitWallet creates a new wallet given
 func NewMonetaryUnitWallet(cfg config.CoinConfig, mnemonic string, params *chaincfg.Params, proxy proxy.Dialer, cache cache.Cacher, disableExchangeRates bool) (*RPCWallet, error) {
+	if err := util.RequireSizeFeeModel(cfg.FeeModel); err != nil {
+		return nil, err
+	}
 	host := "rpc2.monetaryunit.org"
 	connCfg := &rpcclient.ConnConfig{
 		Host:                 path.Join(host, "rpc"),
@@ -89,7 +127,10 @@ func NewMonetaryUnitWallet(cfg config.CoinConfig, mnemonic string, params *chain
 		return nil, err
 	}
 
-	exchRate := NewMonetaryUnitPriceFetcher(proxy)
+	exchRate, err := rates.NewPriceFetcher(cfg.CoinType, cfg.PriceAPIs, proxy)
+	if err != nil {
+		return nil, err
+	}
 	if !disableExchangeRates {
 		go exchRate.Run()
 	}
@@ -997,6 +1038,9 @@ func (w *RPCWallet) GetConfirmations(txid chainhash.Hash) (uint32, uint32, error
 
 // Close closes the rpc wallet connection
 func (w *RPCWallet) Close() {
+	if f, ok := w.exchangeRates.(*rates.Fetcher); ok {
+		f.Stop()
+	}
 	if w.started {
 		log.Info("Disconnecting from peers and shutting down")
 		w.rpcLock.Lock()
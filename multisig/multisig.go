@@ -0,0 +1,484 @@
+package multisig
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/OpenBazaar/wallet-interface"
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+var (
+	// ErrUnknownCosigner is returned when signatures are supplied for a key
+	// which is not part of the redeem script.
+	ErrUnknownCosigner = errors.New("cosigner key is not part of the redeem script")
+
+	// ErrRedeemScriptMismatch is returned when a partially signed input
+	// redeems a different script than the one being signed.
+	ErrRedeemScriptMismatch = errors.New("input does not redeem the multisig script")
+
+	// ErrTimeoutNotMatured is returned when the timeout branch of an escrow
+	// is spent before its relative lock time has passed.
+	ErrTimeoutNotMatured = errors.New("escrow timeout has not matured")
+)
+
+const (
+	// ECDSASignatureSize is the worst case size of a DER signature plus the
+	// sighash byte
+	ECDSASignatureSize = 72 + 1
+
+	// timeoutClauseSize is the worst case size of the timeout branch of an
+	// escrow script. It is calculated as:
+	//
+	//   - OP_IF
+	//   - OP_ELSE
+	//   - OP_DATA_3
+	//   - 3 bytes relative lock time
+	//   - OP_CHECKSEQUENCEVERIFY
+	//   - OP_DROP
+	//   - OP_DATA_33
+	//   - 33 bytes serialized compressed pubkey
+	//   - OP_CHECKSIG
+	//   - OP_ENDIF
+	timeoutClauseSize = 1 + 1 + 1 + 3 + 1 + 1 + 1 + 33 + 1 + 1
+)
+
+// Signatures holds the signatures of the cosigners of a multisig keyed by the
+// hex encoded compressed public key of each cosigner, as it appears in the
+// redeem script.
+type Signatures map[string][]wallet.Signature
+
+// Add sets the signatures of the cosigner with the given public key
+func (s Signatures) Add(pubKey []byte, sigs []wallet.Signature) {
+	s[hex.EncodeToString(pubKey)] = sigs
+}
+
+// Merge adds the signatures of other for the inputs a cosigner has not signed
+// yet. Signatures already present are kept.
+func (s Signatures) Merge(other Signatures) {
+	for key, sigs := range other {
+		for _, sig := range sigs {
+			signed := false
+			for _, existing := range s[key] {
+				if existing.InputIndex == sig.InputIndex {
+					signed = true
+					break
+				}
+			}
+			if !signed {
+				s[key] = append(s[key], sig)
+			}
+		}
+	}
+}
+
+// RedeemScript is a parsed m of n multisig redeem script. A timelocked
+// script is an escrow which a single timeout key can also redeem after a
+// relative lock time.
+type RedeemScript struct {
+	Script     []byte
+	Threshold  int
+	PubKeys    [][]byte
+	Timelocked bool
+
+	// Timeout is the relative lock time checked by OP_CHECKSEQUENCEVERIFY
+	// and TimeoutKey the key which may spend the escrow once it has passed.
+	Timeout    uint32
+	TimeoutKey []byte
+}
+
+// ParseRedeemScript parses a redeem script created by GenerateMultisigScript
+func ParseRedeemScript(script []byte) (*RedeemScript, error) {
+	rs := &RedeemScript{Script: script}
+	pos := 0
+	if len(script) > 0 && script[0] == txscript.OP_IF {
+		rs.Timelocked = true
+		pos++
+	}
+	m, pos, err := readSmallInt(script, pos)
+	if err != nil {
+		return nil, err
+	}
+	for pos < len(script) && script[pos] == txscript.OP_DATA_33 {
+		if pos+1+btcec.PubKeyBytesLenCompressed > len(script) {
+			return nil, errors.New("truncated public key in redeem script")
+		}
+		rs.PubKeys = append(rs.PubKeys, script[pos+1:pos+1+btcec.PubKeyBytesLenCompressed])
+		pos += 1 + btcec.PubKeyBytesLenCompressed
+	}
+	n, pos, err := readSmallInt(script, pos)
+	if err != nil {
+		return nil, err
+	}
+	if n != len(rs.PubKeys) || m < 1 || m > n {
+		return nil, fmt.Errorf("invalid %d of %d multisig with %d keys", m, n, len(rs.PubKeys))
+	}
+	if pos >= len(script) || script[pos] != txscript.OP_CHECKMULTISIG {
+		return nil, errors.New("redeem script is not a multisig")
+	}
+	pos++
+	if rs.Timelocked {
+		if err := rs.parseTimeoutClause(script[pos:]); err != nil {
+			return nil, err
+		}
+	} else if pos != len(script) {
+		return nil, errors.New("unexpected data after multisig in redeem script")
+	}
+	rs.Threshold = m
+	return rs, nil
+}
+
+// parseTimeoutClause parses the OP_ELSE branch of an escrow:
+// OP_ELSE <timeout> OP_CHECKSEQUENCEVERIFY OP_DROP <timeoutKey> OP_CHECKSIG OP_ENDIF
+func (rs *RedeemScript) parseTimeoutClause(clause []byte) error {
+	malformed := errors.New("malformed timeout clause in redeem script")
+	if len(clause) < 2 || clause[0] != txscript.OP_ELSE {
+		return malformed
+	}
+	pos := 1
+	op := clause[pos]
+	switch {
+	case op == txscript.OP_0:
+		pos++
+	case op >= txscript.OP_1 && op <= txscript.OP_16:
+		rs.Timeout = uint32(op-txscript.OP_1) + 1
+		pos++
+	case op >= txscript.OP_DATA_1 && op <= txscript.OP_DATA_4 && pos+1+int(op) <= len(clause):
+		num := clause[pos+1 : pos+1+int(op)]
+		if num[len(num)-1]&0x80 != 0 {
+			return errors.New("negative timeout in redeem script")
+		}
+		for i, b := range num {
+			rs.Timeout |= uint32(b) << uint(8*i)
+		}
+		pos += 1 + int(op)
+	default:
+		return malformed
+	}
+	expected := []byte{txscript.OP_CHECKSEQUENCEVERIFY, txscript.OP_DROP, txscript.OP_DATA_33}
+	if pos+len(expected)+btcec.PubKeyBytesLenCompressed+2 != len(clause) || !bytes.Equal(clause[pos:pos+len(expected)], expected) {
+		return malformed
+	}
+	pos += len(expected)
+	rs.TimeoutKey = clause[pos : pos+btcec.PubKeyBytesLenCompressed]
+	pos += btcec.PubKeyBytesLenCompressed
+	if clause[pos] != txscript.OP_CHECKSIG || clause[pos+1] != txscript.OP_ENDIF {
+		return malformed
+	}
+	return nil
+}
+
+// readSmallInt reads a number pushed by ScriptBuilder.AddInt64 in the range
+// of a multisig threshold or key count.
+func readSmallInt(script []byte, pos int) (int, int, error) {
+	if pos >= len(script) {
+		return 0, pos, errors.New("truncated redeem script")
+	}
+	op := script[pos]
+	switch {
+	case op >= txscript.OP_1 && op <= txscript.OP_16:
+		return int(op-txscript.OP_1) + 1, pos + 1, nil
+	case op == txscript.OP_DATA_1 && pos+1 < len(script) && script[pos+1] < 0x80:
+		return int(script[pos+1]), pos + 2, nil
+	}
+	return 0, pos, fmt.Errorf("expected a number at position %d of redeem script", pos)
+}
+
+// TimeoutBlocks returns the number of blocks the outputs of an escrow must be
+// confirmed for before the timeout key may spend them.
+func (rs *RedeemScript) TimeoutBlocks() (uint32, error) {
+	if !rs.Timelocked {
+		return 0, errors.New("redeem script has no timeout")
+	}
+	if rs.Timeout&wire.SequenceLockTimeDisabled != 0 || rs.Timeout&wire.SequenceLockTimeIsSeconds != 0 {
+		return 0, fmt.Errorf("unsupported relative lock time %#x in redeem script", rs.Timeout)
+	}
+	return rs.Timeout & wire.SequenceLockTimeMask, nil
+}
+
+// TimeoutMatured returns whether an output of an escrow confirmed at height
+// can be spent through the timeout branch in the block after tip. An
+// unconfirmed output, at height zero, never has.
+func TimeoutMatured(height, tip, blocks uint32) bool {
+	return height > 0 && tip+1 >= height+blocks
+}
+
+// TimeoutStack returns the stack spending the timeout branch of an escrow
+// with the signature of the timeout key.
+func (rs *RedeemScript) TimeoutStack(sig []byte) [][]byte {
+	return [][]byte{sig, {}, rs.Script}
+}
+
+// KeyIndex returns the position of the public key in the redeem script or -1
+func (rs *RedeemScript) KeyIndex(pubKey []byte) int {
+	for i, key := range rs.PubKeys {
+		if bytes.Equal(key, pubKey) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Slots returns the signatures of input idx ordered against the keys of the
+// redeem script. Keys which have not signed the input have an empty slot.
+func (rs *RedeemScript) Slots(sigs Signatures, idx int) ([][]byte, error) {
+	slots := make([][]byte, len(rs.PubKeys))
+	for key, keySigs := range sigs {
+		pubKey, err := hex.DecodeString(key)
+		if err != nil {
+			return nil, err
+		}
+		i := rs.KeyIndex(pubKey)
+		if i < 0 {
+			return nil, ErrUnknownCosigner
+		}
+		for _, sig := range keySigs {
+			if int(sig.InputIndex) == idx && len(sig.Signature) > 0 {
+				slots[i] = sig.Signature
+				break
+			}
+		}
+	}
+	return slots, nil
+}
+
+// MergeSlots fills the empty slots of a with the signatures of b
+func MergeSlots(a, b [][]byte) ([][]byte, error) {
+	if len(a) != len(b) {
+		return nil, errors.New("signature slots of different multisigs")
+	}
+	merged := make([][]byte, len(a))
+	for i := range a {
+		merged[i] = a[i]
+		if len(merged[i]) == 0 {
+			merged[i] = b[i]
+		}
+	}
+	return merged, nil
+}
+
+// Select returns the first Threshold signatures in slots together with the
+// positions of their keys. ok is false when too few cosigners have signed.
+func (rs *RedeemScript) Select(slots [][]byte) (sigs [][]byte, positions []int, ok bool) {
+	for i, sig := range slots {
+		if len(sig) == 0 {
+			continue
+		}
+		sigs = append(sigs, sig)
+		positions = append(positions, i)
+		if len(sigs) == rs.Threshold {
+			return sigs, positions, true
+		}
+	}
+	return sigs, positions, false
+}
+
+// Stack returns the elements redeeming an input through the multisig: the
+// dummy element consumed by OP_CHECKMULTISIG, the signatures, the branch
+// selector of a timelocked script and the redeem script. Once Threshold
+// cosigners have signed the stack is final. Until then it holds a slot per
+// key, empty for keys which have not signed, so partially signed
+// transactions can be passed between cosigners and merged.
+func (rs *RedeemScript) Stack(slots [][]byte) (stack [][]byte, complete bool) {
+	sigs, _, complete := rs.Select(slots)
+	if !complete {
+		sigs = slots
+	}
+	stack = append([][]byte{{}}, sigs...)
+	if rs.Timelocked {
+		stack = append(stack, []byte{0x01})
+	}
+	return append(stack, rs.Script), complete
+}
+
+// ParseStack returns the signature slots of an input from the elements of its
+// witness or signature script, see ScriptStack. An input without
+// signatures has only empty slots. complete is set for an input with a final
+// stack, which carries no slots.
+func (rs *RedeemScript) ParseStack(stack [][]byte) (slots [][]byte, complete bool, err error) {
+	if len(stack) == 0 {
+		return make([][]byte, len(rs.PubKeys)), false, nil
+	}
+	if !bytes.Equal(stack[len(stack)-1], rs.Script) {
+		return nil, false, ErrRedeemScriptMismatch
+	}
+	stack = stack[:len(stack)-1]
+	if rs.Timelocked && len(stack) > 0 && bytes.Equal(stack[len(stack)-1], []byte{0x01}) {
+		stack = stack[:len(stack)-1]
+	}
+	if len(stack) == 0 {
+		return nil, false, errors.New("missing multisig dummy element")
+	}
+	dummy, sigs := stack[0], stack[1:]
+	switch {
+	case len(dummy) > 0 && len(sigs) == rs.Threshold:
+		// A bitfield of the signing keys, used with Schnorr signatures
+		return nil, true, nil
+	case len(dummy) == 0 && len(sigs) == len(rs.PubKeys):
+		return sigs, false, nil
+	case len(dummy) == 0 && len(sigs) == rs.Threshold:
+		for _, sig := range sigs {
+			if len(sig) == 0 {
+				return nil, false, errors.New("empty signature in final multisig stack")
+			}
+		}
+		return nil, true, nil
+	}
+	return nil, false, fmt.Errorf("unexpected %d signatures for a %d of %d multisig", len(sigs), rs.Threshold, len(rs.PubKeys))
+}
+
+// SignatureScript returns a signature script pushing the elements of stack
+func SignatureScript(stack [][]byte) ([]byte, error) {
+	builder := txscript.NewScriptBuilder()
+	for _, data := range stack {
+		builder.AddData(data)
+	}
+	return builder.Script()
+}
+
+// ScriptStack returns the elements a push only signature script leaves on the
+// stack. Small integers are returned as a single byte and OP_0 as an empty
+// element, matching the encoding of a witness.
+func ScriptStack(sigScript []byte) ([][]byte, error) {
+	var stack [][]byte
+	for pos := 0; pos < len(sigScript); {
+		op := sigScript[pos]
+		pos++
+		var dataLen int
+		switch {
+		case op == txscript.OP_0:
+			stack = append(stack, []byte{})
+			continue
+		case op >= txscript.OP_1 && op <= txscript.OP_16:
+			stack = append(stack, []byte{op - txscript.OP_1 + 1})
+			continue
+		case op < txscript.OP_PUSHDATA1:
+			dataLen = int(op)
+		case op == txscript.OP_PUSHDATA1 && pos+1 <= len(sigScript):
+			dataLen = int(sigScript[pos])
+			pos++
+		case op == txscript.OP_PUSHDATA2 && pos+2 <= len(sigScript):
+			dataLen = int(binary.LittleEndian.Uint16(sigScript[pos:]))
+			pos += 2
+		default:
+			return nil, fmt.Errorf("unexpected opcode %#x in signature script", op)
+		}
+		if pos+dataLen > len(sigScript) {
+			return nil, errors.New("truncated signature script")
+		}
+		stack = append(stack, sigScript[pos:pos+dataLen])
+		pos += dataLen
+	}
+	return stack, nil
+}
+
+// UnsignedTxHash returns the hash of the transaction with the signature
+// scripts and witnesses of its inputs removed. Partially signed copies of the
+// same transaction have the same unsigned hash.
+func UnsignedTxHash(tx *wire.MsgTx) chainhash.Hash {
+	unsigned := tx.Copy()
+	for _, in := range unsigned.TxIn {
+		in.SignatureScript = nil
+		in.Witness = nil
+	}
+	return unsigned.TxHash()
+}
+
+// RedeemScriptSize returns the worst case size of an m of n multisig redeem
+// script with compressed keys, including the timeout clause of an escrow.
+func RedeemScriptSize(m, n int, timelocked bool) int {
+	size := smallIntSize(m) + n*(1+btcec.PubKeyBytesLenCompressed) + smallIntSize(n) + 1
+	if timelocked {
+		size += timeoutClauseSize
+	}
+	return size
+}
+
+// SigScriptSize returns the worst case size of a signature script redeeming
+// an m of n P2SH multisig with signatures of sigSize bytes. It is calculated
+// as:
+//
+//   - OP_0
+//   - m signature pushes
+//   - OP_1 selecting the multisig branch of an escrow
+//   - redeem script push
+func SigScriptSize(m, n int, timelocked bool, sigSize int) int {
+	size := 1 + m*pushSize(sigSize)
+	if timelocked {
+		size++
+	}
+	return size + pushSize(RedeemScriptSize(m, n, timelocked))
+}
+
+// WitnessSize returns the worst case size of a witness redeeming an m of n
+// P2WSH multisig. It is calculated as:
+//
+//   - 1 byte item count
+//   - 1 byte empty dummy item
+//   - m signatures with their lengths
+//   - 2 bytes branch selector of an escrow
+//   - redeem script with its length
+func WitnessSize(m, n int, timelocked bool) int {
+	size := 1 + 1 + m*(1+ECDSASignatureSize)
+	if timelocked {
+		size += 2
+	}
+	redeemSize := RedeemScriptSize(m, n, timelocked)
+	return size + varIntSize(redeemSize) + redeemSize
+}
+
+// TimeoutSigScriptSize returns the worst case size of a signature script
+// spending the timeout branch of an m of n P2SH escrow with a signature of
+// sigSize bytes. It is calculated as:
+//
+//   - signature push
+//   - OP_0 selecting the timeout branch
+//   - redeem script push
+func TimeoutSigScriptSize(m, n int, sigSize int) int {
+	return pushSize(sigSize) + 1 + pushSize(RedeemScriptSize(m, n, true))
+}
+
+// TimeoutWitnessSize returns the worst case size of a witness spending the
+// timeout branch of an m of n P2WSH escrow. It is calculated as:
+//
+//   - 1 byte item count
+//   - signature with its length
+//   - 1 byte empty branch selector
+//   - redeem script with its length
+func TimeoutWitnessSize(m, n int) int {
+	redeemSize := RedeemScriptSize(m, n, true)
+	return 1 + 1 + ECDSASignatureSize + 1 + varIntSize(redeemSize) + redeemSize
+}
+
+func smallIntSize(i int) int {
+	if i <= 16 {
+		return 1
+	}
+	return 2
+}
+
+func pushSize(dataLen int) int {
+	switch {
+	case dataLen < txscript.OP_PUSHDATA1:
+		return 1 + dataLen
+	case dataLen <= 0xff:
+		return 2 + dataLen
+	}
+	return 3 + dataLen
+}
+
+func varIntSize(i int) int {
+	switch {
+	case i < 0xfd:
+		return 1
+	case i <= 0xffff:
+		return 3
+	}
+	return 5
+}
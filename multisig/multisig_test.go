@@ -0,0 +1,312 @@
+package multisig
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/OpenBazaar/wallet-interface"
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+func testKeys(t *testing.T, n int) [][]byte {
+	var keys [][]byte
+	for i := 0; i < n; i++ {
+		_, pub := btcec.PrivKeyFromBytes(btcec.S256(), []byte{byte(i + 1)})
+		keys = append(keys, pub.SerializeCompressed())
+	}
+	return keys
+}
+
+func testRedeemScript(t *testing.T, m int, keys [][]byte, timeoutKey []byte) []byte {
+	builder := txscript.NewScriptBuilder()
+	if timeoutKey != nil {
+		builder.AddOp(txscript.OP_IF)
+	}
+	builder.AddInt64(int64(m))
+	for _, key := range keys {
+		builder.AddData(key)
+	}
+	builder.AddInt64(int64(len(keys)))
+	builder.AddOp(txscript.OP_CHECKMULTISIG)
+	if timeoutKey != nil {
+		builder.AddOp(txscript.OP_ELSE).
+			AddInt64(0xffff).
+			AddOp(txscript.OP_CHECKSEQUENCEVERIFY).
+			AddOp(txscript.OP_DROP).
+			AddData(timeoutKey).
+			AddOp(txscript.OP_CHECKSIG).
+			AddOp(txscript.OP_ENDIF)
+	}
+	script, err := builder.Script()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return script
+}
+
+func TestParseRedeemScript(t *testing.T) {
+	script, _ := hex.DecodeString("522103c157f2a7c178430972263232c9306110090c50b44d4e906ecd6d377eec89a53c210205b02b9dbe570f36d1c12e3100e55586b2b9dc61d6778c1d24a8eaca03625e7e21030c83b025cd6bdd8c06e93a2b953b821b4a8c29da211335048d7dc3389706d7e853ae")
+	rs, err := ParseRedeemScript(script)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rs.Threshold != 2 || len(rs.PubKeys) != 3 || rs.Timelocked {
+		t.Errorf("Parsed %d of %d multisig, timelocked %t", rs.Threshold, len(rs.PubKeys), rs.Timelocked)
+	}
+	if hex.EncodeToString(rs.PubKeys[1]) != "0205b02b9dbe570f36d1c12e3100e55586b2b9dc61d6778c1d24a8eaca03625e7e" {
+		t.Errorf("Parsed incorrect key %x", rs.PubKeys[1])
+	}
+
+	keys := testKeys(t, 6)
+	rs, err = ParseRedeemScript(testRedeemScript(t, 3, keys[:5], keys[5]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rs.Threshold != 3 || len(rs.PubKeys) != 5 || !rs.Timelocked {
+		t.Errorf("Parsed %d of %d multisig, timelocked %t", rs.Threshold, len(rs.PubKeys), rs.Timelocked)
+	}
+	if rs.KeyIndex(keys[5]) != -1 {
+		t.Error("Timeout key is part of the multisig")
+	}
+
+	for _, script := range [][]byte{
+		nil,
+		testRedeemScript(t, 4, keys[:3], nil),
+		testRedeemScript(t, 2, keys[:3], nil)[1:],
+		append(testRedeemScript(t, 2, keys[:3], nil), txscript.OP_DROP),
+	} {
+		if _, err := ParseRedeemScript(script); err == nil {
+			t.Errorf("Parsed invalid redeem script %x", script)
+		}
+	}
+}
+
+func TestRedeemScript_Slots(t *testing.T) {
+	keys := testKeys(t, 5)
+	rs, err := ParseRedeemScript(testRedeemScript(t, 3, keys, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sigs := make(Signatures)
+	sigs.Add(keys[4], []wallet.Signature{{InputIndex: 0, Signature: []byte{4}}, {InputIndex: 1, Signature: []byte{4, 1}}})
+	sigs.Add(keys[1], []wallet.Signature{{InputIndex: 0, Signature: []byte{1}}})
+
+	slots, err := rs.Slots(sigs, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := [][]byte{nil, {1}, nil, nil, {4}}
+	for i := range expected {
+		if !bytes.Equal(slots[i], expected[i]) {
+			t.Errorf("Slot %d: expected %x but had %x", i, expected[i], slots[i])
+		}
+	}
+	if _, _, ok := rs.Select(slots); ok {
+		t.Error("Selected signatures below the threshold")
+	}
+
+	more := make(Signatures)
+	more.Add(keys[2], []wallet.Signature{{InputIndex: 0, Signature: []byte{2}}})
+	more.Add(keys[4], []wallet.Signature{{InputIndex: 0, Signature: []byte{5}}})
+	sigs.Merge(more)
+	slots, err = rs.Slots(sigs, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	selected, positions, ok := rs.Select(slots)
+	if !ok {
+		t.Fatal("Failed to select signatures at the threshold")
+	}
+	if len(selected) != 3 || !bytes.Equal(selected[0], []byte{1}) || !bytes.Equal(selected[1], []byte{2}) || !bytes.Equal(selected[2], []byte{4}) {
+		t.Errorf("Selected signatures out of key order: %x", selected)
+	}
+	if positions[0] != 1 || positions[1] != 2 || positions[2] != 4 {
+		t.Errorf("Incorrect key positions %v", positions)
+	}
+
+	unknown := make(Signatures)
+	unknown.Add(testKeys(t, 6)[5], []wallet.Signature{{InputIndex: 0, Signature: []byte{6}}})
+	if _, err := rs.Slots(unknown, 0); err != ErrUnknownCosigner {
+		t.Errorf("Expected ErrUnknownCosigner but had %v", err)
+	}
+}
+
+func TestRedeemScript_Stack(t *testing.T) {
+	keys := testKeys(t, 6)
+	rs, err := ParseRedeemScript(testRedeemScript(t, 2, keys[:3], keys[5]))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	partial, complete := rs.Stack([][]byte{nil, {0x30, 1}, nil})
+	if complete {
+		t.Error("Partial stack reported as complete")
+	}
+	if len(partial) != 6 {
+		t.Fatalf("Expected a slot per key in the partial stack but had %d elements", len(partial))
+	}
+	sigScript, err := SignatureScript(partial)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsedStack, err := ScriptStack(sigScript)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, stack := range [][][]byte{partial, parsedStack} {
+		slots, complete, err := rs.ParseStack(stack)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if complete || len(slots) != 3 || !bytes.Equal(slots[1], []byte{0x30, 1}) || len(slots[0]) != 0 || len(slots[2]) != 0 {
+			t.Errorf("Parsed incorrect slots %x", slots)
+		}
+	}
+
+	merged, err := MergeSlots([][]byte{nil, {0x30, 1}, nil}, [][]byte{{0x30, 0}, nil, nil})
+	if err != nil {
+		t.Fatal(err)
+	}
+	final, complete := rs.Stack(merged)
+	if !complete {
+		t.Error("Final stack reported as partial")
+	}
+	expected := [][]byte{{}, {0x30, 0}, {0x30, 1}, {0x01}, rs.Script}
+	if len(final) != len(expected) {
+		t.Fatalf("Expected %d elements but had %d", len(expected), len(final))
+	}
+	for i := range expected {
+		if !bytes.Equal(final[i], expected[i]) {
+			t.Errorf("Element %d: expected %x but had %x", i, expected[i], final[i])
+		}
+	}
+	if _, complete, err := rs.ParseStack(final); err != nil || !complete {
+		t.Errorf("Final stack not parsed as complete: %v", err)
+	}
+	if _, _, err := rs.ParseStack([][]byte{{}, {0x30}, {0x01}, {0x51}}); err != ErrRedeemScriptMismatch {
+		t.Errorf("Expected ErrRedeemScriptMismatch but had %v", err)
+	}
+	if slots, _, err := rs.ParseStack(nil); err != nil || len(slots) != 3 {
+		t.Errorf("Unsigned input not parsed as empty slots: %v", err)
+	}
+}
+
+func TestSizes(t *testing.T) {
+	keys := testKeys(t, 6)
+	tests := []struct {
+		m, n       int
+		timelocked bool
+	}{
+		{2, 3, false},
+		{2, 3, true},
+		{3, 5, false},
+		{3, 5, true},
+		{1, 2, false},
+	}
+	for _, test := range tests {
+		var timeoutKey []byte
+		if test.timelocked {
+			timeoutKey = keys[5]
+		}
+		script := testRedeemScript(t, test.m, keys[:test.n], timeoutKey)
+		if size := RedeemScriptSize(test.m, test.n, test.timelocked); size != len(script) {
+			t.Errorf("%d of %d: expected redeem script size %d but had %d", test.m, test.n, len(script), size)
+		}
+	}
+
+	if size := SigScriptSize(2, 3, false, ECDSASignatureSize); size != 256 {
+		t.Errorf("Expected 2 of 3 signature script size 256 but had %d", size)
+	}
+	if size := SigScriptSize(3, 5, true, ECDSASignatureSize); size != 1+3*74+1+2+217 {
+		t.Errorf("Incorrect 3 of 5 timelocked signature script size %d", size)
+	}
+	if size := WitnessSize(3, 5, false); size != 1+1+3*74+1+173 {
+		t.Errorf("Incorrect 3 of 5 witness size %d", size)
+	}
+}
+
+func TestUnsignedTxHash(t *testing.T) {
+	tx := wire.NewMsgTx(1)
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{1}, 0), nil, nil))
+	tx.AddTxOut(wire.NewTxOut(1000, []byte{txscript.OP_TRUE}))
+	signed := tx.Copy()
+	signed.TxIn[0].SignatureScript = []byte{txscript.OP_0, txscript.OP_0}
+	signed.TxIn[0].Witness = wire.TxWitness{{}, {0x30}}
+	if UnsignedTxHash(signed) != UnsignedTxHash(tx) {
+		t.Error("Signatures changed the unsigned hash")
+	}
+	if signed.TxIn[0].SignatureScript == nil {
+		t.Error("UnsignedTxHash modified the transaction")
+	}
+	tx.TxOut[0].Value++
+	if UnsignedTxHash(signed) == UnsignedTxHash(tx) {
+		t.Error("Different transactions have the same unsigned hash")
+	}
+}
+
+func TestRedeemScript_Timeout(t *testing.T) {
+	keys := testKeys(t, 6)
+	rs, err := ParseRedeemScript(testRedeemScript(t, 3, keys[:5], keys[5]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rs.Timeout != 0xffff || !bytes.Equal(rs.TimeoutKey, keys[5]) {
+		t.Errorf("Parsed timeout %#x with key %x", rs.Timeout, rs.TimeoutKey)
+	}
+	blocks, err := rs.TimeoutBlocks()
+	if err != nil || blocks != 0xffff {
+		t.Errorf("Expected 65535 timeout blocks but had %d: %v", blocks, err)
+	}
+
+	script, err := txscript.NewScriptBuilder().
+		AddOp(txscript.OP_IF).AddOp(txscript.OP_1).AddData(keys[0]).AddData(keys[1]).AddOp(txscript.OP_2).AddOp(txscript.OP_CHECKMULTISIG).
+		AddOp(txscript.OP_ELSE).AddInt64(144).AddOp(txscript.OP_CHECKSEQUENCEVERIFY).AddOp(txscript.OP_DROP).
+		AddData(keys[2]).AddOp(txscript.OP_CHECKSIG).AddOp(txscript.OP_ENDIF).Script()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rs, err = ParseRedeemScript(script)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if blocks, err := rs.TimeoutBlocks(); err != nil || blocks != 144 {
+		t.Errorf("Expected 144 timeout blocks but had %d: %v", blocks, err)
+	}
+	rs.Timeout |= wire.SequenceLockTimeIsSeconds
+	if _, err := rs.TimeoutBlocks(); err == nil {
+		t.Error("Accepted a time based relative lock time")
+	}
+	if _, err := ParseRedeemScript(script[:len(script)-2]); err == nil {
+		t.Error("Parsed a truncated timeout clause")
+	}
+
+	tests := []struct {
+		height, tip uint32
+		matured     bool
+	}{
+		{0, 1000, false},
+		{100, 242, false},
+		{100, 243, true},
+		{100, 500, true},
+	}
+	for _, test := range tests {
+		if TimeoutMatured(test.height, test.tip, 144) != test.matured {
+			t.Errorf("Output confirmed at %d with tip %d: expected matured %t", test.height, test.tip, test.matured)
+		}
+	}
+
+	sigScript, err := SignatureScript(rs.TimeoutStack(make([]byte, ECDSASignatureSize)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size := TimeoutSigScriptSize(1, 2, ECDSASignatureSize); size < len(sigScript) {
+		t.Errorf("Timeout signature script of %d bytes exceeds the estimate of %d", len(sigScript), size)
+	}
+	if size := TimeoutWitnessSize(2, 3); size != 1+74+1+1+RedeemScriptSize(2, 3, true) {
+		t.Errorf("Incorrect 2 of 3 timeout witness size %d", size)
+	}
+}
@@ -2,20 +2,25 @@ package multiwallet
 
 import (
 	"errors"
-	"github.com/muecoin/multiwallet/monetaryunit"
 	"strings"
 	"time"
 
-	"github.com/muecoin/multiwallet/bitcoin"
-	"github.com/muecoin/multiwallet/bitcoincash"
+	_ "github.com/muecoin/multiwallet/bitcoin"
+	_ "github.com/muecoin/multiwallet/bitcoincash"
 	"github.com/muecoin/multiwallet/client/blockbook"
 	"github.com/muecoin/multiwallet/config"
-	"github.com/muecoin/multiwallet/litecoin"
+	"github.com/muecoin/multiwallet/datastore"
+	_ "github.com/muecoin/multiwallet/dogecoin"
+	"github.com/muecoin/multiwallet/ethereum"
+	"github.com/muecoin/multiwallet/htlc"
+	_ "github.com/muecoin/multiwallet/litecoin"
+	_ "github.com/muecoin/multiwallet/monetaryunit"
+	"github.com/muecoin/multiwallet/rates"
+	"github.com/muecoin/multiwallet/registry"
 	"github.com/muecoin/multiwallet/service"
-	"github.com/muecoin/multiwallet/zcash"
+	_ "github.com/muecoin/multiwallet/zcash"
 	"github.com/muecoin/multiwallet/util"
 	"github.com/OpenBazaar/wallet-interface"
-	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/op/go-logging"
 	"github.com/tyler-smith/go-bip39"
 )
@@ -26,10 +31,16 @@ var UnsuppertedCoinError = errors.New("multiwallet does not contain an implement
 
 type MultiWallet map[util.ExtCoinType]wallet.Wallet
 
+// NewMultiWallet constructs the wallets of the coins of cfg. The built-in coins
+// are registered by this package, other coins by importing their package.
+// Coins missing from the registry are skipped.
 func NewMultiWallet(cfg *config.Config) (MultiWallet, error) {
 	log.SetBackend(logging.AddModuleLevel(cfg.Logger))
 	service.Log = log
+	htlc.Log = log
 	blockbook.Log = log
+	rates.Log = log
+	ethereum.Log = log
 
 	if cfg.Mnemonic == "" {
 		ent, err := bip39.NewEntropy(128)
@@ -45,73 +56,16 @@ func NewMultiWallet(cfg *config.Config) (MultiWallet, error) {
 	}
 
 	multiwallet := make(MultiWallet)
-	var err error
 	for _, coin := range cfg.Coins {
-		var w wallet.Wallet
-		switch coin.CoinType {
-		case util.CoinTypeMonetaryUnit:
-			var params chaincfg.Params
-			if cfg.Params.Name == monetaryunit.MonetaryUnitMainNetParams.Name {
-				params = monetaryunit.MonetaryUnitMainNetParams
-			} else {
-				params = monetaryunit.MonetaryUnitTestNetParams
-			}
-			w, err = monetaryunit.NewMonetaryUnitWallet(coin, cfg.Mnemonic, &params, cfg.Proxy, cfg.Cache, cfg.DisableExchangeRates)
-			if err != nil {
-				return nil, err
-			}
-			if cfg.Params.Name == monetaryunit.MonetaryUnitMainNetParams.Name {
-				multiwallet[util.CoinTypeMonetaryUnit] = w
-			} else {
-				multiwallet[util.CoinTypeMonetaryUnitTest] = w
-			}
-		case util.ExtendCoinType(wallet.Bitcoin):
-			w, err = bitcoin.NewBitcoinWallet(coin, cfg.Mnemonic, cfg.Params, cfg.Proxy, cfg.Cache, cfg.DisableExchangeRates)
-			if err != nil {
-				return nil, err
-			}
-			if cfg.Params.Name == chaincfg.MainNetParams.Name {
-				multiwallet[util.ExtendCoinType(wallet.Bitcoin)] = w
-			} else {
-				multiwallet[util.ExtendCoinType(wallet.TestnetBitcoin)] = w
-			}
-		case util.ExtendCoinType(wallet.BitcoinCash):
-			w, err = bitcoincash.NewBitcoinCashWallet(coin, cfg.Mnemonic, cfg.Params, cfg.Proxy, cfg.Cache, cfg.DisableExchangeRates)
-			if err != nil {
-				return nil, err
-			}
-			if cfg.Params.Name == chaincfg.MainNetParams.Name {
-				multiwallet[util.ExtendCoinType(wallet.BitcoinCash)] = w
-			} else {
-				multiwallet[util.ExtendCoinType(wallet.TestnetBitcoinCash)] = w
-			}
-		case util.ExtendCoinType(wallet.Zcash):
-			w, err = zcash.NewZCashWallet(coin, cfg.Mnemonic, cfg.Params, cfg.Proxy, cfg.Cache, cfg.DisableExchangeRates)
-			if err != nil {
-				return nil, err
-			}
-			if cfg.Params.Name == chaincfg.MainNetParams.Name {
-				multiwallet[util.ExtendCoinType(wallet.Zcash)] = w
-			} else {
-				multiwallet[util.ExtendCoinType(wallet.TestnetZcash)] = w
-			}
-		case util.ExtendCoinType(wallet.Litecoin):
-			w, err = litecoin.NewLitecoinWallet(coin, cfg.Mnemonic, cfg.Params, cfg.Proxy, cfg.Cache, cfg.DisableExchangeRates)
-			if err != nil {
-				return nil, err
-			}
-			if cfg.Params.Name == chaincfg.MainNetParams.Name {
-				multiwallet[util.ExtendCoinType(wallet.Litecoin)] = w
-			} else {
-				multiwallet[util.ExtendCoinType(wallet.TestnetLitecoin)] = w
-			}
-			//case wallet.Ethereum:
-			//w, err = eth.NewEthereumWallet(coin, cfg.Mnemonic, cfg.Proxy)
-			//if err != nil {
-			//return nil, err
-			//}
-			//multiwallet[coin.CoinType] = w
+		c, ok := registry.Lookup(coin.CoinType)
+		if !ok {
+			continue
 		}
+		w, err := c.NewWallet(coin, cfg.Mnemonic, c.NetParams(cfg.Params), cfg.Proxy, cfg.Cache, cfg.DisableExchangeRates)
+		if err != nil {
+			return nil, err
+		}
+		multiwallet[c.WalletType(cfg.Params)] = w
 	}
 	return multiwallet, nil
 }
@@ -136,3 +90,38 @@ func (w *MultiWallet) WalletForCurrencyCode(currencyCode string) (wallet.Wallet,
 	}
 	return nil, UnsuppertedCoinError
 }
+
+// NewSwapper returns the atomic swap coordinator of the coins supporting hash
+// time locked contracts, resuming the swaps saved in records.
+func (w *MultiWallet) NewSwapper(records datastore.Records, limits htlc.LockLimits) (*htlc.Swapper, error) {
+	var wallets []htlc.Wallet
+	for _, wl := range *w {
+		if hw, ok := wl.(htlc.Wallet); ok {
+			wallets = append(wallets, hw)
+		}
+	}
+	return htlc.NewSwapper(wallets, records, limits)
+}
+
+type transactionExporter interface {
+	ExportTransactions(currency string) ([]service.ExportRow, error)
+}
+
+// ExportTransactions returns the transactions of every wallet from the oldest,
+// valued in currency. The balance of each row is the balance of its coin.
+func (w *MultiWallet) ExportTransactions(currency string) ([]service.ExportRow, error) {
+	var rows []service.ExportRow
+	for _, wl := range *w {
+		exporter, ok := wl.(transactionExporter)
+		if !ok {
+			continue
+		}
+		r, err := exporter.ExportTransactions(currency)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, r...)
+	}
+	service.SortExportRows(rows)
+	return rows, nil
+}
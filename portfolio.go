@@ -0,0 +1,62 @@
+package multiwallet
+
+import (
+	"sort"
+
+	"github.com/muecoin/multiwallet/rates"
+	"github.com/muecoin/multiwallet/util"
+)
+
+// Holding is the balance of a coin and its value in a fiat currency
+type Holding struct {
+	CoinType     util.ExtCoinType
+	CurrencyCode string
+
+	// Confirmed and Unconfirmed are in base units of the coin
+	Confirmed   int64
+	Unconfirmed int64
+
+	// Rate is the rate the balance was valued at. If the coin couldn't be
+	// valued Err says why and the fiat values are zero.
+	Rate            rates.Rate
+	FiatConfirmed   float64
+	FiatUnconfirmed float64
+	Err             error
+}
+
+// Portfolio is the value of every coin of a MultiWallet in a fiat currency
+type Portfolio struct {
+	Currency string
+	Holdings []Holding
+
+	// The totals add up the coins that could be valued
+	TotalConfirmed   float64
+	TotalUnconfirmed float64
+}
+
+// Portfolio returns the balance of every coin valued in currency. A coin
+// without a usable rate is reported with its error and left out of the
+// totals.
+func (w *MultiWallet) Portfolio(currency string) Portfolio {
+	p := Portfolio{Currency: rates.NormalizeCurrencyCode(currency)}
+	for ct, wl := range *w {
+		confirmed, unconfirmed := wl.Balance()
+		h := Holding{
+			CoinType:     ct,
+			CurrencyCode: wl.CurrencyCode(),
+			Confirmed:    confirmed,
+			Unconfirmed:  unconfirmed,
+		}
+		h.FiatConfirmed, h.Rate, h.Err = rates.Value(wl.ExchangeRates(), confirmed, currency)
+		if h.Err == nil {
+			h.FiatUnconfirmed = float64(unconfirmed) / float64(wl.ExchangeRates().UnitsPerCoin()) * h.Rate.Value
+			p.TotalConfirmed += h.FiatConfirmed
+			p.TotalUnconfirmed += h.FiatUnconfirmed
+		}
+		p.Holdings = append(p.Holdings, h)
+	}
+	sort.Slice(p.Holdings, func(i, j int) bool {
+		return p.Holdings[i].CurrencyCode < p.Holdings[j].CurrencyCode
+	})
+	return p
+}
@@ -0,0 +1,126 @@
+package rates
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/OpenBazaar/wallet-interface"
+)
+
+var (
+	// ErrRatesDisabled is returned when converting through a wallet whose
+	// exchange rates are disabled.
+	ErrRatesDisabled = errors.New("exchange rates are disabled")
+
+	// ErrSlippage is returned when the rate moved further from the expected
+	// rate than the slippage tolerance.
+	ErrSlippage = errors.New("exchange rate moved beyond the slippage tolerance")
+)
+
+// FiatAmount is an amount in a fiat currency to convert to base units of a
+// coin.
+type FiatAmount struct {
+	Currency string
+	Amount   float64
+
+	// MaxAge is the maximum age of the rate. Older rates are refreshed, and
+	// the conversion fails if the refreshed rate is still too old. Zero
+	// accepts the age limit of the exchange rates.
+	MaxAge time.Duration
+
+	// ExpectedRate, if set, is the rate the amount was agreed at. The
+	// conversion fails if the current rate differs from it by more than the
+	// MaxSlippage fraction of it.
+	ExpectedRate float64
+	MaxSlippage  float64
+}
+
+// Conversion is the result of converting a FiatAmount
+type Conversion struct {
+	// Amount is the converted amount in base units of the coin
+	Amount   int64
+	Currency string
+	Rate     Rate
+}
+
+// Convert converts fiat to base units of the coin priced by er
+func Convert(er wallet.ExchangeRates, fiat FiatAmount) (Conversion, error) {
+	if er == nil {
+		return Conversion{}, ErrRatesDisabled
+	}
+	if fiat.Amount <= 0 || math.IsInf(fiat.Amount, 0) || math.IsNaN(fiat.Amount) {
+		return Conversion{}, errors.New("fiat amount must be positive")
+	}
+	currency := NormalizeCurrencyCode(fiat.Currency)
+	rate, err := currentRate(er, currency, fiat.MaxAge)
+	if err != nil {
+		return Conversion{}, err
+	}
+	if fiat.ExpectedRate > 0 && math.Abs(rate.Value-fiat.ExpectedRate) > fiat.MaxSlippage*fiat.ExpectedRate {
+		return Conversion{}, ErrSlippage
+	}
+	return Conversion{
+		Amount:   int64(math.Round(fiat.Amount / rate.Value * float64(er.UnitsPerCoin()))),
+		Currency: currency,
+		Rate:     rate,
+	}, nil
+}
+
+// Value returns the value in currency of amount base units of the coin priced
+// by er, and the rate it was valued at.
+func Value(er wallet.ExchangeRates, amount int64, currency string) (float64, Rate, error) {
+	if er == nil {
+		return 0, Rate{}, ErrRatesDisabled
+	}
+	rate, err := currentRate(er, NormalizeCurrencyCode(currency), 0)
+	if err != nil {
+		return 0, Rate{}, err
+	}
+	return float64(amount) / float64(er.UnitsPerCoin()) * rate.Value, rate, nil
+}
+
+// currentRate returns the rate of currency, refreshing it if it is stale or
+// older than maxAge. Rates of a wallet.ExchangeRates other than a Fetcher
+// have no timestamp and are always refreshed when maxAge is set.
+func currentRate(er wallet.ExchangeRates, currency string, maxAge time.Duration) (Rate, error) {
+	fresh := func(rate Rate) bool {
+		return maxAge == 0 || time.Since(rate.Time) <= maxAge
+	}
+	if f, ok := er.(*Fetcher); ok {
+		rate, err := f.Rate(currency)
+		if err == nil && fresh(rate) {
+			return rate, nil
+		}
+		if err != nil && err != ErrStaleRate && err != ErrCurrencyNotTracked {
+			return Rate{}, err
+		}
+		if err := f.Refresh(); err != nil {
+			return Rate{}, err
+		}
+		if rate, err = f.Rate(currency); err != nil {
+			return Rate{}, err
+		}
+		if !fresh(rate) {
+			return Rate{}, ErrStaleRate
+		}
+		return rate, nil
+	}
+
+	var (
+		value float64
+		err   error
+	)
+	if maxAge == 0 {
+		value, err = er.GetExchangeRate(currency)
+	} else {
+		value, err = er.GetLatestRate(currency)
+	}
+	if err != nil {
+		return Rate{}, err
+	}
+	if value <= 0 {
+		return Rate{}, ErrCurrencyNotTracked
+	}
+	return Rate{Value: value, Time: time.Now()}, nil
+}
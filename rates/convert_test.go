@@ -0,0 +1,87 @@
+package rates
+
+import (
+	"testing"
+	"time"
+)
+
+type staticRates map[string]float64
+
+func (r staticRates) GetExchangeRate(currencyCode string) (float64, error) {
+	return r[currencyCode], nil
+}
+
+func (r staticRates) GetLatestRate(currencyCode string) (float64, error) {
+	return r[currencyCode], nil
+}
+
+func (r staticRates) GetAllRates(cacheOK bool) (map[string]float64, error) {
+	return r, nil
+}
+
+func (r staticRates) UnitsPerCoin() int {
+	return 100000000
+}
+
+func TestConvert(t *testing.T) {
+	server := standIn(t, `{"USD":{"last":50000},"EUR":{"last":45000}}`)
+	defer server.Close()
+	f := NewFetcher(Config{Providers: []Provider{mustParseProvider(t, "blockchain+"+server.URL)}})
+
+	conv, err := Convert(f, FiatAmount{Currency: "usd", Amount: 25.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conv.Amount != 51000 || conv.Currency != "USD" || conv.Rate.Value != 50000 {
+		t.Errorf("Unexpected conversion %+v", conv)
+	}
+
+	// A rate older than the maximum age is refreshed
+	f.lock.Lock()
+	f.rates["EUR"] = Rate{Value: 40000, Time: time.Now().Add(-time.Hour)}
+	f.lock.Unlock()
+	conv, err = Convert(f, FiatAmount{Currency: "EUR", Amount: 45, MaxAge: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conv.Rate.Value != 45000 || conv.Amount != 100000 {
+		t.Errorf("Converted at a stale rate %+v", conv)
+	}
+
+	if _, err := Convert(f, FiatAmount{Currency: "USD", Amount: 10, ExpectedRate: 52000, MaxSlippage: 0.01}); err != ErrSlippage {
+		t.Errorf("Expected ErrSlippage but had %v", err)
+	}
+	if _, err := Convert(f, FiatAmount{Currency: "USD", Amount: 10, ExpectedRate: 50400, MaxSlippage: 0.01}); err != nil {
+		t.Errorf("Rejected a rate within the slippage tolerance: %v", err)
+	}
+	if _, err := Convert(f, FiatAmount{Currency: "JPY", Amount: 10}); err != ErrCurrencyNotTracked {
+		t.Errorf("Expected ErrCurrencyNotTracked but had %v", err)
+	}
+	if _, err := Convert(f, FiatAmount{Currency: "USD", Amount: -1}); err == nil {
+		t.Error("Converted a negative amount")
+	}
+	if _, err := Convert(nil, FiatAmount{Currency: "USD", Amount: 10}); err != ErrRatesDisabled {
+		t.Errorf("Expected ErrRatesDisabled but had %v", err)
+	}
+
+	conv, err = Convert(staticRates{"USD": 200}, FiatAmount{Currency: "USD", Amount: 50, MaxAge: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conv.Amount != 25000000 {
+		t.Errorf("Expected 25000000 but had %d", conv.Amount)
+	}
+}
+
+func TestValue(t *testing.T) {
+	value, rate, err := Value(staticRates{"EUR": 200}, 150000000, "eur")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value != 300 || rate.Value != 200 {
+		t.Errorf("Expected a value of 300 but had %f", value)
+	}
+	if _, _, err := Value(staticRates{}, 1, "USD"); err != ErrCurrencyNotTracked {
+		t.Errorf("Expected ErrCurrencyNotTracked but had %v", err)
+	}
+}
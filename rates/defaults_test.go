@@ -0,0 +1,36 @@
+package rates_test
+
+import (
+	"testing"
+
+	_ "github.com/muecoin/multiwallet/bitcoin"
+	_ "github.com/muecoin/multiwallet/bitcoincash"
+	_ "github.com/muecoin/multiwallet/dogecoin"
+	_ "github.com/muecoin/multiwallet/ethereum"
+	_ "github.com/muecoin/multiwallet/litecoin"
+	_ "github.com/muecoin/multiwallet/monetaryunit"
+	"github.com/muecoin/multiwallet/rates"
+	"github.com/muecoin/multiwallet/registry"
+	"github.com/muecoin/multiwallet/util"
+	_ "github.com/muecoin/multiwallet/zcash"
+)
+
+func TestDefaultProviders(t *testing.T) {
+	coins := registry.Coins()
+	if len(coins) == 0 {
+		t.Fatal("No coins registered")
+	}
+	for _, c := range coins {
+		for _, ct := range []util.ExtCoinType{c.CoinType, c.TestnetCoinType} {
+			entries := rates.DefaultProviders(ct)
+			if len(entries) == 0 {
+				t.Errorf("No default providers for %s", c.Name)
+			}
+			for _, entry := range entries {
+				if _, err := rates.ParseProvider(entry); err != nil {
+					t.Errorf("Failed to parse default provider %s: %s", entry, err)
+				}
+			}
+		}
+	}
+}
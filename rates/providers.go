@@ -0,0 +1,259 @@
+package rates
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/muecoin/multiwallet/registry"
+	"github.com/muecoin/multiwallet/util"
+)
+
+// ProviderType identifies the API spoken by a price provider in
+// CoinConfig.PriceAPIs.
+type ProviderType string
+
+const (
+	ProviderBitPay        ProviderType = "bitpay"
+	ProviderBlockchain    ProviderType = "blockchain"
+	ProviderCoinGecko     ProviderType = "coingecko"
+	ProviderCoinMarketCap ProviderType = "coinmarketcap"
+	ProviderKraken        ProviderType = "kraken"
+	ProviderBitfinex      ProviderType = "bitfinex"
+)
+
+// Provider quotes the price of a coin
+type Provider interface {
+	// Fetch returns the price of one coin in each currency the provider
+	// quotes, keyed by upper case currency code.
+	Fetch(client *http.Client) (map[string]float64, error)
+	String() string
+}
+
+// DefaultProviders returns the providers queried for coin when its
+// CoinConfig.PriceAPIs is empty, the PriceProviders of its registered
+// descriptor.
+func DefaultProviders(coin util.ExtCoinType) []string {
+	if c, ok := registry.Lookup(coin); ok {
+		return c.PriceProviders
+	}
+	return nil
+}
+
+// ParseProvider returns the provider of a PriceAPIs entry. The API of the
+// provider is declared with a scheme prefix such as
+// "coingecko+https://api.coingecko.com/api/v3/coins/litecoin" or
+// "kraken+https://api.kraken.com/0/public/Ticker?pair=LTCXBT". Kraken and
+// Bitfinex quote the currency ending their pair name.
+func ParseProvider(entry string) (Provider, error) {
+	i := strings.Index(entry, "+")
+	if i < 0 || i > strings.Index(entry, "://") {
+		return nil, fmt.Errorf("price provider without type: %s", entry)
+	}
+	var (
+		typ    = ProviderType(strings.ToLower(entry[:i]))
+		rawURL = entry[i+1:]
+	)
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	p := &httpProvider{typ: typ, url: rawURL}
+	switch typ {
+	case ProviderBitPay:
+		p.decode = decodeBitPay
+	case ProviderBlockchain:
+		p.decode = decodeBlockchain
+	case ProviderCoinGecko:
+		p.decode = decodeCoinGecko
+	case ProviderCoinMarketCap:
+		p.decode = decodeCoinMarketCap
+	case ProviderKraken:
+		quote, err := pairQuote(u.Query().Get("pair"))
+		if err != nil {
+			return nil, err
+		}
+		p.decode = func(body []byte) (map[string]float64, error) {
+			return decodeKraken(body, quote)
+		}
+	case ProviderBitfinex:
+		quote, err := pairQuote(path.Base(u.Path))
+		if err != nil {
+			return nil, err
+		}
+		p.decode = func(body []byte) (map[string]float64, error) {
+			return decodeBitfinex(body, quote)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported price provider type: %s", typ)
+	}
+	return p, nil
+}
+
+// pairQuote returns the quote currency of a trading pair such as LTCXBT
+func pairQuote(pair string) (string, error) {
+	if len(pair) < 6 {
+		return "", fmt.Errorf("invalid trading pair: %s", pair)
+	}
+	quote := strings.ToUpper(pair[len(pair)-3:])
+	if quote == "XBT" {
+		quote = "BTC"
+	}
+	return quote, nil
+}
+
+type httpProvider struct {
+	typ    ProviderType
+	url    string
+	decode func(body []byte) (map[string]float64, error)
+}
+
+func (p *httpProvider) String() string {
+	return string(p.typ) + "+" + p.url
+}
+
+func (p *httpProvider) Fetch(client *http.Client) (map[string]float64, error) {
+	resp, err := client.Get(p.url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned status %d", p.typ, resp.StatusCode)
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	quote, err := p.decode(body)
+	if err != nil {
+		return nil, fmt.Errorf("decoding %s quote: %s", p.typ, err.Error())
+	}
+	if len(quote) == 0 {
+		return nil, fmt.Errorf("%s returned no quote", p.typ)
+	}
+	return quote, nil
+}
+
+// decodeBitPay decodes a list of rates, bare or under a data field
+func decodeBitPay(body []byte) (map[string]float64, error) {
+	type rate struct {
+		Code string  `json:"code"`
+		Rate float64 `json:"rate"`
+	}
+	var list []rate
+	if err := json.Unmarshal(body, &list); err != nil {
+		var wrapped struct {
+			Data []rate `json:"data"`
+		}
+		if err := json.Unmarshal(body, &wrapped); err != nil {
+			return nil, err
+		}
+		list = wrapped.Data
+	}
+	quote := make(map[string]float64)
+	for _, r := range list {
+		quote[NormalizeCurrencyCode(r.Code)] = r.Rate
+	}
+	return quote, nil
+}
+
+func decodeBlockchain(body []byte) (map[string]float64, error) {
+	var ticker map[string]struct {
+		Last float64 `json:"last"`
+	}
+	if err := json.Unmarshal(body, &ticker); err != nil {
+		return nil, err
+	}
+	quote := make(map[string]float64)
+	for c, t := range ticker {
+		quote[NormalizeCurrencyCode(c)] = t.Last
+	}
+	return quote, nil
+}
+
+func decodeCoinGecko(body []byte) (map[string]float64, error) {
+	var coin struct {
+		MarketData struct {
+			CurrentPrice map[string]float64 `json:"current_price"`
+		} `json:"market_data"`
+	}
+	if err := json.Unmarshal(body, &coin); err != nil {
+		return nil, err
+	}
+	quote := make(map[string]float64)
+	for c, price := range coin.MarketData.CurrentPrice {
+		quote[NormalizeCurrencyCode(c)] = price
+	}
+	return quote, nil
+}
+
+func decodeCoinMarketCap(body []byte) (map[string]float64, error) {
+	var ticker struct {
+		Data struct {
+			Quotes map[string]struct {
+				Price float64 `json:"price"`
+			} `json:"quotes"`
+		} `json:"data"`
+		Metadata struct {
+			Error *string `json:"error"`
+		} `json:"metadata"`
+	}
+	if err := json.Unmarshal(body, &ticker); err != nil {
+		return nil, err
+	}
+	if ticker.Metadata.Error != nil {
+		return nil, errors.New(*ticker.Metadata.Error)
+	}
+	quote := make(map[string]float64)
+	for c, q := range ticker.Data.Quotes {
+		quote[NormalizeCurrencyCode(c)] = q.Price
+	}
+	return quote, nil
+}
+
+func decodeKraken(body []byte, currency string) (map[string]float64, error) {
+	var ticker struct {
+		Error  []string `json:"error"`
+		Result map[string]struct {
+			Close []string `json:"c"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(body, &ticker); err != nil {
+		return nil, err
+	}
+	if len(ticker.Error) > 0 {
+		return nil, errors.New(strings.Join(ticker.Error, ", "))
+	}
+	for _, pair := range ticker.Result {
+		if len(pair.Close) == 0 {
+			break
+		}
+		price, err := strconv.ParseFloat(pair.Close[0], 64)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]float64{currency: price}, nil
+	}
+	return nil, errors.New("missing last trade price")
+}
+
+func decodeBitfinex(body []byte, currency string) (map[string]float64, error) {
+	var ticker struct {
+		LastPrice string `json:"last_price"`
+	}
+	if err := json.Unmarshal(body, &ticker); err != nil {
+		return nil, err
+	}
+	price, err := strconv.ParseFloat(ticker.LastPrice, 64)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]float64{currency: price}, nil
+}
@@ -0,0 +1,78 @@
+package rates
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestParseProvider(t *testing.T) {
+	for _, entry := range []string{
+		"https://api.coingecko.com/api/v3/coins/litecoin",
+		"poloniex+https://poloniex.com/public?command=returnTicker",
+		"kraken+https://api.kraken.com/0/public/Ticker",
+	} {
+		if _, err := ParseProvider(entry); err == nil {
+			t.Errorf("Parsed invalid entry %s", entry)
+		}
+	}
+}
+
+func TestProviders(t *testing.T) {
+	tests := []struct {
+		typ   string
+		path  string
+		body  string
+		quote map[string]float64
+	}{
+		{
+			"blockchain", "/ticker",
+			`{"USD":{"15m":50010,"last":50000},"EUR":{"last":45000}}`,
+			map[string]float64{"USD": 50000, "EUR": 45000},
+		},
+		{
+			"bitpay", "/api/rates/bch",
+			`{"data":[{"code":"BTC","name":"Bitcoin","rate":0.006},{"code":"usd","name":"US Dollar","rate":300}]}`,
+			map[string]float64{"BTC": 0.006, "USD": 300},
+		},
+		{
+			"coinmarketcap", "/v2/ticker/706/",
+			`{"data":{"quotes":{"USD":{"price":0.01},"BTC":{"price":0.0000002}}},"metadata":{"error":null}}`,
+			map[string]float64{"USD": 0.01, "BTC": 0.0000002},
+		},
+		{
+			"bitfinex", "/v1/pubticker/zecusd",
+			`{"last_price":"35.5"}`,
+			map[string]float64{"USD": 35.5},
+		},
+		{
+			"kraken", "/0/public/Ticker?pair=XBTEUR",
+			`{"error":[],"result":{"XXBTZEUR":{"c":["45000.1","0.01"]}}}`,
+			map[string]float64{"EUR": 45000.1},
+		},
+	}
+	for _, test := range tests {
+		server := standIn(t, test.body)
+		p := mustParseProvider(t, test.typ+"+"+server.URL+test.path)
+		quote, err := p.Fetch(http.DefaultClient)
+		server.Close()
+		if err != nil {
+			t.Errorf("%s: %s", test.typ, err)
+			continue
+		}
+		if len(quote) != len(test.quote) {
+			t.Errorf("%s: expected quote %v but had %v", test.typ, test.quote, quote)
+			continue
+		}
+		for c, v := range test.quote {
+			if !closeTo(quote[c], v) {
+				t.Errorf("%s: expected quote %v but had %v", test.typ, test.quote, quote)
+			}
+		}
+	}
+
+	server := standIn(t, `{"error":["EQuery:Unknown asset pair"]}`)
+	defer server.Close()
+	if _, err := mustParseProvider(t, "kraken+"+server.URL+"?pair=FOOXBT").Fetch(http.DefaultClient); err == nil {
+		t.Error("Returned a quote of a Kraken error")
+	}
+}
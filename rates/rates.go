@@ -0,0 +1,444 @@
+// Package rates fetches the exchange rates of the wallet coins from several
+// price providers and aggregates them into one rate per currency.
+package rates
+
+import (
+	"errors"
+	"math"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/muecoin/multiwallet/util"
+	"github.com/OpenBazaar/wallet-interface"
+	"github.com/op/go-logging"
+	"golang.org/x/net/proxy"
+)
+
+var Log = logging.MustGetLogger("rates")
+
+const (
+	// DefaultInterval is how often Run refreshes the rates
+	DefaultInterval = 15 * time.Minute
+
+	// DefaultMaxAge is the age after which a rate is stale
+	DefaultMaxAge = time.Hour
+
+	// DefaultMaxDeviation is the largest relative distance from the median
+	// of the quotes of a currency at which a quote isn't an outlier.
+	DefaultMaxDeviation = 0.1
+)
+
+var (
+	// ErrCurrencyNotTracked is returned for a currency no provider quotes
+	ErrCurrencyNotTracked = errors.New("currency not tracked")
+
+	// ErrStaleRate is returned for a rate older than the maximum age
+	ErrStaleRate = errors.New("exchange rate is stale")
+
+	// ErrNoQuotes is returned when no provider returned a usable quote
+	ErrNoQuotes = errors.New("all exchange rate providers failed")
+)
+
+// Rate is the price of one coin in a currency, and the time it was fetched
+type Rate struct {
+	Value float64
+	Time  time.Time
+}
+
+// Config configures a Fetcher
+type Config struct {
+	// Providers are queried on every refresh and their quotes aggregated
+	Providers []Provider
+
+	// Reference, if set, prices the currencies some providers don't quote.
+	// It is typically the Bitcoin fetcher, to convert quotes of a coin in
+	// bitcoin to fiat currencies.
+	Reference *Fetcher
+
+	// Client is the HTTP client of the providers
+	Client *http.Client
+
+	// UnitsPerCoin is the number of base units in one coin. It defaults to
+	// 100 million.
+	UnitsPerCoin int
+
+	// Interval, MaxAge and MaxDeviation default to DefaultInterval,
+	// DefaultMaxAge and DefaultMaxDeviation.
+	Interval     time.Duration
+	MaxAge       time.Duration
+	MaxDeviation float64
+}
+
+// Fetcher aggregates the quotes of its providers into the exchange rates of a
+// coin. It implements wallet.ExchangeRates.
+type Fetcher struct {
+	cfg Config
+
+	fetchLock sync.Mutex
+	lock      sync.RWMutex
+	rates     map[string]Rate
+
+	done     chan struct{}
+	stopOnce sync.Once
+	release  func()
+}
+
+var _ wallet.ExchangeRates = (*Fetcher)(nil)
+
+// NewFetcher returns a Fetcher of the rates quoted by cfg.Providers. The rates
+// are fetched on the first request or once Run is started.
+func NewFetcher(cfg Config) *Fetcher {
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{Timeout: time.Minute}
+	}
+	if cfg.UnitsPerCoin == 0 {
+		cfg.UnitsPerCoin = 100000000
+	}
+	if cfg.Interval == 0 {
+		cfg.Interval = DefaultInterval
+	}
+	if cfg.MaxAge == 0 {
+		cfg.MaxAge = DefaultMaxAge
+	}
+	if cfg.MaxDeviation == 0 {
+		cfg.MaxDeviation = DefaultMaxDeviation
+	}
+	return &Fetcher{cfg: cfg, rates: make(map[string]Rate), done: make(chan struct{})}
+}
+
+// NewPriceFetcher returns the Fetcher of the rates of coin. Each entry of
+// providers names its API with a scheme prefix, as parsed by ParseProvider.
+// If providers is empty the default providers of the coin are used. Coins
+// other than Bitcoin convert their quotes through a Bitcoin Fetcher.
+func NewPriceFetcher(coin util.ExtCoinType, providers []string, dialer proxy.Dialer) (*Fetcher, error) {
+	if len(providers) == 0 {
+		providers = DefaultProviders(coin)
+	}
+	cfg := Config{Client: newHTTPClient(dialer)}
+	if coin.ToCoinType() == wallet.Ethereum {
+		// The Ethereum wallet counts in gwei
+		cfg.UnitsPerCoin = 1000000000
+	}
+	for _, entry := range providers {
+		p, err := ParseProvider(entry)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Providers = append(cfg.Providers, p)
+	}
+	if coin.ToCoinType() == wallet.Bitcoin || coin.ToCoinType() == wallet.TestnetBitcoin {
+		return NewFetcher(cfg), nil
+	}
+	ref, release, err := bitcoinReference(dialer)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Reference = ref
+	f := NewFetcher(cfg)
+	f.release = release
+	return f, nil
+}
+
+type sharedReference struct {
+	fetcher *Fetcher
+	users   int
+}
+
+var (
+	referenceLock sync.Mutex
+	references    = make(map[proxy.Dialer]*sharedReference)
+)
+
+// bitcoinReference returns the Bitcoin Fetcher converting the quotes of the
+// coins fetched through dialer, and the function releasing it. The coins share
+// one reference so that the bitcoin rates are fetched once per refresh.
+func bitcoinReference(dialer proxy.Dialer) (*Fetcher, func(), error) {
+	referenceLock.Lock()
+	defer referenceLock.Unlock()
+	ref, ok := references[dialer]
+	if !ok {
+		f, err := NewPriceFetcher(util.ExtendCoinType(wallet.Bitcoin), nil, dialer)
+		if err != nil {
+			return nil, nil, err
+		}
+		ref = &sharedReference{fetcher: f}
+		references[dialer] = ref
+	}
+	ref.users++
+	release := func() {
+		referenceLock.Lock()
+		defer referenceLock.Unlock()
+		ref.users--
+		if ref.users == 0 {
+			delete(references, dialer)
+		}
+	}
+	return ref.fetcher, release, nil
+}
+
+func newHTTPClient(dialer proxy.Dialer) *http.Client {
+	dial := net.Dial
+	if dialer != nil {
+		dial = dialer.Dial
+	}
+	return &http.Client{Transport: &http.Transport{Dial: dial}, Timeout: time.Minute}
+}
+
+// Run refreshes the rates at the configured interval until Stop is called
+func (f *Fetcher) Run() {
+	ticker := time.NewTicker(f.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-f.done:
+			return
+		default:
+		}
+		f.Refresh()
+		select {
+		case <-f.done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Stop ends Run and releases the Bitcoin reference of the fetcher. It may be
+// called more than once, and whether or not Run was started.
+func (f *Fetcher) Stop() {
+	f.stopOnce.Do(func() {
+		close(f.done)
+		if f.release != nil {
+			f.release()
+		}
+	})
+}
+
+// Refresh fetches the quotes of every provider and replaces the rates of the
+// currencies they agree on.
+func (f *Fetcher) Refresh() error {
+	f.fetchLock.Lock()
+	defer f.fetchLock.Unlock()
+
+	var ref map[string]float64
+	if f.cfg.Reference != nil {
+		var err error
+		if ref, err = f.cfg.Reference.GetAllRates(true); err != nil || len(ref) == 0 {
+			if ref, err = f.cfg.Reference.GetAllRates(false); err != nil {
+				Log.Warningf("fetching reference exchange rates: %s", err.Error())
+			}
+		}
+	}
+
+	var (
+		quotes = make([]map[string]float64, len(f.cfg.Providers))
+		wg     sync.WaitGroup
+	)
+	for i, p := range f.cfg.Providers {
+		wg.Add(1)
+		go func(i int, p Provider) {
+			defer wg.Done()
+			q, err := p.Fetch(f.cfg.Client)
+			if err != nil {
+				Log.Warningf("fetching exchange rates from %s: %s", p.String(), err.Error())
+				return
+			}
+			quotes[i] = crossRates(q, ref)
+		}(i, p)
+	}
+	wg.Wait()
+
+	aggregated := aggregate(quotes, f.cfg.MaxDeviation)
+	if len(aggregated) == 0 {
+		return ErrNoQuotes
+	}
+	now := time.Now()
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	for currency, value := range aggregated {
+		f.rates[currency] = Rate{Value: value, Time: now}
+	}
+	return nil
+}
+
+// Rate returns the rate of currency with the time it was fetched. A stale
+// rate is returned with ErrStaleRate.
+func (f *Fetcher) Rate(currency string) (Rate, error) {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	rate, ok := f.rates[NormalizeCurrencyCode(currency)]
+	if !ok {
+		return Rate{}, ErrCurrencyNotTracked
+	}
+	if time.Since(rate.Time) > f.cfg.MaxAge {
+		return rate, ErrStaleRate
+	}
+	return rate, nil
+}
+
+// CrossRate returns the units of currency to that one unit of currency from
+// buys, as implied by the rates of the coin in both.
+func (f *Fetcher) CrossRate(from, to string) (float64, error) {
+	fromRate, err := f.Rate(from)
+	if err != nil {
+		return 0, err
+	}
+	toRate, err := f.Rate(to)
+	if err != nil {
+		return 0, err
+	}
+	return toRate.Value / fromRate.Value, nil
+}
+
+// GetExchangeRate returns the cached rate of currencyCode
+func (f *Fetcher) GetExchangeRate(currencyCode string) (float64, error) {
+	rate, err := f.Rate(currencyCode)
+	if err != nil {
+		return 0, err
+	}
+	return rate.Value, nil
+}
+
+// GetLatestRate refreshes the rates and returns the rate of currencyCode
+func (f *Fetcher) GetLatestRate(currencyCode string) (float64, error) {
+	if err := f.Refresh(); err != nil {
+		return 0, err
+	}
+	return f.GetExchangeRate(currencyCode)
+}
+
+// GetAllRates returns the rates of every currency, refreshing them first
+// unless cacheOK. Stale rates are left out, and ErrStaleRate is returned if
+// every rate is stale.
+func (f *Fetcher) GetAllRates(cacheOK bool) (map[string]float64, error) {
+	if !cacheOK {
+		if err := f.Refresh(); err != nil {
+			return nil, err
+		}
+	}
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	rates := make(map[string]float64, len(f.rates))
+	for currency, rate := range f.rates {
+		if time.Since(rate.Time) <= f.cfg.MaxAge {
+			rates[currency] = rate.Value
+		}
+	}
+	if len(rates) == 0 && len(f.rates) > 0 {
+		return nil, ErrStaleRate
+	}
+	return rates, nil
+}
+
+// UnitsPerCoin returns the number of base units in one coin
+func (f *Fetcher) UnitsPerCoin() int {
+	return f.cfg.UnitsPerCoin
+}
+
+// crossRates adds to quote the currencies of ref it lacks. ref holds the
+// price of a reference coin, so the price of the quoted coin in a currency c
+// is its price in a quoted currency a times ref[c]/ref[a].
+func crossRates(quote, ref map[string]float64) map[string]float64 {
+	if len(ref) == 0 {
+		return quote
+	}
+	anchor := ""
+	for _, a := range anchorCurrencies(quote) {
+		if ref[a] > 0 {
+			anchor = a
+			break
+		}
+	}
+	if anchor == "" {
+		return quote
+	}
+	crossed := make(map[string]float64, len(ref))
+	for c, v := range ref {
+		if v > 0 {
+			crossed[c] = quote[anchor] * v / ref[anchor]
+		}
+	}
+	for c, v := range quote {
+		crossed[c] = v
+	}
+	return crossed
+}
+
+// anchorCurrencies orders the currencies of quote by how much their quotes
+// are trusted to convert to other currencies.
+func anchorCurrencies(quote map[string]float64) []string {
+	currencies := make([]string, 0, len(quote))
+	for c := range quote {
+		currencies = append(currencies, c)
+	}
+	rank := func(c string) int {
+		switch c {
+		case "USD":
+			return 0
+		case "BTC":
+			return 1
+		case "EUR":
+			return 2
+		}
+		return 3
+	}
+	sort.Slice(currencies, func(i, j int) bool {
+		if rank(currencies[i]) != rank(currencies[j]) {
+			return rank(currencies[i]) < rank(currencies[j])
+		}
+		return currencies[i] < currencies[j]
+	})
+	return currencies
+}
+
+// aggregate returns the median quote of each currency after rejecting the
+// quotes further than maxDeviation from the median of all quotes. When the
+// quotes of a currency are all outliers, as when two providers disagree, the
+// quote of the first provider quoting it is kept.
+func aggregate(quotes []map[string]float64, maxDeviation float64) map[string]float64 {
+	values := make(map[string][]float64)
+	for _, q := range quotes {
+		for c, v := range q {
+			if v > 0 && !math.IsInf(v, 0) && !math.IsNaN(v) {
+				values[c] = append(values[c], v)
+			}
+		}
+	}
+	rates := make(map[string]float64, len(values))
+	for c, vs := range values {
+		m := median(vs)
+		var kept []float64
+		for _, v := range vs {
+			if math.Abs(v-m) <= maxDeviation*m {
+				kept = append(kept, v)
+			}
+		}
+		if len(kept) == 0 {
+			Log.Warningf("the exchange rate providers disagree on %s (%v): using the primary quote", c, vs)
+			rates[c] = vs[0]
+			continue
+		}
+		rates[c] = median(kept)
+	}
+	return rates
+}
+
+func median(values []float64) float64 {
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+// NormalizeCurrencyCode standardizes the format for the given currency code
+func NormalizeCurrencyCode(currencyCode string) string {
+	return strings.ToUpper(currencyCode)
+}
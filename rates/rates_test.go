@@ -0,0 +1,201 @@
+package rates
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/muecoin/multiwallet/util"
+	"github.com/OpenBazaar/wallet-interface"
+)
+
+// standIn serves a fixed response in place of a price API
+func standIn(t *testing.T, body string) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, body)
+	}))
+}
+
+func mustParseProvider(t *testing.T, entry string) Provider {
+	t.Helper()
+	p, err := ParseProvider(entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func closeTo(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9*math.Max(1, math.Abs(b))
+}
+
+func TestAggregate(t *testing.T) {
+	quotes := []map[string]float64{
+		{"USD": 100, "EUR": 90},
+		{"USD": 102, "EUR": 91},
+		{"USD": 98},
+		{"USD": 500, "EUR": 0},
+		nil,
+	}
+	rates := aggregate(quotes, 0.1)
+	if !closeTo(rates["USD"], 100) {
+		t.Errorf("Expected USD rate 100 but had %f", rates["USD"])
+	}
+	if !closeTo(rates["EUR"], 90.5) {
+		t.Errorf("Expected EUR rate 90.5 but had %f", rates["EUR"])
+	}
+
+	// Of diverging quotes the first provider's is kept
+	rates = aggregate([]map[string]float64{nil, {"USD": 100}, {"USD": 200}}, 0.1)
+	if !closeTo(rates["USD"], 100) {
+		t.Errorf("Expected the primary USD rate 100 of diverging quotes but had %f", rates["USD"])
+	}
+}
+
+func TestCrossRates(t *testing.T) {
+	ref := map[string]float64{"USD": 50000, "EUR": 45000, "BTC": 1}
+	quote := crossRates(map[string]float64{"BTC": 0.002}, ref)
+	if !closeTo(quote["USD"], 100) || !closeTo(quote["EUR"], 90) || quote["BTC"] != 0.002 {
+		t.Errorf("Unexpected quote %v", quote)
+	}
+
+	// Quoted currencies are kept and anchor the conversion
+	quote = crossRates(map[string]float64{"USD": 110, "BTC": 0.002}, ref)
+	if quote["USD"] != 110 || !closeTo(quote["EUR"], 99) {
+		t.Errorf("Unexpected quote %v", quote)
+	}
+
+	quote = crossRates(map[string]float64{"JPY": 15000}, ref)
+	if len(quote) != 1 {
+		t.Errorf("Converted a quote without a reference currency %v", quote)
+	}
+}
+
+func TestFetcher(t *testing.T) {
+	btc := standIn(t, `[{"code":"BTC","rate":1},{"code":"USD","rate":50000},{"code":"EUR","rate":45000}]`)
+	defer btc.Close()
+	kraken := standIn(t, `{"error":[],"result":{"XLTCXXBT":{"c":["0.002","1.5"]}}}`)
+	defer kraken.Close()
+	gecko := standIn(t, `{"market_data":{"current_price":{"usd":102,"eur":91.8}}}`)
+	defer gecko.Close()
+	broken := standIn(t, `{"market_data":{"current_price":{"usd":900}}}`)
+	defer broken.Close()
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer down.Close()
+
+	ref := NewFetcher(Config{Providers: []Provider{mustParseProvider(t, "bitpay+"+btc.URL)}})
+	f := NewFetcher(Config{
+		Providers: []Provider{
+			mustParseProvider(t, "kraken+"+kraken.URL+"/0/public/Ticker?pair=LTCXBT"),
+			mustParseProvider(t, "coingecko+"+gecko.URL),
+			mustParseProvider(t, "coingecko+"+broken.URL),
+			mustParseProvider(t, "bitfinex+"+down.URL+"/v1/pubticker/ltcbtc"),
+		},
+		Reference: ref,
+	})
+
+	if _, err := f.GetExchangeRate("USD"); err != ErrCurrencyNotTracked {
+		t.Errorf("Expected ErrCurrencyNotTracked before fetching but had %v", err)
+	}
+	usd, err := f.GetLatestRate("usd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The kraken quote converts to 100 USD, the outlier of 900 is rejected
+	if !closeTo(usd, 101) {
+		t.Errorf("Expected USD rate 101 but had %f", usd)
+	}
+	rate, err := f.Rate("BTC")
+	if err != nil || !closeTo(rate.Value, 0.00202) || time.Since(rate.Time) > time.Minute {
+		t.Errorf("Unexpected BTC rate %+v (%v)", rate, err)
+	}
+	cross, err := f.CrossRate("USD", "EUR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !closeTo(cross, 90.9/101) {
+		t.Errorf("Unexpected USD/EUR cross-rate %f", cross)
+	}
+
+	all, err := f.GetAllRates(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 3 {
+		t.Errorf("Expected 3 rates but had %v", all)
+	}
+
+	// Rates past their maximum age are stale
+	f.cfg.MaxAge = time.Nanosecond
+	time.Sleep(time.Millisecond)
+	if _, err := f.GetExchangeRate("USD"); err != ErrStaleRate {
+		t.Errorf("Expected ErrStaleRate but had %v", err)
+	}
+	if _, err := f.GetAllRates(true); err != ErrStaleRate {
+		t.Errorf("Expected ErrStaleRate from GetAllRates but had %v", err)
+	}
+}
+
+func TestFetcher_NoQuotes(t *testing.T) {
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer down.Close()
+	f := NewFetcher(Config{Providers: []Provider{mustParseProvider(t, "blockchain+"+down.URL)}})
+	if err := f.Refresh(); err != ErrNoQuotes {
+		t.Errorf("Expected ErrNoQuotes but had %v", err)
+	}
+}
+
+func TestFetcher_Stop(t *testing.T) {
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer down.Close()
+	f := NewFetcher(Config{
+		Providers: []Provider{mustParseProvider(t, "blockchain+"+down.URL)},
+		Interval:  time.Millisecond,
+	})
+	stopped := make(chan struct{})
+	go func() {
+		f.Run()
+		close(stopped)
+	}()
+	f.Stop()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after Stop")
+	}
+	f.Stop()
+}
+
+func TestNewPriceFetcher_SharedReference(t *testing.T) {
+	ltc, err := NewPriceFetcher(util.ExtendCoinType(wallet.Litecoin), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zec, err := NewPriceFetcher(util.ExtendCoinType(wallet.Zcash), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ltc.cfg.Reference == nil || ltc.cfg.Reference != zec.cfg.Reference {
+		t.Fatal("Expected the coins to share their bitcoin reference")
+	}
+	ltc.Stop()
+	zec.Stop()
+	bch, err := NewPriceFetcher(util.ExtendCoinType(wallet.BitcoinCash), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bch.Stop()
+	if bch.cfg.Reference == ltc.cfg.Reference {
+		t.Error("Expected a released reference to be discarded")
+	}
+}
@@ -0,0 +1,210 @@
+// Package registry holds the coins the multiwallet can construct. Each coin
+// package registers a descriptor of itself when it is imported, so a program
+// adds a coin, including one maintained outside this repository, by importing
+// its package:
+//
+//	import _ "github.com/muecoin/multiwallet/dogecoin"
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/muecoin/multiwallet/cache"
+	"github.com/muecoin/multiwallet/config"
+	"github.com/muecoin/multiwallet/util"
+	"github.com/OpenBazaar/wallet-interface"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcutil"
+	"golang.org/x/net/proxy"
+)
+
+// MainNet is the name of the bitcoin network parameters selecting the main
+// network of every coin.
+var MainNet = chaincfg.MainNetParams.Name
+
+// NewWalletFunc constructs the wallet of a coin. params are the network
+// parameters of the coin returned by Coin.NetParams.
+type NewWalletFunc func(cfg config.CoinConfig, mnemonic string, params *chaincfg.Params, proxy proxy.Dialer, cache cache.Cacher, disableExchangeRates bool) (wallet.Wallet, error)
+
+// Coin describes a coin to the multiwallet
+type Coin struct {
+	// The display name of the coin, such as "Bitcoin Cash". Lookups by name
+	// ignore case, spaces, dashes and underscores.
+	Name string
+
+	// The coin types of the coin on the main network and on the test networks.
+	// Coins without a distinct testnet type, such as Ethereum, repeat
+	// CoinType.
+	CoinType        util.ExtCoinType
+	TestnetCoinType util.ExtCoinType
+
+	// The currency codes of the coin on the main network and on the test
+	// networks, such as "BTC" and "TBTC"
+	CurrencyCode        string
+	TestnetCurrencyCode string
+
+	// The network parameters of the coin keyed by the name of the bitcoin
+	// network parameters they stand for. If nil, or if a network is missing,
+	// the bitcoin parameters are used as they are.
+	Params map[string]*chaincfg.Params
+
+	// NewWallet constructs the wallet of the coin
+	NewWallet NewWalletFunc
+
+	// PriceProviders are the exchange rate providers queried when the
+	// PriceAPIs of the coin config are empty, in the format parsed by
+	// rates.ParseProvider.
+	PriceProviders []string
+
+	// BlockInterval is the target time between two blocks of the coin. The
+	// atomic swaps use it to turn lock durations into block heights, and
+	// take ten minutes if it is zero.
+	BlockInterval time.Duration
+
+	// Address codecs of the coin. Each is optional; the wallet service needs
+	// ScriptToAddress to follow watched scripts.
+	DecodeAddress   func(addr string, params *chaincfg.Params) (btcutil.Address, error)
+	ScriptToAddress func(script []byte, params *chaincfg.Params) (btcutil.Address, error)
+	AddressToScript func(addr btcutil.Address) ([]byte, error)
+}
+
+// NetParams returns the network parameters of the coin on the network of net
+func (c *Coin) NetParams(net *chaincfg.Params) *chaincfg.Params {
+	if params, ok := c.Params[net.Name]; ok {
+		return params
+	}
+	return net
+}
+
+// IsMainNet reports whether net selects the main network of the coin
+func (c *Coin) IsMainNet(net *chaincfg.Params) bool {
+	if net.Name == MainNet {
+		return true
+	}
+	main, ok := c.Params[MainNet]
+	return ok && c.NetParams(net) == main
+}
+
+// WalletType returns the coin type of the wallet of the coin on net, the key
+// of the wallet in a multiwallet
+func (c *Coin) WalletType(net *chaincfg.Params) util.ExtCoinType {
+	if c.IsMainNet(net) {
+		return c.CoinType
+	}
+	return c.TestnetCoinType
+}
+
+var (
+	coinsMu sync.RWMutex
+	coins   = make(map[util.ExtCoinType]*Coin)
+)
+
+// Register makes a coin available to the multiwallet. It is meant to be
+// called from the init function of the coin package and panics if the coin
+// is incomplete or if its name or one of its coin types is taken.
+func Register(c Coin) {
+	coinsMu.Lock()
+	defer coinsMu.Unlock()
+	if c.Name == "" || c.NewWallet == nil {
+		panic(fmt.Sprintf("registry: coin %d has no name or wallet constructor", c.CoinType))
+	}
+	for _, ct := range []util.ExtCoinType{c.CoinType, c.TestnetCoinType} {
+		if dup, ok := coins[ct]; ok {
+			panic(fmt.Sprintf("registry: coin type %d of %s is registered to %s", ct, c.Name, dup.Name))
+		}
+	}
+	for _, dup := range coins {
+		if normalize(dup.Name) == normalize(c.Name) {
+			panic("registry: Register called twice for " + c.Name)
+		}
+	}
+	coin := &c
+	coins[c.CoinType] = coin
+	coins[c.TestnetCoinType] = coin
+	util.RegisterCoinType(c.CoinType, c.Name, c.CurrencyCode)
+	if c.TestnetCoinType != c.CoinType {
+		util.RegisterCoinType(c.TestnetCoinType, "Testnet "+c.Name, c.TestnetCurrencyCode)
+	}
+}
+
+// Lookup returns the coin with the mainnet or testnet coin type ct
+func Lookup(ct util.ExtCoinType) (*Coin, bool) {
+	coinsMu.RLock()
+	defer coinsMu.RUnlock()
+	c, ok := coins[ct]
+	return c, ok
+}
+
+// ByName returns the coin named name. Case, spaces, dashes and underscores
+// are ignored, so "Bitcoin Cash", "bitcoincash" and "BITCOIN_CASH" all name
+// Bitcoin Cash.
+func ByName(name string) (*Coin, bool) {
+	coinsMu.RLock()
+	defer coinsMu.RUnlock()
+	name = normalize(name)
+	for _, c := range coins {
+		if normalize(c.Name) == name {
+			return c, true
+		}
+	}
+	return nil, false
+}
+
+// ByCurrencyCode returns the coin whose mainnet or testnet currency code is
+// code, ignoring case.
+func ByCurrencyCode(code string) (*Coin, bool) {
+	coinsMu.RLock()
+	defer coinsMu.RUnlock()
+	for _, c := range coins {
+		if code != "" && (strings.EqualFold(c.CurrencyCode, code) || strings.EqualFold(c.TestnetCurrencyCode, code)) {
+			return c, true
+		}
+	}
+	return nil, false
+}
+
+// Coins returns the registered coins ordered by coin type
+func Coins() []*Coin {
+	coinsMu.RLock()
+	defer coinsMu.RUnlock()
+	var list []*Coin
+	for ct, c := range coins {
+		if ct == c.CoinType {
+			list = append(list, c)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].CoinType < list[j].CoinType
+	})
+	return list
+}
+
+// AddressToScript returns the output script paying addr, built by the codec
+// of the first coin, in coin type order, which accepts the address. It serves
+// the clients which watch addresses without knowing their coin.
+func AddressToScript(addr btcutil.Address) ([]byte, error) {
+	for _, c := range Coins() {
+		if c.AddressToScript == nil {
+			continue
+		}
+		if script, err := c.AddressToScript(addr); err == nil {
+			return script, nil
+		}
+	}
+	return nil, errors.New("unsupported address type")
+}
+
+func normalize(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '_':
+			return -1
+		}
+		return r
+	}, strings.ToLower(name))
+}
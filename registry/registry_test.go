@@ -0,0 +1,174 @@
+package registry
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/muecoin/multiwallet/cache"
+	"github.com/muecoin/multiwallet/config"
+	"github.com/muecoin/multiwallet/util"
+	"github.com/OpenBazaar/wallet-interface"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcutil"
+	"golang.org/x/net/proxy"
+)
+
+var (
+	testMainNetParams = chaincfg.Params{Name: "mainTestCoin"}
+	testTestNetParams = chaincfg.Params{Name: "testTestCoin"}
+)
+
+func newTestWallet(config.CoinConfig, string, *chaincfg.Params, proxy.Dialer, cache.Cacher, bool) (wallet.Wallet, error) {
+	return nil, errors.New("not implemented")
+}
+
+func testAddressToScript(addr btcutil.Address) ([]byte, error) {
+	if _, ok := addr.(*btcutil.AddressScriptHash); !ok {
+		return nil, errors.New("not a script hash")
+	}
+	return append([]byte{0xa9, 0x14}, addr.ScriptAddress()...), nil
+}
+
+func init() {
+	Register(Coin{
+		Name:                "Test Coin",
+		CoinType:            900001,
+		TestnetCoinType:     1900001,
+		CurrencyCode:        "TST",
+		TestnetCurrencyCode: "TTST",
+		Params: map[string]*chaincfg.Params{
+			MainNet:                      &testMainNetParams,
+			testMainNetParams.Name:       &testMainNetParams,
+			chaincfg.TestNet3Params.Name: &testTestNetParams,
+		},
+		NewWallet:       newTestWallet,
+		AddressToScript: testAddressToScript,
+	})
+	Register(Coin{
+		Name:            "Same Coin",
+		CoinType:        900002,
+		TestnetCoinType: 900002,
+		NewWallet:       newTestWallet,
+	})
+}
+
+func TestLookup(t *testing.T) {
+	for _, ct := range []util.ExtCoinType{900001, 1900001} {
+		c, ok := Lookup(ct)
+		if !ok || c.Name != "Test Coin" {
+			t.Errorf("coin type %d not found", ct)
+		}
+	}
+	if _, ok := Lookup(900003); ok {
+		t.Error("found unregistered coin type")
+	}
+}
+
+func TestByName(t *testing.T) {
+	for _, name := range []string{"Test Coin", "testcoin", "TEST_COIN", "test-coin"} {
+		c, ok := ByName(name)
+		if !ok || c.CoinType != 900001 {
+			t.Errorf("coin %q not found", name)
+		}
+	}
+	if _, ok := ByName("Test Coins"); ok {
+		t.Error("found unregistered coin name")
+	}
+}
+
+func TestByCurrencyCode(t *testing.T) {
+	for _, code := range []string{"TST", "tst", "TTST"} {
+		c, ok := ByCurrencyCode(code)
+		if !ok || c.CoinType != 900001 {
+			t.Errorf("currency code %q not found", code)
+		}
+	}
+	for _, code := range []string{"", "TS"} {
+		if _, ok := ByCurrencyCode(code); ok {
+			t.Errorf("found unregistered currency code %q", code)
+		}
+	}
+}
+
+func TestAddressToScript(t *testing.T) {
+	p2sh, err := btcutil.NewAddressScriptHashFromHash(make([]byte, 20), &testMainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if script, err := AddressToScript(p2sh); err != nil || len(script) != 22 {
+		t.Errorf("unexpected script %x (%v)", script, err)
+	}
+	p2pkh, err := btcutil.NewAddressPubKeyHash(make([]byte, 20), &testMainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := AddressToScript(p2pkh); err == nil {
+		t.Error("expected an address no coin accepts to fail")
+	}
+}
+
+func TestCoins(t *testing.T) {
+	coins := Coins()
+	if len(coins) != 2 {
+		t.Fatalf("expected 2 coins, got %d", len(coins))
+	}
+	if coins[0].CoinType != 900001 || coins[1].CoinType != 900002 {
+		t.Error("coins not ordered by coin type")
+	}
+}
+
+func TestCoin_WalletType(t *testing.T) {
+	c, _ := Lookup(900001)
+	tests := []struct {
+		net        *chaincfg.Params
+		params     *chaincfg.Params
+		walletType util.ExtCoinType
+	}{
+		{&chaincfg.MainNetParams, &testMainNetParams, 900001},
+		{&testMainNetParams, &testMainNetParams, 900001},
+		{&chaincfg.TestNet3Params, &testTestNetParams, 1900001},
+		{&chaincfg.RegressionNetParams, &chaincfg.RegressionNetParams, 1900001},
+	}
+	for _, test := range tests {
+		if params := c.NetParams(test.net); params != test.params {
+			t.Errorf("%s: expected params %s, got %s", test.net.Name, test.params.Name, params.Name)
+		}
+		if walletType := c.WalletType(test.net); walletType != test.walletType {
+			t.Errorf("%s: expected wallet type %d, got %d", test.net.Name, test.walletType, walletType)
+		}
+	}
+
+	same, _ := Lookup(900002)
+	if same.WalletType(&chaincfg.TestNet3Params) != 900002 {
+		t.Error("expected the mainnet type of a coin without testnet type")
+	}
+}
+
+func TestRegister_CoinTypeNames(t *testing.T) {
+	ct := util.ExtCoinType(1900001)
+	if ct.String() != "Testnet Test Coin" || ct.CurrencyCode() != "TTST" {
+		t.Errorf("unexpected name %q and currency code %q", ct.String(), ct.CurrencyCode())
+	}
+}
+
+func TestRegister_Duplicate(t *testing.T) {
+	tests := []Coin{
+		{Name: "Other Coin", CoinType: 900001, TestnetCoinType: 1900003, NewWallet: newTestWallet},
+		{Name: "Other Coin", CoinType: 900003, TestnetCoinType: 1900001, NewWallet: newTestWallet},
+		{Name: "test coin", CoinType: 900003, TestnetCoinType: 1900003, NewWallet: newTestWallet},
+		{Name: "Other Coin", CoinType: 900003, TestnetCoinType: 1900003},
+	}
+	for i, c := range tests {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("test %d: expected Register to panic", i)
+				}
+			}()
+			Register(c)
+		}()
+	}
+	if _, ok := Lookup(900003); ok {
+		t.Error("rejected coin was registered")
+	}
+}
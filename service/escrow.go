@@ -0,0 +1,146 @@
+package service
+
+import (
+	"encoding/hex"
+
+	"github.com/muecoin/multiwallet/multisig"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// EscrowTimeout is sent to the escrow timeout listeners once an output of a
+// watched escrow can be spent through the timeout branch of its redeem script.
+type EscrowTimeout struct {
+	RedeemScript []byte
+	Outpoint     wire.OutPoint
+	Value        int64
+
+	// Height is the height the output confirmed at and SpendableHeight the
+	// first block a transaction spending it through the timeout may be
+	// mined in.
+	Height          uint32
+	SpendableHeight uint32
+}
+
+type watchedEscrow struct {
+	redeemScript []byte
+	blocks       uint32
+}
+
+// WatchEscrowTimeout notifies the escrow timeout listeners once the outputs
+// paying scriptPubKey can be spent through the timeout branch of the redeem
+// script. The address must also be watched for its outputs to be synced.
+// Watched escrows are kept in memory only and each output is notified once
+// per run of the wallet.
+func (ws *WalletService) WatchEscrowTimeout(scriptPubKey []byte, redeemScript []byte) error {
+	rs, err := multisig.ParseRedeemScript(redeemScript)
+	if err != nil {
+		return err
+	}
+	blocks, err := rs.TimeoutBlocks()
+	if err != nil {
+		return err
+	}
+	ws.escrowLock.Lock()
+	ws.escrows[hex.EncodeToString(scriptPubKey)] = watchedEscrow{redeemScript: redeemScript, blocks: blocks}
+	ws.escrowLock.Unlock()
+
+	go ws.checkEscrowTimeouts()
+	return nil
+}
+
+func (ws *WalletService) AddEscrowTimeoutListener(callback func(EscrowTimeout)) {
+	ws.escrowLock.Lock()
+	defer ws.escrowLock.Unlock()
+	ws.escrowListeners = append(ws.escrowListeners, callback)
+}
+
+// checkEscrowTimeouts notifies the listeners of the outputs of watched
+// escrows whose timeout matured since the last check.
+func (ws *WalletService) checkEscrowTimeouts() {
+	ws.escrowLock.Lock()
+	empty := len(ws.escrows) == 0
+	ws.escrowLock.Unlock()
+	if empty {
+		return
+	}
+
+	utxos, err := ws.db.Utxos().GetAll()
+	if err != nil {
+		Log.Errorf("error loading %s utxos from db: %s", ws.coinType.String(), err.Error())
+		return
+	}
+	tip, _ := ws.ChainTip()
+
+	var matured []EscrowTimeout
+	ws.escrowLock.Lock()
+	for _, u := range utxos {
+		escrow, ok := ws.escrows[hex.EncodeToString(u.ScriptPubkey)]
+		if !ok || u.AtHeight <= 0 || ws.escrowsNotified[u.Op] {
+			continue
+		}
+		if !multisig.TimeoutMatured(uint32(u.AtHeight), tip, escrow.blocks) {
+			continue
+		}
+		ws.escrowsNotified[u.Op] = true
+		matured = append(matured, EscrowTimeout{
+			RedeemScript:    escrow.redeemScript,
+			Outpoint:        u.Op,
+			Value:           u.Value,
+			Height:          uint32(u.AtHeight),
+			SpendableHeight: uint32(u.AtHeight) + escrow.blocks,
+		})
+	}
+	listeners := ws.escrowListeners
+	ws.escrowLock.Unlock()
+
+	for _, timeout := range matured {
+		Log.Noticef("%s escrow output %s can be spent by its timeout key", ws.coinType.String(), timeout.Outpoint)
+		for _, l := range listeners {
+			go l(timeout)
+		}
+	}
+}
+
+// CheckEscrowTimeout returns multisig.ErrTimeoutNotMatured unless each of the
+// outpoints was confirmed long enough ago to be spent through a timeout of
+// blocks in the next block.
+func (ws *WalletService) CheckEscrowTimeout(outpoints []wire.OutPoint, blocks uint32) error {
+	utxos, err := ws.db.Utxos().GetAll()
+	if err != nil {
+		return err
+	}
+	heights := make(map[wire.OutPoint]int32)
+	for _, u := range utxos {
+		heights[u.Op] = u.AtHeight
+	}
+	tip, _ := ws.ChainTip()
+	for _, op := range outpoints {
+		height, ok := heights[op]
+		if !ok {
+			if height, err = ws.confirmationHeight(op.Hash, tip); err != nil {
+				return err
+			}
+		}
+		if height <= 0 || !multisig.TimeoutMatured(uint32(height), tip, blocks) {
+			return multisig.ErrTimeoutNotMatured
+		}
+	}
+	return nil
+}
+
+// confirmationHeight returns the height a transaction confirmed at, or zero if
+// it is unconfirmed, looking it up from the API if it is not in the db.
+func (ws *WalletService) confirmationHeight(txid chainhash.Hash, tip uint32) (int32, error) {
+	if txn, err := ws.db.Txns().Get(txid); err == nil {
+		return txn.Height, nil
+	}
+	tx, err := ws.client.GetTransaction(txid.String())
+	if err != nil {
+		return 0, err
+	}
+	if tx.Confirmations <= 0 {
+		return 0, nil
+	}
+	return int32(tip) - (int32(tx.Confirmations) - 1), nil
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/muecoin/multiwallet/multisig"
+	"github.com/OpenBazaar/wallet-interface"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+func TestWalletService_EscrowTimeout(t *testing.T) {
+	ws, err := mockWalletService()
+	if err != nil {
+		t.Fatal(err)
+	}
+	builder := txscript.NewScriptBuilder().AddOp(txscript.OP_IF).AddOp(txscript.OP_2)
+	for _, key := range []string{
+		"03c157f2a7c178430972263232c9306110090c50b44d4e906ecd6d377eec89a53c",
+		"0205b02b9dbe570f36d1c12e3100e55586b2b9dc61d6778c1d24a8eaca03625e7e",
+		"030c83b025cd6bdd8c06e93a2b953b821b4a8c29da211335048d7dc3389706d7e8",
+	} {
+		b, _ := hex.DecodeString(key)
+		builder.AddData(b)
+	}
+	timeoutKey, _ := hex.DecodeString("0205b02b9dbe570f36d1c12e3100e55586b2b9dc61d6778c1d24a8eaca03625e7e")
+	redeemScript, err := builder.AddOp(txscript.OP_3).AddOp(txscript.OP_CHECKMULTISIG).
+		AddOp(txscript.OP_ELSE).AddInt64(144).AddOp(txscript.OP_CHECKSEQUENCEVERIFY).AddOp(txscript.OP_DROP).
+		AddData(timeoutKey).AddOp(txscript.OP_CHECKSIG).AddOp(txscript.OP_ENDIF).Script()
+	if err != nil {
+		t.Fatal(err)
+	}
+	scriptPubKey := []byte{txscript.OP_0, txscript.OP_DATA_32}
+	scriptPubKey = append(scriptPubKey, make([]byte, 32)...)
+
+	op := *wire.NewOutPoint(&chainhash.Hash{1}, 0)
+	if err := ws.db.Utxos().Put(wallet.Utxo{Op: op, Value: 5000, ScriptPubkey: scriptPubKey, AtHeight: 900, WatchOnly: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	notifications := make(chan EscrowTimeout, 2)
+	ws.AddEscrowTimeoutListener(func(timeout EscrowTimeout) {
+		notifications <- timeout
+	})
+	ws.chainHeight = 1000
+	if err := ws.WatchEscrowTimeout(scriptPubKey, redeemScript); err != nil {
+		t.Fatal(err)
+	}
+	if err := ws.CheckEscrowTimeout([]wire.OutPoint{op}, 144); err != multisig.ErrTimeoutNotMatured {
+		t.Errorf("Expected ErrTimeoutNotMatured but had %v", err)
+	}
+
+	ws.lock.Lock()
+	ws.chainHeight = 1043
+	ws.lock.Unlock()
+	ws.checkEscrowTimeouts()
+	select {
+	case timeout := <-notifications:
+		if timeout.Outpoint != op || timeout.Height != 900 || timeout.SpendableHeight != 1044 || timeout.Value != 5000 {
+			t.Errorf("Incorrect escrow timeout notification %+v", timeout)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for the escrow timeout notification")
+	}
+	if err := ws.CheckEscrowTimeout([]wire.OutPoint{op}, 144); err != nil {
+		t.Error(err)
+	}
+
+	// Each output is only notified once
+	ws.checkEscrowTimeouts()
+	select {
+	case <-notifications:
+		t.Error("Escrow timeout notified twice")
+	case <-time.After(time.Second / 10):
+	}
+
+	if err := ws.WatchEscrowTimeout(scriptPubKey, redeemScript[1:]); err == nil {
+		t.Error("Watched a redeem script without a timeout")
+	}
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/muecoin/multiwallet/datastore"
+	"github.com/muecoin/multiwallet/util"
+	"github.com/OpenBazaar/wallet-interface"
+)
+
+// ExportRow is a transaction of the wallet as recorded in an accounting
+// export. Amounts are in satoshis.
+type ExportRow struct {
+	CoinType  util.ExtCoinType
+	Coin      string
+	Time      time.Time
+	Txid      string
+	Direction Direction
+
+	// Amount is the change of the wallet balance, fee included
+	Amount int64
+
+	// Fee is the fee paid by the wallet, which is zero for incoming
+	// transactions. FeeKnown is false if the backend didn't return the value
+	// of every input of a transaction the wallet spent from.
+	Fee      int64
+	FeeKnown bool
+
+	// Balance is the wallet balance after the transaction
+	Balance int64
+	Label   string
+
+	// FiatValue is the value of Amount in Currency at the rate recorded
+	// closest to the transaction time. FiatKnown is false if no rate was
+	// recorded within a day of it.
+	FiatValue float64
+	FiatKnown bool
+	Currency  string
+}
+
+// ExportTransactions returns the transactions of the wallet from the oldest,
+// valued in currency. Watch-only and dead transactions are left out as they
+// don't change the balance.
+func (ws *WalletService) ExportTransactions(currency string) ([]ExportRow, error) {
+	txns, _, err := datastore.QueryTxns(ws.db.Txns(), datastore.TxnQuery{})
+	if err != nil {
+		return nil, err
+	}
+	var (
+		rows      []ExportRow
+		balance   int64
+		satoshis  = util.SatoshisPerCoin(ws.coinType.ToCoinType())
+		height, _ = ws.ChainTip()
+	)
+	currency = strings.ToUpper(currency)
+	for i := len(txns) - 1; i >= 0; i-- {
+		txn := txns[i]
+		setTxStatus(&txn, height)
+		if txn.Status == wallet.StatusDead {
+			continue
+		}
+		balance += txn.Value
+		row := ExportRow{
+			CoinType: ws.coinType,
+			Coin:     ws.coinType.CurrencyCode(),
+			Time:     txn.Timestamp,
+			Txid:     txn.Txid,
+			Amount:   txn.Value,
+			Balance:  balance,
+			Currency: currency,
+		}
+		if idx, ok := ws.txIndex(txn.Txid); ok {
+			row.Direction = idx.Direction
+			row.FeeKnown = idx.Direction == Incoming || idx.FeeKnown
+			if idx.Direction != Incoming {
+				row.Fee = idx.Fee
+			}
+		} else {
+			row.Direction = Incoming
+			if txn.Value < 0 {
+				row.Direction = Outgoing
+			}
+		}
+		if md, ok := ws.TxMetadata(txn.Txid); ok {
+			row.Label = strings.Join(md.Labels, ";")
+		}
+		if currency != "" {
+			if rate, ok := ws.RateAt(currency, txn.Timestamp); ok {
+				row.FiatValue = float64(txn.Value) / satoshis * rate
+				row.FiatKnown = true
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// SortExportRows orders the rows of the exports of several wallets by time
+func SortExportRows(rows []ExportRow) {
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].Time.Before(rows[j].Time)
+	})
+}
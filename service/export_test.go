@@ -0,0 +1,65 @@
+package service
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/muecoin/multiwallet/model/mock"
+)
+
+func TestWalletService_ExportTransactions(t *testing.T) {
+	ws, err := mockWalletService()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ws.chainHeight = uint32(mock.MockBlocks[0].Height)
+	ws.bestBlock = mock.MockBlocks[0].Hash
+	ws.ProcessIncomingTransaction(mock.MockTransactions[0])
+	ws.ProcessIncomingTransaction(mock.MockTransactions[1])
+	if err := ws.SetTxMetadata(TxMetadata{Txid: mock.MockTransactions[1].Txid, Labels: []string{"rent", "march"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ws.RecordRates(time.Unix(3600, 0), map[string]float64{"usd": 20000}); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := ws.ExportTransactions("usd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("Expected 2 rows but had %d", len(rows))
+	}
+	in, out := rows[0], rows[1]
+	if in.Txid != mock.MockTransactions[0].Txid || out.Txid != mock.MockTransactions[1].Txid {
+		t.Fatal("Returned rows out of order")
+	}
+	if in.Direction != Incoming || in.Amount != 2717080 || in.Balance != 2717080 {
+		t.Errorf("Unexpected incoming row %+v", in)
+	}
+	if !in.FeeKnown || in.Fee != 0 {
+		t.Error("Returned a fee paid by the wallet for an incoming transaction")
+	}
+	if out.Direction != Outgoing || out.Amount != -1717080 || out.Balance != 1000000 {
+		t.Errorf("Unexpected outgoing row %+v", out)
+	}
+	if !out.FeeKnown || out.Fee != 100000 {
+		t.Errorf("Expected a fee of 100000 but had %d", out.Fee)
+	}
+	if out.Label != "rent;march" {
+		t.Errorf("Returned incorrect label %s", out.Label)
+	}
+	if !in.FiatKnown || math.Abs(in.FiatValue-543.416) > 1e-6 || in.Currency != "USD" {
+		t.Errorf("Returned incorrect fiat value %f %s", in.FiatValue, in.Currency)
+	}
+
+	// Without a recorded rate the fiat value is unknown
+	rows, err = ws.ExportTransactions("EUR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rows[0].FiatKnown || rows[1].FiatKnown {
+		t.Error("Returned a fiat value without a rate")
+	}
+}
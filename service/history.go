@@ -0,0 +1,234 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/muecoin/multiwallet/datastore"
+	"github.com/muecoin/multiwallet/model"
+	"github.com/muecoin/multiwallet/util"
+	"github.com/OpenBazaar/wallet-interface"
+)
+
+// Direction tells whether a transaction moves coins into, out of or within
+// the wallet.
+type Direction int
+
+const (
+	// AnyDirection doesn't filter transactions by direction
+	AnyDirection Direction = iota
+	// Incoming transactions don't spend coins of the wallet
+	Incoming
+	// Outgoing transactions spend coins of the wallet to other wallets
+	Outgoing
+	// Self transactions only pay the wallet back the coins they spend
+	Self
+)
+
+func (d Direction) String() string {
+	switch d {
+	case Incoming:
+		return "incoming"
+	case Outgoing:
+		return "outgoing"
+	case Self:
+		return "self"
+	default:
+		return "any"
+	}
+}
+
+// TxFilter selects a page of the wallet transactions, most recent first. The
+// zero value of each field doesn't filter, and Limit zero returns every
+// transaction.
+type TxFilter struct {
+	From, To             time.Time
+	MinHeight, MaxHeight int32
+	Direction            Direction
+	Address              string
+	Label                string
+	IncludeWatchOnly     bool
+	MinValue             int64
+	Cursor               string
+	Limit                int
+}
+
+// txIndex is what the wallet records of a transaction to filter it without
+// reading its serialization.
+type txIndex struct {
+	Direction Direction `json:"direction"`
+	Addresses []string  `json:"addresses"`
+	Fee       int64     `json:"fee"`
+	FeeKnown  bool      `json:"feeKnown"`
+}
+
+// TransactionPage returns the transactions selected by filter with their
+// confirmations and status, and the cursor of the next page which is empty
+// after the last page.
+func (ws *WalletService) TransactionPage(filter TxFilter) ([]wallet.Txn, string, error) {
+	q := datastore.TxnQuery{
+		From:             filter.From,
+		To:               filter.To,
+		MinHeight:        filter.MinHeight,
+		MaxHeight:        filter.MaxHeight,
+		IncludeWatchOnly: filter.IncludeWatchOnly,
+		MinValue:         filter.MinValue,
+		Cursor:           filter.Cursor,
+		Limit:            filter.Limit,
+	}
+	if filter.Direction != AnyDirection || filter.Address != "" || filter.Label != "" {
+		q.Match = func(txn wallet.Txn) bool {
+			return ws.matchTx(txn, filter)
+		}
+	}
+	txns, cursor, err := datastore.QueryTxns(ws.db.Txns(), q)
+	if err != nil {
+		return nil, "", err
+	}
+	height, _ := ws.ChainTip()
+	for i := range txns {
+		setTxStatus(&txns[i], height)
+	}
+	return txns, cursor, nil
+}
+
+func (ws *WalletService) matchTx(txn wallet.Txn, filter TxFilter) bool {
+	if filter.Label != "" {
+		md, ok := ws.TxMetadata(txn.Txid)
+		if !ok || !md.HasLabel(filter.Label) {
+			return false
+		}
+	}
+	if filter.Direction == AnyDirection && filter.Address == "" {
+		return true
+	}
+	idx, ok := ws.txIndex(txn.Txid)
+	if !ok {
+		// Transactions saved before the wallet indexed them are reindexed
+		// on the next sync. Until then their value gives their direction.
+		if filter.Address != "" {
+			return false
+		}
+		idx.Direction = Incoming
+		if txn.Value < 0 {
+			idx.Direction = Outgoing
+		}
+	}
+	if filter.Direction != AnyDirection && idx.Direction != filter.Direction {
+		return false
+	}
+	if filter.Address == "" {
+		return true
+	}
+	for _, addr := range idx.Addresses {
+		if addr == filter.Address {
+			return true
+		}
+	}
+	return false
+}
+
+// indexTx records the direction of a transaction, the addresses it spends
+// from and pays, and its fee if the backend returned the value of every input.
+func (ws *WalletService) indexTx(u model.Transaction, addrs map[string]storedAddress) {
+	if !ws.keepsRecords() {
+		return
+	}
+	var (
+		idx      txIndex
+		spends   bool
+		external bool
+		seen     = make(map[string]bool)
+		satoshis = util.SatoshisPerCoin(ws.coinType.ToCoinType())
+	)
+	addAddress := func(addr string) {
+		if addr != "" && !seen[addr] {
+			seen[addr] = true
+			idx.Addresses = append(idx.Addresses, addr)
+		}
+	}
+	idx.FeeKnown = len(u.Inputs) > 0
+	for _, in := range u.Inputs {
+		if in.Value <= 0 {
+			idx.FeeKnown = false
+		}
+		idx.Fee += int64(math.Round(in.Value * satoshis))
+		addAddress(in.Addr)
+		if sa, ok := addrs[in.Addr]; ok && !sa.WatchOnly {
+			spends = true
+		}
+	}
+	for _, out := range u.Outputs {
+		idx.Fee -= int64(math.Round(out.Value * satoshis))
+		if len(out.ScriptPubKey.Addresses) == 0 || out.ScriptPubKey.Addresses[0] == "" {
+			continue
+		}
+		addAddress(out.ScriptPubKey.Addresses[0])
+		if sa, ok := addrs[out.ScriptPubKey.Addresses[0]]; !ok || sa.WatchOnly {
+			external = true
+		}
+	}
+	if !idx.FeeKnown || idx.Fee < 0 {
+		idx.Fee, idx.FeeKnown = 0, false
+	}
+	switch {
+	case !spends:
+		idx.Direction = Incoming
+	case external:
+		idx.Direction = Outgoing
+	default:
+		idx.Direction = Self
+	}
+	b, err := json.Marshal(idx)
+	if err != nil {
+		Log.Errorf("marshaling index of tx (%s): %s", u.Txid, err.Error())
+		return
+	}
+	if err := ws.records.Put(ws.txIndexKey(u.Txid), b); err != nil {
+		Log.Errorf("saving index of tx (%s): %s", u.Txid, err.Error())
+	}
+}
+
+func (ws *WalletService) txIndex(txid string) (txIndex, bool) {
+	var idx txIndex
+	b, err := ws.records.Get(ws.txIndexKey(txid))
+	if err != nil {
+		return idx, false
+	}
+	if err := json.Unmarshal(b, &idx); err != nil {
+		return idx, false
+	}
+	return idx, true
+}
+
+func (ws *WalletService) txIndexKey(txid string) string {
+	return fmt.Sprintf("tx-index-%s-%s", ws.coinType.String(), txid)
+}
+
+// setTxStatus sets the confirmations and status of txn at the chain height
+func setTxStatus(txn *wallet.Txn, height uint32) {
+	var confirmations int32
+	var status wallet.StatusCode
+	confs := int32(height) - txn.Height + 1
+	if txn.Height <= 0 {
+		confs = txn.Height
+	}
+	switch {
+	case confs < 0:
+		status = wallet.StatusDead
+	case confs == 0 && time.Since(txn.Timestamp) <= time.Hour*6:
+		status = wallet.StatusUnconfirmed
+	case confs == 0 && time.Since(txn.Timestamp) > time.Hour*6:
+		status = wallet.StatusDead
+	case confs > 0 && confs < 6:
+		status = wallet.StatusPending
+		confirmations = confs
+	case confs > 5:
+		status = wallet.StatusConfirmed
+		confirmations = confs
+	}
+	txn.Confirmations = int64(confirmations)
+	txn.Status = status
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/muecoin/multiwallet/model/mock"
+)
+
+func TestWalletService_TransactionPage(t *testing.T) {
+	ws, err := mockWalletService()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ws.ProcessIncomingTransaction(mock.MockTransactions[0])
+	ws.ProcessIncomingTransaction(mock.MockTransactions[1])
+
+	txns, cursor, err := ws.TransactionPage(TxFilter{Direction: Incoming})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txns) != 1 || txns[0].Txid != mock.MockTransactions[0].Txid || cursor != "" {
+		t.Errorf("Expected the incoming transaction but had %v", txns)
+	}
+	txns, _, err = ws.TransactionPage(TxFilter{Direction: Outgoing})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txns) != 1 || txns[0].Txid != mock.MockTransactions[1].Txid {
+		t.Errorf("Expected the outgoing transaction but had %v", txns)
+	}
+	txns, _, err = ws.TransactionPage(TxFilter{Address: "38Y6Nt35hQcEDxyCfCEi62QLGPnr4mhANc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txns) != 1 || txns[0].Txid != mock.MockTransactions[1].Txid {
+		t.Errorf("Expected the transaction paying the address but had %v", txns)
+	}
+	// The index is kept by the datastore, not the cache
+	txns, _, err = restart(t, ws).TransactionPage(TxFilter{Address: "38Y6Nt35hQcEDxyCfCEi62QLGPnr4mhANc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txns) != 1 || txns[0].Txid != mock.MockTransactions[1].Txid {
+		t.Errorf("Expected the transaction paying the address after a restart but had %v", txns)
+	}
+	txns, _, err = ws.TransactionPage(TxFilter{Direction: Self})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txns) != 0 {
+		t.Errorf("Expected no self transaction but had %v", txns)
+	}
+
+	// Page through both transactions
+	first, cursor, err := ws.TransactionPage(TxFilter{Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(first) != 1 || cursor == "" {
+		t.Fatal("Failed to return the first page")
+	}
+	second, cursor, err := ws.TransactionPage(TxFilter{Limit: 1, Cursor: cursor})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(second) != 1 || cursor != "" || second[0].Txid == first[0].Txid {
+		t.Error("Failed to return the last page")
+	}
+}
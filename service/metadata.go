@@ -0,0 +1,396 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/muecoin/multiwallet/datastore"
+	"github.com/OpenBazaar/wallet-interface"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcutil"
+)
+
+// ErrUnknownReference is returned when looking up a reference ID no wallet
+// transaction was recorded with.
+var ErrUnknownReference = errors.New("no transaction with this reference ID")
+
+// TxMetadata is the information a user attached to a wallet transaction.
+// Spends record the reference ID they were made for and the address they
+// paid, and incoming payments are labeled with the labels of the receiving
+// addresses.
+type TxMetadata struct {
+	Txid         string   `json:"txid"`
+	ReferenceID  string   `json:"referenceID,omitempty"`
+	Memo         string   `json:"memo,omitempty"`
+	Labels       []string `json:"labels,omitempty"`
+	Counterparty string   `json:"counterparty,omitempty"`
+}
+
+// HasLabel returns whether the transaction is labeled with label
+func (md TxMetadata) HasLabel(label string) bool {
+	for _, l := range md.Labels {
+		if l == label {
+			return true
+		}
+	}
+	return false
+}
+
+// TxMetadata returns the metadata of a wallet transaction
+func (ws *WalletService) TxMetadata(txid string) (TxMetadata, bool) {
+	ws.metadataLock.RLock()
+	defer ws.metadataLock.RUnlock()
+	md, ok, err := ws.txMetadata(txid)
+	if err != nil {
+		Log.Errorf("loading metadata of tx (%s): %s", txid, err.Error())
+	}
+	return md, ok
+}
+
+// SetTxMetadata replaces the metadata of a transaction
+func (ws *WalletService) SetTxMetadata(md TxMetadata) error {
+	return ws.updateTxMetadata(md.Txid, func(saved *TxMetadata) {
+		*saved = md
+		saved.Labels = addLabels(nil, md.Labels...)
+	})
+}
+
+// RecordSpend records the reference ID and recipients of a broadcast spend.
+// Failing to save them does not fail the spend so errors are only logged, and
+// nothing is recorded if the datastore keeps no records.
+func (ws *WalletService) RecordSpend(txid, referenceID string, recipients ...btcutil.Address) {
+	if !ws.keepsRecords() {
+		return
+	}
+	counterparties := make([]string, 0, len(recipients))
+	for _, addr := range recipients {
+		counterparties = append(counterparties, addr.String())
+	}
+	err := ws.updateTxMetadata(txid, func(md *TxMetadata) {
+		md.ReferenceID = referenceID
+		md.Counterparty = strings.Join(counterparties, ",")
+	})
+	if err != nil {
+		Log.Errorf("recording spend (%s): %s", txid, err.Error())
+	}
+}
+
+// SetAddressLabel labels the transactions paying addr with label from now on.
+// An empty label removes the label of the address.
+func (ws *WalletService) SetAddressLabel(addr btcutil.Address, label string) error {
+	ws.metadataLock.Lock()
+	defer ws.metadataLock.Unlock()
+	labels := make(map[string]string, len(ws.addressLabels)+1)
+	for a, l := range ws.addressLabels {
+		labels[a] = l
+	}
+	if label == "" {
+		delete(labels, addr.String())
+	} else {
+		labels[addr.String()] = label
+	}
+	b, err := json.Marshal(labels)
+	if err != nil {
+		return err
+	}
+	if err := ws.records.Put(ws.addressLabelsKey(), b); err != nil {
+		return err
+	}
+	ws.addressLabels = labels
+	return nil
+}
+
+// AddressLabel returns the label of addr, if any
+func (ws *WalletService) AddressLabel(addr btcutil.Address) string {
+	ws.metadataLock.RLock()
+	defer ws.metadataLock.RUnlock()
+	return ws.addressLabels[addr.String()]
+}
+
+// TransactionByReference returns the most recent wallet transaction recorded
+// with referenceID.
+func (ws *WalletService) TransactionByReference(referenceID string) (wallet.Txn, error) {
+	if referenceID == "" {
+		return wallet.Txn{}, ErrUnknownReference
+	}
+	txns, err := ws.indexedTransactions(ws.referenceKey(referenceID), func(md TxMetadata) bool {
+		return md.ReferenceID == referenceID
+	})
+	if err != nil {
+		return wallet.Txn{}, err
+	}
+	if len(txns) == 0 {
+		return wallet.Txn{}, ErrUnknownReference
+	}
+	return txns[0], nil
+}
+
+// TransactionsWithLabel returns the wallet transactions labeled with label,
+// most recent first.
+func (ws *WalletService) TransactionsWithLabel(label string) ([]wallet.Txn, error) {
+	if label == "" {
+		return nil, nil
+	}
+	return ws.indexedTransactions(ws.labelKey(label), func(md TxMetadata) bool {
+		return md.HasLabel(label)
+	})
+}
+
+// indexedTransactions returns the wallet transactions of the index key whose
+// metadata matches, most recent first.
+func (ws *WalletService) indexedTransactions(key string, match func(TxMetadata) bool) ([]wallet.Txn, error) {
+	ws.metadataLock.RLock()
+	matched, err := ws.indexed(key, match)
+	ws.metadataLock.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+
+	var txns []wallet.Txn
+	for _, txid := range matched {
+		hash, err := chainhash.NewHashFromStr(txid)
+		if err != nil {
+			return nil, err
+		}
+		// Metadata may be recorded before the wallet saw the transaction
+		txn, err := ws.db.Txns().Get(*hash)
+		if err != nil {
+			continue
+		}
+		txns = append(txns, txn)
+	}
+	sort.SliceStable(txns, func(i, j int) bool {
+		return txns[i].Timestamp.After(txns[j].Timestamp)
+	})
+	return txns, nil
+}
+
+// labelIncoming labels a transaction with the labels of the addresses it pays
+func (ws *WalletService) labelIncoming(txid string, addrs []btcutil.Address) {
+	ws.metadataLock.RLock()
+	var labels []string
+	for _, addr := range addrs {
+		if label, ok := ws.addressLabels[addr.String()]; ok {
+			labels = append(labels, label)
+		}
+	}
+	var (
+		md  TxMetadata
+		ok  bool
+		err error
+	)
+	if len(labels) > 0 {
+		md, ok, err = ws.txMetadata(txid)
+	}
+	ws.metadataLock.RUnlock()
+	if err != nil {
+		Log.Errorf("labeling tx (%s): %s", txid, err.Error())
+		return
+	}
+	if len(labels) == 0 || (ok && len(addLabels(append([]string{}, md.Labels...), labels...)) == len(md.Labels)) {
+		return
+	}
+	err = ws.updateTxMetadata(txid, func(md *TxMetadata) {
+		md.Labels = addLabels(md.Labels, labels...)
+	})
+	if err != nil {
+		Log.Errorf("labeling tx (%s): %s", txid, err.Error())
+	}
+}
+
+// updateTxMetadata saves the metadata of txid changed by update. The new
+// reference ID and labels are indexed before the metadata is saved, so an
+// index never misses a transaction, and the ones it lost are removed after.
+func (ws *WalletService) updateTxMetadata(txid string, update func(*TxMetadata)) error {
+	ws.metadataLock.Lock()
+	defer ws.metadataLock.Unlock()
+	saved, _, err := ws.txMetadata(txid)
+	if err != nil {
+		return err
+	}
+	md := saved
+	md.Labels = append([]string(nil), saved.Labels...)
+	update(&md)
+	md.Txid = txid
+
+	oldKeys, newKeys := ws.indexKeys(saved), ws.indexKeys(md)
+	for key := range newKeys {
+		if !oldKeys[key] {
+			if err := ws.addToIndex(key, txid); err != nil {
+				return err
+			}
+		}
+	}
+	b, err := json.Marshal(md)
+	if err != nil {
+		return err
+	}
+	if err := ws.records.Put(ws.txMetadataKey(txid), b); err != nil {
+		return err
+	}
+	for key := range oldKeys {
+		if !newKeys[key] {
+			if err := ws.removeFromIndex(key, txid); err != nil {
+				Log.Warningf("removing tx (%s) from %s: %s", txid, key, err.Error())
+			}
+		}
+	}
+	return nil
+}
+
+// txMetadata reads the saved metadata of txid. The lock must be held.
+func (ws *WalletService) txMetadata(txid string) (TxMetadata, bool, error) {
+	b, err := ws.records.Get(ws.txMetadataKey(txid))
+	if err == datastore.ErrNoRecord {
+		return TxMetadata{}, false, nil
+	} else if err != nil {
+		return TxMetadata{}, false, err
+	}
+	var md TxMetadata
+	if err := json.Unmarshal(b, &md); err != nil {
+		return TxMetadata{}, false, err
+	}
+	return md, true, nil
+}
+
+// indexed returns the txids of the index key whose metadata matches. The
+// metadata is checked as an index may still list a transaction whose last
+// update failed. The lock must be held.
+func (ws *WalletService) indexed(key string, match func(TxMetadata) bool) ([]string, error) {
+	txids, err := ws.index(key)
+	if err != nil {
+		return nil, err
+	}
+	var matched []string
+	for _, txid := range txids {
+		md, ok, err := ws.txMetadata(txid)
+		if err != nil {
+			return nil, err
+		}
+		if ok && match(md) {
+			matched = append(matched, txid)
+		}
+	}
+	return matched, nil
+}
+
+// indexKeys returns the keys of the indexes listing a transaction with md
+func (ws *WalletService) indexKeys(md TxMetadata) map[string]bool {
+	keys := make(map[string]bool)
+	if md.ReferenceID != "" {
+		keys[ws.referenceKey(md.ReferenceID)] = true
+	}
+	for _, label := range md.Labels {
+		keys[ws.labelKey(label)] = true
+	}
+	return keys
+}
+
+// index returns the txids listed by an index key
+func (ws *WalletService) index(key string) ([]string, error) {
+	b, err := ws.records.Get(key)
+	if err == datastore.ErrNoRecord {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var txids []string
+	if err := json.Unmarshal(b, &txids); err != nil {
+		return nil, err
+	}
+	return txids, nil
+}
+
+func (ws *WalletService) addToIndex(key, txid string) error {
+	txids, err := ws.index(key)
+	if err != nil {
+		return err
+	}
+	for _, t := range txids {
+		if t == txid {
+			return nil
+		}
+	}
+	return ws.putIndex(key, append(txids, txid))
+}
+
+func (ws *WalletService) removeFromIndex(key, txid string) error {
+	txids, err := ws.index(key)
+	if err != nil {
+		return err
+	}
+	kept := make([]string, 0, len(txids))
+	for _, t := range txids {
+		if t != txid {
+			kept = append(kept, t)
+		}
+	}
+	if len(kept) == len(txids) {
+		return nil
+	}
+	return ws.putIndex(key, kept)
+}
+
+func (ws *WalletService) putIndex(key string, txids []string) error {
+	b, err := json.Marshal(txids)
+	if err != nil {
+		return err
+	}
+	return ws.records.Put(key, b)
+}
+
+func (ws *WalletService) loadMetadata() error {
+	ws.addressLabels = make(map[string]string)
+	b, err := ws.records.Get(ws.addressLabelsKey())
+	if err == datastore.ErrNoRecord {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, &ws.addressLabels); err != nil {
+		return err
+	}
+	if ws.addressLabels == nil {
+		ws.addressLabels = make(map[string]string)
+	}
+	return nil
+}
+
+func (ws *WalletService) txMetadataKey(txid string) string {
+	return fmt.Sprintf("tx-metadata-%s-%s", ws.coinType.String(), txid)
+}
+
+func (ws *WalletService) referenceKey(referenceID string) string {
+	return fmt.Sprintf("tx-reference-%s-%s", ws.coinType.String(), referenceID)
+}
+
+func (ws *WalletService) labelKey(label string) string {
+	return fmt.Sprintf("tx-label-%s-%s", ws.coinType.String(), label)
+}
+
+func (ws *WalletService) addressLabelsKey() string {
+	return fmt.Sprintf("address-labels-%s", ws.coinType.String())
+}
+
+// addLabels appends the labels missing from labels
+func addLabels(labels []string, add ...string) []string {
+	for _, label := range add {
+		if label == "" {
+			continue
+		}
+		found := false
+		for _, l := range labels {
+			if l == label {
+				found = true
+				break
+			}
+		}
+		if !found {
+			labels = append(labels, label)
+		}
+	}
+	return labels
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/muecoin/multiwallet/datastore"
+	"github.com/muecoin/multiwallet/model/mock"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcutil"
+)
+
+func TestWalletService_AddressLabels(t *testing.T) {
+	ws, err := mockWalletService()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx := mock.MockTransactions[0]
+	out := tx.Outputs[0].ScriptPubKey.Addresses[0]
+	addr, err := btcutil.DecodeAddress(out, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ws.SetAddressLabel(addr, "donations"); err != nil {
+		t.Fatal(err)
+	}
+	if label := ws.AddressLabel(addr); label != "donations" {
+		t.Errorf("Expected the donations label but had %q", label)
+	}
+
+	ws.saveSingleTxToDB(tx, 1000, map[string]storedAddress{out: {addr, true}})
+	// Saving the transaction again must not duplicate the label
+	ws.saveSingleTxToDB(tx, 1000, map[string]storedAddress{out: {addr, true}})
+
+	md, ok := ws.TxMetadata(tx.Txid)
+	if !ok || len(md.Labels) != 1 || md.Labels[0] != "donations" {
+		t.Errorf("Incoming payment was not labeled with the label of its address: %+v", md)
+	}
+	txns, err := ws.TransactionsWithLabel("donations")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txns) != 1 || txns[0].Txid != tx.Txid {
+		t.Errorf("Expected the labeled transaction but had %+v", txns)
+	}
+	txns, err = ws.TransactionsWithLabel("payroll")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txns) != 0 {
+		t.Errorf("Expected no transactions with an unused label but had %d", len(txns))
+	}
+
+	if err := ws.SetAddressLabel(addr, ""); err != nil {
+		t.Fatal(err)
+	}
+	if label := ws.AddressLabel(addr); label != "" {
+		t.Errorf("Expected the label to be removed but had %q", label)
+	}
+}
+
+func TestWalletService_TransactionByReference(t *testing.T) {
+	ws, err := mockWalletService()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx := mock.MockTransactions[0]
+	in := tx.Inputs[0]
+	addr, err := btcutil.DecodeAddress(in.Addr, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ws.saveSingleTxToDB(tx, 1000, map[string]storedAddress{in.Addr: {addr, true}})
+
+	if _, err := ws.TransactionByReference("1a3w"); err != ErrUnknownReference {
+		t.Errorf("Expected ErrUnknownReference but had %v", err)
+	}
+	recipient, err := btcutil.DecodeAddress(tx.Outputs[0].ScriptPubKey.Addresses[0], &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ws.RecordSpend(tx.Txid, "1a3w", recipient)
+
+	txn, err := ws.TransactionByReference("1a3w")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if txn.Txid != tx.Txid {
+		t.Errorf("Expected transaction %s but had %s", tx.Txid, txn.Txid)
+	}
+	md, _ := ws.TxMetadata(tx.Txid)
+	if md.Counterparty != recipient.String() {
+		t.Errorf("Expected counterparty %s but had %s", recipient, md.Counterparty)
+	}
+
+	md.Memo = "march payout"
+	md.Labels = []string{"payouts", "payouts"}
+	if err := ws.SetTxMetadata(md); err != nil {
+		t.Fatal(err)
+	}
+
+	// Metadata is kept by the datastore, not the cache
+	md, ok := restart(t, ws).TxMetadata(tx.Txid)
+	if !ok || md.ReferenceID != "1a3w" || md.Memo != "march payout" || len(md.Labels) != 1 {
+		t.Errorf("Metadata was not restored: %+v", md)
+	}
+
+	md.ReferenceID = "2b4x"
+	if err := ws.SetTxMetadata(md); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ws.TransactionByReference("1a3w"); err != ErrUnknownReference {
+		t.Errorf("Expected ErrUnknownReference for a replaced reference ID but had %v", err)
+	}
+	if txids, _ := ws.index(ws.referenceKey("1a3w")); len(txids) != 0 {
+		t.Errorf("Replaced reference ID still indexes %v", txids)
+	}
+	if txn, err := ws.TransactionByReference("2b4x"); err != nil || txn.Txid != tx.Txid {
+		t.Errorf("Expected transaction %s for the new reference ID but had %s: %v", tx.Txid, txn.Txid, err)
+	}
+}
+
+// failingRecords fails every Put
+type failingRecords struct {
+	datastore.Records
+}
+
+func (failingRecords) Put(key string, value []byte) error {
+	return errors.New("disk full")
+}
+
+func TestWalletService_metadataWriteFailure(t *testing.T) {
+	ws, err := mockWalletService()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx := mock.MockTransactions[0]
+	addr, err := btcutil.DecodeAddress(tx.Outputs[0].ScriptPubKey.Addresses[0], &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ws.SetTxMetadata(TxMetadata{Txid: tx.Txid, Memo: "rent"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ws.SetAddressLabel(addr, "donations"); err != nil {
+		t.Fatal(err)
+	}
+
+	ws.records = failingRecords{ws.records}
+	if err := ws.SetTxMetadata(TxMetadata{Txid: tx.Txid, Memo: "groceries"}); err == nil {
+		t.Error("Expected the failed write to fail")
+	}
+	if md, _ := ws.TxMetadata(tx.Txid); md.Memo != "rent" {
+		t.Errorf("Expected the saved memo after a failed write but had %q", md.Memo)
+	}
+	if err := ws.SetAddressLabel(addr, "payroll"); err == nil {
+		t.Error("Expected the failed write to fail")
+	}
+	if label := ws.AddressLabel(addr); label != "donations" {
+		t.Errorf("Expected the saved label after a failed write but had %q", label)
+	}
+}
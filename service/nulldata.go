@@ -0,0 +1,27 @@
+package service
+
+import (
+	"github.com/muecoin/multiwallet/util"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+)
+
+// NullData returns the payloads of the OP_RETURN outputs of a wallet
+// transaction in output order, or nil if it carries none. They are read from
+// the transaction saved in the datastore.
+func (ws *WalletService) NullData(txid chainhash.Hash) ([][]byte, error) {
+	txn, err := ws.db.Txns().Get(txid)
+	if err != nil {
+		return nil, err
+	}
+	msgTx, err := ws.decodeTx(txn.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	var payloads [][]byte
+	for _, out := range msgTx.TxOut {
+		if data, ok := util.ExtractNullData(out.PkScript); ok {
+			payloads = append(payloads, data)
+		}
+	}
+	return payloads, nil
+}
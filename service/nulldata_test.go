@@ -0,0 +1,71 @@
+package service
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/muecoin/multiwallet/model"
+	"github.com/muecoin/multiwallet/model/mock"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcutil"
+)
+
+func TestWalletService_NullData(t *testing.T) {
+	ws, err := mockWalletService()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx := mock.MockTransactions[0]
+	in := tx.Inputs[0]
+	addr, err := btcutil.DecodeAddress(in.Addr, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addrs := map[string]storedAddress{in.Addr: {addr, true}}
+	txid, err := chainhash.NewHashFromStr(tx.Txid)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ws.saveSingleTxToDB(tx, 1000, addrs)
+	payloads, err := ws.NullData(*txid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if payloads != nil {
+		t.Errorf("Expected no data for a transaction without data outputs but had %x", payloads)
+	}
+
+	data := model.Output{
+		N:            len(tx.Outputs),
+		ScriptPubKey: model.OutScript{Script: model.Script{Hex: "6a04deadbeef"}, Type: "nulldata"},
+	}
+	tx.Outputs = append(append([]model.Output{}, tx.Outputs...), data)
+	tx.Txid = "2222222222222222222222222222222222222222222222222222222222222222"
+	txid, err = chainhash.NewHashFromStr(tx.Txid)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ws.saveSingleTxToDB(tx, 1000, addrs)
+	payloads, err = ws.NullData(*txid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(payloads) != 1 || !bytes.Equal(payloads[0], []byte{0xde, 0xad, 0xbe, 0xef}) {
+		t.Errorf("Expected the data output payload but had %x", payloads)
+	}
+
+	payloads, err = restart(t, ws).NullData(*txid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(payloads) != 1 || !bytes.Equal(payloads[0], []byte{0xde, 0xad, 0xbe, 0xef}) {
+		t.Errorf("Expected the data output payload after a restart but had %x", payloads)
+	}
+
+	if _, err := ws.NullData(chainhash.Hash{}); err == nil {
+		t.Error("Returned data of an unknown transaction")
+	}
+}
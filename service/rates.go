@@ -0,0 +1,140 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/muecoin/multiwallet/datastore"
+	"github.com/OpenBazaar/wallet-interface"
+)
+
+const (
+	// rateInterval is how often the wallet records the exchange rates of the
+	// coin.
+	rateInterval = time.Hour
+
+	// rateMaxAge is how far from a time the closest recorded rate may be for
+	// RateAt to return it.
+	rateMaxAge = 24 * time.Hour
+)
+
+// rateSample is the exchange rates of the coin, in units of each currency per
+// coin, at a time.
+type rateSample struct {
+	Time  time.Time          `json:"time"`
+	Rates map[string]float64 `json:"rates"`
+}
+
+// SetExchangeRates sets the fetcher of the coin exchange rates. Once the
+// service starts it records the rates every hour so that transactions can
+// later be valued at the rate of the time they were made.
+func (ws *WalletService) SetExchangeRates(er wallet.ExchangeRates) {
+	ws.exchangeRates = er
+}
+
+// recordsRates returns whether the service records the exchange rates once
+// started
+func (ws *WalletService) recordsRates() bool {
+	return ws.exchangeRates != nil && ws.keepsRecords()
+}
+
+func (ws *WalletService) recordRates() {
+	ticker := time.NewTicker(rateInterval)
+	defer ticker.Stop()
+	for {
+		ws.fetchRates()
+		select {
+		case <-ws.ratesDone:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (ws *WalletService) fetchRates() {
+	rates, err := ws.exchangeRates.GetAllRates(true)
+	if err != nil {
+		Log.Warningf("fetching %s exchange rates: %s", ws.coinType.String(), err.Error())
+		return
+	}
+	if err := ws.RecordRates(time.Now(), rates); err != nil {
+		Log.Errorf("recording %s exchange rates: %s", ws.coinType.String(), err.Error())
+	}
+}
+
+// RecordRates records the exchange rates of the coin at t, in units of each
+// currency per coin. Rates fetched elsewhere may be recorded to backfill the
+// times the wallet wasn't running.
+func (ws *WalletService) RecordRates(t time.Time, rates map[string]float64) error {
+	sample := rateSample{Time: t.UTC(), Rates: make(map[string]float64)}
+	for currency, rate := range rates {
+		if rate > 0 {
+			sample.Rates[strings.ToUpper(currency)] = rate
+		}
+	}
+	if len(sample.Rates) == 0 {
+		return nil
+	}
+
+	ws.ratesLock.Lock()
+	defer ws.ratesLock.Unlock()
+	samples := ws.rateSamples(t)
+	samples = append(samples, sample)
+	b, err := json.Marshal(samples)
+	if err != nil {
+		return err
+	}
+	return ws.records.Put(ws.ratesKey(t), b)
+}
+
+// RateAt returns the recorded exchange rate of the coin in currency closest
+// to t, and false if no rate was recorded within a day of t.
+func (ws *WalletService) RateAt(currency string, t time.Time) (float64, bool) {
+	currency = strings.ToUpper(currency)
+
+	ws.ratesLock.RLock()
+	defer ws.ratesLock.RUnlock()
+	var (
+		rate    float64
+		closest = rateMaxAge + 1
+	)
+	for _, day := range []time.Time{t.Add(-rateMaxAge), t, t.Add(rateMaxAge)} {
+		for _, sample := range ws.rateSamples(day) {
+			r, ok := sample.Rates[currency]
+			if !ok {
+				continue
+			}
+			d := sample.Time.Sub(t)
+			if d < 0 {
+				d = -d
+			}
+			if d < closest {
+				rate, closest = r, d
+			}
+		}
+	}
+	return rate, closest <= rateMaxAge
+}
+
+// rateSamples returns the rates recorded on the day of t
+func (ws *WalletService) rateSamples(t time.Time) []rateSample {
+	b, err := ws.records.Get(ws.ratesKey(t))
+	if err != nil {
+		if err != datastore.ErrNoRecord {
+			Log.Errorf("loading %s exchange rates: %s", ws.coinType.String(), err.Error())
+		}
+		return nil
+	}
+	var samples []rateSample
+	if err := json.Unmarshal(b, &samples); err != nil {
+		Log.Errorf("unmarshaling %s exchange rates: %s", ws.coinType.String(), err.Error())
+		return nil
+	}
+	return samples
+}
+
+func (ws *WalletService) ratesKey(t time.Time) string {
+	return fmt.Sprintf("rates-%s-%s", ws.coinType.String(), t.UTC().Format("2006-01-02"))
+}
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWalletService_RateAt(t *testing.T) {
+	ws, err := mockWalletService()
+	if err != nil {
+		t.Fatal(err)
+	}
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	samples := []struct {
+		t     time.Time
+		rates map[string]float64
+	}{
+		{day.Add(-time.Hour), map[string]float64{"USD": 100, "EUR": 90}},
+		{day.Add(2 * time.Hour), map[string]float64{"USD": 110}},
+		{day.Add(5 * time.Hour), map[string]float64{"usd": 120, "JPY": 0}},
+	}
+	for _, s := range samples {
+		if err := ws.RecordRates(s.t, s.rates); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		currency string
+		t        time.Time
+		rate     float64
+		ok       bool
+	}{
+		{"USD", day, 100, true},
+		{"usd", day.Add(3 * time.Hour), 110, true},
+		{"USD", day.Add(4 * time.Hour), 120, true},
+		{"EUR", day.Add(5 * time.Hour), 90, true},
+		{"USD", day.Add(30 * time.Hour), 0, false},
+		{"JPY", day, 0, false},
+	}
+	// The rates are kept by the datastore, not the cache
+	for _, w := range []*WalletService{ws, restart(t, ws)} {
+		for i, test := range tests {
+			rate, ok := w.RateAt(test.currency, test.t)
+			if ok != test.ok || rate != test.rate {
+				t.Errorf("Test %d: expected rate %f (%t) but had %f (%t)", i, test.rate, test.ok, rate, ok)
+			}
+		}
+	}
+}
@@ -0,0 +1,342 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/muecoin/multiwallet/datastore"
+	"github.com/muecoin/multiwallet/rates"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+)
+
+var (
+	// ErrUnknownRequest is returned when abandoning a request ID no spend
+	// was signed for
+	ErrUnknownRequest = errors.New("no spend was signed for this request ID")
+
+	// ErrRequestBroadcast is returned when abandoning a request whose
+	// transaction was broadcast
+	ErrRequestBroadcast = errors.New("the transaction of this request ID was broadcast")
+
+	// ErrRequestInProgress is returned when abandoning a request whose spend
+	// is in progress
+	ErrRequestInProgress = errors.New("a spend with this request ID is in progress")
+)
+
+// DeadRequestError is returned for a request ID whose transaction will never
+// be broadcast, because it was abandoned or because another transaction spent
+// its inputs. The request ID cannot be spent with again.
+type DeadRequestError struct {
+	RequestID string
+	Reason    string
+}
+
+func (e *DeadRequestError) Error() string {
+	return fmt.Sprintf("spend request %s is dead: %s", e.RequestID, e.Reason)
+}
+
+// SignedSpend is a transaction signed for a spend request
+type SignedSpend struct {
+	Raw    []byte
+	Txid   chainhash.Hash
+	Inputs []wire.OutPoint
+}
+
+// NewSignedSpend returns the spend of tx, serialized as raw and identified by
+// txid
+func NewSignedSpend(raw []byte, txid chainhash.Hash, tx *wire.MsgTx) *SignedSpend {
+	spend := &SignedSpend{Raw: raw, Txid: txid}
+	for _, in := range tx.TxIn {
+		spend.Inputs = append(spend.Inputs, in.PreviousOutPoint)
+	}
+	return spend
+}
+
+// spendRequest is the saved state of a request ID. Raw holds the signed
+// transaction of the request until it is broadcast, and Failures and Error the
+// number and last error of its failed broadcasts. A dead request keeps the
+// reason it died in Error.
+type spendRequest struct {
+	Txid     string   `json:"txid"`
+	Raw      []byte   `json:"raw,omitempty"`
+	Inputs   []string `json:"inputs,omitempty"`
+	Failures int      `json:"failures,omitempty"`
+	Error    string   `json:"error,omitempty"`
+	Dead     bool     `json:"dead,omitempty"`
+}
+
+type inflightRequest struct {
+	done chan struct{}
+}
+
+// SpendOnce broadcasts the transaction returned by build once per request ID
+// and returns its txid for every later call with the same request ID,
+// including after a restart. build returns the signed transaction, and
+// broadcast broadcasts it.
+//
+// The signed transaction is saved before it is broadcast. If the broadcast
+// fails, or is interrupted, a retry with the same request ID broadcasts the
+// same transaction again rather than building a new one, so a payment is
+// never sent twice. Only a failed build may be retried from scratch. Once
+// another transaction spends one of its inputs, or once it is abandoned with
+// AbandonSpendRequest, the transaction can never be broadcast and SpendOnce
+// returns a DeadRequestError for the request ID. A call made while a spend
+// with the same request ID is in progress waits for it to finish. An empty
+// request ID builds and broadcasts unconditionally.
+//
+// Request IDs are saved in the datastore records, so SpendOnce returns
+// datastore.ErrNoRecordStore for a request ID if the datastore keeps none.
+func (ws *WalletService) SpendOnce(requestID string, build func() (*SignedSpend, error), broadcast func(raw []byte) (*chainhash.Hash, error)) (*chainhash.Hash, error) {
+	if requestID == "" {
+		signed, err := build()
+		if err != nil {
+			return nil, err
+		}
+		return broadcast(signed.Raw)
+	}
+	if !ws.keepsRecords() {
+		return nil, datastore.ErrNoRecordStore
+	}
+	for {
+		ws.requestLock.Lock()
+		if inflight, ok := ws.requests[requestID]; ok {
+			ws.requestLock.Unlock()
+			<-inflight.done
+			continue
+		}
+		saved, err := ws.spendRequest(requestID)
+		if err != nil {
+			ws.requestLock.Unlock()
+			return nil, err
+		}
+		if saved != nil && (saved.Dead || len(saved.Raw) == 0) {
+			ws.requestLock.Unlock()
+			return ws.spentRequest(requestID, saved)
+		}
+		inflight := &inflightRequest{done: make(chan struct{})}
+		ws.requests[requestID] = inflight
+		ws.requestLock.Unlock()
+
+		txid, err := ws.spendRequestOnce(requestID, saved, build, broadcast)
+
+		ws.requestLock.Lock()
+		delete(ws.requests, requestID)
+		close(inflight.done)
+		ws.requestLock.Unlock()
+		return txid, err
+	}
+}
+
+// AbandonSpendRequest gives up the transaction of a request ID whose
+// broadcast failed. The transaction is never broadcast again and its inputs
+// are left to other spends, while the request ID returns a DeadRequestError.
+// If the transaction was broadcast after all, it is still found by its txid
+// once the wallet sees it.
+func (ws *WalletService) AbandonSpendRequest(requestID string) error {
+	if !ws.keepsRecords() {
+		return datastore.ErrNoRecordStore
+	}
+	ws.requestLock.Lock()
+	defer ws.requestLock.Unlock()
+	if _, ok := ws.requests[requestID]; ok {
+		return ErrRequestInProgress
+	}
+	req, err := ws.spendRequest(requestID)
+	if err != nil {
+		return err
+	}
+	switch {
+	case req == nil:
+		return ErrUnknownRequest
+	case req.Dead:
+		return nil
+	case len(req.Raw) == 0:
+		return ErrRequestBroadcast
+	case ws.knownTx(req.Txid):
+		if err := ws.saveSpendRequest(requestID, &spendRequest{Txid: req.Txid}); err != nil {
+			return err
+		}
+		return ErrRequestBroadcast
+	}
+	return ws.saveSpendRequest(requestID, &spendRequest{
+		Txid:     req.Txid,
+		Inputs:   req.Inputs,
+		Failures: req.Failures,
+		Error:    "abandoned",
+		Dead:     true,
+	})
+}
+
+// spentRequest returns the outcome of a request which is not broadcast again:
+// the txid of a broadcast transaction, or the error of a dead request unless
+// its transaction turned up in the wallet.
+func (ws *WalletService) spentRequest(requestID string, req *spendRequest) (*chainhash.Hash, error) {
+	if req.Dead && !ws.knownTx(req.Txid) {
+		return nil, &DeadRequestError{RequestID: requestID, Reason: req.Error}
+	}
+	return chainhash.NewHashFromStr(req.Txid)
+}
+
+// spendRequestOnce builds the transaction of a new request, or takes the saved
+// transaction of a request whose broadcast failed, and broadcasts it.
+func (ws *WalletService) spendRequestOnce(requestID string, req *spendRequest, build func() (*SignedSpend, error), broadcast func(raw []byte) (*chainhash.Hash, error)) (*chainhash.Hash, error) {
+	if req == nil {
+		signed, err := build()
+		if err != nil {
+			// Nothing was signed so the request can be built again
+			return nil, err
+		}
+		req = &spendRequest{Txid: signed.Txid.String(), Raw: signed.Raw}
+		for _, in := range signed.Inputs {
+			req.Inputs = append(req.Inputs, in.String())
+		}
+		if err := ws.saveSpendRequest(requestID, req); err != nil {
+			return nil, err
+		}
+	} else if ws.knownTx(req.Txid) {
+		// A broadcast reported as failed or interrupted went through
+		if err := ws.saveSpendRequest(requestID, &spendRequest{Txid: req.Txid}); err != nil {
+			return nil, err
+		}
+		return chainhash.NewHashFromStr(req.Txid)
+	} else if ws.inputsSpent(req.Inputs) {
+		return nil, ws.killSpendRequest(requestID, req, "its inputs were spent by another transaction")
+	}
+
+	txid, err := broadcast(req.Raw)
+	if err != nil {
+		req.Failures++
+		req.Error = err.Error()
+		if ws.inputsSpent(req.Inputs) {
+			return nil, ws.killSpendRequest(requestID, req, fmt.Sprintf("its inputs were spent by another transaction (%s)", req.Error))
+		}
+		if serr := ws.saveSpendRequest(requestID, req); serr != nil {
+			Log.Errorf("saving failed spend request %s (%s): %s", requestID, req.Txid, serr.Error())
+		}
+		return nil, err
+	}
+	if err := ws.saveSpendRequest(requestID, &spendRequest{Txid: txid.String()}); err != nil {
+		Log.Errorf("saving spend request %s (%s): %s", requestID, txid.String(), err.Error())
+	}
+	return txid, nil
+}
+
+// killSpendRequest marks a request dead for reason and returns its
+// DeadRequestError
+func (ws *WalletService) killSpendRequest(requestID string, req *spendRequest, reason string) error {
+	dead := &spendRequest{
+		Txid:     req.Txid,
+		Inputs:   req.Inputs,
+		Failures: req.Failures,
+		Error:    reason,
+		Dead:     true,
+	}
+	if err := ws.saveSpendRequest(requestID, dead); err != nil {
+		return err
+	}
+	return &DeadRequestError{RequestID: requestID, Reason: reason}
+}
+
+// knownTx reports whether the wallet has seen the transaction with txid
+func (ws *WalletService) knownTx(txid string) bool {
+	h, err := chainhash.NewHashFromStr(txid)
+	if err != nil {
+		return false
+	}
+	_, err = ws.db.Txns().Get(*h)
+	return err == nil
+}
+
+// inputsSpent reports whether one of inputs is no longer an unspent output of
+// the wallet
+func (ws *WalletService) inputsSpent(inputs []string) bool {
+	if len(inputs) == 0 {
+		return false
+	}
+	utxos, err := ws.db.Utxos().GetAll()
+	if err != nil {
+		return false
+	}
+	unspent := make(map[string]bool, len(utxos))
+	for _, u := range utxos {
+		unspent[u.Op.String()] = true
+	}
+	for _, in := range inputs {
+		if !unspent[in] {
+			return true
+		}
+	}
+	return false
+}
+
+// ConvertOnce converts a fiat amount with convert once per request ID and
+// returns the saved conversion for every later call with the same request ID,
+// including after a restart, so a retried fiat spend pays and reports the
+// amount and rate of the first attempt. A failed conversion is not saved. An
+// empty request ID converts unconditionally.
+//
+// Conversions are saved in the datastore records, so ConvertOnce returns
+// datastore.ErrNoRecordStore for a request ID if the datastore keeps none.
+func (ws *WalletService) ConvertOnce(requestID string, convert func() (rates.Conversion, error)) (rates.Conversion, error) {
+	if requestID == "" {
+		return convert()
+	}
+	if !ws.keepsRecords() {
+		return rates.Conversion{}, datastore.ErrNoRecordStore
+	}
+	ws.conversionLock.Lock()
+	defer ws.conversionLock.Unlock()
+	var conv rates.Conversion
+	b, err := ws.records.Get(ws.conversionKey(requestID))
+	if err == nil {
+		err = json.Unmarshal(b, &conv)
+		return conv, err
+	} else if err != datastore.ErrNoRecord {
+		return conv, err
+	}
+	conv, err = convert()
+	if err != nil {
+		return conv, err
+	}
+	if b, err = json.Marshal(conv); err != nil {
+		return rates.Conversion{}, err
+	}
+	if err := ws.records.Put(ws.conversionKey(requestID), b); err != nil {
+		return rates.Conversion{}, err
+	}
+	return conv, nil
+}
+
+func (ws *WalletService) conversionKey(requestID string) string {
+	return fmt.Sprintf("fiat-conversion-%s-%s", ws.coinType.String(), requestID)
+}
+
+// spendRequest returns the saved state of a request ID or nil if it was never
+// used or its build failed.
+func (ws *WalletService) spendRequest(requestID string) (*spendRequest, error) {
+	b, err := ws.records.Get(ws.spendRequestKey(requestID))
+	if err == datastore.ErrNoRecord {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var req spendRequest
+	if err := json.Unmarshal(b, &req); err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+func (ws *WalletService) saveSpendRequest(requestID string, req *spendRequest) error {
+	b, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	return ws.records.Put(ws.spendRequestKey(requestID), b)
+}
+
+func (ws *WalletService) spendRequestKey(requestID string) string {
+	return fmt.Sprintf("spend-request-%s-%s", ws.coinType.String(), requestID)
+}
@@ -0,0 +1,341 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/muecoin/multiwallet/cache"
+	"github.com/muecoin/multiwallet/datastore"
+	"github.com/muecoin/multiwallet/rates"
+	"github.com/OpenBazaar/wallet-interface"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+)
+
+func TestWalletService_SpendOnce(t *testing.T) {
+	ws, err := mockWalletService()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var builds, broadcasts int32
+	build := func() (*SignedSpend, error) {
+		n := atomic.AddInt32(&builds, 1)
+		return &SignedSpend{Raw: []byte{byte(n)}, Txid: chainhash.Hash{byte(n)}}, nil
+	}
+	broadcast := func(raw []byte) (*chainhash.Hash, error) {
+		atomic.AddInt32(&broadcasts, 1)
+		time.Sleep(50 * time.Millisecond)
+		return &chainhash.Hash{raw[0]}, nil
+	}
+
+	// Concurrent duplicates wait for the first spend and share its txid
+	var wg sync.WaitGroup
+	txids := make([]*chainhash.Hash, 10)
+	errs := make([]error, 10)
+	for i := range txids {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			txids[i], errs[i] = ws.SpendOnce("payout-1", build, broadcast)
+		}(i)
+	}
+	wg.Wait()
+	if builds != 1 || broadcasts != 1 {
+		t.Fatalf("Expected one spend but built %d and broadcast %d times", builds, broadcasts)
+	}
+	for i := range txids {
+		if errs[i] != nil {
+			t.Fatal(errs[i])
+		}
+		if !txids[i].IsEqual(txids[0]) {
+			t.Errorf("Call %d returned %s instead of %s", i, txids[i], txids[0])
+		}
+	}
+
+	// Other request IDs spend independently
+	other, err := ws.SpendOnce("payout-2", build, broadcast)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if builds != 2 || other.IsEqual(txids[0]) {
+		t.Error("A different request ID did not spend")
+	}
+
+	// The mapping is kept by the datastore, not the cache
+	txid, err := restart(t, ws).SpendOnce("payout-1", build, broadcast)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if builds != 2 || broadcasts != 2 || !txid.IsEqual(txids[0]) {
+		t.Errorf("Spent again after a restart, returned %s", txid)
+	}
+}
+
+func TestWalletService_SpendOnceRetry(t *testing.T) {
+	ws, err := mockWalletService()
+	if err != nil {
+		t.Fatal(err)
+	}
+	errBuild := errors.New("insufficient funds")
+	if _, err := ws.SpendOnce("payout-1", func() (*SignedSpend, error) {
+		return nil, errBuild
+	}, func(raw []byte) (*chainhash.Hash, error) {
+		t.Error("Broadcast a transaction which failed to build")
+		return nil, nil
+	}); err != errBuild {
+		t.Fatalf("Expected the build error but had %v", err)
+	}
+
+	// A request whose build failed is built again
+	var built [][]byte
+	build := func() (*SignedSpend, error) {
+		raw := []byte{byte(len(built) + 1)}
+		built = append(built, raw)
+		return &SignedSpend{Raw: raw, Txid: chainhash.Hash{raw[0]}}, nil
+	}
+	errBroadcast := errors.New("broadcast failed")
+	if _, err := ws.SpendOnce("payout-1", build, func(raw []byte) (*chainhash.Hash, error) {
+		return nil, errBroadcast
+	}); err != errBroadcast {
+		t.Fatalf("Expected the broadcast error but had %v", err)
+	}
+	if len(built) != 1 {
+		t.Fatalf("Expected the request to be built again but built %d times", len(built))
+	}
+
+	// A request whose broadcast failed broadcasts the same transaction again,
+	// also after a restart
+	var broadcast []byte
+	txid, err := restart(t, ws).SpendOnce("payout-1", build, func(raw []byte) (*chainhash.Hash, error) {
+		broadcast = raw
+		return &chainhash.Hash{raw[0]}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(built) != 1 || !bytes.Equal(broadcast, built[0]) || !txid.IsEqual(&chainhash.Hash{built[0][0]}) {
+		t.Errorf("Expected a rebroadcast of %x but built %d times and broadcast %x", built[0], len(built), broadcast)
+	}
+
+	// A request whose broadcast went through despite an error is not
+	// broadcast again once the wallet sees its transaction
+	if _, err := ws.SpendOnce("payout-2", build, func(raw []byte) (*chainhash.Hash, error) {
+		return nil, errBroadcast
+	}); err != errBroadcast {
+		t.Fatalf("Expected the broadcast error but had %v", err)
+	}
+	seen := chainhash.Hash{built[1][0]}
+	if err := ws.db.Txns().Put(built[1], seen.String(), -1000, 0, time.Now(), false); err != nil {
+		t.Fatal(err)
+	}
+	txid, err = restart(t, ws).SpendOnce("payout-2", build, func(raw []byte) (*chainhash.Hash, error) {
+		t.Error("Broadcast a transaction the wallet has seen")
+		return nil, errBroadcast
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !txid.IsEqual(&seen) {
+		t.Errorf("Expected txid %s but had %s", seen, txid)
+	}
+}
+
+func TestWalletService_SpendOnceDead(t *testing.T) {
+	ws, err := mockWalletService()
+	if err != nil {
+		t.Fatal(err)
+	}
+	inputs := []wire.OutPoint{{Hash: chainhash.Hash{0xaa}}, {Hash: chainhash.Hash{0xbb}}}
+	for _, op := range inputs {
+		if err := ws.db.Utxos().Put(wallet.Utxo{Op: op, Value: 100000, AtHeight: 100}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	build := func(n byte, in wire.OutPoint) func() (*SignedSpend, error) {
+		return func() (*SignedSpend, error) {
+			tx := wire.NewMsgTx(1)
+			tx.AddTxIn(wire.NewTxIn(&in, nil, nil))
+			return NewSignedSpend([]byte{n}, chainhash.Hash{n}, tx), nil
+		}
+	}
+	errRejected := errors.New("min relay fee not met")
+	var broadcasts int
+	reject := func(raw []byte) (*chainhash.Hash, error) {
+		broadcasts++
+		return nil, errRejected
+	}
+	for i, in := range inputs {
+		requestID := fmt.Sprintf("payout-%d", i+1)
+		if _, err := ws.SpendOnce(requestID, build(byte(i+1), in), reject); err != errRejected {
+			t.Fatalf("Expected the broadcast error but had %v", err)
+		}
+	}
+
+	// Failed broadcasts are recorded
+	if _, err := ws.SpendOnce("payout-1", nil, reject); err != errRejected {
+		t.Fatalf("Expected the broadcast error but had %v", err)
+	}
+	req, err := ws.spendRequest("payout-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Failures != 2 || req.Error != errRejected.Error() || req.Dead {
+		t.Errorf("Unexpected failed request %+v", req)
+	}
+
+	// A request whose inputs another transaction spent dies without being
+	// broadcast
+	if err := ws.db.Utxos().Delete(wallet.Utxo{Op: inputs[0]}); err != nil {
+		t.Fatal(err)
+	}
+	broadcasts = 0
+	for _, ws := range []*WalletService{ws, restart(t, ws)} {
+		_, err := ws.SpendOnce("payout-1", nil, reject)
+		if dead, ok := err.(*DeadRequestError); !ok || dead.RequestID != "payout-1" {
+			t.Errorf("Expected a dead request error but had %v", err)
+		}
+	}
+	if broadcasts != 0 {
+		t.Errorf("Broadcast a dead request %d times", broadcasts)
+	}
+
+	// An abandoned request is not broadcast again
+	if err := ws.AbandonSpendRequest("payout-2"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ws.SpendOnce("payout-2", nil, reject); err == nil || broadcasts != 0 {
+		t.Errorf("Expected an abandoned request to fail without broadcasting but had %v", err)
+	}
+	if err := ws.AbandonSpendRequest("payout-3"); err != ErrUnknownRequest {
+		t.Errorf("Expected ErrUnknownRequest but had %v", err)
+	}
+	if _, err := ws.SpendOnce("payout-3", build(3, inputs[1]), func(raw []byte) (*chainhash.Hash, error) {
+		return &chainhash.Hash{raw[0]}, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ws.AbandonSpendRequest("payout-3"); err != ErrRequestBroadcast {
+		t.Errorf("Expected ErrRequestBroadcast but had %v", err)
+	}
+
+	// A dead request whose transaction turns up returns its txid
+	seen := chainhash.Hash{2}
+	if err := ws.db.Txns().Put([]byte{2}, seen.String(), -1000, 0, time.Now(), false); err != nil {
+		t.Fatal(err)
+	}
+	txid, err := ws.SpendOnce("payout-2", nil, reject)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !txid.IsEqual(&seen) {
+		t.Errorf("Expected txid %s but had %s", seen, txid)
+	}
+}
+
+// datastoreWithoutRecords hides the records of a datastore
+func TestWalletService_ConvertOnce(t *testing.T) {
+	ws, err := mockWalletService()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var conversions int
+	rate := 50000.0
+	convert := func() (rates.Conversion, error) {
+		conversions++
+		return rates.Conversion{
+			Amount:   int64(100 / rate * 1e8),
+			Currency: "USD",
+			Rate:     rates.Rate{Value: rate, Time: time.Unix(1600000000, 0).UTC()},
+		}, nil
+	}
+
+	first, err := ws.ConvertOnce("payout-1", convert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Amount != 200000 {
+		t.Errorf("Expected 200000 but converted %d", first.Amount)
+	}
+
+	// A retry after the rate moved, also after a restart, returns the first
+	// conversion
+	rate = 40000
+	for _, ws := range []*WalletService{ws, restart(t, ws)} {
+		conv, err := ws.ConvertOnce("payout-1", convert)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if conv.Amount != first.Amount || conv.Rate.Value != first.Rate.Value || !conv.Rate.Time.Equal(first.Rate.Time) {
+			t.Errorf("Expected the first conversion %+v but had %+v", first, conv)
+		}
+	}
+	if conversions != 1 {
+		t.Errorf("Expected 1 conversion but had %d", conversions)
+	}
+
+	// A failed conversion is not saved, and no request ID converts every time
+	if _, err := ws.ConvertOnce("payout-2", func() (rates.Conversion, error) {
+		return rates.Conversion{}, rates.ErrSlippage
+	}); err != rates.ErrSlippage {
+		t.Errorf("Expected ErrSlippage but had %v", err)
+	}
+	if conv, err := ws.ConvertOnce("payout-2", convert); err != nil || conv.Amount != 250000 {
+		t.Errorf("Expected a new conversion of 250000 but had %d (%v)", conv.Amount, err)
+	}
+	if _, err := ws.ConvertOnce("", convert); err != nil {
+		t.Fatal(err)
+	}
+	if conversions != 3 {
+		t.Errorf("Expected 3 conversions but had %d", conversions)
+	}
+}
+
+type datastoreWithoutRecords struct {
+	wallet.Datastore
+}
+
+func TestNewWalletService_withoutRecords(t *testing.T) {
+	ws, err := mockWalletService()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ws, err = NewWalletService(datastoreWithoutRecords{ws.db}, ws.km, ws.client, ws.params, ws.coinType, cache.NewMockCacher())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	build := func() (*SignedSpend, error) {
+		return &SignedSpend{Raw: []byte{0x01}, Txid: chainhash.Hash{0x01}}, nil
+	}
+	broadcast := func(raw []byte) (*chainhash.Hash, error) {
+		return &chainhash.Hash{raw[0]}, nil
+	}
+	if _, err := ws.SpendOnce("", build, broadcast); err != nil {
+		t.Errorf("Spend without a request ID failed: %s", err)
+	}
+	if _, err := ws.SpendOnce("payout-1", build, broadcast); err != datastore.ErrNoRecordStore {
+		t.Errorf("Expected ErrNoRecordStore for a request ID but had %v", err)
+	}
+	if err := ws.SetTxMetadata(TxMetadata{Txid: (&chainhash.Hash{0x01}).String(), Memo: "rent"}); err != datastore.ErrNoRecordStore {
+		t.Errorf("Expected ErrNoRecordStore for metadata but had %v", err)
+	}
+	if _, ok := ws.TxMetadata((&chainhash.Hash{0x01}).String()); ok {
+		t.Error("Kept metadata which was not saved")
+	}
+	if err := ws.RecordRates(time.Now(), map[string]float64{"USD": 1}); err != datastore.ErrNoRecordStore {
+		t.Errorf("Expected ErrNoRecordStore for rates but had %v", err)
+	}
+	if ws.recordsRates() {
+		t.Error("Records rates without records")
+	}
+	if _, err := ws.ConvertOnce("payout-1", func() (rates.Conversion, error) {
+		return rates.Conversion{Amount: 1}, nil
+	}); err != datastore.ErrNoRecordStore {
+		t.Errorf("Expected ErrNoRecordStore for a conversion with a request ID but had %v", err)
+	}
+}
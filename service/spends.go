@@ -0,0 +1,100 @@
+package service
+
+import (
+	"bytes"
+
+	"github.com/muecoin/multiwallet/model"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+)
+
+// ScriptSpend is sent to the spend watchers of an address for each input of a
+// transaction spending one of its outputs. It carries the signature script
+// and witness of the input so that watchers can read the data revealed by
+// the spend.
+type ScriptSpend struct {
+	Txid            string
+	Height          int32
+	Input           int
+	Outpoint        wire.OutPoint
+	SignatureScript []byte
+	Witness         wire.TxWitness
+}
+
+// WatchSpends calls callback for each input spending an output of addr. The
+// address must also be watched for its transactions to be synced. A spend is
+// reported again when its transaction is updated, for example once it
+// confirms, so callbacks must be idempotent. Watchers are kept in memory only.
+func (ws *WalletService) WatchSpends(addr btcutil.Address, callback func(ScriptSpend)) {
+	ws.spendLock.Lock()
+	defer ws.spendLock.Unlock()
+	ws.spendWatchers[addr.String()] = append(ws.spendWatchers[addr.String()], callback)
+}
+
+// notifySpends calls the spend watchers of the addresses spent by tx
+func (ws *WalletService) notifySpends(u model.Transaction, msgTx *wire.MsgTx, height int32) {
+	ws.spendLock.Lock()
+	if len(ws.spendWatchers) == 0 {
+		ws.spendLock.Unlock()
+		return
+	}
+	type watchedInput struct {
+		index     int
+		callbacks []func(ScriptSpend)
+	}
+	var watched []watchedInput
+	for i, in := range u.Inputs {
+		if callbacks, ok := ws.spendWatchers[in.Addr]; ok && i < len(msgTx.TxIn) {
+			watched = append(watched, watchedInput{index: i, callbacks: callbacks})
+		}
+	}
+	ws.spendLock.Unlock()
+	if len(watched) == 0 {
+		return
+	}
+
+	// The API only reports signature scripts so the witnesses are read from
+	// the serialized transaction
+	var witnesses *wire.MsgTx
+	for _, w := range watched {
+		if len(msgTx.TxIn[w.index].SignatureScript) == 0 && witnesses == nil {
+			witnesses = ws.rawTransaction(u)
+		}
+	}
+
+	for _, w := range watched {
+		txIn := msgTx.TxIn[w.index]
+		spend := ScriptSpend{
+			Txid:            u.Txid,
+			Height:          height,
+			Input:           w.index,
+			Outpoint:        txIn.PreviousOutPoint,
+			SignatureScript: txIn.SignatureScript,
+		}
+		if witnesses != nil && w.index < len(witnesses.TxIn) {
+			spend.Witness = witnesses.TxIn[w.index].Witness
+		}
+		for _, callback := range w.callbacks {
+			go callback(spend)
+		}
+	}
+}
+
+// rawTransaction returns the deserialized transaction with its witnesses or
+// nil if it is not available from the API.
+func (ws *WalletService) rawTransaction(u model.Transaction) *wire.MsgTx {
+	raw := u.RawBytes
+	if len(raw) == 0 {
+		var err error
+		if raw, err = ws.client.GetRawTransaction(u.Txid); err != nil || len(raw) == 0 {
+			Log.Warningf("fetching raw %s tx %s: missing witnesses of spend", ws.coinType.String(), u.Txid)
+			return nil
+		}
+	}
+	msgTx := wire.NewMsgTx(wire.TxVersion)
+	if err := msgTx.Deserialize(bytes.NewReader(raw)); err != nil {
+		Log.Warningf("deserializing raw %s tx %s: %s", ws.coinType.String(), u.Txid, err.Error())
+		return nil
+	}
+	return msgTx
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/muecoin/multiwallet/model"
+	"github.com/muecoin/multiwallet/model/mock"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+)
+
+func TestWalletService_WatchSpends(t *testing.T) {
+	ws, err := mockWalletService()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx := mock.MockTransactions[0]
+	in := tx.Inputs[0]
+	addr, err := btcutil.DecodeAddress(in.Addr, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spends := make(chan ScriptSpend, 2)
+	ws.WatchSpends(addr, func(spend ScriptSpend) {
+		spends <- spend
+	})
+	addrs := map[string]storedAddress{in.Addr: {addr, true}}
+
+	ws.saveSingleTxToDB(tx, 1000, addrs)
+	select {
+	case spend := <-spends:
+		sigScript, _ := hex.DecodeString(in.ScriptSig.Hex)
+		if spend.Txid != tx.Txid || spend.Input != 0 || spend.Outpoint.Hash.String() != in.Txid || spend.Outpoint.Index != uint32(in.Vout) {
+			t.Errorf("Unexpected spend %+v", spend)
+		}
+		if !bytes.Equal(spend.SignatureScript, sigScript) {
+			t.Error("Spend does not carry the signature script of the input")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Spend of the watched address was not notified")
+	}
+
+	// Witnesses are read from the serialized transaction
+	prevHash, err := chainhash.NewHashFromStr(in.Txid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	witness := wire.TxWitness{{0x01}, {0x02, 0x03}}
+	msgTx := wire.NewMsgTx(2)
+	msgTx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(prevHash, uint32(in.Vout)), nil, witness))
+	msgTx.AddTxOut(wire.NewTxOut(1000, []byte{0x00, 0x14}))
+	var buf bytes.Buffer
+	if err := msgTx.Serialize(&buf); err != nil {
+		t.Fatal(err)
+	}
+	segwit := tx
+	segwit.Txid = "1111111111111111111111111111111111111111111111111111111111111111"
+	segwit.Inputs = []model.Input{in}
+	segwit.Inputs[0].ScriptSig = model.Script{}
+	segwit.RawBytes = buf.Bytes()
+
+	ws.saveSingleTxToDB(segwit, 1000, addrs)
+	select {
+	case spend := <-spends:
+		if len(spend.SignatureScript) != 0 || len(spend.Witness) != 2 || !bytes.Equal(spend.Witness[1], witness[1]) {
+			t.Errorf("Expected the witness of the input but had %+v", spend)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Spend of the watched address was not notified")
+	}
+}
@@ -11,18 +11,16 @@ import (
 	"time"
 
 	"github.com/muecoin/multiwallet/cache"
+	"github.com/muecoin/multiwallet/datastore"
 	"github.com/muecoin/multiwallet/keys"
-	laddr "github.com/muecoin/multiwallet/litecoin/address"
 	"github.com/muecoin/multiwallet/model"
+	"github.com/muecoin/multiwallet/registry"
 	"github.com/muecoin/multiwallet/util"
-	zaddr "github.com/muecoin/multiwallet/zcash/address"
 	"github.com/OpenBazaar/wallet-interface"
 	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
-	"github.com/btcsuite/btcd/txscript"
 	"github.com/btcsuite/btcd/wire"
 	"github.com/btcsuite/btcutil"
-	"github.com/cpacia/bchutil"
 	"github.com/op/go-logging"
 )
 
@@ -38,9 +36,33 @@ type WalletService struct {
 	chainHeight uint32
 	bestBlock   string
 	cache       cache.Cacher
+	records     datastore.Records
 
 	listeners []func(wallet.TransactionCallback)
 
+	txExpiry ExpiryFunc
+	txDecode DecodeFunc
+
+	escrows         map[string]watchedEscrow
+	escrowsNotified map[wire.OutPoint]bool
+	escrowListeners []func(EscrowTimeout)
+	escrowLock      sync.Mutex
+
+	spendWatchers map[string][]func(ScriptSpend)
+	spendLock     sync.Mutex
+
+	addressLabels map[string]string
+	metadataLock  sync.RWMutex
+
+	requests    map[string]*inflightRequest
+	requestLock sync.Mutex
+
+	conversionLock sync.Mutex
+
+	exchangeRates wallet.ExchangeRates
+	ratesLock     sync.RWMutex
+	ratesDone     chan struct{}
+
 	lock sync.RWMutex
 
 	doneChan chan struct{}
@@ -54,7 +76,22 @@ type HashAndHeight struct {
 
 const nullHash = "0000000000000000000000000000000000000000000000000000000000000000"
 
+// ExpiryFunc reads the expiry height and the spent outpoints of a serialized
+// transaction. An expiry height of zero means the transaction never expires.
+type ExpiryFunc func(rawTx []byte) (expiryHeight uint32, spent []wire.OutPoint, err error)
+
+// DecodeFunc deserializes the transparent inputs and outputs of a transaction
+// saved in the datastore.
+type DecodeFunc func(rawTx []byte) (*wire.MsgTx, error)
+
+// NewWalletService returns the wallet service of the coin. Spends with a
+// request ID, transaction metadata and the rate history need db to implement
+// datastore.RecordStore, and return datastore.ErrNoRecordStore otherwise.
 func NewWalletService(db wallet.Datastore, km *keys.KeyManager, client model.APIClient, params *chaincfg.Params, coinType util.ExtCoinType, cache cache.Cacher) (*WalletService, error) {
+	var records datastore.Records = noRecords{}
+	if store, ok := db.(datastore.RecordStore); ok {
+		records = store.Records()
+	}
 	var (
 		ws = &WalletService{
 			db:          db,
@@ -66,12 +103,25 @@ func NewWalletService(db wallet.Datastore, km *keys.KeyManager, client model.API
 			bestBlock:   nullHash,
 
 			cache:     cache,
+			records:   records,
 			listeners: []func(wallet.TransactionCallback){},
 			lock:      sync.RWMutex{},
 			doneChan:  make(chan struct{}),
+
+			escrows:         make(map[string]watchedEscrow),
+			escrowsNotified: make(map[wire.OutPoint]bool),
+
+			spendWatchers: make(map[string][]func(ScriptSpend)),
+
+			requests: make(map[string]*inflightRequest),
+
+			ratesDone: make(chan struct{}),
 		}
 		marshaledHeight, err = cache.Get(ws.bestHeightKey())
 	)
+	if err := ws.loadMetadata(); err != nil {
+		return nil, err
+	}
 
 	if err != nil {
 		Log.Info("cached block height missing: using default")
@@ -87,14 +137,37 @@ func NewWalletService(db wallet.Datastore, km *keys.KeyManager, client model.API
 	return ws, nil
 }
 
+// noRecords are the records of a datastore which does not keep any
+type noRecords struct{}
+
+func (noRecords) Get(key string) ([]byte, error) {
+	return nil, datastore.ErrNoRecord
+}
+
+func (noRecords) Put(key string, value []byte) error {
+	return datastore.ErrNoRecordStore
+}
+
+// keepsRecords returns whether the datastore of the wallet keeps records
+func (ws *WalletService) keepsRecords() bool {
+	_, none := ws.records.(noRecords)
+	return !none
+}
+
 func (ws *WalletService) Start() {
 	Log.Noticef("starting %s WalletService", ws.coinType.String())
 	go ws.UpdateState()
 	go ws.listen()
+	if ws.recordsRates() {
+		go ws.recordRates()
+	}
 }
 
 func (ws *WalletService) Stop() {
 	ws.doneChan <- struct{}{}
+	if ws.recordsRates() {
+		ws.ratesDone <- struct{}{}
+	}
 }
 
 func (ws *WalletService) ChainTip() (uint32, chainhash.Hash) {
@@ -107,6 +180,32 @@ func (ws *WalletService) ChainTip() (uint32, chainhash.Hash) {
 	return uint32(ws.chainHeight), *ch
 }
 
+// SetTxExpiry sets the function used to read the expiry height of unconfirmed
+// transactions. Once the chain reaches its expiry height an unconfirmed
+// transaction can no longer be mined, so instead of being rebroadcast it is
+// marked dead and the outputs it spent are returned to the wallet.
+func (ws *WalletService) SetTxExpiry(f ExpiryFunc) {
+	ws.txExpiry = f
+}
+
+// SetTxDecoder sets the function used to deserialize saved transactions of
+// coins whose serialization differs from Bitcoin's.
+func (ws *WalletService) SetTxDecoder(f DecodeFunc) {
+	ws.txDecode = f
+}
+
+// decodeTx deserializes a saved transaction
+func (ws *WalletService) decodeTx(raw []byte) (*wire.MsgTx, error) {
+	if ws.txDecode != nil {
+		return ws.txDecode(raw)
+	}
+	msgTx := wire.NewMsgTx(wire.TxVersion)
+	if err := msgTx.Deserialize(bytes.NewReader(raw)); err != nil {
+		return nil, err
+	}
+	return msgTx, nil
+}
+
 func (ws *WalletService) AddTransactionListener(callback func(callback wallet.TransactionCallback)) {
 	ws.listeners = append(ws.listeners, callback)
 }
@@ -179,6 +278,25 @@ func (ws *WalletService) ProcessIncomingTransaction(tx model.Transaction) {
 	}
 }
 
+// IsRelevant returns whether tx spends from or pays a key or watched script of
+// the wallet.
+func (ws *WalletService) IsRelevant(tx model.Transaction) bool {
+	addrs := ws.getStoredAddresses()
+	for _, in := range tx.Inputs {
+		if _, ok := addrs[in.Addr]; ok {
+			return true
+		}
+	}
+	for _, out := range tx.Outputs {
+		for _, addr := range out.ScriptPubKey.Addresses {
+			if _, ok := addrs[addr]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // A new block was found let's update our chain height and best hash and check for a reorg
 func (ws *WalletService) processIncomingBlock(block model.Block) {
 	Log.Infof("received new %s block at height %d: %s", ws.coinType.String(), block.Height, block.Hash)
@@ -212,12 +330,31 @@ func (ws *WalletService) processIncomingBlock(block model.Block) {
 		return
 	}
 	addrs := ws.getStoredAddresses()
+	ws.checkEscrowTimeouts()
 	for _, tx := range txs {
 		if tx.Height == 0 {
 			Log.Debugf("broadcasting unconfirmed txid %s", tx.Txid)
 			go func(txn wallet.Txn) {
+				var (
+					expired bool
+					spent   []wire.OutPoint
+				)
+				if ws.txExpiry != nil {
+					expiry, outpoints, err := ws.txExpiry(txn.Bytes)
+					if err != nil {
+						Log.Warningf("reading expiry of %s tx %s: %s", ws.coinType.String(), txn.Txid, err.Error())
+					} else {
+						// The block after the expiry height can't include it
+						expired = expiry > 0 && uint32(block.Height) >= expiry
+						spent = outpoints
+					}
+				}
 				ret, err := ws.client.GetTransaction(txn.Txid)
 				if err != nil {
+					if expired {
+						ws.markTxDead(txn, spent, addrs)
+						return
+					}
 					Log.Errorf("error fetching unconfirmed %s tx: %s", ws.coinType.String(), err.Error())
 					return
 				}
@@ -235,8 +372,12 @@ func (ws *WalletService) processIncomingBlock(block model.Block) {
 					}
 					return
 				}
+				if expired {
+					ws.markTxDead(txn, spent, addrs)
+					return
+				}
 				// Rebroadcast unconfirmed transactions
-				_, err = ws.client.Broadcast(tx.Bytes)
+				_, err = ws.client.Broadcast(txn.Bytes)
 				if err != nil {
 					Log.Errorf("broadcasting unconfirmed utxo: %s", err.Error())
 				}
@@ -245,6 +386,114 @@ func (ws *WalletService) processIncomingBlock(block model.Block) {
 	}
 }
 
+// markTxDead marks an expired transaction as dead, deletes the outputs it
+// created and restores the wallet outputs it spent so they can be spent again,
+// unless a confirmed transaction spent them meanwhile.
+func (ws *WalletService) markTxDead(txn wallet.Txn, spent []wire.OutPoint, addrs map[string]storedAddress) {
+	Log.Noticef("%s tx %s expired: marking it dead", ws.coinType.String(), txn.Txid)
+	txHash, err := chainhash.NewHashFromStr(txn.Txid)
+	if err != nil {
+		Log.Errorf("error converting to txHash for %s: %s", ws.coinType.String(), err.Error())
+		return
+	}
+	if err := ws.db.Txns().UpdateHeight(*txHash, -1, txn.Timestamp); err != nil {
+		Log.Errorf("marking tx (%s) dead: %s", txn.Txid, err.Error())
+		return
+	}
+
+	utxos, err := ws.db.Utxos().GetAll()
+	if err != nil {
+		Log.Errorf("error loading %s utxos: %s", ws.coinType.String(), err.Error())
+	}
+	for _, u := range utxos {
+		if u.Op.Hash.IsEqual(txHash) {
+			if err := ws.db.Utxos().Delete(u); err != nil {
+				Log.Errorf("deleting utxo of dead tx (%s): %s", txn.Txid, err.Error())
+			}
+		}
+	}
+
+	ws.lock.RLock()
+	chainHeight := int32(ws.chainHeight)
+	ws.lock.RUnlock()
+	if len(spent) > 0 {
+		confirmedSpends, err := ws.confirmedSpends(addrs)
+		if err != nil {
+			// The outputs are restored by the next full sync if unspent
+			Log.Errorf("fetching %s txs spending the outputs of dead tx (%s): %s", ws.coinType.String(), txn.Txid, err.Error())
+			spent = nil
+		}
+		var unspent []wire.OutPoint
+		for _, op := range spent {
+			if !confirmedSpends[op] {
+				unspent = append(unspent, op)
+			}
+		}
+		spent = unspent
+	}
+	for _, op := range spent {
+		prev, err := ws.client.GetTransaction(op.Hash.String())
+		if err != nil {
+			Log.Errorf("fetching %s tx (%s) spent by dead tx: %s", ws.coinType.String(), op.Hash.String(), err.Error())
+			continue
+		}
+		for _, out := range prev.Outputs {
+			if out.N != int(op.Index) || len(out.ScriptPubKey.Addresses) == 0 {
+				continue
+			}
+			if _, ok := addrs[out.ScriptPubKey.Addresses[0]]; !ok {
+				break
+			}
+			utxo := model.Utxo{
+				Txid:          prev.Txid,
+				ScriptPubKey:  out.ScriptPubKey.Hex,
+				Satoshis:      int64(math.Round(out.Value * float64(util.SatoshisPerCoin(ws.coinType.ToCoinType())))),
+				Vout:          out.N,
+				Address:       out.ScriptPubKey.Addresses[0],
+				Confirmations: prev.Confirmations,
+				Amount:        out.Value,
+			}
+			ws.saveSingleUtxoToDB(utxo, addrs, chainHeight)
+			break
+		}
+	}
+
+	ws.callbackListeners(wallet.TransactionCallback{
+		Txid:      txn.Txid,
+		Value:     txn.Value,
+		Height:    -1,
+		Timestamp: txn.Timestamp,
+		WatchOnly: txn.WatchOnly,
+	})
+}
+
+// confirmedSpends returns the outputs spent by the confirmed transactions of
+// the wallet addresses
+func (ws *WalletService) confirmedSpends(addrs map[string]storedAddress) (map[wire.OutPoint]bool, error) {
+	var query []btcutil.Address
+	for _, sa := range addrs {
+		query = append(query, sa.Addr)
+	}
+	txs, err := ws.client.GetTransactions(query)
+	if err != nil {
+		return nil, err
+	}
+	spends := make(map[wire.OutPoint]bool)
+	for _, tx := range txs {
+		if tx.Confirmations <= 0 {
+			continue
+		}
+		for _, in := range tx.Inputs {
+			h, err := chainhash.NewHashFromStr(in.Txid)
+			if err != nil {
+				continue
+			}
+			spends[*wire.NewOutPoint(h, uint32(in.Vout))] = true
+		}
+	}
+	return spends, nil
+}
+
 // updateState will query the API for both UTXOs and TXs relevant to our wallet and then update
 // the db state to match the API responses.
 func (ws *WalletService) UpdateState() {
@@ -325,6 +574,7 @@ func (ws *WalletService) saveUtxosToDB(utxos []model.Utxo, addrs map[string]stor
 			}
 		}
 	}
+	ws.checkEscrowTimeouts()
 }
 
 func (ws *WalletService) saveSingleUtxoToDB(u model.Utxo, addrs map[string]storedAddress, chainHeight int32) {
@@ -419,6 +669,7 @@ func (ws *WalletService) saveSingleTxToDB(u model.Transaction, chainHeight int32
 		return
 	}
 	var relevant bool
+	var received []btcutil.Address
 	cb := wallet.TransactionCallback{Txid: txHash.String(), Height: height, Timestamp: time.Unix(u.Time, 0)}
 	for _, in := range u.Inputs {
 		ch, err := chainhash.NewHashFromStr(in.Txid)
@@ -432,7 +683,7 @@ func (ws *WalletService) saveSingleTxToDB(u model.Transaction, chainHeight int32
 			continue
 		}
 		op := wire.NewOutPoint(ch, uint32(in.Vout))
-		addr, err := util.DecodeAddress(in.Addr, ws.params)
+		addr, err := ws.decodeAddress(in.Addr)
 		if err != nil {
 			// Some addresses may not decode and we can still process them normally
 			addr = nil
@@ -473,19 +724,23 @@ func (ws *WalletService) saveSingleTxToDB(u model.Transaction, chainHeight int32
 		}
 		var addr btcutil.Address
 		if len(out.ScriptPubKey.Addresses) > 0 && out.ScriptPubKey.Addresses[0] != "" {
-			addr, err = util.DecodeAddress(out.ScriptPubKey.Addresses[0], ws.params)
+			addr, err = ws.decodeAddress(out.ScriptPubKey.Addresses[0])
 			if err != nil {
 				// Some addresses may not decode and we can still process them normally
 				addr = nil
 			}
 		}
 
+		v := int64(math.Round(out.Value * float64(util.SatoshisPerCoin(ws.coinType.ToCoinType()))))
+
+		if _, ok := util.ExtractNullData(script); ok {
+			msgTx.TxOut = append(msgTx.TxOut, wire.NewTxOut(v, script))
+			continue
+		}
 		if len(out.ScriptPubKey.Addresses) == 0 {
 			continue
 		}
 
-		v := int64(math.Round(out.Value * float64(util.SatoshisPerCoin(ws.coinType.ToCoinType()))))
-
 		txout := wire.NewTxOut(v, script)
 		msgTx.TxOut = append(msgTx.TxOut, txout)
 		cbout := wallet.TransactionOutput{Address: addr, Value: v, Index: uint32(i)}
@@ -495,6 +750,7 @@ func (ws *WalletService) saveSingleTxToDB(u model.Transaction, chainHeight int32
 		if !ok {
 			continue
 		}
+		received = append(received, sa.Addr)
 		if !sa.WatchOnly {
 			value += v
 			hits++
@@ -512,6 +768,10 @@ func (ws *WalletService) saveSingleTxToDB(u model.Transaction, chainHeight int32
 		return
 	}
 
+	ws.notifySpends(u, msgTx, height)
+	ws.indexTx(u, addrs)
+	ws.labelIncoming(txHash.String(), received)
+
 	cb.Value = value
 	cb.WatchOnly = (hits == 0)
 	saved, err := ws.db.Txns().Get(*txHash)
@@ -576,44 +836,18 @@ func (ws *WalletService) getStoredAddresses() map[string]storedAddress {
 		return addrs
 	}
 
+	coin, ok := registry.Lookup(ws.coinType)
+	if !ok || coin.ScriptToAddress == nil {
+		if len(watchScripts) > 0 {
+			Log.Warningf("no script codec registered for %s, ignoring watched scripts", ws.coinType.String())
+		}
+		return addrs
+	}
 	for _, script := range watchScripts {
-		var addr btcutil.Address
-		switch ws.coinType {
-		case util.ExtendCoinType(wallet.Bitcoin):
-			_, addrSlice, _, err := txscript.ExtractPkScriptAddrs(script, ws.params)
-			if err != nil {
-				Log.Warningf("error serializing %s script: %s", ws.coinType.String(), err.Error())
-				continue
-			}
-			if len(addrs) == 0 {
-				Log.Warningf("error serializing %s script: %s", ws.coinType.String(), "Unknown script")
-				continue
-			}
-			addr = addrSlice[0]
-		case util.ExtendCoinType(wallet.BitcoinCash):
-			cashAddr, err := bchutil.ExtractPkScriptAddrs(script, ws.params)
-			if err != nil {
-				Log.Warningf("error serializing %s script: %s", ws.coinType.String(), err.Error())
-				continue
-			}
-			addr = cashAddr
-		case util.ExtendCoinType(wallet.Zcash):
-			zAddr, err := zaddr.ExtractPkScriptAddrs(script, ws.params)
-			if err != nil {
-				Log.Warningf("error serializing %s script: %s", ws.coinType.String(), err.Error())
-				continue
-			}
-			addr = zAddr
-		case util.ExtendCoinType(wallet.Litecoin):
-			ltcAddr, err := laddr.ExtractPkScriptAddrs(script, ws.params)
-			if err != nil {
-				Log.Warningf("error serializing %s script: %s", ws.coinType.String(), err.Error())
-				continue
-			}
-			addr = ltcAddr
-			case util.CoinTypeMonetaryUnit:
-			//soon
-			Log.Warningf("Not implemented yet for MonetaryUnit.")
+		addr, err := coin.ScriptToAddress(script, ws.params)
+		if err != nil {
+			Log.Warningf("error serializing %s script: %s", ws.coinType.String(), err.Error())
+			continue
 		}
 		addrs[addr.String()] = storedAddress{addr, true}
 	}
@@ -621,6 +855,15 @@ func (ws *WalletService) getStoredAddresses() map[string]storedAddress {
 	return addrs
 }
 
+// decodeAddress decodes addr with the address codec registered for the coin
+func (ws *WalletService) decodeAddress(addr string) (btcutil.Address, error) {
+	coin, ok := registry.Lookup(ws.coinType)
+	if !ok || coin.DecodeAddress == nil {
+		return nil, fmt.Errorf("no address codec registered for %s", ws.coinType.String())
+	}
+	return coin.DecodeAddress(addr, ws.params)
+}
+
 func (ws *WalletService) saveHashAndHeight(hash string, height uint32) error {
 	hh := HashAndHeight{
 		Height:    height,
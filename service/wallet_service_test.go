@@ -2,22 +2,70 @@ package service
 
 import (
 	"encoding/hex"
+	"errors"
 	"strconv"
 	"testing"
 	"time"
 
+	btcaddr "github.com/muecoin/multiwallet/bitcoin/address"
 	"github.com/muecoin/multiwallet/cache"
+	"github.com/muecoin/multiwallet/config"
 	"github.com/muecoin/multiwallet/datastore"
 	"github.com/muecoin/multiwallet/keys"
+	laddr "github.com/muecoin/multiwallet/litecoin/address"
+	"github.com/muecoin/multiwallet/model"
 	"github.com/muecoin/multiwallet/model/mock"
+	"github.com/muecoin/multiwallet/registry"
+	"github.com/muecoin/multiwallet/util"
+	zaddr "github.com/muecoin/multiwallet/zcash/address"
 	"github.com/OpenBazaar/wallet-interface"
 	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
 	"github.com/btcsuite/btcutil"
 	"github.com/btcsuite/btcutil/hdkeychain"
+	"github.com/cpacia/bchutil"
 	"github.com/ltcsuite/ltcd/chaincfg/chainhash"
+	"golang.org/x/net/proxy"
 )
 
+// The coin packages register their coins but import this package, so the
+// tests register the script codecs the wallet service needs themselves.
+func init() {
+	for _, c := range []registry.Coin{
+		{
+			Name:            "Bitcoin",
+			CoinType:        util.ExtendCoinType(wallet.Bitcoin),
+			TestnetCoinType: util.ExtendCoinType(wallet.TestnetBitcoin),
+			DecodeAddress:   btcaddr.DecodeAddress,
+			ScriptToAddress: btcaddr.ExtractPkScriptAddrs,
+		},
+		{
+			Name:            "Bitcoin Cash",
+			CoinType:        util.ExtendCoinType(wallet.BitcoinCash),
+			TestnetCoinType: util.ExtendCoinType(wallet.TestnetBitcoinCash),
+			ScriptToAddress: bchutil.ExtractPkScriptAddrs,
+		},
+		{
+			Name:            "Zcash",
+			CoinType:        util.ExtendCoinType(wallet.Zcash),
+			TestnetCoinType: util.ExtendCoinType(wallet.TestnetZcash),
+			ScriptToAddress: zaddr.ExtractPkScriptAddrs,
+		},
+		{
+			Name:            "Litecoin",
+			CoinType:        util.ExtendCoinType(wallet.Litecoin),
+			TestnetCoinType: util.ExtendCoinType(wallet.TestnetLitecoin),
+			ScriptToAddress: laddr.ExtractPkScriptAddrs,
+		},
+	} {
+		c.NewWallet = func(config.CoinConfig, string, *chaincfg.Params, proxy.Dialer, cache.Cacher, bool) (wallet.Wallet, error) {
+			return nil, errors.New("no wallets in service tests")
+		}
+		registry.Register(c)
+	}
+}
+
 func mockWalletService() (*WalletService, error) {
 	datastore := datastore.NewMockMultiwalletDatastore()
 
@@ -45,6 +93,16 @@ func mockWalletService() (*WalletService, error) {
 	return NewWalletService(db, km, cli, params, wallet.Bitcoin, cache.NewMockCacher())
 }
 
+// restart returns a new wallet service on the datastore of ws with an empty
+// cache
+func restart(t *testing.T, ws *WalletService) *WalletService {
+	restarted, err := NewWalletService(ws.db, ws.km, ws.client, ws.params, ws.coinType, cache.NewMockCacher())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return restarted
+}
+
 func bitcoinAddress(key *hdkeychain.ExtendedKey, params *chaincfg.Params) (btcutil.Address, error) {
 	return key.Address(params)
 }
@@ -282,6 +340,22 @@ func TestWalletService_ProcessIncomingTransaction(t *testing.T) {
 	}
 }
 
+func TestWalletService_IsRelevant(t *testing.T) {
+	ws, err := mockWalletService()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ws.IsRelevant(mock.MockTransactions[0]) {
+		t.Error("Transaction paying the wallet is not relevant")
+	}
+	tx := mock.MockTransactions[0]
+	tx.Inputs = []model.Input{{Txid: tx.Inputs[0].Txid, Vout: tx.Inputs[0].Vout}}
+	tx.Outputs = []model.Output{{N: 0, ScriptPubKey: model.OutScript{Addresses: []string{"1AhsMpyyyVyPZ9KDUgwsX3zTDJWWSsRo4f"}}}}
+	if ws.IsRelevant(tx) {
+		t.Error("Transaction of other wallets is relevant")
+	}
+}
+
 func TestWalletService_processIncomingBlock(t *testing.T) {
 	ws, err := mockWalletService()
 	if err != nil {
@@ -355,6 +429,119 @@ func TestWalletService_processIncomingBlock(t *testing.T) {
 	}
 }
 
+// expireTx adds an unconfirmed tx spending the wallet output spent which
+// expires at the height of the third mock block, and processes the mock blocks
+// up to it
+func expireTx(t *testing.T, ws *WalletService, spentTxid string, spentIndex uint32) model.Transaction {
+	ws.chainHeight = uint32(mock.MockBlocks[0].Height)
+	ws.bestBlock = mock.MockBlocks[0].Hash
+
+	spentHash, err := chainhash.NewHashFromStr(spentTxid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spent := wire.OutPoint{Index: spentIndex}
+	copy(spent.Hash[:], spentHash[:])
+	ws.SetTxExpiry(func(rawTx []byte) (uint32, []wire.OutPoint, error) {
+		return uint32(mock.MockBlocks[2].Height), []wire.OutPoint{spent}, nil
+	})
+	tx := mock.MockTransactions[1]
+	tx.Txid = "0e0c8a6b2bc32fc5ef1e4c6b8f3ecd5eab5e6f3fc0b38cd09b6c4b3d8e3b1f2a"
+	tx.Confirmations = 0
+	// The mock API client rewrites the addresses of the shared mock
+	// transactions, so spend from a wallet address of its own
+	tx.Inputs = append([]model.Input{}, tx.Inputs...)
+	for addr := range ws.getStoredAddresses() {
+		tx.Inputs[0].Addr = addr
+		break
+	}
+	tx.RawBytes = []byte{0x00}
+	ws.ProcessIncomingTransaction(tx)
+
+	// The transaction can still be mined in the block at its expiry height
+	ws.processIncomingBlock(mock.MockBlocks[1])
+	time.Sleep(time.Second / 2)
+	txns, err := ws.db.Txns().GetAll(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txns) != 1 || txns[0].Height != 0 {
+		t.Fatal("expected a single unconfirmed tx")
+	}
+
+	// Once the chain reaches its expiry height it is dead
+	ws.processIncomingBlock(mock.MockBlocks[2])
+	time.Sleep(time.Second / 2)
+
+	txns, err = ws.db.Txns().GetAll(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txns) != 1 || txns[0].Height != -1 {
+		t.Error("failed to mark expired tx dead")
+	}
+	return tx
+}
+
+func TestWalletService_processIncomingBlockExpiredTx(t *testing.T) {
+	ws, err := mockWalletService()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var callbacks []wallet.TransactionCallback
+	ws.AddTransactionListener(func(cb wallet.TransactionCallback) {
+		callbacks = append(callbacks, cb)
+	})
+
+	// Spend the change output of the second mock transaction, which no
+	// confirmed transaction spends
+	tx := expireTx(t, ws, mock.MockTransactions[1].Txid, 1)
+
+	utxos, err := ws.db.Utxos().GetAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(utxos) != 1 {
+		t.Fatalf("expected 1 utxo but had %d", len(utxos))
+	}
+	if utxos[0].Op.Hash.String() != mock.MockTransactions[1].Txid || utxos[0].Op.Index != 1 {
+		t.Errorf("restored incorrect utxo %s", utxos[0].Op.String())
+	}
+	if utxos[0].Value != 1000000 {
+		t.Errorf("expected restored value 1000000 but had %d", utxos[0].Value)
+	}
+	if utxos[0].AtHeight != int32(mock.MockBlocks[2].Height-9) {
+		t.Error("returned incorrect utxo height")
+	}
+
+	if len(callbacks) == 0 {
+		t.Fatal("failed to fire transaction callback")
+	}
+	cb := callbacks[len(callbacks)-1]
+	if cb.Txid != tx.Txid || cb.Height != -1 {
+		t.Errorf("expected dead callback for %s but had %s at %d", tx.Txid, cb.Txid, cb.Height)
+	}
+}
+
+func TestWalletService_processIncomingBlockExpiredTxSpentElsewhere(t *testing.T) {
+	ws, err := mockWalletService()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The wallet output of the first mock transaction is also spent by the
+	// second, confirmed, mock transaction
+	expireTx(t, ws, mock.MockTransactions[0].Txid, 1)
+
+	utxos, err := ws.db.Utxos().GetAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(utxos) != 0 {
+		t.Errorf("expected the output spent by a confirmed tx not to be restored, had %d utxos", len(utxos))
+	}
+}
+
 func TestWalletService_listenersFired(t *testing.T) {
 	nCallbacks := 0
 	var response wallet.TransactionCallback
@@ -8,9 +8,13 @@ go get github.com/mattn/go-sqlite3
 go test -coverprofile=bitcoin.cover.out ./bitcoin
 go test -coverprofile=client.cover.out ./client
 go test -coverprofile=config.cover.out ./config
+go test -coverprofile=dogecoin.cover.out ./dogecoin
+go test -coverprofile=dogecoin.address.cover.out ./dogecoin/address
+go test -coverprofile=htlc.cover.out ./htlc
 go test -coverprofile=keys.cover.out ./keys
 go test -coverprofile=litecoin.cover.out ./litecoin
 go test -coverprofile=litecoin.address.cover.out ./litecoin/address
+go test -coverprofile=registry.cover.out ./registry
 go test -coverprofile=service.cover.out ./service
 go test -coverprofile=util.cover.out ./util
 go test -coverprofile=zcash.cover.out ./zcash
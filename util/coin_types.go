@@ -1,6 +1,10 @@
 package util
 
- import "github.com/OpenBazaar/wallet-interface"
+ import (
+	"sync"
+
+	"github.com/OpenBazaar/wallet-interface"
+)
 
  type ExtCoinType wallet.CoinType
 
@@ -11,6 +15,26 @@ package util
  const (
 	CoinTypeMonetaryUnit     ExtCoinType = 31
 	CoinTypeMonetaryUnitTest             = 100031
+	CoinTypeDogecoin         ExtCoinType = 3
+	CoinTypeDogecoinTest                 = 1000003
+)
+
+// RegisterCoinType names a coin type outside of wallet-interface. Coins are
+// registered through the registry package when their package is imported.
+func RegisterCoinType(c ExtCoinType, name, currencyCode string) {
+	extCoinTypesMu.Lock()
+	defer extCoinTypesMu.Unlock()
+	extCoinTypes[c] = extCoinType{name, currencyCode}
+}
+
+type extCoinType struct {
+	name         string
+	currencyCode string
+}
+
+var (
+	extCoinTypesMu sync.RWMutex
+	extCoinTypes   = make(map[ExtCoinType]extCoinType)
 )
 
  func (c *ExtCoinType) String() string {
@@ -20,14 +44,9 @@ package util
 		return str
 	}
 
- 	switch *c {
-	case CoinTypeMonetaryUnit:
-		return "MonetaryUnit"
-	case CoinTypeMonetaryUnitTest:
-		return "Testnet MonetaryUnit"
-	default:
-		return ""
-	}
+	extCoinTypesMu.RLock()
+	defer extCoinTypesMu.RUnlock()
+	return extCoinTypes[*c].name
 }
 
  func (c *ExtCoinType) CurrencyCode() string {
@@ -37,14 +56,9 @@ package util
 		return str
 	}
 
- 	switch *c {
-	case CoinTypeMonetaryUnit:
-		return "MUE"
-	case CoinTypeMonetaryUnitTest:
-		return "TMUE"
-	default:
-		return ""
-	}
+	extCoinTypesMu.RLock()
+	defer extCoinTypesMu.RUnlock()
+	return extCoinTypes[*c].currencyCode
 }
 
  func (c ExtCoinType) ToCoinType() wallet.CoinType {
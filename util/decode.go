@@ -0,0 +1,163 @@
+package util
+
+import (
+	wi "github.com/OpenBazaar/wallet-interface"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+)
+
+// DecodedTx describes a raw transaction. The fee is only known when the
+// wallet knows every output the transaction spends. ExpiryHeight is only set
+// for coins whose transactions expire.
+type DecodedTx struct {
+	Txid         chainhash.Hash
+	Version      int32
+	LockTime     uint32
+	ExpiryHeight uint32
+	Size         int
+	VSize        int
+	Inputs       []DecodedInput
+	Outputs      []DecodedOutput
+	Fee          int64
+	FeeKnown     bool
+	RBF          bool
+}
+
+// DecodedInput is an input of a decoded transaction. Address and Value are
+// only set if Known, when the wallet knows the output it spends.
+type DecodedInput struct {
+	OutPoint wire.OutPoint
+	Sequence uint32
+	Address  btcutil.Address
+	Value    int64
+	Known    bool
+}
+
+// DecodedOutput is an output of a decoded transaction. Address is nil for
+// scripts without an address and Data holds the payload of data outputs.
+type DecodedOutput struct {
+	Address btcutil.Address
+	Script  []byte
+	Value   int64
+	Data    []byte
+}
+
+// DecodeTx describes tx. prevOuts holds the outputs spent by tx the wallet
+// knows, size is the serialized size of tx and vsize its virtual size.
+func DecodeTx(tx *wire.MsgTx, txid chainhash.Hash, size, vsize int, prevOuts map[wire.OutPoint]*wire.TxOut, scriptToAddress func(script []byte) (btcutil.Address, error)) *DecodedTx {
+	decoded := &DecodedTx{
+		Txid:     txid,
+		Version:  tx.Version,
+		LockTime: tx.LockTime,
+		Size:     size,
+		VSize:    vsize,
+		FeeKnown: true,
+		RBF:      SignalsRBF(tx),
+	}
+	for _, in := range tx.TxIn {
+		input := DecodedInput{
+			OutPoint: in.PreviousOutPoint,
+			Sequence: in.Sequence,
+		}
+		if prevOut, ok := prevOuts[in.PreviousOutPoint]; ok {
+			input.Known = true
+			input.Value = prevOut.Value
+			if addr, err := scriptToAddress(prevOut.PkScript); err == nil {
+				input.Address = addr
+			}
+			decoded.Fee += prevOut.Value
+		} else {
+			decoded.FeeKnown = false
+		}
+		decoded.Inputs = append(decoded.Inputs, input)
+	}
+	for _, out := range tx.TxOut {
+		output := DecodedOutput{
+			Script: out.PkScript,
+			Value:  out.Value,
+		}
+		if addr, err := scriptToAddress(out.PkScript); err == nil {
+			output.Address = addr
+		}
+		if data, ok := ExtractNullData(out.PkScript); ok {
+			output.Data = data
+		}
+		decoded.Fee -= out.Value
+		decoded.Outputs = append(decoded.Outputs, output)
+	}
+	if !decoded.FeeKnown {
+		decoded.Fee = 0
+	}
+	return decoded
+}
+
+// KnownPrevOuts returns the outputs spent by tx found among the wallet's
+// unspent outputs or in its stored transactions, which parse deserializes.
+func KnownPrevOuts(tx *wire.MsgTx, utxos []wi.Utxo, txns []wi.Txn, parse func(raw []byte) (*wire.MsgTx, error)) map[wire.OutPoint]*wire.TxOut {
+	txmap := make(map[string]wi.Txn)
+	for _, txn := range txns {
+		txmap[txn.Txid] = txn
+	}
+	prevOuts := make(map[wire.OutPoint]*wire.TxOut)
+	for _, in := range tx.TxIn {
+		op := in.PreviousOutPoint
+		for _, u := range utxos {
+			if OutPointsEqual(u.Op, op) {
+				prevOuts[op] = wire.NewTxOut(u.Value, u.ScriptPubkey)
+				break
+			}
+		}
+		if _, ok := prevOuts[op]; ok {
+			continue
+		}
+		txn, ok := txmap[op.Hash.String()]
+		if !ok {
+			continue
+		}
+		prev, err := parse(txn.Bytes)
+		if err != nil || int(op.Index) >= len(prev.TxOut) {
+			continue
+		}
+		prevOuts[op] = prev.TxOut[op.Index]
+	}
+	return prevOuts
+}
+
+// FetchPrevOuts adds the outputs spent by tx missing from prevOuts, fetching
+// the transactions creating them with fetch. Outputs that can't be fetched are
+// left out.
+func FetchPrevOuts(tx *wire.MsgTx, prevOuts map[wire.OutPoint]*wire.TxOut, fetch func(txid string) ([]byte, error), parse func(raw []byte) (*wire.MsgTx, error)) {
+	fetched := make(map[chainhash.Hash]*wire.MsgTx)
+	for _, in := range tx.TxIn {
+		op := in.PreviousOutPoint
+		if _, ok := prevOuts[op]; ok {
+			continue
+		}
+		prev, ok := fetched[op.Hash]
+		if !ok {
+			raw, err := fetch(op.Hash.String())
+			if err != nil {
+				continue
+			}
+			prev, err = parse(raw)
+			if err != nil {
+				continue
+			}
+			fetched[op.Hash] = prev
+		}
+		if int(op.Index) < len(prev.TxOut) {
+			prevOuts[op] = prev.TxOut[op.Index]
+		}
+	}
+}
+
+// SignalsRBF returns whether tx opts in to replacement as defined in BIP 125
+func SignalsRBF(tx *wire.MsgTx) bool {
+	for _, in := range tx.TxIn {
+		if in.Sequence < wire.MaxTxInSequenceNum-1 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,150 @@
+package util
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	wi "github.com/OpenBazaar/wallet-interface"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+)
+
+func TestDecodeTx(t *testing.T) {
+	payee, err := btcutil.DecodeAddress("1AhsMpyyyVyPZ9KDUgwsX3zTDJWWSsRo4f", &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payeeScript, _ := txscript.PayToAddrScript(payee)
+	data, err := NullDataOutput([]byte("order 1a3w"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	known := wire.OutPoint{Hash: chainhash.Hash{0x01}, Index: 0}
+	unknown := wire.OutPoint{Hash: chainhash.Hash{0x02}, Index: 3}
+	tx := wire.NewMsgTx(wire.TxVersion)
+	tx.AddTxIn(wire.NewTxIn(&known, nil, nil))
+	tx.AddTxOut(wire.NewTxOut(90000, payeeScript))
+	tx.AddTxOut(data)
+	prevOuts := map[wire.OutPoint]*wire.TxOut{known: wire.NewTxOut(100000, payeeScript)}
+
+	decoded := DecodeTx(tx, tx.TxHash(), tx.SerializeSize(), tx.SerializeSize(), prevOuts, scriptToAddress)
+	if decoded.Txid != tx.TxHash() || decoded.Size != tx.SerializeSize() {
+		t.Error("Returned incorrect txid or size")
+	}
+	if !decoded.FeeKnown || decoded.Fee != 10000 {
+		t.Errorf("Expected a fee of 10000 but had %d", decoded.Fee)
+	}
+	if decoded.RBF {
+		t.Error("Transaction does not signal RBF")
+	}
+	if !decoded.Inputs[0].Known || decoded.Inputs[0].Value != 100000 || decoded.Inputs[0].Address.String() != payee.String() {
+		t.Errorf("Unexpected input %+v", decoded.Inputs[0])
+	}
+	if decoded.Outputs[0].Address.String() != payee.String() || decoded.Outputs[0].Data != nil {
+		t.Errorf("Unexpected payment output %+v", decoded.Outputs[0])
+	}
+	if decoded.Outputs[1].Address != nil || !bytes.Equal(decoded.Outputs[1].Data, []byte("order 1a3w")) {
+		t.Errorf("Unexpected data output %+v", decoded.Outputs[1])
+	}
+
+	in := wire.NewTxIn(&unknown, nil, nil)
+	in.Sequence = wire.MaxTxInSequenceNum - 2
+	tx.AddTxIn(in)
+	decoded = DecodeTx(tx, tx.TxHash(), tx.SerializeSize(), tx.SerializeSize(), prevOuts, scriptToAddress)
+	if decoded.FeeKnown || decoded.Fee != 0 {
+		t.Error("Returned a fee for a transaction spending an unknown output")
+	}
+	if decoded.Inputs[1].Known || decoded.Inputs[1].Address != nil {
+		t.Errorf("Unexpected unknown input %+v", decoded.Inputs[1])
+	}
+	if !decoded.RBF {
+		t.Error("Transaction signals RBF")
+	}
+}
+
+func TestKnownPrevOuts(t *testing.T) {
+	script := []byte{txscript.OP_TRUE}
+	prev := wire.NewMsgTx(wire.TxVersion)
+	prev.AddTxIn(wire.NewTxIn(&wire.OutPoint{}, nil, nil))
+	prev.AddTxOut(wire.NewTxOut(1000, script))
+	prev.AddTxOut(wire.NewTxOut(2000, script))
+	var buf bytes.Buffer
+	if err := prev.Serialize(&buf); err != nil {
+		t.Fatal(err)
+	}
+	prevHash := prev.TxHash()
+
+	utxo := wire.OutPoint{Hash: chainhash.Hash{0x01}, Index: 2}
+	stored := wire.OutPoint{Hash: prevHash, Index: 1}
+	outOfRange := wire.OutPoint{Hash: prevHash, Index: 2}
+	unknown := wire.OutPoint{Hash: chainhash.Hash{0x02}, Index: 0}
+	tx := wire.NewMsgTx(wire.TxVersion)
+	for _, op := range []wire.OutPoint{utxo, stored, outOfRange, unknown} {
+		op := op
+		tx.AddTxIn(wire.NewTxIn(&op, nil, nil))
+	}
+
+	utxos := []wi.Utxo{{Op: utxo, Value: 5000, ScriptPubkey: script}}
+	txns := []wi.Txn{{Txid: prevHash.String(), Bytes: buf.Bytes()}}
+	prevOuts := KnownPrevOuts(tx, utxos, txns, func(raw []byte) (*wire.MsgTx, error) {
+		tx := wire.NewMsgTx(wire.TxVersion)
+		return tx, tx.Deserialize(bytes.NewReader(raw))
+	})
+	if len(prevOuts) != 2 {
+		t.Fatalf("Expected 2 known outputs but had %d", len(prevOuts))
+	}
+	if prevOuts[utxo].Value != 5000 {
+		t.Error("Returned incorrect unspent output")
+	}
+	if prevOuts[stored].Value != 2000 {
+		t.Error("Returned incorrect output of a stored transaction")
+	}
+}
+
+func TestFetchPrevOuts(t *testing.T) {
+	script := []byte{txscript.OP_TRUE}
+	prev := wire.NewMsgTx(wire.TxVersion)
+	prev.AddTxIn(wire.NewTxIn(&wire.OutPoint{}, nil, nil))
+	prev.AddTxOut(wire.NewTxOut(1000, script))
+	prev.AddTxOut(wire.NewTxOut(2000, script))
+	var buf bytes.Buffer
+	if err := prev.Serialize(&buf); err != nil {
+		t.Fatal(err)
+	}
+	prevHash := prev.TxHash()
+
+	known := wire.OutPoint{Hash: chainhash.Hash{0x01}, Index: 0}
+	tx := wire.NewMsgTx(wire.TxVersion)
+	for _, op := range []wire.OutPoint{known, {Hash: prevHash, Index: 0}, {Hash: prevHash, Index: 1}, {Hash: chainhash.Hash{0x02}}} {
+		op := op
+		tx.AddTxIn(wire.NewTxIn(&op, nil, nil))
+	}
+	prevOuts := map[wire.OutPoint]*wire.TxOut{known: wire.NewTxOut(5000, script)}
+
+	fetches := 0
+	fetch := func(txid string) ([]byte, error) {
+		fetches++
+		if txid != prevHash.String() {
+			return nil, errors.New("not found")
+		}
+		return buf.Bytes(), nil
+	}
+	FetchPrevOuts(tx, prevOuts, fetch, func(raw []byte) (*wire.MsgTx, error) {
+		tx := wire.NewMsgTx(wire.TxVersion)
+		return tx, tx.Deserialize(bytes.NewReader(raw))
+	})
+	if len(prevOuts) != 3 {
+		t.Fatalf("Expected 3 known outputs but had %d", len(prevOuts))
+	}
+	if prevOuts[wire.OutPoint{Hash: prevHash, Index: 1}].Value != 2000 || prevOuts[known].Value != 5000 {
+		t.Error("Returned incorrect outputs")
+	}
+	if fetches != 2 {
+		t.Errorf("Expected 2 fetches but had %d", fetches)
+	}
+}
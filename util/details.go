@@ -0,0 +1,61 @@
+package util
+
+import (
+	"github.com/btcsuite/btcutil"
+)
+
+// OutputOwner tells whom an output of a transaction pays
+type OutputOwner int
+
+const (
+	// OwnerExternal outputs pay addresses the wallet holds no key for
+	OwnerExternal OutputOwner = iota
+	// OwnerWallet outputs pay receiving addresses of the wallet
+	OwnerWallet
+	// OwnerChange outputs pay change addresses of the wallet
+	OwnerChange
+)
+
+func (o OutputOwner) String() string {
+	switch o {
+	case OwnerWallet:
+		return "wallet"
+	case OwnerChange:
+		return "change"
+	default:
+		return "external"
+	}
+}
+
+// TxDetails describes a transaction of the wallet
+type TxDetails struct {
+	*DecodedTx
+	Owners        []OutputOwner
+	Confirmations uint32
+}
+
+// NewTxDetails adds the owner of each output and the confirmations of the
+// transaction to its decoded form. owner is only called for outputs paying an
+// address.
+func NewTxDetails(decoded *DecodedTx, confirmations uint32, owner func(addr btcutil.Address) OutputOwner) *TxDetails {
+	details := &TxDetails{
+		DecodedTx:     decoded,
+		Owners:        make([]OutputOwner, len(decoded.Outputs)),
+		Confirmations: confirmations,
+	}
+	for i, out := range decoded.Outputs {
+		if out.Address != nil {
+			details.Owners[i] = owner(out.Address)
+		}
+	}
+	return details
+}
+
+// FeeRate returns the fee per virtual byte the transaction pays, or zero if
+// the fee isn't known.
+func (d *TxDetails) FeeRate() float64 {
+	if !d.FeeKnown || d.VSize == 0 {
+		return 0
+	}
+	return float64(d.Fee) / float64(d.VSize)
+}
@@ -0,0 +1,52 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcutil"
+)
+
+func TestNewTxDetails(t *testing.T) {
+	change, err := btcutil.DecodeAddress("1DxGWC22a46VPEjq8YKoeVXSLzB7BA8sJS", &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payee, err := btcutil.DecodeAddress("1AhsMpyyyVyPZ9KDUgwsX3zTDJWWSsRo4f", &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := &DecodedTx{
+		VSize:    200,
+		Fee:      1000,
+		FeeKnown: true,
+		Outputs: []DecodedOutput{
+			{Address: payee, Value: 50000},
+			{Data: []byte("order 1a3w")},
+			{Address: change, Value: 49000},
+		},
+	}
+	owner := func(addr btcutil.Address) OutputOwner {
+		if addr.String() == change.String() {
+			return OwnerChange
+		}
+		return OwnerExternal
+	}
+	details := NewTxDetails(decoded, 3, owner)
+	expected := []OutputOwner{OwnerExternal, OwnerExternal, OwnerChange}
+	for i, o := range details.Owners {
+		if o != expected[i] {
+			t.Errorf("Output %d: expected owner %s but had %s", i, expected[i], o)
+		}
+	}
+	if details.Confirmations != 3 {
+		t.Error("Returned incorrect confirmations")
+	}
+	if details.FeeRate() != 5 {
+		t.Errorf("Expected a fee rate of 5 but had %f", details.FeeRate())
+	}
+	details.FeeKnown = false
+	if details.FeeRate() != 0 {
+		t.Error("Returned a fee rate for an unknown fee")
+	}
+}
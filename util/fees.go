@@ -1,6 +1,8 @@
 package util
 
 import (
+	"fmt"
+
 	"github.com/OpenBazaar/wallet-interface"
 )
 
@@ -34,3 +36,31 @@ func (fp *FeeProvider) GetFeePerByte(feeLevel wallet.FeeLevel) uint64 {
 		return fp.normalFee
 	}
 }
+
+// FeeModel prices a transaction from its estimated serialized sizes. Coins
+// which charge by size use SizeFeeModel while others, such as Zcash, count
+// logical actions instead.
+type FeeModel interface {
+	// Fee returns the fee in satoshis of a transaction of txSize bytes whose
+	// inputs take inputSize bytes and whose outputs take outputSize bytes.
+	// Models which do not charge by size may ignore feePerByte.
+	Fee(txSize, inputSize, outputSize int, feePerByte uint64) uint64
+}
+
+// SizeFeeModel charges feePerByte for every serialized byte of a transaction
+type SizeFeeModel struct{}
+
+func (SizeFeeModel) Fee(txSize, inputSize, outputSize int, feePerByte uint64) uint64 {
+	return uint64(txSize) * feePerByte
+}
+
+// RequireSizeFeeModel returns an error unless model is nil or SizeFeeModel. The
+// wallets of coins which charge by size call it to reject a configured model
+// they would otherwise ignore.
+func RequireSizeFeeModel(model FeeModel) error {
+	switch model.(type) {
+	case nil, SizeFeeModel, *SizeFeeModel:
+		return nil
+	}
+	return fmt.Errorf("unsupported fee model %T: the wallet charges a fee per byte", model)
+}
@@ -0,0 +1,20 @@
+package util
+
+import "testing"
+
+type actionFeeModel struct{}
+
+func (actionFeeModel) Fee(txSize, inputSize, outputSize int, feePerByte uint64) uint64 {
+	return 10000
+}
+
+func TestRequireSizeFeeModel(t *testing.T) {
+	for _, model := range []FeeModel{nil, SizeFeeModel{}, &SizeFeeModel{}} {
+		if err := RequireSizeFeeModel(model); err != nil {
+			t.Errorf("%T: %s", model, err)
+		}
+	}
+	if err := RequireSizeFeeModel(actionFeeModel{}); err == nil {
+		t.Error("expected a model which does not charge by size to be rejected")
+	}
+}
@@ -0,0 +1,41 @@
+package util
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// MaxNullDataSize is the largest payload relayed in an OP_RETURN output
+const MaxNullDataSize = txscript.MaxDataCarrierSize
+
+// ErrNullDataTooLarge is returned when attaching more than MaxNullDataSize
+// bytes of data to a transaction.
+var ErrNullDataTooLarge = errors.New("data output is limited to 80 bytes")
+
+// NullDataOutput returns a zero value OP_RETURN output carrying data
+func NullDataOutput(data []byte) (*wire.TxOut, error) {
+	if len(data) > MaxNullDataSize {
+		return nil, ErrNullDataTooLarge
+	}
+	script, err := txscript.NullDataScript(data)
+	if err != nil {
+		return nil, err
+	}
+	return wire.NewTxOut(0, script), nil
+}
+
+// ExtractNullData returns the payload of an OP_RETURN output script. Scripts
+// made of OP_RETURN alone carry an empty payload.
+func ExtractNullData(script []byte) ([]byte, bool) {
+	if len(script) == 0 || script[0] != txscript.OP_RETURN {
+		return nil, false
+	}
+	pushes, err := txscript.PushedData(script)
+	if err != nil {
+		return nil, false
+	}
+	return bytes.Join(pushes, nil), true
+}
@@ -0,0 +1,47 @@
+package util
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/btcsuite/btcd/txscript"
+)
+
+func TestNullDataOutput(t *testing.T) {
+	for _, size := range []int{0, 32, 75, 76, MaxNullDataSize} {
+		data := bytes.Repeat([]byte{0xab}, size)
+		out, err := NullDataOutput(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if out.Value != 0 {
+			t.Errorf("Expected a zero value output but had %d", out.Value)
+		}
+		if class := txscript.GetScriptClass(out.PkScript); class != txscript.NullDataTy {
+			t.Errorf("Expected a null data script for %d bytes but had %s", size, class)
+		}
+		extracted, ok := ExtractNullData(out.PkScript)
+		if !ok || !bytes.Equal(extracted, data) {
+			t.Errorf("Failed to extract %d bytes of data", size)
+		}
+	}
+	if _, err := NullDataOutput(make([]byte, MaxNullDataSize+1)); err != ErrNullDataTooLarge {
+		t.Errorf("Expected ErrNullDataTooLarge but had %v", err)
+	}
+}
+
+func TestExtractNullData(t *testing.T) {
+	p2pkh := []byte{txscript.OP_DUP, txscript.OP_HASH160, txscript.OP_DATA_20}
+	p2pkh = append(p2pkh, make([]byte, 20)...)
+	p2pkh = append(p2pkh, txscript.OP_EQUALVERIFY, txscript.OP_CHECKSIG)
+	if _, ok := ExtractNullData(p2pkh); ok {
+		t.Error("Extracted data from a P2PKH script")
+	}
+	data, ok := ExtractNullData([]byte{txscript.OP_RETURN})
+	if !ok || len(data) != 0 {
+		t.Error("Expected an empty payload for a bare OP_RETURN")
+	}
+	if _, ok := ExtractNullData([]byte{txscript.OP_RETURN, txscript.OP_DATA_4, 0x01}); ok {
+		t.Error("Extracted data from a truncated push")
+	}
+}
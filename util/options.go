@@ -0,0 +1,32 @@
+package util
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// IntOption reads the whole number option key from a coin config's Options,
+// where it may be an int, a float64 as decoded from JSON, or a string. It
+// returns def when the option is not set.
+func IntOption(options map[string]interface{}, key string, def int64) (int64, error) {
+	switch v := options[key].(type) {
+	case nil:
+		return def, nil
+	case int:
+		return int64(v), nil
+	case float64:
+		n := int64(v)
+		if float64(n) != v {
+			return 0, fmt.Errorf("invalid %s: %v", key, v)
+		}
+		return n, nil
+	case string:
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid %s: %v", key, v)
+		}
+		return n, nil
+	default:
+		return 0, fmt.Errorf("invalid %s: %v", key, v)
+	}
+}
@@ -0,0 +1,27 @@
+package util
+
+import "testing"
+
+func TestIntOption(t *testing.T) {
+	options := map[string]interface{}{
+		"int":      7,
+		"float":    float64(40),
+		"string":   "25",
+		"fraction": 2.5,
+		"word":     "three",
+		"bool":     true,
+	}
+	for key, expected := range map[string]int64{"int": 7, "float": 40, "string": 25, "missing": 12} {
+		n, err := IntOption(options, key, 12)
+		if err != nil {
+			t.Errorf("unexpected error reading %s: %s", key, err.Error())
+		} else if n != expected {
+			t.Errorf("expected %d for %s, got %d", expected, key, n)
+		}
+	}
+	for _, key := range []string{"fraction", "word", "bool"} {
+		if _, err := IntOption(options, key, 12); err == nil {
+			t.Errorf("expected %s to be rejected", key)
+		}
+	}
+}
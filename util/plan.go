@@ -0,0 +1,154 @@
+package util
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcutil/coinset"
+	hd "github.com/btcsuite/btcutil/hdkeychain"
+)
+
+// ErrStalePlan is returned when executing a spend plan whose inputs the
+// wallet can no longer spend, for example because another spend used them.
+var ErrStalePlan = errors.New("the inputs of the spend plan are no longer spendable")
+
+// PlannedInput is a wallet output spent by a spend plan
+type PlannedInput struct {
+	OutPoint wire.OutPoint
+	Value    int64
+	Address  btcutil.Address
+	Sequence uint32
+}
+
+// PlannedOutput is an output created by a spend plan. Address is nil for data
+// outputs.
+type PlannedOutput struct {
+	Address btcutil.Address
+	Script  []byte
+	Value   int64
+	Change  bool
+}
+
+// SpendPlan is an unsigned spend transaction. Its inputs and outputs are in
+// the order they are signed and broadcast in, so executing the plan spends
+// exactly what it describes. VSize is the estimated virtual size of the
+// signed transaction the fee was computed for and DroppedChange is the value
+// of a change output left out of the transaction and paid to the fee because
+// it was dust.
+type SpendPlan struct {
+	Inputs        []PlannedInput
+	Outputs       []PlannedOutput
+	Fee           int64
+	FeePerByte    uint64
+	VSize         int
+	DroppedChange int64
+}
+
+// NewSpendPlan returns the plan of the unsigned transaction tx. prevOuts holds
+// every output tx spends and outputs paying changeScript are change.
+func NewSpendPlan(tx *wire.MsgTx, prevOuts map[wire.OutPoint]*wire.TxOut, changeScript []byte, scriptToAddress func(script []byte) (btcutil.Address, error)) (*SpendPlan, error) {
+	plan := new(SpendPlan)
+	for _, in := range tx.TxIn {
+		prevOut, ok := prevOuts[in.PreviousOutPoint]
+		if !ok {
+			return nil, errors.New("previous output of an input not found")
+		}
+		input := PlannedInput{
+			OutPoint: in.PreviousOutPoint,
+			Value:    prevOut.Value,
+			Sequence: in.Sequence,
+		}
+		if addr, err := scriptToAddress(prevOut.PkScript); err == nil {
+			input.Address = addr
+		}
+		plan.Fee += prevOut.Value
+		plan.Inputs = append(plan.Inputs, input)
+	}
+	for _, out := range tx.TxOut {
+		output := PlannedOutput{
+			Script: out.PkScript,
+			Value:  out.Value,
+			Change: len(changeScript) > 0 && bytes.Equal(out.PkScript, changeScript),
+		}
+		if addr, err := scriptToAddress(out.PkScript); err == nil {
+			output.Address = addr
+		}
+		plan.Fee -= out.Value
+		plan.Outputs = append(plan.Outputs, output)
+	}
+	if plan.Fee < 0 {
+		return nil, errors.New("transaction outputs exceed its inputs")
+	}
+	return plan, nil
+}
+
+// FeeRate returns the fee per virtual byte the plan pays
+func (p *SpendPlan) FeeRate() float64 {
+	if p.VSize == 0 {
+		return 0
+	}
+	return float64(p.Fee) / float64(p.VSize)
+}
+
+// DustChangeDropped returns whether change was paid to the fee because it was
+// dust.
+func (p *SpendPlan) DustChangeDropped() bool {
+	return p.DroppedChange > 0
+}
+
+// Recipients returns the addresses the plan pays other than change
+func (p *SpendPlan) Recipients() []btcutil.Address {
+	var addrs []btcutil.Address
+	for _, out := range p.Outputs {
+		if out.Address != nil && !out.Change {
+			addrs = append(addrs, out.Address)
+		}
+	}
+	return addrs
+}
+
+// Tx returns the unsigned transaction of the plan
+func (p *SpendPlan) Tx() *wire.MsgTx {
+	tx := wire.NewMsgTx(wire.TxVersion)
+	for _, in := range p.Inputs {
+		op := in.OutPoint
+		txIn := wire.NewTxIn(&op, nil, nil)
+		txIn.Sequence = in.Sequence
+		tx.AddTxIn(txIn)
+	}
+	for _, out := range p.Outputs {
+		tx.AddTxOut(wire.NewTxOut(out.Value, out.Script))
+	}
+	return tx
+}
+
+// Coins returns the coins of coinMap the plan spends. It returns
+// ErrStalePlan if one of the inputs is not in coinMap any more.
+func (p *SpendPlan) Coins(coinMap map[coinset.Coin]*hd.ExtendedKey) (map[coinset.Coin]*hd.ExtendedKey, error) {
+	if len(p.Inputs) == 0 {
+		return nil, errors.New("spend plan has no inputs")
+	}
+	byOutPoint := make(map[wire.OutPoint]coinset.Coin, len(coinMap))
+	for c := range coinMap {
+		byOutPoint[*wire.NewOutPoint(c.Hash(), c.Index())] = c
+	}
+	coins := make(map[coinset.Coin]*hd.ExtendedKey, len(p.Inputs))
+	fee := int64(0)
+	for _, in := range p.Inputs {
+		c, ok := byOutPoint[in.OutPoint]
+		if !ok || int64(c.Value()) != in.Value {
+			return nil, ErrStalePlan
+		}
+		coins[c] = coinMap[c]
+		fee += in.Value
+	}
+	for _, out := range p.Outputs {
+		fee -= out.Value
+	}
+	if fee != p.Fee || len(coins) != len(p.Inputs) {
+		return nil, errors.New("spend plan fee does not match its inputs and outputs")
+	}
+	return coins, nil
+}
@@ -0,0 +1,126 @@
+package util
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcutil/coinset"
+	hd "github.com/btcsuite/btcutil/hdkeychain"
+)
+
+func scriptToAddress(script []byte) (btcutil.Address, error) {
+	_, addrs, _, err := txscript.ExtractPkScriptAddrs(script, &chaincfg.MainNetParams)
+	if err != nil || len(addrs) == 0 {
+		return nil, err
+	}
+	return addrs[0], nil
+}
+
+func TestNewSpendPlan(t *testing.T) {
+	payee, err := btcutil.DecodeAddress("1AhsMpyyyVyPZ9KDUgwsX3zTDJWWSsRo4f", &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	change, err := btcutil.DecodeAddress("1DxGWC22a46VPEjq8YKoeVXSLzB7BA8sJS", &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payeeScript, _ := txscript.PayToAddrScript(payee)
+	changeScript, _ := txscript.PayToAddrScript(change)
+	data, err := NullDataOutput([]byte("order 1a3w"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	op := wire.OutPoint{Hash: chainhash.Hash{0x01}, Index: 1}
+	tx := wire.NewMsgTx(wire.TxVersion)
+	in := wire.NewTxIn(&op, nil, nil)
+	in.Sequence = 0
+	tx.AddTxIn(in)
+	tx.AddTxOut(wire.NewTxOut(60000, payeeScript))
+	tx.AddTxOut(data)
+	tx.AddTxOut(wire.NewTxOut(39000, changeScript))
+	prevOuts := map[wire.OutPoint]*wire.TxOut{op: wire.NewTxOut(100000, changeScript)}
+
+	plan, err := NewSpendPlan(tx, prevOuts, changeScript, scriptToAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.Fee != 1000 {
+		t.Errorf("Expected a fee of 1000 but had %d", plan.Fee)
+	}
+	if plan.Inputs[0].Value != 100000 || plan.Inputs[0].Address.String() != change.String() {
+		t.Errorf("Unexpected input %+v", plan.Inputs[0])
+	}
+	if plan.Outputs[0].Change || plan.Outputs[0].Address.String() != payee.String() {
+		t.Errorf("Unexpected payment output %+v", plan.Outputs[0])
+	}
+	if plan.Outputs[1].Address != nil {
+		t.Error("Data output has an address")
+	}
+	if !plan.Outputs[2].Change {
+		t.Error("Change output is not marked as change")
+	}
+	if recipients := plan.Recipients(); len(recipients) != 1 || recipients[0].String() != payee.String() {
+		t.Errorf("Expected the payee as only recipient but had %v", recipients)
+	}
+	plan.VSize = 250
+	if plan.FeeRate() != 4 {
+		t.Errorf("Expected a fee rate of 4 but had %f", plan.FeeRate())
+	}
+
+	var want, got bytes.Buffer
+	tx.Serialize(&want)
+	plan.Tx().Serialize(&got)
+	if !bytes.Equal(want.Bytes(), got.Bytes()) {
+		t.Error("Plan does not round trip to its transaction")
+	}
+
+	delete(prevOuts, op)
+	if _, err := NewSpendPlan(tx, prevOuts, changeScript, scriptToAddress); err == nil {
+		t.Error("Planned a transaction with an unknown input")
+	}
+}
+
+func TestSpendPlan_Coins(t *testing.T) {
+	height, utxos, toAddress, getKey, _, _, err := buildTestData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	coinMap := GatherCoins(height, utxos, toAddress, getKey)
+	var spent coinset.Coin
+	for c := range coinMap {
+		spent = c
+		break
+	}
+	plan := &SpendPlan{
+		Inputs:  []PlannedInput{{OutPoint: *wire.NewOutPoint(spent.Hash(), spent.Index()), Value: int64(spent.Value())}},
+		Outputs: []PlannedOutput{{Value: int64(spent.Value()) - 500}},
+		Fee:     500,
+	}
+	coins, err := plan.Coins(coinMap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(coins) != 1 || coins[spent] != coinMap[spent] {
+		t.Error("Returned the wrong coins")
+	}
+
+	plan.Fee = 0
+	if _, err := plan.Coins(coinMap); err == nil {
+		t.Error("Accepted a plan with a wrong fee")
+	}
+	plan.Fee = 500
+	plan.Inputs[0].Value++
+	if _, err := plan.Coins(coinMap); err != ErrStalePlan {
+		t.Errorf("Expected ErrStalePlan but had %v", err)
+	}
+	if _, err := plan.Coins(map[coinset.Coin]*hd.ExtendedKey{}); err != ErrStalePlan {
+		t.Errorf("Expected ErrStalePlan but had %v", err)
+	}
+}
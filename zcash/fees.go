@@ -0,0 +1,84 @@
+package zcash
+
+import (
+	"github.com/muecoin/multiwallet/multisig"
+	"github.com/muecoin/multiwallet/util"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// ZIP-317 constants
+const (
+	// MarginalFee is the fee in zatoshis charged per logical action
+	MarginalFee = 5000
+
+	// GraceActions is the number of logical actions every transaction is
+	// charged for at least
+	GraceActions = 2
+
+	// P2PKHStandardInputSize and P2PKHStandardOutputSize are the transparent
+	// input and output sizes which count as one logical action
+	P2PKHStandardInputSize  = 150
+	P2PKHStandardOutputSize = 34
+)
+
+// ZIP317FeeModel computes the ZIP-317 conventional fee of a transparent
+// transaction. The fee depends only on the number of logical actions so the
+// fee level chosen by the user has no effect.
+type ZIP317FeeModel struct{}
+
+func (ZIP317FeeModel) Fee(txSize, inputSize, outputSize int, feePerByte uint64) uint64 {
+	return MarginalFee * uint64(LogicalActions(inputSize, outputSize))
+}
+
+// LogicalActions returns the number of logical actions ZIP-317 charges for a
+// transparent transaction with the given total input and output sizes, which
+// is never less than GraceActions.
+func LogicalActions(inputSize, outputSize int) int {
+	actions := ceilDiv(inputSize, P2PKHStandardInputSize)
+	if outActions := ceilDiv(outputSize, P2PKHStandardOutputSize); outActions > actions {
+		actions = outActions
+	}
+	if actions < GraceActions {
+		actions = GraceActions
+	}
+	return actions
+}
+
+func ceilDiv(n, d int) int {
+	return (n + d - 1) / d
+}
+
+// estimateFee returns the fee charged by model for a transaction spending
+// inputCount inputs of inputType to txOuts, plus a P2PKH change output if
+// addChangeOutput is set. A nil model charges the ZIP-317 conventional fee.
+func estimateFee(model util.FeeModel, inputCount int, txOuts []*wire.TxOut, addChangeOutput bool, inputType InputType, feePerByte uint64) uint64 {
+	if model == nil {
+		model = ZIP317FeeModel{}
+	}
+	outputSize := SumOutputSerializeSizes(txOuts)
+	if addChangeOutput {
+		outputSize += P2PKHOutputSize
+	}
+	txSize := EstimateSerializeSize(inputCount, txOuts, addChangeOutput, inputType)
+	return model.Fee(txSize, inputCount*RedeemInputSize(inputType), outputSize, feePerByte)
+}
+
+// estimateMultisigFee returns the fee of a transaction spending inputCount
+// outputs of the multisig redeem script to txOuts.
+func estimateMultisigFee(model util.FeeModel, inputCount int, txOuts []*wire.TxOut, rs *multisig.RedeemScript, feePerByte uint64) uint64 {
+	if model == nil {
+		model = ZIP317FeeModel{}
+	}
+	txSize := EstimateMultisigSerializeSize(inputCount, txOuts, rs)
+	return model.Fee(txSize, inputCount*multisigInputSize(rs), SumOutputSerializeSizes(txOuts), feePerByte)
+}
+
+// estimateHTLCFee returns the fee of a transaction spending inputCount outputs
+// of a hash time locked contract to txOuts.
+func estimateHTLCFee(model util.FeeModel, inputCount int, txOuts []*wire.TxOut, redeem bool, feePerByte uint64) uint64 {
+	if model == nil {
+		model = ZIP317FeeModel{}
+	}
+	txSize := EstimateHTLCSerializeSize(inputCount, txOuts, redeem)
+	return model.Fee(txSize, inputCount*RedeemHTLCInputSize(redeem), SumOutputSerializeSizes(txOuts), feePerByte)
+}
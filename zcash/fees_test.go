@@ -0,0 +1,135 @@
+package zcash
+
+import (
+	"testing"
+
+	"github.com/muecoin/multiwallet/multisig"
+	"github.com/muecoin/multiwallet/util"
+	"github.com/OpenBazaar/wallet-interface"
+	"github.com/btcsuite/btcd/wire"
+)
+
+func TestZIP317FeeModel(t *testing.T) {
+	p2pkhOut := func() *wire.TxOut { return wire.NewTxOut(0, make([]byte, P2PKHPkScriptSize)) }
+	tests := []struct {
+		inputCount int
+		outputs    int
+		addChange  bool
+		inputType  InputType
+		expected   uint64
+	}{
+		// Anything up to two actions pays the grace actions
+		{1, 1, false, P2PKH, 10000},
+		{1, 1, true, P2PKH, 10000},
+		{2, 0, true, P2PKH, 10000},
+		// Inputs and outputs are counted separately, the larger side wins
+		{3, 1, true, P2PKH, 15000},
+		{1, 4, true, P2PKH, 25000},
+		{10, 1, false, P2PKH, 50000},
+		// Multisig inputs are larger than a standard input
+		{2, 1, false, P2SH_2of3_Multisig, 20000},
+		{1, 1, false, P2SH_1of2_Multisig, 10000},
+		{3, 1, false, P2SH_Multisig_Timelock_2Sigs, 35000},
+	}
+	for i, test := range tests {
+		var outs []*wire.TxOut
+		for j := 0; j < test.outputs; j++ {
+			outs = append(outs, p2pkhOut())
+		}
+		fee := estimateFee(ZIP317FeeModel{}, test.inputCount, outs, test.addChange, test.inputType, 100)
+		if fee != test.expected {
+			t.Errorf("test %d: expected fee %d, got %d", i, test.expected, fee)
+		}
+	}
+
+	// A nil model defaults to ZIP-317 while the size model charges per byte
+	outs := []*wire.TxOut{p2pkhOut()}
+	if fee := estimateFee(nil, 1, outs, true, P2PKH, 100); fee != 10000 {
+		t.Errorf("expected nil model to charge 10000, got %d", fee)
+	}
+	size := EstimateSerializeSize(1, outs, true, P2PKH)
+	if fee := estimateFee(util.SizeFeeModel{}, 1, outs, true, P2PKH, 3); fee != uint64(size)*3 {
+		t.Errorf("expected size model to charge %d, got %d", size*3, fee)
+	}
+
+	// Data outputs count towards the output size, 34 + 34 + 92 bytes is five actions
+	dataOut, err := util.NullDataOutput(make([]byte, util.MaxNullDataSize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fee := estimateFee(nil, 1, []*wire.TxOut{p2pkhOut(), dataOut}, true, P2PKH, 100); fee != 25000 {
+		t.Errorf("expected a data output to raise the fee to 25000, got %d", fee)
+	}
+}
+
+func TestEstimateMultisigFee(t *testing.T) {
+	outs := []*wire.TxOut{wire.NewTxOut(0, make([]byte, P2PKHPkScriptSize))}
+	tests := []struct {
+		inputCount int
+		threshold  int
+		keys       int
+		timelocked bool
+		expected   uint64
+	}{
+		// Up to 2 of 3 is charged as a 2 of 3 input
+		{2, 2, 3, false, estimateFee(nil, 2, outs, false, P2SH_2of3_Multisig, 100)},
+		{2, 2, 3, true, estimateFee(nil, 2, outs, false, P2SH_2of3_Multisig, 100)},
+		{1, 1, 2, false, estimateFee(nil, 1, outs, false, P2SH_2of3_Multisig, 100)},
+		// A 441 byte 3 of 5 input is three logical actions
+		{1, 3, 5, false, 15000},
+		{2, 3, 5, false, 30000},
+	}
+	for i, test := range tests {
+		rs := &multisig.RedeemScript{Threshold: test.threshold, PubKeys: make([][]byte, test.keys), Timelocked: test.timelocked}
+		if fee := estimateMultisigFee(nil, test.inputCount, outs, rs, 100); fee != test.expected {
+			t.Errorf("test %d: expected fee %d, got %d", i, test.expected, fee)
+		}
+	}
+}
+
+func TestZCashWallet_EstimateFee(t *testing.T) {
+	w, err := newMockWallet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr, err := w.DecodeAddress("t1hASvMj8e6TXWryuB3L5TKXJB7XfNioZP3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ins := make([]wallet.TransactionInput, 3)
+	outs := []wallet.TransactionOutput{{Address: addr, Value: 100000}}
+
+	// The fee per byte is ignored under ZIP-317
+	if fee := w.EstimateFee(ins, outs, 1); fee != 15000 {
+		t.Errorf("expected fee 15000, got %d", fee)
+	}
+	if fee := w.EstimateFee(ins, outs, 500); fee != 15000 {
+		t.Errorf("expected fee 15000, got %d", fee)
+	}
+
+	w.feeModel = util.SizeFeeModel{}
+	if fee := w.EstimateFee(ins, outs, 2); fee != uint64(EstimateSerializeSize(3, []*wire.TxOut{wire.NewTxOut(100000, make([]byte, P2PKHPkScriptSize))}, false, P2PKH))*2 {
+		t.Errorf("unexpected fee %d with the size fee model", fee)
+	}
+}
+
+func TestEstimateHTLCFee(t *testing.T) {
+	outs := []*wire.TxOut{wire.NewTxOut(0, make([]byte, P2PKHPkScriptSize))}
+	tests := []struct {
+		inputCount int
+		redeem     bool
+		expected   uint64
+	}{
+		// A 269 byte redeem input and a 236 byte refund input are both two
+		// logical actions
+		{1, true, 10000},
+		{1, false, 10000},
+		{2, true, 20000},
+		{3, false, 25000},
+	}
+	for i, test := range tests {
+		if fee := estimateHTLCFee(nil, test.inputCount, outs, test.redeem, 100); fee != test.expected {
+			t.Errorf("test %d: expected fee %d, got %d", i, test.expected, fee)
+		}
+	}
+}
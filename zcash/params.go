@@ -0,0 +1,56 @@
+package zcash
+
+import (
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+// NetworkUpgrade is a Zcash network upgrade. Every upgrade introduces a new
+// consensus branch ID which transactions commit to when they are signed, so a
+// transaction signed for the wrong branch is rejected by the network.
+type NetworkUpgrade struct {
+	Name             string
+	BranchID         uint32
+	ActivationHeight uint32
+
+	// TxVersion is the transaction version the wallet builds under the upgrade
+	TxVersion int32
+}
+
+var (
+	mainNetUpgrades = []NetworkUpgrade{
+		{Name: "Sapling", BranchID: 0x76b809bb, ActivationHeight: 419200, TxVersion: 4},
+		{Name: "Blossom", BranchID: 0x2bb40e60, ActivationHeight: 653600, TxVersion: 4},
+		{Name: "Heartwood", BranchID: 0xf5b9230b, ActivationHeight: 903000, TxVersion: 4},
+		{Name: "Canopy", BranchID: 0xe9ff75a6, ActivationHeight: 1046400, TxVersion: 4},
+		{Name: "NU5", BranchID: 0xc2d6d0b4, ActivationHeight: 1687104, TxVersion: 5},
+		{Name: "NU6", BranchID: 0xc8e71055, ActivationHeight: 2726400, TxVersion: 5},
+		{Name: "NU6.1", BranchID: 0x4dec4df0, ActivationHeight: 3146400, TxVersion: 5},
+	}
+	testNetUpgrades = []NetworkUpgrade{
+		{Name: "Sapling", BranchID: 0x76b809bb, ActivationHeight: 280000, TxVersion: 4},
+		{Name: "Blossom", BranchID: 0x2bb40e60, ActivationHeight: 584000, TxVersion: 4},
+		{Name: "Heartwood", BranchID: 0xf5b9230b, ActivationHeight: 903800, TxVersion: 4},
+		{Name: "Canopy", BranchID: 0xe9ff75a6, ActivationHeight: 1028500, TxVersion: 4},
+		{Name: "NU5", BranchID: 0xc2d6d0b4, ActivationHeight: 1842420, TxVersion: 5},
+		{Name: "NU6", BranchID: 0xc8e71055, ActivationHeight: 2976000, TxVersion: 5},
+		{Name: "NU6.1", BranchID: 0x4dec4df0, ActivationHeight: 3536500, TxVersion: 5},
+	}
+)
+
+// NetworkUpgradeAt returns the network upgrade in effect for a block at the
+// given height. Heights before Sapling are reported as Sapling since the
+// wallet cannot build older transaction versions. Networks other than mainnet
+// use the testnet activation heights.
+func NetworkUpgradeAt(params *chaincfg.Params, height uint32) NetworkUpgrade {
+	upgrades := testNetUpgrades
+	if params.Name == chaincfg.MainNetParams.Name {
+		upgrades = mainNetUpgrades
+	}
+	upgrade := upgrades[0]
+	for _, u := range upgrades {
+		if height >= u.ActivationHeight {
+			upgrade = u
+		}
+	}
+	return upgrade
+}
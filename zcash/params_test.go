@@ -0,0 +1,36 @@
+package zcash
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+func TestNetworkUpgradeAt(t *testing.T) {
+	tests := []struct {
+		params   *chaincfg.Params
+		height   uint32
+		name     string
+		branchID uint32
+		version  int32
+	}{
+		{&chaincfg.MainNetParams, 419200, "Sapling", 0x76b809bb, 4},
+		{&chaincfg.MainNetParams, 653599, "Sapling", 0x76b809bb, 4},
+		{&chaincfg.MainNetParams, 653600, "Blossom", 0x2bb40e60, 4},
+		{&chaincfg.MainNetParams, 903000, "Heartwood", 0xf5b9230b, 4},
+		{&chaincfg.MainNetParams, 1289595, "Canopy", 0xe9ff75a6, 4},
+		{&chaincfg.MainNetParams, 1687103, "Canopy", 0xe9ff75a6, 4},
+		{&chaincfg.MainNetParams, 1687104, "NU5", 0xc2d6d0b4, 5},
+		{&chaincfg.MainNetParams, 2726400, "NU6", 0xc8e71055, 5},
+		{&chaincfg.TestNet3Params, 1028500, "Canopy", 0xe9ff75a6, 4},
+		{&chaincfg.TestNet3Params, 1842420, "NU5", 0xc2d6d0b4, 5},
+		{&chaincfg.MainNetParams, 1, "Sapling", 0x76b809bb, 4},
+	}
+	for _, test := range tests {
+		u := NetworkUpgradeAt(test.params, test.height)
+		if u.Name != test.name || u.BranchID != test.branchID || u.TxVersion != test.version {
+			t.Errorf("%s height %d: expected %s (%x, v%d), got %s (%x, v%d)", test.params.Name, test.height,
+				test.name, test.branchID, test.version, u.Name, u.BranchID, u.TxVersion)
+		}
+	}
+}
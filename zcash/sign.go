@@ -6,6 +6,7 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
 	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/minio/blake2b-simd"
 	"time"
@@ -23,6 +24,8 @@ import (
 	"github.com/btcsuite/btcwallet/wallet/txauthor"
 	"github.com/btcsuite/btcwallet/wallet/txrules"
 
+	"github.com/muecoin/multiwallet/htlc"
+	"github.com/muecoin/multiwallet/multisig"
 	"github.com/muecoin/multiwallet/util"
 	zaddr "github.com/muecoin/multiwallet/zcash/address"
 )
@@ -35,25 +38,107 @@ var (
 	hashSequencePersonalization = []byte("ZcashSequencHash")
 	hashOutputsPersonalization  = []byte("ZcashOutputsHash")
 	sigHashPersonalization      = []byte("ZcashSigHash")
-)
 
-const (
-	sigHashMask     = 0x1f
-	saplingBranchID = 1991772603
+	txV5HeaderBytes          = []byte{0x05, 0x00, 0x00, 0x80}
+	txV5NVersionGroupIDBytes = []byte{0x0a, 0x27, 0xa7, 0x26}
+
+	txHashPersonalization          = []byte("ZcashTxHash_")
+	headersHashPersonalization     = []byte("ZTxIdHeadersHash")
+	transparentHashPersonalization = []byte("ZTxIdTranspaHash")
+	prevOutHashV5Personalization   = []byte("ZTxIdPrevoutHash")
+	sequenceHashV5Personalization  = []byte("ZTxIdSequencHash")
+	outputsHashV5Personalization   = []byte("ZTxIdOutputsHash")
+	amountsHashPersonalization     = []byte("ZTxTrAmountsHash")
+	scriptsHashPersonalization     = []byte("ZTxTrScriptsHash")
+	txInHashPersonalization        = []byte("Zcash___TxInHash")
+	saplingHashPersonalization     = []byte("ZTxIdSaplingHash")
+	orchardHashPersonalization     = []byte("ZTxIdOrchardHash")
 )
 
-func (w *ZCashWallet) buildTx(amount int64, addr btc.Address, feeLevel wi.FeeLevel, optionalOutput *wire.TxOut) (*wire.MsgTx, error) {
-	// Check for dust
-	script, err := zaddr.PayToAddrScript(addr)
+const sigHashMask = 0x1f
+
+// txParams are the consensus branch and expiry height a transaction is signed
+// and serialized with. The signatures commit to both so they must not change
+// between signing and broadcasting.
+type txParams struct {
+	upgrade      NetworkUpgrade
+	expiryHeight uint32
+}
+
+// networkUpgrade returns the network upgrade the next block will be mined
+// under. It selects the consensus branch ID and the transaction version of the
+// transactions the wallet signs.
+func (w *ZCashWallet) networkUpgrade() NetworkUpgrade {
+	height, _ := w.ws.ChainTip()
+	return NetworkUpgradeAt(w.params, height+1)
+}
+
+// newTxParams returns the parameters of a transaction built for the next
+// block. It expires expiryDelta blocks after that block, or never if the
+// delta is zero. Multisig transactions do not use it since every cosigner
+// must sign the same expiry height.
+func (w *ZCashWallet) newTxParams() txParams {
+	height, _ := w.ws.ChainTip()
+	params := txParams{upgrade: NetworkUpgradeAt(w.params, height+1)}
+	if w.expiryDelta > 0 {
+		params.expiryHeight = height + 1 + w.expiryDelta
+	}
+	return params
+}
+
+// prevOutsFor returns the outputs spent by tx in the order of its inputs.
+func prevOutsFor(tx *wire.MsgTx, prevOuts map[wire.OutPoint]*wire.TxOut) ([]*wire.TxOut, error) {
+	ordered := make([]*wire.TxOut, len(tx.TxIn))
+	for i, in := range tx.TxIn {
+		out, ok := prevOuts[in.PreviousOutPoint]
+		if !ok {
+			return nil, fmt.Errorf("previous output of input %d not found", i)
+		}
+		ordered[i] = out
+	}
+	return ordered, nil
+}
+
+func (w *ZCashWallet) buildTx(amount int64, addr btc.Address, feeLevel wi.FeeLevel, optionalOutput *wire.TxOut) (*wire.MsgTx, txParams, error) {
+	return w.buildBatchTx([]wi.TransactionOutput{{Address: addr, Value: amount}}, feeLevel, optionalOutput)
+}
+
+// buildBatchTx builds a transaction paying every payment and optionalOutput
+// if set.
+func (w *ZCashWallet) buildBatchTx(payments []wi.TransactionOutput, feeLevel wi.FeeLevel, optionalOutput *wire.TxOut) (*wire.MsgTx, txParams, error) {
+	plan, err := w.planBatchTx(payments, feeLevel, optionalOutput)
 	if err != nil {
-		return nil, err
+		return nil, txParams{}, err
 	}
-	if txrules.IsDustAmount(btc.Amount(amount), len(script), txrules.DefaultRelayFeePerKb) {
-		return nil, wi.ErrorDustAmount
+	return w.signPlan(plan)
+}
+
+func (w *ZCashWallet) planBatchTx(payments []wi.TransactionOutput, feeLevel wi.FeeLevel, optionalOutput *wire.TxOut) (*util.SpendPlan, error) {
+	if len(payments) == 0 {
+		return nil, errors.New("no payments to send")
+	}
+	var outputs []*wire.TxOut
+	for _, payment := range payments {
+		// Check for dust
+		script, err := zaddr.PayToAddrScript(payment.Address)
+		if err != nil {
+			return nil, err
+		}
+		if txrules.IsDustAmount(btc.Amount(payment.Value), len(script), txrules.DefaultRelayFeePerKb) {
+			return nil, wi.ErrorDustAmount
+		}
+		outputs = append(outputs, wire.NewTxOut(payment.Value, script))
 	}
+	if optionalOutput != nil {
+		outputs = append(outputs, optionalOutput)
+	}
+	return w.planTxWithOutputs(outputs, feeLevel)
+}
 
-	var additionalPrevScripts map[wire.OutPoint][]byte
-	var additionalKeysByAddress map[string]*btc.WIF
+// planTxWithOutputs selects the coins paying outputs at feeLevel and adds a
+// change output unless the change is dust.
+func (w *ZCashWallet) planTxWithOutputs(outputs []*wire.TxOut, feeLevel wi.FeeLevel) (*util.SpendPlan, error) {
+	var prevOuts map[wire.OutPoint]*wire.TxOut
 
 	// Create input source
 	height, _ := w.ws.ChainTip()
@@ -73,52 +158,35 @@ func (w *ZCashWallet) buildTx(amount int64, addr btc.Address, feeLevel wi.FeeLev
 		if err != nil {
 			return total, inputs, inputValues, scripts, wi.ErrorInsuffientFunds
 		}
-		additionalPrevScripts = make(map[wire.OutPoint][]byte)
-		additionalKeysByAddress = make(map[string]*btc.WIF)
+		prevOuts = make(map[wire.OutPoint]*wire.TxOut)
 		for _, c := range coins.Coins() {
 			total += c.Value()
 			outpoint := wire.NewOutPoint(c.Hash(), c.Index())
 			in := wire.NewTxIn(outpoint, []byte{}, [][]byte{})
 			in.Sequence = 0 // Opt-in RBF so we can bump fees
 			inputs = append(inputs, in)
-			additionalPrevScripts[*outpoint] = c.PkScript()
-			key := coinMap[c]
-			addr, err := key.Address(w.params)
-			if err != nil {
-				continue
-			}
-			privKey, err := key.ECPrivKey()
-			if err != nil {
-				continue
-			}
-			wif, _ := btc.NewWIF(privKey, w.params, true)
-			additionalKeysByAddress[addr.EncodeAddress()] = wif
+			prevOuts[*outpoint] = wire.NewTxOut(int64(c.Value()), c.PkScript())
 			inputValues = append(inputValues, c.Value())
 		}
 		return total, inputs, inputValues, scripts, nil
 	}
 
-	// Get the fee per kilobyte
-	feePerKB := int64(w.GetFeePerByte(feeLevel)) * 1000
-
-	// outputs
-	out := wire.NewTxOut(amount, script)
+	// Get the fee per byte, which the ZIP-317 fee model ignores
+	feePerByte := w.GetFeePerByte(feeLevel)
 
 	// Create change source
+	var changeScript []byte
 	changeSource := func() ([]byte, error) {
 		addr := w.CurrentAddress(wi.INTERNAL)
 		script, err := zaddr.PayToAddrScript(addr)
 		if err != nil {
 			return []byte{}, err
 		}
+		changeScript = script
 		return script, nil
 	}
 
-	outputs := []*wire.TxOut{out}
-	if optionalOutput != nil {
-		outputs = append(outputs, optionalOutput)
-	}
-	authoredTx, inputValues, err := newUnsignedTransaction(outputs, btc.Amount(feePerKB), inputSource, changeSource)
+	authoredTx, _, err := newUnsignedTransaction(outputs, w.feeModel, feePerByte, inputSource, changeSource)
 	if err != nil {
 		return nil, err
 	}
@@ -126,40 +194,28 @@ func (w *ZCashWallet) buildTx(amount int64, addr btc.Address, feeLevel wi.FeeLev
 	// BIP 69 sorting
 	txsort.InPlaceSort(authoredTx.Tx)
 
-	// Sign tx
-	getKey := txscript.KeyClosure(func(addr btc.Address) (*btcec.PrivateKey, bool, error) {
-		addrStr := addr.EncodeAddress()
-		wif := additionalKeysByAddress[addrStr]
-		return wif.PrivKey, wif.CompressPubKey, nil
-	})
-	for i, txIn := range authoredTx.Tx.TxIn {
-		prevOutScript := additionalPrevScripts[txIn.PreviousOutPoint]
-		_, addrs, _, err := txscript.ExtractPkScriptAddrs(prevOutScript, w.params)
-		if err != nil {
-			return nil, err
-		}
-		key, _, err := getKey(addrs[0])
-		if err != nil {
-			return nil, err
-		}
-		val := int64(inputValues[i].ToUnit(btc.AmountSatoshi))
-		sig, err := rawTxInSignature(authoredTx.Tx, i, prevOutScript, txscript.SigHashAll, key, val)
-		if err != nil {
-			return nil, errors.New("failed to sign transaction")
-		}
-		builder := txscript.NewScriptBuilder()
-		builder.AddData(sig)
-		builder.AddData(key.PubKey().SerializeCompressed())
-		script, err := builder.Script()
-		if err != nil {
-			return nil, err
-		}
-		txIn.SignatureScript = script
+	plan, err := util.NewSpendPlan(authoredTx.Tx, prevOuts, changeScript, w.ScriptToAddress)
+	if err != nil {
+		return nil, err
+	}
+	plan.FeePerByte = feePerByte
+	plan.VSize = EstimateSerializeSize(len(plan.Inputs), authoredTx.Tx.TxOut, false, P2PKH)
+	if authoredTx.ChangeIndex < 0 {
+		requiredFee := estimateFee(w.feeModel, len(plan.Inputs), outputs, true, P2PKH, feePerByte)
+		plan.DroppedChange = plan.Fee - int64(requiredFee)
+	}
+	return plan, nil
+}
+
+func (w *ZCashWallet) buildSpendAllTx(addr btc.Address, feeLevel wi.FeeLevel, optionalOutput *wire.TxOut) (*wire.MsgTx, txParams, error) {
+	plan, err := w.planSpendAllTx(addr, feeLevel, optionalOutput)
+	if err != nil {
+		return nil, txParams{}, err
 	}
-	return authoredTx.Tx, nil
+	return w.signPlan(plan)
 }
 
-func (w *ZCashWallet) buildSpendAllTx(addr btc.Address, feeLevel wi.FeeLevel) (*wire.MsgTx, error) {
+func (w *ZCashWallet) planSpendAllTx(addr btc.Address, feeLevel wi.FeeLevel, optionalOutput *wire.TxOut) (*util.SpendPlan, error) {
 	tx := wire.NewMsgTx(1)
 
 	height, _ := w.ws.ChainTip()
@@ -169,7 +225,11 @@ func (w *ZCashWallet) buildSpendAllTx(addr btc.Address, feeLevel wi.FeeLevel) (*
 	}
 	coinMap := util.GatherCoins(height, utxos, w.ScriptToAddress, w.km.GetKeyForScript)
 
-	totalIn, inVals, additionalPrevScripts, additionalKeysByAddress := util.LoadAllInputs(tx, coinMap, w.params)
+	totalIn, inVals, additionalPrevScripts, _ := util.LoadAllInputs(tx, coinMap, w.params)
+	prevOuts := make(map[wire.OutPoint]*wire.TxOut)
+	for op, script := range additionalPrevScripts {
+		prevOuts[op] = wire.NewTxOut(inVals[op], script)
+	}
 
 	// outputs
 	script, err := zaddr.PayToAddrScript(addr)
@@ -177,10 +237,14 @@ func (w *ZCashWallet) buildSpendAllTx(addr btc.Address, feeLevel wi.FeeLevel) (*
 		return nil, err
 	}
 
+	outputs := []*wire.TxOut{wire.NewTxOut(0, script)}
+	if optionalOutput != nil {
+		outputs = append(outputs, optionalOutput)
+	}
+
 	// Get the fee
-	feePerByte := int64(w.GetFeePerByte(feeLevel))
-	estimatedSize := EstimateSerializeSize(1, []*wire.TxOut{wire.NewTxOut(0, script)}, false, P2PKH)
-	fee := int64(estimatedSize) * feePerByte
+	feePerByte := w.GetFeePerByte(feeLevel)
+	fee := int64(estimateFee(w.feeModel, len(tx.TxIn), outputs, false, P2PKH, feePerByte))
 
 	// Check for dust output
 	if txrules.IsDustAmount(btc.Amount(totalIn-fee), len(script), txrules.DefaultRelayFeePerKb) {
@@ -190,10 +254,48 @@ func (w *ZCashWallet) buildSpendAllTx(addr btc.Address, feeLevel wi.FeeLevel) (*
 	// Build the output
 	out := wire.NewTxOut(totalIn-fee, script)
 	tx.TxOut = append(tx.TxOut, out)
+	if optionalOutput != nil {
+		tx.TxOut = append(tx.TxOut, optionalOutput)
+	}
 
 	// BIP 69 sorting
 	txsort.InPlaceSort(tx)
 
+	plan, err := util.NewSpendPlan(tx, prevOuts, nil, w.ScriptToAddress)
+	if err != nil {
+		return nil, err
+	}
+	plan.FeePerByte = feePerByte
+	plan.VSize = EstimateSerializeSize(len(tx.TxIn), tx.TxOut, false, P2PKH)
+	return plan, nil
+}
+
+// signPlan signs the transaction of plan for the next block. It fails if the
+// wallet can no longer spend the inputs of the plan.
+func (w *ZCashWallet) signPlan(plan *util.SpendPlan) (*wire.MsgTx, txParams, error) {
+	var params txParams
+	height, _ := w.ws.ChainTip()
+	utxos, err := w.db.Utxos().GetAll()
+	if err != nil {
+		return nil, params, err
+	}
+	coinMap, err := plan.Coins(util.GatherCoins(height, utxos, w.ScriptToAddress, w.km.GetKeyForScript))
+	if err != nil {
+		return nil, params, err
+	}
+	_, inVals, additionalPrevScripts, additionalKeysByAddress := util.LoadAllInputs(wire.NewMsgTx(1), coinMap, w.params)
+
+	tx := plan.Tx()
+	prevOutMap := make(map[wire.OutPoint]*wire.TxOut)
+	for op, script := range additionalPrevScripts {
+		prevOutMap[op] = wire.NewTxOut(inVals[op], script)
+	}
+	prevOuts, err := prevOutsFor(tx, prevOutMap)
+	if err != nil {
+		return nil, params, err
+	}
+	params = w.newTxParams()
+
 	// Sign
 	getKey := txscript.KeyClosure(func(addr btc.Address) (*btcec.PrivateKey, bool, error) {
 		addrStr := addr.EncodeAddress()
@@ -207,37 +309,36 @@ func (w *ZCashWallet) buildSpendAllTx(addr btc.Address, feeLevel wi.FeeLevel) (*
 		prevOutScript := additionalPrevScripts[txIn.PreviousOutPoint]
 		_, addrs, _, err := txscript.ExtractPkScriptAddrs(prevOutScript, w.params)
 		if err != nil {
-			return nil, err
+			return nil, params, err
 		}
 		key, _, err := getKey(addrs[0])
 		if err != nil {
-			return nil, err
+			return nil, params, err
 		}
-		sig, err := rawTxInSignature(tx, i, prevOutScript, txscript.SigHashAll, key, inVals[txIn.PreviousOutPoint])
+		sig, err := rawTxInSignature(tx, i, prevOutScript, txscript.SigHashAll, key, prevOuts, params)
 		if err != nil {
-			return nil, errors.New("failed to sign transaction")
+			return nil, params, errors.New("failed to sign transaction")
 		}
 		builder := txscript.NewScriptBuilder()
 		builder.AddData(sig)
 		builder.AddData(key.PubKey().SerializeCompressed())
 		script, err := builder.Script()
 		if err != nil {
-			return nil, err
+			return nil, params, err
 		}
 		txIn.SignatureScript = script
 	}
-	return tx, nil
+	return tx, params, nil
 }
 
-func newUnsignedTransaction(outputs []*wire.TxOut, feePerKb btc.Amount, fetchInputs txauthor.InputSource, fetchChange txauthor.ChangeSource) (*txauthor.AuthoredTx, []btc.Amount, error) {
+func newUnsignedTransaction(outputs []*wire.TxOut, feeModel util.FeeModel, feePerByte uint64, fetchInputs txauthor.InputSource, fetchChange txauthor.ChangeSource) (*txauthor.AuthoredTx, []btc.Amount, error) {
 
 	var targetAmount btc.Amount
 	for _, txOut := range outputs {
 		targetAmount += btc.Amount(txOut.Value)
 	}
 
-	estimatedSize := EstimateSerializeSize(1, outputs, true, P2PKH)
-	targetFee := txrules.FeeForSerializeSize(feePerKb, estimatedSize)
+	targetFee := btc.Amount(estimateFee(feeModel, 1, outputs, true, P2PKH, feePerByte))
 	var inputValues []btc.Amount
 	for {
 		inputAmount, inputs, vals, scripts, err := fetchInputs(targetAmount + targetFee)
@@ -249,8 +350,7 @@ func newUnsignedTransaction(outputs []*wire.TxOut, feePerKb btc.Amount, fetchInp
 			return nil, nil, errors.New("insufficient funds available to construct transaction")
 		}
 
-		maxSignedSize := EstimateSerializeSize(len(inputs), outputs, true, P2PKH)
-		maxRequiredFee := txrules.FeeForSerializeSize(feePerKb, maxSignedSize)
+		maxRequiredFee := btc.Amount(estimateFee(feeModel, len(inputs), outputs, true, P2PKH, feePerByte))
 		remainingAmount := inputAmount - targetAmount
 		if remainingAmount < maxRequiredFee {
 			targetFee = maxRequiredFee
@@ -347,11 +447,9 @@ func (w *ZCashWallet) sweepAddress(ins []wi.TransactionInput, address *btc.Addre
 
 	var val int64
 	var inputs []*wire.TxIn
-	additionalPrevScripts := make(map[wire.OutPoint][]byte)
-	var values []int64
+	additionalPrevOuts := make(map[wire.OutPoint]*wire.TxOut)
 	for _, in := range ins {
 		val += in.Value
-		values = append(values, in.Value)
 		ch, err := chainhash.NewHashFromStr(hex.EncodeToString(in.OutpointHash))
 		if err != nil {
 			return nil, err
@@ -363,7 +461,7 @@ func (w *ZCashWallet) sweepAddress(ins []wi.TransactionInput, address *btc.Addre
 		outpoint := wire.NewOutPoint(ch, in.OutpointIndex)
 		input := wire.NewTxIn(outpoint, []byte{}, [][]byte{})
 		inputs = append(inputs, input)
-		additionalPrevScripts[*outpoint] = script
+		additionalPrevOuts[*outpoint] = wire.NewTxOut(in.Value, script)
 	}
 	out := wire.NewTxOut(val, script)
 
@@ -377,11 +475,8 @@ func (w *ZCashWallet) sweepAddress(ins []wi.TransactionInput, address *btc.Addre
 	} else {
 		redeemScript = &[]byte{}
 	}
-	estimatedSize := EstimateSerializeSize(len(ins), []*wire.TxOut{out}, false, txType)
-
 	// Calculate the fee
-	feePerByte := int(w.GetFeePerByte(feeLevel))
-	fee := estimatedSize * feePerByte
+	fee := estimateFee(w.feeModel, len(ins), []*wire.TxOut{out}, false, txType, w.GetFeePerByte(feeLevel))
 
 	outVal := val - int64(fee)
 	if outVal < 0 {
@@ -398,6 +493,11 @@ func (w *ZCashWallet) sweepAddress(ins []wi.TransactionInput, address *btc.Addre
 
 	// BIP 69 sorting
 	txsort.InPlaceSort(tx)
+	prevOuts, err := prevOutsFor(tx, additionalPrevOuts)
+	if err != nil {
+		return nil, err
+	}
+	params := w.newTxParams()
 
 	// Sign tx
 	privKey, err := key.ECPrivKey()
@@ -406,7 +506,7 @@ func (w *ZCashWallet) sweepAddress(ins []wi.TransactionInput, address *btc.Addre
 	}
 
 	for i, txIn := range tx.TxIn {
-		sig, err := rawTxInSignature(tx, i, *redeemScript, txscript.SigHashAll, privKey, values[i])
+		sig, err := rawTxInSignature(tx, i, *redeemScript, txscript.SigHashAll, privKey, prevOuts, params)
 		if err != nil {
 			return nil, errors.New("failed to sign transaction")
 		}
@@ -424,41 +524,50 @@ func (w *ZCashWallet) sweepAddress(ins []wi.TransactionInput, address *btc.Addre
 	}
 
 	// broadcast
-	txid, err := w.Broadcast(tx)
+	txid, err := w.broadcast(tx, params)
 	if err != nil {
 		return nil, err
 	}
 	return chainhash.NewHashFromStr(txid)
 }
 
-func (w *ZCashWallet) createMultisigSignature(ins []wi.TransactionInput, outs []wi.TransactionOutput, key *hd.ExtendedKey, redeemScript []byte, feePerByte uint64) ([]wi.Signature, error) {
-	var sigs []wi.Signature
+// newMultisigTx returns the unsigned transaction the cosigners of the
+// redeem script sign, with the fee subtracted from the outputs, and the
+// outputs it spends.
+func (w *ZCashWallet) newMultisigTx(ins []wi.TransactionInput, outs []wi.TransactionOutput, rs *multisig.RedeemScript, feePerByte uint64) (*wire.MsgTx, map[wire.OutPoint]*wire.TxOut, error) {
 	tx := wire.NewMsgTx(1)
-	var values []int64
+	scriptAddr, err := zaddr.NewAddressScriptHash(rs.Script, w.params)
+	if err != nil {
+		return nil, nil, err
+	}
+	prevScript, err := zaddr.PayToAddrScript(scriptAddr)
+	if err != nil {
+		return nil, nil, err
+	}
+	prevOuts := make(map[wire.OutPoint]*wire.TxOut)
 	for _, in := range ins {
 		ch, err := chainhash.NewHashFromStr(hex.EncodeToString(in.OutpointHash))
 		if err != nil {
-			return sigs, err
+			return nil, nil, err
 		}
-		values = append(values, in.Value)
 		outpoint := wire.NewOutPoint(ch, in.OutpointIndex)
 		input := wire.NewTxIn(outpoint, []byte{}, [][]byte{})
 		tx.TxIn = append(tx.TxIn, input)
+		prevOuts[*outpoint] = wire.NewTxOut(in.Value, prevScript)
 	}
 	for _, out := range outs {
 		scriptPubkey, err := zaddr.PayToAddrScript(out.Address)
 		if err != nil {
-			return sigs, err
+			return nil, nil, err
 		}
 		output := wire.NewTxOut(out.Value, scriptPubkey)
 		tx.TxOut = append(tx.TxOut, output)
 	}
 
-	// Subtract fee
-	estimatedSize := EstimateSerializeSize(len(ins), tx.TxOut, false, P2SH_2of3_Multisig)
-	fee := estimatedSize * int(feePerByte)
+	// Subtract fee, rounding up so the outputs together pay at least the fee
+	fee := int(estimateMultisigFee(w.feeModel, len(ins), tx.TxOut, rs, feePerByte))
 	if len(tx.TxOut) > 0 {
-		feePerOutput := fee / len(tx.TxOut)
+		feePerOutput := (fee + len(tx.TxOut) - 1) / len(tx.TxOut)
 		for _, output := range tx.TxOut {
 			output.Value -= int64(feePerOutput)
 		}
@@ -466,6 +575,24 @@ func (w *ZCashWallet) createMultisigSignature(ins []wi.TransactionInput, outs []
 
 	// BIP 69 sorting
 	txsort.InPlaceSort(tx)
+	return tx, prevOuts, nil
+}
+
+func (w *ZCashWallet) createMultisigSignature(ins []wi.TransactionInput, outs []wi.TransactionOutput, key *hd.ExtendedKey, redeemScript []byte, feePerByte uint64) ([]wi.Signature, error) {
+	var sigs []wi.Signature
+	rs, err := multisig.ParseRedeemScript(redeemScript)
+	if err != nil {
+		return sigs, err
+	}
+	tx, additionalPrevOuts, err := w.newMultisigTx(ins, outs, rs, feePerByte)
+	if err != nil {
+		return sigs, err
+	}
+	prevOuts, err := prevOutsFor(tx, additionalPrevOuts)
+	if err != nil {
+		return sigs, err
+	}
+	params := txParams{upgrade: w.networkUpgrade()}
 
 	signingKey, err := key.ECPrivKey()
 	if err != nil {
@@ -473,7 +600,7 @@ func (w *ZCashWallet) createMultisigSignature(ins []wi.TransactionInput, outs []
 	}
 
 	for i := range tx.TxIn {
-		sig, err := rawTxInSignature(tx, i, redeemScript, txscript.SigHashAll, signingKey, values[i])
+		sig, err := rawTxInSignature(tx, i, redeemScript, txscript.SigHashAll, signingKey, prevOuts, params)
 		if err != nil {
 			continue
 		}
@@ -484,37 +611,17 @@ func (w *ZCashWallet) createMultisigSignature(ins []wi.TransactionInput, outs []
 }
 
 func (w *ZCashWallet) multisign(ins []wi.TransactionInput, outs []wi.TransactionOutput, sigs1 []wi.Signature, sigs2 []wi.Signature, redeemScript []byte, feePerByte uint64, broadcast bool) ([]byte, error) {
-	tx := wire.NewMsgTx(1)
-	for _, in := range ins {
-		ch, err := chainhash.NewHashFromStr(hex.EncodeToString(in.OutpointHash))
-		if err != nil {
-			return nil, err
-		}
-		outpoint := wire.NewOutPoint(ch, in.OutpointIndex)
-		input := wire.NewTxIn(outpoint, []byte{}, [][]byte{})
-		tx.TxIn = append(tx.TxIn, input)
-	}
-	for _, out := range outs {
-		scriptPubkey, err := zaddr.PayToAddrScript(out.Address)
-		if err != nil {
-			return nil, err
-		}
-		output := wire.NewTxOut(out.Value, scriptPubkey)
-		tx.TxOut = append(tx.TxOut, output)
+	rs, err := multisig.ParseRedeemScript(redeemScript)
+	if err != nil {
+		return nil, err
 	}
-
-	// Subtract fee
-	estimatedSize := EstimateSerializeSize(len(ins), tx.TxOut, false, P2SH_2of3_Multisig)
-	fee := estimatedSize * int(feePerByte)
-	if len(tx.TxOut) > 0 {
-		feePerOutput := fee / len(tx.TxOut)
-		for _, output := range tx.TxOut {
-			output.Value -= int64(feePerOutput)
-		}
+	tx, _, err := w.newMultisigTx(ins, outs, rs, feePerByte)
+	if err != nil {
+		return nil, err
 	}
 
-	// BIP 69 sorting
-	txsort.InPlaceSort(tx)
+	// The cosigners signed without an expiry height
+	params := txParams{upgrade: w.networkUpgrade()}
 
 	for i, input := range tx.TxIn {
 		var sig1 []byte
@@ -536,6 +643,9 @@ func (w *ZCashWallet) multisign(ins []wi.TransactionInput, outs []wi.Transaction
 		builder.AddOp(txscript.OP_0)
 		builder.AddData(sig1)
 		builder.AddData(sig2)
+		if rs.Timelocked {
+			builder.AddOp(txscript.OP_1)
+		}
 		builder.AddData(redeemScript)
 		scriptSig, err := builder.Script()
 		if err != nil {
@@ -545,11 +655,253 @@ func (w *ZCashWallet) multisign(ins []wi.TransactionInput, outs []wi.Transaction
 	}
 	// broadcast
 	if broadcast {
-		if _, err := w.Broadcast(tx); err != nil {
+		if _, err := w.broadcast(tx, params); err != nil {
+			return nil, err
+		}
+	}
+	return serializeTransaction(tx, params.upgrade, params.expiryHeight)
+}
+
+// multisignWithSignatures signs the transaction with the signatures of any
+// number of cosigners of an m of n multisig. Until the threshold is met the
+// inputs hold partial signature scripts and complete is false.
+func (w *ZCashWallet) multisignWithSignatures(ins []wi.TransactionInput, outs []wi.TransactionOutput, sigs multisig.Signatures, redeemScript []byte, feePerByte uint64, broadcast bool) ([]byte, bool, error) {
+	rs, err := multisig.ParseRedeemScript(redeemScript)
+	if err != nil {
+		return nil, false, err
+	}
+	tx, _, err := w.newMultisigTx(ins, outs, rs, feePerByte)
+	if err != nil {
+		return nil, false, err
+	}
+
+	complete := true
+	for i, input := range tx.TxIn {
+		slots, err := rs.Slots(sigs, i)
+		if err != nil {
+			return nil, false, err
+		}
+		stack, ok := rs.Stack(slots)
+		scriptSig, err := multisig.SignatureScript(stack)
+		if err != nil {
+			return nil, false, err
+		}
+		input.SignatureScript = scriptSig
+		complete = complete && ok
+	}
+	// The cosigners signed without an expiry height
+	return w.finishMultisig(tx, txParams{upgrade: w.networkUpgrade()}, complete, broadcast)
+}
+
+// mergeMultisig combines the signatures of partially signed transactions
+// spending the outputs of the redeem script. The merged transaction keeps the
+// version and consensus branch the cosigners signed.
+func (w *ZCashWallet) mergeMultisig(redeemScript []byte, txs [][]byte, broadcast bool) ([]byte, bool, error) {
+	rs, err := multisig.ParseRedeemScript(redeemScript)
+	if err != nil {
+		return nil, false, err
+	}
+	var merged *wire.MsgTx
+	var params txParams
+	var slots [][][]byte
+	var finals [][]byte
+	for _, raw := range txs {
+		tx, expiry, err := parseTransaction(raw)
+		if err != nil {
+			return nil, false, err
+		}
+		txp := txParams{upgrade: NetworkUpgrade{TxVersion: tx.Version}, expiryHeight: expiry}
+		if tx.Version >= 5 {
+			txp.upgrade.BranchID = binary.LittleEndian.Uint32(raw[8:12])
+		}
+		if merged == nil {
+			merged, params = tx, txp
+			slots = make([][][]byte, len(tx.TxIn))
+			finals = make([][]byte, len(tx.TxIn))
+		} else if multisig.UnsignedTxHash(tx) != multisig.UnsignedTxHash(merged) || txp != params {
+			return nil, false, errors.New("partially signed transactions differ")
+		}
+		for i, in := range tx.TxIn {
+			stack, err := multisig.ScriptStack(in.SignatureScript)
+			if err != nil {
+				return nil, false, fmt.Errorf("input %d: %s", i, err)
+			}
+			s, complete, err := rs.ParseStack(stack)
+			if err != nil {
+				return nil, false, fmt.Errorf("input %d: %s", i, err)
+			}
+			if complete {
+				finals[i] = in.SignatureScript
+			} else if slots[i] == nil {
+				slots[i] = s
+			} else if slots[i], err = multisig.MergeSlots(slots[i], s); err != nil {
+				return nil, false, err
+			}
+		}
+	}
+	if merged == nil {
+		return nil, false, errors.New("no transactions to merge")
+	}
+
+	complete := true
+	for i, input := range merged.TxIn {
+		if finals[i] != nil {
+			input.SignatureScript = finals[i]
+			continue
+		}
+		stack, ok := rs.Stack(slots[i])
+		scriptSig, err := multisig.SignatureScript(stack)
+		if err != nil {
+			return nil, false, err
+		}
+		input.SignatureScript = scriptSig
+		complete = complete && ok
+	}
+	return w.finishMultisig(merged, params, complete, broadcast)
+}
+
+// finishMultisig serializes a multisig transaction, broadcasting it if
+// requested once every input is fully signed.
+func (w *ZCashWallet) finishMultisig(tx *wire.MsgTx, params txParams, complete, broadcast bool) ([]byte, bool, error) {
+	if broadcast && complete {
+		if _, err := w.broadcast(tx, params); err != nil {
+			return nil, false, err
+		}
+	}
+	raw, err := serializeTransaction(tx, params.upgrade, params.expiryHeight)
+	if err != nil {
+		return nil, false, err
+	}
+	return raw, complete, nil
+}
+
+// releaseAfterTimeout always fails. Zcash has no relative lock times, so
+// generateMultisigScript never adds a timeout branch to an escrow and the
+// multisig is the only way to spend it.
+func (w *ZCashWallet) releaseAfterTimeout(ins []wi.TransactionInput, address btc.Address, timeoutKey *hd.ExtendedKey, redeemScript []byte, feeLevel wi.FeeLevel) (*chainhash.Hash, error) {
+	return nil, errors.New("zcash escrows have no timeout branch")
+}
+
+// generateHTLCScript returns the P2SH address and script of a hash time locked
+// contract. The lock time is absolute so, unlike an escrow timeout, it is
+// enforced by CHECKLOCKTIMEVERIFY on Zcash.
+func (w *ZCashWallet) generateHTLCScript(secretHash, recipientKey, refundKey []byte, lockTime uint32) (btc.Address, []byte, error) {
+	contract, err := htlc.NewContract(secretHash, recipientKey, refundKey, lockTime)
+	if err != nil {
+		return nil, nil, err
+	}
+	addr, err := zaddr.NewAddressScriptHash(contract.Script, w.params)
+	if err != nil {
+		return nil, nil, err
+	}
+	return addr, contract.Script, nil
+}
+
+// spendHTLC spends the outputs of a hash time locked contract to address. The
+// contract is redeemed by the recipient key if a secret is given and refunded
+// by the refund key otherwise.
+func (w *ZCashWallet) spendHTLC(ins []wi.TransactionInput, address btc.Address, key *hd.ExtendedKey, script []byte, secret []byte, feeLevel wi.FeeLevel) (*chainhash.Hash, error) {
+	contract, err := htlc.ParseContract(script)
+	if err != nil {
+		return nil, err
+	}
+	privKey, err := key.ECPrivKey()
+	if err != nil {
+		return nil, fmt.Errorf("retrieving private key: %s", err.Error())
+	}
+	pubKey := privKey.PubKey().SerializeCompressed()
+	redeem := secret != nil
+	if redeem {
+		if !contract.Matches(secret) {
+			return nil, htlc.ErrSecretMismatch
+		}
+		if !bytes.Equal(pubKey, contract.RecipientKey) {
+			return nil, errors.New("key is not the recipient key of the contract")
+		}
+	} else {
+		if !bytes.Equal(pubKey, contract.RefundKey) {
+			return nil, errors.New("key is not the refund key of the contract")
+		}
+		if tip, _ := w.ChainTip(); !contract.Refundable(tip) {
+			return nil, htlc.ErrLockTimeNotReached
+		}
+	}
+	if address == nil {
+		address = w.CurrentAddress(wi.INTERNAL)
+	}
+	outScript, err := zaddr.PayToAddrScript(address)
+	if err != nil {
+		return nil, err
+	}
+	scriptAddr, err := zaddr.NewAddressScriptHash(contract.Script, w.params)
+	if err != nil {
+		return nil, err
+	}
+	prevScript, err := zaddr.PayToAddrScript(scriptAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := wire.NewMsgTx(wire.TxVersion)
+	var val int64
+	additionalPrevOuts := make(map[wire.OutPoint]*wire.TxOut)
+	for _, in := range ins {
+		ch, err := chainhash.NewHashFromStr(hex.EncodeToString(in.OutpointHash))
+		if err != nil {
+			return nil, err
+		}
+		outpoint := wire.NewOutPoint(ch, in.OutpointIndex)
+		input := wire.NewTxIn(outpoint, []byte{}, [][]byte{})
+		if !redeem {
+			// The lock time is only enforced on inputs which are not final
+			input.Sequence = wire.MaxTxInSequenceNum - 1
+		}
+		tx.TxIn = append(tx.TxIn, input)
+		additionalPrevOuts[*outpoint] = wire.NewTxOut(in.Value, prevScript)
+		val += in.Value
+	}
+	if !redeem {
+		tx.LockTime = contract.LockTime
+	}
+
+	out := wire.NewTxOut(val, outScript)
+	tx.TxOut = append(tx.TxOut, out)
+	fee := estimateHTLCFee(w.feeModel, len(ins), tx.TxOut, redeem, w.GetFeePerByte(feeLevel))
+	out.Value = val - int64(fee)
+	if w.IsDust(out.Value) {
+		return nil, wi.ErrorDustAmount
+	}
+
+	// BIP 69 sorting
+	txsort.InPlaceSort(tx)
+	prevOuts, err := prevOutsFor(tx, additionalPrevOuts)
+	if err != nil {
+		return nil, err
+	}
+	params := w.newTxParams()
+
+	for i, txIn := range tx.TxIn {
+		sig, err := rawTxInSignature(tx, i, script, txscript.SigHashAll, privKey, prevOuts, params)
+		if err != nil {
+			return nil, errors.New("failed to sign transaction")
+		}
+		stack := contract.RefundStack(sig)
+		if redeem {
+			stack = contract.RedeemStack(sig, secret)
+		}
+		scriptSig, err := multisig.SignatureScript(stack)
+		if err != nil {
 			return nil, err
 		}
+		txIn.SignatureScript = scriptSig
+	}
+
+	// broadcast
+	txid, err := w.broadcast(tx, params)
+	if err != nil {
+		return nil, err
 	}
-	return serializeVersion4Transaction(tx, 0)
+	return chainhash.NewHashFromStr(txid)
 }
 
 func (w *ZCashWallet) generateMultisigScript(keys []hd.ExtendedKey, threshold int, timeout time.Duration, timeoutKey *hd.ExtendedKey) (addr btc.Address, redeemScript []byte, err error) {
@@ -598,7 +950,7 @@ func (w *ZCashWallet) estimateSpendFee(amount int64, feeLevel wi.FeeLevel) (uint
 	if err != nil {
 		return 0, err
 	}
-	tx, err := w.buildTx(amount, addr, feeLevel, nil)
+	tx, _, err := w.buildTx(amount, addr, feeLevel, nil)
 	if err != nil {
 		return 0, err
 	}
@@ -626,11 +978,25 @@ func (w *ZCashWallet) estimateSpendFee(amount int64, feeLevel wi.FeeLevel) (uint
 }
 
 // rawTxInSignature returns the serialized ECDSA signature for the input idx of
-// the given transaction, with hashType appended to it.
+// the given transaction, with hashType appended to it. The signature hash
+// commits to the consensus branch, the expiry height and, from NU5 on, to all
+// of the previous outputs. prevScriptBytes is the script code signed over by
+// version 4 transactions.
 func rawTxInSignature(tx *wire.MsgTx, idx int, prevScriptBytes []byte,
-	hashType txscript.SigHashType, key *btcec.PrivateKey, amt int64) ([]byte, error) {
+	hashType txscript.SigHashType, key *btcec.PrivateKey, prevOuts []*wire.TxOut, params txParams) ([]byte, error) {
 
-	hash, err := calcSignatureHash(prevScriptBytes, hashType, tx, idx, amt, 0)
+	if idx > len(prevOuts)-1 {
+		return nil, fmt.Errorf("idx %d but %d previous outputs", idx, len(prevOuts))
+	}
+	var (
+		hash []byte
+		err  error
+	)
+	if params.upgrade.TxVersion >= 5 {
+		hash, err = calcSignatureHashV5(hashType, tx, idx, prevOuts, params.expiryHeight, params.upgrade.BranchID)
+	} else {
+		hash, err = calcSignatureHash(prevScriptBytes, hashType, tx, idx, prevOuts[idx].Value, params.expiryHeight, params.upgrade.BranchID)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -642,7 +1008,9 @@ func rawTxInSignature(tx *wire.MsgTx, idx int, prevScriptBytes []byte,
 	return append(signature.Serialize(), byte(hashType)), nil
 }
 
-func calcSignatureHash(prevScriptBytes []byte, hashType txscript.SigHashType, tx *wire.MsgTx, idx int, amt int64, expiry uint32) ([]byte, error) {
+// calcSignatureHash computes the ZIP-243 signature hash of a version 4
+// transaction.
+func calcSignatureHash(prevScriptBytes []byte, hashType txscript.SigHashType, tx *wire.MsgTx, idx int, amt int64, expiry uint32, branchID uint32) ([]byte, error) {
 
 	// As a sanity check, ensure the passed input index for the transaction
 	// is valid.
@@ -752,7 +1120,7 @@ func calcSignatureHash(prevScriptBytes []byte, hashType txscript.SigHashType, tx
 	sigHash.Write(bSequence[:])
 
 	leBranchID := make([]byte, 4)
-	binary.LittleEndian.PutUint32(leBranchID, saplingBranchID)
+	binary.LittleEndian.PutUint32(leBranchID, branchID)
 	bl, _ := blake2b.New(&blake2b.Config{
 		Size:   32,
 		Person: append(sigHashPersonalization, leBranchID...),
@@ -779,11 +1147,62 @@ func serializeVersion4Transaction(tx *wire.MsgTx, expiryHeight uint32) ([]byte,
 		return nil, err
 	}
 
+	// Write inputs and outputs
+	if err := writeTransparentBundle(&buf, tx); err != nil {
+		return nil, err
+	}
+
+	// Write nLocktime
+	nLockTime := make([]byte, 4)
+	binary.LittleEndian.PutUint32(nLockTime, tx.LockTime)
+	_, err = buf.Write(nLockTime)
+	if err != nil {
+		return nil, err
+	}
+
+	// Write nExpiryHeight
+	expiry := make([]byte, 4)
+	binary.LittleEndian.PutUint32(expiry, expiryHeight)
+	_, err = buf.Write(expiry)
+	if err != nil {
+		return nil, err
+	}
+
+	// Write nil value balance
+	_, err = buf.Write(make([]byte, 8))
+	if err != nil {
+		return nil, err
+	}
+
+	// Write nil value vShieldedSpend
+	_, err = buf.Write(make([]byte, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	// Write nil value vShieldedOutput
+	_, err = buf.Write(make([]byte, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	// Write nil value vJoinSplit
+	_, err = buf.Write(make([]byte, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// writeTransparentBundle writes the inputs and outputs of tx, which are
+// encoded the same way in version 4 and version 5 transactions.
+func writeTransparentBundle(buf *bytes.Buffer, tx *wire.MsgTx) error {
 	// Write varint input count
 	count := uint64(len(tx.TxIn))
-	err = wire.WriteVarInt(&buf, wire.ProtocolVersion, count)
+	err := wire.WriteVarInt(buf, wire.ProtocolVersion, count)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	// Write inputs
@@ -791,33 +1210,33 @@ func serializeVersion4Transaction(tx *wire.MsgTx, expiryHeight uint32) ([]byte,
 		// Write outpoint hash
 		_, err := buf.Write(ti.PreviousOutPoint.Hash[:])
 		if err != nil {
-			return nil, err
+			return err
 		}
 		// Write outpoint index
 		index := make([]byte, 4)
 		binary.LittleEndian.PutUint32(index, ti.PreviousOutPoint.Index)
 		_, err = buf.Write(index)
 		if err != nil {
-			return nil, err
+			return err
 		}
 		// Write sigscript
-		err = wire.WriteVarBytes(&buf, wire.ProtocolVersion, ti.SignatureScript)
+		err = wire.WriteVarBytes(buf, wire.ProtocolVersion, ti.SignatureScript)
 		if err != nil {
-			return nil, err
+			return err
 		}
 		// Write sequence
 		sequence := make([]byte, 4)
 		binary.LittleEndian.PutUint32(sequence, ti.Sequence)
 		_, err = buf.Write(sequence)
 		if err != nil {
-			return nil, err
+			return err
 		}
 	}
 	// Write varint output count
 	count = uint64(len(tx.TxOut))
-	err = wire.WriteVarInt(&buf, wire.ProtocolVersion, count)
+	err = wire.WriteVarInt(buf, wire.ProtocolVersion, count)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	// Write outputs
 	for _, to := range tx.TxOut {
@@ -826,57 +1245,342 @@ func serializeVersion4Transaction(tx *wire.MsgTx, expiryHeight uint32) ([]byte,
 		binary.LittleEndian.PutUint64(val, uint64(to.Value))
 		_, err = buf.Write(val)
 		if err != nil {
-			return nil, err
+			return err
 		}
 		// Write pkScript
-		err = wire.WriteVarBytes(&buf, wire.ProtocolVersion, to.PkScript)
+		err = wire.WriteVarBytes(buf, wire.ProtocolVersion, to.PkScript)
 		if err != nil {
-			return nil, err
+			return err
 		}
 	}
-	// Write nLocktime
-	nLockTime := make([]byte, 4)
-	binary.LittleEndian.PutUint32(nLockTime, tx.LockTime)
-	_, err = buf.Write(nLockTime)
-	if err != nil {
-		return nil, err
+	return nil
+}
+
+// serializeTransaction serializes tx in the transaction version used under the
+// network upgrade.
+func serializeTransaction(tx *wire.MsgTx, upgrade NetworkUpgrade, expiryHeight uint32) ([]byte, error) {
+	if upgrade.TxVersion >= 5 {
+		return serializeVersion5Transaction(tx, upgrade.BranchID, expiryHeight)
 	}
+	return serializeVersion4Transaction(tx, expiryHeight)
+}
 
-	// Write nExpiryHeight
-	expiry := make([]byte, 4)
-	binary.LittleEndian.PutUint32(expiry, expiryHeight)
-	_, err = buf.Write(expiry)
-	if err != nil {
-		return nil, err
+// parseTransaction reads the transparent inputs and outputs and the expiry
+// height of a version four or five transaction serialized by the wallet. The
+// shielded parts of the transaction are not read.
+func parseTransaction(raw []byte) (*wire.MsgTx, uint32, error) {
+	r := bytes.NewReader(raw)
+	var header [8]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, 0, err
+	}
+	tx := wire.NewMsgTx(1)
+	var fields [12]byte
+	switch {
+	case bytes.Equal(header[:4], txHeaderBytes) && bytes.Equal(header[4:], txNVersionGroupIDBytes):
+		tx.Version = 4
+		if err := readTransparentBundle(r, tx); err != nil {
+			return nil, 0, err
+		}
+		if _, err := io.ReadFull(r, fields[4:12]); err != nil {
+			return nil, 0, err
+		}
+	case bytes.Equal(header[:4], txV5HeaderBytes) && bytes.Equal(header[4:], txV5NVersionGroupIDBytes):
+		tx.Version = 5
+		if _, err := io.ReadFull(r, fields[:]); err != nil {
+			return nil, 0, err
+		}
+		if err := readTransparentBundle(r, tx); err != nil {
+			return nil, 0, err
+		}
+	default:
+		return nil, 0, fmt.Errorf("unsupported transaction header %x", header)
 	}
+	tx.LockTime = binary.LittleEndian.Uint32(fields[4:8])
+	return tx, binary.LittleEndian.Uint32(fields[8:12]), nil
+}
 
-	// Write nil value balance
-	_, err = buf.Write(make([]byte, 8))
+// readTransparentBundle is the inverse of writeTransparentBundle.
+func readTransparentBundle(r io.Reader, tx *wire.MsgTx) error {
+	count, err := wire.ReadVarInt(r, wire.ProtocolVersion)
 	if err != nil {
-		return nil, err
+		return err
+	}
+	for i := uint64(0); i < count; i++ {
+		var op wire.OutPoint
+		if _, err := io.ReadFull(r, op.Hash[:]); err != nil {
+			return err
+		}
+		var b [8]byte
+		if _, err := io.ReadFull(r, b[:4]); err != nil {
+			return err
+		}
+		op.Index = binary.LittleEndian.Uint32(b[:4])
+		script, err := wire.ReadVarBytes(r, wire.ProtocolVersion, wire.MaxMessagePayload, "sigScript")
+		if err != nil {
+			return err
+		}
+		if _, err := io.ReadFull(r, b[:4]); err != nil {
+			return err
+		}
+		in := wire.NewTxIn(&op, script, nil)
+		in.Sequence = binary.LittleEndian.Uint32(b[:4])
+		tx.TxIn = append(tx.TxIn, in)
 	}
 
-	// Write nil value vShieldedSpend
-	_, err = buf.Write(make([]byte, 1))
+	count, err = wire.ReadVarInt(r, wire.ProtocolVersion)
 	if err != nil {
-		return nil, err
+		return err
 	}
+	for i := uint64(0); i < count; i++ {
+		var b [8]byte
+		if _, err := io.ReadFull(r, b[:]); err != nil {
+			return err
+		}
+		script, err := wire.ReadVarBytes(r, wire.ProtocolVersion, wire.MaxMessagePayload, "pkScript")
+		if err != nil {
+			return err
+		}
+		tx.TxOut = append(tx.TxOut, wire.NewTxOut(int64(binary.LittleEndian.Uint64(b[:])), script))
+	}
+	return nil
+}
 
-	// Write nil value vShieldedOutput
-	_, err = buf.Write(make([]byte, 1))
+// txExpiry implements service.ExpiryFunc for transactions broadcast by the
+// wallet.
+func txExpiry(raw []byte) (uint32, []wire.OutPoint, error) {
+	tx, expiry, err := parseTransaction(raw)
 	if err != nil {
+		return 0, nil, err
+	}
+	spent := make([]wire.OutPoint, 0, len(tx.TxIn))
+	for _, in := range tx.TxIn {
+		spent = append(spent, in.PreviousOutPoint)
+	}
+	return expiry, spent, nil
+}
+
+// decodeTx implements service.DecodeFunc. Transactions saved without their raw
+// bytes from the API are stored in Bitcoin's serialization instead.
+func decodeTx(raw []byte) (*wire.MsgTx, error) {
+	if tx, _, err := parseTransaction(raw); err == nil {
+		return tx, nil
+	}
+	tx := wire.NewMsgTx(wire.TxVersion)
+	if err := tx.Deserialize(bytes.NewReader(raw)); err != nil {
 		return nil, err
 	}
+	return tx, nil
+}
 
-	// Write nil value vJoinSplit
-	_, err = buf.Write(make([]byte, 1))
-	if err != nil {
+// transparentOnly returns whether raw holds nothing beyond the transparent
+// parts parseTransaction read into tx.
+func transparentOnly(raw []byte, tx *wire.MsgTx, expiry uint32) bool {
+	var (
+		reserialized []byte
+		err          error
+	)
+	if tx.Version >= 5 {
+		reserialized, err = serializeVersion5Transaction(tx, binary.LittleEndian.Uint32(raw[8:12]), expiry)
+	} else {
+		reserialized, err = serializeVersion4Transaction(tx, expiry)
+	}
+	return err == nil && bytes.Equal(reserialized, raw)
+}
+
+// transactionID returns the ID of the transaction parsed from raw. Version five
+// IDs are ZIP-244 digests that commit to the shielded bundles, so they can only
+// be computed for transparent transactions.
+func transactionID(raw []byte, tx *wire.MsgTx, expiry uint32) (chainhash.Hash, error) {
+	if tx.Version < 5 {
+		return chainhash.DoubleHashH(raw), nil
+	}
+	if !transparentOnly(raw, tx, expiry) {
+		return chainhash.Hash{}, errors.New("version 5 transactions with shielded parts are not supported")
+	}
+	branchID := binary.LittleEndian.Uint32(raw[8:12])
+	transparentDigest := zip244Hash(transparentHashPersonalization)
+	if len(tx.TxIn) > 0 || len(tx.TxOut) > 0 {
+		transparentDigest = zip244Hash(transparentHashPersonalization,
+			calcPrevOutsDigestV5(tx),
+			calcSequenceDigestV5(tx),
+			calcOutputsDigestV5(tx.TxOut),
+		)
+	}
+	leBranchID := make([]byte, 4)
+	binary.LittleEndian.PutUint32(leBranchID, branchID)
+	personalization := append(append([]byte{}, txHashPersonalization...), leBranchID...)
+	var txid chainhash.Hash
+	copy(txid[:], zip244Hash(personalization,
+		calcHeaderDigestV5(tx, expiry, branchID),
+		transparentDigest,
+		zip244Hash(saplingHashPersonalization),
+		zip244Hash(orchardHashPersonalization),
+	))
+	return txid, nil
+}
+
+// serializeVersion5Transaction serializes a wire.MsgTx into the ZIP-225 version
+// five wire transaction format with empty Sapling and Orchard bundles.
+func serializeVersion5Transaction(tx *wire.MsgTx, branchID uint32, expiryHeight uint32) ([]byte, error) {
+	var buf bytes.Buffer
+
+	// Write header, group ID, consensus branch ID, nLockTime and nExpiryHeight
+	buf.Write(txV5HeaderBytes)
+	buf.Write(txV5NVersionGroupIDBytes)
+	var fields [12]byte
+	binary.LittleEndian.PutUint32(fields[0:4], branchID)
+	binary.LittleEndian.PutUint32(fields[4:8], tx.LockTime)
+	binary.LittleEndian.PutUint32(fields[8:12], expiryHeight)
+	buf.Write(fields[:])
+
+	// Write inputs and outputs
+	if err := writeTransparentBundle(&buf, tx); err != nil {
 		return nil, err
 	}
 
+	// Write nil nSpendsSapling, nOutputsSapling and nActionsOrchard
+	buf.Write(make([]byte, 3))
+
 	return buf.Bytes(), nil
 }
 
+// zip244Hash returns the 32 byte BLAKE2b hash of data with the given
+// personalization.
+func zip244Hash(personalization []byte, data ...[]byte) []byte {
+	bl, _ := blake2b.New(&blake2b.Config{
+		Size:   32,
+		Person: personalization,
+	})
+	for _, d := range data {
+		bl.Write(d)
+	}
+	return bl.Sum(nil)
+}
+
+func calcHeaderDigestV5(tx *wire.MsgTx, expiry uint32, branchID uint32) []byte {
+	var fields [12]byte
+	binary.LittleEndian.PutUint32(fields[0:4], branchID)
+	binary.LittleEndian.PutUint32(fields[4:8], tx.LockTime)
+	binary.LittleEndian.PutUint32(fields[8:12], expiry)
+	return zip244Hash(headersHashPersonalization, txV5HeaderBytes, txV5NVersionGroupIDBytes, fields[:])
+}
+
+func calcPrevOutsDigestV5(tx *wire.MsgTx) []byte {
+	var b bytes.Buffer
+	for _, in := range tx.TxIn {
+		b.Write(in.PreviousOutPoint.Hash[:])
+		var buf [4]byte
+		binary.LittleEndian.PutUint32(buf[:], in.PreviousOutPoint.Index)
+		b.Write(buf[:])
+	}
+	return zip244Hash(prevOutHashV5Personalization, b.Bytes())
+}
+
+func calcSequenceDigestV5(tx *wire.MsgTx) []byte {
+	var b bytes.Buffer
+	for _, in := range tx.TxIn {
+		var buf [4]byte
+		binary.LittleEndian.PutUint32(buf[:], in.Sequence)
+		b.Write(buf[:])
+	}
+	return zip244Hash(sequenceHashV5Personalization, b.Bytes())
+}
+
+func calcOutputsDigestV5(outs []*wire.TxOut) []byte {
+	var b bytes.Buffer
+	for _, out := range outs {
+		wire.WriteTxOut(&b, 0, 0, out)
+	}
+	return zip244Hash(outputsHashV5Personalization, b.Bytes())
+}
+
+// calcSignatureHashV5 computes the ZIP-244 signature hash of the transparent
+// input idx of a version 5 transaction. Unlike earlier versions it commits to
+// the values and scripts of every output spent by the transaction, which are
+// passed in prevOuts in input order.
+func calcSignatureHashV5(hashType txscript.SigHashType, tx *wire.MsgTx, idx int, prevOuts []*wire.TxOut, expiry uint32, branchID uint32) ([]byte, error) {
+	if idx > len(tx.TxIn)-1 {
+		return nil, fmt.Errorf("idx %d but %d txins", idx, len(tx.TxIn))
+	}
+	if len(prevOuts) != len(tx.TxIn) {
+		return nil, fmt.Errorf("%d previous outputs but %d txins", len(prevOuts), len(tx.TxIn))
+	}
+	switch hashType &^ txscript.SigHashAnyOneCanPay {
+	case txscript.SigHashAll, txscript.SigHashNone, txscript.SigHashSingle:
+	default:
+		return nil, fmt.Errorf("invalid sighash type %#x", uint32(hashType))
+	}
+
+	var (
+		anyoneCanPay = hashType&txscript.SigHashAnyOneCanPay != 0
+		baseType     = hashType & sigHashMask
+
+		prevOutsDigest, amountsDigest, scriptsDigest, sequenceDigest, outputsDigest []byte
+	)
+	if anyoneCanPay {
+		prevOutsDigest = zip244Hash(prevOutHashV5Personalization)
+		amountsDigest = zip244Hash(amountsHashPersonalization)
+		scriptsDigest = zip244Hash(scriptsHashPersonalization)
+		sequenceDigest = zip244Hash(sequenceHashV5Personalization)
+	} else {
+		var amounts, scripts bytes.Buffer
+		for _, out := range prevOuts {
+			var bAmount [8]byte
+			binary.LittleEndian.PutUint64(bAmount[:], uint64(out.Value))
+			amounts.Write(bAmount[:])
+			wire.WriteVarBytes(&scripts, 0, out.PkScript)
+		}
+		prevOutsDigest = calcPrevOutsDigestV5(tx)
+		amountsDigest = zip244Hash(amountsHashPersonalization, amounts.Bytes())
+		scriptsDigest = zip244Hash(scriptsHashPersonalization, scripts.Bytes())
+		sequenceDigest = calcSequenceDigestV5(tx)
+	}
+
+	switch {
+	case baseType != txscript.SigHashSingle && baseType != txscript.SigHashNone:
+		outputsDigest = calcOutputsDigestV5(tx.TxOut)
+	case baseType == txscript.SigHashSingle && idx < len(tx.TxOut):
+		outputsDigest = calcOutputsDigestV5(tx.TxOut[idx : idx+1])
+	default:
+		outputsDigest = calcOutputsDigestV5(nil)
+	}
+
+	// Commit to the input being signed and the output it spends
+	var txIn bytes.Buffer
+	txIn.Write(tx.TxIn[idx].PreviousOutPoint.Hash[:])
+	var bIndex [4]byte
+	binary.LittleEndian.PutUint32(bIndex[:], tx.TxIn[idx].PreviousOutPoint.Index)
+	txIn.Write(bIndex[:])
+	var bAmount [8]byte
+	binary.LittleEndian.PutUint64(bAmount[:], uint64(prevOuts[idx].Value))
+	txIn.Write(bAmount[:])
+	wire.WriteVarBytes(&txIn, 0, prevOuts[idx].PkScript)
+	var bSequence [4]byte
+	binary.LittleEndian.PutUint32(bSequence[:], tx.TxIn[idx].Sequence)
+	txIn.Write(bSequence[:])
+
+	transparentDigest := zip244Hash(transparentHashPersonalization,
+		[]byte{byte(hashType)},
+		prevOutsDigest,
+		amountsDigest,
+		scriptsDigest,
+		sequenceDigest,
+		outputsDigest,
+		zip244Hash(txInHashPersonalization, txIn.Bytes()),
+	)
+
+	leBranchID := make([]byte, 4)
+	binary.LittleEndian.PutUint32(leBranchID, branchID)
+	personalization := append(append([]byte{}, txHashPersonalization...), leBranchID...)
+	return zip244Hash(personalization,
+		calcHeaderDigestV5(tx, expiry, branchID),
+		transparentDigest,
+		zip244Hash(saplingHashPersonalization),
+		zip244Hash(orchardHashPersonalization),
+	), nil
+}
+
 func calcHashPrevOuts(tx *wire.MsgTx) []byte {
 	var b bytes.Buffer
 	for _, in := range tx.TxIn {
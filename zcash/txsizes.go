@@ -8,6 +8,9 @@ package zcash
 
 import (
 	"github.com/btcsuite/btcd/wire"
+
+	"github.com/muecoin/multiwallet/htlc"
+	"github.com/muecoin/multiwallet/multisig"
 )
 
 // Worst case script and input/output size estimates.
@@ -149,10 +152,10 @@ const (
 	//
 	//   - 32 bytes previous tx
 	//   - 4 bytes output index
-	//   - 1 byte script len
+	//   - 3 bytes script len
 	//   - 4 bytes sequence
-	///  - witness discounted signature script
-	RedeemP2SH2of3MultisigInputSize = 32 + 4 + 1 + 4 + (RedeemP2SH2of3MultisigSigScriptSize / 4)
+	//   - signature script
+	RedeemP2SH2of3MultisigInputSize = 32 + 4 + 3 + 4 + RedeemP2SH2of3MultisigSigScriptSize
 
 	// RedeemP2SH1of2MultisigInputSize is the worst case (largest) serialize size of a
 	// transaction input redeeming a compressed P2SH 2 of 3 multisig output.  It is
@@ -162,8 +165,8 @@ const (
 	//   - 4 bytes output index
 	//   - 1 byte script len
 	//   - 4 bytes sequence
-	///  - witness discounted signature script
-	RedeemP2SH1of2MultisigInputSize = 32 + 4 + 1 + 4 + (RedeemP2SH1of2MultisigSigScriptSize / 4)
+	//   - signature script
+	RedeemP2SH1of2MultisigInputSize = 32 + 4 + 1 + 4 + RedeemP2SH1of2MultisigSigScriptSize
 
 	// RedeemP2SHMultisigTimelock1InputSize is the worst case (largest) serialize size of a
 	// transaction input redeeming a compressed p2sh timelocked multig output with using the timeout.  It is
@@ -173,8 +176,8 @@ const (
 	//   - 4 bytes output index
 	//   - 1 byte script len
 	//   - 4 bytes sequence
-	///  - witness discounted signature script
-	RedeemP2SHMultisigTimelock1InputSize = 32 + 4 + 1 + 4 + (RedeemP2SHMultisigTimelock1SigScriptSize / 4)
+	//   - signature script
+	RedeemP2SHMultisigTimelock1InputSize = 32 + 4 + 1 + 4 + RedeemP2SHMultisigTimelock1SigScriptSize
 
 	// RedeemP2SHMultisigTimelock2InputSize is the worst case (largest) serialize size of a
 	// transaction input redeeming a compressed P2SH timelocked multisig output without using the timeout.  It is
@@ -182,10 +185,10 @@ const (
 	//
 	//   - 32 bytes previous tx
 	//   - 4 bytes output index
-	//   - 1 byte script len
+	//   - 3 bytes script len
 	//   - 4 bytes sequence
-	///  - witness discounted signature script
-	RedeemP2SHMultisigTimelock2InputSize = 32 + 4 + 1 + 4 + (RedeemP2SHMultisigTimelock2SigScriptSize / 4)
+	//   - signature script
+	RedeemP2SHMultisigTimelock2InputSize = 32 + 4 + 3 + 4 + RedeemP2SHMultisigTimelock2SigScriptSize
 
 	// P2PKHOutputSize is the serialize size of a transaction output with a
 	// P2PKH output script.  It is calculated as:
@@ -218,26 +221,80 @@ func EstimateSerializeSize(inputCount int, txOuts []*wire.TxOut, addChangeOutput
 		outputCount++
 	}
 
-	var redeemScriptSize int
+	redeemScriptSize := RedeemInputSize(inputType)
+
+	// 10 additional bytes are for version, locktime, and segwit flags
+	return 10 + wire.VarIntSerializeSize(uint64(inputCount)) +
+		wire.VarIntSerializeSize(uint64(outputCount)) +
+		inputCount*redeemScriptSize +
+		SumOutputSerializeSizes(txOuts) +
+		changeSize
+}
+
+// RedeemInputSize returns the worst case serialize size of an input of the
+// given type.
+func RedeemInputSize(inputType InputType) int {
 	switch inputType {
-	case P2PKH:
-		redeemScriptSize = RedeemP2PKHInputSize
 	case P2SH_1of2_Multisig:
-		redeemScriptSize = RedeemP2SH1of2MultisigInputSize
+		return RedeemP2SH1of2MultisigInputSize
 	case P2SH_2of3_Multisig:
-		redeemScriptSize = RedeemP2SH2of3MultisigInputSize
+		return RedeemP2SH2of3MultisigInputSize
 	case P2SH_Multisig_Timelock_1Sig:
-		redeemScriptSize = RedeemP2SHMultisigTimelock1InputSize
+		return RedeemP2SHMultisigTimelock1InputSize
 	case P2SH_Multisig_Timelock_2Sigs:
-		redeemScriptSize = RedeemP2SHMultisigTimelock2InputSize
+		return RedeemP2SHMultisigTimelock2InputSize
 	}
+	return RedeemP2PKHInputSize
+}
 
-	// 10 additional bytes are for version, locktime, and segwit flags
+// RedeemMultisigInputSize returns the worst case serialize size of a
+// transaction input redeeming an m of n P2SH multisig output through the
+// multisig branch.
+func RedeemMultisigInputSize(m, n int, timelocked bool) int {
+	sigScriptSize := multisig.SigScriptSize(m, n, timelocked, multisig.ECDSASignatureSize)
+	return 32 + 4 + wire.VarIntSerializeSize(uint64(sigScriptSize)) + sigScriptSize + 4
+}
+
+// multisigInputSize returns the size of an input of the redeem script used to
+// estimate its fee. Multisigs of up to 2 of 3 keys use the 2 of 3 estimate
+// they have always been signed with, so cosigners running older versions
+// subtract the same fee.
+func multisigInputSize(rs *multisig.RedeemScript) int {
+	if rs.Threshold <= 2 && len(rs.PubKeys) <= 3 {
+		return RedeemInputSize(P2SH_2of3_Multisig)
+	}
+	return RedeemMultisigInputSize(rs.Threshold, len(rs.PubKeys), rs.Timelocked)
+}
+
+// EstimateMultisigSerializeSize returns a worst case serialize size estimate
+// for a transaction spending inputCount outputs of the multisig redeem script
+// to txOuts.
+func EstimateMultisigSerializeSize(inputCount int, txOuts []*wire.TxOut, rs *multisig.RedeemScript) int {
 	return 10 + wire.VarIntSerializeSize(uint64(inputCount)) +
-		wire.VarIntSerializeSize(uint64(outputCount)) +
-		inputCount*redeemScriptSize +
-		SumOutputSerializeSizes(txOuts) +
-		changeSize
+		wire.VarIntSerializeSize(uint64(len(txOuts))) +
+		inputCount*multisigInputSize(rs) +
+		SumOutputSerializeSizes(txOuts)
+}
+
+// RedeemHTLCInputSize returns the worst case serialize size of a transaction
+// input spending a P2SH hash time locked contract, either redeeming it with
+// the secret or refunding it.
+func RedeemHTLCInputSize(redeem bool) int {
+	sigScriptSize := htlc.RefundSigScriptSize(htlc.ECDSASignatureSize)
+	if redeem {
+		sigScriptSize = htlc.RedeemSigScriptSize(htlc.ECDSASignatureSize)
+	}
+	return 32 + 4 + wire.VarIntSerializeSize(uint64(sigScriptSize)) + sigScriptSize + 4
+}
+
+// EstimateHTLCSerializeSize returns a worst case serialize size estimate for
+// a transaction spending inputCount outputs of a hash time locked contract to
+// txOuts.
+func EstimateHTLCSerializeSize(inputCount int, txOuts []*wire.TxOut, redeem bool) int {
+	return 10 + wire.VarIntSerializeSize(uint64(inputCount)) +
+		wire.VarIntSerializeSize(uint64(len(txOuts))) +
+		inputCount*RedeemHTLCInputSize(redeem) +
+		SumOutputSerializeSizes(txOuts)
 }
 
 // SumOutputSerializeSizes sums up the serialized size of the supplied outputs.
@@ -4,13 +4,18 @@ import (
 	"encoding/hex"
 	"fmt"
 	"io"
+	"math"
 	"time"
 
 	"github.com/muecoin/multiwallet/cache"
 	"github.com/muecoin/multiwallet/client"
 	"github.com/muecoin/multiwallet/config"
+	"github.com/muecoin/multiwallet/htlc"
 	"github.com/muecoin/multiwallet/keys"
 	"github.com/muecoin/multiwallet/model"
+	"github.com/muecoin/multiwallet/multisig"
+	"github.com/muecoin/multiwallet/rates"
+	"github.com/muecoin/multiwallet/registry"
 	"github.com/muecoin/multiwallet/service"
 	"github.com/muecoin/multiwallet/util"
 	zaddr "github.com/muecoin/multiwallet/zcash/address"
@@ -33,12 +38,46 @@ type ZCashWallet struct {
 	ws     *service.WalletService
 	fp     *util.FeeProvider
 
+	feeModel util.FeeModel
+
+	// expiryDelta is the number of blocks after which an unmined transaction
+	// expires. Zero disables expiry.
+	expiryDelta uint32
+
 	mPrivKey *hd.ExtendedKey
 	mPubKey  *hd.ExtendedKey
 
 	exchangeRates wi.ExchangeRates
 }
 
+func init() {
+	registry.Register(registry.Coin{
+		Name:                "Zcash",
+		CoinType:            util.ExtendCoinType(wi.Zcash),
+		TestnetCoinType:     util.ExtendCoinType(wi.TestnetZcash),
+		CurrencyCode:        "ZEC",
+		TestnetCurrencyCode: "TZEC",
+		NewWallet:           newWallet,
+		PriceProviders: []string{
+			"kraken+https://api.kraken.com/0/public/Ticker?pair=ZECXBT",
+			"bitfinex+https://api.bitfinex.com/v1/pubticker/zecbtc",
+			"coingecko+https://api.coingecko.com/api/v3/coins/zcash?tickers=false&community_data=false&developer_data=false&sparkline=false",
+		},
+		BlockInterval:   75 * time.Second,
+		DecodeAddress:   zaddr.DecodeAddress,
+		ScriptToAddress: zaddr.ExtractPkScriptAddrs,
+		AddressToScript: zaddr.PayToAddrScript,
+	})
+}
+
+func newWallet(cfg config.CoinConfig, mnemonic string, params *chaincfg.Params, proxy proxy.Dialer, cache cache.Cacher, disableExchangeRates bool) (wi.Wallet, error) {
+	w, err := NewZCashWallet(cfg, mnemonic, params, proxy, cache, disableExchangeRates)
+	if err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
 func NewZCashWallet(cfg config.CoinConfig, mnemonic string, params *chaincfg.Params, proxy proxy.Dialer, cache cache.Cacher, disableExchangeRates bool) (*ZCashWallet, error) {
 	seed := bip39.NewSeed(mnemonic, "")
 
@@ -55,7 +94,7 @@ func NewZCashWallet(cfg config.CoinConfig, mnemonic string, params *chaincfg.Par
 		return nil, err
 	}
 
-	c, err := client.NewClientPool(cfg.ClientAPIs, proxy)
+	c, err := client.NewClientPoolWithOptions(cfg.ClientAPIs, cfg.Options, proxy)
 	if err != nil {
 		return nil, err
 	}
@@ -67,12 +106,54 @@ func NewZCashWallet(cfg config.CoinConfig, mnemonic string, params *chaincfg.Par
 
 	var er wi.ExchangeRates
 	if !disableExchangeRates {
-		er = NewZcashPriceFetcher(proxy)
+		fetcher, err := rates.NewPriceFetcher(cfg.CoinType, cfg.PriceAPIs, proxy)
+		if err != nil {
+			return nil, err
+		}
+		go fetcher.Run()
+		er = fetcher
+		wm.SetExchangeRates(er)
 	}
 
 	fp := util.NewFeeDefaultProvider(cfg.MaxFee, cfg.HighFee, cfg.MediumFee, cfg.LowFee)
 
-	return &ZCashWallet{cfg.DB, km, params, c, wm, fp, mPrivKey, mPubKey, er}, nil
+	var feeModel util.FeeModel = ZIP317FeeModel{}
+	if cfg.FeeModel != nil {
+		feeModel = cfg.FeeModel
+	}
+
+	expiryDelta, err := expiryDeltaOption(cfg.Options)
+	if err != nil {
+		return nil, err
+	}
+	wm.SetTxExpiry(txExpiry)
+	wm.SetTxDecoder(decodeTx)
+
+	return &ZCashWallet{cfg.DB, km, params, c, wm, fp, feeModel, expiryDelta, mPrivKey, mPubKey, er}, nil
+}
+
+const (
+	// OptionExpiryDelta is the CoinConfig option setting the number of blocks
+	// after which an unmined transaction expires. Zero disables expiry.
+	OptionExpiryDelta = "ExpiryDelta"
+
+	// DefaultExpiryDelta is the expiry delta used by zcashd
+	DefaultExpiryDelta = 40
+
+	// expiringSoonThreshold is the number of blocks before its expiry height
+	// at which zcashd stops accepting a transaction into its mempool.
+	expiringSoonThreshold = 3
+)
+
+func expiryDeltaOption(options map[string]interface{}) (uint32, error) {
+	delta, err := util.IntOption(options, OptionExpiryDelta, DefaultExpiryDelta)
+	if err != nil {
+		return 0, err
+	}
+	if delta < 0 || delta > math.MaxInt32 || (delta > 0 && delta <= expiringSoonThreshold) {
+		return 0, fmt.Errorf("invalid %s %d: must be 0 or greater than %d", OptionExpiryDelta, delta, expiringSoonThreshold)
+	}
+	return uint32(delta), nil
 }
 
 func zcashCashAddress(key *hd.ExtendedKey, params *chaincfg.Params) (btcutil.Address, error) {
@@ -214,6 +295,50 @@ func (w *ZCashWallet) GetTransaction(txid chainhash.Hash) (wi.Txn, error) {
 	return txn, err
 }
 
+// TransactionData returns the payloads of the OP_RETURN outputs of a wallet
+// transaction.
+func (w *ZCashWallet) TransactionData(txid chainhash.Hash) ([][]byte, error) {
+	return w.ws.NullData(txid)
+}
+
+// TransactionMetadata returns the reference ID, memo, labels and counterparty
+// recorded for a wallet transaction.
+func (w *ZCashWallet) TransactionMetadata(txid chainhash.Hash) (service.TxMetadata, bool) {
+	return w.ws.TxMetadata(txid.String())
+}
+
+// SetTransactionMetadata replaces the metadata recorded for a transaction
+func (w *ZCashWallet) SetTransactionMetadata(md service.TxMetadata) error {
+	return w.ws.SetTxMetadata(md)
+}
+
+// SetAddressLabel labels incoming payments to addr with label
+func (w *ZCashWallet) SetAddressLabel(addr btcutil.Address, label string) error {
+	return w.ws.SetAddressLabel(addr, label)
+}
+
+// TransactionByReference returns the transaction spent with referenceID
+func (w *ZCashWallet) TransactionByReference(referenceID string) (wi.Txn, error) {
+	return w.ws.TransactionByReference(referenceID)
+}
+
+// TransactionsWithLabel returns the transactions labeled with label
+func (w *ZCashWallet) TransactionsWithLabel(label string) ([]wi.Txn, error) {
+	return w.ws.TransactionsWithLabel(label)
+}
+
+// TransactionPage returns a page of the transactions selected by filter, most
+// recent first, and the cursor of the next page.
+func (w *ZCashWallet) TransactionPage(filter service.TxFilter) ([]wi.Txn, string, error) {
+	return w.ws.TransactionPage(filter)
+}
+
+// ExportTransactions returns the transactions of the wallet from the oldest
+// with their fee, the running balance and their value in currency.
+func (w *ZCashWallet) ExportTransactions(currency string) ([]service.ExportRow, error) {
+	return w.ws.ExportTransactions(currency)
+}
+
 func (w *ZCashWallet) ChainTip() (uint32, chainhash.Hash) {
 	return w.ws.ChainTip()
 }
@@ -223,28 +348,130 @@ func (w *ZCashWallet) GetFeePerByte(feeLevel wi.FeeLevel) uint64 {
 }
 
 func (w *ZCashWallet) Spend(amount int64, addr btcutil.Address, feeLevel wi.FeeLevel, referenceID string, spendAll bool) (*chainhash.Hash, error) {
-	var (
-		tx  *wire.MsgTx
-		err error
-	)
-	if spendAll {
-		tx, err = w.buildSpendAllTx(addr, feeLevel)
+	return w.SpendWithData(amount, addr, feeLevel, referenceID, nil, spendAll)
+}
+
+// SpendWithData spends like Spend and attaches data of up to 80 bytes to the
+// transaction in an OP_RETURN output.
+func (w *ZCashWallet) SpendWithData(amount int64, addr btcutil.Address, feeLevel wi.FeeLevel, referenceID string, data []byte, spendAll bool) (*chainhash.Hash, error) {
+	tx, params, err := w.buildSpendTx(amount, addr, feeLevel, data, spendAll)
+	if err != nil {
+		return nil, err
+	}
+	// Broadcast
+	txid, err := w.broadcast(tx, params)
+	if err != nil {
+		return nil, err
+	}
+	w.ws.RecordSpend(txid, referenceID, addr)
+
+	return chainhash.NewHashFromStr(txid)
+}
+
+// buildSpendTx builds and signs the transaction of SpendWithData
+func (w *ZCashWallet) buildSpendTx(amount int64, addr btcutil.Address, feeLevel wi.FeeLevel, data []byte, spendAll bool) (*wire.MsgTx, txParams, error) {
+	var dataOutput *wire.TxOut
+	if len(data) > 0 {
+		var err error
+		dataOutput, err = util.NullDataOutput(data)
 		if err != nil {
-			return nil, err
+			return nil, txParams{}, err
 		}
-	} else {
-		tx, err = w.buildTx(amount, addr, feeLevel, nil)
+	}
+	if spendAll {
+		return w.buildSpendAllTx(addr, feeLevel, dataOutput)
+	}
+	return w.buildTx(amount, addr, feeLevel, dataOutput)
+}
+
+// SpendWithRequestID spends like SpendWithData once per request ID. Retrying
+// a request ID returns the txid of its transaction, broadcasting the same
+// transaction again if its broadcast failed.
+func (w *ZCashWallet) SpendWithRequestID(requestID string, amount int64, addr btcutil.Address, feeLevel wi.FeeLevel, referenceID string, data []byte, spendAll bool) (*chainhash.Hash, error) {
+	return w.spendOnce(requestID, referenceID, []btcutil.Address{addr}, func() (*wire.MsgTx, txParams, error) {
+		return w.buildSpendTx(amount, addr, feeLevel, data, spendAll)
+	})
+}
+
+// SpendBatch pays every payment in a single transaction. With a request ID
+// the payments are sent once like SpendWithRequestID.
+func (w *ZCashWallet) SpendBatch(requestID string, payments []wi.TransactionOutput, feeLevel wi.FeeLevel, referenceID string) (*chainhash.Hash, error) {
+	recipients := make([]btcutil.Address, 0, len(payments))
+	for _, payment := range payments {
+		recipients = append(recipients, payment.Address)
+	}
+	return w.spendOnce(requestID, referenceID, recipients, func() (*wire.MsgTx, txParams, error) {
+		return w.buildBatchTx(payments, feeLevel, nil)
+	})
+}
+
+// AbandonSpendRequest gives up the transaction of a request ID whose
+// broadcast failed, so that it is never broadcast and its inputs are left to
+// other spends.
+func (w *ZCashWallet) AbandonSpendRequest(requestID string) error {
+	return w.ws.AbandonSpendRequest(requestID)
+}
+
+// ConvertFiatOnce converts a fiat amount at the current exchange rate once per
+// request ID, returning the first conversion again for later calls with the
+// same request ID.
+func (w *ZCashWallet) ConvertFiatOnce(requestID string, fiat rates.FiatAmount) (rates.Conversion, error) {
+	return w.ws.ConvertOnce(requestID, func() (rates.Conversion, error) {
+		return rates.Convert(w.ExchangeRates(), fiat)
+	})
+}
+
+// PlanSpend returns the transaction SpendWithData would build without signing
+// or broadcasting it.
+func (w *ZCashWallet) PlanSpend(amount int64, addr btcutil.Address, feeLevel wi.FeeLevel, data []byte, spendAll bool) (*util.SpendPlan, error) {
+	var dataOutput *wire.TxOut
+	if len(data) > 0 {
+		var err error
+		dataOutput, err = util.NullDataOutput(data)
 		if err != nil {
 			return nil, err
 		}
 	}
-	// Broadcast
-	txid, err := w.Broadcast(tx)
-	if err != nil {
-		return nil, err
+	if spendAll {
+		return w.planSpendAllTx(addr, feeLevel, dataOutput)
 	}
+	return w.planBatchTx([]wi.TransactionOutput{{Address: addr, Value: amount}}, feeLevel, dataOutput)
+}
 
-	return chainhash.NewHashFromStr(txid)
+// ExecuteSpendPlan signs and broadcasts the transaction of a plan returned by
+// PlanSpend as is. With a request ID the plan is executed once like
+// SpendWithRequestID.
+func (w *ZCashWallet) ExecuteSpendPlan(requestID string, plan *util.SpendPlan, referenceID string) (*chainhash.Hash, error) {
+	return w.spendOnce(requestID, referenceID, plan.Recipients(), func() (*wire.MsgTx, txParams, error) {
+		return w.signPlan(plan)
+	})
+}
+
+// spendOnce signs the transaction returned by build and broadcasts it once per
+// request ID like service.WalletService.SpendOnce
+func (w *ZCashWallet) spendOnce(requestID, referenceID string, recipients []btcutil.Address, build func() (*wire.MsgTx, txParams, error)) (*chainhash.Hash, error) {
+	return w.ws.SpendOnce(requestID, func() (*service.SignedSpend, error) {
+		tx, params, err := build()
+		if err != nil {
+			return nil, err
+		}
+		raw, err := serializeTransaction(tx, params.upgrade, params.expiryHeight)
+		if err != nil {
+			return nil, err
+		}
+		txid, err := transactionID(raw, tx, params.expiryHeight)
+		if err != nil {
+			return nil, err
+		}
+		return service.NewSignedSpend(raw, txid, tx), nil
+	}, func(raw []byte) (*chainhash.Hash, error) {
+		txid, err := w.BroadcastRawTx(raw)
+		if err != nil {
+			return nil, err
+		}
+		w.ws.RecordSpend(txid.String(), referenceID, recipients...)
+		return txid, nil
+	})
 }
 
 func (w *ZCashWallet) BumpFee(txid chainhash.Hash) (*chainhash.Hash, error) {
@@ -258,9 +485,7 @@ func (w *ZCashWallet) EstimateFee(ins []wi.TransactionInput, outs []wi.Transacti
 		output := wire.NewTxOut(out.Value, scriptPubKey)
 		tx.TxOut = append(tx.TxOut, output)
 	}
-	estimatedSize := EstimateSerializeSize(len(ins), tx.TxOut, false, P2PKH)
-	fee := estimatedSize * int(feePerByte)
-	return uint64(fee)
+	return estimateFee(w.feeModel, len(ins), tx.TxOut, false, P2PKH, feePerByte)
 }
 
 func (w *ZCashWallet) EstimateSpendFee(amount int64, feeLevel wi.FeeLevel) (uint64, error) {
@@ -279,6 +504,36 @@ func (w *ZCashWallet) Multisign(ins []wi.TransactionInput, outs []wi.Transaction
 	return w.multisign(ins, outs, sigs1, sigs2, redeemScript, feePerByte, broadcast)
 }
 
+func (w *ZCashWallet) MultisignWithSignatures(ins []wi.TransactionInput, outs []wi.TransactionOutput, sigs multisig.Signatures, redeemScript []byte, feePerByte uint64, broadcast bool) ([]byte, bool, error) {
+	return w.multisignWithSignatures(ins, outs, sigs, redeemScript, feePerByte, broadcast)
+}
+
+func (w *ZCashWallet) MergeMultisig(redeemScript []byte, txs [][]byte, broadcast bool) ([]byte, bool, error) {
+	return w.mergeMultisig(redeemScript, txs, broadcast)
+}
+
+func (w *ZCashWallet) ReleaseAfterTimeout(ins []wi.TransactionInput, address btcutil.Address, timeoutKey *hd.ExtendedKey, redeemScript []byte, feeLevel wi.FeeLevel) (*chainhash.Hash, error) {
+	return w.releaseAfterTimeout(ins, address, timeoutKey, redeemScript, feeLevel)
+}
+
+// GenerateHTLCScript returns the address and script of a hash time locked
+// contract paying recipientKey for the preimage of secretHash, or refundKey
+// once the chain reached the lockTime height.
+func (w *ZCashWallet) GenerateHTLCScript(secretHash, recipientKey, refundKey []byte, lockTime uint32) (btcutil.Address, []byte, error) {
+	return w.generateHTLCScript(secretHash, recipientKey, refundKey, lockTime)
+}
+
+func (w *ZCashWallet) RedeemHTLC(ins []wi.TransactionInput, address btcutil.Address, key *hd.ExtendedKey, contract []byte, secret []byte, feeLevel wi.FeeLevel) (*chainhash.Hash, error) {
+	if secret == nil {
+		return nil, htlc.ErrSecretMismatch
+	}
+	return w.spendHTLC(ins, address, key, contract, secret, feeLevel)
+}
+
+func (w *ZCashWallet) RefundHTLC(ins []wi.TransactionInput, address btcutil.Address, key *hd.ExtendedKey, contract []byte, feeLevel wi.FeeLevel) (*chainhash.Hash, error) {
+	return w.spendHTLC(ins, address, key, contract, nil, feeLevel)
+}
+
 func (w *ZCashWallet) GenerateMultisigScript(keys []hd.ExtendedKey, threshold int, timeout time.Duration, timeoutKey *hd.ExtendedKey) (addr btcutil.Address, redeemScript []byte, err error) {
 	return w.generateMultisigScript(keys, threshold, timeout, timeoutKey)
 }
@@ -313,6 +568,16 @@ func (w *ZCashWallet) AddTransactionListener(callback func(wi.TransactionCallbac
 	w.ws.AddTransactionListener(callback)
 }
 
+// WatchHTLC watches the contract address and calls callback for each input
+// spending its outputs, revealing the secret of a redeem.
+func (w *ZCashWallet) WatchHTLC(addr btcutil.Address, callback func(service.ScriptSpend)) error {
+	if err := w.AddWatchedAddress(addr); err != nil {
+		return err
+	}
+	w.ws.WatchSpends(addr, callback)
+	return nil
+}
+
 func (w *ZCashWallet) ReSyncBlockchain(fromTime time.Time) {
 	go w.ws.UpdateState()
 }
@@ -332,12 +597,23 @@ func (w *ZCashWallet) GetConfirmations(txid chainhash.Hash) (uint32, uint32, err
 func (w *ZCashWallet) Close() {
 	w.ws.Stop()
 	w.client.Close()
+	if f, ok := w.exchangeRates.(*rates.Fetcher); ok {
+		f.Stop()
+	}
 }
 
 func (w *ZCashWallet) ExchangeRates() wi.ExchangeRates {
 	return w.exchangeRates
 }
 
+// BackendStatus returns the health scoreboard of the API servers used by the wallet
+func (w *ZCashWallet) BackendStatus() []client.BackendStatus {
+	if pool, ok := w.client.(*client.ClientPool); ok {
+		return pool.BackendStatus()
+	}
+	return nil
+}
+
 func (w *ZCashWallet) DumpTables(wr io.Writer) {
 	fmt.Fprintln(wr, "Transactions-----")
 	txns, _ := w.db.Txns().GetAll(true)
@@ -351,36 +627,46 @@ func (w *ZCashWallet) DumpTables(wr io.Writer) {
 	}
 }
 
-// Build a client.Transaction so we can ingest it into the wallet service then broadcast
+// Broadcast broadcasts a transaction signed without an expiry height
 func (w *ZCashWallet) Broadcast(tx *wire.MsgTx) (string, error) {
-	txBytes, err := serializeVersion4Transaction(tx, 0)
+	return w.broadcast(tx, txParams{upgrade: w.networkUpgrade()})
+}
+
+// Build a client.Transaction so we can ingest it into the wallet service then broadcast
+func (w *ZCashWallet) broadcast(tx *wire.MsgTx, params txParams) (string, error) {
+	txBytes, err := serializeTransaction(tx, params.upgrade, params.expiryHeight)
+	if err != nil {
+		return "", err
+	}
+	cTxn, err := w.modelTransaction(tx, tx.TxHash().String(), txBytes)
+	if err != nil {
+		return "", err
+	}
+	cTxn.Txid, err = w.client.Broadcast(txBytes)
 	if err != nil {
 		return "", err
 	}
+	w.ws.ProcessIncomingTransaction(cTxn)
+	return cTxn.Txid, nil
+}
+
+// modelTransaction converts tx into the form the wallet service ingests.
+// Inputs spending outputs the wallet doesn't hold are left without an address,
+// as are outputs whose script doesn't pay to one.
+func (w *ZCashWallet) modelTransaction(tx *wire.MsgTx, txid string, raw []byte) (model.Transaction, error) {
 	cTxn := model.Transaction{
-		Txid:          tx.TxHash().String(),
+		Txid:          txid,
 		Locktime:      int(tx.LockTime),
 		Version:       int(tx.Version),
 		Confirmations: 0,
 		Time:          time.Now().Unix(),
-		RawBytes:      txBytes,
+		RawBytes:      raw,
 	}
 	utxos, err := w.db.Utxos().GetAll()
 	if err != nil {
-		return "", err
+		return cTxn, err
 	}
 	for n, in := range tx.TxIn {
-		var u wi.Utxo
-		for _, ut := range utxos {
-			if util.OutPointsEqual(ut.Op, in.PreviousOutPoint) {
-				u = ut
-				break
-			}
-		}
-		addr, err := w.ScriptToAddress(u.ScriptPubkey)
-		if err != nil {
-			return "", err
-		}
 		input := model.Input{
 			Txid: in.PreviousOutPoint.Hash.String(),
 			Vout: int(in.PreviousOutPoint.Index),
@@ -389,33 +675,143 @@ func (w *ZCashWallet) Broadcast(tx *wire.MsgTx) (string, error) {
 			},
 			Sequence: uint32(in.Sequence),
 			N:        n,
-			Addr:     addr.String(),
-			Satoshis: u.Value,
-			Value:    float64(u.Value) / util.SatoshisPerCoin(wi.Zcash),
+		}
+		for _, u := range utxos {
+			if !util.OutPointsEqual(u.Op, in.PreviousOutPoint) {
+				continue
+			}
+			if addr, err := w.ScriptToAddress(u.ScriptPubkey); err == nil {
+				input.Addr = addr.String()
+			}
+			input.Satoshis = u.Value
+			input.Value = float64(u.Value) / util.SatoshisPerCoin(wi.Zcash)
+			break
 		}
 		cTxn.Inputs = append(cTxn.Inputs, input)
 	}
 	for n, out := range tx.TxOut {
-		addr, err := w.ScriptToAddress(out.PkScript)
-		if err != nil {
-			return "", err
-		}
 		output := model.Output{
 			N: n,
 			ScriptPubKey: model.OutScript{
 				Script: model.Script{
 					Hex: hex.EncodeToString(out.PkScript),
 				},
-				Addresses: []string{addr.String()},
 			},
-			Value: float64(float64(out.Value) / util.SatoshisPerCoin(wi.Bitcoin)),
+			Value: float64(out.Value) / util.SatoshisPerCoin(wi.Zcash),
+		}
+		if addr, err := w.ScriptToAddress(out.PkScript); err == nil {
+			output.ScriptPubKey.Addresses = []string{addr.String()}
+		} else if _, ok := util.ExtractNullData(out.PkScript); ok {
+			output.ScriptPubKey.Type = "nulldata"
 		}
 		cTxn.Outputs = append(cTxn.Outputs, output)
 	}
-	cTxn.Txid, err = w.client.Broadcast(txBytes)
+	return cTxn, nil
+}
+
+// DecodeRawTx decodes a serialized version four or five transaction. Only its
+// transparent parts are decoded, so the fee is only computed for transparent
+// transactions whose spent outputs the wallet knows.
+func (w *ZCashWallet) DecodeRawTx(raw []byte) (*util.DecodedTx, error) {
+	return w.decodeTx(raw, nil, false)
+}
+
+// TransactionDetails decodes a wallet transaction and tells which of its
+// outputs pay the wallet. Outputs it spends the wallet doesn't store are
+// fetched from the backend.
+func (w *ZCashWallet) TransactionDetails(txid chainhash.Hash) (*util.TxDetails, error) {
+	txn, err := w.db.Txns().Get(txid)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	w.ws.ProcessIncomingTransaction(cTxn)
-	return cTxn.Txid, nil
+	decoded, err := w.decodeTx(txn.Bytes, &txid, true)
+	if err != nil {
+		// Transactions the backend returned without their raw bytes are
+		// stored in the Bitcoin format
+		raw, rerr := w.client.GetRawTransaction(txid.String())
+		if rerr != nil {
+			return nil, err
+		}
+		if decoded, err = w.decodeTx(raw, &txid, true); err != nil {
+			return nil, err
+		}
+	}
+	confirmations, _, err := w.GetConfirmations(txid)
+	if err != nil {
+		return nil, err
+	}
+	return util.NewTxDetails(decoded, confirmations, w.outputOwner), nil
+}
+
+// decodeTx decodes raw, computing its ID unless txid is given.
+func (w *ZCashWallet) decodeTx(raw []byte, txid *chainhash.Hash, fetchPrevOuts bool) (*util.DecodedTx, error) {
+	tx, expiry, err := parseTransaction(raw)
+	if err != nil {
+		return nil, err
+	}
+	if txid == nil {
+		id, err := transactionID(raw, tx, expiry)
+		if err != nil {
+			return nil, err
+		}
+		txid = &id
+	}
+	utxos, err := w.db.Utxos().GetAll()
+	if err != nil {
+		return nil, err
+	}
+	txns, err := w.db.Txns().GetAll(true)
+	if err != nil {
+		return nil, err
+	}
+	parse := func(b []byte) (*wire.MsgTx, error) {
+		prev, _, err := parseTransaction(b)
+		return prev, err
+	}
+	prevOuts := util.KnownPrevOuts(tx, utxos, txns, parse)
+	if fetchPrevOuts {
+		util.FetchPrevOuts(tx, prevOuts, w.client.GetRawTransaction, parse)
+	}
+	decoded := util.DecodeTx(tx, *txid, len(raw), len(raw), prevOuts, w.ScriptToAddress)
+	decoded.ExpiryHeight = expiry
+	if !transparentOnly(raw, tx, expiry) {
+		decoded.Fee, decoded.FeeKnown = 0, false
+	}
+	// Zcash nodes don't replace transactions whatever their sequence
+	decoded.RBF = false
+	return decoded, nil
+}
+
+// outputOwner tells whether addr is a receiving or a change address of the
+// wallet.
+func (w *ZCashWallet) outputOwner(addr btcutil.Address) util.OutputOwner {
+	path, err := w.db.Keys().GetPathForKey(addr.ScriptAddress())
+	if err != nil {
+		return util.OwnerExternal
+	}
+	if path.Purpose == wi.INTERNAL {
+		return util.OwnerChange
+	}
+	return util.OwnerWallet
+}
+
+// BroadcastRawTx broadcasts a serialized transaction built outside the wallet.
+// It's ingested like the wallet's own transactions if it touches our addresses.
+func (w *ZCashWallet) BroadcastRawTx(raw []byte) (*chainhash.Hash, error) {
+	tx, _, err := parseTransaction(raw)
+	if err != nil {
+		return nil, err
+	}
+	cTxn, err := w.modelTransaction(tx, "", raw)
+	if err != nil {
+		return nil, err
+	}
+	cTxn.Txid, err = w.client.Broadcast(raw)
+	if err != nil {
+		return nil, err
+	}
+	if w.ws.IsRelevant(cTxn) {
+		w.ws.ProcessIncomingTransaction(cTxn)
+	}
+	return chainhash.NewHashFromStr(cTxn.Txid)
 }